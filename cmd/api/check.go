@@ -0,0 +1,355 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"macro-analyst/internal/attribution"
+	"macro-analyst/internal/config"
+	"macro-analyst/internal/fredstore"
+	"macro-analyst/internal/secrets"
+	"macro-analyst/internal/server"
+	"macro-analyst/internal/sessions"
+	"macro-analyst/internal/surprise"
+	"macro-analyst/pkg/feedlog"
+	"macro-analyst/pkg/fred"
+	"macro-analyst/pkg/usage"
+	"macro-analyst/pkg/ws"
+)
+
+// CheckTimeout bounds each upstream connectivity check of --check
+const CheckTimeout = 10 * time.Second
+
+// Statuses of a check; only failures make --check exit non-zero
+const (
+	CheckOK      = "ok"
+	CheckWarning = "warn"
+	CheckFailed  = "fail"
+	CheckSkipped = "skip"
+)
+
+// Settings validated by --check, by type
+var (
+	intSettings = []string{
+		"PORT", "MAX_PAYLOAD_SIZE", "BACKPRESSURE_THROTTLE_FACTOR",
+		"STATS_HISTORY_SIZE", "RAW_STREAMS_PER_USER", "MAX_CONNECTIONS",
+		"MAX_CONNECTIONS_PER_IP", "UPGRADES_PER_MINUTE", "AUDIT_MAX_BYTES",
+		"BROADCAST_HISTORY", "EMBED_HOURLY_BYTE_CAP", "RECONNECT_MAX_ATTEMPTS",
+		"WS_COMPRESSION_LEVEL", "HUB_BROADCAST_BUFFER", "CLIENT_SEND_BUFFER",
+		"AUTO_TRACK_TOP_N",
+	}
+	boolSettings     = []string{"WS_COMPRESSION", "REPLAY_LOOP", "FUTURES_STREAM"}
+	floatSettings    = []string{"HUB_BACKPRESSURE_THRESHOLD", "REPLAY_SPEED"}
+	durationSettings = []string{
+		"HUB_MESSAGE_TTL", "HUB_BACKPRESSURE_SUSTAIN", "STALE_FEED_TIMEOUT",
+		"HEARTBEAT_INTERVAL", "ATTRIBUTION_INTERVAL", "STATS_INTERVAL",
+		"SURPRISE_WINDOW", "SURPRISE_CHECK_INTERVAL", "RELEASE_WINDOW", "FORMULA_INTERVAL",
+		"RECENT_WINDOW", "RECENT_RESOLUTION", "POLL_WAIT", "ORDERBOOK_INTERVAL",
+		"TRADE_STATS_WINDOW", "TRADE_STATS_INTERVAL", "HUB_WRITE_TIMEOUT", "HUB_MAX_MESSAGE_AGE",
+		"HUB_IDLE_TIMEOUT", "FUTURES_INTERVAL", "FUTURES_OPEN_INTEREST_INTERVAL",
+		"NET_LIQUIDITY_INTERVAL", "MOVERS_INTERVAL", "FRED_POLL_INTERVAL",
+		"FAILOVER_TIMEOUT", "RECONNECT_BACKOFF", "THROTTLE_INTERVAL", "CANDLES_CACHE_TTL",
+	}
+	secretSettings = []string{
+		"FRED_API_KEY", "BINANCE_API_KEY", "BINANCE_API_SECRET", "ADMIN_TOKEN",
+		"API_KEYS", "SHARE_SECRET", "JWT_SIGNING_KEY", "STALE_FEED_WEBHOOK_URL",
+		"NATS_URL",
+	}
+)
+
+// CheckResult is the outcome of one pre-deploy check.
+type CheckResult struct {
+	Name       string   `json:"name"`
+	Status     string   `json:"status"`
+	Detail     string   `json:"detail,omitempty"`
+	Problems   []string `json:"problems,omitempty"`
+	DurationMs int64    `json:"duration_ms"`
+}
+
+// CheckReport is printed by --check for CI/CD pre-deploy gates.
+type CheckReport struct {
+	OK        bool          `json:"ok"` // No check failed
+	CheckedAt time.Time     `json:"checked_at"`
+	Checks    []CheckResult `json:"checks"`
+}
+
+// runCheck validates the configuration and upstream connectivity without
+// starting the server, prints a JSON report to stdout and returns the exit
+// code: 0 if no check failed, 1 otherwise.
+func runCheck(cfg config.Config, resolver *secrets.Resolver) int {
+	report := CheckReport{OK: true, CheckedAt: time.Now().UTC()}
+
+	var resolved map[string]string
+	for _, check := range []struct {
+		name string
+		run  func() CheckResult
+	}{
+		{"config", func() CheckResult { return checkConfig(cfg.Lookup) }},
+		{"secrets", func() CheckResult {
+			var result CheckResult
+			resolved, result = checkSecrets(resolver)
+			return result
+		}},
+		{"fred", func() CheckResult {
+			if !moduleEnabled(cfg, server.ModuleFRED) {
+				return CheckResult{Status: CheckSkipped, Detail: "fred module disabled"}
+			}
+			return checkFRED(resolved["FRED_API_KEY"])
+		}},
+		{"binance", func() CheckResult {
+			if !moduleEnabled(cfg, server.ModuleCrypto) {
+				return CheckResult{Status: CheckSkipped, Detail: "crypto module disabled"}
+			}
+			return checkBinance(resolved["BINANCE_API_KEY"], resolved["BINANCE_API_SECRET"])
+		}},
+		{"storage", func() CheckResult { return checkStorage(cfg) }},
+	} {
+		started := time.Now()
+		result := check.run()
+		result.Name = check.name
+		result.DurationMs = time.Since(started).Milliseconds()
+		result.Detail = resolver.Redact(result.Detail)
+		for idx, problem := range result.Problems {
+			result.Problems[idx] = resolver.Redact(problem)
+		}
+
+		report.Checks = append(report.Checks, result)
+		if result.Status == CheckFailed {
+			report.OK = false
+		}
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write check report: %v\n", err)
+		return 1
+	}
+	if !report.OK {
+		return 1
+	}
+	return 0
+}
+
+// moduleEnabled reports whether MODULES switches a module on; all are on
+// when it is unset or invalid, which checkConfig reports.
+func moduleEnabled(cfg config.Config, module server.Module) bool {
+	modules, err := server.ParseModules(cfg.Server.Modules)
+	if err != nil || len(modules) == 0 {
+		return true
+	}
+	return slices.Contains(modules, module)
+}
+
+// checkConfig validates the non-secret settings of the config file and the
+// environment that would otherwise fall back to their defaults with only a
+// log line.
+func checkConfig(lookupEnv func(key string) (string, bool)) CheckResult {
+	var problems []string
+	invalid := func(key, value, expected string) {
+		problems = append(problems, fmt.Sprintf("%s=%q is not %s", key, value, expected))
+	}
+
+	for _, key := range intSettings {
+		if value, ok := lookupEnv(key); ok && value != "" {
+			if _, err := strconv.Atoi(value); err != nil {
+				invalid(key, value, "an integer")
+			}
+		}
+	}
+	for _, key := range boolSettings {
+		if value, ok := lookupEnv(key); ok && value != "" {
+			if _, err := strconv.ParseBool(value); err != nil {
+				invalid(key, value, "true or false")
+			}
+		}
+	}
+	for _, key := range floatSettings {
+		if value, ok := lookupEnv(key); ok && value != "" {
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				invalid(key, value, "a number")
+			}
+		}
+	}
+	for _, key := range durationSettings {
+		if value, ok := lookupEnv(key); ok && value != "" {
+			if _, err := time.ParseDuration(value); err != nil {
+				invalid(key, value, "a duration such as 90s or 2m")
+			}
+		}
+	}
+
+	if value, ok := lookupEnv("TIMESTAMP_SOURCE"); ok && value != "" {
+		if _, err := ws.ParseTimestampSource(value); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if value, ok := lookupEnv("CHANGE_REFERENCE"); ok && value != "" {
+		reference, err := ws.ParseChangeReference(value)
+		if err != nil {
+			problems = append(problems, err.Error())
+		} else if reference == ws.ChangeReferenceAnchor {
+			anchor, _ := lookupEnv("CHANGE_ANCHOR")
+			if _, err := time.Parse(time.RFC3339, anchor); err != nil {
+				invalid("CHANGE_ANCHOR", anchor, "an RFC3339 time")
+			}
+		}
+	}
+	if value, ok := lookupEnv("MOCK_DATA"); ok && value != "" && value != MockDataAuto {
+		if _, err := strconv.ParseBool(value); err != nil {
+			invalid("MOCK_DATA", value, "true, false or auto")
+		}
+	}
+	if value, ok := lookupEnv("HUB_SLOW_CLIENT_POLICY"); ok && value != "" {
+		if _, err := ws.ParseSlowClientPolicy(value); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if value, ok := lookupEnv("SIGNIFICANCE_THRESHOLD"); ok && value != "" {
+		if _, err := ws.ParseThreshold(value); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if value, ok := lookupEnv("SYMBOL_SIGNIFICANCE_THRESHOLDS"); ok {
+		if _, err := ws.ParseSymbolThresholds(config.List(value)); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if value, ok := lookupEnv("FX_PAIRS"); ok {
+		if _, err := ws.NewFXRates(config.List(value)); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if value, ok := lookupEnv("EXCHANGES"); ok {
+		if _, err := ws.ParseExchanges(config.List(value)); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if value, ok := lookupEnv("CANDLE_INTERVALS"); ok {
+		if _, err := ws.ParseCandleIntervals(config.List(value)); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if value, ok := lookupEnv("ORDERBOOK_DEPTH"); ok && value != "" {
+		if _, err := ws.ParseOrderBookDepth(value); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if value, ok := lookupEnv("FRED_TICKERS"); ok {
+		if _, err := fred.ParseSeriesList(value); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if value, ok := lookupEnv("MARKET_HOLIDAYS"); ok {
+		for _, date := range config.List(value) {
+			if _, err := time.Parse(sessions.DateLayout, date); err != nil {
+				invalid("MARKET_HOLIDAYS", date, "a YYYY-MM-DD date")
+			}
+		}
+	}
+	if value, ok := lookupEnv("MODULES"); ok {
+		if _, err := server.ParseModules(config.List(value)); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if value, ok := lookupEnv("ATTRIBUTION_MODULES"); ok {
+		for _, module := range config.List(value) {
+			if !slices.Contains(attribution.Modules, attribution.Module(module)) {
+				invalid("ATTRIBUTION_MODULES", module, fmt.Sprintf("one of %v", attribution.Modules))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return CheckResult{Status: CheckFailed, Problems: problems}
+	}
+	return CheckResult{Status: CheckOK}
+}
+
+// checkSecrets resolves every credential from its configured source and
+// returns the resolved values. Missing optional credentials are warnings.
+func checkSecrets(resolver *secrets.Resolver) (map[string]string, CheckResult) {
+	resolved := make(map[string]string, len(secretSettings))
+	var problems, missing []string
+	for _, key := range secretSettings {
+		value, err := resolver.Get(key)
+		switch {
+		case err != nil:
+			problems = append(problems, err.Error())
+		case value == "":
+			missing = append(missing, key)
+		}
+		resolved[key] = value
+	}
+
+	switch {
+	case len(problems) > 0:
+		return resolved, CheckResult{Status: CheckFailed, Problems: problems}
+	case slices.Contains(missing, "FRED_API_KEY") || slices.Contains(missing, "SHARE_SECRET"):
+		return resolved, CheckResult{Status: CheckWarning, Detail: fmt.Sprintf("not set: %v", missing)}
+	}
+	return resolved, CheckResult{Status: CheckOK}
+}
+
+// checkFRED verifies the FRED API key by fetching the metadata of one series.
+func checkFRED(apiKey string) CheckResult {
+	if apiKey == "" {
+		return CheckResult{Status: CheckSkipped, Detail: "FRED_API_KEY not set, FRED endpoints will be unavailable"}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), CheckTimeout)
+	defer cancel()
+
+	if _, err := fred.NewClient(apiKey).GetSeriesInfo(ctx, fred.TickerWALCL); err != nil {
+		return CheckResult{Status: CheckFailed, Detail: err.Error()}
+	}
+	return CheckResult{Status: CheckOK}
+}
+
+// checkBinance verifies the Binance REST API is reachable with the
+// configured credentials.
+func checkBinance(apiKey, secretKey string) CheckResult {
+	ctx, cancel := context.WithTimeout(context.Background(), CheckTimeout)
+	defer cancel()
+
+	ingestor := ws.NewIngestor(ws.HubSink(ws.NewHub()), ws.WithBinanceCredentials(apiKey, secretKey))
+	if err := ingestor.CheckBinance(ctx); err != nil {
+		return CheckResult{Status: CheckFailed, Detail: err.Error()}
+	}
+	if !ingestor.HasBinanceCredentials() {
+		return CheckResult{Status: CheckOK, Detail: "anonymous access"}
+	}
+	return CheckResult{Status: CheckOK}
+}
+
+// checkStorage loads the persisted state files. The server has no
+// database, so there are no migrations to check; a state file that no
+// longer parses is the equivalent failure.
+func checkStorage(cfg config.Config) CheckResult {
+	var problems []string
+	if err := usage.NewTracker(usage.WithFile(cfg.UsageFile)).Load(); err != nil {
+		problems = append(problems, fmt.Sprintf("USAGE_FILE: %v", err))
+	}
+	if err := surprise.NewStore(surprise.WithFile(cfg.Macro.ExpectationsFile)).Load(); err != nil {
+		problems = append(problems, fmt.Sprintf("EXPECTATIONS_FILE: %v", err))
+	}
+	if err := fredstore.New(nil, fredstore.WithFile(cfg.Macro.FREDHistoryFile)).Load(); err != nil {
+		problems = append(problems, fmt.Sprintf("FRED_HISTORY_FILE: %v", err))
+	}
+	if err := feedlog.New(feedlog.WithFile(cfg.Feeds.HistoryFile)).Load(); err != nil {
+		problems = append(problems, fmt.Sprintf("FEED_HISTORY_FILE: %v", err))
+	}
+	if err := ws.NewIngestor(ws.HubSink(ws.NewHub()), ws.WithSnapshotFile(cfg.Feeds.SnapshotFile)).RestoreSnapshot(); err != nil {
+		problems = append(problems, fmt.Sprintf("SNAPSHOT_FILE: %v", err))
+	}
+
+	if len(problems) > 0 {
+		return CheckResult{Status: CheckFailed, Problems: problems}
+	}
+	return CheckResult{Status: CheckOK}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCheckConfig verifies invalid settings fail the config check.
+func TestCheckConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected string
+		problem  string
+	}{
+		{"defaults", map[string]string{}, CheckOK, ""},
+		{"valid", map[string]string{
+			"PORT": "8080", "HUB_MESSAGE_TTL": "5s", "SIGNIFICANCE_THRESHOLD": "5bps",
+			"MARKET_HOLIDAYS": "2024-12-25", "TIMESTAMP_SOURCE": "event",
+		}, CheckOK, ""},
+		{"integer", map[string]string{"PORT": "eighty"}, CheckFailed, "PORT"},
+		{"number", map[string]string{"HUB_BACKPRESSURE_THRESHOLD": "high"}, CheckFailed, "HUB_BACKPRESSURE_THRESHOLD"},
+		{"boolean", map[string]string{"WS_COMPRESSION": "yes"}, CheckFailed, "WS_COMPRESSION"},
+		{"duration", map[string]string{"STALE_FEED_TIMEOUT": "90"}, CheckFailed, "STALE_FEED_TIMEOUT"},
+		{"threshold", map[string]string{"SIGNIFICANCE_THRESHOLD": "1%"}, CheckFailed, "1%"},
+		{"fred ticker", map[string]string{"FRED_TICKERS": "UNRATE|Unemployment Rate|Percent;T10Y-2Y"}, CheckFailed, "T10Y-2Y"},
+		{"holiday", map[string]string{"MARKET_HOLIDAYS": "2024-12-25,25.12.2024"}, CheckFailed, "25.12.2024"},
+		{"module", map[string]string{"ATTRIBUTION_MODULES": "astrology"}, CheckFailed, "astrology"},
+		{"server module", map[string]string{"MODULES": "crypto,alarms"}, CheckFailed, "alarms"},
+		{"lean modules", map[string]string{"MODULES": "Crypto"}, CheckOK, ""},
+		{"order book depth", map[string]string{"ORDERBOOK_DEPTH": "50"}, CheckFailed, "50"},
+		{"slow client policy", map[string]string{"HUB_SLOW_CLIENT_POLICY": "kick"}, CheckFailed, "kick"},
+		{"change reference", map[string]string{"CHANGE_REFERENCE": "week"}, CheckFailed, "week"},
+		{"change anchor", map[string]string{"CHANGE_REFERENCE": "anchor", "CHANGE_ANCHOR": "2024-02-19"}, CheckFailed, "CHANGE_ANCHOR"},
+	}
+
+	for _, tt := range tests {
+		result := checkConfig(func(key string) (string, bool) {
+			value, ok := tt.env[key]
+			return value, ok
+		})
+
+		if result.Status != tt.expected {
+			t.Errorf("%s: expected status %s, got %s (%v)", tt.name, tt.expected, result.Status, result.Problems)
+		}
+		if tt.problem != "" && (len(result.Problems) != 1 || !strings.Contains(result.Problems[0], tt.problem)) {
+			t.Errorf("%s: expected one problem mentioning %q, got %v", tt.name, tt.problem, result.Problems)
+		}
+	}
+}
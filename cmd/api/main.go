@@ -2,85 +2,968 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"slices"
 	"strconv"
 	"syscall"
 	"time"
 
 	_ "github.com/joho/godotenv/autoload"
 
+	"macro-analyst/internal/analytics"
+	"macro-analyst/internal/attribution"
+	"macro-analyst/internal/config"
+	"macro-analyst/internal/connlimit"
+	"macro-analyst/internal/dashboard"
+	"macro-analyst/internal/embed"
+	"macro-analyst/internal/formula"
+	"macro-analyst/internal/fredstore"
+	"macro-analyst/internal/klines"
+	"macro-analyst/internal/macro"
+	"macro-analyst/internal/nats"
+	"macro-analyst/internal/scheduler"
+	"macro-analyst/internal/secrets"
 	"macro-analyst/internal/server"
-	"macro-analyst/internal/ws"
+	"macro-analyst/internal/sessions"
+	"macro-analyst/internal/share"
+	"macro-analyst/internal/softdelete"
+	"macro-analyst/internal/stats"
+	"macro-analyst/internal/surprise"
+	"macro-analyst/pkg/feedlog"
+	"macro-analyst/pkg/fred"
+	"macro-analyst/pkg/metrics"
+	"macro-analyst/pkg/usage"
+	"macro-analyst/pkg/ws"
 )
 
 const (
-	// DefaultPort is used if PORT environment variable is not set
-	DefaultPort = 8080
-
 	// ShutdownTimeout is the maximum time to wait for graceful shutdown
 	ShutdownTimeout = 5 * time.Second
+
+	// DefaultPort is the HTTP port when PORT is not set
+	DefaultPort = 8080
+
+	// DefaultThrottleInterval is the minimum interval between price
+	// broadcasts when THROTTLE_INTERVAL is not set
+	DefaultThrottleInterval = 500 * time.Millisecond
+
+	// MockDataAuto is the MOCK_DATA value generating mock prices only when
+	// Binance is unreachable at startup
+	MockDataAuto = "auto"
+
+	// MockProbeTimeout bounds the check whether Binance is reachable
+	MockProbeTimeout = 3 * time.Second
+
+	// UsageFlushInterval is how often API usage is persisted
+	UsageFlushInterval = 5 * time.Minute
 )
 
 func main() {
-	// Initialize the WebSocket Hub
-	hub := ws.NewHub()
+	// Settings from CONFIG_FILE, overridden by the environment
+	cfg, err := config.Load(os.Getenv("CONFIG_FILE"), defaultConfig())
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Resolve credentials from env, the config file, secret files, Vault or
+	// SOPS and keep their values out of the logs
+	secretResolver := secrets.New(secrets.WithLookup(cfg.Lookup))
+	log.SetOutput(secretResolver.Writer(os.Stderr))
+
+	// Pre-deploy gate: validate configuration and connectivity, then exit
+	check := flag.Bool("check", false, "validate configuration and upstream connectivity, print a JSON report and exit")
+	flag.Parse()
+	if *check {
+		os.Exit(runCheck(cfg, secretResolver))
+	}
+
+	// Initialize the WebSocket Hub, recording the metrics of the hubs and
+	// feeds to the registry exposed at /metrics
+	wsMetrics := ws.NewMetrics(metrics.Default)
+	hubMetrics, feedMetrics := ws.WithHubMetrics(wsMetrics), ws.WithMetrics(wsMetrics)
+	idleTimeout := ws.WithIdleTimeout(cfg.Hub.IdleTimeout)
+	hub := ws.NewHub(
+		hubMetrics,
+		ws.WithRegion(cfg.Hub.Region),
+		ws.WithMessageTTL(cfg.Hub.MessageTTL),
+		ws.WithBackpressure(cfg.Hub.BackpressureThreshold, cfg.Hub.BackpressureSustain),
+		ws.WithAuditFile(cfg.Hub.AuditFile, int64(cfg.Hub.AuditMaxBytes)),
+		ws.WithBroadcastHistory(cfg.Hub.BroadcastHistory),
+		ws.WithSlowClientPolicy(getSlowClientPolicy(cfg.Hub.SlowClientPolicy)),
+		ws.WithWriteTimeout(cfg.Hub.WriteTimeout),
+		ws.WithMaxMessageAge(cfg.Hub.MaxMessageAge),
+		ws.WithBroadcastBuffer(cfg.Hub.BroadcastBuffer),
+		idleTimeout,
+	)
 	go hub.Run()
 	log.Println("WebSocket Hub started")
 
-	// Initialize the Price Ingestor with custom throttle interval
-	ingestor := ws.NewIngestor(hub,
-		ws.WithThrottleInterval(500*time.Millisecond),
+	// Modules switched on by MODULES, e.g. only crypto for a lean price streamer
+	modules, err := getServerModules(cfg.Server.Modules)
+	if err != nil {
+		log.Fatalf("Invalid MODULES: %v", err)
+	}
+	enabled := func(module server.Module) bool { return slices.Contains(modules, module) }
+	log.Printf("Modules enabled: %v", modules)
+
+	// The stale feed alarm is part of the alerts module
+	staleFeedTimeout := cfg.Feeds.StaleTimeout
+	if !enabled(server.ModuleAlerts) {
+		staleFeedTimeout = 0
+	}
+
+	sched := scheduler.New()
+
+	// Binance feeds of the crypto module
+	var (
+		ingestor    *ws.Ingestor
+		feeds       *ws.FeedSwitch
+		feedHistory *feedlog.Log
+		candleHub   *ws.Hub
+		candles     *ws.Ingestor
+		bookHub     *ws.Hub
+		books       *ws.Ingestor
+		trades      *ws.Ingestor
+		futures     *ws.Ingestor
+		movers      *ws.MoverTracker
+		alerts      *ws.AlertBook
+		fxRates     *ws.FXRates
+		klineCache  *klines.Cache
 	)
+	if enabled(server.ModuleCrypto) {
+		// Binance connection history across restarts, explaining gaps in charts
+		feedHistory = feedlog.New(feedlog.WithFile(cfg.Feeds.HistoryFile))
+		if err := feedHistory.Load(); err != nil {
+			log.Printf("Failed to load feed history: %v", err)
+		}
+
+		// Reconnects to Binance after a failed connect or a dropped stream
+		reconnectBackoff := ws.WithReconnectBackoff(cfg.Reconnect.Backoff)
+		maxReconnects := ws.WithMaxReconnectAttempts(cfg.Reconnect.MaxAttempts)
+
+		// Replay a recording instead of streaming from Binance, e.g. for UI
+		// development and demos
+		replayPath := cfg.Feeds.ReplayPath
+		replay := ws.WithReplay(replayPath, cfg.Feeds.ReplaySpeed, cfg.Feeds.ReplayLoop)
+
+		// Mirror the published market data of every feed to recordings for
+		// replay and to NATS for downstream analytics jobs
+		var sinks []ws.Sink
+		if dir := cfg.Feeds.RecordDir; dir != "" && replayPath == "" {
+			recorder := ws.NewRecorder(dir)
+			defer recorder.Close()
+			sinks = append(sinks, recorder)
+			log.Printf("Recording market data to %s", dir)
+		}
+		if natsURL := getSecret(secretResolver, "NATS_URL"); natsURL != "" {
+			publisher, err := nats.New(natsURL, nats.WithSubject(cfg.Feeds.NATSSubject))
+			if err != nil {
+				log.Fatalf("Invalid NATS configuration: %v", err)
+			}
+			go publisher.Run()
+			defer publisher.Close()
+			sinks = append(sinks, publisher)
+			log.Println("Mirroring market data to NATS")
+		}
+		mirror := ws.WithSink(nil)
+		if len(sinks) > 0 {
+			mirror = ws.WithSink(ws.FanOut(sinks...))
+		}
+
+		// Price alerts of the alerts module, evaluated by the active feed
+		if enabled(server.ModuleAlerts) {
+			alerts = ws.NewAlertBook()
+		}
 
-	// Start the ingestor - connects to Binance WebSocket
-	go ingestor.Start()
-	log.Println("Price Ingestor started - connecting to Binance for real-time data")
+		// Exchange rates of the currencies clients may see prices in,
+		// streamed by the price feeds along with their symbols
+		if pairs := cfg.Feeds.FXPairs; len(pairs) > 0 {
+			rates, err := ws.NewFXRates(pairs)
+			if err != nil {
+				log.Fatalf("Invalid FX_PAIRS: %v", err)
+			}
+			fxRates = rates
+			log.Printf("Converting prices to %v", rates.Currencies())
+		}
+
+		binanceAPIKey := getSecret(secretResolver, "BINANCE_API_KEY")
+
+		// Ingestor options shared by the primary feed and standby feeds
+		// created at runtime through the admin API
+		ingestorOpts := []ws.IngestorOption{
+			ws.WithAlerts(alerts),
+			ws.WithConversionRates(fxRates),
+			feedMetrics,
+			reconnectBackoff,
+			maxReconnects,
+			mirror,
+			replay,
+			ws.WithThrottleInterval(cfg.ThrottleInterval),
+			ws.WithTimestampSource(getTimestampSource(cfg.Feeds.TimestampSource)),
+			ws.WithChangeReference(getChangeReference(cfg.Feeds.ChangeReference, cfg.Feeds.ChangeAnchor)),
+			ws.WithStaleFeedTimeout(staleFeedTimeout),
+			ws.WithStaleFeedWebhook(getSecret(secretResolver, "STALE_FEED_WEBHOOK_URL")),
+			ws.WithSnapshotFile(cfg.Feeds.SnapshotFile),
+			ws.WithFeedHistory(feedHistory),
+			ws.WithMaxPayloadSize(cfg.Feeds.MaxPayloadSize),
+			ws.WithBackpressureThrottleFactor(cfg.Feeds.BackpressureThrottleFactor),
+			ws.WithSignificanceThreshold(getThreshold(cfg.Feeds.SignificanceThreshold), getSymbolThresholds(cfg.Feeds.SymbolThresholds)),
+			ws.WithRecentHistory(cfg.Feeds.RecentWindow, cfg.Feeds.RecentResolution),
+			ws.WithBinanceCredentials(
+				binanceAPIKey,
+				getSecret(secretResolver, "BINANCE_API_SECRET"),
+			),
+		}
+
+		// Initialize the Price Ingestor with the configured symbols, or its
+		// defaults; standby feeds take the symbols they are created with.
+		// It streams from Binance, replays REPLAY_PATH (see above)
+		primaryOpts := ingestorOpts
+		if len(cfg.Symbols) > 0 {
+			primaryOpts = append(slices.Clip(ingestorOpts), ws.WithSymbols(cfg.Symbols))
+		}
+		// Prices of other exchanges aggregated with the primary feed's
+		if names := cfg.Feeds.Exchanges; len(names) > 0 {
+			exchanges, err := ws.ParseExchanges(names)
+			if err != nil {
+				log.Fatalf("Invalid EXCHANGES: %v", err)
+			}
+			primaryOpts = append(slices.Clip(primaryOpts), ws.WithExchanges(exchanges...))
+			log.Printf("Aggregating prices from %v", names)
+		}
+		// or generating mock prices for offline development
+		mockData := replayPath == "" && useMockData(cfg.Feeds.MockData)
+		if mockData {
+			ingestor = ws.NewMockIngestor(ws.HubSink(hub), primaryOpts...)
+		} else {
+			ingestor = ws.NewIngestor(ws.HubSink(hub), primaryOpts...)
+		}
+
+		if ingestor.HasBinanceCredentials() {
+			log.Println("Binance API credentials configured for REST requests")
+		}
+
+		// Cache the symbols trading on Binance, which subscribe commands are
+		// checked against; added symbols load it on demand if this fails
+		if !mockData && replayPath == "" {
+			go loadSymbolCatalog(ingestor)
+		}
+
+		// Register it with the feed switch for blue/green switchover
+		feeds = ws.NewFeedSwitch(hub, ingestorOpts...)
+		if err := feeds.Add(ingestor); err != nil {
+			log.Fatalf("Failed to register ingestor: %v", err)
+		}
+
+		// Restore last-known prices so reconnecting clients see data immediately
+		if err := ingestor.RestoreSnapshot(); err != nil {
+			log.Printf("Failed to restore snapshot: %v", err)
+		}
+
+		// Start the ingestor - connects to Binance WebSocket
+		go ingestor.Start()
+		log.Println("Price Ingestor started - connecting to Binance for real-time data")
+
+		// Keep a warm standby connection, promoted when the primary goes silent
+		if name := cfg.Feeds.StandbyFeed; name != "" {
+			if _, err := feeds.Create(context.Background(), name, ingestor.GetSymbols()); err != nil {
+				log.Printf("Failed to start standby feed %q: %v", name, err)
+			}
+		}
+		if timeout := cfg.Feeds.FailoverTimeout; timeout > 0 {
+			sched.Every("feed-failover", timeout/2, func(ctx context.Context) {
+				feeds.Failover(time.Now(), timeout)
+			})
+		}
+
+		// Stream OHLCV candles of the same symbols to /ws/candles on a hub of
+		// their own, so price clients do not receive them
+		if intervals := getCandleIntervals(cfg.Feeds.CandleIntervals); len(intervals) > 0 && !mockData {
+			candleHub = ws.NewHub(hubMetrics, ws.WithRegion(cfg.Hub.Region), idleTimeout)
+			go candleHub.Run()
+
+			candles = ws.NewIngestor(ws.HubSink(candleHub),
+				ws.WithSourceName("candles"),
+				ws.WithSymbols(ingestor.GetSymbols()),
+				ws.WithCandles(intervals),
+				ws.WithFeedHistory(feedHistory),
+				ws.WithStaleFeedTimeout(staleFeedTimeout),
+				feedMetrics,
+				reconnectBackoff,
+				maxReconnects,
+				mirror,
+				replay,
+			)
+			go candles.Start()
+			log.Printf("Candle Ingestor started for intervals %v", intervals)
+		}
+
+		// Stream the top of the order books to /ws/orderbook, throttled on
+		// its own as depth updates arrive every 100ms
+		if depth := getOrderBookDepth(cfg.Feeds.OrderBookDepth); depth > 0 && !mockData {
+			bookHub = ws.NewHub(hubMetrics, ws.WithRegion(cfg.Hub.Region), idleTimeout)
+			go bookHub.Run()
+
+			books = ws.NewIngestor(ws.HubSink(bookHub),
+				ws.WithSourceName("orderbook"),
+				ws.WithSymbols(ingestor.GetSymbols()),
+				ws.WithOrderBook(depth),
+				ws.WithThrottleInterval(cfg.Feeds.OrderBookInterval),
+				ws.WithFeedHistory(feedHistory),
+				ws.WithStaleFeedTimeout(staleFeedTimeout),
+				feedMetrics,
+				reconnectBackoff,
+				maxReconnects,
+				mirror,
+				replay,
+			)
+			go books.Start()
+			log.Printf("Order Book Ingestor started for %d levels", depth)
+		}
+
+		// Aggregate trades into rolling VWAP and taker volume, sent to price
+		// clients as trade_stats alongside the price updates
+		if window := cfg.Feeds.TradeStatsWindow; window > 0 && !mockData {
+			trades = ws.NewIngestor(ws.HubSink(hub),
+				ws.WithSourceName("trades"),
+				ws.WithSymbols(ingestor.GetSymbols()),
+				ws.WithTrades(window),
+				ws.WithThrottleInterval(cfg.Feeds.TradeStatsInterval),
+				ws.WithFeedHistory(feedHistory),
+				ws.WithStaleFeedTimeout(staleFeedTimeout),
+				feedMetrics,
+				reconnectBackoff,
+				maxReconnects,
+				mirror,
+				replay,
+			)
+			go trades.Start()
+			log.Printf("Trade Ingestor started for a %v window", window)
+		}
+
+		// Stream the mark prices, funding rates and open interest of the
+		// symbols' perpetual futures, sent to price clients alongside the
+		// price updates
+		if cfg.Feeds.Futures && !mockData {
+			futures = ws.NewIngestor(ws.HubSink(hub),
+				ws.WithSourceName("futures"),
+				ws.WithSymbols(ingestor.GetSymbols()),
+				ws.WithFutures(cfg.Feeds.OpenInterestInterval),
+				ws.WithThrottleInterval(cfg.Feeds.FuturesInterval),
+				ws.WithBinanceCredentials(binanceAPIKey, getSecret(secretResolver, "BINANCE_API_SECRET")),
+				ws.WithFeedHistory(feedHistory),
+				ws.WithStaleFeedTimeout(staleFeedTimeout),
+				feedMetrics,
+				reconnectBackoff,
+				maxReconnects,
+				mirror,
+				replay,
+			)
+			go futures.Start()
+			log.Println("Futures Ingestor started")
+		}
+
+		// Reload day opens at UTC midnight for the since-midnight change
+		sched.DailyAt("day-open-rollover", 0, 0, feeds.RefreshDayOpens)
+
+		// Track the Binance USDT pairs with the most volume, re-ranked daily
+		if n := cfg.Feeds.AutoTrackTopN; n > 0 && !mockData && replayPath == "" {
+			trackTop := func(ctx context.Context) {
+				if err := ingestor.TrackTopSymbols(ctx, n); err != nil {
+					log.Printf("Failed to track the top %d symbols: %v", n, err)
+				}
+			}
+			go trackTop(context.Background())
+			sched.DailyAt("top-symbols", 0, 5, trackTop)
+		}
+
+		// Rank all Binance USDT pairs for symbol discovery
+		if interval := cfg.Feeds.MoversInterval; interval > 0 {
+			movers = ws.NewMoverTracker(ingestor.ListTickerStats)
+			refreshMovers := func(ctx context.Context) {
+				if err := movers.Refresh(ctx); err != nil {
+					log.Printf("Failed to refresh top movers: %v", err)
+				}
+			}
+			go refreshMovers(context.Background())
+			sched.Every("movers", interval, refreshMovers)
+		}
+
+		// Historical candles backfilling charts before /ws/candles takes over
+		klineCache = klines.New(klines.Binance(binanceAPIKey),
+			klines.WithTTL(cfg.Feeds.CandlesCacheTTL))
+	}
+
+	// Let clients tell a quiet market from a dead connection
+	if interval := cfg.Hub.HeartbeatInterval; interval > 0 {
+		sched.Every("heartbeat", interval, func(ctx context.Context) {
+			hub.PublishHeartbeat(time.Now())
+		})
+	}
+
+	// Credit data sources as upstream terms require
+	attributor := attribution.New(getModules(cfg.AttributionModules)...)
+	if feeds != nil && attributor.Enabled(attribution.ModuleCrypto) {
+		sched.Every("attribution", cfg.AttributionInterval, func(ctx context.Context) {
+			if active := feeds.Active(); active != nil {
+				attr := attributor.Binance(active.LastEventAt())
+				active.PublishAttribution(attr.Source, attr.Citation, attr.URL, attr.RetrievedAt)
+			}
+		})
+	}
+
+	// Track per-key API usage, persisted periodically and on shutdown
+	usageTracker := usage.NewTracker(usage.WithFile(cfg.UsageFile))
+	if err := usageTracker.Load(); err != nil {
+		log.Printf("Failed to load usage: %v", err)
+	}
+	sched.Every("usage-flush", UsageFlushInterval, func(ctx context.Context) {
+		if err := usageTracker.Save(); err != nil {
+			log.Printf("Failed to save usage: %v", err)
+		}
+	})
+
+	// Sample resource usage for capacity planning
+	statsCollector := stats.NewCollector(hub, feeds, stats.WithHistorySize(cfg.StatsHistorySize))
+	sched.Every("stats", cfg.StatsInterval, func(ctx context.Context) {
+		statsCollector.Record()
+	})
+
+	// Expectations for macro releases, editable through the admin API
+	surpriseStore := surprise.NewStore(
+		surprise.WithFile(cfg.Macro.ExpectationsFile),
+		surprise.WithWindow(cfg.Macro.SurpriseWindow),
+	)
+	if err := surpriseStore.Load(); err != nil {
+		log.Printf("Failed to load expectations: %v", err)
+	}
+
+	// US market hours and FRED release windows; outside them the server is
+	// quiet, FRED polling pauses and heavier jobs run
+	calendar := sessions.New(
+		sessions.WithHolidays(cfg.Macro.MarketHolidays...),
+		sessions.WithReleaseWindow(cfg.Macro.ReleaseWindow),
+	)
+	busy := func(now time.Time) bool { return !calendar.Quiet(now) }
+
+	// FRED series served in addition to the built-in tickers
+	for _, series := range getFREDSeries(cfg.Macro.FREDTickers) {
+		if _, err := fred.DefaultRegistry.Register(series); err != nil {
+			log.Printf("Failed to register FRED series %s: %v", series.Ticker, err)
+		}
+	}
 
 	// Initialize the HTTP/WebSocket server with FRED API key
-	fredAPIKey := os.Getenv("FRED_API_KEY")
-	if fredAPIKey != "" {
+	fredAPIKey := getSecret(secretResolver, "FRED_API_KEY")
+	switch {
+	case !enabled(server.ModuleFRED):
+		log.Println("FRED module disabled")
+	case fredAPIKey != "":
 		log.Println("FRED API client initialized")
-	} else {
+	default:
 		log.Println("⚠ FRED_API_KEY not set - FRED endpoints will be unavailable")
 	}
 
-	srv := server.New(hub, server.Config{
-		FREDAPIKey: fredAPIKey,
-	})
+	cfg.Server.FREDAPIKey = fredAPIKey
+	cfg.Server.AdminToken = getSecret(secretResolver, "ADMIN_TOKEN")
+	cfg.Server.APIKeys = config.List(getSecret(secretResolver, "API_KEYS"))
+	cfg.Server.JWTSigningKey = getSecret(secretResolver, "JWT_SIGNING_KEY")
+	srv := server.New(hub, cfg.Server)
+	srv.Usage = usageTracker
+	srv.Attribution = attributor
+	srv.Stats = statsCollector
+	srv.Formulas = softdelete.New[formula.Formula]()
+	srv.Dashboards = softdelete.New[dashboard.Dashboard]()
+	srv.Shares = share.NewSigner(getShareSecret(secretResolver))
+	srv.Sessions = calendar
+	srv.PollWait = cfg.Server.PollWait
+	srv.ConnLimiter = connlimit.New(
+		connlimit.WithMaxConnections(cfg.Server.MaxConnections),
+		connlimit.WithMaxPerIP(cfg.Server.MaxConnectionsPerIP),
+		connlimit.WithUpgradesPerMinute(cfg.Server.UpgradesPerMinute),
+	)
+	if enabled(server.ModuleCrypto) {
+		srv.Feeds = feeds
+		srv.CryptoHistory = cryptoHistory(ingestor)
+		srv.Embeds = softdelete.New[embed.Widget]()
+		srv.EmbedByteCap = int64(cfg.Server.EmbedHourlyByteCap)
+		srv.CandleHub = candleHub
+		srv.OrderBookHub = bookHub
+		srv.FeedHistory = feedHistory
+		srv.Movers = movers
+		srv.Klines = klineCache
+		srv.Preferences = ws.NewPreferenceStore()
+		srv.Alerts = alerts
+		srv.FXRates = fxRates
+		srv.RawProxy = ws.NewRawProxy(
+			ws.WithMaxRawStreamsPerUser(cfg.Server.RawStreamsPerUser),
+			ws.WithRawRegion(cfg.Hub.Region),
+		)
+	}
+	if enabled(server.ModuleAnalytics) || enabled(server.ModuleAlerts) {
+		// Surprise index for analytics, expectations of notices for alerts
+		srv.Surprises = surpriseStore
+	}
+	if enabled(server.ModuleAnalytics) && srv.FREDClient != nil {
+		// Keep the FRED history net liquidity and the analytics routes
+		// read, backfilled on start so they have data on a fresh install
+		history := fredstore.New(srv.FREDClient, fredstore.WithFile(cfg.Macro.FREDHistoryFile))
+		if err := history.Load(); err != nil {
+			log.Printf("Failed to load FRED history: %v", err)
+		}
+		srv.FREDHistory = history
+		if period := cfg.Macro.FREDSeedPeriod; period > 0 {
+			go history.Seed(context.Background(), srv.Tickers.Tickers(), time.Now().Add(-period))
+		}
+	}
+	if enabled(server.ModuleAnalytics) {
+		srv.FormulaEngine = formula.NewEngine(srv.FREDClient, func(symbol string) (float64, bool) {
+			return activePrice(feeds, symbol)
+		})
+	}
 	srv.RegisterFiberRoutes()
 
+	// Push the surprise of each release with an expectation once FRED
+	// publishes it; no release is expected during quiet windows
+	interval := cfg.Macro.SurpriseCheckInterval
+	if enabled(server.ModuleAlerts) && srv.FREDClient != nil && interval > 0 {
+		sched.Every("macro-surprise", interval, sched.When(busy, func(ctx context.Context) {
+			publishSurprises(ctx, hub, surpriseStore, srv.FREDClient)
+		}))
+	}
+
+	// Push Fed net liquidity whenever one of its inputs is published or
+	// revised; FRED is not polled during quiet windows
+	if interval := cfg.Macro.NetLiquidityInterval; enabled(server.ModuleAnalytics) && srv.FREDClient != nil && interval > 0 {
+		monitor := macro.NewMonitor(srv.FREDClient)
+		sched.Every("net-liquidity", interval, sched.When(busy, func(ctx context.Context) {
+			publishNetLiquidity(ctx, hub, monitor)
+		}))
+	}
+
+	// Push new observations of the registered FRED tickers; each ticker is
+	// checked as often as its frequency warrants
+	if interval := cfg.Macro.FREDPollInterval; enabled(server.ModuleFRED) && srv.FREDClient != nil && interval > 0 {
+		publish := func(message []byte) bool { return hub.PublishTopic(ws.TopicMacro, message) }
+		poller := fred.NewPoller(srv.FREDClient, publish, fred.WithPollerRegistry(srv.Tickers))
+		sched.Every("fred-poll", interval, sched.When(busy, func(ctx context.Context) {
+			if _, err := poller.Poll(ctx); err != nil {
+				log.Printf("Failed to poll FRED: %v", err)
+			}
+		}))
+	}
+
+	// Recompute user-defined formulas and stream the values that changed
+	if interval := cfg.Macro.FormulaInterval; srv.FormulaEngine != nil && interval > 0 {
+		sched.Every("formulas", interval, func(ctx context.Context) {
+			publishFormulas(ctx, hub, srv.FormulaEngine, srv.Formulas.List())
+		})
+	}
+
+	// Purge expired trash during quiet windows
+	schedulePurges(sched, srv, calendar.Quiet)
+
 	// Start the server in a goroutine
-	port := getPort()
-	go startServer(srv, port)
+	go startServer(srv, cfg.Port)
 
 	// Wait for shutdown signal and perform graceful shutdown
-	waitForShutdown(srv, ingestor)
+	waitForShutdown(srv, feeds, []*ws.Ingestor{candles, books, trades, futures}, sched)
+}
+
+// publishSurprises records newly published releases and pushes their
+// surprise to WebSocket clients.
+func publishSurprises(ctx context.Context, hub *ws.Hub, store *surprise.Store, client fred.Client) {
+	releases := store.Check(ctx, client)
+	if len(releases) == 0 {
+		return
+	}
+
+	index := store.Index()
+	for _, release := range releases {
+		notice, err := surprise.NewNotice(release, index)
+		if err != nil {
+			log.Printf("Error marshaling surprise notice: %v", err)
+			continue
+		}
+		hub.PublishTopic(ws.TopicMacro, notice)
+	}
+}
+
+// publishNetLiquidity pushes net liquidity to WebSocket clients when it
+// changed since the last check.
+func publishNetLiquidity(ctx context.Context, hub *ws.Hub, monitor *macro.Monitor) {
+	point, changed, err := monitor.Check(ctx)
+	if err != nil {
+		log.Printf("Failed to check net liquidity: %v", err)
+		return
+	}
+	if !changed {
+		return
+	}
+
+	update, err := macro.NewUpdate(point, time.Now())
+	if err != nil {
+		log.Printf("Error marshaling net liquidity update: %v", err)
+		return
+	}
+	hub.PublishTopic(ws.TopicMacro, update)
+}
+
+// publishFormulas pushes the formula values that changed since the last run
+// to WebSocket clients.
+func publishFormulas(ctx context.Context, hub *ws.Hub, engine *formula.Engine, formulas []formula.Formula) {
+	values := engine.Evaluate(ctx, formulas)
+	if len(values) == 0 {
+		return
+	}
+
+	update, err := formula.NewUpdate(values, time.Now())
+	if err != nil {
+		log.Printf("Error marshaling formula update: %v", err)
+		return
+	}
+	hub.PublishTopic(ws.TopicFormulas, update)
+}
+
+// schedulePurges purges the expired trash of the server's stores every hour
+// while quiet holds. Embeds only exist with the crypto module.
+func schedulePurges(sched *scheduler.Scheduler, srv *server.FiberServer, quiet func(now time.Time) bool) {
+	sched.Every("purge-formulas", time.Hour, sched.When(quiet, func(ctx context.Context) {
+		srv.Formulas.Purge(time.Now())
+	}))
+	sched.Every("purge-dashboards", time.Hour, sched.When(quiet, func(ctx context.Context) {
+		srv.Dashboards.Purge(time.Now())
+	}))
+	if srv.Embeds != nil {
+		sched.Every("purge-embeds", time.Hour, sched.When(quiet, func(ctx context.Context) {
+			srv.Embeds.Purge(time.Now())
+		}))
+	}
 }
 
-// getPort retrieves the port number from environment variable or returns default.
-func getPort() int {
-	portStr := os.Getenv("PORT")
-	if portStr == "" {
-		return DefaultPort
+// loadSymbolCatalog loads the catalog of symbols trading on Binance.
+func loadSymbolCatalog(ingestor *ws.Ingestor) {
+	ctx, cancel := context.WithTimeout(context.Background(), ws.BinanceRESTTimeout)
+	defer cancel()
+
+	count, err := ingestor.LoadSymbolCatalog(ctx)
+	if err != nil {
+		log.Printf("⚠ Failed to load Binance symbols: %v", err)
+		return
 	}
+	log.Printf("Loaded %d symbols trading on Binance", count)
+}
 
-	port, err := strconv.Atoi(portStr)
+// activePrice returns the live price of a symbol tracked by the active feed,
+// if the crypto module is enabled.
+func activePrice(feeds *ws.FeedSwitch, symbol string) (float64, bool) {
+	if feeds == nil {
+		return 0, false
+	}
+	active := feeds.Active()
+	if active == nil {
+		return 0, false
+	}
+	price, err := active.GetCurrentPrice(symbol)
+	if err != nil {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(price, 64)
+	return value, err == nil
+}
+
+// getSecret resolves a credential, exiting if a configured source fails so
+// the server never starts with a silently missing secret.
+func getSecret(resolver *secrets.Resolver, key string) string {
+	value, err := resolver.Get(key)
 	if err != nil {
-		log.Printf("Invalid PORT value '%s', using default %d", portStr, DefaultPort)
-		return DefaultPort
+		log.Fatalf("Failed to load secret: %v", err)
 	}
+	return value
+}
 
-	return port
+// getShareSecret retrieves the secret signing share links from SHARE_SECRET,
+// falling back to a random secret that invalidates links on restart.
+func getShareSecret(resolver *secrets.Resolver) []byte {
+	if secret := getSecret(resolver, "SHARE_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+	log.Println("⚠ SHARE_SECRET not set - share links will not survive a restart")
+	return share.NewRandomSecret()
+}
+
+// defaultConfig returns the settings used where neither the config file
+// nor the environment sets them.
+func defaultConfig() config.Config {
+	attributionModules := make([]string, len(attribution.Modules))
+	for idx, module := range attribution.Modules {
+		attributionModules[idx] = string(module)
+	}
+
+	return config.Config{
+		Port:             DefaultPort,
+		ThrottleInterval: DefaultThrottleInterval,
+		Reconnect:        config.ReconnectPolicy{Backoff: ws.DefaultReconnectBackoff},
+		Hub: config.HubConfig{
+			MessageTTL:            ws.DefaultMessageTTL,
+			BackpressureThreshold: ws.DefaultBackpressureThreshold,
+			BackpressureSustain:   ws.DefaultBackpressureSustain,
+			AuditMaxBytes:         ws.DefaultAuditMaxBytes,
+			BroadcastHistory:      ws.DefaultBroadcastHistory,
+			BroadcastBuffer:       ws.BroadcastBufferSize,
+			WriteTimeout:          ws.DefaultWriteTimeout,
+			MaxMessageAge:         ws.DefaultMaxMessageAge,
+			IdleTimeout:           ws.DefaultIdleTimeout,
+			HeartbeatInterval:     ws.DefaultHeartbeatInterval,
+		},
+		Feeds: config.FeedConfig{
+			StaleTimeout:               ws.DefaultStaleFeedTimeout,
+			FailoverTimeout:            ws.DefaultFailoverTimeout,
+			ReplaySpeed:                1,
+			ReplayLoop:                 true,
+			MaxPayloadSize:             ws.DefaultMaxPayloadSize,
+			BackpressureThrottleFactor: ws.DefaultBackpressureThrottleFactor,
+			RecentWindow:               ws.DefaultRecentWindow,
+			RecentResolution:           ws.DefaultRecentResolution,
+			CandleIntervals:            ws.DefaultCandleIntervals,
+			CandlesCacheTTL:            klines.DefaultTTL,
+			OrderBookInterval:          ws.DefaultOrderBookInterval,
+			TradeStatsInterval:         ws.DefaultTradeInterval,
+			FuturesInterval:            ws.DefaultFuturesInterval,
+			OpenInterestInterval:       ws.DefaultOpenInterestInterval,
+			MoversInterval:             ws.DefaultMoversInterval,
+		},
+		Server: config.ServerConfig{
+			CORSOrigins:         []string{"*"},
+			SendBufferSize:      server.ClientSendBufferSize,
+			PollWait:            server.DefaultPollWait,
+			MaxConnections:      connlimit.DefaultMaxConnections,
+			MaxConnectionsPerIP: connlimit.DefaultMaxPerIP,
+			UpgradesPerMinute:   connlimit.DefaultUpgradesPerMinute,
+			RawStreamsPerUser:   ws.DefaultMaxRawStreamsPerUser,
+		},
+		Macro: config.MacroConfig{
+			FREDPollInterval:      fred.DefaultPollInterval,
+			NetLiquidityInterval:  macro.DefaultCheckInterval,
+			FormulaInterval:       formula.DefaultInterval,
+			FREDSeedPeriod:        fredstore.DefaultSeedPeriod,
+			SurpriseWindow:        surprise.DefaultWindow,
+			SurpriseCheckInterval: surprise.DefaultCheckInterval,
+			ReleaseWindow:         sessions.DefaultReleaseWindow,
+		},
+		AttributionModules:  attributionModules,
+		AttributionInterval: attribution.DefaultInterval,
+		StatsInterval:       stats.DefaultInterval,
+		StatsHistorySize:    stats.DefaultHistorySize,
+	}
+}
+
+// getServerModules returns the server modules named by MODULES, all of
+// them when it names none.
+func getServerModules(names []string) ([]server.Module, error) {
+	if len(names) == 0 {
+		return server.Modules, nil
+	}
+	return server.ParseModules(names)
+}
+
+// getModules returns the data modules that carry source attribution, named
+// by ATTRIBUTION_MODULES.
+func getModules(names []string) []attribution.Module {
+	modules := make([]attribution.Module, 0, len(names))
+	for _, name := range names {
+		modules = append(modules, attribution.Module(name))
+	}
+	return modules
+}
+
+// getCandleIntervals returns the kline intervals streamed to /ws/candles,
+// named by CANDLE_INTERVALS. None disables candles.
+func getCandleIntervals(names []string) []string {
+	intervals, err := ws.ParseCandleIntervals(names)
+	if err != nil {
+		log.Printf("%v, using default %v", err, ws.DefaultCandleIntervals)
+		return ws.DefaultCandleIntervals
+	}
+
+	return intervals
+}
+
+// getOrderBookDepth returns the bid and ask levels streamed to
+// /ws/orderbook from ORDERBOOK_DEPTH (5, 10 or 20). Order books are off
+// when it is unset.
+func getOrderBookDepth(value string) int {
+	if value == "" {
+		return 0
+	}
+
+	depth, err := ws.ParseOrderBookDepth(value)
+	if err != nil {
+		log.Printf("%v, using default %d", err, ws.DefaultOrderBookDepth)
+		return ws.DefaultOrderBookDepth
+	}
+
+	return depth
+}
+
+// getTimestampSource returns which clock fills price update timestamps,
+// set by TIMESTAMP_SOURCE ("received" or "event").
+func getTimestampSource(value string) ws.TimestampSource {
+	if value == "" {
+		return ws.TimestampSourceReceived
+	}
+
+	source, err := ws.ParseTimestampSource(value)
+	if err != nil {
+		log.Printf("%v, using default %s", err, ws.TimestampSourceReceived)
+		return ws.TimestampSourceReceived
+	}
+
+	return source
+}
+
+// getChangeReference returns what price changes are measured from, set by
+// CHANGE_REFERENCE, Binance's 24h window by default, and the time of the
+// "anchor" reference set by CHANGE_ANCHOR (RFC3339).
+func getChangeReference(value, anchorValue string) (ws.ChangeReference, time.Time) {
+	if value == "" {
+		return ws.ChangeReference24h, time.Time{}
+	}
+
+	reference, err := ws.ParseChangeReference(value)
+	if err != nil {
+		log.Printf("%v, using default %s", err, ws.ChangeReference24h)
+		return ws.ChangeReference24h, time.Time{}
+	}
+	if reference != ws.ChangeReferenceAnchor {
+		return reference, time.Time{}
+	}
+
+	anchor, err := time.Parse(time.RFC3339, anchorValue)
+	if err != nil {
+		log.Printf("Invalid CHANGE_ANCHOR '%s', using default %s", anchorValue, ws.ChangeReference24h)
+		return ws.ChangeReference24h, time.Time{}
+	}
+
+	return reference, anchor
+}
+
+// useMockData reports whether the price feed generates mock prices instead
+// of streaming from Binance: with MOCK_DATA=true, or with MOCK_DATA=auto
+// while Binance is unreachable. Mock data covers prices only, so the candle,
+// order book, trade and futures feeds are not started.
+func useMockData(value string) bool {
+	if value == "" {
+		return false
+	}
+	if value == MockDataAuto {
+		if ws.BinanceReachable(MockProbeTimeout) {
+			return false
+		}
+		log.Println("⚠ Binance is unreachable, generating mock prices")
+		return true
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid MOCK_DATA %q, streaming from Binance", value)
+		return false
+	}
+	if enabled {
+		log.Println("Generating mock prices instead of streaming from Binance")
+	}
+	return enabled
+}
+
+// getSlowClientPolicy returns what happens to broadcasts for clients with
+// a full send buffer, set by HUB_SLOW_CLIENT_POLICY, disconnecting them by
+// default.
+func getSlowClientPolicy(value string) ws.SlowClientPolicy {
+	if value == "" {
+		return ws.SlowClientDisconnect
+	}
+
+	policy, err := ws.ParseSlowClientPolicy(value)
+	if err != nil {
+		log.Printf("%v, using default %s", err, ws.SlowClientDisconnect)
+		return ws.SlowClientDisconnect
+	}
+
+	return policy
+}
+
+// getThreshold returns the minimum price move before a symbol is
+// broadcast, set by SIGNIFICANCE_THRESHOLD (e.g. "0.5" or "5bps").
+func getThreshold(value string) ws.Threshold {
+	if value == "" {
+		return ws.Threshold{}
+	}
+
+	threshold, err := ws.ParseThreshold(value)
+	if err != nil {
+		log.Printf("%v, broadcasting every move", err)
+		return ws.Threshold{}
+	}
+
+	return threshold
+}
+
+// getSymbolThresholds returns per-symbol overrides of the significance
+// threshold, set by SYMBOL_SIGNIFICANCE_THRESHOLDS (e.g. "BTCUSDT=10,USDCUSDT=1bps").
+func getSymbolThresholds(values []string) map[string]ws.Threshold {
+	thresholds, err := ws.ParseSymbolThresholds(values)
+	if err != nil {
+		log.Printf("%v, ignoring SYMBOL_SIGNIFICANCE_THRESHOLDS", err)
+		return nil
+	}
+
+	return thresholds
+}
+
+// getFREDSeries returns the FRED series to serve in addition to the
+// built-in tickers, set by FRED_TICKERS (e.g. "UNRATE|Unemployment Rate|Percent").
+func getFREDSeries(value string) []fred.Series {
+	series, err := fred.ParseSeriesList(value)
+	if err != nil {
+		log.Printf("%v, ignoring FRED_TICKERS", err)
+		return nil
+	}
+
+	return series
+}
+
+// cryptoHistory returns the daily closes of a feed as analytics points.
+func cryptoHistory(ingestor *ws.Ingestor) analytics.CryptoHistory {
+	return func(ctx context.Context, symbol string, start, end time.Time) ([]analytics.Point, error) {
+		closes, err := ingestor.DailyCloses(ctx, symbol, start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		points := make([]analytics.Point, len(closes))
+		for idx, daily := range closes {
+			points[idx] = analytics.Point{Time: daily.Time, Value: daily.Close}
+		}
+		return points, nil
+	}
 }
 
 // startServer starts the HTTP/WebSocket server on the specified port.
 func startServer(srv *server.FiberServer, port int) {
 	log.Printf("Server starting on port %d", port)
 	log.Printf("WebSocket endpoint: ws://localhost:%d/ws/prices", port)
+	if srv.CandleHub != nil {
+		log.Printf("Candle endpoint: ws://localhost:%d/ws/candles", port)
+	}
+	if srv.OrderBookHub != nil {
+		log.Printf("Order book endpoint: ws://localhost:%d/ws/orderbook", port)
+	}
 	log.Printf("Health check: http://localhost:%d/health", port)
+	log.Printf("Metrics: http://localhost:%d/metrics", port)
 	log.Printf("FRED API endpoints:")
 	log.Printf("  - GET /api/v1/fred/tickers (list all available tickers)")
 	log.Printf("  - GET /api/v1/fred/latest (get all latest values)")
@@ -95,7 +978,7 @@ func startServer(srv *server.FiberServer, port int) {
 
 // waitForShutdown blocks until an interrupt signal is received,
 // then performs a graceful shutdown of the server.
-func waitForShutdown(srv *server.FiberServer, ingestor *ws.Ingestor) {
+func waitForShutdown(srv *server.FiberServer, feeds *ws.FeedSwitch, streams []*ws.Ingestor, sched *scheduler.Scheduler) {
 	// Create a channel to listen for interrupt signals
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -104,9 +987,28 @@ func waitForShutdown(srv *server.FiberServer, ingestor *ws.Ingestor) {
 	sig := <-quit
 	log.Printf("Received signal %v, shutting down gracefully...", sig)
 
-	// Stop the ingestor first
-	if ingestor != nil {
-		ingestor.Stop()
+	// Stop scheduled jobs and the ingestors first
+	sched.Stop()
+	for _, stream := range streams {
+		if stream != nil {
+			stream.Stop()
+		}
+	}
+	if feeds != nil {
+		feeds.StopAll()
+
+		// Persist last-known prices of the feed serving clients for the next boot
+		if active := feeds.Active(); active != nil {
+			if err := active.SaveSnapshot(); err != nil {
+				log.Printf("Failed to save snapshot: %v", err)
+			}
+		}
+	}
+
+	if srv.Usage != nil {
+		if err := srv.Usage.Save(); err != nil {
+			log.Printf("Failed to save usage: %v", err)
+		}
 	}
 
 	// Create a context with timeout for shutdown
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"macro-analyst/internal/dashboard"
+	"macro-analyst/internal/formula"
+	"macro-analyst/internal/scheduler"
+	"macro-analyst/internal/server"
+	"macro-analyst/internal/softdelete"
+	"macro-analyst/pkg/clock"
+	"macro-analyst/pkg/ws"
+)
+
+// TestSchedulePurgesWithoutCrypto verifies the purge jobs run without the
+// stores of the crypto module, as wired by main with MODULES=fred.
+func TestSchedulePurgesWithoutCrypto(t *testing.T) {
+	srv := server.New(ws.NewHub())
+	srv.Formulas = softdelete.New[formula.Formula]()
+	srv.Dashboards = softdelete.New[dashboard.Dashboard]()
+
+	clk := clock.NewFake(time.Date(2024, 1, 13, 12, 0, 0, 0, time.UTC))
+	sched := scheduler.New(scheduler.WithClock(clk))
+	runs := make(chan struct{}, 3)
+	schedulePurges(sched, srv, func(now time.Time) bool {
+		runs <- struct{}{}
+		return true
+	})
+
+	clk.BlockUntil(2)
+	clk.Advance(time.Hour)
+	for n := 0; n < 2; n++ {
+		<-runs
+	}
+	// Stop waits for the purges, which would panic on the missing embeds
+	sched.Stop()
+	if len(runs) != 0 {
+		t.Errorf("Expected 2 purge jobs, got %d", 2+len(runs))
+	}
+}
@@ -0,0 +1,197 @@
+// Command tail connects to a running server's WebSocket endpoint and prints
+// the price stream, for ops debugging and scripting.
+//
+// Usage:
+//
+//	go run ./cmd/tail --symbols BTCUSDT,ETHUSDT
+//	go run ./cmd/tail --types feed_status,data_quality
+//	go run ./cmd/tail --json | jq .price
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/gorilla/websocket"
+
+	"macro-analyst/pkg/ws"
+)
+
+const (
+	// DefaultURL is the price stream of a locally running server
+	DefaultURL = "ws://localhost:8080/ws/prices"
+
+	// PriceUpdateType is the type of each symbol update in a multi_update,
+	// matching the NDJSON stream
+	PriceUpdateType = "price_update"
+)
+
+// filter selects which messages and symbols are printed.
+type filter struct {
+	symbols map[string]bool // Empty means all symbols
+	types   map[string]bool // Empty means all message types
+}
+
+// newFilter builds a filter from comma-separated flag values.
+func newFilter(symbols, types string) filter {
+	return filter{
+		symbols: toSet(strings.ToUpper(symbols)),
+		types:   toSet(types),
+	}
+}
+
+// toSet splits a comma-separated list into a set of its non-empty items.
+func toSet(list string) map[string]bool {
+	set := make(map[string]bool)
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			set[item] = true
+		}
+	}
+	return set
+}
+
+func (f filter) allowsType(messageType string) bool {
+	return len(f.types) == 0 || f.types[messageType]
+}
+
+func (f filter) allowsSymbol(symbol string) bool {
+	return len(f.symbols) == 0 || f.symbols[symbol]
+}
+
+// printer writes messages that pass the filter.
+type printer struct {
+	filter filter
+	json   bool
+	out    io.Writer
+}
+
+// print renders one message from the server. A multi_update is split into
+// one price_update per symbol so both filters apply to each symbol.
+func (p *printer) print(message []byte) error {
+	var envelope struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(message, &envelope); err != nil {
+		return fmt.Errorf("invalid message: %w", err)
+	}
+
+	if envelope.Type != "multi_update" {
+		if !p.filter.allowsType(envelope.Type) {
+			return nil
+		}
+		_, err := fmt.Fprintf(p.out, "%s\n", p.render(envelope.Type, message))
+		return err
+	}
+
+	if !p.filter.allowsType(PriceUpdateType) && !p.filter.allowsType("multi_update") {
+		return nil
+	}
+
+	var update ws.MultiUpdate
+	if err := json.Unmarshal(message, &update); err != nil {
+		return fmt.Errorf("invalid multi_update: %w", err)
+	}
+
+	for _, priceUpdate := range update.Data {
+		if !p.filter.allowsSymbol(priceUpdate.Symbol) {
+			continue
+		}
+		if _, err := fmt.Fprintln(p.out, p.renderPrice(priceUpdate)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderPrice formats a single symbol update.
+func (p *printer) renderPrice(update *ws.PriceUpdate) string {
+	if p.json {
+		line, _ := json.Marshal(struct {
+			Type string `json:"type"`
+			*ws.PriceUpdate
+		}{PriceUpdateType, update})
+		return string(line)
+	}
+
+	line := fmt.Sprintf("%s  %-10s %14.4f  %+8.2f%%  vol %d",
+		update.Timestamp, update.Symbol, update.Price, update.ChangePercent, update.Volume)
+	if update.Restored {
+		line += "  (restored)"
+	}
+	return line
+}
+
+// render formats any other message type.
+func (p *printer) render(messageType string, message []byte) string {
+	if p.json {
+		return strings.TrimSpace(string(message))
+	}
+	return fmt.Sprintf("[%s] %s", messageType, strings.TrimSpace(string(message)))
+}
+
+func main() {
+	serverURL := flag.String("url", DefaultURL, "WebSocket endpoint of the server")
+	symbols := flag.String("symbols", "", "comma-separated symbols to print (default all)")
+	types := flag.String("types", "", "comma-separated message types to print, e.g. price_update,feed_status (default all)")
+	jsonOutput := flag.Bool("json", false, "print one JSON object per line instead of formatted text")
+	apiKey := flag.String("api-key", os.Getenv("API_KEY"), "API key for usage accounting (default $API_KEY)")
+	flag.Parse()
+
+	endpoint, err := url.Parse(*serverURL)
+	if err != nil {
+		log.Fatalf("Invalid URL %q: %v", *serverURL, err)
+	}
+	if *apiKey != "" {
+		query := endpoint.Query()
+		query.Set("api_key", *apiKey)
+		endpoint.RawQuery = query.Encode()
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(endpoint.String(), nil)
+	if err != nil {
+		log.Fatalf("Failed to connect to %s: %v", *serverURL, err)
+	}
+	defer conn.Close()
+
+	// Close the connection on Ctrl-C so the read loop ends
+	var stopping atomic.Bool
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-quit
+		stopping.Store(true)
+		conn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		conn.Close()
+	}()
+
+	p := &printer{
+		filter: newFilter(*symbols, *types),
+		json:   *jsonOutput,
+		out:    os.Stdout,
+	}
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if stopping.Load() || websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				return
+			}
+			log.Fatalf("Connection closed: %v", err)
+		}
+
+		if err := p.print(message); err != nil {
+			log.Printf("Skipping message: %v", err)
+		}
+	}
+}
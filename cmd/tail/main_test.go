@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const testMultiUpdate = `{"type":"multi_update","data":[` +
+	`{"symbol":"BTCUSDT","price":94250.5,"changePercent":0.13,"timestamp":"14:23:45.123"},` +
+	`{"symbol":"ETHUSDT","price":2635.8,"changePercent":-1.2,"timestamp":"14:23:45.123"}]}`
+
+// TestPrintFiltersSymbols verifies multi_updates are split and filtered per symbol.
+func TestPrintFiltersSymbols(t *testing.T) {
+	var out bytes.Buffer
+	p := &printer{filter: newFilter("btcusdt", ""), out: &out}
+
+	if err := p.print([]byte(testMultiUpdate)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], "BTCUSDT") || !strings.Contains(lines[0], "+0.13%") {
+		t.Errorf("Unexpected output: %q", out.String())
+	}
+}
+
+// TestPrintFiltersTypes verifies message type filtering.
+func TestPrintFiltersTypes(t *testing.T) {
+	var out bytes.Buffer
+	p := &printer{filter: newFilter("", "feed_status"), out: &out}
+
+	p.print([]byte(testMultiUpdate))
+	p.print([]byte(`{"type":"feed_status","status":"stale"}`))
+	p.print([]byte(`{"type":"attribution","source":"Binance"}`))
+
+	if got := strings.TrimSpace(out.String()); got != `[feed_status] {"type":"feed_status","status":"stale"}` {
+		t.Errorf("Unexpected output: %q", got)
+	}
+}
+
+// TestPrintJSON verifies JSON output emits one price_update per line.
+func TestPrintJSON(t *testing.T) {
+	var out bytes.Buffer
+	p := &printer{filter: newFilter("", PriceUpdateType), json: true, out: &out}
+
+	if err := p.print([]byte(testMultiUpdate)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], `{"type":"price_update","symbol":"BTCUSDT"`) {
+		t.Errorf("Unexpected output: %q", out.String())
+	}
+}
+
+// TestPrintInvalidMessage verifies malformed messages are reported.
+func TestPrintInvalidMessage(t *testing.T) {
+	p := &printer{filter: newFilter("", ""), out: &bytes.Buffer{}}
+
+	if err := p.print([]byte("not json")); err == nil {
+		t.Error("Expected error for invalid message")
+	}
+}
@@ -0,0 +1,137 @@
+package analytics
+
+import (
+	"math"
+	"time"
+)
+
+// TradingDaysPerYear annualizes daily crypto statistics; crypto trades
+// every day of the year.
+const TradingDaysPerYear = 365
+
+// DefaultReturnWindows are the trailing windows, in days, returns and
+// Sharpe ratios are computed over.
+var DefaultReturnWindows = []int{30, 90, 365}
+
+// WindowStats holds the return statistics of one trailing window. Fields
+// are nil when the series does not cover the whole window.
+type WindowStats struct {
+	Days  int    `json:"days"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+
+	ReturnPercent       *float64 `json:"return_percent"`         // Price change over the window
+	VolatilityPercent   *float64 `json:"volatility_percent"`     // Annualized standard deviation of daily returns
+	Sharpe              *float64 `json:"sharpe"`                 // Annualized excess return per unit of volatility
+	RiskFreeRatePercent *float64 `json:"risk_free_rate_percent"` // Average annual risk-free rate over the window
+}
+
+// Returns computes the trailing return, annualized volatility and Sharpe
+// ratio of a daily price series for each window, ending at its latest
+// observation. The risk-free rate is an annual percentage series such as
+// FEDFUNDS, forward-filled onto each day; days without a rate count as 0%.
+func Returns(prices, riskFree Series, windows []int) []WindowStats {
+	stats := make([]WindowStats, len(windows))
+	if len(prices.Points) == 0 {
+		for idx, days := range windows {
+			stats[idx] = WindowStats{Days: days}
+		}
+		return stats
+	}
+
+	longest := 0
+	for _, days := range windows {
+		longest = max(longest, days)
+	}
+
+	end := truncateDay(prices.Points[0].Time)
+	for _, point := range prices.Points {
+		if point.Time.After(end) {
+			end = truncateDay(point.Time)
+		}
+	}
+	dates, err := Grid(GridDaily, end.AddDate(0, 0, -longest), end)
+	if err != nil {
+		return stats
+	}
+	aligned := Align(GridDaily, dates, []Series{prices, riskFree})
+
+	for idx, days := range windows {
+		// days+1 closes span days daily returns
+		first := len(dates) - 1 - days
+		stats[idx] = windowStats(dates[first:], aligned.Series[0].Values[first:], aligned.Series[1].Values[first:])
+		stats[idx].Days = days
+	}
+	return stats
+}
+
+// windowStats computes the statistics of one window of daily closes and
+// risk-free rates (annual percent).
+func windowStats(dates []time.Time, closes, rates []*float64) WindowStats {
+	stats := WindowStats{
+		Start: dates[0].Format(DateLayout),
+		End:   dates[len(dates)-1].Format(DateLayout),
+	}
+
+	var rateSum float64
+	for _, rate := range rates {
+		if rate != nil {
+			rateSum += *rate
+		}
+	}
+	stats.RiskFreeRatePercent = float64Ptr(rateSum / float64(len(rates)))
+
+	firstClose, lastClose := closes[0], closes[len(closes)-1]
+	if firstClose == nil || lastClose == nil || *firstClose == 0 {
+		return stats
+	}
+	stats.ReturnPercent = float64Ptr((*lastClose/(*firstClose) - 1) * 100)
+
+	var returns, excess []float64
+	for day := 1; day < len(closes); day++ {
+		if closes[day] == nil || closes[day-1] == nil || *closes[day-1] == 0 {
+			continue
+		}
+		dailyReturn := *closes[day]/(*closes[day-1]) - 1
+		dailyRate := 0.0
+		if rates[day] != nil {
+			dailyRate = *rates[day] / 100 / TradingDaysPerYear
+		}
+		returns = append(returns, dailyReturn)
+		excess = append(excess, dailyReturn-dailyRate)
+	}
+	if len(returns) < 2 {
+		return stats
+	}
+
+	deviation := StdDev(returns)
+	annualization := math.Sqrt(TradingDaysPerYear)
+	stats.VolatilityPercent = float64Ptr(deviation * annualization * 100)
+	if deviation > 0 {
+		stats.Sharpe = float64Ptr(mean(excess) / deviation * annualization)
+	}
+	return stats
+}
+
+// mean returns the arithmetic mean of values.
+func mean(values []float64) float64 {
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+// StdDev returns the sample standard deviation of values.
+func StdDev(values []float64) float64 {
+	avg := mean(values)
+	var squares float64
+	for _, value := range values {
+		squares += (value - avg) * (value - avg)
+	}
+	return math.Sqrt(squares / float64(len(values)-1))
+}
+
+func float64Ptr(value float64) *float64 {
+	return &value
+}
@@ -0,0 +1,86 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// dailySeries creates a daily close series ending on end.
+func dailySeries(end time.Time, closes ...float64) Series {
+	series := Series{ID: "crypto:BTCUSDT", Frequency: "Daily"}
+	for idx, value := range closes {
+		series.Points = append(series.Points, Point{
+			Time:  end.AddDate(0, 0, idx-len(closes)+1),
+			Value: value,
+		})
+	}
+	return series
+}
+
+// TestReturns tests the trailing return and volatility over a window.
+func TestReturns(t *testing.T) {
+	// Arrange
+	end := date(t, "2024-03-31")
+	prices := dailySeries(end, 100, 110, 99, 108.9)
+
+	// Act
+	stats := Returns(prices, Series{}, []int{3})
+
+	// Assert
+	window := stats[0]
+	if window.Start != "2024-03-28" || window.End != "2024-03-31" {
+		t.Errorf("Expected window 2024-03-28..2024-03-31, got %s..%s", window.Start, window.End)
+	}
+	if window.ReturnPercent == nil || math.Abs(*window.ReturnPercent-8.9) > 1e-9 {
+		t.Errorf("Expected return 8.9%%, got %v", window.ReturnPercent)
+	}
+	if window.VolatilityPercent == nil || *window.VolatilityPercent <= 0 {
+		t.Errorf("Expected positive volatility, got %v", window.VolatilityPercent)
+	}
+	if window.Sharpe == nil || *window.Sharpe <= 0 {
+		t.Errorf("Expected positive Sharpe ratio, got %v", window.Sharpe)
+	}
+}
+
+// TestReturnsRiskFreeLowersSharpe tests that a higher risk-free rate lowers the Sharpe ratio.
+func TestReturnsRiskFreeLowersSharpe(t *testing.T) {
+	// Arrange
+	end := date(t, "2024-03-31")
+	prices := dailySeries(end, 100, 101, 100.5, 102, 101.5, 103)
+	riskFree := Series{ID: "fred:FEDFUNDS", Frequency: "Monthly", Points: []Point{
+		{Time: date(t, "2024-03-01"), Value: 50},
+	}}
+
+	// Act
+	withoutRate := Returns(prices, Series{}, []int{5})[0]
+	withRate := Returns(prices, riskFree, []int{5})[0]
+
+	// Assert
+	if withRate.RiskFreeRatePercent == nil || *withRate.RiskFreeRatePercent != 50 {
+		t.Errorf("Expected risk-free rate 50%%, got %v", withRate.RiskFreeRatePercent)
+	}
+	if *withRate.Sharpe >= *withoutRate.Sharpe {
+		t.Errorf("Expected Sharpe %f to be below %f", *withRate.Sharpe, *withoutRate.Sharpe)
+	}
+}
+
+// TestReturnsInsufficientHistory tests that windows longer than the history are left empty.
+func TestReturnsInsufficientHistory(t *testing.T) {
+	// Arrange
+	prices := dailySeries(date(t, "2024-03-31"), 100, 110)
+
+	// Act
+	stats := Returns(prices, Series{}, []int{1, 30})
+
+	// Assert
+	if stats[0].ReturnPercent == nil {
+		t.Error("Expected a 1-day return")
+	}
+	if stats[1].ReturnPercent != nil || stats[1].Sharpe != nil {
+		t.Errorf("Expected no 30-day statistics, got %+v", stats[1])
+	}
+	if stats[1].Days != 30 {
+		t.Errorf("Expected window of 30 days, got %d", stats[1].Days)
+	}
+}
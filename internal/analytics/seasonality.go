@@ -0,0 +1,171 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Significance hints for seasonal averages, based on the t-statistic of
+// the mean return and the number of samples.
+const (
+	SignificanceInsufficient = "insufficient_data" // Fewer than MinSeasonalSamples samples
+	SignificanceNoise        = "noise"             // |t| < 1: indistinguishable from zero
+	SignificanceWeak         = "weak"              // 1 <= |t| < 2
+	SignificanceSignificant  = "significant"       // |t| >= 2, roughly 95% confidence
+)
+
+// MinSeasonalSamples is the fewest samples a significance hint is given for.
+const MinSeasonalSamples = 3
+
+// SeasonalBucket aggregates the returns of one calendar month or weekday.
+type SeasonalBucket struct {
+	Period              string   `json:"period"` // e.g. "September" or "Monday"
+	Samples             int      `json:"samples"`
+	MeanReturnPercent   *float64 `json:"mean_return_percent"`
+	MedianReturnPercent *float64 `json:"median_return_percent"`
+	PositiveShare       *float64 `json:"positive_share"` // Share of samples with a positive return (0-1)
+	TStat               *float64 `json:"t_stat"`
+	Significance        string   `json:"significance"`
+}
+
+// Seasonality holds average returns per calendar month and per weekday.
+type Seasonality struct {
+	Start   string           `json:"start"`
+	End     string           `json:"end"`
+	Monthly []SeasonalBucket `json:"monthly"`
+	Weekday []SeasonalBucket `json:"weekday"`
+}
+
+// SeasonalityOf computes monthly and weekday seasonality from daily closes.
+// Monthly returns run from one month's last close to the next; the month
+// of the latest close is left out as it is not complete yet. Weekday
+// returns are close-to-close returns of consecutive days, attributed to the
+// later day.
+func SeasonalityOf(prices Series) Seasonality {
+	closes := make([]Point, len(prices.Points))
+	copy(closes, prices.Points)
+	sort.SliceStable(closes, func(a, b int) bool { return closes[a].Time.Before(closes[b].Time) })
+
+	monthly := make([][]float64, 12)
+	weekday := make([][]float64, 7)
+	result := Seasonality{}
+
+	if len(closes) > 0 {
+		result.Start = closes[0].Time.UTC().Format(DateLayout)
+		result.End = closes[len(closes)-1].Time.UTC().Format(DateLayout)
+	}
+
+	// Weekday returns from consecutive daily closes
+	for idx := 1; idx < len(closes); idx++ {
+		previous, current := closes[idx-1], closes[idx]
+		if previous.Value == 0 || truncateDay(current.Time).Sub(truncateDay(previous.Time)) != 24*time.Hour {
+			continue
+		}
+		day := current.Time.UTC().Weekday()
+		weekday[day] = append(weekday[day], current.Value/previous.Value-1)
+	}
+
+	// Monthly returns from month-end closes of complete months
+	var monthEnds []Point
+	for idx, point := range closes {
+		if idx+1 < len(closes) && sameMonth(point.Time, closes[idx+1].Time) {
+			continue
+		}
+		monthEnds = append(monthEnds, point)
+	}
+	if len(monthEnds) > 0 && !isMonthEnd(monthEnds[len(monthEnds)-1].Time) {
+		monthEnds = monthEnds[:len(monthEnds)-1]
+	}
+	for idx := 1; idx < len(monthEnds); idx++ {
+		previous, current := monthEnds[idx-1], monthEnds[idx]
+		if previous.Value == 0 || monthIndex(current.Time) != monthIndex(previous.Time)+1 {
+			// Skip months following a gap in the history
+			continue
+		}
+		month := current.Time.UTC().Month() - 1
+		monthly[month] = append(monthly[month], current.Value/previous.Value-1)
+	}
+
+	for month, returns := range monthly {
+		result.Monthly = append(result.Monthly, seasonalBucket(time.Month(month+1).String(), returns))
+	}
+	// Start the week on Monday
+	for offset := range 7 {
+		day := time.Weekday((offset + 1) % 7)
+		result.Weekday = append(result.Weekday, seasonalBucket(day.String(), weekday[day]))
+	}
+	return result
+}
+
+// seasonalBucket summarizes the returns of one period.
+func seasonalBucket(period string, returns []float64) SeasonalBucket {
+	bucket := SeasonalBucket{
+		Period:       period,
+		Samples:      len(returns),
+		Significance: SignificanceInsufficient,
+	}
+	if len(returns) == 0 {
+		return bucket
+	}
+
+	positive := 0
+	for _, value := range returns {
+		if value > 0 {
+			positive++
+		}
+	}
+	bucket.MeanReturnPercent = float64Ptr(mean(returns) * 100)
+	bucket.MedianReturnPercent = float64Ptr(median(returns) * 100)
+	bucket.PositiveShare = float64Ptr(float64(positive) / float64(len(returns)))
+
+	if len(returns) < MinSeasonalSamples {
+		return bucket
+	}
+
+	deviation := StdDev(returns)
+	if deviation == 0 {
+		return bucket
+	}
+	tStat := mean(returns) / (deviation / math.Sqrt(float64(len(returns))))
+	bucket.TStat = float64Ptr(tStat)
+
+	switch abs := math.Abs(tStat); {
+	case abs >= 2:
+		bucket.Significance = SignificanceSignificant
+	case abs >= 1:
+		bucket.Significance = SignificanceWeak
+	default:
+		bucket.Significance = SignificanceNoise
+	}
+	return bucket
+}
+
+// median returns the median of values.
+func median(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// sameMonth reports whether a and b fall in the same UTC calendar month.
+func sameMonth(a, b time.Time) bool {
+	return monthIndex(a) == monthIndex(b)
+}
+
+// monthIndex numbers UTC calendar months consecutively.
+func monthIndex(t time.Time) int {
+	t = t.UTC()
+	return t.Year()*12 + int(t.Month()) - 1
+}
+
+// isMonthEnd reports whether t falls on the last UTC day of its month.
+func isMonthEnd(t time.Time) bool {
+	return !sameMonth(t, t.UTC().AddDate(0, 0, 1))
+}
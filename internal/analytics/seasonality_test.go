@@ -0,0 +1,96 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestSeasonalityMonthly tests month-end to month-end returns, leaving out
+// the incomplete latest month.
+func TestSeasonalityMonthly(t *testing.T) {
+	// Arrange: closes double each September and stay flat otherwise
+	var prices Series
+	price := 100.0
+	for day := date(t, "2020-01-01"); day.Before(date(t, "2024-01-16")); day = day.AddDate(0, 0, 1) {
+		if day.Month() == time.September && day.Day() == 30 {
+			price *= 2
+		}
+		prices.Points = append(prices.Points, Point{Time: day, Value: price})
+	}
+
+	// Act
+	seasonality := SeasonalityOf(prices)
+
+	// Assert
+	september := seasonality.Monthly[8]
+	if september.Period != "September" {
+		t.Fatalf("Expected September, got %s", september.Period)
+	}
+	if september.Samples != 4 {
+		t.Errorf("Expected 4 Septembers, got %d", september.Samples)
+	}
+	if september.MeanReturnPercent == nil || math.Abs(*september.MeanReturnPercent-100) > 1e-9 {
+		t.Errorf("Expected mean return 100%%, got %v", september.MeanReturnPercent)
+	}
+	if *september.PositiveShare != 1 {
+		t.Errorf("Expected every September to be positive, got %f", *september.PositiveShare)
+	}
+
+	january := seasonality.Monthly[0]
+	if january.Samples != 3 {
+		t.Errorf("Expected 3 complete Januaries (2021-2023), got %d", january.Samples)
+	}
+	if *january.MeanReturnPercent != 0 || january.Significance != SignificanceInsufficient {
+		t.Errorf("Expected flat Januaries without a significance hint, got %+v", january)
+	}
+}
+
+// TestSeasonalityWeekday tests that daily returns are attributed to the later day.
+func TestSeasonalityWeekday(t *testing.T) {
+	// Arrange: 2024-01-01 is a Monday
+	prices := Series{Points: []Point{
+		{Time: date(t, "2024-01-01"), Value: 100},
+		{Time: date(t, "2024-01-02"), Value: 110},
+		{Time: date(t, "2024-01-03"), Value: 99},
+	}}
+
+	// Act
+	seasonality := SeasonalityOf(prices)
+
+	// Assert
+	if seasonality.Weekday[0].Period != "Monday" || seasonality.Weekday[6].Period != "Sunday" {
+		t.Fatalf("Expected the week to run Monday to Sunday, got %s..%s",
+			seasonality.Weekday[0].Period, seasonality.Weekday[6].Period)
+	}
+	tuesday := seasonality.Weekday[1]
+	if tuesday.Samples != 1 || math.Abs(*tuesday.MeanReturnPercent-10) > 1e-9 {
+		t.Errorf("Expected one Tuesday return of 10%%, got %+v", tuesday)
+	}
+	if seasonality.Weekday[0].Samples != 0 {
+		t.Errorf("Expected no Monday returns, got %d", seasonality.Weekday[0].Samples)
+	}
+}
+
+// TestSeasonalBucketSignificance tests significance hints from the t-statistic.
+func TestSeasonalBucketSignificance(t *testing.T) {
+	tests := []struct {
+		name     string
+		returns  []float64
+		expected string
+	}{
+		{"too few samples", []float64{0.1, 0.2}, SignificanceInsufficient},
+		{"consistent gains", []float64{0.10, 0.11, 0.09, 0.10, 0.12}, SignificanceSignificant},
+		{"mixed", []float64{0.10, -0.12, 0.05, -0.04, 0.02}, SignificanceNoise},
+	}
+
+	for _, tt := range tests {
+		// Act
+		bucket := seasonalBucket("September", tt.returns)
+
+		// Assert
+		if bucket.Significance != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, bucket.Significance)
+		}
+	}
+}
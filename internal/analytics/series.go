@@ -0,0 +1,175 @@
+// Package analytics combines macro (FRED) and crypto time series: aligning
+// them onto a common time grid so they can be overlaid in charts, and
+// deriving return statistics such as Sharpe ratios and seasonality.
+package analytics
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DateLayout is the format of grid dates and series observations (UTC).
+const DateLayout = "2006-01-02"
+
+// Grid frequencies series can be aligned to.
+const (
+	GridDaily   = "daily"
+	GridWeekly  = "weekly"
+	GridMonthly = "monthly"
+)
+
+// MaxGridPoints bounds the size of an aligned result.
+const MaxGridPoints = 5000
+
+var (
+	// ErrInvalidRange is returned when the start date is after the end date
+	// or the grid would exceed MaxGridPoints.
+	ErrInvalidRange = errors.New("invalid date range")
+
+	// ErrInvalidGrid is returned for unknown grid frequencies.
+	ErrInvalidGrid = errors.New("invalid grid frequency")
+)
+
+// Point is one observation of a series.
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// Series is a named sequence of observations in ascending time order.
+type Series struct {
+	ID        string // Request identifier, e.g. "fred:WALCL" or "crypto:BTCUSDT"
+	Source    string // Data provider, e.g. "FRED" or "Binance"
+	Frequency string // Native frequency as reported by the source, e.g. "Weekly, Ending Wednesday"
+	Points    []Point
+}
+
+// AlignedSeries holds the values of a series at each grid date. A nil value
+// means no observation was recent enough to carry forward.
+type AlignedSeries struct {
+	ID        string     `json:"id"`
+	Source    string     `json:"source"`
+	Frequency string     `json:"frequency"`
+	Values    []*float64 `json:"values"`
+}
+
+// Alignment is the result of aligning several series onto one grid.
+type Alignment struct {
+	Grid   string          `json:"grid"`
+	Dates  []string        `json:"dates"`
+	Series []AlignedSeries `json:"series"`
+}
+
+// Grid returns the dates from start to end (inclusive, UTC days) spaced by
+// the grid frequency.
+func Grid(frequency string, start, end time.Time) ([]time.Time, error) {
+	start, end = truncateDay(start), truncateDay(end)
+	if end.Before(start) {
+		return nil, fmt.Errorf("%w: start %s is after end %s",
+			ErrInvalidRange, start.Format(DateLayout), end.Format(DateLayout))
+	}
+
+	var step func(time.Time) time.Time
+	switch frequency {
+	case GridDaily:
+		step = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	case GridWeekly:
+		step = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+	case GridMonthly:
+		step = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	default:
+		return nil, fmt.Errorf("%w: %q (expected %s, %s or %s)",
+			ErrInvalidGrid, frequency, GridDaily, GridWeekly, GridMonthly)
+	}
+
+	var dates []time.Time
+	for date := start; !date.After(end); date = step(date) {
+		if len(dates) == MaxGridPoints {
+			return nil, fmt.Errorf("%w: more than %d %s points", ErrInvalidRange, MaxGridPoints, frequency)
+		}
+		dates = append(dates, date)
+	}
+	return dates, nil
+}
+
+// Align resamples each series onto the grid dates by forward-filling its
+// latest observation on or before each date. A value is only carried
+// forward for as long as its native frequency allows (see MaxFillAge), so
+// a discontinued series shows gaps instead of a flat line.
+func Align(grid string, dates []time.Time, series []Series) Alignment {
+	alignment := Alignment{
+		Grid:   grid,
+		Dates:  make([]string, len(dates)),
+		Series: make([]AlignedSeries, len(series)),
+	}
+	for idx, date := range dates {
+		alignment.Dates[idx] = date.Format(DateLayout)
+	}
+
+	for idx, s := range series {
+		alignment.Series[idx] = AlignedSeries{
+			ID:        s.ID,
+			Source:    s.Source,
+			Frequency: s.Frequency,
+			Values:    forwardFill(s.Points, dates, MaxFillAge(s.Frequency)),
+		}
+	}
+	return alignment
+}
+
+// forwardFill returns the value of the latest point on or before each date,
+// or nil if there is none within maxAge.
+func forwardFill(points []Point, dates []time.Time, maxAge time.Duration) []*float64 {
+	sorted := make([]Point, len(points))
+	copy(sorted, points)
+	sort.SliceStable(sorted, func(a, b int) bool { return sorted[a].Time.Before(sorted[b].Time) })
+
+	values := make([]*float64, len(dates))
+	next := 0
+	var latest *Point
+	for idx, date := range dates {
+		// Observations during the grid day count for that day
+		endOfDay := date.AddDate(0, 0, 1)
+		for next < len(sorted) && sorted[next].Time.Before(endOfDay) {
+			latest = &sorted[next]
+			next++
+		}
+		if latest == nil || date.Sub(truncateDay(latest.Time)) > maxAge {
+			continue
+		}
+		value := latest.Value
+		values[idx] = &value
+	}
+	return values
+}
+
+// MaxFillAge returns how long an observation of the given native frequency
+// may be carried forward: a few periods of slack for weekends, holidays and
+// publication lag, beyond which the series is considered to have a gap.
+func MaxFillAge(frequency string) time.Duration {
+	const day = 24 * time.Hour
+
+	switch f := strings.ToLower(frequency); {
+	case strings.HasPrefix(f, "daily"):
+		return 5 * day
+	case strings.HasPrefix(f, "weekly"), strings.HasPrefix(f, "biweekly"):
+		return 16 * day
+	case strings.HasPrefix(f, "monthly"):
+		return 62 * day
+	case strings.HasPrefix(f, "quarterly"):
+		return 184 * day
+	case strings.HasPrefix(f, "annual"), strings.HasPrefix(f, "semiannual"):
+		return 366 * day
+	default:
+		return 5 * day
+	}
+}
+
+// truncateDay returns the start of the UTC day of t.
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,154 @@
+package analytics
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// date parses a YYYY-MM-DD date for tests.
+func date(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(DateLayout, value)
+	if err != nil {
+		t.Fatalf("Invalid date %q: %v", value, err)
+	}
+	return parsed
+}
+
+// TestGrid tests grid generation for each frequency.
+func TestGrid(t *testing.T) {
+	tests := []struct {
+		frequency string
+		start     string
+		end       string
+		expected  []string
+	}{
+		{GridDaily, "2024-01-30", "2024-02-02", []string{"2024-01-30", "2024-01-31", "2024-02-01", "2024-02-02"}},
+		{GridWeekly, "2024-01-01", "2024-01-20", []string{"2024-01-01", "2024-01-08", "2024-01-15"}},
+		{GridMonthly, "2024-01-01", "2024-03-15", []string{"2024-01-01", "2024-02-01", "2024-03-01"}},
+	}
+
+	for _, tt := range tests {
+		// Act
+		dates, err := Grid(tt.frequency, date(t, tt.start), date(t, tt.end))
+
+		// Assert
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.frequency, err)
+		}
+		if len(dates) != len(tt.expected) {
+			t.Fatalf("%s: expected %d dates, got %d", tt.frequency, len(tt.expected), len(dates))
+		}
+		for idx, expected := range tt.expected {
+			if got := dates[idx].Format(DateLayout); got != expected {
+				t.Errorf("%s: date %d: expected %s, got %s", tt.frequency, idx, expected, got)
+			}
+		}
+	}
+}
+
+// TestGridErrors tests invalid grids and ranges.
+func TestGridErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		frequency string
+		start     string
+		end       string
+		expected  error
+	}{
+		{"unknown frequency", "hourly", "2024-01-01", "2024-01-02", ErrInvalidGrid},
+		{"start after end", GridDaily, "2024-01-02", "2024-01-01", ErrInvalidRange},
+		{"too many points", GridDaily, "1990-01-01", "2024-01-01", ErrInvalidRange},
+	}
+
+	for _, tt := range tests {
+		// Act
+		_, err := Grid(tt.frequency, date(t, tt.start), date(t, tt.end))
+
+		// Assert
+		if !errors.Is(err, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, err)
+		}
+	}
+}
+
+// TestAlignForwardFills tests that weekly values are carried forward onto a
+// daily grid next to a daily series.
+func TestAlignForwardFills(t *testing.T) {
+	// Arrange
+	dates, err := Grid(GridDaily, date(t, "2024-01-01"), date(t, "2024-01-05"))
+	if err != nil {
+		t.Fatalf("Grid failed: %v", err)
+	}
+	weekly := Series{ID: "fred:WALCL", Frequency: "Weekly, Ending Wednesday", Points: []Point{
+		{Time: date(t, "2024-01-03"), Value: 100},
+		{Time: date(t, "2023-12-27"), Value: 90},
+	}}
+	daily := Series{ID: "crypto:BTCUSDT", Frequency: "Daily", Points: []Point{
+		{Time: date(t, "2024-01-02"), Value: 45000},
+		{Time: date(t, "2024-01-04"), Value: 46000},
+	}}
+
+	// Act
+	alignment := Align(GridDaily, dates, []Series{weekly, daily})
+
+	// Assert
+	expected := map[string][]any{
+		"fred:WALCL":     {90.0, 90.0, 100.0, 100.0, 100.0},
+		"crypto:BTCUSDT": {nil, 45000.0, 45000.0, 46000.0, 46000.0},
+	}
+	for _, aligned := range alignment.Series {
+		for idx, want := range expected[aligned.ID] {
+			got := aligned.Values[idx]
+			switch {
+			case want == nil && got != nil:
+				t.Errorf("%s %s: expected no value, got %f", aligned.ID, alignment.Dates[idx], *got)
+			case want != nil && (got == nil || *got != want.(float64)):
+				t.Errorf("%s %s: expected %v, got %v", aligned.ID, alignment.Dates[idx], want, got)
+			}
+		}
+	}
+}
+
+// TestAlignLeavesGapsForStaleSeries tests that values older than the fill age are not carried forward.
+func TestAlignLeavesGapsForStaleSeries(t *testing.T) {
+	// Arrange
+	dates := []time.Time{date(t, "2024-01-04"), date(t, "2024-01-10")}
+	daily := Series{ID: "crypto:BTCUSDT", Frequency: "Daily", Points: []Point{
+		{Time: date(t, "2024-01-01"), Value: 45000},
+	}}
+
+	// Act
+	alignment := Align(GridDaily, dates, []Series{daily})
+
+	// Assert
+	values := alignment.Series[0].Values
+	if values[0] == nil || *values[0] != 45000 {
+		t.Errorf("Expected 45000 within the fill age, got %v", values[0])
+	}
+	if values[1] != nil {
+		t.Errorf("Expected a gap after the fill age, got %f", *values[1])
+	}
+}
+
+// TestMaxFillAge tests fill ages per native frequency.
+func TestMaxFillAge(t *testing.T) {
+	const day = 24 * time.Hour
+	tests := []struct {
+		frequency string
+		expected  time.Duration
+	}{
+		{"Daily", 5 * day},
+		{"Weekly, Ending Wednesday", 16 * day},
+		{"Monthly", 62 * day},
+		{"Quarterly", 184 * day},
+		{"", 5 * day},
+	}
+
+	for _, tt := range tests {
+		if got := MaxFillAge(tt.frequency); got != tt.expected {
+			t.Errorf("%q: expected %s, got %s", tt.frequency, tt.expected, got)
+		}
+	}
+}
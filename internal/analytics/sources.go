@@ -0,0 +1,42 @@
+package analytics
+
+import (
+	"context"
+	"time"
+
+	"macro-analyst/pkg/fred"
+)
+
+// Series sources accepted in series identifiers such as "fred:WALCL".
+const (
+	SourceFRED   = "fred"
+	SourceCrypto = "crypto"
+)
+
+// CryptoHistory returns the daily closes of a crypto symbol between start
+// and end (inclusive), oldest first.
+type CryptoHistory func(ctx context.Context, symbol string, start, end time.Time) ([]Point, error)
+
+// FromFRED converts FRED observations into a Series. Missing observations,
+// which FRED reports as ".", are skipped.
+func FromFRED(data *fred.SeriesData) Series {
+	series := Series{
+		ID:        SourceFRED + ":" + string(data.Ticker),
+		Source:    "FRED",
+		Frequency: data.Frequency,
+		Points:    make([]Point, 0, len(data.Observations)),
+	}
+
+	for _, observation := range data.Observations {
+		date, err := time.Parse(DateLayout, observation.Date)
+		if err != nil {
+			continue
+		}
+		typed := observation.Typed()
+		if typed.Missing {
+			continue
+		}
+		series.Points = append(series.Points, Point{Time: date, Value: typed.Value})
+	}
+	return series
+}
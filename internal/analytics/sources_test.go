@@ -0,0 +1,38 @@
+package analytics
+
+import (
+	"testing"
+
+	"macro-analyst/pkg/fred"
+)
+
+// TestFromFRED tests conversion of FRED observations, skipping missing values.
+func TestFromFRED(t *testing.T) {
+	// Arrange
+	data := &fred.SeriesData{
+		Ticker:    fred.TickerFEDFUNDS,
+		Frequency: "Monthly",
+		Observations: []fred.Observation{
+			{Date: "2024-01-01", Value: "5.33"},
+			{Date: "2024-02-01", Value: "."},
+			{Date: "2024-03-01", Value: "5.33"},
+		},
+	}
+
+	// Act
+	series := FromFRED(data)
+
+	// Assert
+	if series.ID != "fred:FEDFUNDS" {
+		t.Errorf("Expected ID fred:FEDFUNDS, got %s", series.ID)
+	}
+	if series.Frequency != "Monthly" {
+		t.Errorf("Expected frequency Monthly, got %s", series.Frequency)
+	}
+	if len(series.Points) != 2 {
+		t.Fatalf("Expected 2 points, got %d", len(series.Points))
+	}
+	if series.Points[1].Time.Format(DateLayout) != "2024-03-01" {
+		t.Errorf("Expected second point on 2024-03-01, got %s", series.Points[1].Time.Format(DateLayout))
+	}
+}
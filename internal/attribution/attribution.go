@@ -0,0 +1,90 @@
+// Package attribution builds the source attribution that upstream data
+// terms require in redistributed UIs, per data module.
+package attribution
+
+import (
+	"fmt"
+	"time"
+)
+
+// Module identifies a data module whose responses carry attribution.
+type Module string
+
+const (
+	// ModuleFRED covers macroeconomic data from FRED
+	ModuleFRED Module = "fred"
+
+	// ModuleCrypto covers market data from Binance
+	ModuleCrypto Module = "crypto"
+
+	// DefaultInterval is how often attribution is broadcast to WebSocket clients
+	DefaultInterval = 5 * time.Minute
+)
+
+// Modules lists all data modules, which carry attribution by default.
+var Modules = []Module{ModuleFRED, ModuleCrypto}
+
+// Attribution credits the source of a piece of data.
+type Attribution struct {
+	Source      string    `json:"source"`
+	Citation    string    `json:"citation"`
+	URL         string    `json:"url,omitempty"`
+	RetrievedAt time.Time `json:"retrieved_at"`
+}
+
+// Attributor builds attributions for the enabled data modules.
+type Attributor struct {
+	enabled map[Module]bool
+}
+
+// New creates an Attributor for the given modules. Unknown modules are
+// ignored.
+func New(modules ...Module) *Attributor {
+	enabled := make(map[Module]bool, len(modules))
+	for _, module := range modules {
+		enabled[module] = true
+	}
+	return &Attributor{enabled: enabled}
+}
+
+// Enabled reports whether the module carries attribution. A nil Attributor
+// disables attribution for all modules.
+func (a *Attributor) Enabled(module Module) bool {
+	return a != nil && a.enabled[module]
+}
+
+// FRED attributes data retrieved from FRED, citing the series if given.
+// It returns nil if the FRED module is disabled.
+func (a *Attributor) FRED(seriesID string, retrievedAt time.Time) *Attribution {
+	if !a.Enabled(ModuleFRED) {
+		return nil
+	}
+
+	attribution := &Attribution{
+		Source:      "FRED, Federal Reserve Bank of St. Louis",
+		Citation:    "Federal Reserve Economic Data (FRED), Federal Reserve Bank of St. Louis",
+		URL:         "https://fred.stlouisfed.org/",
+		RetrievedAt: retrievedAt,
+	}
+	if seriesID != "" {
+		attribution.Citation = fmt.Sprintf("%s; %s, retrieved %s", attribution.Citation,
+			seriesID, retrievedAt.UTC().Format("January 2, 2006"))
+		attribution.URL = "https://fred.stlouisfed.org/series/" + seriesID
+	}
+	return attribution
+}
+
+// Binance attributes market data received from Binance. It returns nil if
+// the crypto module is disabled.
+func (a *Attributor) Binance(retrievedAt time.Time) *Attribution {
+	if !a.Enabled(ModuleCrypto) {
+		return nil
+	}
+
+	return &Attribution{
+		Source:      "Binance",
+		Citation:    "Market data provided by Binance",
+		URL:         "https://www.binance.com/",
+		RetrievedAt: retrievedAt,
+	}
+}
@@ -0,0 +1,44 @@
+package attribution
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestEnabled verifies per-module configuration.
+func TestEnabled(t *testing.T) {
+	attributor := New(ModuleFRED)
+
+	if !attributor.Enabled(ModuleFRED) {
+		t.Error("Expected FRED to be enabled")
+	}
+	if attributor.Enabled(ModuleCrypto) {
+		t.Error("Expected crypto to be disabled")
+	}
+	if attributor.Binance(time.Now()) != nil {
+		t.Error("Expected no attribution for a disabled module")
+	}
+
+	var disabled *Attributor
+	if disabled.FRED("GDP", time.Now()) != nil {
+		t.Error("Expected a nil Attributor to disable attribution")
+	}
+}
+
+// TestFREDCitation verifies series citations.
+func TestFREDCitation(t *testing.T) {
+	retrievedAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	attr := New(Modules...).FRED("GDP", retrievedAt)
+
+	if !strings.Contains(attr.Citation, "GDP, retrieved January 15, 2024") {
+		t.Errorf("Unexpected citation: %s", attr.Citation)
+	}
+	if attr.URL != "https://fred.stlouisfed.org/series/GDP" {
+		t.Errorf("Unexpected URL: %s", attr.URL)
+	}
+	if !attr.RetrievedAt.Equal(retrievedAt) {
+		t.Errorf("Expected retrieval time %v, got %v", retrievedAt, attr.RetrievedAt)
+	}
+}
@@ -0,0 +1,332 @@
+// Package config loads the service configuration from an optional YAML file
+// and the environment, which takes precedence:
+//
+//	# config.yaml
+//	port: 8080
+//	symbols: [BTCUSDT, ETHUSDT]
+//	throttle_interval: 500ms
+//	cors_origins:
+//	  - https://dashboard.example.com
+//
+//	cfg, err := config.Load(os.Getenv("CONFIG_FILE"), defaults)
+//
+// Each key of the file is the lower-case name of an environment variable.
+// Keys may be grouped under sections, such as "hub:", which only group
+// them: a key means the same in a section as at the top level.
+// Load reads every setting of the service into Config; credentials are
+// left to package secrets, which looks them up with Config.Lookup so the
+// file may hold them too. Settings are kept in their plain form: the caller
+// parses the ones naming policies or modules of its packages and turns the
+// configuration into their options.
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Config is the configuration of the service.
+type Config struct {
+	// Port is the HTTP port (PORT)
+	Port int
+
+	// Symbols are the Binance symbols streamed at startup, the ingestor's
+	// defaults when empty (SYMBOLS)
+	Symbols []string
+
+	// ThrottleInterval is the minimum interval between price broadcasts
+	// (THROTTLE_INTERVAL)
+	ThrottleInterval time.Duration
+
+	// Reconnect is how the Binance feeds reconnect
+	Reconnect ReconnectPolicy
+
+	// Hub configures the WebSocket hubs
+	Hub HubConfig
+
+	// Feeds configures the Binance feeds of the crypto module
+	Feeds FeedConfig
+
+	// Server configures the HTTP server, other than its credentials
+	Server ServerConfig
+
+	// Macro configures FRED polling and the macro jobs
+	Macro MacroConfig
+
+	// AttributionModules are the data modules credited to their sources, all
+	// of them by default and none when set empty (ATTRIBUTION_MODULES)
+	AttributionModules []string
+
+	// AttributionInterval is how often attribution is published
+	// (ATTRIBUTION_INTERVAL)
+	AttributionInterval time.Duration
+
+	// UsageFile persists per-key API usage (USAGE_FILE)
+	UsageFile string
+
+	// StatsInterval is how often resource usage is sampled (STATS_INTERVAL)
+	StatsInterval time.Duration
+
+	// StatsHistorySize is how many samples are kept (STATS_HISTORY_SIZE)
+	StatsHistorySize int
+
+	// source looks up the settings Load read, for Lookup
+	source source
+}
+
+// ReconnectPolicy is how the Binance feeds reconnect after a failed connect
+// or a dropped stream.
+type ReconnectPolicy struct {
+	// Backoff is the first wait before reconnecting, doubled per failure;
+	// zero disables reconnecting (RECONNECT_BACKOFF)
+	Backoff time.Duration
+
+	// MaxAttempts is how many consecutive reconnects are attempted, zero for
+	// no limit (RECONNECT_MAX_ATTEMPTS)
+	MaxAttempts int
+}
+
+// HubConfig configures the WebSocket hubs.
+type HubConfig struct {
+	Region                string        // REGION
+	MessageTTL            time.Duration // HUB_MESSAGE_TTL
+	BackpressureThreshold float64       // HUB_BACKPRESSURE_THRESHOLD
+	BackpressureSustain   time.Duration // HUB_BACKPRESSURE_SUSTAIN
+	AuditFile             string        // AUDIT_FILE
+	AuditMaxBytes         int           // AUDIT_MAX_BYTES
+	BroadcastHistory      int           // BROADCAST_HISTORY
+	BroadcastBuffer       int           // HUB_BROADCAST_BUFFER
+	SlowClientPolicy      string        // HUB_SLOW_CLIENT_POLICY
+	WriteTimeout          time.Duration // HUB_WRITE_TIMEOUT
+	MaxMessageAge         time.Duration // HUB_MAX_MESSAGE_AGE
+	IdleTimeout           time.Duration // HUB_IDLE_TIMEOUT
+	HeartbeatInterval     time.Duration // HEARTBEAT_INTERVAL
+}
+
+// FeedConfig configures the Binance feeds of the crypto module.
+type FeedConfig struct {
+	HistoryFile                string        // FEED_HISTORY_FILE
+	SnapshotFile               string        // SNAPSHOT_FILE
+	StaleTimeout               time.Duration // STALE_FEED_TIMEOUT
+	StandbyFeed                string        // STANDBY_FEED
+	FailoverTimeout            time.Duration // FAILOVER_TIMEOUT
+	MockData                   string        // MOCK_DATA: true, false or auto
+	ReplayPath                 string        // REPLAY_PATH
+	ReplaySpeed                float64       // REPLAY_SPEED
+	ReplayLoop                 bool          // REPLAY_LOOP
+	RecordDir                  string        // RECORD_DIR
+	NATSSubject                string        // NATS_SUBJECT
+	FXPairs                    []string      // FX_PAIRS
+	TimestampSource            string        // TIMESTAMP_SOURCE
+	ChangeReference            string        // CHANGE_REFERENCE
+	ChangeAnchor               string        // CHANGE_ANCHOR
+	MaxPayloadSize             int           // MAX_PAYLOAD_SIZE
+	BackpressureThrottleFactor int           // BACKPRESSURE_THROTTLE_FACTOR
+	SignificanceThreshold      string        // SIGNIFICANCE_THRESHOLD
+	SymbolThresholds           []string      // SYMBOL_SIGNIFICANCE_THRESHOLDS
+	RecentWindow               time.Duration // RECENT_WINDOW
+	RecentResolution           time.Duration // RECENT_RESOLUTION
+	CandleIntervals            []string      // CANDLE_INTERVALS, none when set empty
+	CandlesCacheTTL            time.Duration // CANDLES_CACHE_TTL
+	OrderBookDepth             string        // ORDERBOOK_DEPTH, off when empty
+	OrderBookInterval          time.Duration // ORDERBOOK_INTERVAL
+	TradeStatsWindow           time.Duration // TRADE_STATS_WINDOW, off when zero
+	TradeStatsInterval         time.Duration // TRADE_STATS_INTERVAL
+	Futures                    bool          // FUTURES_STREAM
+	FuturesInterval            time.Duration // FUTURES_INTERVAL
+	OpenInterestInterval       time.Duration // FUTURES_OPEN_INTEREST_INTERVAL
+	MoversInterval             time.Duration // MOVERS_INTERVAL
+	AutoTrackTopN              int           // AUTO_TRACK_TOP_N, off when zero
+	Exchanges                  []string      // EXCHANGES aggregated with the primary feed
+}
+
+// ServerConfig configures the HTTP server. Load leaves its credentials
+// empty: the caller resolves them from their secret sources.
+type ServerConfig struct {
+	FREDAPIKey          string        // FRED_API_KEY, FRED disabled when empty
+	AdminToken          string        // ADMIN_TOKEN, admin routes disabled when empty
+	APIKeys             []string      // API_KEYS
+	JWTSigningKey       string        // JWT_SIGNING_KEY, anonymous streams when empty
+	Modules             []string      // MODULES, all when empty
+	CORSOrigins         []string      // CORS_ORIGINS, every origin when empty
+	JWTIssuer           string        // JWT_ISSUER
+	Compression         bool          // WS_COMPRESSION
+	CompressionLevel    int           // WS_COMPRESSION_LEVEL
+	SendBufferSize      int           // CLIENT_SEND_BUFFER
+	PollWait            time.Duration // POLL_WAIT
+	MaxConnections      int           // MAX_CONNECTIONS
+	MaxConnectionsPerIP int           // MAX_CONNECTIONS_PER_IP
+	UpgradesPerMinute   int           // UPGRADES_PER_MINUTE
+	EmbedHourlyByteCap  int           // EMBED_HOURLY_BYTE_CAP
+	RawStreamsPerUser   int           // RAW_STREAMS_PER_USER
+}
+
+// MacroConfig configures FRED polling and the macro jobs.
+type MacroConfig struct {
+	FREDTickers           string        // FRED_TICKERS
+	FREDPollInterval      time.Duration // FRED_POLL_INTERVAL
+	NetLiquidityInterval  time.Duration // NET_LIQUIDITY_INTERVAL
+	FormulaInterval       time.Duration // FORMULA_INTERVAL
+	ExpectationsFile      string        // EXPECTATIONS_FILE
+	FREDHistoryFile       string        // FRED_HISTORY_FILE
+	FREDSeedPeriod        time.Duration // FRED_SEED_PERIOD
+	SurpriseWindow        time.Duration // SURPRISE_WINDOW
+	SurpriseCheckInterval time.Duration // SURPRISE_CHECK_INTERVAL
+	MarketHolidays        []string      // MARKET_HOLIDAYS
+	ReleaseWindow         time.Duration // RELEASE_WINDOW
+}
+
+// Load reads the configuration from the YAML file at path and the
+// environment, which takes precedence, starting from defaults. The file is
+// optional: an empty path reads the environment only. Invalid values fall
+// back to their defaults with a log line, as they do throughout the
+// service; --check reports them.
+func Load(path string, defaults Config) (Config, error) {
+	src := source{lookupEnv: os.LookupEnv}
+	if path != "" {
+		file, err := loadFile(path)
+		if err != nil {
+			return Config{}, err
+		}
+		src.file = file
+	}
+	return read(src, defaults), nil
+}
+
+// Lookup returns a setting by its environment variable name, from the
+// environment or else the config file Load read.
+func (c Config) Lookup(key string) (string, bool) {
+	return c.source.lookup(key)
+}
+
+// read reads every setting from src, keeping the defaults of those it does
+// not set.
+func read(src source, d Config) Config {
+	cfg := Config{
+		Port:             src.int("PORT", d.Port),
+		Symbols:          symbols(src.list("SYMBOLS", d.Symbols)),
+		ThrottleInterval: src.duration("THROTTLE_INTERVAL", d.ThrottleInterval),
+		Reconnect: ReconnectPolicy{
+			Backoff:     src.duration("RECONNECT_BACKOFF", d.Reconnect.Backoff),
+			MaxAttempts: src.int("RECONNECT_MAX_ATTEMPTS", d.Reconnect.MaxAttempts),
+		},
+		Hub: HubConfig{
+			Region:                src.string("REGION", d.Hub.Region),
+			MessageTTL:            src.duration("HUB_MESSAGE_TTL", d.Hub.MessageTTL),
+			BackpressureThreshold: src.float("HUB_BACKPRESSURE_THRESHOLD", d.Hub.BackpressureThreshold),
+			BackpressureSustain:   src.duration("HUB_BACKPRESSURE_SUSTAIN", d.Hub.BackpressureSustain),
+			AuditFile:             src.string("AUDIT_FILE", d.Hub.AuditFile),
+			AuditMaxBytes:         src.int("AUDIT_MAX_BYTES", d.Hub.AuditMaxBytes),
+			BroadcastHistory:      src.int("BROADCAST_HISTORY", d.Hub.BroadcastHistory),
+			BroadcastBuffer:       src.int("HUB_BROADCAST_BUFFER", d.Hub.BroadcastBuffer),
+			SlowClientPolicy:      src.string("HUB_SLOW_CLIENT_POLICY", d.Hub.SlowClientPolicy),
+			WriteTimeout:          src.duration("HUB_WRITE_TIMEOUT", d.Hub.WriteTimeout),
+			MaxMessageAge:         src.duration("HUB_MAX_MESSAGE_AGE", d.Hub.MaxMessageAge),
+			IdleTimeout:           src.duration("HUB_IDLE_TIMEOUT", d.Hub.IdleTimeout),
+			HeartbeatInterval:     src.duration("HEARTBEAT_INTERVAL", d.Hub.HeartbeatInterval),
+		},
+		Feeds: FeedConfig{
+			HistoryFile:                src.string("FEED_HISTORY_FILE", d.Feeds.HistoryFile),
+			SnapshotFile:               src.string("SNAPSHOT_FILE", d.Feeds.SnapshotFile),
+			StaleTimeout:               src.duration("STALE_FEED_TIMEOUT", d.Feeds.StaleTimeout),
+			StandbyFeed:                src.string("STANDBY_FEED", d.Feeds.StandbyFeed),
+			FailoverTimeout:            src.duration("FAILOVER_TIMEOUT", d.Feeds.FailoverTimeout),
+			MockData:                   src.string("MOCK_DATA", d.Feeds.MockData),
+			ReplayPath:                 src.string("REPLAY_PATH", d.Feeds.ReplayPath),
+			ReplaySpeed:                src.float("REPLAY_SPEED", d.Feeds.ReplaySpeed),
+			ReplayLoop:                 src.bool("REPLAY_LOOP", d.Feeds.ReplayLoop),
+			RecordDir:                  src.string("RECORD_DIR", d.Feeds.RecordDir),
+			NATSSubject:                src.string("NATS_SUBJECT", d.Feeds.NATSSubject),
+			FXPairs:                    src.list("FX_PAIRS", d.Feeds.FXPairs),
+			TimestampSource:            src.string("TIMESTAMP_SOURCE", d.Feeds.TimestampSource),
+			ChangeReference:            src.string("CHANGE_REFERENCE", d.Feeds.ChangeReference),
+			ChangeAnchor:               src.string("CHANGE_ANCHOR", d.Feeds.ChangeAnchor),
+			MaxPayloadSize:             src.int("MAX_PAYLOAD_SIZE", d.Feeds.MaxPayloadSize),
+			BackpressureThrottleFactor: src.int("BACKPRESSURE_THROTTLE_FACTOR", d.Feeds.BackpressureThrottleFactor),
+			SignificanceThreshold:      src.string("SIGNIFICANCE_THRESHOLD", d.Feeds.SignificanceThreshold),
+			SymbolThresholds:           src.list("SYMBOL_SIGNIFICANCE_THRESHOLDS", d.Feeds.SymbolThresholds),
+			RecentWindow:               src.duration("RECENT_WINDOW", d.Feeds.RecentWindow),
+			RecentResolution:           src.duration("RECENT_RESOLUTION", d.Feeds.RecentResolution),
+			CandleIntervals:            src.list("CANDLE_INTERVALS", d.Feeds.CandleIntervals),
+			CandlesCacheTTL:            src.duration("CANDLES_CACHE_TTL", d.Feeds.CandlesCacheTTL),
+			OrderBookDepth:             src.string("ORDERBOOK_DEPTH", d.Feeds.OrderBookDepth),
+			OrderBookInterval:          src.duration("ORDERBOOK_INTERVAL", d.Feeds.OrderBookInterval),
+			TradeStatsWindow:           src.duration("TRADE_STATS_WINDOW", d.Feeds.TradeStatsWindow),
+			TradeStatsInterval:         src.duration("TRADE_STATS_INTERVAL", d.Feeds.TradeStatsInterval),
+			Futures:                    src.bool("FUTURES_STREAM", d.Feeds.Futures),
+			FuturesInterval:            src.duration("FUTURES_INTERVAL", d.Feeds.FuturesInterval),
+			OpenInterestInterval:       src.duration("FUTURES_OPEN_INTEREST_INTERVAL", d.Feeds.OpenInterestInterval),
+			MoversInterval:             src.duration("MOVERS_INTERVAL", d.Feeds.MoversInterval),
+			AutoTrackTopN:              src.int("AUTO_TRACK_TOP_N", d.Feeds.AutoTrackTopN),
+			Exchanges:                  src.list("EXCHANGES", d.Feeds.Exchanges),
+		},
+		Server: ServerConfig{
+			Modules:             src.list("MODULES", d.Server.Modules),
+			CORSOrigins:         src.list("CORS_ORIGINS", d.Server.CORSOrigins),
+			JWTIssuer:           src.string("JWT_ISSUER", d.Server.JWTIssuer),
+			Compression:         src.bool("WS_COMPRESSION", d.Server.Compression),
+			CompressionLevel:    src.int("WS_COMPRESSION_LEVEL", d.Server.CompressionLevel),
+			SendBufferSize:      src.int("CLIENT_SEND_BUFFER", d.Server.SendBufferSize),
+			PollWait:            src.duration("POLL_WAIT", d.Server.PollWait),
+			MaxConnections:      src.int("MAX_CONNECTIONS", d.Server.MaxConnections),
+			MaxConnectionsPerIP: src.int("MAX_CONNECTIONS_PER_IP", d.Server.MaxConnectionsPerIP),
+			UpgradesPerMinute:   src.int("UPGRADES_PER_MINUTE", d.Server.UpgradesPerMinute),
+			EmbedHourlyByteCap:  src.int("EMBED_HOURLY_BYTE_CAP", d.Server.EmbedHourlyByteCap),
+			RawStreamsPerUser:   src.int("RAW_STREAMS_PER_USER", d.Server.RawStreamsPerUser),
+		},
+		Macro: MacroConfig{
+			FREDTickers:           src.string("FRED_TICKERS", d.Macro.FREDTickers),
+			FREDPollInterval:      src.duration("FRED_POLL_INTERVAL", d.Macro.FREDPollInterval),
+			NetLiquidityInterval:  src.duration("NET_LIQUIDITY_INTERVAL", d.Macro.NetLiquidityInterval),
+			FormulaInterval:       src.duration("FORMULA_INTERVAL", d.Macro.FormulaInterval),
+			ExpectationsFile:      src.string("EXPECTATIONS_FILE", d.Macro.ExpectationsFile),
+			FREDHistoryFile:       src.string("FRED_HISTORY_FILE", d.Macro.FREDHistoryFile),
+			FREDSeedPeriod:        src.duration("FRED_SEED_PERIOD", d.Macro.FREDSeedPeriod),
+			SurpriseWindow:        src.duration("SURPRISE_WINDOW", d.Macro.SurpriseWindow),
+			SurpriseCheckInterval: src.duration("SURPRISE_CHECK_INTERVAL", d.Macro.SurpriseCheckInterval),
+			MarketHolidays:        src.list("MARKET_HOLIDAYS", d.Macro.MarketHolidays),
+			ReleaseWindow:         src.duration("RELEASE_WINDOW", d.Macro.ReleaseWindow),
+		},
+		AttributionModules:  src.list("ATTRIBUTION_MODULES", d.AttributionModules),
+		AttributionInterval: src.duration("ATTRIBUTION_INTERVAL", d.AttributionInterval),
+		UsageFile:           src.string("USAGE_FILE", d.UsageFile),
+		StatsInterval:       src.duration("STATS_INTERVAL", d.StatsInterval),
+		StatsHistorySize:    src.int("STATS_HISTORY_SIZE", d.StatsHistorySize),
+		source:              src,
+	}
+	return cfg
+}
+
+// loadFile reads the settings of the YAML file at path, keyed by their
+// environment variable names.
+func loadFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	settings, err := parseYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	file := make(map[string]string, len(settings))
+	for key, value := range settings {
+		file[strings.ToUpper(key)] = value
+	}
+	return file, nil
+}
+
+// symbols upper-cases symbol names, as Binance lists them.
+func symbols(names []string) []string {
+	if len(names) == 0 {
+		return names
+	}
+	upper := make([]string, len(names))
+	for idx, name := range names {
+		upper[idx] = strings.ToUpper(name)
+	}
+	return upper
+}
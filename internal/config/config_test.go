@@ -0,0 +1,160 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testDefaults are the defaults the tests load the configuration over.
+var testDefaults = Config{
+	Port:             8080,
+	ThrottleInterval: 500 * time.Millisecond,
+	Reconnect:        ReconnectPolicy{Backoff: time.Second},
+	Hub:              HubConfig{BroadcastBuffer: 256},
+	Feeds:            FeedConfig{CandleIntervals: []string{"1m", "5m"}, ReplayLoop: true},
+	Server:           ServerConfig{SendBufferSize: 256},
+}
+
+// writeConfig writes a config file.
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+// TestLoadDefaults verifies the defaults apply without a file or variables.
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := Load("", testDefaults)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	cfg.source = source{}
+	if !reflect.DeepEqual(cfg, testDefaults) {
+		t.Errorf("Expected the defaults, got %+v", cfg)
+	}
+}
+
+// TestLoadFile verifies settings are read from the file, that the
+// environment overrides them and that the environment is left untouched.
+func TestLoadFile(t *testing.T) {
+	path := writeConfig(t, `# Production
+port: 9090
+symbols: [btcusdt, "ETHUSDT"]
+throttle_interval: 250ms
+cors_origins:
+  - https://dashboard.example.com   # the React app
+  - 'https://admin.example.com'
+reconnect_backoff: 2s
+reconnect_max_attempts: 5
+hub_broadcast_buffer: 1024
+candle_intervals: []
+auto_track_top_n: 20
+exchanges: [coinbase, kraken]
+modules: crypto
+fred_api_key: from-file
+`)
+	t.Setenv("PORT", "7070")
+	t.Setenv("CLIENT_SEND_BUFFER", "64")
+
+	cfg, err := Load(path, testDefaults)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Port != 7070 {
+		t.Errorf("Expected the environment to override the port, got %d", cfg.Port)
+	}
+	if !reflect.DeepEqual(cfg.Symbols, []string{"BTCUSDT", "ETHUSDT"}) {
+		t.Errorf("Unexpected symbols %v", cfg.Symbols)
+	}
+	if cfg.ThrottleInterval != 250*time.Millisecond || cfg.Hub.BroadcastBuffer != 1024 {
+		t.Errorf("Unexpected throttle interval %v or broadcast buffer %d", cfg.ThrottleInterval, cfg.Hub.BroadcastBuffer)
+	}
+	if cfg.Reconnect != (ReconnectPolicy{Backoff: 2 * time.Second, MaxAttempts: 5}) {
+		t.Errorf("Unexpected reconnect policy %+v", cfg.Reconnect)
+	}
+	if !reflect.DeepEqual(cfg.Server.CORSOrigins, []string{"https://dashboard.example.com", "https://admin.example.com"}) {
+		t.Errorf("Unexpected CORS origins %v", cfg.Server.CORSOrigins)
+	}
+	if cfg.Server.SendBufferSize != 64 || !reflect.DeepEqual(cfg.Server.Modules, []string{"crypto"}) {
+		t.Errorf("Unexpected server config %+v", cfg.Server)
+	}
+	if len(cfg.Feeds.CandleIntervals) != 0 || !cfg.Feeds.ReplayLoop || cfg.Feeds.AutoTrackTopN != 20 {
+		t.Errorf("Expected candles disabled, the top 20 tracked and the replay loop default kept, got %+v", cfg.Feeds)
+	}
+	if !reflect.DeepEqual(cfg.Feeds.Exchanges, []string{"coinbase", "kraken"}) {
+		t.Errorf("Unexpected exchanges %v", cfg.Feeds.Exchanges)
+	}
+	if key, _ := cfg.Lookup("FRED_API_KEY"); key != "from-file" {
+		t.Errorf("Expected the FRED key of the file to be looked up, got %q", key)
+	}
+	if port, _ := cfg.Lookup("PORT"); port != "7070" {
+		t.Errorf("Expected lookups to prefer the environment, got %q", port)
+	}
+	if _, set := os.LookupEnv("FRED_API_KEY"); set {
+		t.Error("Expected the file to leave the environment alone")
+	}
+}
+
+// TestLoadErrors verifies unreadable and invalid files fail.
+func TestLoadErrors(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml"), testDefaults); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+	if _, err := Load(writeConfig(t, "port 8080\n"), testDefaults); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Expected an error naming the invalid line, got %v", err)
+	}
+}
+
+// TestParseYAML tests reading settings, sections and lists from YAML.
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		expected map[string]string
+		err      string
+	}{
+		{"scalars", "port: 8080\nregion: eu-west-1 # comment\n", map[string]string{"port": "8080", "region": "eu-west-1"}, ""},
+		{"quoted", `anchor: "2024-02-19T00:00:00Z"` + "\n" + `name: 'it''s # not a comment'`, map[string]string{"anchor": "2024-02-19T00:00:00Z", "name": "it's # not a comment"}, ""},
+		{"empty list", "symbols: []\n", map[string]string{"symbols": ""}, ""},
+		{"block list", "---\nsymbols:\n  - BTCUSDT\n  - ETHUSDT\nport: 1\n", map[string]string{"symbols": "BTCUSDT,ETHUSDT", "port": "1"}, ""},
+		{"unindented block list", "cors_origins:\n- https://a.example.com\n- https://b.example.com\nport: 1\n", map[string]string{"cors_origins": "https://a.example.com,https://b.example.com", "port": "1"}, ""},
+		{"section", "port: 1\nhub:\n  hub_message_ttl: 5s\n  region: eu\n", map[string]string{"port": "1", "hub_message_ttl": "5s", "region": "eu"}, ""},
+		{"null", "cors_origins:\nport: 1\n", map[string]string{"cors_origins": "", "port": "1"}, ""},
+		{"alias", "a: &origins [x, y]\ncors_origins: *origins\n", map[string]string{"a": "x,y", "cors_origins": "x,y"}, ""},
+		{"item without key", "- BTCUSDT\n", nil, "line 1: expected"},
+		{"misindented key", "hub:\n  region: eu\n port: 1\n", nil, "did not find expected key"},
+		{"missing colon", "port 8080\n", nil, "line 1: expected"},
+		{"duplicate key", "port: 1\nport: 2\n", nil, "line 2"},
+		{"duplicate key across sections", "port: 1\nserver:\n  port: 2\n", nil, "line 3: duplicate key"},
+		{"nested list", "symbols: [[BTCUSDT]]\n", nil, "line 1: items of \"symbols\""},
+		{"unterminated list", "symbols: [BTCUSDT\n", nil, "line"},
+		{"unterminated string", `name: "x` + "\n", nil, "line"},
+	}
+
+	for _, tt := range tests {
+		settings, err := parseYAML([]byte(tt.yaml))
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(settings, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, settings)
+		}
+	}
+}
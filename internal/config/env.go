@@ -0,0 +1,119 @@
+package config
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// List splits a comma-separated value into its non-empty, trimmed items.
+func List(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// source looks settings up in the environment, then in the config file.
+type source struct {
+	lookupEnv func(key string) (string, bool)
+	file      map[string]string // Settings of the config file by variable name
+}
+
+// lookup returns the value of a setting and whether it is set.
+func (s source) lookup(key string) (string, bool) {
+	if s.lookupEnv != nil {
+		if value, ok := s.lookupEnv(key); ok {
+			return value, true
+		}
+	}
+	value, ok := s.file[key]
+	return value, ok
+}
+
+// string retrieves a string or returns the given default.
+func (s source) string(key, defaultValue string) string {
+	if value, _ := s.lookup(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// list retrieves a comma-separated list or returns the given default. A
+// setting that is present but empty yields an empty list.
+func (s source) list(key string, defaultValue []string) []string {
+	value, ok := s.lookup(key)
+	if !ok {
+		return defaultValue
+	}
+	return List(value)
+}
+
+// int retrieves an integer or returns the given default.
+func (s source) int(key string, defaultValue int) int {
+	value, _ := s.lookup(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid %s value '%s', using default %d", key, value, defaultValue)
+		return defaultValue
+	}
+
+	return number
+}
+
+// bool retrieves a boolean (e.g. "true", "0") or returns the given default.
+func (s source) bool(key string, defaultValue bool) bool {
+	value, _ := s.lookup(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid %s value '%s', using default %t", key, value, defaultValue)
+		return defaultValue
+	}
+
+	return enabled
+}
+
+// float retrieves a floating-point number or returns the given default.
+func (s source) float(key string, defaultValue float64) float64 {
+	value, _ := s.lookup(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("Invalid %s value '%s', using default %g", key, value, defaultValue)
+		return defaultValue
+	}
+
+	return number
+}
+
+// duration retrieves a duration (e.g. "90s", "2m") or returns the given
+// default.
+func (s source) duration(key string, defaultValue time.Duration) time.Duration {
+	value, _ := s.lookup(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid %s value '%s', using default %s", key, value, defaultValue)
+		return defaultValue
+	}
+
+	return duration
+}
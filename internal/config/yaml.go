@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parseYAML reads the settings of a config file: a mapping of keys to
+// values, where a value is a scalar or a list of scalars. Mappings nested
+// under a key group settings into sections; their keys are settings like
+// the top-level ones, so
+//
+//	hub:
+//	  hub_message_ttl: 5s
+//
+// sets HUB_MESSAGE_TTL. List items are joined with commas, the form list
+// settings take in the environment.
+func parseYAML(data []byte) (map[string]string, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]string)
+	if len(document.Content) == 0 {
+		return settings, nil
+	}
+	if err := readSettings(document.Content[0], settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// readSettings adds the settings of a mapping node, and of the sections
+// nested in it, to settings.
+func readSettings(node *yaml.Node, settings map[string]string) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected \"key: value\" settings", node.Line)
+	}
+
+	for idx := 0; idx+1 < len(node.Content); idx += 2 {
+		key, value := node.Content[idx], resolveAlias(node.Content[idx+1])
+		if value.Kind == yaml.MappingNode {
+			if err := readSettings(value, settings); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, exists := settings[key.Value]; exists {
+			return fmt.Errorf("line %d: duplicate key %q", key.Line, key.Value)
+		}
+		setting, err := settingValue(key.Value, value)
+		if err != nil {
+			return err
+		}
+		settings[key.Value] = setting
+	}
+	return nil
+}
+
+// settingValue returns the value of a setting in its environment form.
+func settingValue(key string, node *yaml.Node) (string, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return scalarValue(node), nil
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			item = resolveAlias(item)
+			if item.Kind != yaml.ScalarNode {
+				return "", fmt.Errorf("line %d: items of %q must be plain values", item.Line, key)
+			}
+			items = append(items, scalarValue(item))
+		}
+		return strings.Join(items, ","), nil
+	}
+	return "", fmt.Errorf("line %d: unsupported value of %q", node.Line, key)
+}
+
+// scalarValue returns a scalar as written, or empty for null.
+func scalarValue(node *yaml.Node) string {
+	if node.ShortTag() == "!!null" {
+		return ""
+	}
+	return node.Value
+}
+
+// resolveAlias returns the node an alias refers to.
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
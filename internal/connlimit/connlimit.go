@@ -0,0 +1,194 @@
+// Package connlimit caps WebSocket connections per server and per client
+// IP and rate limits upgrades per IP, to protect the Hub against connection
+// floods:
+//
+//	limiter := connlimit.New(connlimit.WithMaxConnections(10000), connlimit.WithMaxPerIP(20))
+//	release, err := limiter.Acquire(ip)
+//	if err != nil {
+//	    return // 429
+//	}
+//	defer release()
+package connlimit
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"macro-analyst/pkg/metrics"
+)
+
+const (
+	// DefaultMaxConnections caps concurrent connections per server
+	DefaultMaxConnections = 10000
+
+	// DefaultMaxPerIP caps concurrent connections per client IP
+	DefaultMaxPerIP = 20
+
+	// DefaultUpgradesPerMinute limits new connections per client IP
+	DefaultUpgradesPerMinute = 60
+)
+
+// rateWindow is the window upgrade rates are counted in
+const rateWindow = time.Minute
+
+var (
+	// ErrServerFull is returned when the server holds its maximum of connections
+	ErrServerFull = errors.New("connlimit: too many connections")
+
+	// ErrTooManyFromIP is returned when an IP holds its maximum of connections
+	ErrTooManyFromIP = errors.New("connlimit: too many connections from this address")
+
+	// ErrRateLimited is returned when an IP opened too many connections
+	// within the last minute
+	ErrRateLimited = errors.New("connlimit: too many new connections from this address")
+)
+
+var rejectedConnections = metrics.Default.NewCounter(
+	"ws_connections_rejected_total",
+	"Number of WebSocket upgrades refused by connection limits.",
+)
+
+// upgrades counts the upgrades of one IP in the current rate window.
+type upgrades struct {
+	windowStart time.Time
+	count       int
+}
+
+// Limiter enforces connection limits. It is safe for concurrent use.
+type Limiter struct {
+	mu       sync.Mutex
+	total    int
+	perIP    map[string]int
+	upgrades map[string]*upgrades
+	pruned   time.Time // Last time ended rate windows were forgotten
+
+	maxConnections    int
+	maxPerIP          int
+	upgradesPerMinute int
+	now               func() time.Time
+}
+
+// Option is a functional option for configuring the Limiter.
+type Option func(*Limiter)
+
+// WithMaxConnections caps concurrent connections per server; 0 disables
+// the cap.
+func WithMaxConnections(limit int) Option {
+	return func(l *Limiter) {
+		l.maxConnections = limit
+	}
+}
+
+// WithMaxPerIP caps concurrent connections per client IP; 0 disables the
+// cap.
+func WithMaxPerIP(limit int) Option {
+	return func(l *Limiter) {
+		l.maxPerIP = limit
+	}
+}
+
+// WithUpgradesPerMinute limits how many connections a client IP may open
+// per minute; 0 disables the limit.
+func WithUpgradesPerMinute(limit int) Option {
+	return func(l *Limiter) {
+		l.upgradesPerMinute = limit
+	}
+}
+
+// New creates a Limiter with the default limits.
+func New(opts ...Option) *Limiter {
+	limiter := &Limiter{
+		perIP:             make(map[string]int),
+		upgrades:          make(map[string]*upgrades),
+		maxConnections:    DefaultMaxConnections,
+		maxPerIP:          DefaultMaxPerIP,
+		upgradesPerMinute: DefaultUpgradesPerMinute,
+		now:               time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(limiter)
+	}
+
+	return limiter
+}
+
+// Acquire admits a connection from ip, or returns ErrServerFull,
+// ErrTooManyFromIP or ErrRateLimited. The returned release must be called
+// once the connection closes; calling it again has no effect.
+func (l *Limiter) Acquire(ip string) (release func(), err error) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.pruneUpgrades(now)
+	window := l.upgrades[ip]
+	if window == nil || now.Sub(window.windowStart) >= rateWindow {
+		window = &upgrades{windowStart: now}
+	}
+
+	switch {
+	case l.maxConnections > 0 && l.total >= l.maxConnections:
+		err = ErrServerFull
+	case l.maxPerIP > 0 && l.perIP[ip] >= l.maxPerIP:
+		err = ErrTooManyFromIP
+	case l.upgradesPerMinute > 0 && window.count >= l.upgradesPerMinute:
+		err = ErrRateLimited
+	}
+	if err != nil {
+		rejectedConnections.Inc()
+		return nil, err
+	}
+
+	window.count++
+	l.upgrades[ip] = window
+	l.total++
+	l.perIP[ip]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { l.release(ip) })
+	}, nil
+}
+
+// RetryAfter returns how long until ip may open a connection again after
+// ErrRateLimited.
+func (l *Limiter) RetryAfter(ip string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	window := l.upgrades[ip]
+	if window == nil {
+		return 0
+	}
+	return max(window.windowStart.Add(rateWindow).Sub(l.now()), 0)
+}
+
+// release frees a connection of ip.
+func (l *Limiter) release(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.total--
+	if l.perIP[ip]--; l.perIP[ip] <= 0 {
+		delete(l.perIP, ip)
+	}
+}
+
+// pruneUpgrades forgets the upgrade counts of windows that ended, at most
+// once per window, so IPs that stopped connecting do not accumulate. The
+// caller holds l.mu.
+func (l *Limiter) pruneUpgrades(now time.Time) {
+	if now.Sub(l.pruned) < rateWindow {
+		return
+	}
+	l.pruned = now
+
+	for ip, window := range l.upgrades {
+		if now.Sub(window.windowStart) >= rateWindow {
+			delete(l.upgrades, ip)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package connlimit
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestAcquireLimits verifies connections beyond the server and per-IP caps
+// are refused until earlier ones are released.
+func TestAcquireLimits(t *testing.T) {
+	limiter := New(WithMaxConnections(3), WithMaxPerIP(2), WithUpgradesPerMinute(0))
+
+	releaseFirst, err := limiter.Acquire("10.0.0.1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := limiter.Acquire("10.0.0.1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := limiter.Acquire("10.0.0.1"); !errors.Is(err, ErrTooManyFromIP) {
+		t.Errorf("Expected ErrTooManyFromIP, got %v", err)
+	}
+	if _, err := limiter.Acquire("10.0.0.2"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := limiter.Acquire("10.0.0.3"); !errors.Is(err, ErrServerFull) {
+		t.Errorf("Expected ErrServerFull, got %v", err)
+	}
+
+	// Releasing twice frees a single connection
+	releaseFirst()
+	releaseFirst()
+	if _, err := limiter.Acquire("10.0.0.3"); err != nil {
+		t.Errorf("Expected a released connection to admit another, got %v", err)
+	}
+	if _, err := limiter.Acquire("10.0.0.1"); !errors.Is(err, ErrServerFull) {
+		t.Errorf("Expected ErrServerFull, got %v", err)
+	}
+}
+
+// TestAcquireRateLimit verifies upgrades per IP are limited per minute,
+// independently of released connections.
+func TestAcquireRateLimit(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	limiter := New(WithUpgradesPerMinute(2))
+	limiter.now = func() time.Time { return now }
+
+	for range 2 {
+		release, err := limiter.Acquire("10.0.0.1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		release()
+	}
+	if _, err := limiter.Acquire("10.0.0.1"); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited, got %v", err)
+	}
+	if _, err := limiter.Acquire("10.0.0.2"); err != nil {
+		t.Errorf("Expected other IPs to connect, got %v", err)
+	}
+
+	now = now.Add(45 * time.Second)
+	if retry := limiter.RetryAfter("10.0.0.1"); retry != 15*time.Second {
+		t.Errorf("Expected a retry after 15s, got %s", retry)
+	}
+
+	now = now.Add(15 * time.Second)
+	if _, err := limiter.Acquire("10.0.0.1"); err != nil {
+		t.Errorf("Expected a new window to admit the IP, got %v", err)
+	}
+}
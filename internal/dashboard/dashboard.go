@@ -0,0 +1,129 @@
+// Package dashboard defines dashboard configurations served to the
+// frontend: built-in templates for common views and user-saved variants, so
+// a layout follows its owner across devices.
+//
+// The server validates the widgets it knows how to feed with data and
+// stores the frontend's own layout state opaquely.
+package dashboard
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxNameLength bounds the length of a dashboard name
+	MaxNameLength = 64
+
+	// MaxWidgets bounds how many widgets a dashboard may hold
+	MaxWidgets = 24
+
+	// MaxSeriesPerWidget bounds how many series one widget may show
+	MaxSeriesPerWidget = 8
+
+	// MaxStateSize bounds the opaque frontend state in bytes
+	MaxStateSize = 64 * 1024
+)
+
+// Widget types the frontend knows how to render.
+const (
+	WidgetChart       = "chart"       // Series over time, from /api/analytics/align
+	WidgetTicker      = "ticker"      // Live prices from /ws/prices
+	WidgetStats       = "stats"       // Feed statistics from /api/crypto/stats
+	WidgetReturns     = "returns"     // Returns and Sharpe ratios from /api/analytics/returns
+	WidgetSeasonality = "seasonality" // Seasonality from /api/analytics/seasonality
+	WidgetSurprise    = "surprise"    // Macro surprise index from /api/analytics/surprise
+	WidgetFormula     = "formula"     // A user-defined formula from /api/formulas
+)
+
+var widgetTypes = []string{
+	WidgetChart, WidgetTicker, WidgetStats, WidgetReturns,
+	WidgetSeasonality, WidgetSurprise, WidgetFormula,
+}
+
+// ErrInvalidDashboard is returned for dashboards failing validation.
+var ErrInvalidDashboard = errors.New("invalid dashboard")
+
+// Position places a widget on the dashboard grid.
+type Position struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// Widget is one panel of a dashboard.
+type Widget struct {
+	Type     string   `json:"type"`
+	Title    string   `json:"title,omitempty"`
+	Series   []string `json:"series,omitempty"`  // e.g. "fred:WALCL", "crypto:BTCUSDT" or a formula ID
+	Symbols  []string `json:"symbols,omitempty"` // Crypto symbols of ticker, returns and seasonality widgets
+	Grid     string   `json:"grid,omitempty"`    // Chart grid: daily, weekly or monthly
+	Position Position `json:"position"`
+}
+
+// Dashboard is a named set of widgets.
+type Dashboard struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Template    bool            `json:"template"`           // Built-in and read-only
+	BasedOn     string          `json:"based_on,omitempty"` // Template the dashboard was saved from
+	Widgets     []Widget        `json:"widgets"`
+	State       json.RawMessage `json:"state,omitempty"` // Opaque frontend state, e.g. zoom or selected ranges
+	Owner       string          `json:"owner,omitempty"` // Usage key ID of the API key that saved it
+	CreatedAt   time.Time       `json:"created_at,omitzero"`
+	UpdatedAt   time.Time       `json:"updated_at,omitzero"`
+}
+
+// Validate checks the user-editable fields of a dashboard and trims its name.
+func (d *Dashboard) Validate() error {
+	d.Name = strings.TrimSpace(d.Name)
+	if d.Name == "" || len(d.Name) > MaxNameLength {
+		return fmt.Errorf("%w: name must be 1 to %d characters", ErrInvalidDashboard, MaxNameLength)
+	}
+	if len(d.Widgets) > MaxWidgets {
+		return fmt.Errorf("%w: more than %d widgets", ErrInvalidDashboard, MaxWidgets)
+	}
+	if len(d.State) > MaxStateSize {
+		return fmt.Errorf("%w: state larger than %d bytes", ErrInvalidDashboard, MaxStateSize)
+	}
+	if len(d.State) > 0 && !json.Valid(d.State) {
+		return fmt.Errorf("%w: state is not valid JSON", ErrInvalidDashboard)
+	}
+
+	for idx, widget := range d.Widgets {
+		if !isWidgetType(widget.Type) {
+			return fmt.Errorf("%w: widget %d has unknown type %q (expected one of %s)",
+				ErrInvalidDashboard, idx, widget.Type, strings.Join(widgetTypes, ", "))
+		}
+		if len(widget.Series) > MaxSeriesPerWidget || len(widget.Symbols) > MaxSeriesPerWidget {
+			return fmt.Errorf("%w: widget %d has more than %d series", ErrInvalidDashboard, idx, MaxSeriesPerWidget)
+		}
+		if p := widget.Position; p.X < 0 || p.Y < 0 || p.W < 0 || p.H < 0 {
+			return fmt.Errorf("%w: widget %d has a negative position", ErrInvalidDashboard, idx)
+		}
+	}
+	return nil
+}
+
+// NewID returns a random dashboard ID.
+func NewID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+func isWidgetType(widgetType string) bool {
+	for _, known := range widgetTypes {
+		if widgetType == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestTemplatesValid verifies the built-in dashboards pass validation.
+func TestTemplatesValid(t *testing.T) {
+	for _, template := range Templates() {
+		if err := template.Validate(); err != nil {
+			t.Errorf("Template %s: unexpected error: %v", template.ID, err)
+		}
+		if !template.Template {
+			t.Errorf("Template %s: expected it to be marked as template", template.ID)
+		}
+	}
+}
+
+// TestTemplatesAreCopies verifies callers cannot modify the built-in dashboards.
+func TestTemplatesAreCopies(t *testing.T) {
+	liquidity, _ := FindTemplate(TemplateLiquidity)
+	liquidity.Widgets[0].Title = "Changed"
+
+	again, exists := FindTemplate(TemplateLiquidity)
+	if !exists || again.Widgets[0].Title == "Changed" {
+		t.Errorf("Expected a fresh copy of the template, got %+v", again.Widgets[0])
+	}
+	if _, exists := FindTemplate("unknown"); exists {
+		t.Error("Expected unknown template to be missing")
+	}
+}
+
+// TestValidate verifies invalid dashboards are rejected.
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		dashboard Dashboard
+	}{
+		{"empty name", Dashboard{Name: "  "}},
+		{"long name", Dashboard{Name: strings.Repeat("x", MaxNameLength+1)}},
+		{"too many widgets", Dashboard{Name: "Big", Widgets: make([]Widget, MaxWidgets+1)}},
+		{"unknown widget", Dashboard{Name: "Odd", Widgets: []Widget{{Type: "pie"}}}},
+		{"too many series", Dashboard{Name: "Busy", Widgets: []Widget{{Type: WidgetChart, Series: make([]string, MaxSeriesPerWidget+1)}}}},
+		{"negative position", Dashboard{Name: "Off", Widgets: []Widget{{Type: WidgetStats, Position: Position{X: -1}}}}},
+		{"invalid state", Dashboard{Name: "Broken", State: json.RawMessage(`{"zoom":`)}},
+		{"large state", Dashboard{Name: "Heavy", State: json.RawMessage(`"` + strings.Repeat("x", MaxStateSize) + `"`)}},
+	}
+
+	for _, tt := range tests {
+		if err := tt.dashboard.Validate(); !errors.Is(err, ErrInvalidDashboard) {
+			t.Errorf("%s: expected ErrInvalidDashboard, got %v", tt.name, err)
+		}
+	}
+
+	valid := Dashboard{Name: " Mine ", State: json.RawMessage(`{"zoom":2}`)}
+	if err := valid.Validate(); err != nil || valid.Name != "Mine" {
+		t.Errorf("Expected valid dashboard with trimmed name, got %q and %v", valid.Name, err)
+	}
+}
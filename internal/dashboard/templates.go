@@ -0,0 +1,111 @@
+package dashboard
+
+// Built-in template IDs.
+const (
+	TemplateLiquidity = "liquidity"
+	TemplateRates     = "rates"
+	TemplateCrypto    = "crypto"
+)
+
+// Templates returns the built-in dashboards. Each call returns fresh
+// copies, so callers may modify them, e.g. to save a variant.
+func Templates() []Dashboard {
+	return []Dashboard{
+		{
+			ID:          TemplateLiquidity,
+			Name:        "Liquidity",
+			Description: "Fed balance sheet, Treasury cash and reverse repo against Bitcoin",
+			Template:    true,
+			Widgets: []Widget{
+				{
+					Type:     WidgetChart,
+					Title:    "Fed balance sheet vs BTC",
+					Series:   []string{"fred:WALCL", "crypto:BTCUSDT"},
+					Grid:     "weekly",
+					Position: Position{X: 0, Y: 0, W: 8, H: 4},
+				},
+				{
+					Type:     WidgetTicker,
+					Title:    "Live prices",
+					Symbols:  []string{"BTCUSDT", "ETHUSDT"},
+					Position: Position{X: 8, Y: 0, W: 4, H: 4},
+				},
+				{
+					Type:     WidgetChart,
+					Title:    "Treasury General Account and reverse repo",
+					Series:   []string{"fred:WTREGEN", "fred:RRPONTSYD"},
+					Grid:     "weekly",
+					Position: Position{X: 0, Y: 4, W: 12, H: 4},
+				},
+			},
+		},
+		{
+			ID:          TemplateRates,
+			Name:        "Rates",
+			Description: "Fed funds rate, inflation, the dollar and macro surprises",
+			Template:    true,
+			Widgets: []Widget{
+				{
+					Type:     WidgetChart,
+					Title:    "Fed funds rate vs CPI",
+					Series:   []string{"fred:FEDFUNDS", "fred:CPIAUCSL"},
+					Grid:     "monthly",
+					Position: Position{X: 0, Y: 0, W: 8, H: 4},
+				},
+				{
+					Type:     WidgetSurprise,
+					Title:    "Macro surprise index",
+					Position: Position{X: 8, Y: 0, W: 4, H: 4},
+				},
+				{
+					Type:     WidgetChart,
+					Title:    "US dollar index",
+					Series:   []string{"fred:DTWEXBGS"},
+					Grid:     "daily",
+					Position: Position{X: 0, Y: 4, W: 12, H: 4},
+				},
+			},
+		},
+		{
+			ID:          TemplateCrypto,
+			Name:        "Crypto",
+			Description: "Live prices, risk-adjusted returns and seasonality",
+			Template:    true,
+			Widgets: []Widget{
+				{
+					Type:     WidgetTicker,
+					Title:    "Live prices",
+					Symbols:  []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"},
+					Position: Position{X: 0, Y: 0, W: 6, H: 4},
+				},
+				{
+					Type:     WidgetReturns,
+					Title:    "Returns and Sharpe ratios",
+					Symbols:  []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"},
+					Position: Position{X: 6, Y: 0, W: 6, H: 4},
+				},
+				{
+					Type:     WidgetSeasonality,
+					Title:    "BTC seasonality",
+					Symbols:  []string{"BTCUSDT"},
+					Position: Position{X: 0, Y: 4, W: 8, H: 4},
+				},
+				{
+					Type:     WidgetStats,
+					Title:    "Feed health",
+					Position: Position{X: 8, Y: 4, W: 4, H: 4},
+				},
+			},
+		},
+	}
+}
+
+// FindTemplate returns the built-in dashboard with the given ID.
+func FindTemplate(id string) (Dashboard, bool) {
+	for _, template := range Templates() {
+		if template.ID == id {
+			return template, true
+		}
+	}
+	return Dashboard{}, false
+}
@@ -0,0 +1,120 @@
+// Package embed defines live ticker widgets that can be embedded in other
+// sites, such as blogs or newsletters, through an iframe. Each widget is
+// limited to its configured symbols and refresh rate, so embedding pages
+// cannot use it as an unrestricted price feed.
+package embed
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxTitleLength bounds the length of a widget title
+	MaxTitleLength = 64
+
+	// MaxSymbols bounds how many symbols one widget may show
+	MaxSymbols = 10
+
+	// DefaultRefreshSeconds is how often a widget's prices refresh by default
+	DefaultRefreshSeconds = 5
+
+	// MinRefreshSeconds and MaxRefreshSeconds bound a widget's refresh rate
+	MinRefreshSeconds = 1
+	MaxRefreshSeconds = 60
+)
+
+// Widget themes.
+const (
+	ThemeLight = "light"
+	ThemeDark  = "dark"
+)
+
+// ErrInvalidWidget is returned for widgets failing validation.
+var ErrInvalidWidget = errors.New("invalid embed widget")
+
+// Widget is an embeddable live ticker.
+type Widget struct {
+	ID             string    `json:"id"`
+	Title          string    `json:"title,omitempty"`
+	Symbols        []string  `json:"symbols"`
+	RefreshSeconds int       `json:"refresh_seconds"`
+	Theme          string    `json:"theme"`
+	Owner          string    `json:"owner,omitempty"` // Usage key ID of the API key that created it
+	CreatedAt      time.Time `json:"created_at,omitzero"`
+	UpdatedAt      time.Time `json:"updated_at,omitzero"`
+}
+
+// Validate checks the user-editable fields of a widget, normalizes its
+// title and symbols and fills in defaults.
+func (w *Widget) Validate() error {
+	w.Title = strings.TrimSpace(w.Title)
+	if len(w.Title) > MaxTitleLength {
+		return fmt.Errorf("%w: title longer than %d characters", ErrInvalidWidget, MaxTitleLength)
+	}
+
+	if len(w.Symbols) == 0 || len(w.Symbols) > MaxSymbols {
+		return fmt.Errorf("%w: symbols must list 1 to %d symbols", ErrInvalidWidget, MaxSymbols)
+	}
+	for idx, symbol := range w.Symbols {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if !isSymbol(symbol) {
+			return fmt.Errorf("%w: invalid symbol %q", ErrInvalidWidget, w.Symbols[idx])
+		}
+		w.Symbols[idx] = symbol
+	}
+
+	if w.RefreshSeconds == 0 {
+		w.RefreshSeconds = DefaultRefreshSeconds
+	}
+	if w.RefreshSeconds < MinRefreshSeconds || w.RefreshSeconds > MaxRefreshSeconds {
+		return fmt.Errorf("%w: refresh_seconds must be %d to %d", ErrInvalidWidget, MinRefreshSeconds, MaxRefreshSeconds)
+	}
+
+	switch w.Theme {
+	case "":
+		w.Theme = ThemeLight
+	case ThemeLight, ThemeDark:
+	default:
+		return fmt.Errorf("%w: theme must be %s or %s", ErrInvalidWidget, ThemeLight, ThemeDark)
+	}
+	return nil
+}
+
+// RefreshInterval returns how often the widget's prices refresh.
+func (w Widget) RefreshInterval() time.Duration {
+	return time.Duration(w.RefreshSeconds) * time.Second
+}
+
+// SymbolSet returns the widget's symbols as a set.
+func (w Widget) SymbolSet() map[string]bool {
+	symbols := make(map[string]bool, len(w.Symbols))
+	for _, symbol := range w.Symbols {
+		symbols[symbol] = true
+	}
+	return symbols
+}
+
+// NewID returns a random widget ID.
+func NewID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// isSymbol reports whether s looks like a Binance symbol such as BTCUSDT.
+func isSymbol(s string) bool {
+	if len(s) < 2 || len(s) > 20 {
+		return false
+	}
+	for _, c := range s {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package embed
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestValidate verifies widgets are normalized and defaulted.
+func TestValidate(t *testing.T) {
+	widget := Widget{Title: " Crypto ", Symbols: []string{"btcusdt", " ETHUSDT"}}
+	if err := widget.Validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if widget.Title != "Crypto" || widget.Symbols[0] != "BTCUSDT" || widget.Symbols[1] != "ETHUSDT" {
+		t.Errorf("Expected trimmed title and upper-case symbols, got %+v", widget)
+	}
+	if widget.RefreshSeconds != DefaultRefreshSeconds || widget.Theme != ThemeLight {
+		t.Errorf("Expected default refresh and theme, got %d and %q", widget.RefreshSeconds, widget.Theme)
+	}
+	if !widget.SymbolSet()["ETHUSDT"] {
+		t.Error("Expected ETHUSDT in the symbol set")
+	}
+}
+
+// TestValidateErrors verifies invalid widgets are rejected.
+func TestValidateErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		widget Widget
+	}{
+		{"no symbols", Widget{}},
+		{"too many symbols", Widget{Symbols: strings.Split(strings.Repeat("BTCUSDT,", MaxSymbols+1), ",")[:MaxSymbols+1]}},
+		{"invalid symbol", Widget{Symbols: []string{"BTC/USDT"}}},
+		{"refresh too fast", Widget{Symbols: []string{"BTCUSDT"}, RefreshSeconds: -1}},
+		{"refresh too slow", Widget{Symbols: []string{"BTCUSDT"}, RefreshSeconds: MaxRefreshSeconds + 1}},
+		{"unknown theme", Widget{Symbols: []string{"BTCUSDT"}, Theme: "neon"}},
+		{"long title", Widget{Symbols: []string{"BTCUSDT"}, Title: strings.Repeat("x", MaxTitleLength+1)}},
+	}
+
+	for _, tt := range tests {
+		if err := tt.widget.Validate(); !errors.Is(err, ErrInvalidWidget) {
+			t.Errorf("%s: expected ErrInvalidWidget, got %v", tt.name, err)
+		}
+	}
+}
+
+// TestRender verifies the page lists the symbols and escapes the title.
+func TestRender(t *testing.T) {
+	var page strings.Builder
+	widget := Widget{ID: "abc123", Title: "<b>Prices</b>", Symbols: []string{"BTCUSDT"}, Theme: ThemeDark}
+	if err := Render(&page, widget); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	html := page.String()
+	if strings.Contains(html, "<b>Prices</b>") || !strings.Contains(html, "&lt;b&gt;Prices&lt;/b&gt;") {
+		t.Error("Expected the title to be escaped")
+	}
+	if !strings.Contains(html, `<tr id="BTCUSDT">`) || !strings.Contains(html, `"abc123"`) {
+		t.Error("Expected a row per symbol and the widget ID in the script")
+	}
+}
@@ -0,0 +1,66 @@
+package embed
+
+import (
+	"html/template"
+	"io"
+)
+
+// ContentSecurityPolicy lets any site frame the widget page while keeping
+// the page itself from loading anything but its inline code and its stream.
+const ContentSecurityPolicy = "default-src 'none'; script-src 'unsafe-inline'; style-src 'unsafe-inline'; " +
+	"connect-src 'self'; frame-ancestors *"
+
+// page renders a widget as a self-contained HTML document that streams its
+// prices from /ws/embed/:id.
+var page = template.Must(template.New("embed").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{if .Title}}{{.Title}}{{else}}Live prices{{end}}</title>
+<style>
+body { margin: 0; font: 14px system-ui, sans-serif; background: {{if eq .Theme "dark"}}#111{{else}}#fff{{end}}; color: {{if eq .Theme "dark"}}#eee{{else}}#111{{end}}; }
+h1 { margin: 8px 12px 4px; font-size: 14px; font-weight: 600; }
+table { width: 100%; border-collapse: collapse; }
+td { padding: 4px 12px; font-variant-numeric: tabular-nums; }
+td.price, td.change { text-align: right; }
+.up { color: #16a34a; } .down { color: #dc2626; }
+footer { margin: 4px 12px; font-size: 11px; opacity: 0.6; }
+</style>
+</head>
+<body>
+{{if .Title}}<h1>{{.Title}}</h1>{{end}}
+<table>
+{{range .Symbols}}<tr id="{{.}}"><td>{{.}}</td><td class="price">-</td><td class="change"></td></tr>
+{{end}}</table>
+<footer>Market data: Binance</footer>
+<script>
+(function () {
+  var id = {{.ID}};
+  var url = (location.protocol === "https:" ? "wss://" : "ws://") + location.host + "/ws/embed/" + encodeURIComponent(id);
+  function render(update) {
+    var row = document.getElementById(update.symbol);
+    if (!row) return;
+    row.cells[1].textContent = update.price.toLocaleString(undefined, {maximumFractionDigits: 8});
+    row.cells[2].textContent = (update.changePercent >= 0 ? "+" : "") + update.changePercent.toFixed(2) + "%";
+    row.cells[2].className = "change " + (update.changePercent >= 0 ? "up" : "down");
+  }
+  function connect() {
+    var socket = new WebSocket(url);
+    socket.onmessage = function (event) {
+      var message = JSON.parse(event.data);
+      if (message.type === "multi_update") message.data.forEach(render);
+    };
+    socket.onclose = function () { setTimeout(connect, 5000); };
+  }
+  connect();
+})();
+</script>
+</body>
+</html>
+`))
+
+// Render writes the widget's HTML page.
+func Render(w io.Writer, widget Widget) error {
+	return page.Execute(w, widget)
+}
@@ -0,0 +1,307 @@
+// Package formula evaluates user-defined derived series such as net
+// liquidity ("WALCL - WTREGEN - RRPONTSYD") or BTC priced in the broad
+// dollar index ("BTCUSDT / DTWEXBGS").
+//
+// Expressions are parsed into a small syntax tree supporting only numbers,
+// series variables, + - * /, unary minus and parentheses, so evaluating a
+// user-supplied expression can never run arbitrary code:
+//
+//	expr, err := formula.Parse("(WALCL - WTREGEN) / 1e6")
+//	value, err := expr.Eval(map[string]float64{"WALCL": 7.5e6, "WTREGEN": 7e5})
+package formula
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxExpressionLength bounds the length of an expression in bytes
+	MaxExpressionLength = 256
+
+	// MaxVariables bounds how many distinct series an expression may use
+	MaxVariables = 8
+
+	// MaxDepth bounds the nesting of an expression
+	MaxDepth = 32
+)
+
+var (
+	// ErrSyntax is returned for expressions that cannot be parsed.
+	ErrSyntax = errors.New("invalid expression")
+
+	// ErrMissingVariable is returned when evaluating without a value for a
+	// variable of the expression.
+	ErrMissingVariable = errors.New("missing variable")
+
+	// ErrUndefined is returned when the result is not a finite number, e.g.
+	// after a division by zero.
+	ErrUndefined = errors.New("undefined result")
+)
+
+// Expr is a parsed expression. It is safe for concurrent use.
+type Expr struct {
+	source    string
+	root      node
+	variables []string
+}
+
+// node is a node of the expression syntax tree.
+type node interface {
+	eval(values map[string]float64) (float64, error)
+}
+
+type number float64
+
+func (n number) eval(map[string]float64) (float64, error) {
+	return float64(n), nil
+}
+
+type variable string
+
+func (v variable) eval(values map[string]float64) (float64, error) {
+	value, exists := values[string(v)]
+	if !exists {
+		return 0, fmt.Errorf("%w: %s", ErrMissingVariable, string(v))
+	}
+	return value, nil
+}
+
+type negate struct {
+	operand node
+}
+
+func (n negate) eval(values map[string]float64) (float64, error) {
+	value, err := n.operand.eval(values)
+	return -value, err
+}
+
+type binary struct {
+	op          byte
+	left, right node
+}
+
+func (b binary) eval(values map[string]float64) (float64, error) {
+	left, err := b.left.eval(values)
+	if err != nil {
+		return 0, err
+	}
+	right, err := b.right.eval(values)
+	if err != nil {
+		return 0, err
+	}
+
+	switch b.op {
+	case '+':
+		return left + right, nil
+	case '-':
+		return left - right, nil
+	case '*':
+		return left * right, nil
+	default:
+		if right == 0 {
+			return 0, fmt.Errorf("%w: division by zero", ErrUndefined)
+		}
+		return left / right, nil
+	}
+}
+
+// Parse parses an expression. Variables are uppercased, so "btcusdt" and
+// "BTCUSDT" refer to the same series.
+func Parse(source string) (*Expr, error) {
+	if len(source) > MaxExpressionLength {
+		return nil, fmt.Errorf("%w: longer than %d characters", ErrSyntax, MaxExpressionLength)
+	}
+
+	p := &parser{input: source, seen: make(map[string]bool)}
+	root, err := p.parseSum(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.input) {
+		return nil, p.errorf("unexpected %q", p.input[p.pos])
+	}
+	if len(p.variables) == 0 {
+		return nil, fmt.Errorf("%w: must reference at least one series", ErrSyntax)
+	}
+
+	return &Expr{source: strings.TrimSpace(source), root: root, variables: p.variables}, nil
+}
+
+// String returns the expression as written.
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Variables returns the distinct variables in order of first appearance.
+func (e *Expr) Variables() []string {
+	return append([]string(nil), e.variables...)
+}
+
+// Eval evaluates the expression with the given variable values.
+func (e *Expr) Eval(values map[string]float64) (float64, error) {
+	value, err := e.root.eval(values)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("%w: %v", ErrUndefined, value)
+	}
+	return value, nil
+}
+
+// parser is a recursive descent parser over the expression grammar:
+//
+//	sum     = product { ("+" | "-") product }
+//	product = unary { ("*" | "/") unary }
+//	unary   = "-" unary | primary
+//	primary = number | variable | "(" sum ")"
+type parser struct {
+	input     string
+	pos       int
+	variables []string
+	seen      map[string]bool
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w at position %d: %s", ErrSyntax, p.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// peek returns the next non-space byte, or 0 at the end of the input.
+func (p *parser) peek() byte {
+	p.skipSpace()
+	if p.pos == len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *parser) parseSum(depth int) (node, error) {
+	left, err := p.parseProduct(depth)
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek(); op == '+' || op == '-'; op = p.peek() {
+		p.pos++
+		right, err := p.parseProduct(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseProduct(depth int) (node, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek(); op == '*' || op == '/'; op = p.peek() {
+		p.pos++
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary(depth int) (node, error) {
+	if depth > MaxDepth {
+		return nil, p.errorf("nested deeper than %d levels", MaxDepth)
+	}
+	if p.peek() == '-' {
+		p.pos++
+		operand, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return negate{operand: operand}, nil
+	}
+	return p.parsePrimary(depth)
+}
+
+func (p *parser) parsePrimary(depth int) (node, error) {
+	switch c := p.peek(); {
+	case c == 0:
+		return nil, p.errorf("unexpected end of expression")
+	case c == '(':
+		p.pos++
+		inner, err := p.parseSum(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, p.errorf("expected ')'")
+		}
+		p.pos++
+		return inner, nil
+	case isDigit(c) || c == '.':
+		return p.parseNumber()
+	case isLetter(c):
+		return p.parseVariable()
+	default:
+		return nil, p.errorf("unexpected %q", c)
+	}
+}
+
+func (p *parser) parseNumber() (node, error) {
+	start := p.pos
+	for p.pos < len(p.input) && (isDigit(p.input[p.pos]) || p.input[p.pos] == '.') {
+		p.pos++
+	}
+	// Exponent, e.g. 1e6 or 2.5E-3
+	if p.pos < len(p.input) && (p.input[p.pos] == 'e' || p.input[p.pos] == 'E') {
+		p.pos++
+		if p.pos < len(p.input) && (p.input[p.pos] == '+' || p.input[p.pos] == '-') {
+			p.pos++
+		}
+		for p.pos < len(p.input) && isDigit(p.input[p.pos]) {
+			p.pos++
+		}
+	}
+
+	text := p.input[start:p.pos]
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		p.pos = start
+		return nil, p.errorf("invalid number %q", text)
+	}
+	return number(value), nil
+}
+
+func (p *parser) parseVariable() (node, error) {
+	start := p.pos
+	for p.pos < len(p.input) && (isLetter(p.input[p.pos]) || isDigit(p.input[p.pos]) || p.input[p.pos] == ':') {
+		p.pos++
+	}
+
+	name := strings.ToUpper(p.input[start:p.pos])
+	if !p.seen[name] {
+		if len(p.variables) == MaxVariables {
+			return nil, p.errorf("more than %d series", MaxVariables)
+		}
+		p.seen[name] = true
+		p.variables = append(p.variables, name)
+	}
+	return variable(name), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || c == '_'
+}
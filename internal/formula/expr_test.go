@@ -0,0 +1,93 @@
+package formula
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestEval verifies operator precedence, unary minus and parentheses.
+func TestEval(t *testing.T) {
+	values := map[string]float64{"WALCL": 7500, "WTREGEN": 700, "RRPONTSYD": 300, "BTCUSDT": 60000, "DTWEXBGS": 120}
+
+	tests := []struct {
+		expression string
+		expected   float64
+	}{
+		{"WALCL - WTREGEN - RRPONTSYD", 6500},
+		{"BTCUSDT / DTWEXBGS", 500},
+		{"WALCL - WTREGEN * 2", 6100},
+		{"(WALCL - WTREGEN) * 2", 13600},
+		{"-WTREGEN + -(-RRPONTSYD)", -400},
+		{"walcl / 1e3 + .5", 8},
+		{"BTCUSDT * 2.5E-3", 150},
+	}
+
+	for _, tt := range tests {
+		expr, err := Parse(tt.expression)
+		if err != nil {
+			t.Errorf("%q: unexpected parse error: %v", tt.expression, err)
+			continue
+		}
+
+		value, err := expr.Eval(values)
+		if err != nil || math.Abs(value-tt.expected) > 1e-9 {
+			t.Errorf("%q: expected %v, got %v (%v)", tt.expression, tt.expected, value, err)
+		}
+	}
+}
+
+// TestParseErrors verifies malformed and oversized expressions are rejected.
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"42",
+		"WALCL -",
+		"(WALCL",
+		"WALCL)",
+		"WALCL ^ 2",
+		"os.Exit(1)",
+		"WALCL WTREGEN",
+		"1.2.3 * WALCL",
+		"A + B + C + D + E + F + G + H + I",
+		strings.Repeat("(", MaxDepth+2) + "WALCL" + strings.Repeat(")", MaxDepth+2),
+		"WALCL + " + strings.Repeat("1 + ", MaxExpressionLength/4),
+	}
+
+	for _, expression := range tests {
+		if _, err := Parse(expression); !errors.Is(err, ErrSyntax) {
+			t.Errorf("%q: expected ErrSyntax, got %v", expression, err)
+		}
+	}
+}
+
+// TestVariables verifies variables are uppercased, deduplicated and kept in
+// order of appearance.
+func TestVariables(t *testing.T) {
+	expr, err := Parse("btcusdt / fred:DTWEXBGS - BTCUSDT")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"BTCUSDT", "FRED:DTWEXBGS"}
+	if variables := expr.Variables(); !reflect.DeepEqual(variables, expected) {
+		t.Errorf("Expected %v, got %v", expected, variables)
+	}
+}
+
+// TestEvalErrors verifies missing variables and undefined results are errors.
+func TestEvalErrors(t *testing.T) {
+	expr, _ := Parse("WALCL / WTREGEN")
+
+	if _, err := expr.Eval(map[string]float64{"WALCL": 1}); !errors.Is(err, ErrMissingVariable) {
+		t.Errorf("Expected ErrMissingVariable, got %v", err)
+	}
+	if _, err := expr.Eval(map[string]float64{"WALCL": 1, "WTREGEN": 0}); !errors.Is(err, ErrUndefined) {
+		t.Errorf("Expected ErrUndefined, got %v", err)
+	}
+	if _, err := expr.Eval(map[string]float64{"WALCL": math.Inf(1), "WTREGEN": 1}); !errors.Is(err, ErrUndefined) {
+		t.Errorf("Expected ErrUndefined for infinity, got %v", err)
+	}
+}
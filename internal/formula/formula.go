@@ -0,0 +1,104 @@
+package formula
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"macro-analyst/internal/analytics"
+)
+
+// MaxNameLength bounds the length of a formula name
+const MaxNameLength = 64
+
+// Source prefixes variables may carry to pick the data source explicitly,
+// matching the series IDs of the analytics endpoints.
+const (
+	PrefixFRED   = "FRED:"
+	PrefixCrypto = "CRYPTO:"
+)
+
+// quoteAssets are the Binance quote assets that mark an unprefixed variable
+// as a crypto symbol rather than a FRED ticker.
+var quoteAssets = []string{"USDT", "USDC", "FDUSD", "TUSD", "BUSD"}
+
+// ErrInvalidName is returned for empty or overly long formula names.
+var ErrInvalidName = errors.New("invalid formula name")
+
+// Formula is a user-defined derived series.
+type Formula struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Expression string            `json:"expression"`
+	Series     map[string]string `json:"series"` // Variable -> series ID, e.g. "WALCL" -> "fred:WALCL"
+	Owner      string            `json:"owner"`  // Usage key ID of the API key that created it
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+
+	expr *Expr
+}
+
+// New validates and parses a formula. The ID is left to the caller, see NewID.
+func New(name, expression string) (Formula, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > MaxNameLength {
+		return Formula{}, fmt.Errorf("%w: must be 1 to %d characters", ErrInvalidName, MaxNameLength)
+	}
+
+	expr, err := Parse(expression)
+	if err != nil {
+		return Formula{}, err
+	}
+
+	series := make(map[string]string, len(expr.variables))
+	for _, variable := range expr.variables {
+		series[variable] = SeriesID(variable)
+	}
+
+	return Formula{
+		Name:       name,
+		Expression: expr.String(),
+		Series:     series,
+		expr:       expr,
+	}, nil
+}
+
+// NewID returns a random formula ID.
+func NewID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// Variables returns the variables of the formula in order of first appearance.
+func (f Formula) Variables() []string {
+	return f.expr.Variables()
+}
+
+// Eval evaluates the formula with the given variable values.
+func (f Formula) Eval(values map[string]float64) (float64, error) {
+	return f.expr.Eval(values)
+}
+
+// SeriesID maps a variable to the analytics series ID it reads from:
+// "fred:<ticker>" or "crypto:<symbol>". Variables may be prefixed with
+// "fred:" or "crypto:"; unprefixed variables ending in a quote asset such as
+// USDT are crypto symbols and all others are FRED tickers.
+func SeriesID(variable string) string {
+	switch {
+	case strings.HasPrefix(variable, PrefixFRED):
+		return analytics.SourceFRED + ":" + strings.TrimPrefix(variable, PrefixFRED)
+	case strings.HasPrefix(variable, PrefixCrypto):
+		return analytics.SourceCrypto + ":" + strings.TrimPrefix(variable, PrefixCrypto)
+	}
+
+	for _, quote := range quoteAssets {
+		if strings.HasSuffix(variable, quote) && len(variable) > len(quote) {
+			return analytics.SourceCrypto + ":" + variable
+		}
+	}
+	return analytics.SourceFRED + ":" + variable
+}
@@ -0,0 +1,65 @@
+package formula
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestNew verifies formulas map their variables to series IDs.
+func TestNew(t *testing.T) {
+	f, err := New(" Net liquidity ", " WALCL - WTREGEN - RRPONTSYD ")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if f.Name != "Net liquidity" || f.Expression != "WALCL - WTREGEN - RRPONTSYD" {
+		t.Errorf("Expected trimmed name and expression, got %q and %q", f.Name, f.Expression)
+	}
+	expected := map[string]string{"WALCL": "fred:WALCL", "WTREGEN": "fred:WTREGEN", "RRPONTSYD": "fred:RRPONTSYD"}
+	if !reflect.DeepEqual(f.Series, expected) {
+		t.Errorf("Expected series %v, got %v", expected, f.Series)
+	}
+}
+
+// TestNewErrors verifies invalid names and expressions are rejected.
+func TestNewErrors(t *testing.T) {
+	if _, err := New("", "WALCL"); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("Expected ErrInvalidName for empty name, got %v", err)
+	}
+	if _, err := New(strings.Repeat("x", MaxNameLength+1), "WALCL"); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("Expected ErrInvalidName for long name, got %v", err)
+	}
+	if _, err := New("Broken", "WALCL +"); !errors.Is(err, ErrSyntax) {
+		t.Errorf("Expected ErrSyntax, got %v", err)
+	}
+}
+
+// TestSeriesID verifies variables resolve to FRED or crypto series.
+func TestSeriesID(t *testing.T) {
+	tests := map[string]string{
+		"WALCL":          "fred:WALCL",
+		"BTCUSDT":        "crypto:BTCUSDT",
+		"ETHUSDC":        "crypto:ETHUSDC",
+		"USDT":           "fred:USDT",
+		"FRED:DEXUSEU":   "fred:DEXUSEU",
+		"CRYPTO:ETHBTC":  "crypto:ETHBTC",
+		"CRYPTO:BTCUSDT": "crypto:BTCUSDT",
+	}
+
+	for variable, expected := range tests {
+		if id := SeriesID(variable); id != expected {
+			t.Errorf("%s: expected %s, got %s", variable, expected, id)
+		}
+	}
+}
+
+// TestNewID verifies IDs are random hex strings.
+func TestNewID(t *testing.T) {
+	first, second := NewID(), NewID()
+
+	if len(first) != 16 || first == second {
+		t.Errorf("Expected distinct 16-character IDs, got %q and %q", first, second)
+	}
+}
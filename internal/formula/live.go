@@ -0,0 +1,180 @@
+package formula
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"macro-analyst/internal/analytics"
+	"macro-analyst/pkg/fred"
+)
+
+const (
+	// DefaultInterval is how often live formula values are recomputed
+	DefaultInterval = 5 * time.Second
+
+	// FREDRefreshInterval is how long a fetched FRED value is reused; FRED
+	// series update daily at most
+	FREDRefreshInterval = time.Hour
+)
+
+// PriceLookup returns the live price of a crypto symbol.
+type PriceLookup func(symbol string) (float64, bool)
+
+// Value is the live value of a formula.
+type Value struct {
+	ID    string  `json:"id"`
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+}
+
+// Update is pushed to WebSocket clients when formula values change.
+type Update struct {
+	Type      string  `json:"type"` // Always "formula_update"
+	Values    []Value `json:"values"`
+	Timestamp int64   `json:"timestamp"` // Unix ms when the values were computed
+}
+
+// fredValue is a cached FRED observation.
+type fredValue struct {
+	value     float64
+	valid     bool // False until a fetch succeeded
+	fetchedAt time.Time
+}
+
+// Engine keeps formula values up to date from live crypto prices and the
+// latest FRED observations. It is safe for concurrent use.
+type Engine struct {
+	mu     sync.Mutex
+	latest map[string]float64   // Formula ID -> latest value
+	fred   map[string]fredValue // Ticker -> cached observation
+
+	client fred.Client
+	prices PriceLookup
+	now    func() time.Time
+}
+
+// NewEngine creates an Engine. FRED variables cannot be resolved when
+// client is nil; crypto variables cannot be resolved when prices is nil.
+func NewEngine(client fred.Client, prices PriceLookup) *Engine {
+	return &Engine{
+		latest: make(map[string]float64),
+		fred:   make(map[string]fredValue),
+		client: client,
+		prices: prices,
+		now:    time.Now,
+	}
+}
+
+// Evaluate recomputes the formulas and returns the values that changed
+// since the last evaluation. Formulas with an unresolved variable keep
+// their previous value.
+func (e *Engine) Evaluate(ctx context.Context, formulas []Formula) []Value {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var changed []Value
+	live := make(map[string]bool, len(formulas))
+	for _, f := range formulas {
+		live[f.ID] = true
+
+		values := make(map[string]float64, len(f.Series))
+		resolved := true
+		for variable, seriesID := range f.Series {
+			value, ok := e.resolve(ctx, seriesID)
+			if !ok {
+				resolved = false
+				break
+			}
+			values[variable] = value
+		}
+		if !resolved {
+			continue
+		}
+
+		value, err := f.Eval(values)
+		if err != nil {
+			continue
+		}
+		if previous, exists := e.latest[f.ID]; exists && previous == value {
+			continue
+		}
+		e.latest[f.ID] = value
+		changed = append(changed, Value{ID: f.ID, Name: f.Name, Value: value})
+	}
+
+	// Forget formulas that were deleted
+	for id := range e.latest {
+		if !live[id] {
+			delete(e.latest, id)
+		}
+	}
+	return changed
+}
+
+// Latest returns the last computed value of a formula.
+func (e *Engine) Latest(id string) (float64, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	value, exists := e.latest[id]
+	return value, exists
+}
+
+// resolve returns the live value of a series. The caller must hold e.mu.
+func (e *Engine) resolve(ctx context.Context, seriesID string) (float64, bool) {
+	source, name, _ := strings.Cut(seriesID, ":")
+	switch source {
+	case analytics.SourceCrypto:
+		if e.prices == nil {
+			return 0, false
+		}
+		return e.prices(name)
+	case analytics.SourceFRED:
+		return e.resolveFRED(ctx, name)
+	default:
+		return 0, false
+	}
+}
+
+// resolveFRED returns the latest observation of a FRED ticker, fetching it
+// at most once per FREDRefreshInterval. A failed fetch falls back to the
+// cached value and is not retried before the next refresh either, so an
+// unknown ticker does not hit FRED on every evaluation. The caller must
+// hold e.mu.
+func (e *Engine) resolveFRED(ctx context.Context, ticker string) (float64, bool) {
+	cached, exists := e.fred[ticker]
+	if (exists && e.now().Sub(cached.fetchedAt) < FREDRefreshInterval) || e.client == nil {
+		return cached.value, cached.valid
+	}
+
+	cached.fetchedAt = e.now()
+	e.fred[ticker] = cached
+
+	latest, err := e.client.GetLatestValue(ctx, fred.Ticker(ticker))
+	if err != nil {
+		log.Printf("Failed to fetch %s for formulas: %v", ticker, err)
+		return cached.value, cached.valid
+	}
+	// FRED marks missing observations with "."
+	value, err := strconv.ParseFloat(latest.Value, 64)
+	if err != nil {
+		return cached.value, cached.valid
+	}
+
+	e.fred[ticker] = fredValue{value: value, valid: true, fetchedAt: cached.fetchedAt}
+	return value, true
+}
+
+// NewUpdate encodes the WebSocket message for changed formula values.
+func NewUpdate(values []Value, computedAt time.Time) ([]byte, error) {
+	return json.Marshal(&Update{
+		Type:      "formula_update",
+		Values:    values,
+		Timestamp: computedAt.UnixMilli(),
+	})
+}
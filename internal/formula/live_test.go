@@ -0,0 +1,157 @@
+package formula
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"macro-analyst/pkg/fred"
+)
+
+// countingFREDClient returns fixed latest values and counts fetches.
+type countingFREDClient struct {
+	values  map[fred.Ticker]string
+	fetches map[fred.Ticker]int
+}
+
+func (c *countingFREDClient) GetSeriesObservations(ctx context.Context, ticker fred.Ticker, opts *fred.QueryOptions) (*fred.SeriesData, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *countingFREDClient) GetLatestValue(ctx context.Context, ticker fred.Ticker) (*fred.LatestValue, error) {
+	c.fetches[ticker]++
+	value, exists := c.values[ticker]
+	if !exists {
+		return nil, errors.New("unknown series")
+	}
+	return &fred.LatestValue{Ticker: ticker, Value: value}, nil
+}
+
+func (c *countingFREDClient) GetMultipleLatest(ctx context.Context, tickers []fred.Ticker) (*fred.MultiTickerResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *countingFREDClient) GetSeriesInfo(ctx context.Context, ticker fred.Ticker) (*fred.FREDSeriesInfo, error) {
+	return nil, errors.New("not implemented")
+}
+
+// mustNew creates a formula with the given ID or fails the test.
+func mustNew(t *testing.T, id, expression string) Formula {
+	t.Helper()
+
+	f, err := New(id, expression)
+	if err != nil {
+		t.Fatalf("Failed to create formula: %v", err)
+	}
+	f.ID = id
+	return f
+}
+
+// TestEngineEvaluate verifies only changed values are returned and FRED
+// values are cached.
+func TestEngineEvaluate(t *testing.T) {
+	// Arrange
+	client := &countingFREDClient{
+		values:  map[fred.Ticker]string{"DTWEXBGS": "120"},
+		fetches: make(map[fred.Ticker]int),
+	}
+	prices := map[string]float64{"BTCUSDT": 60000}
+	engine := NewEngine(client, func(symbol string) (float64, bool) {
+		price, exists := prices[symbol]
+		return price, exists
+	})
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	engine.now = func() time.Time { return now }
+
+	formulas := []Formula{
+		mustNew(t, "btc-dxy", "BTCUSDT / DTWEXBGS"),
+		mustNew(t, "eth", "ETHUSDT * 2"), // No live price
+	}
+
+	// Act
+	first := engine.Evaluate(context.Background(), formulas)
+	unchanged := engine.Evaluate(context.Background(), formulas)
+	prices["BTCUSDT"] = 72000
+	changed := engine.Evaluate(context.Background(), formulas)
+
+	// Assert
+	if len(first) != 1 || first[0].ID != "btc-dxy" || first[0].Value != 500 {
+		t.Errorf("Expected initial value 500, got %+v", first)
+	}
+	if len(unchanged) != 0 {
+		t.Errorf("Expected no values without price changes, got %+v", unchanged)
+	}
+	if len(changed) != 1 || changed[0].Value != 600 {
+		t.Errorf("Expected changed value 600, got %+v", changed)
+	}
+	if client.fetches["DTWEXBGS"] != 1 {
+		t.Errorf("Expected DTWEXBGS to be fetched once, got %d", client.fetches["DTWEXBGS"])
+	}
+	if value, exists := engine.Latest("btc-dxy"); !exists || value != 600 {
+		t.Errorf("Expected latest value 600, got %v (%v)", value, exists)
+	}
+	if _, exists := engine.Latest("eth"); exists {
+		t.Error("Expected no value for a formula without live price")
+	}
+}
+
+// TestEngineFREDRefresh verifies FRED values are refetched after the
+// refresh interval and failed fetches are not retried before it.
+func TestEngineFREDRefresh(t *testing.T) {
+	// Arrange
+	client := &countingFREDClient{
+		values:  map[fred.Ticker]string{"WALCL": "7500"},
+		fetches: make(map[fred.Ticker]int),
+	}
+	engine := NewEngine(client, nil)
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	engine.now = func() time.Time { return now }
+	formulas := []Formula{mustNew(t, "fed", "WALCL"), mustNew(t, "unknown", "NOSUCHSERIES")}
+
+	// Act
+	engine.Evaluate(context.Background(), formulas)
+	engine.Evaluate(context.Background(), formulas)
+	now = now.Add(FREDRefreshInterval)
+	client.values["WALCL"] = "7400"
+	refreshed := engine.Evaluate(context.Background(), formulas)
+
+	// Assert
+	if client.fetches["NOSUCHSERIES"] != 2 {
+		t.Errorf("Expected the unknown series to be fetched once per refresh, got %d", client.fetches["NOSUCHSERIES"])
+	}
+	if len(refreshed) != 1 || refreshed[0].Value != 7400 {
+		t.Errorf("Expected refreshed value 7400, got %+v", refreshed)
+	}
+}
+
+// TestEngineForgetsDeletedFormulas verifies deleted formulas lose their value.
+func TestEngineForgetsDeletedFormulas(t *testing.T) {
+	engine := NewEngine(nil, func(string) (float64, bool) { return 1, true })
+	engine.Evaluate(context.Background(), []Formula{mustNew(t, "btc", "BTCUSDT")})
+
+	engine.Evaluate(context.Background(), nil)
+
+	if _, exists := engine.Latest("btc"); exists {
+		t.Error("Expected the deleted formula to be forgotten")
+	}
+}
+
+// TestNewUpdate verifies the WebSocket message format.
+func TestNewUpdate(t *testing.T) {
+	computedAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	data, err := NewUpdate([]Value{{ID: "abc", Name: "Net liquidity", Value: 6500}}, computedAt)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var update Update
+	if err := json.Unmarshal(data, &update); err != nil {
+		t.Fatalf("Failed to decode update: %v", err)
+	}
+	if update.Type != "formula_update" || len(update.Values) != 1 || update.Timestamp != computedAt.UnixMilli() {
+		t.Errorf("Unexpected update: %+v", update)
+	}
+}
@@ -0,0 +1,399 @@
+// Package fredstore keeps the FRED observations derived metrics read, so
+// net liquidity and the analytics endpoints are computed from stored
+// history instead of being fetched from FRED on each request.
+//
+// A Store wraps a fred.Client and implements it. Requests for observations
+// in levels from a start date are answered from the store once it holds the
+// series from that date; otherwise the series is fetched from that date on
+// and kept. Series older than the maximum age are topped up with the
+// observations FRED published since. Other requests pass through.
+//
+// Concurrent requests for a ticker the store cannot answer yet share one
+// fetch. On start, Seed backfills the history of the given tickers,
+// reporting its progress. With a file the store is persisted after each
+// fetch that changes it, so only the first start fetches the full history
+// and later ones top it up:
+//
+//	store := fredstore.New(fredClient, fredstore.WithFile("data/fred-history.json"))
+//	if err := store.Load(); err != nil {
+//	    log.Printf("Failed to load FRED history: %v", err)
+//	}
+//	go store.Seed(ctx, macro.NetLiquidityTickers, time.Now().AddDate(-2, 0, 0))
+//	store.Progress() // {"state":"seeding","total":3,"done":1,"current":"WTREGEN",...}
+package fredstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"macro-analyst/pkg/fred"
+)
+
+const (
+	// DefaultMaxAge is how long a stored series is served before it is
+	// topped up from FRED
+	DefaultMaxAge = time.Hour
+
+	// DefaultSeedPeriod is how much history Seed backfills by default,
+	// enough for the analytics ranges and the carry-forward before them
+	DefaultSeedPeriod = 2 * 365 * 24 * time.Hour
+
+	// DateLayout is the format of observation dates
+	DateLayout = "2006-01-02"
+
+	// observationLimit is the largest page the FRED API accepts
+	observationLimit = 100000
+)
+
+// Seeding states reported by Progress.
+const (
+	SeedPending = "pending"
+	SeedRunning = "seeding"
+	SeedDone    = "done"
+)
+
+// Progress is how far seeding got.
+type Progress struct {
+	State      string     `json:"state"`             // SeedPending, SeedRunning or SeedDone
+	Since      string     `json:"since,omitempty"`   // First date seeded
+	Total      int        `json:"total"`             // Tickers to seed
+	Done       int        `json:"done"`              // Tickers seeded, including failed ones
+	Current    string     `json:"current,omitempty"` // Ticker being fetched
+	Failed     []string   `json:"failed,omitempty"`  // Tickers that failed to load
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// entry is a stored series.
+type entry struct {
+	Data      fred.SeriesData `json:"data"`       // Levels, oldest first
+	From      string          `json:"from"`       // First date held
+	FetchedAt time.Time       `json:"fetched_at"` // Last fetch from FRED
+}
+
+// Store holds FRED observations by ticker. It is safe for concurrent use.
+type Store struct {
+	client fred.Client
+
+	mu       sync.RWMutex
+	series   map[fred.Ticker]*entry
+	progress Progress
+	fills    map[fred.Ticker]*sync.Mutex // Serializes fetches per ticker
+	saveMu   sync.Mutex                  // Serializes writes of the file
+
+	file   string
+	maxAge time.Duration
+	now    func() time.Time
+}
+
+// Option is a functional option for configuring the Store.
+type Option func(*Store)
+
+// WithFile sets the file observations are persisted to. An empty path
+// disables persistence.
+func WithFile(path string) Option {
+	return func(s *Store) {
+		s.file = path
+	}
+}
+
+// WithMaxAge sets how long a stored series is served before it is topped
+// up from FRED.
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(s *Store) {
+		s.maxAge = maxAge
+	}
+}
+
+// New creates an empty Store fetching from client.
+func New(client fred.Client, opts ...Option) *Store {
+	store := &Store{
+		client:   client,
+		series:   make(map[fred.Ticker]*entry),
+		fills:    make(map[fred.Ticker]*sync.Mutex),
+		progress: Progress{State: SeedPending},
+		maxAge:   DefaultMaxAge,
+		now:      time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(store)
+	}
+
+	return store
+}
+
+// GetSeriesObservations returns observations of a ticker, from the store
+// when opts ask for levels from a start date.
+func (s *Store) GetSeriesObservations(ctx context.Context, ticker fred.Ticker, opts *fred.QueryOptions) (*fred.SeriesData, error) {
+	if opts == nil || opts.StartDate == "" || (opts.Units != "" && opts.Units != fred.UnitsLevels) {
+		return s.client.GetSeriesObservations(ctx, ticker, opts)
+	}
+
+	if err := s.fill(ctx, ticker, opts.StartDate); err != nil {
+		return nil, err
+	}
+	return s.read(ticker, opts), nil
+}
+
+// GetLatestValue passes through to FRED.
+func (s *Store) GetLatestValue(ctx context.Context, ticker fred.Ticker) (*fred.LatestValue, error) {
+	return s.client.GetLatestValue(ctx, ticker)
+}
+
+// GetMultipleLatest passes through to FRED.
+func (s *Store) GetMultipleLatest(ctx context.Context, tickers []fred.Ticker) (*fred.MultiTickerResponse, error) {
+	return s.client.GetMultipleLatest(ctx, tickers)
+}
+
+// GetSeriesInfo passes through to FRED.
+func (s *Store) GetSeriesInfo(ctx context.Context, ticker fred.Ticker) (*fred.FREDSeriesInfo, error) {
+	return s.client.GetSeriesInfo(ctx, ticker)
+}
+
+// fill makes sure the store holds a ticker from the date from, fetching
+// the series from there if not, and tops it up once older than the
+// maximum age. A failed top-up leaves the stored series to be served.
+// Fills of a ticker run one at a time, so callers waiting on a fetch are
+// answered from its result instead of fetching again. The store is
+// persisted when a fetch changed it.
+func (s *Store) fill(ctx context.Context, ticker fred.Ticker, from string) error {
+	fillMu := s.fillLock(ticker)
+	fillMu.Lock()
+	defer fillMu.Unlock()
+
+	s.mu.RLock()
+	stored := s.series[ticker]
+	var fetchedAt time.Time
+	var last string
+	if stored != nil {
+		fetchedAt = stored.FetchedAt
+		last = stored.From
+		if n := len(stored.Data.Observations); n > 0 {
+			last = stored.Data.Observations[n-1].Date
+		}
+	}
+	covered := stored != nil && stored.From <= from
+	s.mu.RUnlock()
+
+	var changed bool
+	var err error
+	switch {
+	case !covered:
+		if changed, err = s.fetch(ctx, ticker, from); err != nil {
+			return err
+		}
+	case s.now().Sub(fetchedAt) >= s.maxAge:
+		// FRED may still revise the last observation, so it is refetched
+		if changed, err = s.fetch(ctx, ticker, last); err != nil {
+			log.Printf("Failed to top up FRED history of %s: %v", ticker, err)
+		}
+	}
+
+	if changed {
+		if err := s.Save(); err != nil {
+			log.Printf("Failed to save FRED history: %v", err)
+		}
+	}
+	return nil
+}
+
+// fillLock returns the mutex serializing the fills of a ticker.
+func (s *Store) fillLock(ticker fred.Ticker) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fillMu, exists := s.fills[ticker]
+	if !exists {
+		fillMu = &sync.Mutex{}
+		s.fills[ticker] = fillMu
+	}
+	return fillMu
+}
+
+// fetch loads the observations of a ticker from the date from on and
+// stores them in place of those held from that date. It reports whether
+// the stored observations changed.
+func (s *Store) fetch(ctx context.Context, ticker fred.Ticker, from string) (bool, error) {
+	data, err := s.client.GetSeriesObservations(ctx, ticker, &fred.QueryOptions{
+		StartDate: from,
+		Limit:     observationLimit,
+		SortOrder: "asc",
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch %s: %w", ticker, err)
+	}
+	stored := *data
+	stored.Values, stored.NormalizedUnits = nil, ""
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := s.series[ticker]
+	if previous != nil && previous.From < from {
+		kept := slices.DeleteFunc(slices.Clone(previous.Data.Observations), func(observation fred.Observation) bool {
+			return observation.Date >= from
+		})
+		stored.Observations = append(kept, stored.Observations...)
+		from = previous.From
+	}
+	s.series[ticker] = &entry{Data: stored, From: from, FetchedAt: s.now()}
+
+	changed := previous == nil || previous.From != from ||
+		!slices.Equal(previous.Data.Observations, stored.Observations)
+	return changed, nil
+}
+
+// read returns the stored observations of a ticker opts ask for.
+func (s *Store) read(ticker fred.Ticker, opts *fred.QueryOptions) *fred.SeriesData {
+	s.mu.RLock()
+	stored := s.series[ticker]
+	data := stored.Data
+	data.LastUpdated = stored.FetchedAt
+	data.Observations = make([]fred.Observation, 0, len(stored.Data.Observations))
+	for _, observation := range stored.Data.Observations {
+		if observation.Date >= opts.StartDate && (opts.EndDate == "" || observation.Date <= opts.EndDate) {
+			data.Observations = append(data.Observations, observation)
+		}
+	}
+	s.mu.RUnlock()
+
+	if opts.SortOrder == "desc" {
+		slices.Reverse(data.Observations)
+	}
+	if opts.Limit > 0 && len(data.Observations) > opts.Limit {
+		data.Observations = data.Observations[:opts.Limit]
+	}
+	data.Values, data.NormalizedUnits = data.TypedObservations()
+	return &data
+}
+
+// Seed backfills the history of tickers since a time, skipping those the
+// store holds already and topping up those older than the maximum age.
+// Tickers that fail are reported and left to be
+// fetched on request. Progress reports how far it got.
+func (s *Store) Seed(ctx context.Context, tickers []fred.Ticker, since time.Time) {
+	from := since.UTC().Format(DateLayout)
+	started := s.now()
+	s.updateProgress(func(progress *Progress) {
+		*progress = Progress{State: SeedRunning, Since: from, Total: len(tickers), StartedAt: &started}
+	})
+
+	for _, ticker := range tickers {
+		if ctx.Err() != nil {
+			break
+		}
+		s.updateProgress(func(progress *Progress) {
+			progress.Current = string(ticker)
+		})
+
+		err := s.fill(ctx, ticker, from)
+		if err != nil {
+			log.Printf("Failed to seed FRED history: %v", err)
+		}
+		s.updateProgress(func(progress *Progress) {
+			progress.Done++
+			if err != nil {
+				progress.Failed = append(progress.Failed, string(ticker))
+			}
+		})
+	}
+
+	finished := s.now()
+	s.updateProgress(func(progress *Progress) {
+		progress.State = SeedDone
+		progress.Current = ""
+		progress.FinishedAt = &finished
+	})
+	progress := s.Progress()
+	log.Printf("Seeded FRED history of %d tickers since %s (%d failed)", progress.Done-len(progress.Failed), from, len(progress.Failed))
+}
+
+// updateProgress changes the seeding progress.
+func (s *Store) updateProgress(update func(progress *Progress)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	update(&s.progress)
+}
+
+// Progress returns how far seeding got.
+func (s *Store) Progress() Progress {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	progress := s.progress
+	progress.Failed = slices.Clone(progress.Failed)
+	return progress
+}
+
+// Save persists the store to the configured file. It is a no-op when no
+// file is configured.
+func (s *Store) Save() error {
+	if s.file == "" {
+		return nil
+	}
+
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	s.mu.RLock()
+	data, err := json.Marshal(s.series)
+	s.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal FRED history: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.file), 0o755); err != nil {
+		return fmt.Errorf("failed to create FRED history directory: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves partial history
+	tmpFile := s.file + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write FRED history: %w", err)
+	}
+	if err := os.Rename(tmpFile, s.file); err != nil {
+		return fmt.Errorf("failed to replace FRED history: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads the persisted store from the configured file. A missing file
+// is not an error: the store starts empty and Seed backfills it.
+func (s *Store) Load() error {
+	if s.file == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read FRED history: %w", err)
+	}
+
+	var series map[fred.Ticker]*entry
+	if err := json.Unmarshal(data, &series); err != nil {
+		return fmt.Errorf("failed to parse FRED history: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ticker, stored := range series {
+		s.series[ticker] = stored
+	}
+
+	log.Printf("Loaded FRED history of %d tickers from %s", len(series), s.file)
+	return nil
+}
@@ -0,0 +1,230 @@
+package fredstore
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"macro-analyst/pkg/fred"
+)
+
+// countingFREDClient returns daily observations from the requested start
+// date and counts the observation requests per ticker.
+type countingFREDClient struct {
+	mu       sync.Mutex
+	requests map[fred.Ticker]int
+	failing  fred.Ticker
+	end      string
+	release  chan struct{} // When set, requests wait for it to close
+}
+
+func newCountingFREDClient(end string) *countingFREDClient {
+	return &countingFREDClient{requests: make(map[fred.Ticker]int), end: end}
+}
+
+func (c *countingFREDClient) GetSeriesObservations(ctx context.Context, ticker fred.Ticker, opts *fred.QueryOptions) (*fred.SeriesData, error) {
+	c.mu.Lock()
+	c.requests[ticker]++
+	c.mu.Unlock()
+
+	if c.release != nil {
+		<-c.release
+	}
+	if ticker == c.failing {
+		return nil, errors.New("upstream unavailable")
+	}
+
+	data := &fred.SeriesData{Ticker: ticker, Frequency: "Daily"}
+	day, _ := time.Parse(DateLayout, opts.StartDate)
+	for ; day.Format(DateLayout) <= c.end; day = day.AddDate(0, 0, 1) {
+		data.Observations = append(data.Observations, fred.Observation{Date: day.Format(DateLayout), Value: "1.5"})
+	}
+	return data, nil
+}
+
+func (c *countingFREDClient) GetLatestValue(ctx context.Context, ticker fred.Ticker) (*fred.LatestValue, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *countingFREDClient) GetMultipleLatest(ctx context.Context, tickers []fred.Ticker) (*fred.MultiTickerResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *countingFREDClient) GetSeriesInfo(ctx context.Context, ticker fred.Ticker) (*fred.FREDSeriesInfo, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *countingFREDClient) count(ticker fred.Ticker) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.requests[ticker]
+}
+
+// TestSeed verifies seeding backfills each ticker once, reports failures
+// in the progress and answers later requests from the store.
+func TestSeed(t *testing.T) {
+	// Arrange
+	client := newCountingFREDClient("2024-01-10")
+	client.failing = fred.TickerTGA
+	store := New(client)
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Act
+	store.Seed(context.Background(), []fred.Ticker{fred.TickerWALCL, fred.TickerTGA}, since)
+	data, err := store.GetSeriesObservations(context.Background(), fred.TickerWALCL, &fred.QueryOptions{
+		StartDate: "2024-01-03",
+		EndDate:   "2024-01-05",
+	})
+
+	// Assert
+	progress := store.Progress()
+	if progress.State != SeedDone || progress.Total != 2 || progress.Done != 2 || progress.Since != "2024-01-01" {
+		t.Errorf("Expected seeding of 2 tickers since 2024-01-01 to be done, got %+v", progress)
+	}
+	if len(progress.Failed) != 1 || progress.Failed[0] != string(fred.TickerTGA) {
+		t.Errorf("Expected %s to fail, got %v", fred.TickerTGA, progress.Failed)
+	}
+	if err != nil {
+		t.Fatalf("Failed to read observations: %v", err)
+	}
+	if len(data.Observations) != 3 || data.Observations[0].Date != "2024-01-03" || len(data.Values) != 3 {
+		t.Errorf("Expected the 3 stored observations from 2024-01-03, got %+v", data.Observations)
+	}
+	if n := client.count(fred.TickerWALCL); n != 1 {
+		t.Errorf("Expected %s to be fetched once, got %d", fred.TickerWALCL, n)
+	}
+}
+
+// TestGetSeriesObservations verifies requests from before the stored
+// history fetch it again, stale series are topped up and requests for
+// other units pass through.
+func TestGetSeriesObservations(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	client := newCountingFREDClient("2024-01-10")
+	store := New(client)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+	get := func(opts *fred.QueryOptions) *fred.SeriesData {
+		t.Helper()
+		data, err := store.GetSeriesObservations(ctx, fred.TickerWALCL, opts)
+		if err != nil {
+			t.Fatalf("Failed to read observations: %v", err)
+		}
+		return data
+	}
+
+	// Act & Assert
+	get(&fred.QueryOptions{StartDate: "2024-01-05"})
+	get(&fred.QueryOptions{StartDate: "2024-01-07"})
+	if n := client.count(fred.TickerWALCL); n != 1 {
+		t.Errorf("Expected a covered request to be served from the store, got %d fetches", n)
+	}
+
+	data := get(&fred.QueryOptions{StartDate: "2024-01-01", SortOrder: "desc", Limit: 2})
+	if n := client.count(fred.TickerWALCL); n != 2 {
+		t.Errorf("Expected an earlier start to fetch again, got %d fetches", n)
+	}
+	if len(data.Observations) != 2 || data.Observations[0].Date != "2024-01-10" {
+		t.Errorf("Expected the 2 latest observations, newest first, got %+v", data.Observations)
+	}
+
+	client.end = "2024-01-12"
+	now = now.Add(DefaultMaxAge)
+	data = get(&fred.QueryOptions{StartDate: "2024-01-01"})
+	if len(data.Observations) != 12 || data.Observations[11].Date != "2024-01-12" {
+		t.Errorf("Expected a stale series to be topped up to 12 observations, got %d", len(data.Observations))
+	}
+
+	get(&fred.QueryOptions{StartDate: "2024-01-01", Units: fred.UnitsPercentChangeYearAgo})
+	if n := client.count(fred.TickerWALCL); n != 4 {
+		t.Errorf("Expected transformed units to pass through, got %d fetches", n)
+	}
+}
+
+// TestSaveLoad verifies a seeded store is restored from its file, so the
+// next start only tops it up.
+func TestSaveLoad(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "fred-history.json")
+	client := newCountingFREDClient("2024-01-10")
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	New(client, WithFile(path)).Seed(context.Background(), []fred.Ticker{fred.TickerWALCL}, since)
+
+	// Act
+	restored := New(client, WithFile(path), WithMaxAge(24*time.Hour))
+	if err := restored.Load(); err != nil {
+		t.Fatalf("Failed to load FRED history: %v", err)
+	}
+	restored.Seed(context.Background(), []fred.Ticker{fred.TickerWALCL}, since)
+
+	// Assert
+	if n := client.count(fred.TickerWALCL); n != 1 {
+		t.Errorf("Expected the restored history not to be fetched again, got %d fetches", n)
+	}
+	if err := New(client, WithFile(filepath.Join(t.TempDir(), "missing.json"))).Load(); err != nil {
+		t.Errorf("Expected a missing file to be ignored, got %v", err)
+	}
+}
+
+// TestConcurrentRequestsShareFetch verifies concurrent requests for a
+// ticker the store does not hold yet fetch it once.
+func TestConcurrentRequestsShareFetch(t *testing.T) {
+	// Arrange
+	client := newCountingFREDClient("2024-01-10")
+	client.release = make(chan struct{})
+	store := New(client)
+
+	// Act
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := store.GetSeriesObservations(context.Background(), fred.TickerWALCL, &fred.QueryOptions{StartDate: "2024-01-01"}); err != nil {
+				t.Errorf("Failed to read observations: %v", err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(client.release)
+	wg.Wait()
+
+	// Assert
+	if n := client.count(fred.TickerWALCL); n != 1 {
+		t.Errorf("Expected concurrent requests to share 1 fetch, got %d", n)
+	}
+}
+
+// TestFetchPersists verifies a series fetched on request is saved right
+// away, without waiting for a seed to finish.
+func TestFetchPersists(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "fred-history.json")
+	client := newCountingFREDClient("2024-01-10")
+	store := New(client, WithFile(path))
+
+	// Act
+	if _, err := store.GetSeriesObservations(context.Background(), fred.TickerWALCL, &fred.QueryOptions{StartDate: "2024-01-01"}); err != nil {
+		t.Fatalf("Failed to read observations: %v", err)
+	}
+
+	// Assert
+	restored := New(client, WithFile(path))
+	if err := restored.Load(); err != nil {
+		t.Fatalf("Failed to load FRED history: %v", err)
+	}
+	data, err := restored.GetSeriesObservations(context.Background(), fred.TickerWALCL, &fred.QueryOptions{StartDate: "2024-01-01"})
+	if err != nil {
+		t.Fatalf("Failed to read observations: %v", err)
+	}
+	if len(data.Observations) != 10 {
+		t.Errorf("Expected 10 persisted observations, got %d", len(data.Observations))
+	}
+	if n := client.count(fred.TickerWALCL); n != 1 {
+		t.Errorf("Expected the persisted series not to be fetched again, got %d fetches", n)
+	}
+}
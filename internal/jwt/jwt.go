@@ -0,0 +1,169 @@
+// Package jwt verifies HS256-signed JSON Web Tokens (RFC 7519) issued by an
+// identity provider sharing a signing key with the server, to authenticate
+// WebSocket clients:
+//
+//	verifier := jwt.NewVerifier(key, jwt.WithIssuer("https://auth.example.com"))
+//	claims, err := verifier.Verify(token) // jwt.ErrExpired after exp
+//
+// Only the HS256 algorithm is accepted; tokens naming any other algorithm,
+// including "none", are rejected.
+package jwt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Algorithm is the only signing algorithm accepted
+const Algorithm = "HS256"
+
+// DefaultLeeway tolerates clock skew between the issuer and the server when
+// checking exp and nbf.
+const DefaultLeeway = 30 * time.Second
+
+var (
+	// ErrInvalidToken is returned for malformed or forged tokens and
+	// tokens signed with another algorithm.
+	ErrInvalidToken = errors.New("invalid token")
+
+	// ErrExpired is returned for tokens past their exp claim.
+	ErrExpired = errors.New("token expired")
+
+	// ErrNotYetValid is returned for tokens before their nbf claim.
+	ErrNotYetValid = errors.New("token not yet valid")
+
+	// ErrWrongIssuer is returned for tokens of another issuer.
+	ErrWrongIssuer = errors.New("token issuer not accepted")
+)
+
+// Claims are the registered claims of a token the server uses.
+type Claims struct {
+	Subject   string `json:"sub"`           // User identity
+	Issuer    string `json:"iss,omitempty"` // Identity provider
+	ExpiresAt int64  `json:"exp,omitempty"` // Unix seconds, required
+	NotBefore int64  `json:"nbf,omitempty"` // Unix seconds
+	IssuedAt  int64  `json:"iat,omitempty"` // Unix seconds
+}
+
+// header is the JOSE header of a token.
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+}
+
+// Verifier checks the signature and claims of tokens. It is safe for
+// concurrent use.
+type Verifier struct {
+	key    []byte
+	issuer string
+	leeway time.Duration
+	now    func() time.Time
+}
+
+// Option configures a Verifier.
+type Option func(*Verifier)
+
+// WithIssuer requires tokens to carry the given iss claim. Any issuer is
+// accepted when empty.
+func WithIssuer(issuer string) Option {
+	return func(v *Verifier) {
+		v.issuer = issuer
+	}
+}
+
+// WithLeeway sets the clock skew tolerated when checking exp and nbf.
+func WithLeeway(leeway time.Duration) Option {
+	return func(v *Verifier) {
+		v.leeway = leeway
+	}
+}
+
+// NewVerifier creates a Verifier for tokens signed with key.
+func NewVerifier(key []byte, opts ...Option) *Verifier {
+	verifier := &Verifier{key: key, leeway: DefaultLeeway, now: time.Now}
+	for _, opt := range opts {
+		opt(verifier)
+	}
+	return verifier
+}
+
+// Sign returns an HS256 token with the claims, e.g. for tests and tooling
+// that need tokens without an identity provider.
+func (v *Verifier) Sign(claims Claims) (string, error) {
+	encodedHeader, err := encodeSegment(header{Algorithm: Algorithm, Type: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	encodedClaims, err := encodeSegment(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := encodedHeader + "." + encodedClaims
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(v.sign(signingInput)), nil
+}
+
+// Verify checks the signature, algorithm, expiry, not-before time, issuer
+// and subject of a token and returns its claims.
+func (v *Verifier) Verify(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, ErrInvalidToken
+	}
+
+	var head header
+	if err := decodeSegment(parts[0], &head); err != nil || head.Algorithm != Algorithm {
+		return Claims{}, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, v.sign(parts[0]+"."+parts[1])) {
+		return Claims{}, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil || claims.Subject == "" || claims.ExpiresAt == 0 {
+		return Claims{}, ErrInvalidToken
+	}
+
+	now := v.now()
+	switch {
+	case now.After(time.Unix(claims.ExpiresAt, 0).Add(v.leeway)):
+		return Claims{}, ErrExpired
+	case claims.NotBefore != 0 && now.Before(time.Unix(claims.NotBefore, 0).Add(-v.leeway)):
+		return Claims{}, ErrNotYetValid
+	case v.issuer != "" && claims.Issuer != v.issuer:
+		return Claims{}, ErrWrongIssuer
+	}
+	return claims, nil
+}
+
+// sign returns the HMAC-SHA256 of the signing input.
+func (v *Verifier) sign(signingInput string) []byte {
+	mac := hmac.New(sha256.New, v.key)
+	mac.Write([]byte(signingInput))
+	return mac.Sum(nil)
+}
+
+// encodeSegment returns the base64url-encoded JSON of value.
+func encodeSegment(value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeSegment decodes a base64url-encoded JSON segment into value.
+func decodeSegment(segment string, value any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
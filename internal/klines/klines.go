@@ -0,0 +1,228 @@
+// Package klines fetches historical candles from the Binance REST API and
+// caches them briefly, so charts can be backfilled before the live candle
+// stream takes over without each page load reaching Binance:
+//
+//	cache := klines.New(klines.Binance(apiKey))
+//	candles, err := cache.Get(ctx, "BTCUSDT", "1m", 500)
+package klines
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"macro-analyst/pkg/clock"
+	"macro-analyst/pkg/ws"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/common"
+)
+
+const (
+	// DefaultLimit is how many candles are returned when no limit is given
+	DefaultLimit = 500
+
+	// MaxLimit is the most candles Binance returns in one request
+	MaxLimit = 1000
+
+	// DefaultTTL is how long fetched candles are served from the cache. The
+	// last candle is still forming, so it stays short.
+	DefaultTTL = 15 * time.Second
+
+	// DefaultMaxEntries caps the symbol, interval and limit combinations
+	// cached at once
+	DefaultMaxEntries = 256
+)
+
+// binanceInvalidSymbol is the error code of Binance for unknown symbols
+const binanceInvalidSymbol = -1121
+
+var (
+	// ErrInvalidInterval is returned for intervals outside
+	// ws.CandleIntervals.
+	ErrInvalidInterval = errors.New("invalid candle interval")
+
+	// ErrInvalidLimit is returned for limits outside 1 to MaxLimit.
+	ErrInvalidLimit = fmt.Errorf("limit must be 1 to %d", MaxLimit)
+)
+
+// Fetcher returns the last limit candles of a symbol and interval, oldest
+// first. It returns ws.ErrUnknownSymbol for symbols the exchange does not
+// list.
+type Fetcher func(ctx context.Context, symbol, interval string, limit int) ([]ws.CandleUpdate, error)
+
+// Cache serves the candles of a Fetcher, reusing each result for its TTL.
+// It is safe for concurrent use.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]entry
+
+	fetch      Fetcher
+	ttl        time.Duration
+	maxEntries int
+	clock      clock.Clock
+}
+
+// entry is a cached fetch result.
+type entry struct {
+	candles   []ws.CandleUpdate
+	fetchedAt time.Time
+}
+
+// Option configures a Cache.
+type Option func(*Cache)
+
+// WithTTL sets how long fetched candles are reused, DefaultTTL by default.
+// Candles are fetched on every request when it is zero.
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.ttl = ttl
+	}
+}
+
+// WithClock sets the clock cache entries expire by.
+func WithClock(clk clock.Clock) Option {
+	return func(c *Cache) {
+		c.clock = clk
+	}
+}
+
+// New creates a Cache over a Fetcher.
+func New(fetch Fetcher, opts ...Option) *Cache {
+	c := &Cache{
+		entries:    make(map[string]entry),
+		fetch:      fetch,
+		ttl:        DefaultTTL,
+		maxEntries: DefaultMaxEntries,
+		clock:      clock.Real,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Get returns the last limit candles of a symbol and interval, oldest
+// first, from the cache or the Fetcher. It returns ErrInvalidInterval or
+// ErrInvalidLimit before fetching anything.
+func (c *Cache) Get(ctx context.Context, symbol, interval string, limit int) ([]ws.CandleUpdate, error) {
+	if !slices.Contains(ws.CandleIntervals, interval) {
+		return nil, fmt.Errorf("%w %q (expected one of %v)", ErrInvalidInterval, interval, ws.CandleIntervals)
+	}
+	if limit < 1 || limit > MaxLimit {
+		return nil, fmt.Errorf("%w, got %d", ErrInvalidLimit, limit)
+	}
+
+	key := symbol + "/" + interval + "/" + strconv.Itoa(limit)
+	c.mu.Lock()
+	cached, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && c.clock.Now().Sub(cached.fetchedAt) < c.ttl {
+		return cached.candles, nil
+	}
+
+	candles, err := c.fetch(ctx, symbol, interval, limit)
+	if err != nil {
+		return nil, err
+	}
+	if c.ttl > 0 {
+		c.store(key, entry{candles: candles, fetchedAt: c.clock.Now()})
+	}
+	return candles, nil
+}
+
+// store caches a result, dropping expired entries and then the oldest one
+// when the cache is full.
+func (c *Cache) store(key string, e entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		oldest := ""
+		for k, cached := range c.entries {
+			if e.fetchedAt.Sub(cached.fetchedAt) >= c.ttl {
+				delete(c.entries, k)
+			} else if oldest == "" || cached.fetchedAt.Before(c.entries[oldest].fetchedAt) {
+				oldest = k
+			}
+		}
+		if len(c.entries) >= c.maxEntries {
+			delete(c.entries, oldest)
+		}
+	}
+	c.entries[key] = e
+}
+
+// Binance returns a Fetcher reading klines from the Binance REST API. The
+// API key, if set, is sent so requests count against the account's limits
+// rather than the shared anonymous ones.
+func Binance(apiKey string) Fetcher {
+	client := binance.NewClient(apiKey, "")
+	client.HTTPClient = &http.Client{Timeout: ws.BinanceRESTTimeout}
+
+	return func(ctx context.Context, symbol, interval string, limit int) ([]ws.CandleUpdate, error) {
+		var opts []binance.RequestOption
+		if apiKey != "" {
+			opts = append(opts, binance.WithHeader("X-MBX-APIKEY", apiKey, false))
+		}
+		klines, err := client.NewKlinesService().Symbol(symbol).Interval(interval).Limit(limit).Do(ctx, opts...)
+		if err != nil {
+			var apiErr *common.APIError
+			if errors.As(err, &apiErr) && apiErr.Code == binanceInvalidSymbol {
+				return nil, fmt.Errorf("%w: %s", ws.ErrUnknownSymbol, symbol)
+			}
+			return nil, fmt.Errorf("failed to fetch klines of %s: %w", symbol, err)
+		}
+		return convertKlines(symbol, interval, klines, time.Now()), nil
+	}
+}
+
+// convertKlines converts Binance klines to candles. Klines whose prices or
+// volume fail to parse are left out. The last kline is still forming unless
+// its interval ended by now.
+func convertKlines(symbol, interval string, klines []*binance.Kline, now time.Time) []ws.CandleUpdate {
+	candles := make([]ws.CandleUpdate, 0, len(klines))
+	for _, kline := range klines {
+		candle := ws.CandleUpdate{
+			Type:      "candle",
+			Symbol:    symbol,
+			Interval:  interval,
+			OpenTime:  kline.OpenTime,
+			CloseTime: kline.CloseTime,
+			Trades:    kline.TradeNum,
+			Closed:    true,
+		}
+
+		valid := true
+		for _, field := range []struct {
+			value string
+			dest  *float64
+		}{
+			{kline.Open, &candle.Open},
+			{kline.High, &candle.High},
+			{kline.Low, &candle.Low},
+			{kline.Close, &candle.Close},
+			{kline.Volume, &candle.Volume},
+		} {
+			value, err := strconv.ParseFloat(field.value, 64)
+			if err != nil {
+				valid = false
+				break
+			}
+			*field.dest = value
+		}
+		if valid {
+			candles = append(candles, candle)
+		}
+	}
+
+	if n := len(candles); n > 0 && candles[n-1].CloseTime >= now.UnixMilli() {
+		candles[n-1].Closed = false
+	}
+	return candles
+}
@@ -0,0 +1,107 @@
+package klines
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"macro-analyst/pkg/clock"
+	"macro-analyst/pkg/ws"
+
+	"github.com/adshao/go-binance/v2"
+)
+
+// TestCacheGet verifies results are reused until they expire and that
+// invalid requests fail before fetching.
+func TestCacheGet(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+	fetches := 0
+	cache := New(func(ctx context.Context, symbol, interval string, limit int) ([]ws.CandleUpdate, error) {
+		fetches++
+		return []ws.CandleUpdate{{Symbol: symbol, Interval: interval}}, nil
+	}, WithClock(clk))
+
+	for range 2 {
+		if _, err := cache.Get(context.Background(), "BTCUSDT", "1m", 100); err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("Expected the second request to be cached, got %d fetches", fetches)
+	}
+
+	cache.Get(context.Background(), "BTCUSDT", "1h", 100)
+	clk.Advance(DefaultTTL)
+	cache.Get(context.Background(), "BTCUSDT", "1m", 100)
+	if fetches != 3 {
+		t.Errorf("Expected other intervals and expired results to be fetched, got %d fetches", fetches)
+	}
+
+	tests := []struct {
+		name     string
+		interval string
+		limit    int
+		err      error
+	}{
+		{"unknown interval", "7m", 100, ErrInvalidInterval},
+		{"zero limit", "1m", 0, ErrInvalidLimit},
+		{"limit too high", "1m", MaxLimit + 1, ErrInvalidLimit},
+	}
+	for _, tt := range tests {
+		if _, err := cache.Get(context.Background(), "BTCUSDT", tt.interval, tt.limit); !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.err, err)
+		}
+	}
+	if fetches != 3 {
+		t.Errorf("Expected invalid requests not to be fetched, got %d fetches", fetches)
+	}
+}
+
+// TestCacheEviction verifies a full cache drops its oldest entry.
+func TestCacheEviction(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+	cache := New(func(ctx context.Context, symbol, interval string, limit int) ([]ws.CandleUpdate, error) {
+		return nil, nil
+	}, WithClock(clk))
+	cache.maxEntries = 2
+
+	for _, symbol := range []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"} {
+		cache.Get(context.Background(), symbol, "1m", 10)
+		clk.Advance(time.Second)
+	}
+
+	if len(cache.entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(cache.entries))
+	}
+	if _, ok := cache.entries["BTCUSDT/1m/10"]; ok {
+		t.Error("Expected the oldest entry to be evicted")
+	}
+}
+
+// TestConvertKlines tests converting Binance klines to candles.
+func TestConvertKlines(t *testing.T) {
+	now := time.UnixMilli(1_700_000_120_000)
+	klines := []*binance.Kline{
+		{OpenTime: 1_700_000_000_000, CloseTime: 1_700_000_059_999, Open: "100", High: "110", Low: "95", Close: "105", Volume: "12.5", TradeNum: 40},
+		{OpenTime: 1_700_000_060_000, CloseTime: 1_700_000_119_999, Open: "105", High: "x", Low: "100", Close: "101", Volume: "3"},
+		{OpenTime: 1_700_000_120_000, CloseTime: 1_700_000_179_999, Open: "101", High: "102", Low: "100", Close: "102", Volume: "1"},
+	}
+
+	candles := convertKlines("BTCUSDT", "1m", klines, now)
+	if len(candles) != 2 {
+		t.Fatalf("Expected the invalid kline to be skipped, got %d candles", len(candles))
+	}
+
+	expected := ws.CandleUpdate{
+		Type: "candle", Symbol: "BTCUSDT", Interval: "1m",
+		OpenTime: 1_700_000_000_000, CloseTime: 1_700_000_059_999,
+		Open: 100, High: 110, Low: 95, Close: 105, Volume: 12.5, Trades: 40, Closed: true,
+	}
+	if candles[0] != expected {
+		t.Errorf("Expected %+v, got %+v", expected, candles[0])
+	}
+	if candles[1].Closed {
+		t.Error("Expected the forming candle not to be closed")
+	}
+}
@@ -0,0 +1,48 @@
+package macro
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"macro-analyst/internal/analytics"
+	"macro-analyst/pkg/fred"
+)
+
+// AlignOptions selects the grid GetAlignedSeries aligns series on.
+type AlignOptions struct {
+	Grid  string    // analytics.GridDaily, GridWeekly or GridMonthly
+	Start time.Time // First grid date
+	End   time.Time // Last grid date
+}
+
+// GetAlignedSeries loads several FRED series and forward-fills them onto
+// one date grid, so they can be charted together without joining them.
+// Series are loaded from before the start so the first dates can carry
+// them forward. It also returns the series as loaded.
+//
+// It takes the client instead of being a fred.Client method so every
+// client, stored history included, gets it without implementing it.
+func GetAlignedSeries(ctx context.Context, client fred.Client, tickers []fred.Ticker, opts AlignOptions) (analytics.Alignment, []analytics.Series, error) {
+	dates, err := analytics.Grid(opts.Grid, opts.Start, opts.End)
+	if err != nil {
+		return analytics.Alignment{}, nil, err
+	}
+
+	lookback := opts.Start.Add(-analytics.MaxFillAge("quarterly"))
+	series := make([]analytics.Series, len(tickers))
+	for idx, ticker := range tickers {
+		data, err := client.GetSeriesObservations(ctx, ticker, &fred.QueryOptions{
+			StartDate: lookback.Format(analytics.DateLayout),
+			EndDate:   opts.End.Format(analytics.DateLayout),
+			Limit:     observationLimit,
+			SortOrder: "asc",
+		})
+		if err != nil {
+			return analytics.Alignment{}, nil, fmt.Errorf("failed to fetch %s: %w", ticker, err)
+		}
+		series[idx] = analytics.FromFRED(data)
+	}
+
+	return analytics.Align(opts.Grid, dates, series), series, nil
+}
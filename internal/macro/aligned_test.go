@@ -0,0 +1,64 @@
+package macro
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"macro-analyst/internal/analytics"
+	"macro-analyst/pkg/fred"
+)
+
+// TestGetAlignedSeries tests that the inputs are forward-filled onto a
+// weekly grid, with the lookback carrying values into the first date.
+func TestGetAlignedSeries(t *testing.T) {
+	// Arrange
+	client := newInputsClient()
+	opts := AlignOptions{
+		Grid:  analytics.GridWeekly,
+		Start: time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 1, 19, 0, 0, 0, 0, time.UTC),
+	}
+
+	// Act
+	aligned, series, err := GetAlignedSeries(context.Background(), client, NetLiquidityTickers, opts)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(series) != len(NetLiquidityTickers) || len(aligned.Series) != len(NetLiquidityTickers) {
+		t.Fatalf("Expected %d series, got %d loaded and %d aligned", len(NetLiquidityTickers), len(series), len(aligned.Series))
+	}
+	if len(aligned.Dates) != 3 || aligned.Dates[0] != "2024-01-05" {
+		t.Fatalf("Expected 3 weekly dates from 2024-01-05, got %v", aligned.Dates)
+	}
+
+	tga := aligned.Series[1]
+	if tga.ID != "fred:WTREGEN" {
+		t.Fatalf("Expected the series in request order, got %s second", tga.ID)
+	}
+	for idx, expected := range []float64{750000, 700000, 700000} {
+		if tga.Values[idx] == nil || *tga.Values[idx] != expected {
+			t.Errorf("%s: expected TGA %v, got %v", aligned.Dates[idx], expected, tga.Values[idx])
+		}
+	}
+}
+
+// TestGetAlignedSeriesErrors tests that an invalid grid or a failing
+// series fails the alignment.
+func TestGetAlignedSeriesErrors(t *testing.T) {
+	client := newInputsClient()
+	start := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+
+	_, _, err := GetAlignedSeries(context.Background(), client, NetLiquidityTickers, AlignOptions{Grid: "hourly", Start: start, End: start})
+	if !errors.Is(err, analytics.ErrInvalidGrid) {
+		t.Errorf("Expected ErrInvalidGrid, got %v", err)
+	}
+
+	_, _, err = GetAlignedSeries(context.Background(), client, []fred.Ticker{fred.TickerFEDFUNDS}, AlignOptions{Grid: analytics.GridWeekly, Start: start, End: start})
+	if err == nil {
+		t.Error("Expected an error for a failing series")
+	}
+}
@@ -0,0 +1,75 @@
+package macro
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"macro-analyst/pkg/fred"
+)
+
+const (
+	// DefaultCheckInterval is how often FRED is polled for new inputs
+	DefaultCheckInterval = time.Hour
+
+	// checkLookback is how much history a check loads; enough to carry the
+	// weekly inputs forward to the latest daily observation
+	checkLookback = 30 * 24 * time.Hour
+)
+
+// Update is pushed to WebSocket clients when net liquidity changes.
+type Update struct {
+	Type string `json:"type"` // Always "net_liquidity"
+	Point
+	Units     string `json:"units"`
+	Timestamp int64  `json:"timestamp"` // Unix ms when the change was detected
+}
+
+// Monitor tracks the latest net liquidity. It is safe for concurrent use.
+type Monitor struct {
+	mu     sync.Mutex
+	latest *Point
+
+	client fred.Client
+	now    func() time.Time
+}
+
+// NewMonitor creates a Monitor fetching its inputs with client.
+func NewMonitor(client fred.Client) *Monitor {
+	return &Monitor{client: client, now: time.Now}
+}
+
+// Check recomputes net liquidity from recent FRED observations and reports
+// whether the latest value changed since the last check, i.e. an input was
+// published for a new date or revised.
+func (m *Monitor) Check(ctx context.Context) (Point, bool, error) {
+	now := m.now()
+	points, _, err := FetchNetLiquidity(ctx, m.client, now.Add(-checkLookback), now)
+	if err != nil {
+		return Point{}, false, err
+	}
+	if len(points) == 0 {
+		return Point{}, false, nil
+	}
+	latest := points[len(points)-1]
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.latest != nil && *m.latest == latest {
+		return latest, false, nil
+	}
+	m.latest = &latest
+	return latest, true, nil
+}
+
+// NewUpdate encodes the WebSocket message for a net liquidity change.
+func NewUpdate(point Point, detectedAt time.Time) ([]byte, error) {
+	return json.Marshal(&Update{
+		Type:      "net_liquidity",
+		Point:     point,
+		Units:     NetLiquidityUnits,
+		Timestamp: detectedAt.UnixMilli(),
+	})
+}
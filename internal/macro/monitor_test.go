@@ -0,0 +1,64 @@
+package macro
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"macro-analyst/pkg/fred"
+)
+
+// TestMonitorCheck tests that only new or revised values are reported.
+func TestMonitorCheck(t *testing.T) {
+	client := newInputsClient()
+	monitor := NewMonitor(client)
+	monitor.now = func() time.Time { return time.Date(2024, 1, 11, 12, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		update   func()
+		expected Point
+		changed  bool
+	}{
+		{"first", func() {}, Point{"2024-01-10", 6380, 7680, 700, 600}, true},
+		{"unchanged", func() {}, Point{"2024-01-10", 6380, 7680, 700, 600}, false},
+		{"new reverse repo", func() {
+			client.observations[fred.TickerRRPONTSYD] = append(client.observations[fred.TickerRRPONTSYD],
+				fred.Observation{Date: "2024-01-11", Value: "580"})
+		}, Point{"2024-01-11", 6400, 7680, 700, 580}, true},
+		{"revision", func() {
+			client.observations[fred.TickerTGA][1].Value = "720000"
+		}, Point{"2024-01-11", 6380, 7680, 720, 580}, true},
+	}
+
+	for _, tt := range tests {
+		tt.update()
+		point, changed, err := monitor.Check(context.Background())
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if point != tt.expected || changed != tt.changed {
+			t.Errorf("%s: expected %+v (changed %v), got %+v (changed %v)",
+				tt.name, tt.expected, tt.changed, point, changed)
+		}
+	}
+}
+
+// TestNewUpdate verifies the WebSocket message format.
+func TestNewUpdate(t *testing.T) {
+	detectedAt := time.UnixMilli(1704974400000)
+	data, err := NewUpdate(Point{"2024-01-10", 6380, 7680, 700, 600}, detectedAt)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var update map[string]any
+	if err := json.Unmarshal(data, &update); err != nil {
+		t.Fatalf("Failed to decode update: %v", err)
+	}
+	if update["type"] != "net_liquidity" || update["date"] != "2024-01-10" || update["value"] != 6380.0 ||
+		update["units"] != NetLiquidityUnits || update["timestamp"] != 1704974400000.0 {
+		t.Errorf("Unexpected update: %s", data)
+	}
+}
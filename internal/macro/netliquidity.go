@@ -0,0 +1,114 @@
+// Package macro derives composite macro metrics from FRED series.
+package macro
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"macro-analyst/internal/analytics"
+	"macro-analyst/pkg/fred"
+)
+
+// NetLiquidityUnits are the units of net liquidity and its inputs.
+const NetLiquidityUnits = "Billions of U.S. Dollars"
+
+// observationLimit is the largest page the FRED API accepts
+const observationLimit = 100000
+
+// NetLiquidityTickers are the inputs of net liquidity: the Fed's total
+// assets, the Treasury General Account and overnight reverse repos.
+var NetLiquidityTickers = []fred.Ticker{fred.TickerWALCL, fred.TickerTGA, fred.TickerRRPONTSYD}
+
+// perBillion is how many reported units of each input make a billion: the
+// H.4.1 series (WALCL and WTREGEN) are reported in millions, RRPONTSYD in
+// billions.
+var perBillion = map[fred.Ticker]float64{
+	fred.TickerWALCL:     1000,
+	fred.TickerTGA:       1000,
+	fred.TickerRRPONTSYD: 1,
+}
+
+// Point is Fed net liquidity on one date, with its inputs.
+type Point struct {
+	Date        string  `json:"date"`
+	Value       float64 `json:"value"` // TotalAssets - TGA - ReverseRepo
+	TotalAssets float64 `json:"total_assets"`
+	TGA         float64 `json:"tga"`
+	ReverseRepo float64 `json:"reverse_repo"`
+}
+
+// NetLiquidity computes net liquidity on every date any input was observed,
+// carrying the other inputs forward from their latest observation like
+// analytics.Align. Dates where an input has no recent enough observation
+// are left out. The series must be given in NetLiquidityTickers order.
+func NetLiquidity(assets, tga, rrp analytics.Series) []Point {
+	inputs := []analytics.Series{assets, tga, rrp}
+
+	var dates []time.Time
+	for _, series := range inputs {
+		for _, point := range series.Points {
+			t := point.Time.UTC()
+			dates = append(dates, time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC))
+		}
+	}
+	slices.SortFunc(dates, func(a, b time.Time) int { return a.Compare(b) })
+	dates = slices.Compact(dates)
+
+	aligned := analytics.Align("", dates, inputs)
+	points := make([]Point, 0, len(dates))
+	for day, date := range aligned.Dates {
+		values := make([]float64, len(inputs))
+		complete := true
+		for idx, ticker := range NetLiquidityTickers {
+			value := aligned.Series[idx].Values[day]
+			if value == nil {
+				complete = false
+				break
+			}
+			values[idx] = *value / perBillion[ticker]
+		}
+		if !complete {
+			continue
+		}
+
+		points = append(points, Point{
+			Date:        date,
+			Value:       values[0] - values[1] - values[2],
+			TotalAssets: values[0],
+			TGA:         values[1],
+			ReverseRepo: values[2],
+		})
+	}
+	return points
+}
+
+// FetchNetLiquidity loads the inputs from FRED and computes net liquidity
+// between start and end (inclusive). Inputs are loaded from before start so
+// the first dates can carry them forward. It also returns the inputs.
+func FetchNetLiquidity(ctx context.Context, client fred.Client, start, end time.Time) ([]Point, []analytics.Series, error) {
+	lookback := start.Add(-analytics.MaxFillAge("weekly"))
+
+	series := make([]analytics.Series, len(NetLiquidityTickers))
+	for idx, ticker := range NetLiquidityTickers {
+		data, err := client.GetSeriesObservations(ctx, ticker, &fred.QueryOptions{
+			StartDate: lookback.Format(analytics.DateLayout),
+			EndDate:   end.Format(analytics.DateLayout),
+			Limit:     observationLimit,
+			SortOrder: "asc",
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to fetch %s: %w", ticker, err)
+		}
+		series[idx] = analytics.FromFRED(data)
+	}
+
+	// Dates in analytics.DateLayout sort lexically
+	points := NetLiquidity(series[0], series[1], series[2])
+	from, _ := slices.BinarySearchFunc(points, start.Format(analytics.DateLayout), func(p Point, date string) int {
+		return strings.Compare(p.Date, date)
+	})
+	return points[from:], series, nil
+}
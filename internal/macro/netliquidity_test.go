@@ -0,0 +1,131 @@
+package macro
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"macro-analyst/internal/analytics"
+	"macro-analyst/pkg/fred"
+)
+
+// inputsFREDClient returns fixed observations of the net liquidity inputs
+// within the requested date range.
+type inputsFREDClient struct {
+	observations map[fred.Ticker][]fred.Observation
+}
+
+// frequencies are the native frequencies of the inputs as FRED reports them.
+var frequencies = map[fred.Ticker]string{
+	fred.TickerWALCL:     "Weekly, As of Wednesday",
+	fred.TickerTGA:       "Weekly, As of Wednesday",
+	fred.TickerRRPONTSYD: "Daily",
+}
+
+func (c *inputsFREDClient) GetSeriesObservations(ctx context.Context, ticker fred.Ticker, opts *fred.QueryOptions) (*fred.SeriesData, error) {
+	observations, exists := c.observations[ticker]
+	if !exists {
+		return nil, errors.New("unknown series")
+	}
+
+	data := &fred.SeriesData{Ticker: ticker, Frequency: frequencies[ticker]}
+	for _, observation := range observations {
+		if observation.Date >= opts.StartDate && observation.Date <= opts.EndDate {
+			data.Observations = append(data.Observations, observation)
+		}
+	}
+	return data, nil
+}
+
+func (c *inputsFREDClient) GetLatestValue(ctx context.Context, ticker fred.Ticker) (*fred.LatestValue, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *inputsFREDClient) GetMultipleLatest(ctx context.Context, tickers []fred.Ticker) (*fred.MultiTickerResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *inputsFREDClient) GetSeriesInfo(ctx context.Context, ticker fred.Ticker) (*fred.FREDSeriesInfo, error) {
+	return nil, errors.New("not implemented")
+}
+
+// newInputsClient returns two weeks of inputs, in millions for the H.4.1
+// series and billions for reverse repos, with a missing RRP observation.
+func newInputsClient() *inputsFREDClient {
+	return &inputsFREDClient{observations: map[fred.Ticker][]fred.Observation{
+		fred.TickerWALCL: {
+			{Date: "2024-01-03", Value: "7700000"},
+			{Date: "2024-01-10", Value: "7680000"},
+			{Date: "2024-01-17", Value: "7670000"},
+		},
+		fred.TickerTGA: {
+			{Date: "2024-01-03", Value: "750000"},
+			{Date: "2024-01-10", Value: "700000"},
+		},
+		fred.TickerRRPONTSYD: {
+			{Date: "2024-01-02", Value: "700"},
+			{Date: "2024-01-03", Value: "690"},
+			{Date: "2024-01-04", Value: "680"},
+			{Date: "2024-01-05", Value: "."},
+			{Date: "2024-01-08", Value: "650"},
+			{Date: "2024-01-10", Value: "600"},
+		},
+	}}
+}
+
+// TestFetchNetLiquidity tests that net liquidity is computed in billions on
+// every input date with complete inputs, from start on.
+func TestFetchNetLiquidity(t *testing.T) {
+	tests := []struct {
+		name     string
+		start    string
+		expected []Point
+	}{
+		{"all dates", "2024-01-01", []Point{
+			// 2024-01-02 has no total assets yet, and on 2024-01-17 the
+			// last reverse repo is older than the daily fill age
+			{"2024-01-03", 6260, 7700, 750, 690},
+			{"2024-01-04", 6270, 7700, 750, 680},
+			{"2024-01-08", 6300, 7700, 750, 650},
+			{"2024-01-10", 6380, 7680, 700, 600},
+		}},
+		{"from start", "2024-01-08", []Point{
+			{"2024-01-08", 6300, 7700, 750, 650},
+			{"2024-01-10", 6380, 7680, 700, 600},
+		}},
+	}
+
+	client := newInputsClient()
+	end := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		start, _ := time.Parse(analytics.DateLayout, tt.start)
+		points, series, err := FetchNetLiquidity(context.Background(), client, start, end)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if len(series) != len(NetLiquidityTickers) {
+			t.Errorf("%s: expected the inputs, got %d series", tt.name, len(series))
+		}
+		if len(points) != len(tt.expected) {
+			t.Fatalf("%s: expected %v, got %v", tt.name, tt.expected, points)
+		}
+		for i := range points {
+			if points[i] != tt.expected[i] {
+				t.Errorf("%s: expected %+v, got %+v", tt.name, tt.expected[i], points[i])
+			}
+		}
+	}
+}
+
+// TestFetchNetLiquidityError tests that a failing input fails the
+// computation.
+func TestFetchNetLiquidityError(t *testing.T) {
+	client := newInputsClient()
+	delete(client.observations, fred.TickerTGA)
+
+	_, _, err := FetchNetLiquidity(context.Background(), client, time.Now().AddDate(0, -1, 0), time.Now())
+	if err == nil {
+		t.Error("Expected an error for a missing input")
+	}
+}
@@ -0,0 +1,121 @@
+// Package scheduler runs recurring background jobs such as daily rollovers
+// and periodic refreshes.
+//
+// Jobs run in their own goroutines and receive a context that is cancelled
+// when the scheduler stops:
+//
+//	sched := scheduler.New()
+//	sched.DailyAt("day-open-rollover", 0, 0, func(ctx context.Context) {
+//	    ingestor.RefreshDayOpens(ctx)
+//	})
+//	defer sched.Stop()
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"macro-analyst/pkg/clock"
+)
+
+// Job is a unit of scheduled work.
+type Job func(ctx context.Context)
+
+// Scheduler runs registered jobs until stopped.
+type Scheduler struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	clock  clock.Clock
+}
+
+// Option configures a Scheduler.
+type Option func(*Scheduler)
+
+// WithClock sets the clock that decides when jobs run. Tests inject a
+// clock.Fake to run jobs without waiting.
+func WithClock(clk clock.Clock) Option {
+	return func(s *Scheduler) {
+		s.clock = clk
+	}
+}
+
+// New creates a Scheduler.
+func New(opts ...Option) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	scheduler := &Scheduler{
+		ctx:    ctx,
+		cancel: cancel,
+		clock:  clock.Real,
+	}
+	for _, opt := range opts {
+		opt(scheduler)
+	}
+	return scheduler
+}
+
+// Every runs job at a fixed interval, starting one interval from now.
+func (s *Scheduler) Every(name string, interval time.Duration, job Job) {
+	s.run(name, job, func(now time.Time) time.Time {
+		return now.Add(interval)
+	})
+}
+
+// DailyAt runs job every day at the given UTC hour and minute.
+func (s *Scheduler) DailyAt(name string, hour, minute int, job Job) {
+	s.run(name, job, func(now time.Time) time.Time {
+		return NextDailyRun(now, hour, minute)
+	})
+}
+
+// When wraps job so that it only runs while condition holds at the
+// scheduled time, e.g. heavy jobs restricted to quiet market hours. Skipped
+// runs are not made up.
+func (s *Scheduler) When(condition func(now time.Time) bool, job Job) Job {
+	return func(ctx context.Context) {
+		if condition(s.clock.Now()) {
+			job(ctx)
+		}
+	}
+}
+
+// Stop cancels all jobs and waits for running ones to return.
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// run executes job whenever the next run time computed by next is reached.
+func (s *Scheduler) run(name string, job Job, next func(now time.Time) time.Time) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		for {
+			now := s.clock.Now()
+			timer := s.clock.NewTimer(next(now).Sub(now))
+
+			select {
+			case <-s.ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C():
+				log.Printf("Running scheduled job %q", name)
+				job(s.ctx)
+			}
+		}
+	}()
+}
+
+// NextDailyRun returns the next time strictly after now at which the clock
+// reads hour:minute in UTC.
+func NextDailyRun(now time.Time, hour, minute int) time.Time {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
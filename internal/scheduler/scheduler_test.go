@@ -0,0 +1,124 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"macro-analyst/pkg/clock"
+)
+
+// TestNextDailyRun verifies the next UTC run time calculation.
+func TestNextDailyRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		now      time.Time
+		hour     int
+		minute   int
+		expected time.Time
+	}{
+		{
+			name:     "later today",
+			now:      time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+			hour:     12,
+			minute:   30,
+			expected: time.Date(2024, 1, 15, 12, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "already passed today",
+			now:      time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+			hour:     0,
+			minute:   0,
+			expected: time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "exactly at run time",
+			now:      time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			hour:     0,
+			minute:   0,
+			expected: time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "non-UTC input",
+			now:      time.Date(2024, 1, 15, 23, 0, 0, 0, time.FixedZone("UTC-5", -5*3600)),
+			hour:     0,
+			minute:   0,
+			expected: time.Date(2024, 1, 17, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		if got := NextDailyRun(tt.now, tt.hour, tt.minute); !got.Equal(tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+// TestEvery verifies interval jobs run once per interval until stopped.
+func TestEvery(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+	sched := New(WithClock(clk))
+
+	runs := make(chan time.Time, 10)
+	sched.Every("test", time.Minute, func(ctx context.Context) {
+		runs <- clk.Now()
+	})
+
+	for n := 1; n <= 2; n++ {
+		clk.BlockUntil(1)
+		clk.Advance(time.Minute)
+		if ran := <-runs; !ran.Equal(time.Date(2024, 1, 15, 0, n, 0, 0, time.UTC)) {
+			t.Errorf("Run %d: expected it at 00:0%d, got %v", n, n, ran)
+		}
+	}
+
+	sched.Stop()
+	if len(runs) != 0 {
+		t.Errorf("Expected no extra runs, got %d", len(runs))
+	}
+}
+
+// TestStopCancelsJobContext verifies running jobs observe cancellation.
+func TestStopCancelsJobContext(t *testing.T) {
+	clk := clock.NewFake(time.Unix(0, 0))
+	sched := New(WithClock(clk))
+
+	started := make(chan struct{})
+	sched.Every("blocking", time.Millisecond, func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+	})
+
+	clk.BlockUntil(1)
+	clk.Advance(time.Millisecond)
+	<-started
+
+	done := make(chan struct{})
+	go func() {
+		sched.Stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return after cancelling jobs")
+	}
+}
+
+// TestWhen verifies conditional jobs only run while the condition holds at
+// the scheduler's time.
+func TestWhen(t *testing.T) {
+	now := time.Date(2024, 1, 15, 3, 0, 0, 0, time.UTC)
+	sched := New(WithClock(clock.NewFake(now)))
+
+	runs := 0
+	job := func(ctx context.Context) { runs++ }
+
+	sched.When(func(at time.Time) bool { return false }, job)(context.Background())
+	sched.When(func(at time.Time) bool { return at.Equal(now) }, job)(context.Background())
+
+	if runs != 1 {
+		t.Errorf("Expected 1 run, got %d", runs)
+	}
+}
@@ -0,0 +1,283 @@
+// Package secrets resolves credentials such as API keys from the
+// environment, from mounted secret files (Docker/Kubernetes secrets), from
+// HashiCorp Vault, or from a SOPS-encrypted file, and redacts every resolved
+// value from log output.
+//
+// For a key such as FRED_API_KEY, sources are tried in this order:
+//
+//  1. FRED_API_KEY_FILE: path of a file holding the value
+//  2. FRED_API_KEY set to "vault:<path>#<field>": a field of a Vault KV secret,
+//     read using VAULT_ADDR and VAULT_TOKEN
+//  3. FRED_API_KEY: the raw value
+//  4. The FRED_API_KEY entry of the SOPS file named by SOPS_SECRETS_FILE
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// FileSuffix is appended to a key to name the variable holding a secret file path
+	FileSuffix = "_FILE"
+
+	// VaultPrefix marks a value as a reference to a Vault secret
+	VaultPrefix = "vault:"
+
+	// Redacted replaces secret values in log output
+	Redacted = "[REDACTED]"
+
+	// VaultTimeout bounds each Vault request
+	VaultTimeout = 10 * time.Second
+)
+
+// Resolver looks up secrets and remembers resolved values for redaction.
+type Resolver struct {
+	lookupEnv  func(key string) (string, bool)
+	readFile   func(path string) ([]byte, error)
+	httpClient *http.Client
+
+	// decryptSOPS returns the decrypted contents of a SOPS file as JSON
+	decryptSOPS func(path string) ([]byte, error)
+
+	mu         sync.RWMutex
+	sopsValues map[string]string // Decrypted SOPS entries, loaded on first use
+	values     []string          // Resolved secret values to redact
+}
+
+// Option is a functional option for configuring the Resolver.
+type Option func(*Resolver)
+
+// WithLookup sets where the Resolver looks up keys and their _FILE paths,
+// the process environment by default, e.g. to also read a config file.
+func WithLookup(lookup func(key string) (string, bool)) Option {
+	return func(r *Resolver) {
+		r.lookupEnv = lookup
+	}
+}
+
+// New creates a Resolver reading from the process environment.
+func New(opts ...Option) *Resolver {
+	r := &Resolver{
+		lookupEnv:   os.LookupEnv,
+		readFile:    os.ReadFile,
+		httpClient:  &http.Client{Timeout: VaultTimeout},
+		decryptSOPS: decryptSOPSFile,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Get resolves the secret stored under key. It returns "" without error
+// when the secret is not configured anywhere.
+func (r *Resolver) Get(key string) (string, error) {
+	value, err := r.resolve(key)
+	if err != nil {
+		return "", err
+	}
+
+	r.remember(value)
+	return value, nil
+}
+
+// resolve tries each secret source in order of precedence.
+func (r *Resolver) resolve(key string) (string, error) {
+	if path, ok := r.lookupEnv(key + FileSuffix); ok && path != "" {
+		data, err := r.readFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s%s: %w", key, FileSuffix, err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	if value, ok := r.lookupEnv(key); ok && value != "" {
+		if ref, isVault := strings.CutPrefix(value, VaultPrefix); isVault {
+			secret, err := r.readVault(ref)
+			if err != nil {
+				return "", fmt.Errorf("failed to resolve %s from Vault: %w", key, err)
+			}
+			return secret, nil
+		}
+		return value, nil
+	}
+
+	return r.readSOPS(key)
+}
+
+// readVault reads one field of a Vault KV secret referenced as
+// "<path>#<field>", e.g. "secret/data/macro-analyst#fred_api_key".
+// Both KV version 1 and 2 responses are supported.
+func (r *Resolver) readVault(ref string) (string, error) {
+	path, field, found := strings.Cut(ref, "#")
+	if !found || path == "" || field == "" {
+		return "", fmt.Errorf("invalid reference %q (expected <path>#<field>)", ref)
+	}
+
+	addr, _ := r.lookupEnv("VAULT_ADDR")
+	token, _ := r.lookupEnv("VAULT_TOKEN")
+	if addr == "" || token == "" {
+		return "", errors.New("VAULT_ADDR and VAULT_TOKEN are required")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), VaultTimeout)
+	defer cancel()
+
+	url := strings.TrimSuffix(addr, "/") + "/v1/" + strings.TrimPrefix(path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("vault returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var secret struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return "", fmt.Errorf("failed to decode vault response: %w", err)
+	}
+
+	// KV version 2 nests the fields under data.data
+	fields := secret.Data
+	if nested, ok := secret.Data["data"]; ok {
+		var kv2 map[string]json.RawMessage
+		if err := json.Unmarshal(nested, &kv2); err == nil {
+			fields = kv2
+		}
+	}
+
+	raw, ok := fields[field]
+	if !ok {
+		return "", fmt.Errorf("field %q not found in %s", field, path)
+	}
+
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("field %q in %s is not a string", field, path)
+	}
+	return value, nil
+}
+
+// readSOPS returns the key's entry from the SOPS file, if one is configured.
+func (r *Resolver) readSOPS(key string) (string, error) {
+	path, _ := r.lookupEnv("SOPS_SECRETS_FILE")
+	if path == "" {
+		return "", nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.sopsValues == nil {
+		data, err := r.decryptSOPS(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to decrypt %s: %w", path, err)
+		}
+
+		values := make(map[string]string)
+		if err := json.Unmarshal(data, &values); err != nil {
+			return "", fmt.Errorf("failed to parse decrypted %s: %w", path, err)
+		}
+		r.sopsValues = values
+
+		// Every decrypted value is sensitive, even if never requested
+		for _, value := range values {
+			r.values = appendSecret(r.values, value)
+		}
+	}
+
+	return r.sopsValues[key], nil
+}
+
+// decryptSOPSFile decrypts a flat SOPS file to JSON using the sops CLI.
+func decryptSOPSFile(path string) ([]byte, error) {
+	out, err := exec.Command("sops", "--decrypt", "--output-type", "json", path).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("sops: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// remember records a resolved value for redaction.
+func (r *Resolver) remember(value string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values = appendSecret(r.values, value)
+}
+
+// appendSecret adds value to values unless it is empty or already present.
+func appendSecret(values []string, value string) []string {
+	if value == "" {
+		return values
+	}
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// Redact replaces every resolved secret value in s.
+func (r *Resolver) Redact(s string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, value := range r.values {
+		s = strings.ReplaceAll(s, value, Redacted)
+	}
+	return s
+}
+
+// Writer wraps w so that resolved secret values never reach it, e.g.
+// log.SetOutput(resolver.Writer(os.Stderr)).
+func (r *Resolver) Writer(w io.Writer) io.Writer {
+	return &redactingWriter{resolver: r, out: w}
+}
+
+// redactingWriter redacts secrets from each write. The log package issues
+// one write per entry, so a value is never split across writes.
+type redactingWriter struct {
+	resolver *Resolver
+	out      io.Writer
+}
+
+func (w *redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.out, w.resolver.Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Mask shows only whether a secret is set, for configuration dumps.
+func Mask(value string) string {
+	if value == "" {
+		return ""
+	}
+	return Redacted
+}
@@ -0,0 +1,142 @@
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestResolver creates a Resolver over the given environment.
+func newTestResolver(env map[string]string) *Resolver {
+	resolver := New(WithLookup(func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}))
+	resolver.decryptSOPS = func(path string) ([]byte, error) {
+		return nil, errors.New("sops not available")
+	}
+	return resolver
+}
+
+// TestGetFromEnv verifies raw environment values and missing keys.
+func TestGetFromEnv(t *testing.T) {
+	resolver := newTestResolver(map[string]string{"FRED_API_KEY": "env-key"})
+
+	if value, err := resolver.Get("FRED_API_KEY"); err != nil || value != "env-key" {
+		t.Errorf("Expected env-key, got %q (%v)", value, err)
+	}
+	if value, err := resolver.Get("MISSING"); err != nil || value != "" {
+		t.Errorf("Expected empty value for missing key, got %q (%v)", value, err)
+	}
+}
+
+// TestGetFromFile verifies *_FILE takes precedence and is trimmed.
+func TestGetFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fred_api_key")
+	if err := os.WriteFile(path, []byte("file-key\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write secret file: %v", err)
+	}
+
+	resolver := newTestResolver(map[string]string{
+		"FRED_API_KEY":      "env-key",
+		"FRED_API_KEY_FILE": path,
+	})
+
+	if value, err := resolver.Get("FRED_API_KEY"); err != nil || value != "file-key" {
+		t.Errorf("Expected file-key, got %q (%v)", value, err)
+	}
+}
+
+// TestGetFromMissingFile verifies an unreadable secret file is an error.
+func TestGetFromMissingFile(t *testing.T) {
+	resolver := newTestResolver(map[string]string{
+		"FRED_API_KEY_FILE": filepath.Join(t.TempDir(), "missing"),
+	})
+
+	if _, err := resolver.Get("FRED_API_KEY"); err == nil {
+		t.Error("Expected error for missing secret file")
+	}
+}
+
+// TestGetFromVault verifies KV v2 references are resolved.
+func TestGetFromVault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "vault-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/secret/data/macro-analyst" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"fred_api_key":"vault-key"},"metadata":{"version":1}}}`))
+	}))
+	defer server.Close()
+
+	resolver := newTestResolver(map[string]string{
+		"FRED_API_KEY": "vault:secret/data/macro-analyst#fred_api_key",
+		"VAULT_ADDR":   server.URL,
+		"VAULT_TOKEN":  "vault-token",
+	})
+
+	if value, err := resolver.Get("FRED_API_KEY"); err != nil || value != "vault-key" {
+		t.Errorf("Expected vault-key, got %q (%v)", value, err)
+	}
+
+	resolver = newTestResolver(map[string]string{
+		"FRED_API_KEY": "vault:secret/data/macro-analyst#missing",
+		"VAULT_ADDR":   server.URL,
+		"VAULT_TOKEN":  "vault-token",
+	})
+	if _, err := resolver.Get("FRED_API_KEY"); err == nil {
+		t.Error("Expected error for missing Vault field")
+	}
+}
+
+// TestGetFromSOPS verifies SOPS entries are used when nothing else is set.
+func TestGetFromSOPS(t *testing.T) {
+	resolver := newTestResolver(map[string]string{"SOPS_SECRETS_FILE": "secrets.enc.json"})
+	resolver.decryptSOPS = func(path string) ([]byte, error) {
+		return []byte(`{"FRED_API_KEY":"sops-key","SMTP_PASSWORD":"sops-password"}`), nil
+	}
+
+	if value, err := resolver.Get("FRED_API_KEY"); err != nil || value != "sops-key" {
+		t.Errorf("Expected sops-key, got %q (%v)", value, err)
+	}
+
+	// Values never requested are still redacted
+	if got := resolver.Redact("password=sops-password"); got != "password="+Redacted {
+		t.Errorf("Expected SOPS values to be redacted, got %q", got)
+	}
+}
+
+// TestRedactingWriter verifies resolved values never reach the log output.
+func TestRedactingWriter(t *testing.T) {
+	resolver := newTestResolver(map[string]string{"ADMIN_TOKEN": "s3cret"})
+	if _, err := resolver.Get("ADMIN_TOKEN"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	logger := log.New(resolver.Writer(&buf), "", 0)
+	logger.Printf("request failed: token=s3cret")
+
+	if got := buf.String(); got != "request failed: token="+Redacted+"\n" {
+		t.Errorf("Unexpected log output: %q", got)
+	}
+}
+
+// TestMask verifies configuration dumps never show secret values.
+func TestMask(t *testing.T) {
+	if Mask("") != "" {
+		t.Error("Expected empty mask for unset secret")
+	}
+	if Mask("value") != Redacted {
+		t.Error("Expected set secret to be masked")
+	}
+}
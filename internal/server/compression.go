@@ -0,0 +1,22 @@
+package server
+
+import (
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+// upgrade creates the WebSocket handler of a stream accepting the given
+// subprotocols. With compression on, clients that offer permessage-deflate
+// receive their messages compressed; others are served uncompressed.
+func (s *FiberServer) upgrade(handler func(*websocket.Conn), subprotocols ...string) fiber.Handler {
+	return websocket.New(func(c *websocket.Conn) {
+		// No-op unless deflate was negotiated; invalid levels keep the default
+		if s.compressionLevel != 0 {
+			c.SetCompressionLevel(s.compressionLevel)
+		}
+		handler(c)
+	}, websocket.Config{
+		Subprotocols:      subprotocols,
+		EnableCompression: s.compression,
+	})
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"net"
+	"testing"
+
+	"macro-analyst/internal/config"
+
+	fiberws "github.com/gofiber/contrib/websocket"
+	"github.com/gorilla/websocket"
+)
+
+// TestUpgradeCompression tests that permessage-deflate is negotiated only
+// when compression is on and the client offers it.
+func TestUpgradeCompression(t *testing.T) {
+	tests := []struct {
+		name          string
+		compression   bool
+		clientOffers  bool
+		expectDeflate bool
+	}{
+		{"on and offered", true, true, true},
+		{"on, not offered", true, false, false},
+		{"off", false, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(nil, config.ServerConfig{Compression: tt.compression, CompressionLevel: 6})
+			srv.App.Get("/ws/test", srv.upgrade(func(c *fiberws.Conn) {
+				c.WriteMessage(websocket.TextMessage, []byte(`{"type":"heartbeat"}`))
+			}))
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("Failed to listen: %v", err)
+			}
+			go srv.App.Listener(listener)
+			defer srv.App.Shutdown()
+
+			dialer := websocket.Dialer{EnableCompression: tt.clientOffers}
+			conn, resp, err := dialer.Dial("ws://"+listener.Addr().String()+"/ws/test", nil)
+			if err != nil {
+				t.Fatalf("Failed to connect: %v", err)
+			}
+			defer conn.Close()
+
+			extensions := resp.Header.Get("Sec-WebSocket-Extensions")
+			if deflate := extensions != ""; deflate != tt.expectDeflate {
+				t.Errorf("Expected deflate %v, got extensions %q", tt.expectDeflate, extensions)
+			}
+
+			// Compressed or not, the client reads the message
+			if _, message, err := conn.ReadMessage(); err != nil || string(message) != `{"type":"heartbeat"}` {
+				t.Errorf("Expected the heartbeat, got %q (%v)", message, err)
+			}
+		})
+	}
+}
@@ -28,6 +28,11 @@
 //
 // WebSocket Endpoints:
 //   - GET /ws/prices - Real-time price updates
+//   - GET /ws/candles - OHLCV candle updates (when CandleHub is set)
+//   - GET /ws/orderbook - Top bid and ask levels (when OrderBookHub is set)
+//
+// When Config.JWTSigningKey is set, both require a valid JWT in the token
+// query parameter or a "bearer.<jwt>" Sec-WebSocket-Protocol entry.
 //
 // # Usage
 //
@@ -44,17 +49,17 @@
 //
 // # Custom Configuration
 //
-// The server can be configured with custom settings:
+// The server takes the server part of the service configuration, with
+// the credentials resolved by the caller:
 //
-//	srv := server.New(hub, server.Config{
-//	    ServerHeader: "my-app",
-//	    AppName:      "my-app-v1",
-//	})
+//	cfg, err := config.Load(os.Getenv("CONFIG_FILE"), defaults)
+//	cfg.Server.AdminToken = adminToken
+//	srv := server.New(hub, cfg.Server)
 //
 // # Middleware
 //
 // The server includes CORS middleware by default, configured to:
-//   - Allow all origins (*)
+//   - Allow all origins (*), or those listed in config.ServerConfig.CORSOrigins
 //   - Support common HTTP methods
 //   - Cache preflight requests for 5 minutes
 //
@@ -0,0 +1,201 @@
+package server
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"macro-analyst/pkg/i18n"
+	"macro-analyst/pkg/ws"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateFeedRequest is the body of POST /api/admin/feeds.
+type CreateFeedRequest struct {
+	Name    string   `json:"name"`
+	Symbols []string `json:"symbols"`
+}
+
+// FeedSymbolsRequest is the body of POST /api/admin/feeds/:name/symbols and
+// POST /api/admin/symbols.
+type FeedSymbolsRequest struct {
+	Symbols []string `json:"symbols"`
+}
+
+// requireAdmin rejects requests without the configured admin bearer token.
+func (s *FiberServer) requireAdmin(c *fiber.Ctx) error {
+	token := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if s.adminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgUnauthorized),
+		})
+	}
+	return c.Next()
+}
+
+// ListFeedsHandler returns all running feeds and which one serves clients.
+func (s *FiberServer) ListFeedsHandler(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"feeds":         s.Feeds.Feeds(),
+		"active_source": s.Hub.ActiveSource(),
+	})
+}
+
+// CreateFeedHandler starts a standby feed that runs in the shadow of the
+// active feed until it is activated.
+func (s *FiberServer) CreateFeedHandler(c *fiber.Ctx) error {
+	var req CreateFeedRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgInvalidBody),
+		})
+	}
+
+	ingestor, err := s.Feeds.Create(c.Context(), req.Name, req.Symbols)
+	if err != nil {
+		return c.Status(feedErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(ws.FeedInfo{
+		Name:    ingestor.Name(),
+		Symbols: ingestor.GetSymbols(),
+		Active:  s.Hub.IsActiveSource(ingestor.Name()),
+	})
+}
+
+// ActivateFeedHandler cuts clients over to the named feed.
+func (s *FiberServer) ActivateFeedHandler(c *fiber.Ctx) error {
+	name := c.Params("name")
+
+	if err := s.Feeds.Activate(name); err != nil {
+		return c.Status(feedErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"active_source": name,
+	})
+}
+
+// RemoveFeedHandler stops and removes an inactive feed.
+func (s *FiberServer) RemoveFeedHandler(c *fiber.Ctx) error {
+	name := c.Params("name")
+
+	if err := s.Feeds.Remove(name); err != nil {
+		return c.Status(feedErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"removed": name,
+	})
+}
+
+// AddFeedSymbolsHandler adds symbols to a running feed without a restart.
+func (s *FiberServer) AddFeedSymbolsHandler(c *fiber.Ctx) error {
+	return s.addSymbols(c, c.Params("name"))
+}
+
+// RemoveFeedSymbolHandler stops streaming a symbol on a running feed.
+func (s *FiberServer) RemoveFeedSymbolHandler(c *fiber.Ctx) error {
+	return s.removeSymbol(c, c.Params("name"))
+}
+
+// ListSymbolsHandler returns the symbols tracked by the feed that serves
+// clients.
+func (s *FiberServer) ListSymbolsHandler(c *fiber.Ctx) error {
+	ingestor := s.Feeds.Active()
+	if ingestor == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": ws.ErrFeedNotFound.Error(),
+		})
+	}
+
+	return c.JSON(ws.FeedInfo{
+		Name:    ingestor.Name(),
+		Symbols: ingestor.GetSymbols(),
+		Active:  true,
+	})
+}
+
+// AddSymbolsHandler adds symbols to the feed that serves clients.
+func (s *FiberServer) AddSymbolsHandler(c *fiber.Ctx) error {
+	return s.addSymbols(c, s.activeFeedName())
+}
+
+// RemoveSymbolHandler stops streaming a symbol on the feed that serves
+// clients.
+func (s *FiberServer) RemoveSymbolHandler(c *fiber.Ctx) error {
+	return s.removeSymbol(c, s.activeFeedName())
+}
+
+// activeFeedName returns the name of the feed that serves clients, or an
+// empty name, which no feed has, if there are no feeds.
+func (s *FiberServer) activeFeedName() string {
+	if ingestor := s.Feeds.Active(); ingestor != nil {
+		return ingestor.Name()
+	}
+	return ""
+}
+
+// addSymbols adds the symbols in the request body to the named feed and
+// responds with its symbols.
+func (s *FiberServer) addSymbols(c *fiber.Ctx, name string) error {
+	var req FeedSymbolsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgInvalidBody),
+		})
+	}
+
+	symbols, err := s.Feeds.AddSymbols(c.Context(), name, req.Symbols)
+	if err != nil {
+		return c.Status(feedErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(ws.FeedInfo{
+		Name:    name,
+		Symbols: symbols,
+		Active:  s.Hub.IsActiveSource(name),
+	})
+}
+
+// removeSymbol removes the symbol in the path from the named feed and
+// responds with its remaining symbols.
+func (s *FiberServer) removeSymbol(c *fiber.Ctx, name string) error {
+	symbols, err := s.Feeds.RemoveSymbol(name, c.Params("symbol"))
+	if err != nil {
+		return c.Status(feedErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(ws.FeedInfo{
+		Name:    name,
+		Symbols: symbols,
+		Active:  s.Hub.IsActiveSource(name),
+	})
+}
+
+// feedErrorStatus maps FeedSwitch errors to HTTP status codes.
+func feedErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ws.ErrFeedNotFound), errors.Is(err, ws.ErrSymbolNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, ws.ErrFeedExists), errors.Is(err, ws.ErrFeedActive):
+		return fiber.StatusConflict
+	case errors.Is(err, ws.ErrUnknownSymbol):
+		return fiber.StatusUnprocessableEntity
+	case errors.Is(err, ws.ErrSymbolCatalogUnavailable):
+		return fiber.StatusServiceUnavailable
+	default:
+		return fiber.StatusBadRequest
+	}
+}
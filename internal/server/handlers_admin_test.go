@@ -0,0 +1,193 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"macro-analyst/pkg/ws"
+)
+
+// withSymbolFeed gives the server a primary feed whose symbols are
+// validated against a fixed listing instead of Binance.
+func withSymbolFeed(t *testing.T) func(srv *FiberServer) {
+	return func(srv *FiberServer) {
+		lister := ws.WithSymbolLister(func(ctx context.Context) ([]string, error) {
+			return []string{"BTCUSDT", "ETHUSDT", "DOGEUSDT"}, nil
+		})
+		srv.Feeds = ws.NewFeedSwitch(srv.Hub, lister)
+		if err := srv.Feeds.Add(ws.NewIngestor(ws.HubSink(srv.Hub), lister)); err != nil {
+			t.Fatalf("Failed to add feed: %v", err)
+		}
+	}
+}
+
+// TestAdminRequiresToken tests that admin routes reject missing or wrong tokens.
+func TestAdminRequiresToken(t *testing.T) {
+	srv := newAPITestServer(withSymbolFeed(t))
+
+	for _, token := range []string{"", "wrong"} {
+		resp := doAdminRequest(t, srv, http.MethodGet, "/api/admin/feeds", token, "")
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Token %q: expected status %d, got %d", token, http.StatusUnauthorized, resp.StatusCode)
+		}
+	}
+}
+
+// TestAdminRoutesDisabledWithoutToken tests that admin routes are absent without a token.
+func TestAdminRoutesDisabledWithoutToken(t *testing.T) {
+	hub := ws.NewHub()
+	srv := New(hub)
+	srv.Feeds = ws.NewFeedSwitch(hub)
+	srv.RegisterFiberRoutes()
+
+	resp := doAdminRequest(t, srv, http.MethodGet, "/api/admin/feeds", "", "")
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+// TestListFeedsHandler tests listing feeds.
+func TestListFeedsHandler(t *testing.T) {
+	srv := newAPITestServer(withSymbolFeed(t))
+
+	resp := doAdminRequest(t, srv, http.MethodGet, "/api/admin/feeds", "secret", "")
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var body struct {
+		Feeds        []ws.FeedInfo `json:"feeds"`
+		ActiveSource string        `json:"active_source"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if len(body.Feeds) != 1 || body.Feeds[0].Name != ws.DefaultSourceName || !body.Feeds[0].Active {
+		t.Errorf("Unexpected feeds: %+v", body.Feeds)
+	}
+}
+
+// TestActivateAndRemoveFeedHandlers tests error mapping of feed operations.
+func TestActivateAndRemoveFeedHandlers(t *testing.T) {
+	srv := newAPITestServer(withSymbolFeed(t))
+
+	resp := doAdminRequest(t, srv, http.MethodPost, "/api/admin/feeds/missing/activate", "secret", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status %d for unknown feed, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+
+	resp = doAdminRequest(t, srv, http.MethodPost, "/api/admin/feeds/"+ws.DefaultSourceName+"/activate", "secret", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	resp = doAdminRequest(t, srv, http.MethodDelete, "/api/admin/feeds/"+ws.DefaultSourceName, "secret", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("Expected status %d when removing active feed, got %d", http.StatusConflict, resp.StatusCode)
+	}
+}
+
+// TestCreateFeedHandlerValidation tests that invalid feed requests are rejected.
+func TestCreateFeedHandlerValidation(t *testing.T) {
+	srv := newAPITestServer(withSymbolFeed(t))
+
+	resp := doAdminRequest(t, srv, http.MethodPost, "/api/admin/feeds", "secret", `{"name":"green"}`)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+// TestFeedSymbolHandlers tests adding and removing symbols of a running feed.
+func TestFeedSymbolHandlers(t *testing.T) {
+	srv := newAPITestServer(withSymbolFeed(t))
+	path := "/api/admin/feeds/" + ws.DefaultSourceName + "/symbols"
+
+	resp := doAdminRequest(t, srv, http.MethodPost, path, "secret", `{"symbols":["DOGEUSDT"]}`)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var feed ws.FeedInfo
+	if err := json.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if feed.Symbols[len(feed.Symbols)-1] != "DOGEUSDT" {
+		t.Errorf("Expected DOGEUSDT to be streamed, got %v", feed.Symbols)
+	}
+
+	tests := []struct {
+		method   string
+		path     string
+		body     string
+		expected int
+	}{
+		{http.MethodPost, path, `{"symbols":[]}`, http.StatusBadRequest},
+		{http.MethodPost, path, `{"symbols":["DOGE.USDT"]}`, http.StatusBadRequest},
+		{http.MethodPost, path, `{"symbols":["TESTUSDT"]}`, http.StatusUnprocessableEntity},
+		{http.MethodPost, "/api/admin/feeds/missing/symbols", `{"symbols":["DOGEUSDT"]}`, http.StatusNotFound},
+		{http.MethodDelete, path + "/DOGEUSDT", "", http.StatusOK},
+		{http.MethodDelete, path + "/DOGEUSDT", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		resp := doAdminRequest(t, srv, tt.method, tt.path, "secret", tt.body)
+		resp.Body.Close()
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.path, tt.expected, resp.StatusCode)
+		}
+	}
+}
+
+// TestActiveFeedSymbolHandlers tests managing the symbols of the feed that
+// serves clients.
+func TestActiveFeedSymbolHandlers(t *testing.T) {
+	srv := newAPITestServer(withSymbolFeed(t))
+
+	resp := doAdminRequest(t, srv, http.MethodPost, "/api/admin/symbols", "secret", `{"symbols":["dogeusdt"]}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	resp = doAdminRequest(t, srv, http.MethodGet, "/api/admin/symbols", "secret", "")
+	defer resp.Body.Close()
+	var feed ws.FeedInfo
+	if err := json.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if feed.Name != ws.DefaultSourceName || feed.Symbols[len(feed.Symbols)-1] != "DOGEUSDT" {
+		t.Errorf("Expected DOGEUSDT on the %s feed, got %+v", ws.DefaultSourceName, feed)
+	}
+
+	tests := []struct {
+		method   string
+		path     string
+		body     string
+		expected int
+	}{
+		{http.MethodPost, "/api/admin/symbols", `{"symbols":["TESTUSDT"]}`, http.StatusUnprocessableEntity},
+		{http.MethodPost, "/api/admin/symbols", `not json`, http.StatusBadRequest},
+		{http.MethodDelete, "/api/admin/symbols/DOGEUSDT", "", http.StatusOK},
+		{http.MethodDelete, "/api/admin/symbols/DOGEUSDT", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		resp := doAdminRequest(t, srv, tt.method, tt.path, "secret", tt.body)
+		resp.Body.Close()
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.path, tt.expected, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"errors"
+
+	"macro-analyst/internal/jwt"
+	"macro-analyst/pkg/i18n"
+	"macro-analyst/pkg/ws"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListAlertsHandler returns the price alerts of the token's user.
+func (s *FiberServer) ListAlertsHandler(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"alerts": s.Alerts.List(alertOwner(c)),
+	})
+}
+
+// CreateAlertHandler registers a price alert for the token's user, sent to
+// each of their /ws/prices connections as an alert message when it
+// triggers.
+func (s *FiberServer) CreateAlertHandler(c *fiber.Ctx) error {
+	var rule ws.AlertRule
+	if err := c.BodyParser(&rule); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgInvalidBody),
+		})
+	}
+
+	rule.Symbol = ws.NormalizeSymbol(rule.Symbol)
+	if active := s.Feeds.Active(); active != nil {
+		rule.Symbol = active.ResolveSymbol(rule.Symbol)
+	}
+
+	rule, err := s.Alerts.Add(alertOwner(c), rule)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		if errors.Is(err, ws.ErrTooManyAlerts) {
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(rule)
+}
+
+// DeleteAlertHandler removes a price alert of the token's user.
+func (s *FiberServer) DeleteAlertHandler(c *fiber.Ctx) error {
+	if err := s.Alerts.Remove(alertOwner(c), c.Params("id")); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"removed": c.Params("id"),
+	})
+}
+
+// ExportAlertsHandler returns the price alerts of the token's user as a
+// YAML document ImportAlertsHandler takes.
+func (s *FiberServer) ExportAlertsHandler(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, "application/yaml")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="alerts.yaml"`)
+	return c.Send(ws.MarshalAlertsYAML(s.Alerts.List(alertOwner(c))))
+}
+
+// ImportAlertsHandler imports the price alerts of a YAML document for the
+// token's user. The mode query parameter validates the document
+// ("validate"), checks it against the user's alerts ("dry-run", the
+// default) or registers its rules ("apply"); replace=true removes the
+// user's alerts first instead of adding to them. Symbols are checked
+// against the Binance catalog, like the symbols the admin API adds.
+func (s *FiberServer) ImportAlertsHandler(c *fiber.Ctx) error {
+	rules, err := ws.ParseAlertsYAML(c.Body())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	resolve := func(symbol string) (string, error) {
+		return ws.NormalizeSymbol(symbol), nil
+	}
+	if active := s.Feeds.Active(); active != nil {
+		resolve = func(symbol string) (string, error) {
+			return active.ValidateSymbol(c.UserContext(), symbol)
+		}
+	}
+
+	report, err := s.Alerts.Import(alertOwner(c), rules, c.Query("mode", ws.ImportDryRun), c.Query("replace") == "true", resolve)
+	switch {
+	case errors.Is(err, ws.ErrSymbolCatalogUnavailable):
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, ws.ErrInvalidImportMode):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, ws.ErrInvalidAlerts):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(report)
+	case errors.Is(err, ws.ErrTooManyAlerts):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":  err.Error(),
+			"import": report,
+		})
+	}
+
+	return c.JSON(report)
+}
+
+// alertOwner returns the owner of the alerts of the request's user, which
+// requireJWT verified.
+func alertOwner(c *fiber.Ctx) string {
+	claims, _ := c.Locals(claimsLocal).(jwt.Claims)
+	return ws.UserAlertOwner(claims.Subject)
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"macro-analyst/internal/config"
+	"macro-analyst/internal/jwt"
+	"macro-analyst/pkg/ws"
+)
+
+// TestAlertHandlers tests managing the price alerts of a JWT user over REST.
+func TestAlertHandlers(t *testing.T) {
+	hub := ws.NewHub()
+	srv := New(hub, config.ServerConfig{JWTSigningKey: "alert-key"})
+	srv.Feeds = ws.NewFeedSwitch(hub)
+	srv.Feeds.Add(ws.NewIngestor(ws.HubSink(hub)))
+	srv.Alerts = ws.NewAlertBook()
+	srv.RegisterFiberRoutes()
+
+	sign := func(subject string) string {
+		token, _ := jwt.NewVerifier([]byte("alert-key")).Sign(jwt.Claims{
+			Subject:   subject,
+			ExpiresAt: time.Now().Add(time.Hour).Unix(),
+		})
+		return token
+	}
+	request := func(method, path, token, body string) *http.Response {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := srv.App.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to execute request: %v", err)
+		}
+		return resp
+	}
+
+	resp := request(http.MethodPost, "/api/alerts", sign("user-1"), `{"symbol":"eth","condition":"change","percent":-5,"windowMs":60000}`)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+	var rule ws.AlertRule
+	if err := json.NewDecoder(resp.Body).Decode(&rule); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if rule.ID == "" || rule.Symbol != "ETHUSDT" {
+		t.Errorf("Unexpected alert %+v", rule)
+	}
+	if rules := srv.Alerts.List(ws.UserAlertOwner("user-1")); len(rules) != 1 {
+		t.Errorf("Expected the alert to belong to user-1, got %+v", rules)
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		token    string
+		body     string
+		expected int
+	}{
+		{"no token", http.MethodGet, "/api/alerts", "", "", http.StatusUnauthorized},
+		{"list", http.MethodGet, "/api/alerts", sign("user-1"), "", http.StatusOK},
+		{"invalid rule", http.MethodPost, "/api/alerts", sign("user-1"), `{"symbol":"BTCUSDT","condition":"above"}`, http.StatusBadRequest},
+		{"other user's alert", http.MethodDelete, "/api/alerts/" + rule.ID, sign("user-2"), "", http.StatusNotFound},
+		{"delete", http.MethodDelete, "/api/alerts/" + rule.ID, sign("user-1"), "", http.StatusOK},
+		{"deleted", http.MethodDelete, "/api/alerts/" + rule.ID, sign("user-1"), "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		resp := request(tt.method, tt.path, tt.token, tt.body)
+		resp.Body.Close()
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expected, resp.StatusCode)
+		}
+	}
+}
+
+// TestAlertImportExportHandlers tests moving price alerts between users as
+// YAML.
+func TestAlertImportExportHandlers(t *testing.T) {
+	lister := ws.WithSymbolLister(func(ctx context.Context) ([]string, error) {
+		return []string{"BTCUSDT", "ETHUSDT"}, nil
+	})
+	hub := ws.NewHub()
+	srv := New(hub, config.ServerConfig{JWTSigningKey: "alert-key"})
+	srv.Feeds = ws.NewFeedSwitch(hub)
+	srv.Feeds.Add(ws.NewIngestor(ws.HubSink(hub), lister))
+	srv.Alerts = ws.NewAlertBook()
+	srv.RegisterFiberRoutes()
+
+	sign := func(subject string) string {
+		token, _ := jwt.NewVerifier([]byte("alert-key")).Sign(jwt.Claims{
+			Subject:   subject,
+			ExpiresAt: time.Now().Add(time.Hour).Unix(),
+		})
+		return token
+	}
+	request := func(method, path, token, body string) (*http.Response, string) {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/yaml")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := srv.App.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to execute request: %v", err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return resp, string(data)
+	}
+
+	srv.Alerts.Add(ws.UserAlertOwner("user-1"), ws.AlertRule{Symbol: "BTCUSDT", Condition: ws.AlertCrosses, Price: 100000})
+	resp, exported := request(http.MethodGet, "/api/alerts/export", sign("user-1"), "")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/yaml" {
+		t.Fatalf("Expected a YAML export, got status %d and %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if !strings.Contains(exported, "symbol: BTCUSDT") || strings.Contains(exported, "id:") {
+		t.Errorf("Unexpected export %q", exported)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		expected int
+		alerts   int
+	}{
+		{"dry run by default", "/api/alerts/import", exported, http.StatusOK, 0},
+		{"unknown mode", "/api/alerts/import?mode=merge", exported, http.StatusBadRequest, 0},
+		{"malformed", "/api/alerts/import?mode=apply", "alerts:\n  - level: 1\n", http.StatusBadRequest, 0},
+		{"invalid rule", "/api/alerts/import?mode=apply", "alerts:\n  - symbol: BTCUSDT\n    condition: above\n", http.StatusUnprocessableEntity, 0},
+		{"unlisted symbol", "/api/alerts/import?mode=apply", "alerts:\n  - symbol: DOGEUSDT\n    condition: crosses\n    price: 1\n", http.StatusUnprocessableEntity, 0},
+		{"apply", "/api/alerts/import?mode=apply", exported, http.StatusOK, 1},
+		{"apply short symbol", "/api/alerts/import?mode=apply", "alerts:\n  - symbol: eth\n    condition: crosses\n    price: 5000\n", http.StatusOK, 2},
+		{"replace", "/api/alerts/import?mode=apply&replace=true", exported, http.StatusOK, 1},
+	}
+	for _, tt := range tests {
+		resp, body := request(http.MethodPost, tt.path, sign("user-2"), tt.body)
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s: expected status %d, got %d (%s)", tt.name, tt.expected, resp.StatusCode, body)
+		}
+		if alerts := srv.Alerts.List(ws.UserAlertOwner("user-2")); len(alerts) != tt.alerts {
+			t.Errorf("%s: expected %d alerts, got %+v", tt.name, tt.alerts, alerts)
+		}
+	}
+
+	if rules := srv.Alerts.List(ws.UserAlertOwner("user-1")); len(rules) != 1 {
+		t.Errorf("Expected importing to leave user-1's alerts alone, got %+v", rules)
+	}
+}
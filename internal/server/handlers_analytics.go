@@ -0,0 +1,351 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"macro-analyst/internal/analytics"
+	"macro-analyst/internal/attribution"
+	"macro-analyst/pkg/fred"
+	"macro-analyst/pkg/i18n"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// AnalyticsTimeout bounds fetching all series of an analytics request.
+	AnalyticsTimeout = 30 * time.Second
+
+	// MaxAlignSeries limits how many series one alignment request may combine.
+	MaxAlignSeries = 8
+
+	// DefaultAlignRange is the date range aligned when no start is given.
+	DefaultAlignRange = 365 * 24 * time.Hour
+
+	// MaxReturnSymbols limits how many symbols one returns request may cover.
+	MaxReturnSymbols = 20
+
+	// DefaultSeasonalityYears is how much history seasonality covers by default.
+	DefaultSeasonalityYears = 8
+
+	// MaxSeasonalityYears limits the history one seasonality request may load.
+	MaxSeasonalityYears = 15
+
+	// RiskFreeSeries is the series Sharpe ratios use as the risk-free rate.
+	RiskFreeSeries = analytics.SourceFRED + ":" + string(fred.TickerFEDFUNDS)
+
+	// fredObservationLimit is the largest page the FRED API accepts
+	fredObservationLimit = 100000
+)
+
+var (
+	// errUnknownSource is returned for series IDs without a known source prefix
+	errUnknownSource = errors.New("unknown series source")
+
+	// errSourceUnavailable is returned when the series source is not configured
+	errSourceUnavailable = errors.New("series source not configured")
+)
+
+// AlignHandler returns several FRED and crypto series resampled onto a
+// common time grid, so the frontend can overlay e.g. BTC on net liquidity
+// without resampling client-side.
+//
+// Query parameters: series (comma-separated, e.g. fred:WALCL,crypto:BTCUSDT),
+// start and end (YYYY-MM-DD, default the last year) and grid (daily, weekly
+// or monthly, default daily).
+func (s *FiberServer) AlignHandler(c *fiber.Ctx) error {
+	ids := splitSeriesIDs(c.Query("series"))
+	if len(ids) == 0 || len(ids) > MaxAlignSeries {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("series must list 1 to %d series, e.g. fred:WALCL,crypto:BTCUSDT", MaxAlignSeries),
+		})
+	}
+
+	return s.respondAligned(c, ids, analytics.GridDaily)
+}
+
+// respondAligned responds with the series aligned over the start, end and
+// grid query parameters, using defaultGrid when no grid is given.
+func (s *FiberServer) respondAligned(c *fiber.Ctx, ids []string, defaultGrid string) error {
+	start, end, err := parseDateRange(c.Query("start"), c.Query("end"), DefaultAlignRange)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	grid := c.Query("grid", defaultGrid)
+	dates, err := analytics.Grid(grid, start, end)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), AnalyticsTimeout)
+	defer cancel()
+
+	// Fetch enough history before the start to forward-fill the first dates
+	lookback := start.Add(-analytics.MaxFillAge("quarterly"))
+	series := make([]analytics.Series, len(ids))
+	for idx, id := range ids {
+		if series[idx], err = s.fetchSeries(ctx, id, lookback, end); err != nil {
+			return c.Status(seriesErrorStatus(err)).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	return c.JSON(struct {
+		analytics.Alignment
+		Attribution []*attribution.Attribution `json:"attribution,omitempty"`
+	}{analytics.Align(grid, dates, series), s.seriesAttribution(series)})
+}
+
+// SymbolReturns holds the return statistics of one symbol.
+type SymbolReturns struct {
+	Symbol  string                  `json:"symbol"`
+	Windows []analytics.WindowStats `json:"windows"`
+}
+
+// ReturnsHandler returns trailing 30, 90 and 365-day returns, volatility and
+// Sharpe ratios of crypto symbols, using FEDFUNDS as the risk-free rate.
+//
+// Query parameters: symbols (comma-separated, default the symbols tracked by
+// the active feed).
+func (s *FiberServer) ReturnsHandler(c *fiber.Ctx) error {
+	symbols := splitSeriesIDs(c.Query("symbols"))
+	for idx, symbol := range symbols {
+		symbols[idx] = s.resolveSymbol(symbol)
+	}
+	if len(symbols) == 0 && s.Feeds != nil {
+		if active := s.Feeds.Active(); active != nil {
+			symbols = active.GetSymbols()
+		}
+	}
+	if len(symbols) == 0 || len(symbols) > MaxReturnSymbols {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("symbols must list 1 to %d symbols, e.g. BTCUSDT,ETHUSDT", MaxReturnSymbols),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), AnalyticsTimeout)
+	defer cancel()
+
+	longest := slices.Max(analytics.DefaultReturnWindows)
+	end := time.Now().UTC()
+	start := end.AddDate(0, 0, -longest-1)
+
+	// Without FRED, Sharpe ratios fall back to a 0% risk-free rate
+	var riskFree analytics.Series
+	riskFreeSource := ""
+	if s.FREDClient != nil {
+		series, err := s.fetchSeries(ctx, RiskFreeSeries, start.Add(-analytics.MaxFillAge("monthly")), end)
+		if err != nil {
+			return c.Status(seriesErrorStatus(err)).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		riskFree = series
+		riskFreeSource = RiskFreeSeries
+	}
+
+	fetched := []analytics.Series{riskFree}
+	results := make([]SymbolReturns, len(symbols))
+	for idx, symbol := range symbols {
+		prices, err := s.fetchSeries(ctx, analytics.SourceCrypto+":"+symbol, start, end)
+		if err != nil {
+			return c.Status(seriesErrorStatus(err)).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		fetched = append(fetched, prices)
+		results[idx] = SymbolReturns{
+			Symbol:  symbol,
+			Windows: analytics.Returns(prices, riskFree, analytics.DefaultReturnWindows),
+		}
+	}
+	if riskFreeSource == "" {
+		fetched = fetched[1:]
+	}
+
+	body := fiber.Map{
+		"risk_free": riskFreeSource,
+		"symbols":   results,
+	}
+	if attrs := s.seriesAttribution(fetched); len(attrs) > 0 {
+		body["attribution"] = attrs
+	}
+
+	return c.JSON(body)
+}
+
+// SeasonalityHandler returns the average return of a crypto symbol per
+// calendar month and per weekday, with sample counts and significance hints.
+//
+// Query parameters: symbol (required, e.g. BTCUSDT) and years of history
+// (default 8).
+func (s *FiberServer) SeasonalityHandler(c *fiber.Ctx) error {
+	symbol := strings.TrimSpace(c.Query("symbol"))
+	if symbol == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgSymbolRequired, "BTCUSDT"),
+		})
+	}
+	symbol = s.resolveSymbol(symbol)
+
+	years := c.QueryInt("years", DefaultSeasonalityYears)
+	if years < 1 || years > MaxSeasonalityYears {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("years must be between 1 and %d", MaxSeasonalityYears),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), AnalyticsTimeout)
+	defer cancel()
+
+	end := time.Now().UTC()
+	prices, err := s.fetchSeries(ctx, analytics.SourceCrypto+":"+symbol, end.AddDate(-years, 0, 0), end)
+	if err != nil {
+		return c.Status(seriesErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(struct {
+		Symbol string `json:"symbol"`
+		analytics.Seasonality
+		Attribution []*attribution.Attribution `json:"attribution,omitempty"`
+	}{symbol, analytics.SeasonalityOf(prices), s.seriesAttribution([]analytics.Series{prices})})
+}
+
+// fetchSeries loads a series by ID ("fred:<ticker>" or "crypto:<symbol>")
+// between start and end.
+func (s *FiberServer) fetchSeries(ctx context.Context, id string, start, end time.Time) (analytics.Series, error) {
+	source, name, _ := strings.Cut(id, ":")
+	if name == "" {
+		return analytics.Series{}, fmt.Errorf("%w: %q (expected fred:<ticker> or crypto:<symbol>)", errUnknownSource, id)
+	}
+
+	switch strings.ToLower(source) {
+	case analytics.SourceFRED:
+		if s.FREDClient == nil {
+			return analytics.Series{}, fmt.Errorf("%w: FRED API client not configured", errSourceUnavailable)
+		}
+		ticker := fred.Ticker(strings.ToUpper(name))
+		if !s.Tickers.Has(ticker) {
+			return analytics.Series{}, fmt.Errorf("%w: %s", fred.ErrUnknownTicker, ticker)
+		}
+		data, err := s.fredHistory().GetSeriesObservations(ctx, ticker, &fred.QueryOptions{
+			StartDate: start.Format(analytics.DateLayout),
+			EndDate:   end.Format(analytics.DateLayout),
+			Limit:     fredObservationLimit,
+			SortOrder: "asc",
+		})
+		if err != nil {
+			return analytics.Series{}, fmt.Errorf("failed to fetch %s: %w", id, err)
+		}
+		return analytics.FromFRED(data), nil
+
+	case analytics.SourceCrypto:
+		if s.CryptoHistory == nil {
+			return analytics.Series{}, fmt.Errorf("%w: crypto history not configured", errSourceUnavailable)
+		}
+		symbol := s.resolveSymbol(name)
+		points, err := s.CryptoHistory(ctx, symbol, start, end)
+		if err != nil {
+			return analytics.Series{}, fmt.Errorf("failed to fetch %s: %w", id, err)
+		}
+		return analytics.Series{
+			ID:        analytics.SourceCrypto + ":" + symbol,
+			Source:    "Binance",
+			Frequency: "Daily",
+			Points:    points,
+		}, nil
+
+	default:
+		return analytics.Series{}, fmt.Errorf("%w: %q (expected fred:<ticker> or crypto:<symbol>)", errUnknownSource, id)
+	}
+}
+
+// fredHistory returns where FRED series are read from: the stored history
+// if there is one, or else FRED.
+func (s *FiberServer) fredHistory() fred.Client {
+	if s.FREDHistory != nil {
+		return s.FREDHistory
+	}
+	return s.FREDClient
+}
+
+// seriesAttribution credits the sources of the given series.
+func (s *FiberServer) seriesAttribution(series []analytics.Series) []*attribution.Attribution {
+	var attrs []*attribution.Attribution
+	binanceCredited := false
+
+	for _, ser := range series {
+		var attr *attribution.Attribution
+		if ticker, isFRED := strings.CutPrefix(ser.ID, analytics.SourceFRED+":"); isFRED {
+			attr = s.Attribution.FRED(ticker, time.Now())
+		} else if !binanceCredited {
+			attr = s.Attribution.Binance(time.Now())
+			binanceCredited = true
+		}
+		if attr != nil {
+			attrs = append(attrs, attr)
+		}
+	}
+	return attrs
+}
+
+// seriesErrorStatus maps fetchSeries errors to HTTP status codes.
+func seriesErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, errUnknownSource):
+		return fiber.StatusBadRequest
+	case errors.Is(err, fred.ErrUnknownTicker):
+		return fiber.StatusNotFound
+	case errors.Is(err, errSourceUnavailable):
+		return fiber.StatusServiceUnavailable
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// splitSeriesIDs splits a comma-separated list of series IDs.
+func splitSeriesIDs(list string) []string {
+	var ids []string
+	for _, id := range strings.Split(list, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// parseDateRange parses optional start and end dates (YYYY-MM-DD). The end
+// defaults to today and the start to defaultRange before the end.
+func parseDateRange(startParam, endParam string, defaultRange time.Duration) (time.Time, time.Time, error) {
+	end := time.Now().UTC()
+	if endParam != "" {
+		parsed, err := time.Parse(analytics.DateLayout, endParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end date %q (expected YYYY-MM-DD)", endParam)
+		}
+		end = parsed
+	}
+
+	start := end.Add(-defaultRange)
+	if startParam != "" {
+		parsed, err := time.Parse(analytics.DateLayout, startParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid start date %q (expected YYYY-MM-DD)", startParam)
+		}
+		start = parsed
+	}
+
+	return start, end, nil
+}
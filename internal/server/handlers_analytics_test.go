@@ -0,0 +1,239 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"macro-analyst/internal/analytics"
+	"macro-analyst/pkg/fred"
+	"macro-analyst/pkg/ws"
+)
+
+// seriesFREDClient returns fixed weekly observations for any ticker.
+type seriesFREDClient struct {
+	stubFREDClient
+}
+
+func (seriesFREDClient) GetSeriesObservations(ctx context.Context, ticker fred.Ticker, opts *fred.QueryOptions) (*fred.SeriesData, error) {
+	return &fred.SeriesData{
+		Ticker:    ticker,
+		Frequency: "Weekly, Ending Wednesday",
+		Observations: []fred.Observation{
+			{Date: "2023-12-27", Value: "7700000"},
+			{Date: "2024-01-03", Value: "7650000"},
+		},
+	}, nil
+}
+
+// stubCryptoHistory returns one close per day from start.
+func stubCryptoHistory(ctx context.Context, symbol string, start, end time.Time) ([]analytics.Point, error) {
+	var points []analytics.Point
+	for day, price := start, 40000.0; !day.After(end); day, price = day.AddDate(0, 0, 1), price+100 {
+		points = append(points, analytics.Point{Time: day, Value: price})
+	}
+	return points, nil
+}
+
+// withAnalyticsStubs gives the server stubbed FRED and crypto history.
+func withAnalyticsStubs(srv *FiberServer) {
+	srv.FREDClient = seriesFREDClient{}
+	srv.CryptoHistory = stubCryptoHistory
+}
+
+// TestAlignHandler tests aligning a FRED and a crypto series on a daily grid.
+func TestAlignHandler(t *testing.T) {
+	// Arrange
+	srv := newAPITestServer(withAnalyticsStubs)
+
+	// Act
+	resp := doAPIRequest(t, srv, http.MethodGet, "/api/analytics/align?series=fred:WALCL,crypto:btcusdt&start=2024-01-01&end=2024-01-04", "", "")
+	defer resp.Body.Close()
+
+	// Assert
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var body analytics.Alignment
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(body.Dates) != 4 || body.Dates[0] != "2024-01-01" {
+		t.Errorf("Expected 4 dates from 2024-01-01, got %v", body.Dates)
+	}
+	if len(body.Series) != 2 {
+		t.Fatalf("Expected 2 series, got %d", len(body.Series))
+	}
+	if body.Series[1].ID != "crypto:BTCUSDT" {
+		t.Errorf("Expected crypto:BTCUSDT, got %s", body.Series[1].ID)
+	}
+	walcl := body.Series[0].Values
+	if walcl[0] == nil || *walcl[0] != 7700000 || walcl[3] == nil || *walcl[3] != 7650000 {
+		t.Errorf("Expected forward-filled WALCL values, got %v", walcl)
+	}
+}
+
+// TestAlignHandlerErrors tests request validation.
+func TestAlignHandlerErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected int
+	}{
+		{"no series", "", http.StatusBadRequest},
+		{"unknown source", "?series=yahoo:SPY", http.StatusBadRequest},
+		{"unknown ticker", "?series=fred:UNRATE", http.StatusNotFound},
+		{"invalid date", "?series=fred:WALCL&start=01/01/2024", http.StatusBadRequest},
+		{"invalid grid", "?series=fred:WALCL&grid=hourly", http.StatusBadRequest},
+	}
+
+	srv := newAPITestServer(withAnalyticsStubs)
+	for _, tt := range tests {
+		resp := doAPIRequest(t, srv, http.MethodGet, "/api/analytics/align"+tt.query, "", "")
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expected, resp.StatusCode)
+		}
+	}
+}
+
+// TestAlignHandlerSourceUnavailable tests that unconfigured sources are reported.
+func TestAlignHandlerSourceUnavailable(t *testing.T) {
+	// Arrange
+	srv := newAPITestServer(func(srv *FiberServer) {
+		srv.CryptoHistory = stubCryptoHistory
+	})
+
+	// Act
+	resp := doAPIRequest(t, srv, http.MethodGet, "/api/analytics/align?series=fred:WALCL", "", "")
+	defer resp.Body.Close()
+
+	// Assert
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, resp.StatusCode)
+	}
+}
+
+// TestReturnsHandler tests return statistics for the requested symbols.
+func TestReturnsHandler(t *testing.T) {
+	// Arrange
+	srv := newAPITestServer(withAnalyticsStubs)
+
+	// Act
+	resp := doAPIRequest(t, srv, http.MethodGet, "/api/analytics/returns?symbols=btcusdt", "", "")
+	defer resp.Body.Close()
+
+	// Assert
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var body struct {
+		RiskFree string          `json:"risk_free"`
+		Symbols  []SymbolReturns `json:"symbols"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.RiskFree != RiskFreeSeries {
+		t.Errorf("Expected risk-free series %s, got %s", RiskFreeSeries, body.RiskFree)
+	}
+	if len(body.Symbols) != 1 || body.Symbols[0].Symbol != "BTCUSDT" {
+		t.Fatalf("Expected BTCUSDT, got %+v", body.Symbols)
+	}
+	windows := body.Symbols[0].Windows
+	if len(windows) != 3 || windows[2].Days != 365 {
+		t.Fatalf("Expected 30/90/365-day windows, got %+v", windows)
+	}
+	for _, window := range windows {
+		if window.ReturnPercent == nil || *window.ReturnPercent <= 0 {
+			t.Errorf("%d days: expected a positive return, got %v", window.Days, window.ReturnPercent)
+		}
+	}
+}
+
+// TestReturnsHandlerDefaultsToTrackedSymbols tests that the active feed's symbols are used by default.
+func TestReturnsHandlerDefaultsToTrackedSymbols(t *testing.T) {
+	// Arrange
+	srv := newAPITestServer(func(srv *FiberServer) {
+		srv.CryptoHistory = stubCryptoHistory
+		srv.Feeds = ws.NewFeedSwitch(srv.Hub)
+		if err := srv.Feeds.Add(ws.NewIngestor(ws.HubSink(srv.Hub), ws.WithSymbols([]string{"BTCUSDT", "ETHUSDT"}))); err != nil {
+			t.Fatalf("Failed to add feed: %v", err)
+		}
+	})
+
+	// Act
+	resp := doAPIRequest(t, srv, http.MethodGet, "/api/analytics/returns", "", "")
+	defer resp.Body.Close()
+
+	// Assert
+	var body struct {
+		RiskFree string          `json:"risk_free"`
+		Symbols  []SymbolReturns `json:"symbols"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(body.Symbols) != 2 {
+		t.Errorf("Expected 2 tracked symbols, got %d", len(body.Symbols))
+	}
+	if body.RiskFree != "" {
+		t.Errorf("Expected no risk-free series without FRED, got %s", body.RiskFree)
+	}
+}
+
+// TestSeasonalityHandler tests monthly and weekday seasonality for a symbol.
+func TestSeasonalityHandler(t *testing.T) {
+	// Arrange
+	srv := newAPITestServer(withAnalyticsStubs)
+
+	// Act
+	resp := doAPIRequest(t, srv, http.MethodGet, "/api/analytics/seasonality?symbol=btcusdt&years=2", "", "")
+	defer resp.Body.Close()
+
+	// Assert
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var body struct {
+		Symbol string `json:"symbol"`
+		analytics.Seasonality
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.Symbol != "BTCUSDT" {
+		t.Errorf("Expected symbol BTCUSDT, got %s", body.Symbol)
+	}
+	if len(body.Monthly) != 12 || len(body.Weekday) != 7 {
+		t.Errorf("Expected 12 months and 7 weekdays, got %d and %d", len(body.Monthly), len(body.Weekday))
+	}
+	if body.Weekday[0].Samples == 0 {
+		t.Error("Expected Monday samples")
+	}
+}
+
+// TestSeasonalityHandlerValidation tests request validation.
+func TestSeasonalityHandlerValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"missing symbol", ""},
+		{"too many years", "?symbol=BTCUSDT&years=50"},
+		{"no years", "?symbol=BTCUSDT&years=0"},
+	}
+
+	srv := newAPITestServer(withAnalyticsStubs)
+	for _, tt := range tests {
+		resp := doAPIRequest(t, srv, http.MethodGet, "/api/analytics/seasonality"+tt.query, "", "")
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", tt.name, http.StatusBadRequest, resp.StatusCode)
+		}
+	}
+}
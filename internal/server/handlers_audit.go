@@ -0,0 +1,60 @@
+package server
+
+import (
+	"errors"
+
+	"macro-analyst/pkg/i18n"
+	"macro-analyst/pkg/ws"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AuditRequest is the body of POST /api/admin/audit.
+type AuditRequest struct {
+	// SampleEvery writes 1 in SampleEvery broadcasts to the audit file
+	// (default 100)
+	SampleEvery int `json:"sample_every"`
+}
+
+// AuditStatusHandler reports whether broadcasts are being sampled.
+func (s *FiberServer) AuditStatusHandler(c *fiber.Ctx) error {
+	return c.JSON(s.Hub.AuditStatus())
+}
+
+// EnableAuditHandler starts sampling broadcast payloads to the audit file,
+// for diagnosing reports of clients showing wrong prices.
+func (s *FiberServer) EnableAuditHandler(c *fiber.Ctx) error {
+	req := AuditRequest{SampleEvery: ws.DefaultAuditSampleEvery}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": translate(c, i18n.MsgInvalidBody),
+			})
+		}
+	}
+
+	if err := s.Hub.EnableAudit(req.SampleEvery); err != nil {
+		status := fiber.StatusInternalServerError
+		switch {
+		case errors.Is(err, ws.ErrInvalidSampleRate):
+			status = fiber.StatusBadRequest
+		case errors.Is(err, ws.ErrAuditUnavailable):
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(s.Hub.AuditStatus())
+}
+
+// DisableAuditHandler stops sampling broadcasts.
+func (s *FiberServer) DisableAuditHandler(c *fiber.Ctx) error {
+	if err := s.Hub.DisableAudit(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(s.Hub.AuditStatus())
+}
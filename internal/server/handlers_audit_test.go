@@ -0,0 +1,63 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"macro-analyst/internal/config"
+	"macro-analyst/pkg/ws"
+)
+
+// TestAuditHandlers tests that admins can toggle broadcast sampling at
+// runtime.
+func TestAuditHandlers(t *testing.T) {
+	hub := ws.NewHub(ws.WithAuditFile(filepath.Join(t.TempDir(), "audit.jsonl"), ws.DefaultAuditMaxBytes))
+	srv := New(hub, config.ServerConfig{AdminToken: "secret"})
+	srv.RegisterFiberRoutes()
+
+	steps := []struct {
+		method      string
+		body        string
+		expected    int
+		enabled     bool
+		sampleEvery int
+	}{
+		{http.MethodGet, "", http.StatusOK, false, 0},
+		{http.MethodPost, "", http.StatusOK, true, ws.DefaultAuditSampleEvery},
+		{http.MethodPost, `{"sample_every":5}`, http.StatusOK, true, 5},
+		{http.MethodPost, `{"sample_every":-1}`, http.StatusBadRequest, true, 5},
+		{http.MethodDelete, "", http.StatusOK, false, 0},
+	}
+
+	for _, step := range steps {
+		resp := doAdminRequest(t, srv, step.method, "/api/admin/audit", "secret", step.body)
+		if resp.StatusCode != step.expected {
+			t.Errorf("%s %s: expected status %d, got %d", step.method, step.body, step.expected, resp.StatusCode)
+		}
+		resp.Body.Close()
+
+		if status := hub.AuditStatus(); status.Enabled != step.enabled || status.SampleEvery != step.sampleEvery {
+			t.Errorf("%s %s: unexpected status %+v", step.method, step.body, status)
+		}
+	}
+}
+
+// TestEnableAuditWithoutFile tests that the audit cannot be enabled without
+// an audit file.
+func TestEnableAuditWithoutFile(t *testing.T) {
+	srv := New(ws.NewHub(), config.ServerConfig{AdminToken: "secret"})
+	srv.RegisterFiberRoutes()
+
+	resp := doAdminRequest(t, srv, http.MethodPost, "/api/admin/audit", "secret", "")
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("Expected status %d, got %d", http.StatusConflict, resp.StatusCode)
+	}
+	var body map[string]string
+	if json.NewDecoder(resp.Body).Decode(&body); body["error"] == "" {
+		t.Error("Expected an error message")
+	}
+}
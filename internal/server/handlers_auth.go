@@ -0,0 +1,68 @@
+package server
+
+import (
+	"strings"
+
+	"macro-analyst/internal/jwt"
+	"macro-analyst/pkg/i18n"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// TokenQueryParam carries the JWT of WebSocket clients that cannot set
+	// headers
+	TokenQueryParam = "token"
+
+	// TokenSubprotocolPrefix marks the Sec-WebSocket-Protocol entry
+	// carrying the JWT, e.g. "bearer.<jwt>". Browsers should offer it next
+	// to "json" or "ndjson", which the server selects.
+	TokenSubprotocolPrefix = "bearer."
+
+	// claimsLocal is the context key holding the verified JWT claims
+	claimsLocal = "jwtClaims"
+)
+
+// requireJWT rejects stream requests without a valid JWT when a signing
+// key is configured, and passes the verified claims on to the handler.
+func (s *FiberServer) requireJWT(c *fiber.Ctx) error {
+	if s.jwtVerifier == nil {
+		return c.Next()
+	}
+
+	claims, err := s.jwtVerifier.Verify(requestToken(c))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgValidTokenRequired),
+		})
+	}
+
+	c.Locals(claimsLocal, claims)
+	return c.Next()
+}
+
+// requestToken returns the JWT from the token query parameter, the
+// Sec-WebSocket-Protocol header or, for REST requests, the Authorization
+// header, or an empty string.
+func requestToken(c *fiber.Ctx) string {
+	if token := c.Query(TokenQueryParam); token != "" {
+		return token
+	}
+	if token, found := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer "); found {
+		return token
+	}
+	for _, protocol := range strings.Split(c.Get(fiber.HeaderSecWebSocketProtocol), ",") {
+		if token, found := strings.CutPrefix(strings.TrimSpace(protocol), TokenSubprotocolPrefix); found {
+			return token
+		}
+	}
+	return ""
+}
+
+// streamUser returns the subject of the token a stream authenticated with,
+// or an empty string for anonymous streams.
+func streamUser(c *websocket.Conn) string {
+	claims, _ := c.Locals(claimsLocal).(jwt.Claims)
+	return claims.Subject
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"macro-analyst/internal/config"
+	"macro-analyst/internal/jwt"
+	"macro-analyst/pkg/ws"
+)
+
+// TestPriceStreamRequiresJWT tests that price stream upgrades are rejected
+// without a valid token from the query or the subprotocol header.
+func TestPriceStreamRequiresJWT(t *testing.T) {
+	srv := New(ws.NewHub(), config.ServerConfig{JWTSigningKey: "stream-key", JWTIssuer: "auth.example"})
+	srv.RegisterFiberRoutes()
+
+	sign := func(key, issuer string) string {
+		token, _ := jwt.NewVerifier([]byte(key)).Sign(jwt.Claims{
+			Subject:   "user-1",
+			Issuer:    issuer,
+			ExpiresAt: time.Now().Add(time.Hour).Unix(),
+		})
+		return token
+	}
+	valid := sign("stream-key", "auth.example")
+
+	tests := []struct {
+		name     string
+		query    string
+		protocol string
+		expected int
+	}{
+		{"missing token", "", "", http.StatusUnauthorized},
+		{"wrong key", "?token=" + sign("other-key", "auth.example"), "", http.StatusUnauthorized},
+		{"wrong issuer", "?token=" + sign("stream-key", "other.example"), "", http.StatusUnauthorized},
+		{"not a token", "?token=guess", "", http.StatusUnauthorized},
+		// A valid token passes authentication and reaches the upgrade handler
+		{"query token", "?token=" + valid, "", http.StatusUpgradeRequired},
+		{"subprotocol token", "", "json, " + TokenSubprotocolPrefix + valid, http.StatusUpgradeRequired},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, "/ws/prices"+tt.query, nil)
+		if tt.protocol != "" {
+			req.Header.Set("Sec-WebSocket-Protocol", tt.protocol)
+		}
+
+		resp, err := srv.App.Test(req)
+		if err != nil {
+			t.Fatalf("%s: failed to execute request: %v", tt.name, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expected, resp.StatusCode)
+		}
+	}
+}
+
+// TestPriceStreamWithoutJWTKey tests that price streams stay open to
+// anonymous clients when no signing key is configured.
+func TestPriceStreamWithoutJWTKey(t *testing.T) {
+	srv := New(ws.NewHub())
+	srv.RegisterFiberRoutes()
+
+	req, _ := http.NewRequest(http.MethodGet, "/ws/prices", nil)
+	resp, err := srv.App.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to execute request: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUpgradeRequired {
+		t.Errorf("Expected status %d, got %d", http.StatusUpgradeRequired, resp.StatusCode)
+	}
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"macro-analyst/pkg/ws"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MessageTypes lists the types of the messages WebSocket clients may
+// receive, whichever stream they are sent on.
+var MessageTypes = []string{
+	"multi_update", "price_update", "trade_stats", "mark_price", "open_interest",
+	"candle", "orderbook",
+	"heartbeat", "attribution", "feed_status", "stream_status", "symbols_changed", "data_quality",
+	"bandwidth_capped", "reconnect_to", "macro_update", "macro_surprise",
+	"net_liquidity", "formula_update", "alert", "ack", "pong", "error",
+}
+
+// Capabilities describes what this deployment supports, so frontends and
+// SDKs can feature-detect instead of assuming.
+type Capabilities struct {
+	Modules   []Module             `json:"modules"`
+	Protocol  ProtocolCapabilities `json:"protocol"`
+	Streams   []string             `json:"streams"`
+	Messages  []string             `json:"messages"`
+	Commands  []string             `json:"commands"`
+	Topics    []string             `json:"topics"`
+	Encodings EncodingCapabilities `json:"encodings"`
+	Throttle  ThrottleCapabilities `json:"throttle"`
+
+	// Currencies prices may be converted to besides USDT
+	Currencies []string `json:"currencies"`
+}
+
+// ProtocolCapabilities are the WebSocket protocol version and the
+// subprotocols /ws/prices negotiates.
+type ProtocolCapabilities struct {
+	Version      int      `json:"version"`
+	Subprotocols []string `json:"subprotocols"`
+	JWTRequired  bool     `json:"jwtRequired"` // Whether price streams require a JWT
+}
+
+// EncodingCapabilities are the number formats and rounding price stream
+// clients may select.
+type EncodingCapabilities struct {
+	Numbers     []ws.NumberFormat `json:"numbers"`
+	MaxDecimals int               `json:"maxDecimals"`
+}
+
+// ThrottleCapabilities are the update rates clients may choose: with
+// set_throttle, with the reduced-rate interval parameter, and the rate
+// embed streams fall back to past their hourly byte cap.
+type ThrottleCapabilities struct {
+	MinIntervalMs         int64 `json:"minIntervalMs"`
+	MaxIntervalMs         int64 `json:"maxIntervalMs"`
+	MaxReducedRateSeconds int   `json:"maxReducedRateSeconds"`
+	EmbedHourlyByteCap    int64 `json:"embedHourlyByteCap,omitempty"`
+	EmbedCappedIntervalMs int64 `json:"embedCappedIntervalMs,omitempty"`
+}
+
+// CapabilitiesHandler returns the enabled modules, WebSocket streams,
+// message and command types, protocol version, encodings and throttle
+// limits of this deployment.
+func (s *FiberServer) CapabilitiesHandler(c *fiber.Ctx) error {
+	capabilities := Capabilities{
+		Modules: s.ActiveModules(),
+		Protocol: ProtocolCapabilities{
+			Version:      ws.ProtocolVersion,
+			Subprotocols: ws.Subprotocols,
+			JWTRequired:  s.jwtVerifier != nil,
+		},
+		Streams:  s.streams(),
+		Messages: MessageTypes,
+		Commands: ws.Commands,
+		Topics: []string{
+			ws.TopicPrices, ws.TopicTrades, ws.TopicFutures, ws.TopicMacro, ws.TopicFormulas,
+			ws.SymbolTopic(ws.TopicCandles, "<symbol>"), ws.SymbolTopic(ws.TopicOrderBook, "<symbol>"),
+		},
+		Encodings: EncodingCapabilities{
+			Numbers:     ws.NumberFormats,
+			MaxDecimals: MaxStreamDecimals,
+		},
+		Throttle: ThrottleCapabilities{
+			MinIntervalMs:         ws.MinThrottleInterval.Milliseconds(),
+			MaxIntervalMs:         ws.MaxThrottleInterval.Milliseconds(),
+			MaxReducedRateSeconds: MaxStreamIntervalSeconds,
+		},
+	}
+	capabilities.Currencies = []string{}
+	if s.FXRates != nil {
+		capabilities.Currencies = s.FXRates.Currencies()
+	}
+	if s.Embeds != nil && s.EmbedByteCap > 0 {
+		capabilities.Throttle.EmbedHourlyByteCap = s.EmbedByteCap
+		capabilities.Throttle.EmbedCappedIntervalMs = ws.DefaultCappedRefreshInterval.Milliseconds()
+	}
+
+	return c.JSON(capabilities)
+}
+
+// streams returns the WebSocket routes registered by setupWebSocketRoutes.
+func (s *FiberServer) streams() []string {
+	streams := []string{}
+	if !s.ModuleEnabled(ModuleCrypto) {
+		return streams
+	}
+
+	streams = append(streams, "/ws/prices")
+	if s.RawProxy != nil && len(s.apiKeys) > 0 {
+		streams = append(streams, "/ws/raw/:stream")
+	}
+	if s.Embeds != nil {
+		streams = append(streams, "/ws/embed/:id")
+	}
+	if s.Shares != nil && s.Dashboards != nil {
+		streams = append(streams, "/ws/shared/:token")
+	}
+	if s.CandleHub != nil {
+		streams = append(streams, "/ws/candles")
+	}
+	if s.OrderBookHub != nil {
+		streams = append(streams, "/ws/orderbook")
+	}
+	return streams
+}
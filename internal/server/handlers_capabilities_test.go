@@ -0,0 +1,80 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"macro-analyst/internal/config"
+	"macro-analyst/pkg/ws"
+)
+
+// TestCapabilitiesHandler tests that the capabilities reflect the modules
+// and streams of the deployment.
+func TestCapabilitiesHandler(t *testing.T) {
+	tests := []struct {
+		name            string
+		config          config.ServerConfig
+		candles         bool
+		expectedModules []Module
+		expectedStreams []string
+		expectJWT       bool
+	}{
+		{
+			name:            "crypto with candles",
+			config:          config.ServerConfig{Modules: []string{"crypto", "analytics"}, JWTSigningKey: "key"},
+			candles:         true,
+			expectedModules: []Module{ModuleCrypto, ModuleAnalytics},
+			expectedStreams: []string{"/ws/prices", "/ws/candles"},
+			expectJWT:       true,
+		},
+		{
+			name:            "macro API only",
+			config:          config.ServerConfig{Modules: []string{"fred", "admin"}, AdminToken: "secret"},
+			expectedModules: []Module{ModuleAdmin},
+			expectedStreams: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(ws.NewHub(), tt.config)
+			if tt.candles {
+				srv.CandleHub = ws.NewHub()
+			}
+			srv.RegisterFiberRoutes()
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/capabilities", nil)
+			resp, err := srv.App.Test(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+			}
+			var capabilities Capabilities
+			if err := json.NewDecoder(resp.Body).Decode(&capabilities); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+
+			if !slices.Equal(capabilities.Modules, tt.expectedModules) {
+				t.Errorf("Expected modules %v, got %v", tt.expectedModules, capabilities.Modules)
+			}
+			if !slices.Equal(capabilities.Streams, tt.expectedStreams) {
+				t.Errorf("Expected streams %v, got %v", tt.expectedStreams, capabilities.Streams)
+			}
+			if capabilities.Protocol.Version != ws.ProtocolVersion || capabilities.Protocol.JWTRequired != tt.expectJWT {
+				t.Errorf("Unexpected protocol %+v", capabilities.Protocol)
+			}
+			if !slices.Contains(capabilities.Commands, ws.CommandSubscribeTopics) || !slices.Contains(capabilities.Messages, "stream_status") {
+				t.Errorf("Expected every command and message type, got %v and %v", capabilities.Commands, capabilities.Messages)
+			}
+			if capabilities.Throttle.MinIntervalMs != 250 || capabilities.Throttle.MaxReducedRateSeconds != MaxStreamIntervalSeconds {
+				t.Errorf("Unexpected throttle limits %+v", capabilities.Throttle)
+			}
+		})
+	}
+}
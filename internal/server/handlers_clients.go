@@ -0,0 +1,61 @@
+package server
+
+import (
+	"sort"
+	"strconv"
+
+	"macro-analyst/pkg/ws"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// hubs returns the hubs clients connect to: prices, and candles and order
+// books when enabled.
+func (s *FiberServer) hubs() []*ws.Hub {
+	hubs := []*ws.Hub{s.Hub}
+	for _, hub := range []*ws.Hub{s.CandleHub, s.OrderBookHub} {
+		if hub != nil {
+			hubs = append(hubs, hub)
+		}
+	}
+	return hubs
+}
+
+// ListClientsHandler lists the active WebSocket connections of every
+// stream, oldest first, for operational debugging.
+func (s *FiberServer) ListClientsHandler(c *fiber.Ctx) error {
+	clients := []ws.ClientInfo{}
+	for _, hub := range s.hubs() {
+		clients = append(clients, hub.Clients()...)
+	}
+	sort.Slice(clients, func(a, b int) bool {
+		return clients[a].ID < clients[b].ID
+	})
+
+	return c.JSON(fiber.Map{
+		"count":   len(clients),
+		"clients": clients,
+	})
+}
+
+// DisconnectClientHandler force-disconnects the client with the ID of the
+// listing.
+func (s *FiberServer) DisconnectClientHandler(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid client id",
+		})
+	}
+
+	for _, hub := range s.hubs() {
+		if hub.Disconnect(id) {
+			return c.JSON(fiber.Map{
+				"disconnected": id,
+			})
+		}
+	}
+	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+		"error": "client not found",
+	})
+}
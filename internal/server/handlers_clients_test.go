@@ -0,0 +1,70 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"macro-analyst/internal/config"
+	"macro-analyst/pkg/ws"
+	"macro-analyst/pkg/ws/testutil"
+)
+
+// TestClientsHandlers verifies the admin API lists the live connections
+// with their metadata and force-disconnects one.
+func TestClientsHandlers(t *testing.T) {
+	hub := ws.NewHub()
+	go hub.Run()
+	cfg := config.ServerConfig{}
+	cfg.AdminToken = "secret"
+	srv := New(hub, cfg)
+	srv.RegisterFiberRoutes()
+	server := testutil.NewServer(t, srv.App)
+
+	conn, _, err := server.DialErr("/ws/prices", http.Header{"User-Agent": {"dashboard/1.0"}})
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	testutil.WaitForClients(t, hub, 1)
+
+	resp := doAdminRequest(t, srv, http.MethodGet, "/api/admin/clients", "secret", "")
+	defer resp.Body.Close()
+	var body struct {
+		Count   int             `json:"count"`
+		Clients []ws.ClientInfo `json:"clients"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.Count != 1 || len(body.Clients) != 1 {
+		t.Fatalf("Expected one client, got %+v", body)
+	}
+	client := body.Clients[0]
+	if client.Stream != "prices" || client.UserAgent != "dashboard/1.0" || client.RemoteIP != "127.0.0.1" || client.ConnectedAt.IsZero() {
+		t.Errorf("Unexpected client %+v", client)
+	}
+
+	tests := []struct {
+		id       string
+		expected int
+	}{
+		{"abc", http.StatusBadRequest},
+		{strconv.FormatUint(client.ID+1, 10), http.StatusNotFound},
+		{strconv.FormatUint(client.ID, 10), http.StatusOK},
+	}
+	for _, tt := range tests {
+		resp := doAdminRequest(t, srv, http.MethodDelete, "/api/admin/clients/"+tt.id, "secret", "")
+		resp.Body.Close()
+		if resp.StatusCode != tt.expected {
+			t.Errorf("DELETE client %s: expected status %d, got %d", tt.id, tt.expected, resp.StatusCode)
+		}
+	}
+
+	testutil.WaitForClients(t, hub, 0)
+	conn.SetReadDeadline(time.Now().Add(testutil.DefaultTimeout))
+	if _, _, err := conn.ReadMessage(); err == nil {
+		t.Error("Expected the connection closed")
+	}
+}
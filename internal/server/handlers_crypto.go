@@ -0,0 +1,252 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"macro-analyst/internal/klines"
+	"macro-analyst/pkg/i18n"
+	"macro-analyst/pkg/ws"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CryptoStatsHandler returns per-symbol ingestion and broadcast counters of
+// the feed serving clients, for debugging why a pair appears frozen.
+func (s *FiberServer) CryptoStatsHandler(c *fiber.Ctx) error {
+	ingestor := s.Feeds.Active()
+	if ingestor == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgNoActiveFeed),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"source":    ingestor.Name(),
+		"symbols":   ingestor.Stats(),
+		"timestamp": time.Now(),
+	})
+}
+
+// PricesHandler returns the cached last-known prices of the feed serving
+// clients, for consumers that poll instead of opening a WebSocket. Symbols
+// without a price yet are omitted.
+//
+// Query parameters: currency to convert the prices to, e.g. EUR.
+func (s *FiberServer) PricesHandler(c *fiber.Ctx) error {
+	ingestor := s.Feeds.Active()
+	if ingestor == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgNoActiveFeed),
+		})
+	}
+
+	currency := strings.ToUpper(c.Query("currency"))
+	if currency == "" {
+		return c.JSON(fiber.Map{
+			"source":    ingestor.Name(),
+			"prices":    ingestor.Prices(),
+			"timestamp": time.Now(),
+		})
+	}
+
+	if err := s.checkCurrency(currency); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	prices, err := s.FXRates.Convert(ingestor.Prices(), currency)
+	if err != nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	rate, _ := s.FXRates.Rate(currency)
+	return c.JSON(fiber.Map{
+		"source":    ingestor.Name(),
+		"prices":    prices,
+		"currency":  currency,
+		"rate":      rate,
+		"timestamp": time.Now(),
+	})
+}
+
+// checkCurrency returns an error unless prices may be converted to
+// currency.
+func (s *FiberServer) checkCurrency(currency string) error {
+	if s.FXRates == nil {
+		return errors.New("currency conversion is not enabled")
+	}
+	return s.FXRates.Supports(currency)
+}
+
+// AvailableSymbolsHandler returns the symbols trading on Binance, which
+// may be added to the feeds and subscribed to, from the catalog of the feed
+// serving clients.
+//
+// Query parameters: quote to list only the symbols quoted in an asset,
+// e.g. USDT.
+func (s *FiberServer) AvailableSymbolsHandler(c *fiber.Ctx) error {
+	ingestor := s.Feeds.Active()
+	if ingestor == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgNoActiveFeed),
+		})
+	}
+
+	symbols, err := ingestor.AvailableSymbols(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if quote := strings.ToUpper(c.Query("quote")); quote != "" {
+		quoted := []string{}
+		for _, symbol := range symbols {
+			if len(symbol) > len(quote) && strings.HasSuffix(symbol, quote) {
+				quoted = append(quoted, symbol)
+			}
+		}
+		symbols = quoted
+	}
+
+	return c.JSON(fiber.Map{
+		"count":   len(symbols),
+		"symbols": symbols,
+	})
+}
+
+// CryptoRecentHandler returns the recent prices of a symbol from the
+// in-memory history of the feed serving clients, for sparklines that work
+// without a persistent store.
+//
+// Query parameters: minutes to look back (default and maximum: the whole
+// history window).
+func (s *FiberServer) CryptoRecentHandler(c *fiber.Ctx) error {
+	ingestor := s.Feeds.Active()
+	if ingestor == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgNoActiveFeed),
+		})
+	}
+
+	minutes := c.QueryInt("minutes", 0)
+	if minutes < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("minutes must not be negative, got %d", minutes),
+		})
+	}
+
+	symbol := ingestor.ResolveSymbol(c.Params("symbol"))
+	points, err := ingestor.Recent(symbol, time.Duration(minutes)*time.Minute)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, ws.ErrSymbolNotFound) {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"symbol":        symbol,
+		"source":        ingestor.Name(),
+		"resolution_ms": ingestor.RecentResolution().Milliseconds(),
+		"points":        points,
+	})
+}
+
+// MoversHandler returns the top gainers, losers and volume leaders among
+// all Binance USDT pairs, tracked or not, so users can discover symbols to
+// add. tracked lists the symbols the active feed already streams.
+//
+// Query parameters: limit per list (default 10, at most 100) and
+// min_quote_volume, the 24h USDT volume gainers and losers need (default
+// 1000000).
+func (s *FiberServer) MoversHandler(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", ws.DefaultMoversLimit)
+	if limit < 1 || limit > ws.MaxMoversLimit {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("limit must be 1 to %d, got %d", ws.MaxMoversLimit, limit),
+		})
+	}
+
+	minQuoteVolume := float64(ws.DefaultMinQuoteVolume)
+	if value := c.Query("min_quote_volume"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("min_quote_volume must be a non-negative number, got %q", value),
+			})
+		}
+		minQuoteVolume = parsed
+	}
+
+	movers, err := s.Movers.Top(limit, minQuoteVolume)
+	if err != nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	tracked := []string{}
+	if active := s.Feeds.Active(); active != nil {
+		tracked = active.GetSymbols()
+	}
+
+	return c.JSON(struct {
+		ws.Movers
+		Tracked []string `json:"tracked"`
+	}{movers, tracked})
+}
+
+// CandlesHandler returns historical candles of a symbol from the Binance
+// REST API, in the /ws/candles format, so charts can be backfilled before
+// the live stream takes over.
+//
+// Query parameters: symbol (required), interval (default 1m) and limit
+// (default 500, at most 1000).
+func (s *FiberServer) CandlesHandler(c *fiber.Ctx) error {
+	symbol := s.resolveSymbol(c.Query("symbol"))
+	if !ws.ValidSymbol(symbol) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("invalid symbol %q", c.Query("symbol")),
+		})
+	}
+
+	interval := c.Query("interval", "1m")
+	candles, err := s.Klines.Get(c.UserContext(), symbol, interval, c.QueryInt("limit", klines.DefaultLimit))
+	if err != nil {
+		status := fiber.StatusServiceUnavailable
+		switch {
+		case errors.Is(err, klines.ErrInvalidInterval), errors.Is(err, klines.ErrInvalidLimit):
+			status = fiber.StatusBadRequest
+		case errors.Is(err, ws.ErrUnknownSymbol):
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"symbol":   symbol,
+		"interval": interval,
+		"candles":  candles,
+	})
+}
+
+// resolveSymbol returns the symbol for a symbol or an alias such as "btc" or
+// "BTC-USD", preferring the symbols tracked by the active feed.
+func (s *FiberServer) resolveSymbol(name string) string {
+	if s.Feeds != nil {
+		if active := s.Feeds.Active(); active != nil {
+			return active.ResolveSymbol(name)
+		}
+	}
+	return ws.NormalizeSymbol(name)
+}
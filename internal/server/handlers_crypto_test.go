@@ -0,0 +1,372 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"macro-analyst/internal/klines"
+	"macro-analyst/pkg/ws"
+)
+
+// TestCryptoStatsHandler tests the per-symbol statistics endpoint.
+func TestCryptoStatsHandler(t *testing.T) {
+	hub := ws.NewHub()
+	srv := New(hub)
+	srv.Feeds = ws.NewFeedSwitch(hub)
+	srv.Feeds.Add(ws.NewIngestor(ws.HubSink(hub), ws.WithSymbols([]string{"BTCUSDT", "ETHUSDT"})))
+	srv.RegisterFiberRoutes()
+
+	req, err := http.NewRequest(http.MethodGet, "/api/crypto/stats", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	resp, err := srv.App.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to execute request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var body struct {
+		Source  string           `json:"source"`
+		Symbols []ws.SymbolStats `json:"symbols"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if body.Source != ws.DefaultSourceName {
+		t.Errorf("Expected source %q, got %q", ws.DefaultSourceName, body.Source)
+	}
+
+	if len(body.Symbols) != 2 || body.Symbols[0].Symbol != "BTCUSDT" {
+		t.Errorf("Unexpected symbol stats: %+v", body.Symbols)
+	}
+}
+
+// TestCryptoStatsHandlerNoFeed tests the endpoint without any running feed.
+func TestCryptoStatsHandlerNoFeed(t *testing.T) {
+	hub := ws.NewHub()
+	srv := New(hub)
+	srv.Feeds = ws.NewFeedSwitch(hub)
+	srv.RegisterFiberRoutes()
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/crypto/stats", nil)
+	resp, err := srv.App.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to execute request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, resp.StatusCode)
+	}
+}
+
+// TestCryptoRecentHandler tests the recent price history endpoint.
+func TestCryptoRecentHandler(t *testing.T) {
+	hub := ws.NewHub()
+	srv := New(hub)
+	srv.Feeds = ws.NewFeedSwitch(hub)
+	srv.Feeds.Add(ws.NewIngestor(ws.HubSink(hub), ws.WithSymbols([]string{"BTCUSDT"})))
+	srv.RegisterFiberRoutes()
+
+	tests := []struct {
+		name     string
+		path     string
+		expected int
+	}{
+		{"tracked symbol", "/api/crypto/btcusdt/recent", http.StatusOK},
+		{"with minutes", "/api/crypto/BTCUSDT/recent?minutes=5", http.StatusOK},
+		{"asset alias", "/api/crypto/btc/recent", http.StatusOK},
+		{"pair alias", "/api/crypto/BTC-USD/recent", http.StatusOK},
+		{"negative minutes", "/api/crypto/BTCUSDT/recent?minutes=-1", http.StatusBadRequest},
+		{"untracked symbol", "/api/crypto/DOGEUSDT/recent", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+		resp, err := srv.App.Test(req)
+		if err != nil {
+			t.Fatalf("%s: failed to execute request: %v", tt.name, err)
+		}
+
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expected, resp.StatusCode)
+		}
+		if resp.StatusCode == http.StatusOK {
+			var body struct {
+				Symbol       string           `json:"symbol"`
+				ResolutionMs int64            `json:"resolution_ms"`
+				Points       []ws.RecentPrice `json:"points"`
+			}
+			json.NewDecoder(resp.Body).Decode(&body)
+			if body.Symbol != "BTCUSDT" || body.ResolutionMs != 1000 || body.Points == nil {
+				t.Errorf("%s: unexpected response %+v", tt.name, body)
+			}
+		}
+		resp.Body.Close()
+	}
+}
+
+// TestPricesHandler tests the cached prices endpoint.
+func TestPricesHandler(t *testing.T) {
+	// Arrange: restore a cached price from a snapshot
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	snapshot := fmt.Sprintf(`{"savedAt":%q,"symbols":[{"name":"BTCUSDT","lastPrice":"50000.00","lastChange":"0.20","lastPriceChange":"100.00","lastVolume":"1000"}]}`,
+		time.Now().Format(time.RFC3339))
+	if err := os.WriteFile(path, []byte(snapshot), 0o600); err != nil {
+		t.Fatalf("Failed to write snapshot: %v", err)
+	}
+
+	hub := ws.NewHub()
+	ingestor := ws.NewIngestor(ws.HubSink(hub), ws.WithSymbols([]string{"BTCUSDT", "ETHUSDT"}), ws.WithSnapshotFile(path))
+	if err := ingestor.RestoreSnapshot(); err != nil {
+		t.Fatalf("Failed to restore snapshot: %v", err)
+	}
+
+	srv := New(hub)
+	srv.Feeds = ws.NewFeedSwitch(hub)
+	srv.Feeds.Add(ingestor)
+	srv.RegisterFiberRoutes()
+
+	// Act
+	req, _ := http.NewRequest(http.MethodGet, "/api/prices", nil)
+	resp, err := srv.App.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to execute request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Assert
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var body struct {
+		Source string            `json:"source"`
+		Prices []*ws.PriceUpdate `json:"prices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.Source != ws.DefaultSourceName || len(body.Prices) != 1 {
+		t.Fatalf("Expected the BTCUSDT price only, got %+v", body)
+	}
+	if price := body.Prices[0]; price.Symbol != "BTCUSDT" || price.Price != 50000 || !price.Restored {
+		t.Errorf("Unexpected price %+v", price)
+	}
+}
+
+// TestMoversHandler tests the top movers endpoint.
+func TestMoversHandler(t *testing.T) {
+	hub := ws.NewHub()
+	srv := New(hub)
+	srv.Feeds = ws.NewFeedSwitch(hub)
+	srv.Feeds.Add(ws.NewIngestor(ws.HubSink(hub), ws.WithSymbols([]string{"BTCUSDT"})))
+	srv.Movers = ws.NewMoverTracker(func(ctx context.Context) ([]ws.TickerStats, error) {
+		return []ws.TickerStats{
+			{Symbol: "BTCUSDT", ChangePercent: 2, QuoteVolume: 900_000_000, Trades: 100},
+			{Symbol: "PEPEUSDT", ChangePercent: 25, QuoteVolume: 80_000_000, Trades: 100},
+			{Symbol: "ETHUSDT", ChangePercent: -3, QuoteVolume: 400_000_000, Trades: 100},
+		}, nil
+	})
+	srv.RegisterFiberRoutes()
+
+	get := func(query string) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, "/api/crypto/movers"+query, nil)
+		resp, err := srv.App.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to execute request: %v", err)
+		}
+		return resp
+	}
+
+	resp := get("")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 before the first poll, got %d", resp.StatusCode)
+	}
+
+	srv.Movers.Refresh(context.Background())
+	resp = get("?limit=1")
+	var body struct {
+		Gainers []ws.TickerStats `json:"gainers"`
+		Losers  []ws.TickerStats `json:"losers"`
+		Volume  []ws.TickerStats `json:"volume"`
+		Tracked []string         `json:"tracked"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if len(body.Gainers) != 1 || body.Gainers[0].Symbol != "PEPEUSDT" || body.Losers[0].Symbol != "ETHUSDT" ||
+		body.Volume[0].Symbol != "BTCUSDT" || len(body.Tracked) != 1 || body.Tracked[0] != "BTCUSDT" {
+		t.Errorf("Unexpected movers: %+v", body)
+	}
+
+	for _, query := range []string{"?limit=0", "?limit=101", "?min_quote_volume=-1", "?min_quote_volume=lots"} {
+		resp := get(query)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, resp.StatusCode)
+		}
+	}
+}
+
+// TestCandlesHandler tests serving historical candles for chart backfills.
+func TestCandlesHandler(t *testing.T) {
+	hub := ws.NewHub()
+	srv := New(hub)
+	srv.Feeds = ws.NewFeedSwitch(hub)
+	srv.Feeds.Add(ws.NewIngestor(ws.HubSink(hub), ws.WithSymbols([]string{"BTCUSDT"})))
+	srv.Klines = klines.New(func(ctx context.Context, symbol, interval string, limit int) ([]ws.CandleUpdate, error) {
+		switch symbol {
+		case "BTCUSDT":
+			return []ws.CandleUpdate{{Type: "candle", Symbol: symbol, Interval: interval, Close: 50000, Closed: true}}, nil
+		case "DOGEUSDT":
+			return nil, errors.New("connection reset")
+		}
+		return nil, ws.ErrUnknownSymbol
+	})
+	srv.RegisterFiberRoutes()
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/candles?symbol=btc&interval=5m", nil)
+	resp, err := srv.App.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to execute request: %v", err)
+	}
+	var body struct {
+		Symbol   string            `json:"symbol"`
+		Interval string            `json:"interval"`
+		Candles  []ws.CandleUpdate `json:"candles"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if body.Symbol != "BTCUSDT" || body.Interval != "5m" || len(body.Candles) != 1 || body.Candles[0].Close != 50000 {
+		t.Errorf("Unexpected candles: %+v", body)
+	}
+
+	tests := []struct {
+		query    string
+		expected int
+	}{
+		{"", http.StatusBadRequest},
+		{"?symbol=BTCUSDT&interval=7m", http.StatusBadRequest},
+		{"?symbol=BTCUSDT&limit=1001", http.StatusBadRequest},
+		{"?symbol=NOPEUSDT", http.StatusNotFound},
+		{"?symbol=DOGEUSDT", http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, "/api/candles"+tt.query, nil)
+		resp, err := srv.App.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to execute request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%q: expected status %d, got %d", tt.query, tt.expected, resp.StatusCode)
+		}
+	}
+}
+
+// TestPricesHandlerCurrency tests the currency parameter of the prices
+// endpoint before any exchange rate arrived.
+func TestPricesHandlerCurrency(t *testing.T) {
+	hub := ws.NewHub()
+	srv := New(hub)
+	srv.Feeds = ws.NewFeedSwitch(hub)
+	srv.Feeds.Add(ws.NewIngestor(ws.HubSink(hub), ws.WithSymbols([]string{"BTCUSDT"})))
+	srv.RegisterFiberRoutes()
+
+	request := func(query string) int {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, "/api/prices?"+query, nil)
+		resp, err := srv.App.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to execute request: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if status := request("currency=EUR"); status != http.StatusBadRequest {
+		t.Errorf("Expected status %d without conversion, got %d", http.StatusBadRequest, status)
+	}
+
+	srv.FXRates, _ = ws.NewFXRates([]string{"EURUSDT"})
+	tests := []struct {
+		query    string
+		expected int
+	}{
+		{"", http.StatusOK},
+		{"currency=GBP", http.StatusBadRequest},
+		{"currency=eur", http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		if status := request(tt.query); status != tt.expected {
+			t.Errorf("GET /api/prices?%s: expected status %d, got %d", tt.query, tt.expected, status)
+		}
+	}
+}
+
+// TestAvailableSymbolsHandler tests listing the symbols trading on Binance.
+func TestAvailableSymbolsHandler(t *testing.T) {
+	hub := ws.NewHub()
+	srv := New(hub)
+	srv.Feeds = ws.NewFeedSwitch(hub)
+	srv.RegisterFiberRoutes()
+
+	request := func(query string) (int, []string) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, "/api/symbols/available?"+query, nil)
+		resp, err := srv.App.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to execute request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var body struct {
+			Symbols []string `json:"symbols"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body.Symbols
+	}
+
+	if status, _ := request(""); status != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d without a feed, got %d", http.StatusServiceUnavailable, status)
+	}
+
+	srv.Feeds.Add(ws.NewIngestor(ws.HubSink(hub), ws.WithSymbolLister(func(ctx context.Context) ([]string, error) {
+		return []string{"ETHUSDT", "BTCUSDT", "ETHBTC"}, nil
+	})))
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"", []string{"BTCUSDT", "ETHBTC", "ETHUSDT"}},
+		{"quote=usdt", []string{"BTCUSDT", "ETHUSDT"}},
+		{"quote=EUR", []string{}},
+	}
+	for _, tt := range tests {
+		status, symbols := request(tt.query)
+		if status != http.StatusOK || fmt.Sprint(symbols) != fmt.Sprint(tt.expected) {
+			t.Errorf("GET /api/symbols/available?%s: expected 200 and %v, got %d and %v", tt.query, tt.expected, status, symbols)
+		}
+	}
+}
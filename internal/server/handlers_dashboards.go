@@ -0,0 +1,274 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"macro-analyst/internal/dashboard"
+	"macro-analyst/internal/softdelete"
+	"macro-analyst/pkg/i18n"
+	"macro-analyst/pkg/usage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MaxDashboardsPerKey limits how many dashboards one API key may save.
+const MaxDashboardsPerKey = 50
+
+var (
+	// errNotDashboardOwner is returned when modifying another key's dashboard
+	errNotDashboardOwner = errors.New("dashboard belongs to another API key")
+
+	// errTemplateReadOnly is returned when modifying a built-in dashboard
+	errTemplateReadOnly = errors.New("built-in dashboards are read-only, save a variant instead")
+
+	// errUnknownTemplate is returned when saving a variant of a missing template
+	errUnknownTemplate = errors.New("unknown template")
+)
+
+// DashboardRequest is the body of POST /api/dashboards and
+// PUT /api/dashboards/:id. Creating a dashboard with based_on and without
+// widgets copies the widgets of that template.
+type DashboardRequest struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	BasedOn     string             `json:"based_on"`
+	Widgets     []dashboard.Widget `json:"widgets"`
+	State       json.RawMessage    `json:"state"`
+}
+
+// ListDashboardsHandler returns the built-in templates and, for requests
+// with an API key, the dashboards saved with that key.
+func (s *FiberServer) ListDashboardsHandler(c *fiber.Ctx) error {
+	saved := []dashboard.Dashboard{}
+	if key := apiKey(c); key != "" {
+		owner := usage.KeyID(key)
+		for _, d := range s.Dashboards.List() {
+			if d.Owner == owner {
+				saved = append(saved, d)
+			}
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"templates":  dashboard.Templates(),
+		"dashboards": saved,
+	})
+}
+
+// GetDashboardHandler returns a template or one of the caller's dashboards.
+// Dashboards saved with another key are reported as not found.
+func (s *FiberServer) GetDashboardHandler(c *fiber.Ctx) error {
+	if template, exists := dashboard.FindTemplate(c.Params("id")); exists {
+		return c.JSON(template)
+	}
+
+	d, err := s.ownedDashboard(c)
+	if errors.Is(err, errNotDashboardOwner) {
+		err = softdelete.ErrNotFound
+	}
+	if err != nil {
+		return c.Status(dashboardErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(d)
+}
+
+// CreateDashboardHandler saves a new dashboard owned by the caller's API key.
+func (s *FiberServer) CreateDashboardHandler(c *fiber.Ctx) error {
+	var req DashboardRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgInvalidBody),
+		})
+	}
+
+	owner := usage.KeyID(apiKey(c))
+	owned := 0
+	for _, d := range s.Dashboards.List() {
+		if d.Owner == owner {
+			owned++
+		}
+	}
+	if owned >= MaxDashboardsPerKey {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": fmt.Sprintf("at most %d dashboards per API key", MaxDashboardsPerKey),
+		})
+	}
+
+	d, err := newDashboard(req)
+	if err != nil {
+		return c.Status(dashboardErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	d.ID = dashboard.NewID()
+	d.Owner = owner
+	d.CreatedAt = time.Now().UTC()
+	d.UpdatedAt = d.CreatedAt
+	s.Dashboards.Put(d.ID, d)
+
+	return c.Status(fiber.StatusCreated).JSON(d)
+}
+
+// UpdateDashboardHandler replaces the name, widgets and state of a dashboard.
+func (s *FiberServer) UpdateDashboardHandler(c *fiber.Ctx) error {
+	existing, err := s.ownedDashboard(c)
+	if err != nil {
+		return c.Status(dashboardErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var req DashboardRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgInvalidBody),
+		})
+	}
+
+	// The template a dashboard was saved from does not change
+	req.BasedOn = existing.BasedOn
+	if req.Widgets == nil {
+		req.Widgets = []dashboard.Widget{}
+	}
+	d, err := newDashboard(req)
+	if err != nil {
+		return c.Status(dashboardErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	d.ID = existing.ID
+	d.Owner = existing.Owner
+	d.CreatedAt = existing.CreatedAt
+	d.UpdatedAt = time.Now().UTC()
+	s.Dashboards.Put(d.ID, d)
+
+	return c.JSON(d)
+}
+
+// DeleteDashboardHandler moves a dashboard to the trash, from which it can
+// be restored until it is purged.
+func (s *FiberServer) DeleteDashboardHandler(c *fiber.Ctx) error {
+	d, err := s.ownedDashboard(c)
+	if err == nil {
+		err = s.Dashboards.Delete(d.ID)
+	}
+	if err != nil {
+		return c.Status(dashboardErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"removed": d.ID,
+	})
+}
+
+// DashboardTrashHandler returns the caller's deleted dashboards.
+func (s *FiberServer) DashboardTrashHandler(c *fiber.Ctx) error {
+	owner := usage.KeyID(apiKey(c))
+	trash := []softdelete.Deleted[dashboard.Dashboard]{}
+	for _, deleted := range s.Dashboards.Trash() {
+		if deleted.Value.Owner == owner {
+			trash = append(trash, deleted)
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"trash": trash,
+	})
+}
+
+// RestoreDashboardHandler moves one of the caller's dashboards out of the trash.
+func (s *FiberServer) RestoreDashboardHandler(c *fiber.Ctx) error {
+	id := c.Params("id")
+	owner := usage.KeyID(apiKey(c))
+
+	var err error = softdelete.ErrNotInTrash
+	for _, deleted := range s.Dashboards.Trash() {
+		if deleted.ID == id {
+			err = nil
+			if deleted.Value.Owner != owner {
+				err = errNotDashboardOwner
+			}
+		}
+	}
+	var d dashboard.Dashboard
+	if err == nil {
+		d, err = s.Dashboards.Restore(id)
+	}
+	if err != nil {
+		return c.Status(dashboardErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(d)
+}
+
+// newDashboard builds and validates a dashboard from a request, copying the
+// widgets of its template if the request has none.
+func newDashboard(req DashboardRequest) (dashboard.Dashboard, error) {
+	d := dashboard.Dashboard{
+		Name:        req.Name,
+		Description: req.Description,
+		BasedOn:     req.BasedOn,
+		Widgets:     req.Widgets,
+		State:       req.State,
+	}
+
+	if d.BasedOn != "" {
+		template, exists := dashboard.FindTemplate(d.BasedOn)
+		if !exists {
+			return d, fmt.Errorf("%w %q", errUnknownTemplate, d.BasedOn)
+		}
+		if d.Widgets == nil {
+			d.Widgets = template.Widgets
+		}
+		if d.Description == "" {
+			d.Description = template.Description
+		}
+	}
+	if d.Widgets == nil {
+		d.Widgets = []dashboard.Widget{}
+	}
+
+	return d, d.Validate()
+}
+
+// ownedDashboard returns the saved dashboard named in the path if it
+// belongs to the caller's API key.
+func (s *FiberServer) ownedDashboard(c *fiber.Ctx) (dashboard.Dashboard, error) {
+	id := c.Params("id")
+	if _, exists := dashboard.FindTemplate(id); exists {
+		return dashboard.Dashboard{}, errTemplateReadOnly
+	}
+
+	d, exists := s.Dashboards.Get(id)
+	if !exists {
+		return d, softdelete.ErrNotFound
+	}
+	if d.Owner != usage.KeyID(apiKey(c)) {
+		return d, errNotDashboardOwner
+	}
+	return d, nil
+}
+
+// dashboardErrorStatus maps dashboard errors to HTTP status codes.
+func dashboardErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dashboard.ErrInvalidDashboard), errors.Is(err, errUnknownTemplate):
+		return fiber.StatusBadRequest
+	case errors.Is(err, softdelete.ErrNotFound), errors.Is(err, softdelete.ErrNotInTrash):
+		return fiber.StatusNotFound
+	case errors.Is(err, errNotDashboardOwner), errors.Is(err, errTemplateReadOnly):
+		return fiber.StatusForbidden
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
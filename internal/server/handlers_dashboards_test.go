@@ -0,0 +1,149 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"macro-analyst/internal/dashboard"
+	"macro-analyst/internal/softdelete"
+)
+
+// withDashboards gives the server a dashboard collection.
+func withDashboards(srv *FiberServer) {
+	srv.Dashboards = softdelete.New[dashboard.Dashboard]()
+}
+
+// TestListDashboardsHandler tests that templates are public and saved
+// dashboards are only listed for their own key.
+func TestListDashboardsHandler(t *testing.T) {
+	srv := newAPITestServer(withDashboards)
+	createResource[dashboard.Dashboard](t, srv, "/api/dashboards", "alice-key", `{"name":"My liquidity","based_on":"liquidity"}`)
+
+	tests := []struct {
+		name  string
+		key   string
+		saved int
+	}{
+		{"anonymous", "", 0},
+		{"owner", "alice-key", 1},
+		{"other key", "bob-key", 0},
+	}
+
+	for _, tt := range tests {
+		resp := doAPIRequest(t, srv, http.MethodGet, "/api/dashboards", tt.key, "")
+		var body struct {
+			Templates  []dashboard.Dashboard `json:"templates"`
+			Dashboards []dashboard.Dashboard `json:"dashboards"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+
+		if len(body.Templates) != len(dashboard.Templates()) {
+			t.Errorf("%s: expected %d templates, got %d", tt.name, len(dashboard.Templates()), len(body.Templates))
+		}
+		if len(body.Dashboards) != tt.saved {
+			t.Errorf("%s: expected %d saved dashboards, got %d", tt.name, tt.saved, len(body.Dashboards))
+		}
+	}
+}
+
+// TestDashboardCRUD tests saving a variant of a template, updating,
+// deleting and restoring it.
+func TestDashboardCRUD(t *testing.T) {
+	srv := newAPITestServer(withDashboards)
+
+	created := createResource[dashboard.Dashboard](t, srv, "/api/dashboards", "alice-key", `{"name":"My crypto","based_on":"crypto","state":{"zoom":2}}`)
+	template, _ := dashboard.FindTemplate(dashboard.TemplateCrypto)
+	if created.ID == "" || created.Template || created.BasedOn != "crypto" || len(created.Widgets) != len(template.Widgets) {
+		t.Errorf("Unexpected dashboard: %+v", created)
+	}
+	if string(created.State) != `{"zoom":2}` {
+		t.Errorf("Expected state to be stored as sent, got %s", created.State)
+	}
+
+	resp := doAPIRequest(t, srv, http.MethodPut, "/api/dashboards/"+created.ID, "alice-key",
+		`{"name":"Just BTC","widgets":[{"type":"ticker","symbols":["BTCUSDT"],"position":{"x":0,"y":0,"w":4,"h":2}}]}`)
+	var updated dashboard.Dashboard
+	json.NewDecoder(resp.Body).Decode(&updated)
+	resp.Body.Close()
+	if updated.ID != created.ID || updated.Name != "Just BTC" || len(updated.Widgets) != 1 || updated.BasedOn != "crypto" {
+		t.Errorf("Unexpected updated dashboard: %+v", updated)
+	}
+
+	resp = doAPIRequest(t, srv, http.MethodGet, "/api/dashboards/"+created.ID, "alice-key", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected get status 200, got %d", resp.StatusCode)
+	}
+
+	resp = doAPIRequest(t, srv, http.MethodDelete, "/api/dashboards/"+created.ID, "alice-key", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(srv.Dashboards.List()) != 0 {
+		t.Errorf("Expected the dashboard to be in the trash, got status %d", resp.StatusCode)
+	}
+
+	resp = doAPIRequest(t, srv, http.MethodPost, "/api/dashboards/"+created.ID+"/restore", "alice-key", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(srv.Dashboards.List()) != 1 {
+		t.Errorf("Expected the dashboard to be restored, got status %d", resp.StatusCode)
+	}
+}
+
+// TestDashboardAccess tests that templates are read-only and saved
+// dashboards are private to the key that saved them.
+func TestDashboardAccess(t *testing.T) {
+	srv := newAPITestServer(withDashboards)
+	created := createResource[dashboard.Dashboard](t, srv, "/api/dashboards", "alice-key", `{"name":"Mine","based_on":"rates"}`)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		key      string
+		expected int
+	}{
+		{"get template", http.MethodGet, "/api/dashboards/liquidity", "", http.StatusOK},
+		{"update template", http.MethodPut, "/api/dashboards/liquidity", "alice-key", http.StatusForbidden},
+		{"get other key", http.MethodGet, "/api/dashboards/" + created.ID, "bob-key", http.StatusNotFound},
+		{"get without key", http.MethodGet, "/api/dashboards/" + created.ID, "", http.StatusNotFound},
+		{"update other key", http.MethodPut, "/api/dashboards/" + created.ID, "bob-key", http.StatusForbidden},
+		{"delete without key", http.MethodDelete, "/api/dashboards/" + created.ID, "", http.StatusUnauthorized},
+		{"delete unknown", http.MethodDelete, "/api/dashboards/unknown", "alice-key", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		resp := doAPIRequest(t, srv, tt.method, tt.path, tt.key, `{"name":"Taken"}`)
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expected, resp.StatusCode)
+		}
+	}
+	if d, _ := srv.Dashboards.Get(created.ID); d.Name != "Mine" {
+		t.Errorf("Expected the dashboard to be unchanged, got %+v", d)
+	}
+}
+
+// TestCreateDashboardErrors tests that invalid dashboards are rejected.
+func TestCreateDashboardErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"missing name", `{"based_on":"liquidity"}`},
+		{"unknown template", `{"name":"Mine","based_on":"bonds"}`},
+		{"unknown widget", `{"name":"Mine","widgets":[{"type":"pie"}]}`},
+		{"invalid body", `not json`},
+	}
+
+	srv := newAPITestServer(withDashboards)
+	for _, tt := range tests {
+		resp := doAPIRequest(t, srv, http.MethodPost, "/api/dashboards", "alice-key", tt.body)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", tt.name, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"errors"
+	"time"
+
+	"macro-analyst/pkg/i18n"
+	"macro-analyst/pkg/ws"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DrainRequest is the body of POST /api/admin/drain.
+type DrainRequest struct {
+	// ReconnectTo is the alternate WebSocket URL clients are sent to
+	ReconnectTo string `json:"reconnect_to"`
+
+	// GracePeriodSeconds spreads the reconnect hints before remaining
+	// connections are closed (default 30)
+	GracePeriodSeconds int `json:"grace_period_seconds"`
+}
+
+// DrainHandler stops accepting new WebSocket connections and moves the
+// connected clients to another host over a grace period, for rolling
+// maintenance.
+func (s *FiberServer) DrainHandler(c *fiber.Ctx) error {
+	var req DrainRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": translate(c, i18n.MsgInvalidBody),
+			})
+		}
+	}
+	if req.GracePeriodSeconds < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgNegativeGracePeriod),
+		})
+	}
+
+	grace := ws.DefaultDrainGracePeriod
+	if req.GracePeriodSeconds > 0 {
+		grace = time.Duration(req.GracePeriodSeconds) * time.Second
+	}
+
+	clients, err := s.Hub.Drain(req.ReconnectTo, grace)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, ws.ErrAlreadyDraining) {
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	for _, hub := range []*ws.Hub{s.CandleHub, s.OrderBookHub} {
+		if hub == nil {
+			continue
+		}
+		hubClients, err := hub.Drain(req.ReconnectTo, grace)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		clients += hubClients
+	}
+	if s.RawProxy != nil {
+		subscribers, err := s.RawProxy.Drain(req.ReconnectTo, grace)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		clients += subscribers
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"draining":             true,
+		"clients":              clients,
+		"reconnect_to":         req.ReconnectTo,
+		"grace_period_seconds": int(grace.Seconds()),
+	})
+}
+
+// rejectWhileDraining refuses new WebSocket connections while the server
+// drains for maintenance.
+func (s *FiberServer) rejectWhileDraining(c *fiber.Ctx) error {
+	if s.Hub.Draining() {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgDraining),
+		})
+	}
+	return c.Next()
+}
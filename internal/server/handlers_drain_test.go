@@ -0,0 +1,71 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"macro-analyst/internal/config"
+	"macro-analyst/pkg/ws"
+)
+
+// TestDrainHandler tests that draining refuses new connections and reports unhealthy.
+func TestDrainHandler(t *testing.T) {
+	// Arrange
+	hub := ws.NewHub()
+	srv := New(hub, config.ServerConfig{AdminToken: "secret", APIKeys: []string{"alice-key"}})
+	srv.CandleHub = ws.NewHub()
+	srv.OrderBookHub = ws.NewHub()
+	srv.RawProxy = ws.NewRawProxy()
+	srv.RegisterFiberRoutes()
+
+	// Act
+	resp := doAdminRequest(t, srv, http.MethodPost, "/api/admin/drain", "secret",
+		`{"reconnect_to":"wss://standby.example.com/ws/prices","grace_period_seconds":1}`)
+	resp.Body.Close()
+
+	// Assert
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d", http.StatusAccepted, resp.StatusCode)
+	}
+
+	checks := []struct {
+		method   string
+		path     string
+		body     string
+		expected int
+	}{
+		{http.MethodGet, "/ws/prices", "", http.StatusServiceUnavailable},
+		{http.MethodGet, "/ws/candles", "", http.StatusServiceUnavailable},
+		{http.MethodGet, "/ws/orderbook", "", http.StatusServiceUnavailable},
+		{http.MethodGet, "/ws/raw/btcusdt@trade", "", http.StatusServiceUnavailable},
+		{http.MethodGet, "/health", "", http.StatusServiceUnavailable},
+		{http.MethodPost, "/api/admin/drain", "{}", http.StatusConflict},
+	}
+	for _, check := range checks {
+		resp := doAdminRequest(t, srv, check.method, check.path, "secret", check.body)
+		resp.Body.Close()
+		if resp.StatusCode != check.expected {
+			t.Errorf("%s %s: expected status %d, got %d", check.method, check.path, check.expected, resp.StatusCode)
+		}
+	}
+}
+
+// TestDrainHandlerRejectsNegativeGrace tests validation of the grace period.
+func TestDrainHandlerRejectsNegativeGrace(t *testing.T) {
+	// Arrange
+	hub := ws.NewHub()
+	srv := New(hub, config.ServerConfig{AdminToken: "secret"})
+	srv.RegisterFiberRoutes()
+
+	// Act
+	resp := doAdminRequest(t, srv, http.MethodPost, "/api/admin/drain", "secret", `{"grace_period_seconds":-1}`)
+	defer resp.Body.Close()
+
+	// Assert
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+	if hub.Draining() {
+		t.Error("Expected the hub not to drain")
+	}
+}
@@ -0,0 +1,23 @@
+package server
+
+import (
+	"macro-analyst/pkg/ws"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DropsHandler summarizes the messages that did not reach clients over the
+// last hour, by hub and reason, for tracking down gaps clients report.
+func (s *FiberServer) DropsHandler(c *fiber.Ctx) error {
+	hubs := fiber.Map{"prices": s.Hub.DropSummary()}
+	for name, hub := range map[string]*ws.Hub{"candles": s.CandleHub, "orderbook": s.OrderBookHub} {
+		if hub != nil {
+			hubs[name] = hub.DropSummary()
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"window_seconds": int(ws.DropWindow.Seconds()),
+		"hubs":           hubs,
+	})
+}
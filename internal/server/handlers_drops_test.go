@@ -0,0 +1,47 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"macro-analyst/internal/config"
+	"macro-analyst/pkg/ws"
+)
+
+// TestDropsHandler tests that dropped messages are summarized by hub and
+// reason.
+func TestDropsHandler(t *testing.T) {
+	hub := ws.NewHub()
+	srv := New(hub, config.ServerConfig{AdminToken: "secret"})
+	srv.CandleHub = ws.NewHub()
+	srv.RegisterFiberRoutes()
+
+	for i := 0; i < ws.BroadcastBufferSize+2; i++ {
+		hub.Publish([]byte(`{"type":"heartbeat"}`))
+	}
+
+	resp := doAdminRequest(t, srv, http.MethodGet, "/api/admin/drops", "secret", "")
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var body struct {
+		WindowSeconds int                       `json:"window_seconds"`
+		Hubs          map[string]ws.DropSummary `json:"hubs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if body.WindowSeconds != 3600 || len(body.Hubs) != 2 {
+		t.Fatalf("Expected an hour of prices and candles drops, got %+v", body)
+	}
+	if prices := body.Hubs["prices"]; prices.Total != 2 || prices.Reasons[ws.DropChannelFull] != 2 {
+		t.Errorf("Expected 2 channel_full drops, got %+v", prices)
+	}
+	if candles := body.Hubs["candles"]; candles.Total != 0 || len(candles.Reasons) != len(ws.DropReasons) {
+		t.Errorf("Expected every reason without drops, got %+v", candles)
+	}
+}
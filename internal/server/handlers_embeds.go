@@ -0,0 +1,265 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"macro-analyst/internal/embed"
+	"macro-analyst/internal/softdelete"
+	"macro-analyst/pkg/i18n"
+	"macro-analyst/pkg/usage"
+	"macro-analyst/pkg/ws"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// MaxEmbedsPerKey limits how many embed widgets one API key may create.
+	MaxEmbedsPerKey = 20
+
+	// embedWidgetLocal is the context key holding the requested embed widget
+	embedWidgetLocal = "embedWidget"
+)
+
+// errNotEmbedOwner is returned when modifying another key's embed widget
+var errNotEmbedOwner = errors.New("embed widget belongs to another API key")
+
+// EmbedRequest is the body of POST /api/embeds and PUT /api/embeds/:id.
+type EmbedRequest struct {
+	Title          string   `json:"title"`
+	Symbols        []string `json:"symbols"`
+	RefreshSeconds int      `json:"refresh_seconds"`
+	Theme          string   `json:"theme"`
+}
+
+// ListEmbedsHandler returns the caller's embed widgets.
+func (s *FiberServer) ListEmbedsHandler(c *fiber.Ctx) error {
+	owner := usage.KeyID(apiKey(c))
+	widgets := []embed.Widget{}
+	for _, widget := range s.Embeds.List() {
+		if widget.Owner == owner {
+			widgets = append(widgets, widget)
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"embeds": widgets,
+	})
+}
+
+// CreateEmbedHandler creates an embed widget owned by the caller's API key.
+func (s *FiberServer) CreateEmbedHandler(c *fiber.Ctx) error {
+	var req EmbedRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgInvalidBody),
+		})
+	}
+
+	owner := usage.KeyID(apiKey(c))
+	owned := 0
+	for _, widget := range s.Embeds.List() {
+		if widget.Owner == owner {
+			owned++
+		}
+	}
+	if owned >= MaxEmbedsPerKey {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": fmt.Sprintf("at most %d embed widgets per API key", MaxEmbedsPerKey),
+		})
+	}
+
+	widget := embed.Widget{
+		Title:          req.Title,
+		Symbols:        req.Symbols,
+		RefreshSeconds: req.RefreshSeconds,
+		Theme:          req.Theme,
+	}
+	if err := widget.Validate(); err != nil {
+		return c.Status(embedErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	widget.ID = embed.NewID()
+	widget.Owner = owner
+	widget.CreatedAt = time.Now().UTC()
+	widget.UpdatedAt = widget.CreatedAt
+	s.Embeds.Put(widget.ID, widget)
+
+	return c.Status(fiber.StatusCreated).JSON(widget)
+}
+
+// UpdateEmbedHandler replaces the configuration of an embed widget. Pages
+// embedding it pick up the change when they reload.
+func (s *FiberServer) UpdateEmbedHandler(c *fiber.Ctx) error {
+	existing, err := s.ownedEmbed(c)
+	if err != nil {
+		return c.Status(embedErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var req EmbedRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgInvalidBody),
+		})
+	}
+
+	widget := embed.Widget{
+		Title:          req.Title,
+		Symbols:        req.Symbols,
+		RefreshSeconds: req.RefreshSeconds,
+		Theme:          req.Theme,
+	}
+	if err := widget.Validate(); err != nil {
+		return c.Status(embedErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	widget.ID = existing.ID
+	widget.Owner = existing.Owner
+	widget.CreatedAt = existing.CreatedAt
+	widget.UpdatedAt = time.Now().UTC()
+	s.Embeds.Put(widget.ID, widget)
+
+	return c.JSON(widget)
+}
+
+// DeleteEmbedHandler moves an embed widget to the trash. Pages embedding it
+// stop receiving prices until it is restored.
+func (s *FiberServer) DeleteEmbedHandler(c *fiber.Ctx) error {
+	widget, err := s.ownedEmbed(c)
+	if err == nil {
+		err = s.Embeds.Delete(widget.ID)
+	}
+	if err != nil {
+		return c.Status(embedErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"removed": widget.ID,
+	})
+}
+
+// EmbedTrashHandler returns the caller's deleted embed widgets.
+func (s *FiberServer) EmbedTrashHandler(c *fiber.Ctx) error {
+	owner := usage.KeyID(apiKey(c))
+	trash := []softdelete.Deleted[embed.Widget]{}
+	for _, deleted := range s.Embeds.Trash() {
+		if deleted.Value.Owner == owner {
+			trash = append(trash, deleted)
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"trash": trash,
+	})
+}
+
+// RestoreEmbedHandler moves one of the caller's embed widgets out of the trash.
+func (s *FiberServer) RestoreEmbedHandler(c *fiber.Ctx) error {
+	id := c.Params("id")
+	owner := usage.KeyID(apiKey(c))
+
+	var err error = softdelete.ErrNotInTrash
+	for _, deleted := range s.Embeds.Trash() {
+		if deleted.ID == id {
+			err = nil
+			if deleted.Value.Owner != owner {
+				err = errNotEmbedOwner
+			}
+		}
+	}
+	var widget embed.Widget
+	if err == nil {
+		widget, err = s.Embeds.Restore(id)
+	}
+	if err != nil {
+		return c.Status(embedErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(widget)
+}
+
+// EmbedPageHandler serves the iframe-able HTML page of an embed widget.
+func (s *FiberServer) EmbedPageHandler(c *fiber.Ctx) error {
+	widget := c.Locals(embedWidgetLocal).(embed.Widget)
+
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	c.Set(fiber.HeaderContentSecurityPolicy, embed.ContentSecurityPolicy)
+	c.Set(fiber.HeaderCacheControl, "public, max-age=60")
+	return embed.Render(c, widget)
+}
+
+// handleEmbedStream streams the prices of an embed widget's symbols at its
+// refresh rate.
+func (s *FiberServer) handleEmbedStream(c *websocket.Conn) {
+	defer releaseConnection(c)
+
+	widget := c.Locals(embedWidgetLocal).(embed.Widget)
+	client := &ws.Client{
+		Hub:             s.Hub,
+		Conn:            c,
+		Send:            make(chan []byte, s.sendBufferSize),
+		Encoding:        ws.EncodingJSON,
+		RemoteIP:        c.IP(),
+		UserAgent:       c.Headers(fiber.HeaderUserAgent),
+		Stream:          "embed",
+		Symbols:         widget.SymbolSet(),
+		RefreshInterval: widget.RefreshInterval(),
+
+		// Commands may slow the widget's stream down, not speed it up
+		MinRefreshInterval: widget.RefreshInterval(),
+
+		HourlyByteCap: s.EmbedByteCap,
+	}
+
+	pumpClient(s.Hub, client)
+}
+
+// requireEmbed loads the embed widget named in the path.
+func (s *FiberServer) requireEmbed(c *fiber.Ctx) error {
+	widget, exists := s.Embeds.Get(c.Params("id"))
+	if !exists {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": softdelete.ErrNotFound.Error(),
+		})
+	}
+
+	c.Locals(embedWidgetLocal, widget)
+	return c.Next()
+}
+
+// ownedEmbed returns the embed widget named in the path if it belongs to
+// the caller's API key.
+func (s *FiberServer) ownedEmbed(c *fiber.Ctx) (embed.Widget, error) {
+	widget, exists := s.Embeds.Get(c.Params("id"))
+	if !exists {
+		return widget, softdelete.ErrNotFound
+	}
+	if widget.Owner != usage.KeyID(apiKey(c)) {
+		return widget, errNotEmbedOwner
+	}
+	return widget, nil
+}
+
+// embedErrorStatus maps embed widget errors to HTTP status codes.
+func embedErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, embed.ErrInvalidWidget):
+		return fiber.StatusBadRequest
+	case errors.Is(err, softdelete.ErrNotFound), errors.Is(err, softdelete.ErrNotInTrash):
+		return fiber.StatusNotFound
+	case errors.Is(err, errNotEmbedOwner):
+		return fiber.StatusForbidden
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"macro-analyst/internal/embed"
+	"macro-analyst/internal/softdelete"
+)
+
+// withEmbeds gives the server a widget collection.
+func withEmbeds(srv *FiberServer) {
+	srv.Embeds = softdelete.New[embed.Widget]()
+}
+
+// TestEmbedPageHandler tests that widget pages are public and frameable.
+func TestEmbedPageHandler(t *testing.T) {
+	srv := newAPITestServer(withEmbeds)
+	created := createResource[embed.Widget](t, srv, "/api/embeds", "alice-key", `{"title":"Crypto","symbols":["btcusdt","ETHUSDT"],"refresh_seconds":10}`)
+	if created.RefreshSeconds != 10 || created.Symbols[0] != "BTCUSDT" {
+		t.Errorf("Unexpected widget: %+v", created)
+	}
+
+	resp := doAPIRequest(t, srv, http.MethodGet, "/embed/"+created.ID, "", "")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		t.Errorf("Expected an HTML page, got %q", resp.Header.Get("Content-Type"))
+	}
+	if csp := resp.Header.Get("Content-Security-Policy"); !strings.Contains(csp, "frame-ancestors *") {
+		t.Errorf("Expected the page to be frameable, got CSP %q", csp)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), `<tr id="ETHUSDT">`) {
+		t.Error("Expected a row for ETHUSDT")
+	}
+
+	resp = doAPIRequest(t, srv, http.MethodGet, "/embed/unknown", "", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown widget, got %d", resp.StatusCode)
+	}
+}
+
+// TestEmbedCRUD tests updating, deleting and restoring a widget and that
+// only its owner may change it.
+func TestEmbedCRUD(t *testing.T) {
+	srv := newAPITestServer(withEmbeds)
+	created := createResource[embed.Widget](t, srv, "/api/embeds", "alice-key", `{"symbols":["BTCUSDT"]}`)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		key      string
+		body     string
+		expected int
+	}{
+		{"update other key", http.MethodPut, "/api/embeds/" + created.ID, "bob-key", `{"symbols":["ETHUSDT"]}`, http.StatusForbidden},
+		{"update invalid", http.MethodPut, "/api/embeds/" + created.ID, "alice-key", `{"symbols":[]}`, http.StatusBadRequest},
+		{"update", http.MethodPut, "/api/embeds/" + created.ID, "alice-key", `{"symbols":["ETHUSDT"],"theme":"dark"}`, http.StatusOK},
+		{"list without key", http.MethodGet, "/api/embeds", "", "", http.StatusUnauthorized},
+		{"delete other key", http.MethodDelete, "/api/embeds/" + created.ID, "bob-key", "", http.StatusForbidden},
+		{"delete", http.MethodDelete, "/api/embeds/" + created.ID, "alice-key", "", http.StatusOK},
+		{"page after delete", http.MethodGet, "/embed/" + created.ID, "", "", http.StatusNotFound},
+		{"restore other key", http.MethodPost, "/api/embeds/" + created.ID + "/restore", "bob-key", "", http.StatusForbidden},
+		{"restore", http.MethodPost, "/api/embeds/" + created.ID + "/restore", "alice-key", "", http.StatusOK},
+		{"page after restore", http.MethodGet, "/embed/" + created.ID, "", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		resp := doAPIRequest(t, srv, tt.method, tt.path, tt.key, tt.body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expected, resp.StatusCode)
+		}
+	}
+
+	if widget, _ := srv.Embeds.Get(created.ID); widget.Symbols[0] != "ETHUSDT" || widget.Theme != embed.ThemeDark {
+		t.Errorf("Expected the updated widget, got %+v", widget)
+	}
+}
@@ -0,0 +1,296 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"macro-analyst/internal/analytics"
+	"macro-analyst/internal/attribution"
+	"macro-analyst/internal/formula"
+	"macro-analyst/internal/softdelete"
+	"macro-analyst/pkg/i18n"
+	"macro-analyst/pkg/usage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MaxFormulasPerKey limits how many formulas one API key may define, as
+// each one is recomputed live.
+const MaxFormulasPerKey = 20
+
+// errNotFormulaOwner is returned when modifying another key's formula
+var errNotFormulaOwner = errors.New("formula belongs to another API key")
+
+// FormulaRequest is the body of POST /api/formulas and PUT /api/formulas/:id.
+type FormulaRequest struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"` // e.g. "WALCL - WTREGEN - RRPONTSYD"
+}
+
+// FormulaResponse is a formula with its latest live value, nil until all
+// of its series have a live value.
+type FormulaResponse struct {
+	formula.Formula
+	Value *float64 `json:"value"`
+}
+
+// ListFormulasHandler returns all formulas with their latest values.
+func (s *FiberServer) ListFormulasHandler(c *fiber.Ctx) error {
+	formulas := s.Formulas.List()
+	responses := make([]FormulaResponse, len(formulas))
+	for idx, f := range formulas {
+		responses[idx] = s.formulaResponse(f)
+	}
+
+	return c.JSON(fiber.Map{
+		"formulas": responses,
+	})
+}
+
+// GetFormulaHandler returns a formula with its latest value.
+func (s *FiberServer) GetFormulaHandler(c *fiber.Ctx) error {
+	f, exists := s.Formulas.Get(c.Params("id"))
+	if !exists {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": softdelete.ErrNotFound.Error(),
+		})
+	}
+
+	return c.JSON(s.formulaResponse(f))
+}
+
+// CreateFormulaHandler defines a new formula owned by the caller's API key.
+func (s *FiberServer) CreateFormulaHandler(c *fiber.Ctx) error {
+	var req FormulaRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgInvalidBody),
+		})
+	}
+
+	owner := usage.KeyID(apiKey(c))
+	owned := 0
+	for _, f := range s.Formulas.List() {
+		if f.Owner == owner {
+			owned++
+		}
+	}
+	if owned >= MaxFormulasPerKey {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": fmt.Sprintf("at most %d formulas per API key", MaxFormulasPerKey),
+		})
+	}
+
+	f, err := formula.New(req.Name, req.Expression)
+	if err != nil {
+		return c.Status(formulaErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	f.ID = formula.NewID()
+	f.Owner = owner
+	f.CreatedAt = time.Now().UTC()
+	f.UpdatedAt = f.CreatedAt
+	s.Formulas.Put(f.ID, f)
+
+	return c.Status(fiber.StatusCreated).JSON(s.formulaResponse(f))
+}
+
+// UpdateFormulaHandler replaces the name and expression of a formula.
+func (s *FiberServer) UpdateFormulaHandler(c *fiber.Ctx) error {
+	existing, err := s.ownedFormula(c)
+	if err != nil {
+		return c.Status(formulaErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var req FormulaRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgInvalidBody),
+		})
+	}
+
+	f, err := formula.New(req.Name, req.Expression)
+	if err != nil {
+		return c.Status(formulaErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	f.ID = existing.ID
+	f.Owner = existing.Owner
+	f.CreatedAt = existing.CreatedAt
+	f.UpdatedAt = time.Now().UTC()
+	s.Formulas.Put(f.ID, f)
+
+	return c.JSON(s.formulaResponse(f))
+}
+
+// DeleteFormulaHandler moves a formula to the trash, from which it can be
+// restored until it is purged.
+func (s *FiberServer) DeleteFormulaHandler(c *fiber.Ctx) error {
+	f, err := s.ownedFormula(c)
+	if err == nil {
+		err = s.Formulas.Delete(f.ID)
+	}
+	if err != nil {
+		return c.Status(formulaErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"removed": f.ID,
+	})
+}
+
+// FormulaTrashHandler returns the caller's deleted formulas.
+func (s *FiberServer) FormulaTrashHandler(c *fiber.Ctx) error {
+	owner := usage.KeyID(apiKey(c))
+	trash := []softdelete.Deleted[formula.Formula]{}
+	for _, deleted := range s.Formulas.Trash() {
+		if deleted.Value.Owner == owner {
+			trash = append(trash, deleted)
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"trash": trash,
+	})
+}
+
+// RestoreFormulaHandler moves one of the caller's formulas out of the trash.
+func (s *FiberServer) RestoreFormulaHandler(c *fiber.Ctx) error {
+	id := c.Params("id")
+	owner := usage.KeyID(apiKey(c))
+
+	var err error = softdelete.ErrNotInTrash
+	for _, deleted := range s.Formulas.Trash() {
+		if deleted.ID == id {
+			err = nil
+			if deleted.Value.Owner != owner {
+				err = errNotFormulaOwner
+			}
+		}
+	}
+	var f formula.Formula
+	if err == nil {
+		f, err = s.Formulas.Restore(id)
+	}
+	if err != nil {
+		return c.Status(formulaErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(s.formulaResponse(f))
+}
+
+// FormulaHistoryHandler computes a formula over historical data, with its
+// series aligned onto a common grid like /api/analytics/align.
+//
+// Query parameters: start and end (YYYY-MM-DD, default the last year) and
+// grid (daily, weekly or monthly, default daily).
+func (s *FiberServer) FormulaHistoryHandler(c *fiber.Ctx) error {
+	f, exists := s.Formulas.Get(c.Params("id"))
+	if !exists {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": softdelete.ErrNotFound.Error(),
+		})
+	}
+
+	start, end, err := parseDateRange(c.Query("start"), c.Query("end"), DefaultAlignRange)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	grid := c.Query("grid", analytics.GridDaily)
+	dates, err := analytics.Grid(grid, start, end)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), AnalyticsTimeout)
+	defer cancel()
+
+	variables := f.Variables()
+	lookback := start.Add(-analytics.MaxFillAge("quarterly"))
+	series := make([]analytics.Series, len(variables))
+	for idx, variable := range variables {
+		if series[idx], err = s.fetchSeries(ctx, f.Series[variable], lookback, end); err != nil {
+			return c.Status(seriesErrorStatus(err)).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+	}
+	aligned := analytics.Align(grid, dates, series)
+
+	// Dates where a series has no value or the result is undefined stay null
+	values := make([]*float64, len(dates))
+	for day := range dates {
+		inputs := make(map[string]float64, len(variables))
+		for idx, variable := range variables {
+			if value := aligned.Series[idx].Values[day]; value != nil {
+				inputs[variable] = *value
+			}
+		}
+		if value, err := f.Eval(inputs); err == nil {
+			values[day] = &value
+		}
+	}
+
+	return c.JSON(struct {
+		ID          string                     `json:"id"`
+		Name        string                     `json:"name"`
+		Expression  string                     `json:"expression"`
+		Grid        string                     `json:"grid"`
+		Dates       []string                   `json:"dates"`
+		Values      []*float64                 `json:"values"`
+		Attribution []*attribution.Attribution `json:"attribution,omitempty"`
+	}{f.ID, f.Name, f.Expression, grid, aligned.Dates, values, s.seriesAttribution(series)})
+}
+
+// ownedFormula returns the formula named in the path if it belongs to the
+// caller's API key.
+func (s *FiberServer) ownedFormula(c *fiber.Ctx) (formula.Formula, error) {
+	f, exists := s.Formulas.Get(c.Params("id"))
+	if !exists {
+		return f, softdelete.ErrNotFound
+	}
+	if f.Owner != usage.KeyID(apiKey(c)) {
+		return f, errNotFormulaOwner
+	}
+	return f, nil
+}
+
+// formulaResponse attaches the latest live value to a formula.
+func (s *FiberServer) formulaResponse(f formula.Formula) FormulaResponse {
+	response := FormulaResponse{Formula: f}
+	if s.FormulaEngine != nil {
+		if value, exists := s.FormulaEngine.Latest(f.ID); exists {
+			response.Value = &value
+		}
+	}
+	return response
+}
+
+// formulaErrorStatus maps formula errors to HTTP status codes.
+func formulaErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, formula.ErrSyntax), errors.Is(err, formula.ErrInvalidName):
+		return fiber.StatusBadRequest
+	case errors.Is(err, softdelete.ErrNotFound), errors.Is(err, softdelete.ErrNotInTrash):
+		return fiber.StatusNotFound
+	case errors.Is(err, errNotFormulaOwner):
+		return fiber.StatusForbidden
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,188 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"macro-analyst/internal/formula"
+	"macro-analyst/internal/softdelete"
+	"macro-analyst/pkg/ws"
+)
+
+// withFormulas gives the server stubbed history, a formula collection and engine.
+func withFormulas(srv *FiberServer) {
+	srv.FREDClient = seriesFREDClient{}
+	srv.CryptoHistory = stubCryptoHistory
+	srv.Formulas = softdelete.New[formula.Formula]()
+	srv.FormulaEngine = formula.NewEngine(srv.FREDClient, nil)
+}
+
+// TestFormulaCRUD tests creating, updating, deleting and restoring a formula.
+func TestFormulaCRUD(t *testing.T) {
+	srv := newAPITestServer(withFormulas)
+
+	created := createResource[FormulaResponse](t, srv, "/api/formulas", "alice-key", `{"name":"Net liquidity","expression":"WALCL - WTREGEN - RRPONTSYD"}`)
+	if created.ID == "" || created.Series["WTREGEN"] != "fred:WTREGEN" || created.Value != nil {
+		t.Errorf("Unexpected formula: %+v", created)
+	}
+
+	// Live values show up once the engine evaluated the formula
+	srv.FormulaEngine.Evaluate(context.Background(), srv.Formulas.List())
+	resp := doAPIRequest(t, srv, http.MethodGet, "/api/formulas/"+created.ID, "", "")
+	var fetched FormulaResponse
+	json.NewDecoder(resp.Body).Decode(&fetched)
+	resp.Body.Close()
+	if fetched.Value == nil || *fetched.Value != -3.5 {
+		t.Errorf("Expected live value -3.5 from the stub, got %v", fetched.Value)
+	}
+
+	resp = doAPIRequest(t, srv, http.MethodPut, "/api/formulas/"+created.ID, "alice-key",
+		`{"name":"BTC in dollar index","expression":"BTCUSDT / DTWEXBGS"}`)
+	var updated FormulaResponse
+	json.NewDecoder(resp.Body).Decode(&updated)
+	resp.Body.Close()
+	if updated.ID != created.ID || updated.Series["BTCUSDT"] != "crypto:BTCUSDT" || !updated.CreatedAt.Equal(created.CreatedAt) {
+		t.Errorf("Unexpected updated formula: %+v", updated)
+	}
+
+	resp = doAPIRequest(t, srv, http.MethodDelete, "/api/formulas/"+created.ID, "alice-key", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected delete status 200, got %d", resp.StatusCode)
+	}
+	if len(srv.Formulas.List()) != 0 {
+		t.Error("Expected the formula to be in the trash")
+	}
+
+	resp = doAPIRequest(t, srv, http.MethodPost, "/api/formulas/"+created.ID+"/restore", "alice-key", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(srv.Formulas.List()) != 1 {
+		t.Errorf("Expected the formula to be restored, got status %d", resp.StatusCode)
+	}
+}
+
+// TestFormulaOwnership tests that only the creating API key may change a formula.
+func TestFormulaOwnership(t *testing.T) {
+	srv := newAPITestServer(withFormulas)
+	created := createResource[FormulaResponse](t, srv, "/api/formulas", "alice-key", `{"name":"Net liquidity","expression":"WALCL - WTREGEN"}`)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		key      string
+		expected int
+	}{
+		{"update other key", http.MethodPut, "/api/formulas/" + created.ID, "bob-key", http.StatusForbidden},
+		{"delete other key", http.MethodDelete, "/api/formulas/" + created.ID, "bob-key", http.StatusForbidden},
+		{"delete without key", http.MethodDelete, "/api/formulas/" + created.ID, "", http.StatusUnauthorized},
+		{"delete unknown", http.MethodDelete, "/api/formulas/unknown", "alice-key", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		resp := doAPIRequest(t, srv, tt.method, tt.path, tt.key, `{"name":"Taken","expression":"WALCL"}`)
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expected, resp.StatusCode)
+		}
+	}
+	if f, _ := srv.Formulas.Get(created.ID); f.Name != "Net liquidity" {
+		t.Errorf("Expected the formula to be unchanged, got %+v", f)
+	}
+}
+
+// TestCreateFormulaErrors tests that invalid formulas are rejected.
+func TestCreateFormulaErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"syntax error", `{"name":"Broken","expression":"WALCL -"}`},
+		{"no series", `{"name":"Constant","expression":"42"}`},
+		{"missing name", `{"expression":"WALCL"}`},
+		{"invalid body", `not json`},
+	}
+
+	srv := newAPITestServer(withFormulas)
+	for _, tt := range tests {
+		resp := doAPIRequest(t, srv, http.MethodPost, "/api/formulas", "alice-key", tt.body)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", tt.name, resp.StatusCode)
+		}
+	}
+}
+
+// TestCreateFormulaLimit tests the per-key formula limit.
+func TestCreateFormulaLimit(t *testing.T) {
+	srv := newAPITestServer(withFormulas)
+	for range MaxFormulasPerKey {
+		createResource[FormulaResponse](t, srv, "/api/formulas", "alice-key", `{"name":"Fed","expression":"WALCL"}`)
+	}
+
+	resp := doAPIRequest(t, srv, http.MethodPost, "/api/formulas", "alice-key", `{"name":"Fed","expression":"WALCL"}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d", resp.StatusCode)
+	}
+
+	createResource[FormulaResponse](t, srv, "/api/formulas", "bob-key", `{"name":"Fed","expression":"WALCL"}`)
+}
+
+// TestFormulaHistoryHandler tests that formulas are computed over aligned history.
+func TestFormulaHistoryHandler(t *testing.T) {
+	// Arrange
+	srv := newAPITestServer(withFormulas)
+	created := createResource[FormulaResponse](t, srv, "/api/formulas", "alice-key", `{"name":"Fed in billions","expression":"fred:WALCL / 1000"}`)
+
+	// Act
+	resp := doAPIRequest(t, srv, http.MethodGet,
+		"/api/formulas/"+created.ID+"/history?start=2024-01-01&end=2024-01-04", "", "")
+
+	// Assert
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Dates  []string   `json:"dates"`
+		Values []*float64 `json:"values"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	expected := []float64{7700, 7700, 7650, 7650}
+	if len(body.Values) != len(expected) || len(body.Dates) != len(expected) {
+		t.Fatalf("Expected %d values, got %d", len(expected), len(body.Values))
+	}
+	for idx, value := range body.Values {
+		if value == nil || *value != expected[idx] {
+			t.Errorf("%s: expected %v, got %v", body.Dates[idx], expected[idx], value)
+		}
+	}
+}
+
+// TestFormulasReadOnlyWithoutAPIKeys tests that formulas cannot be changed
+// when no API keys are configured.
+func TestFormulasReadOnlyWithoutAPIKeys(t *testing.T) {
+	srv := New(ws.NewHub())
+	srv.Formulas = softdelete.New[formula.Formula]()
+	srv.RegisterFiberRoutes()
+
+	resp := doAPIRequest(t, srv, http.MethodPost, "/api/formulas", "", `{"name":"Fed","expression":"WALCL"}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed && resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected the create route to be missing, got %d", resp.StatusCode)
+	}
+
+	resp = doAPIRequest(t, srv, http.MethodGet, "/api/formulas", "", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+}
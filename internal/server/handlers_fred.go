@@ -2,9 +2,13 @@ package server
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"time"
 
-	"macro-analyst/internal/fred"
+	"macro-analyst/internal/attribution"
+	"macro-analyst/pkg/fred"
+	"macro-analyst/pkg/i18n"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -14,47 +18,67 @@ const (
 	RequestTimeout = 10 * time.Second
 )
 
-// GetAllTickersHandler returns all available FRED tickers with descriptions.
+// GetAllTickersHandler returns all registered FRED tickers with
+// descriptions and units.
 func (s *FiberServer) GetAllTickersHandler(c *fiber.Ctx) error {
 	if s.FREDClient == nil {
 		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "FRED API client not configured",
+			"error": translate(c, i18n.MsgFREDUnavailable),
 		})
 	}
 
-	tickers := fred.AllTickers()
-	response := make([]fiber.Map, len(tickers))
+	registered := s.Tickers.Series()
+	response := make([]fiber.Map, len(registered))
 
-	for i, ticker := range tickers {
+	for i, series := range registered {
 		response[i] = fiber.Map{
-			"symbol":      ticker.String(),
-			"description": ticker.Description(),
+			"symbol":      series.Ticker.String(),
+			"description": s.Tickers.DescriptionIn(series.Ticker, requestLang(c)),
+			"units":       series.Units,
 		}
 	}
 
-	return c.JSON(fiber.Map{
+	body := fiber.Map{
 		"tickers": response,
 		"count":   len(response),
-	})
+	}
+	if attr := s.Attribution.FRED("", time.Now()); attr != nil {
+		body["attribution"] = attr
+	}
+
+	return c.JSON(body)
 }
 
-// GetTickerDataHandler returns historical observations for a specific ticker.
+// GetTickerDataHandler returns historical observations for a specific ticker,
+// transformed by the transform query parameter, e.g. yoy for inflation as a
+// year-over-year percent change instead of index levels.
 func (s *FiberServer) GetTickerDataHandler(c *fiber.Ctx) error {
 	if s.FREDClient == nil {
 		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "FRED API client not configured",
+			"error": translate(c, i18n.MsgFREDUnavailable),
 		})
 	}
 
-	symbol := c.Params("symbol")
-	ticker := fred.Ticker(symbol)
+	ticker, ok := s.lookupTicker(c)
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgUnknownTicker, ticker),
+		})
+	}
 
 	// Parse query parameters
+	units, err := fred.ParseUnits(c.Query("transform"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 	opts := &fred.QueryOptions{
 		StartDate: c.Query("start_date", ""),
 		EndDate:   c.Query("end_date", ""),
 		Limit:     c.QueryInt("limit", fred.DefaultLimit),
 		SortOrder: c.Query("sort_order", "desc"),
+		Units:     units,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), RequestTimeout)
@@ -67,19 +91,29 @@ func (s *FiberServer) GetTickerDataHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.JSON(data)
+	localized := *data
+	localized.Description = s.Tickers.DescriptionIn(ticker, requestLang(c))
+
+	return c.JSON(struct {
+		*fred.SeriesData
+		Attribution *attribution.Attribution `json:"attribution,omitempty"`
+	}{&localized, s.Attribution.FRED(string(ticker), data.LastUpdated)})
 }
 
 // GetLatestValueHandler returns the most recent value for a specific ticker.
 func (s *FiberServer) GetLatestValueHandler(c *fiber.Ctx) error {
 	if s.FREDClient == nil {
 		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "FRED API client not configured",
+			"error": translate(c, i18n.MsgFREDUnavailable),
 		})
 	}
 
-	symbol := c.Params("symbol")
-	ticker := fred.Ticker(symbol)
+	ticker, ok := s.lookupTicker(c)
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgUnknownTicker, ticker),
+		})
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), RequestTimeout)
 	defer cancel()
@@ -91,18 +125,24 @@ func (s *FiberServer) GetLatestValueHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.JSON(latest)
+	localized := *latest
+	localized.Description = s.Tickers.DescriptionIn(ticker, requestLang(c))
+
+	return c.JSON(struct {
+		*fred.LatestValue
+		Attribution *attribution.Attribution `json:"attribution,omitempty"`
+	}{&localized, s.Attribution.FRED(string(ticker), latest.UpdatedAt)})
 }
 
-// GetAllLatestHandler returns the latest values for all supported tickers.
+// GetAllLatestHandler returns the latest values for all registered tickers.
 func (s *FiberServer) GetAllLatestHandler(c *fiber.Ctx) error {
 	if s.FREDClient == nil {
 		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "FRED API client not configured",
+			"error": translate(c, i18n.MsgFREDUnavailable),
 		})
 	}
 
-	tickers := fred.AllTickers()
+	tickers := s.Tickers.Tickers()
 
 	ctx, cancel := context.WithTimeout(context.Background(), RequestTimeout)
 	defer cancel()
@@ -114,5 +154,100 @@ func (s *FiberServer) GetAllLatestHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.JSON(result)
+	localized := &fred.MultiTickerResponse{
+		Data:      make([]fred.LatestValue, len(result.Data)),
+		Timestamp: result.Timestamp,
+		Errors:    result.Errors,
+	}
+	for idx, latest := range result.Data {
+		latest.Description = s.Tickers.DescriptionIn(latest.Ticker, requestLang(c))
+		localized.Data[idx] = latest
+	}
+
+	return c.JSON(struct {
+		*fred.MultiTickerResponse
+		Attribution *attribution.Attribution `json:"attribution,omitempty"`
+	}{localized, s.Attribution.FRED("", result.Timestamp)})
+}
+
+// RegisterTickerHandler adds a FRED series to the served tickers, or
+// updates the description and units of a registered one. When a FRED
+// client is configured the series must exist on FRED, and missing
+// description and units are taken from its series info.
+func (s *FiberServer) RegisterTickerHandler(c *fiber.Ctx) error {
+	var req fred.Series
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgInvalidBody),
+		})
+	}
+
+	ticker, err := fred.NormalizeTicker(string(req.Ticker))
+	if err != nil {
+		return c.Status(tickerErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	req.Ticker = ticker
+
+	if s.FREDClient != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), RequestTimeout)
+		defer cancel()
+
+		info, err := s.FREDClient.GetSeriesInfo(ctx, ticker)
+		if err != nil {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if req.Description == "" {
+			req.Description = info.Title
+		}
+		if req.Units == "" {
+			req.Units = info.Units
+		}
+	}
+
+	series, err := s.Tickers.Register(req)
+	if err != nil {
+		return c.Status(tickerErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(series)
+}
+
+// UnregisterTickerHandler removes a FRED series registered at runtime.
+func (s *FiberServer) UnregisterTickerHandler(c *fiber.Ctx) error {
+	ticker := fred.Ticker(strings.ToUpper(c.Params("symbol")))
+
+	if err := s.Tickers.Unregister(ticker); err != nil {
+		return c.Status(tickerErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"removed": ticker,
+	})
+}
+
+// tickerErrorStatus maps registry errors to HTTP status codes.
+func tickerErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, fred.ErrUnknownTicker):
+		return fiber.StatusNotFound
+	case errors.Is(err, fred.ErrBuiltinTicker):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusBadRequest
+	}
+}
+
+// lookupTicker resolves the symbol parameter to a ticker and reports
+// whether it is registered. Symbols are case-insensitive.
+func (s *FiberServer) lookupTicker(c *fiber.Ctx) (fred.Ticker, bool) {
+	ticker := fred.Ticker(strings.ToUpper(c.Params("symbol")))
+	return ticker, s.Tickers.Has(ticker)
 }
@@ -0,0 +1,213 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"macro-analyst/internal/attribution"
+	"macro-analyst/internal/config"
+	"macro-analyst/pkg/fred"
+	"macro-analyst/pkg/ws"
+)
+
+// stubFREDClient returns fixed FRED data.
+type stubFREDClient struct{}
+
+func (stubFREDClient) GetSeriesObservations(ctx context.Context, ticker fred.Ticker, opts *fred.QueryOptions) (*fred.SeriesData, error) {
+	return &fred.SeriesData{Ticker: ticker, Transform: opts.Units, LastUpdated: time.Now()}, nil
+}
+
+func (stubFREDClient) GetLatestValue(ctx context.Context, ticker fred.Ticker) (*fred.LatestValue, error) {
+	return &fred.LatestValue{Ticker: ticker, Value: "3.5", UpdatedAt: time.Now()}, nil
+}
+
+func (stubFREDClient) GetMultipleLatest(ctx context.Context, tickers []fred.Ticker) (*fred.MultiTickerResponse, error) {
+	return &fred.MultiTickerResponse{Timestamp: time.Now()}, nil
+}
+
+func (stubFREDClient) GetSeriesInfo(ctx context.Context, ticker fred.Ticker) (*fred.FREDSeriesInfo, error) {
+	if ticker == "NOSUCHSERIES" {
+		return nil, errors.New("series does not exist")
+	}
+	return &fred.FREDSeriesInfo{ID: string(ticker), Title: "Series " + string(ticker), Units: "Percent"}, nil
+}
+
+// TestFREDAttribution tests that FRED responses carry attribution when the
+// module is enabled and keep their original fields.
+func TestFREDAttribution(t *testing.T) {
+	tests := []struct {
+		name     string
+		modules  []attribution.Module
+		expected bool
+	}{
+		{"enabled", []attribution.Module{attribution.ModuleFRED}, true},
+		{"disabled", []attribution.Module{attribution.ModuleCrypto}, false},
+	}
+
+	for _, tt := range tests {
+		srv := New(ws.NewHub())
+		srv.FREDClient = stubFREDClient{}
+		srv.Tickers = fred.NewRegistry()
+		srv.Tickers.Register(fred.Series{Ticker: "UNRATE"})
+		srv.Attribution = attribution.New(tt.modules...)
+		srv.RegisterFiberRoutes()
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/fred/latest/UNRATE", nil)
+		resp, err := srv.App.Test(req)
+		if err != nil {
+			t.Fatalf("%s: failed to execute request: %v", tt.name, err)
+		}
+
+		var body struct {
+			Ticker      string                   `json:"ticker"`
+			Value       string                   `json:"value"`
+			Attribution *attribution.Attribution `json:"attribution"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("%s: failed to decode response: %v", tt.name, err)
+		}
+		resp.Body.Close()
+
+		if body.Ticker != "UNRATE" || body.Value != "3.5" {
+			t.Errorf("%s: expected original fields to be kept, got %+v", tt.name, body)
+		}
+		if (body.Attribution != nil) != tt.expected {
+			t.Errorf("%s: expected attribution %v, got %+v", tt.name, tt.expected, body.Attribution)
+		}
+		if tt.expected && body.Attribution.URL != "https://fred.stlouisfed.org/series/UNRATE" {
+			t.Errorf("%s: unexpected attribution URL %s", tt.name, body.Attribution.URL)
+		}
+	}
+}
+
+// TestFREDUnknownTicker tests that only registered tickers are served.
+func TestFREDUnknownTicker(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected int
+	}{
+		{"/api/v1/fred/latest/UNRATE", http.StatusNotFound},
+		{"/api/v1/fred/ticker/UNRATE", http.StatusNotFound},
+		{"/api/v1/fred/latest/fedfunds", http.StatusOK},
+		{"/api/v1/fred/ticker/FEDFUNDS", http.StatusOK},
+	}
+
+	srv := New(ws.NewHub())
+	srv.FREDClient = stubFREDClient{}
+	srv.Tickers = fred.NewRegistry()
+	srv.RegisterFiberRoutes()
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+		resp, err := srv.App.Test(req)
+		if err != nil {
+			t.Fatalf("%s: failed to execute request: %v", tt.path, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.expected, resp.StatusCode)
+		}
+	}
+}
+
+// TestFREDTickerTransform tests requesting transformed observations.
+func TestFREDTickerTransform(t *testing.T) {
+	srv := New(ws.NewHub())
+	srv.FREDClient = stubFREDClient{}
+	srv.Tickers = fred.NewRegistry()
+	srv.RegisterFiberRoutes()
+
+	tests := []struct {
+		query     string
+		expected  int
+		transform string
+	}{
+		{"?transform=yoy", http.StatusOK, fred.UnitsPercentChangeYearAgo},
+		{"?transform=pch", http.StatusOK, fred.UnitsPercentChange},
+		{"", http.StatusOK, fred.UnitsLevels},
+		{"?transform=qoq", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/fred/ticker/CPIAUCSL"+tt.query, nil)
+		resp, err := srv.App.Test(req)
+		if err != nil {
+			t.Fatalf("%s: failed to execute request: %v", tt.query, err)
+		}
+		var body struct {
+			Transform string `json:"transform"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.expected || body.Transform != tt.transform {
+			t.Errorf("%q: expected status %d and transform %q, got %d and %q",
+				tt.query, tt.expected, tt.transform, resp.StatusCode, body.Transform)
+		}
+	}
+}
+
+// TestRegisterTicker tests adding and removing FRED series through the
+// admin API.
+func TestRegisterTicker(t *testing.T) {
+	srv := New(ws.NewHub(), config.ServerConfig{AdminToken: "secret"})
+	srv.FREDClient = stubFREDClient{}
+	srv.Tickers = fred.NewRegistry()
+	srv.RegisterFiberRoutes()
+
+	resp := doAdminRequest(t, srv, http.MethodPost, "/api/admin/fred/tickers", "secret",
+		`{"symbol":"unrate","description":"Unemployment Rate"}`)
+	var series fred.Series
+	json.NewDecoder(resp.Body).Decode(&series)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if series != (fred.Series{Ticker: "UNRATE", Description: "Unemployment Rate", Units: "Percent"}) {
+		t.Errorf("Unexpected series: %+v", series)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/fred/tickers", nil)
+	resp, err := srv.App.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to execute request: %v", err)
+	}
+	var body struct {
+		Tickers []fred.Series `json:"tickers"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	resp.Body.Close()
+	if len(body.Tickers) != 7 || body.Tickers[6].Description != "Unemployment Rate" {
+		t.Errorf("Expected UNRATE to be listed, got %+v", body.Tickers)
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		expected int
+	}{
+		{"invalid ticker", http.MethodPost, "/api/admin/fred/tickers", `{"symbol":"UN RATE"}`, http.StatusBadRequest},
+		{"not on FRED", http.MethodPost, "/api/admin/fred/tickers", `{"symbol":"NOSUCHSERIES"}`, http.StatusUnprocessableEntity},
+		{"built-in", http.MethodDelete, "/api/admin/fred/tickers/WALCL", "", http.StatusConflict},
+		{"remove", http.MethodDelete, "/api/admin/fred/tickers/unrate", "", http.StatusOK},
+		{"removed", http.MethodDelete, "/api/admin/fred/tickers/UNRATE", "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		resp := doAdminRequest(t, srv, tt.method, tt.path, "secret", tt.body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expected, resp.StatusCode)
+		}
+	}
+	if srv.Tickers.Has("UNRATE") {
+		t.Error("Expected UNRATE to be removed")
+	}
+}
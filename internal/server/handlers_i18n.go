@@ -0,0 +1,40 @@
+package server
+
+import (
+	"macro-analyst/pkg/i18n"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// LangQueryParam selects the response language explicitly, ahead of
+	// Accept-Language, e.g. for WebSocket clients and embedded pages
+	LangQueryParam = "lang"
+
+	// langLocal is the context key holding the request's language
+	langLocal = "lang"
+)
+
+// localize selects the response language from the lang query parameter or
+// the Accept-Language header.
+func (s *FiberServer) localize(c *fiber.Ctx) error {
+	lang := i18n.Match(c.Query(LangQueryParam), c.Get(fiber.HeaderAcceptLanguage))
+	c.Locals(langLocal, lang)
+
+	c.Set(fiber.HeaderContentLanguage, string(lang))
+	c.Vary(fiber.HeaderAcceptLanguage)
+	return c.Next()
+}
+
+// requestLang returns the language selected for the request.
+func requestLang(c *fiber.Ctx) i18n.Lang {
+	if lang, ok := c.Locals(langLocal).(i18n.Lang); ok {
+		return lang
+	}
+	return i18n.Default
+}
+
+// translate returns the message for key in the request's language.
+func translate(c *fiber.Ctx, key string, args ...any) string {
+	return i18n.T(requestLang(c), key, args...)
+}
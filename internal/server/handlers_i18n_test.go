@@ -0,0 +1,86 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"macro-analyst/pkg/i18n"
+	"macro-analyst/pkg/usage"
+	"macro-analyst/pkg/ws"
+)
+
+// TestLocalizedTickerDescription tests that ticker descriptions follow the
+// lang query parameter, then Accept-Language.
+func TestLocalizedTickerDescription(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		acceptLanguage string
+		expected       i18n.Lang
+	}{
+		{"default", "", "", i18n.English},
+		{"accept language", "", "de-DE,de;q=0.9,en;q=0.5", i18n.German},
+		{"query overrides header", "?lang=vi", "de", i18n.Vietnamese},
+		{"unsupported", "", "fr-FR", i18n.English},
+	}
+
+	srv := New(ws.NewHub())
+	srv.FREDClient = stubFREDClient{}
+	srv.RegisterFiberRoutes()
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/fred/latest/WALCL"+tt.query, nil)
+		if tt.acceptLanguage != "" {
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+		}
+		resp, err := srv.App.Test(req)
+		if err != nil {
+			t.Fatalf("%s: failed to execute request: %v", tt.name, err)
+		}
+
+		var body struct {
+			Description string `json:"description"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("%s: failed to decode response: %v", tt.name, err)
+		}
+
+		if got := resp.Header.Get("Content-Language"); got != string(tt.expected) {
+			t.Errorf("%s: expected Content-Language %s, got %s", tt.name, tt.expected, got)
+		}
+		if want := i18n.T(tt.expected, i18n.TickerKey("WALCL")); body.Description != want {
+			t.Errorf("%s: expected description %q, got %q", tt.name, want, body.Description)
+		}
+	}
+}
+
+// TestLocalizedErrorMessage tests that error messages are translated.
+func TestLocalizedErrorMessage(t *testing.T) {
+	// Arrange
+	srv := New(ws.NewHub())
+	srv.Usage = usage.NewTracker()
+	srv.RegisterFiberRoutes()
+	req, _ := http.NewRequest(http.MethodGet, "/api/usage", nil)
+	req.Header.Set("Accept-Language", "de")
+
+	// Act
+	resp, err := srv.App.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to execute request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Assert
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected status %d, got %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+	var body map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if want := i18n.T(i18n.German, i18n.MsgAPIKeyRequired); body["error"] != want {
+		t.Errorf("Expected error %q, got %q", want, body["error"])
+	}
+}
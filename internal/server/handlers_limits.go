@@ -0,0 +1,54 @@
+package server
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"macro-analyst/internal/connlimit"
+	"macro-analyst/pkg/i18n"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+// releaseConnectionLocal is the context key holding the function that
+// frees a connection admitted by the ConnLimiter
+const releaseConnectionLocal = "releaseConnection"
+
+// limitConnections refuses WebSocket upgrades beyond the connection limits
+// with 429, before the client is registered with a Hub. Admitted
+// connections are released when their stream ends, or right away if the
+// upgrade does not happen.
+func (s *FiberServer) limitConnections(c *fiber.Ctx) error {
+	if s.ConnLimiter == nil {
+		return c.Next()
+	}
+
+	ip := c.IP()
+	release, err := s.ConnLimiter.Acquire(ip)
+	if err != nil {
+		if errors.Is(err, connlimit.ErrRateLimited) {
+			retryAfter := math.Ceil(s.ConnLimiter.RetryAfter(ip).Seconds())
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(retryAfter)))
+		}
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgTooManyConnections),
+		})
+	}
+
+	c.Locals(releaseConnectionLocal, release)
+	err = c.Next()
+	if c.Response().StatusCode() != fiber.StatusSwitchingProtocols {
+		release()
+	}
+	return err
+}
+
+// releaseConnection frees the connection of a stream admitted by
+// limitConnections.
+func releaseConnection(c *websocket.Conn) {
+	if release, ok := c.Locals(releaseConnectionLocal).(func()); ok {
+		release()
+	}
+}
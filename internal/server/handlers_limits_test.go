@@ -0,0 +1,55 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"macro-analyst/internal/connlimit"
+	"macro-analyst/pkg/ws"
+)
+
+// TestStreamUpgradeRateLimit tests that upgrades beyond the per-IP rate are
+// refused with 429 and a Retry-After header.
+func TestStreamUpgradeRateLimit(t *testing.T) {
+	srv := New(ws.NewHub())
+	srv.ConnLimiter = connlimit.New(connlimit.WithUpgradesPerMinute(2))
+	srv.RegisterFiberRoutes()
+
+	expected := []int{http.StatusUpgradeRequired, http.StatusUpgradeRequired, http.StatusTooManyRequests}
+	for idx, status := range expected {
+		req, _ := http.NewRequest(http.MethodGet, "/ws/prices", nil)
+		resp, err := srv.App.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to execute request: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != status {
+			t.Errorf("Request %d: expected status %d, got %d", idx+1, status, resp.StatusCode)
+		}
+		if status == http.StatusTooManyRequests && resp.Header.Get("Retry-After") == "" {
+			t.Error("Expected a Retry-After header")
+		}
+	}
+}
+
+// TestStreamConnectionReleasedWithoutUpgrade tests that requests that are
+// not upgraded do not hold on to a connection slot.
+func TestStreamConnectionReleasedWithoutUpgrade(t *testing.T) {
+	srv := New(ws.NewHub())
+	srv.ConnLimiter = connlimit.New(connlimit.WithMaxPerIP(1), connlimit.WithUpgradesPerMinute(0))
+	srv.RegisterFiberRoutes()
+
+	for idx := range 3 {
+		req, _ := http.NewRequest(http.MethodGet, "/ws/prices?interval=fast", nil)
+		resp, err := srv.App.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to execute request: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Request %d: expected status %d, got %d", idx+1, http.StatusBadRequest, resp.StatusCode)
+		}
+	}
+}
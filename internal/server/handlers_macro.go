@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"macro-analyst/internal/analytics"
+	"macro-analyst/internal/attribution"
+	"macro-analyst/internal/macro"
+	"macro-analyst/pkg/fred"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NetLiquidityHandler returns Fed net liquidity (total assets minus the
+// Treasury General Account and overnight reverse repos) on every date an
+// input was observed, with the latest value.
+//
+// Query parameters: start and end (YYYY-MM-DD, default the last year).
+func (s *FiberServer) NetLiquidityHandler(c *fiber.Ctx) error {
+	start, end, err := parseDateRange(c.Query("start"), c.Query("end"), DefaultAlignRange)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), AnalyticsTimeout)
+	defer cancel()
+
+	points, series, err := macro.FetchNetLiquidity(ctx, s.fredHistory(), start, end)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var latest *macro.Point
+	if len(points) > 0 {
+		latest = &points[len(points)-1]
+	}
+
+	return c.JSON(struct {
+		Units       string                     `json:"units"`
+		Series      []macro.Point              `json:"series"`
+		Latest      *macro.Point               `json:"latest"`
+		Attribution []*attribution.Attribution `json:"attribution,omitempty"`
+	}{macro.NetLiquidityUnits, points, latest, s.seriesAttribution(series)})
+}
+
+// MacroAlignedHandler returns several FRED series forward-filled onto a
+// common time grid, so the frontend can chart e.g. WALCL against the TGA and
+// reverse repos without joining them client-side. The response has the
+// shape of /api/analytics/align.
+//
+// Query parameters: tickers (comma-separated, e.g. WALCL,WTREGEN,RRPONTSYD),
+// start and end (YYYY-MM-DD, default the last year) and grid (daily, weekly
+// or monthly, default weekly).
+func (s *FiberServer) MacroAlignedHandler(c *fiber.Ctx) error {
+	names := splitSeriesIDs(c.Query("tickers"))
+	if len(names) == 0 || len(names) > MaxAlignSeries {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("tickers must list 1 to %d FRED tickers, e.g. WALCL,WTREGEN,RRPONTSYD", MaxAlignSeries),
+		})
+	}
+	if s.FREDClient == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "FRED API client not configured",
+		})
+	}
+
+	tickers := make([]fred.Ticker, len(names))
+	for idx, name := range names {
+		tickers[idx] = fred.Ticker(strings.ToUpper(name))
+		if !s.Tickers.Has(tickers[idx]) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": fmt.Sprintf("%v: %s", fred.ErrUnknownTicker, tickers[idx]),
+			})
+		}
+	}
+
+	start, end, err := parseDateRange(c.Query("start"), c.Query("end"), DefaultAlignRange)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), AnalyticsTimeout)
+	defer cancel()
+
+	aligned, series, err := macro.GetAlignedSeries(ctx, s.fredHistory(), tickers, macro.AlignOptions{
+		Grid:  c.Query("grid", analytics.GridWeekly),
+		Start: start,
+		End:   end,
+	})
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, analytics.ErrInvalidGrid) || errors.Is(err, analytics.ErrInvalidRange) {
+			status = fiber.StatusBadRequest
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(struct {
+		analytics.Alignment
+		Attribution []*attribution.Attribution `json:"attribution,omitempty"`
+	}{aligned, s.seriesAttribution(series)})
+}
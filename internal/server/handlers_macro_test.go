@@ -0,0 +1,139 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"macro-analyst/internal/analytics"
+	"macro-analyst/internal/attribution"
+	"macro-analyst/internal/macro"
+	"macro-analyst/pkg/ws"
+)
+
+// TestNetLiquidityHandler tests that net liquidity is returned in billions
+// with its latest value and the attribution of its inputs.
+func TestNetLiquidityHandler(t *testing.T) {
+	// Arrange
+	srv := New(ws.NewHub())
+	srv.FREDClient = seriesFREDClient{}
+	srv.Attribution = attribution.New(attribution.ModuleFRED)
+	srv.RegisterFiberRoutes()
+	req, _ := http.NewRequest(http.MethodGet, "/api/macro/net-liquidity?start=2023-12-01&end=2024-01-31", nil)
+
+	// Act
+	resp, err := srv.App.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to execute request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Assert
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	var body struct {
+		Units       string                     `json:"units"`
+		Series      []macro.Point              `json:"series"`
+		Latest      *macro.Point               `json:"latest"`
+		Attribution []*attribution.Attribution `json:"attribution"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	// Every input reads 7650000 on 2024-01-03: 7650 - 7650 - 7650000
+	expected := macro.Point{Date: "2024-01-03", Value: -7650000, TotalAssets: 7650, TGA: 7650, ReverseRepo: 7650000}
+	if body.Units != macro.NetLiquidityUnits || len(body.Series) != 2 {
+		t.Fatalf("Unexpected response: %+v", body)
+	}
+	if body.Latest == nil || *body.Latest != expected {
+		t.Errorf("Expected latest %+v, got %+v", expected, body.Latest)
+	}
+	if len(body.Attribution) != len(macro.NetLiquidityTickers) {
+		t.Errorf("Expected attribution of each input, got %d", len(body.Attribution))
+	}
+}
+
+// TestNetLiquidityHandlerErrors tests request validation and that the
+// route needs FRED.
+func TestNetLiquidityHandlerErrors(t *testing.T) {
+	withFRED := New(ws.NewHub())
+	withFRED.FREDClient = seriesFREDClient{}
+	withFRED.RegisterFiberRoutes()
+
+	withoutFRED := New(ws.NewHub())
+	withoutFRED.RegisterFiberRoutes()
+
+	tests := []struct {
+		name     string
+		srv      *FiberServer
+		query    string
+		expected int
+	}{
+		{"invalid date", withFRED, "?start=01/01/2024", http.StatusBadRequest},
+		{"no FRED", withoutFRED, "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, "/api/macro/net-liquidity"+tt.query, nil)
+		resp, err := tt.srv.App.Test(req)
+		if err != nil {
+			t.Fatalf("%s: failed to execute request: %v", tt.name, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expected, resp.StatusCode)
+		}
+	}
+}
+
+// TestMacroAlignedHandler tests aligning FRED tickers on the default weekly
+// grid.
+func TestMacroAlignedHandler(t *testing.T) {
+	srv := New(ws.NewHub())
+	srv.FREDClient = seriesFREDClient{}
+	srv.RegisterFiberRoutes()
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/macro/aligned?tickers=WALCL,wtregen,RRPONTSYD&start=2023-12-27&end=2024-01-10", nil)
+	resp, err := srv.App.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to execute request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	var body analytics.Alignment
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.Grid != analytics.GridWeekly || len(body.Dates) != 3 || len(body.Series) != 3 {
+		t.Fatalf("Unexpected alignment: %+v", body)
+	}
+	if series := body.Series[1]; series.ID != "fred:WTREGEN" || series.Values[2] == nil || *series.Values[2] != 7650000 {
+		t.Errorf("Expected the last observation to be carried forward, got %+v", series)
+	}
+
+	tests := []struct {
+		query    string
+		expected int
+	}{
+		{"", http.StatusBadRequest},
+		{"?tickers=WALCL,NOSUCH", http.StatusNotFound},
+		{"?tickers=WALCL&grid=hourly", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, "/api/macro/aligned"+tt.query, nil)
+		resp, err := srv.App.Test(req)
+		if err != nil {
+			t.Fatalf("%q: failed to execute request: %v", tt.query, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%q: expected status %d, got %d", tt.query, tt.expected, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DefaultPollWait is the longest a /api/poll request waits for a broadcast
+// unless PollWait is set; below common proxy idle timeouts.
+const DefaultPollWait = 25 * time.Second
+
+// PollHandler returns the broadcasts newer than since_seq, the same messages
+// /ws/prices clients receive, as a last-resort transport where neither
+// WebSockets nor server-sent events get through. Without newer broadcasts
+// it waits up to wait seconds (at most PollWait) for the next one.
+func (s *FiberServer) PollHandler(c *fiber.Ctx) error {
+	maxWait := s.PollWait
+	if maxWait <= 0 {
+		maxWait = DefaultPollWait
+	}
+
+	sinceSeq, err := strconv.ParseUint(c.Query("since_seq", "0"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "since_seq must be a non-negative integer",
+		})
+	}
+
+	wait := maxWait
+	if raw := c.Query("wait"); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds < 0 || time.Duration(seconds)*time.Second > maxWait {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("wait must be 0 to %d seconds", int(maxWait.Seconds())),
+			})
+		}
+		wait = time.Duration(seconds) * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), wait)
+	defer cancel()
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(s.Hub.PollBroadcasts(ctx, sinceSeq))
+}
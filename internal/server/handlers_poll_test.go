@@ -0,0 +1,60 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"macro-analyst/pkg/ws"
+)
+
+// TestPollHandler tests that polls return the broadcasts after since_seq
+// and validate their parameters.
+func TestPollHandler(t *testing.T) {
+	hub := ws.NewHub()
+	go hub.Run()
+	srv := New(hub)
+	srv.PollWait = 5 * time.Second
+	srv.RegisterFiberRoutes()
+
+	for _, message := range []string{`{"type":"heartbeat","timestamp":1}`, `{"type":"heartbeat","timestamp":2}`} {
+		hub.Publish([]byte(message))
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected int
+		messages int
+	}{
+		{"all", "?wait=0", http.StatusOK, 2},
+		{"since", "?since_seq=1&wait=0", http.StatusOK, 1},
+		{"nothing newer", "?since_seq=2&wait=0", http.StatusOK, 0},
+		{"invalid since_seq", "?since_seq=-1", http.StatusBadRequest, 0},
+		{"wait too long", "?wait=60", http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, "/api/poll"+tt.query, nil)
+		resp, err := srv.App.Test(req)
+		if err != nil {
+			t.Fatalf("%s: failed to execute request: %v", tt.name, err)
+		}
+
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expected, resp.StatusCode)
+		}
+		if resp.StatusCode == http.StatusOK {
+			var page ws.BroadcastPage
+			if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+				t.Fatalf("%s: failed to decode response: %v", tt.name, err)
+			}
+			if len(page.Messages) != tt.messages || page.LastSeq != 2 {
+				t.Errorf("%s: expected %d messages, got %+v", tt.name, tt.messages, page)
+			}
+		}
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"errors"
+	"log"
+
+	"macro-analyst/pkg/usage"
+	"macro-analyst/pkg/ws"
+
+	"github.com/gofiber/contrib/websocket"
+)
+
+// handleRawStream relays a raw Binance stream such as "btcusdt@trade" to the
+// client. Clients of the same stream share one upstream connection.
+func (s *FiberServer) handleRawStream(c *websocket.Conn) {
+	defer releaseConnection(c)
+
+	stream := c.Params("stream")
+	user := usage.KeyID(c.Query(APIKeyQueryParam, c.Headers(APIKeyHeader)))
+
+	sub, err := s.RawProxy.Subscribe(user, stream)
+	if err != nil {
+		closeCode := websocket.CloseInternalServerErr
+		if errors.Is(err, ws.ErrRawStreamLimit) || errors.Is(err, ws.ErrInvalidRawStream) {
+			closeCode = websocket.ClosePolicyViolation
+		}
+		c.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(closeCode, err.Error()))
+		return
+	}
+	defer sub.Close()
+
+	// Detect the client going away while we are blocked on upstream data
+	clientGone := make(chan struct{})
+	go func() {
+		defer close(clientGone)
+		for {
+			if _, _, err := c.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-clientGone:
+			return
+		case message, ok := <-sub.C:
+			if !ok {
+				c.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseGoingAway, "upstream stream closed"))
+				return
+			}
+			if err := c.WriteMessage(websocket.TextMessage, message); err != nil {
+				log.Printf("Error relaying raw stream %s: %v", stream, err)
+				return
+			}
+			if s.Usage != nil {
+				s.Usage.RecordMessage(user, len(message))
+			}
+		}
+	}
+}
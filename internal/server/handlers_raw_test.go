@@ -0,0 +1,66 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"macro-analyst/internal/config"
+	"macro-analyst/pkg/ws"
+)
+
+// TestRawStreamRequiresAPIKey tests that the raw stream proxy rejects
+// requests without a configured API key.
+func TestRawStreamRequiresAPIKey(t *testing.T) {
+	srv := New(ws.NewHub(), config.ServerConfig{APIKeys: []string{"power-user"}})
+	srv.RawProxy = ws.NewRawProxy()
+	srv.RegisterFiberRoutes()
+
+	tests := []struct {
+		name     string
+		key      string
+		expected int
+	}{
+		{"missing key", "", http.StatusUnauthorized},
+		{"unknown key", "guess", http.StatusUnauthorized},
+		// A valid key passes authentication and reaches the upgrade handler
+		{"valid key", "power-user", http.StatusUpgradeRequired},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, "/ws/raw/btcusdt@trade", nil)
+		if tt.key != "" {
+			req.Header.Set(APIKeyHeader, tt.key)
+		}
+
+		resp, err := srv.App.Test(req)
+		if err != nil {
+			t.Fatalf("%s: failed to execute request: %v", tt.name, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expected, resp.StatusCode)
+		}
+	}
+}
+
+// TestRawStreamDisabledWithoutAPIKeys tests that the raw stream proxy is
+// not registered when no API keys are configured.
+func TestRawStreamDisabledWithoutAPIKeys(t *testing.T) {
+	srv := New(ws.NewHub())
+	srv.RawProxy = ws.NewRawProxy()
+	srv.RegisterFiberRoutes()
+
+	req, _ := http.NewRequest(http.MethodGet, "/ws/raw/btcusdt@trade", nil)
+	req.Header.Set(APIKeyHeader, "anything")
+
+	resp, err := srv.App.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to execute request: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
@@ -0,0 +1,13 @@
+package server
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SessionsHandler returns the current US market session, the open FRED
+// release windows and whether the server is in a quiet window.
+func (s *FiberServer) SessionsHandler(c *fiber.Ctx) error {
+	return c.JSON(s.Sessions.Status(time.Now().UTC()))
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"macro-analyst/internal/sessions"
+	"macro-analyst/pkg/ws"
+)
+
+// TestSessionsHandler tests the market session status.
+func TestSessionsHandler(t *testing.T) {
+	// Arrange
+	srv := New(ws.NewHub())
+	srv.Sessions = sessions.New()
+	srv.RegisterFiberRoutes()
+	req, _ := http.NewRequest(http.MethodGet, "/api/sessions", nil)
+
+	// Act
+	resp, err := srv.App.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to execute request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Assert
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var body sessions.Status
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.Session == "" || body.NextOpen.IsZero() || body.Releases == nil {
+		t.Errorf("Expected a session, next open and release windows, got %+v", body)
+	}
+}
@@ -0,0 +1,252 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"macro-analyst/internal/analytics"
+	"macro-analyst/internal/dashboard"
+	"macro-analyst/internal/share"
+	"macro-analyst/internal/softdelete"
+	"macro-analyst/pkg/i18n"
+	"macro-analyst/pkg/ws"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// shareScopeLocal is the context key holding the verified share scope
+	shareScopeLocal = "shareScope"
+
+	// sharedDashboardLocal is the context key holding the shared dashboard
+	sharedDashboardLocal = "sharedDashboard"
+)
+
+var (
+	// errWidgetOutOfRange is returned when sharing a widget the dashboard does not have
+	errWidgetOutOfRange = errors.New("widget index out of range")
+
+	// errWidgetNotShared is returned when reading a widget outside the share scope
+	errWidgetNotShared = errors.New("widget is not shared")
+
+	// errNotChart is returned when requesting chart data of another widget type
+	errNotChart = errors.New("widget is not a chart")
+)
+
+// ShareRequest is the body of POST /api/dashboards/:id/share.
+type ShareRequest struct {
+	// Widget limits the link to the widget at this index, e.g. one chart;
+	// the whole dashboard is shared when omitted
+	Widget *int `json:"widget"`
+
+	// ExpiresInSeconds is how long the link stays valid (default 7 days,
+	// at most 90 days)
+	ExpiresInSeconds int `json:"expires_in_seconds"`
+}
+
+// SharedDashboard is what a share link grants read-only access to.
+type SharedDashboard struct {
+	Dashboard dashboard.Dashboard `json:"dashboard"`
+	Widget    *int                `json:"widget,omitempty"` // Index of the one shared widget
+	ExpiresAt time.Time           `json:"expires_at"`
+}
+
+// ShareDashboardHandler issues a signed, expiring link granting read-only
+// access to one of the caller's dashboards or one of its widgets.
+func (s *FiberServer) ShareDashboardHandler(c *fiber.Ctx) error {
+	d, err := s.ownedDashboard(c)
+	if err != nil {
+		return c.Status(dashboardErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var req ShareRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": translate(c, i18n.MsgInvalidBody),
+			})
+		}
+	}
+	if req.Widget != nil && (*req.Widget < 0 || *req.Widget >= len(d.Widgets)) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": errWidgetOutOfRange.Error(),
+		})
+	}
+
+	ttl := share.DefaultTTL
+	if req.ExpiresInSeconds != 0 {
+		ttl = time.Duration(req.ExpiresInSeconds) * time.Second
+	}
+	if ttl <= 0 || ttl > share.MaxTTL {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("expires_in_seconds must be 1 to %d", int(share.MaxTTL.Seconds())),
+		})
+	}
+
+	scope := share.Scope{
+		Dashboard: d.ID,
+		Widget:    req.Widget,
+		ExpiresAt: time.Now().Add(ttl).Unix(),
+	}
+	token, err := s.Shares.Sign(scope)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"token":      token,
+		"url":        "/api/shared/" + token,
+		"ws_url":     "/ws/shared/" + token,
+		"expires_at": scope.Expiry(),
+	})
+}
+
+// SharedDashboardHandler returns the dashboard, or the one widget, a share
+// link grants access to. The owner is not disclosed.
+func (s *FiberServer) SharedDashboardHandler(c *fiber.Ctx) error {
+	scope := c.Locals(shareScopeLocal).(share.Scope)
+	d := c.Locals(sharedDashboardLocal).(dashboard.Dashboard)
+
+	return c.JSON(SharedDashboard{
+		Dashboard: d,
+		Widget:    scope.Widget,
+		ExpiresAt: scope.Expiry(),
+	})
+}
+
+// SharedChartHandler returns the series of a shared chart widget aligned like
+// /api/analytics/align, on the widget's grid unless grid is given.
+//
+// Query parameters: start and end (YYYY-MM-DD, default the last year) and grid.
+func (s *FiberServer) SharedChartHandler(c *fiber.Ctx) error {
+	scope := c.Locals(shareScopeLocal).(share.Scope)
+	d := c.Locals(sharedDashboardLocal).(dashboard.Dashboard)
+
+	index, err := c.ParamsInt("widget")
+	widget, exists := sharedWidget(scope, d, index)
+	if err != nil || !exists {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": errWidgetNotShared.Error(),
+		})
+	}
+	if widget.Type != dashboard.WidgetChart || len(widget.Series) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": errNotChart.Error(),
+		})
+	}
+
+	grid := widget.Grid
+	if grid == "" {
+		grid = analytics.GridDaily
+	}
+	return s.respondAligned(c, widget.Series, grid)
+}
+
+// handleSharedStream streams live prices restricted to the crypto symbols of
+// the shared scope.
+func (s *FiberServer) handleSharedStream(c *websocket.Conn) {
+	defer releaseConnection(c)
+
+	client := &ws.Client{
+		Hub:       s.Hub,
+		Conn:      c,
+		Send:      make(chan []byte, s.sendBufferSize),
+		Encoding:  ws.EncodingFromSubprotocol(c.Subprotocol()),
+		RemoteIP:  c.IP(),
+		UserAgent: c.Headers(fiber.HeaderUserAgent),
+		Stream:    "share",
+		Symbols:   sharedSymbols(c.Locals(sharedDashboardLocal).(dashboard.Dashboard)),
+	}
+
+	pumpClient(s.Hub, client)
+}
+
+// requireShareToken verifies the share token in the path and loads the
+// shared dashboard, limited to the widgets within the scope.
+func (s *FiberServer) requireShareToken(c *fiber.Ctx) error {
+	scope, err := s.Shares.Verify(c.Params("token"))
+	var d dashboard.Dashboard
+	if err == nil {
+		d, err = s.sharedDashboard(scope)
+	}
+	if err != nil {
+		return c.Status(shareErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	c.Locals(shareScopeLocal, scope)
+	c.Locals(sharedDashboardLocal, d)
+	return c.Next()
+}
+
+// sharedDashboard loads the dashboard of a share scope without its owner,
+// with only the shared widget if the scope names one.
+func (s *FiberServer) sharedDashboard(scope share.Scope) (dashboard.Dashboard, error) {
+	d, exists := dashboard.FindTemplate(scope.Dashboard)
+	if !exists {
+		if d, exists = s.Dashboards.Get(scope.Dashboard); !exists {
+			return d, softdelete.ErrNotFound
+		}
+	}
+	d.Owner = ""
+
+	if scope.Widget != nil {
+		if *scope.Widget >= len(d.Widgets) {
+			return d, errWidgetNotShared
+		}
+		d.Widgets = []dashboard.Widget{d.Widgets[*scope.Widget]}
+	}
+	return d, nil
+}
+
+// sharedWidget returns the widget at index of a dashboard loaded for the
+// scope. A dashboard limited to one widget holds it as its only widget.
+func sharedWidget(scope share.Scope, d dashboard.Dashboard, index int) (dashboard.Widget, bool) {
+	switch {
+	case !scope.Includes(index):
+		return dashboard.Widget{}, false
+	case scope.Widget != nil:
+		return d.Widgets[0], true
+	case index >= 0 && index < len(d.Widgets):
+		return d.Widgets[index], true
+	}
+	return dashboard.Widget{}, false
+}
+
+// sharedSymbols returns the crypto symbols shown by a dashboard's widgets.
+func sharedSymbols(d dashboard.Dashboard) map[string]bool {
+	symbols := make(map[string]bool)
+	for _, widget := range d.Widgets {
+		for _, symbol := range widget.Symbols {
+			symbols[strings.ToUpper(symbol)] = true
+		}
+		for _, id := range widget.Series {
+			if symbol, found := strings.CutPrefix(id, analytics.SourceCrypto+":"); found {
+				symbols[strings.ToUpper(symbol)] = true
+			}
+		}
+	}
+	return symbols
+}
+
+// shareErrorStatus maps share errors to HTTP status codes.
+func shareErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, share.ErrInvalidToken):
+		return fiber.StatusUnauthorized
+	case errors.Is(err, share.ErrExpired):
+		return fiber.StatusGone
+	case errors.Is(err, softdelete.ErrNotFound), errors.Is(err, errWidgetNotShared):
+		return fiber.StatusNotFound
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"macro-analyst/internal/analytics"
+	"macro-analyst/internal/dashboard"
+	"macro-analyst/internal/share"
+	"macro-analyst/internal/softdelete"
+)
+
+// withShares gives the server stubbed history, dashboards and a share link signer.
+func withShares(srv *FiberServer) {
+	srv.FREDClient = seriesFREDClient{}
+	srv.CryptoHistory = stubCryptoHistory
+	srv.Dashboards = softdelete.New[dashboard.Dashboard]()
+	srv.Shares = share.NewSigner([]byte("test-secret"))
+}
+
+// shareDashboard creates a share link and returns its token.
+func shareDashboard(t *testing.T, srv *FiberServer, id, key, body string) string {
+	t.Helper()
+
+	resp := doAPIRequest(t, srv, http.MethodPost, "/api/dashboards/"+id+"/share", key, body)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", resp.StatusCode)
+	}
+
+	var link struct {
+		Token string `json:"token"`
+		URL   string `json:"url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&link); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if link.URL != "/api/shared/"+link.Token {
+		t.Errorf("Unexpected share URL %q", link.URL)
+	}
+	return link.Token
+}
+
+// TestSharedDashboard tests that share links grant read-only access without
+// an API key and without disclosing the owner.
+func TestSharedDashboard(t *testing.T) {
+	srv := newAPITestServer(withShares)
+	created := createResource[dashboard.Dashboard](t, srv, "/api/dashboards", "alice-key", `{"name":"My liquidity","based_on":"liquidity"}`)
+	token := shareDashboard(t, srv, created.ID, "alice-key", "")
+
+	resp := doAPIRequest(t, srv, http.MethodGet, "/api/shared/"+token, "", "")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var shared SharedDashboard
+	if err := json.NewDecoder(resp.Body).Decode(&shared); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if shared.Dashboard.ID != created.ID || shared.Dashboard.Owner != "" || len(shared.Dashboard.Widgets) != len(created.Widgets) {
+		t.Errorf("Unexpected shared dashboard: %+v", shared.Dashboard)
+	}
+	if shared.Widget != nil || time.Until(shared.ExpiresAt) < share.DefaultTTL-time.Minute {
+		t.Errorf("Expected the whole dashboard shared for the default TTL, got %+v", shared)
+	}
+}
+
+// TestSharedChart tests that only the shared widget's chart data is served.
+func TestSharedChart(t *testing.T) {
+	srv := newAPITestServer(withShares)
+	created := createResource[dashboard.Dashboard](t, srv, "/api/dashboards", "alice-key", `{"name":"My liquidity","based_on":"liquidity"}`)
+	token := shareDashboard(t, srv, created.ID, "alice-key", `{"widget":0,"expires_in_seconds":3600}`)
+
+	resp := doAPIRequest(t, srv, http.MethodGet, "/api/shared/"+token+"/charts/0?start=2024-01-01&end=2024-01-31", "", "")
+	var body analytics.Alignment
+	json.NewDecoder(resp.Body).Decode(&body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if body.Grid != analytics.GridWeekly || len(body.Series) != 2 || body.Series[1].ID != "crypto:BTCUSDT" {
+		t.Errorf("Expected the widget's series on its weekly grid, got %+v", body)
+	}
+
+	// The live stream of a scope is limited to the symbols of its widgets
+	d, _ := srv.sharedDashboard(share.Scope{Dashboard: created.ID, Widget: new(int)})
+	if symbols := sharedSymbols(d); len(symbols) != 1 || !symbols["BTCUSDT"] {
+		t.Errorf("Expected only BTCUSDT to be streamed, got %v", symbols)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected int
+	}{
+		{"other widget", "/api/shared/" + token + "/charts/2", http.StatusNotFound},
+		{"invalid widget", "/api/shared/" + token + "/charts/first", http.StatusNotFound},
+		{"forged token", "/api/shared/" + strings.Replace(token, ".", ".x", 1), http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		resp := doAPIRequest(t, srv, http.MethodGet, tt.path, "", "")
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expected, resp.StatusCode)
+		}
+	}
+}
+
+// TestShareErrors tests that links are only issued by the owner and stop
+// working once expired or once the dashboard is deleted.
+func TestShareErrors(t *testing.T) {
+	srv := newAPITestServer(withShares)
+	created := createResource[dashboard.Dashboard](t, srv, "/api/dashboards", "alice-key", `{"name":"Mine","based_on":"crypto"}`)
+
+	tests := []struct {
+		name     string
+		key      string
+		body     string
+		expected int
+	}{
+		{"other key", "bob-key", "", http.StatusForbidden},
+		{"without key", "", "", http.StatusUnauthorized},
+		{"widget out of range", "alice-key", `{"widget":9}`, http.StatusBadRequest},
+		{"negative expiry", "alice-key", `{"expires_in_seconds":-1}`, http.StatusBadRequest},
+		{"expiry too long", "alice-key", `{"expires_in_seconds":99999999}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		resp := doAPIRequest(t, srv, http.MethodPost, "/api/dashboards/"+created.ID+"/share", tt.key, tt.body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expected, resp.StatusCode)
+		}
+	}
+
+	expired, _ := srv.Shares.Sign(share.Scope{Dashboard: created.ID, ExpiresAt: time.Now().Add(-time.Minute).Unix()})
+	resp := doAPIRequest(t, srv, http.MethodGet, "/api/shared/"+expired, "", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusGone {
+		t.Errorf("Expected status 410 for an expired link, got %d", resp.StatusCode)
+	}
+
+	token := shareDashboard(t, srv, created.ID, "alice-key", "")
+	srv.Dashboards.Delete(created.ID)
+	resp = doAPIRequest(t, srv, http.MethodGet, "/api/shared/"+token, "", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 after deleting the dashboard, got %d", resp.StatusCode)
+	}
+}
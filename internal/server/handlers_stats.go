@@ -0,0 +1,15 @@
+package server
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// StatsHandler returns the current resource usage, the uptime of each
+// component and the recorded history for capacity planning.
+func (s *FiberServer) StatsHandler(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"current":        s.Stats.Sample(),
+		"uptime_seconds": s.Stats.Uptime(),
+		"history":        s.Stats.History(),
+	})
+}
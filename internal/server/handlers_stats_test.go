@@ -0,0 +1,66 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"macro-analyst/internal/config"
+	"macro-analyst/internal/stats"
+	"macro-analyst/pkg/ws"
+)
+
+// TestStatsHandler tests that the stats endpoint reports current usage and history.
+func TestStatsHandler(t *testing.T) {
+	// Arrange
+	hub := ws.NewHub()
+	srv := New(hub, config.ServerConfig{AdminToken: "secret"})
+	srv.Stats = stats.NewCollector(hub, nil)
+	srv.Stats.Record()
+	srv.RegisterFiberRoutes()
+
+	// Act
+	resp := doAdminRequest(t, srv, http.MethodGet, "/api/admin/stats", "secret", "")
+	defer resp.Body.Close()
+
+	// Assert
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var body struct {
+		Current       stats.Sample       `json:"current"`
+		UptimeSeconds map[string]float64 `json:"uptime_seconds"`
+		History       []stats.Sample     `json:"history"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.Current.Goroutines <= 0 {
+		t.Errorf("Expected goroutines > 0, got %d", body.Current.Goroutines)
+	}
+	if _, exists := body.UptimeSeconds["server"]; !exists {
+		t.Errorf("Expected server uptime, got %v", body.UptimeSeconds)
+	}
+	if len(body.History) != 1 {
+		t.Errorf("Expected 1 history sample, got %d", len(body.History))
+	}
+}
+
+// TestStatsRequiresToken tests that the stats endpoint is admin-only.
+func TestStatsRequiresToken(t *testing.T) {
+	// Arrange
+	hub := ws.NewHub()
+	srv := New(hub, config.ServerConfig{AdminToken: "secret"})
+	srv.Stats = stats.NewCollector(hub, nil)
+	srv.RegisterFiberRoutes()
+
+	// Act
+	resp := doAdminRequest(t, srv, http.MethodGet, "/api/admin/stats", "", "")
+	defer resp.Body.Close()
+
+	// Assert
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+}
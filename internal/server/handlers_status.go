@@ -0,0 +1,77 @@
+package server
+
+import (
+	"time"
+
+	"macro-analyst/internal/fredstore"
+	"macro-analyst/pkg/feedlog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DefaultFeedHistoryWindow is the period /api/status/feed-history covers
+// without a since parameter
+const DefaultFeedHistoryWindow = 24 * time.Hour
+
+// FeedHistoryResponse lists the feed connection events of a period with
+// the uptime of each feed in it.
+type FeedHistoryResponse struct {
+	Since  time.Time            `json:"since"`
+	Until  time.Time            `json:"until"`
+	Feeds  []feedlog.FeedUptime `json:"feeds"`
+	Events []feedlog.Event      `json:"events"`
+}
+
+// StatusResponse is the progress of the server's startup work.
+type StatusResponse struct {
+	// FREDHistory is how far backfilling the FRED history of derived
+	// metrics got; until it is done, their first requests fetch from FRED
+	FREDHistory *fredstore.Progress `json:"fred_history,omitempty"`
+}
+
+// StatusHandler reports the progress of the server's startup work, such as
+// seeding the FRED history.
+func (s *FiberServer) StatusHandler(c *fiber.Ctx) error {
+	var status StatusResponse
+	if s.FREDHistory != nil {
+		progress := s.FREDHistory.Progress()
+		status.FREDHistory = &progress
+	}
+	return c.JSON(status)
+}
+
+// FeedHistoryHandler returns when the Binance feeds connected, disconnected
+// and reconnected since the RFC 3339 time since (default: the last 24
+// hours), optionally only for one feed, and the uptime of each feed.
+func (s *FiberServer) FeedHistoryHandler(c *fiber.Ctx) error {
+	until := time.Now()
+	since := until.Add(-DefaultFeedHistoryWindow)
+	if raw := c.Query("since"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil || parsed.After(until) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "since must be a past RFC 3339 time, e.g. 2024-02-20T00:00:00Z",
+			})
+		}
+		since = parsed
+	}
+
+	feed := c.Query("feed")
+	uptimes := s.FeedHistory.Uptime(since, until)
+	if feed != "" {
+		filtered := []feedlog.FeedUptime{}
+		for _, uptime := range uptimes {
+			if uptime.Feed == feed {
+				filtered = append(filtered, uptime)
+			}
+		}
+		uptimes = filtered
+	}
+
+	return c.JSON(FeedHistoryResponse{
+		Since:  since,
+		Until:  until,
+		Feeds:  uptimes,
+		Events: s.FeedHistory.Events(since, feed),
+	})
+}
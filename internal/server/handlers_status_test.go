@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"macro-analyst/internal/fredstore"
+	"macro-analyst/pkg/feedlog"
+	"macro-analyst/pkg/fred"
+	"macro-analyst/pkg/ws"
+)
+
+// TestFeedHistoryHandler tests that the feed history lists the events and
+// uptime of the requested feeds and validates since.
+func TestFeedHistoryHandler(t *testing.T) {
+	srv := New(ws.NewHub())
+	srv.FeedHistory = feedlog.New()
+	srv.FeedHistory.Record("blue", feedlog.Connected, "", 6)
+	srv.FeedHistory.Record("green", feedlog.Connected, "", 6)
+	srv.FeedHistory.Record("blue", feedlog.Disconnected, "connection reset by peer", 0)
+	srv.RegisterFiberRoutes()
+
+	tests := []struct {
+		name     string
+		query    string
+		expected int
+		feeds    int
+		events   int
+	}{
+		{"default window", "", http.StatusOK, 2, 3},
+		{"one feed", "?feed=blue", http.StatusOK, 1, 2},
+		{"since", "?since=2000-01-01T00:00:00Z", http.StatusOK, 2, 3},
+		{"invalid since", "?since=yesterday", http.StatusBadRequest, 0, 0},
+		{"future since", "?since=2999-01-01T00:00:00Z", http.StatusBadRequest, 0, 0},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, "/api/status/feed-history"+tt.query, nil)
+		resp, err := srv.App.Test(req)
+		if err != nil {
+			t.Fatalf("%s: failed to execute request: %v", tt.name, err)
+		}
+
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expected, resp.StatusCode)
+		}
+		if resp.StatusCode == http.StatusOK {
+			var history FeedHistoryResponse
+			if err := json.NewDecoder(resp.Body).Decode(&history); err != nil {
+				t.Fatalf("%s: failed to decode response: %v", tt.name, err)
+			}
+			if len(history.Feeds) != tt.feeds || len(history.Events) != tt.events {
+				t.Errorf("%s: expected %d feeds and %d events, got %+v", tt.name, tt.feeds, tt.events, history)
+			}
+		}
+		resp.Body.Close()
+	}
+}
+
+// TestStatusHandler tests that the status reports FRED history seeding
+// once there is a history to seed.
+func TestStatusHandler(t *testing.T) {
+	srv := newAPITestServer(func(srv *FiberServer) {
+		srv.FREDHistory = fredstore.New(seriesFREDClient{})
+	})
+
+	var status StatusResponse
+	resp := doAPIRequest(t, srv, http.MethodGet, "/api/status", "alice-key", "")
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	resp.Body.Close()
+	if status.FREDHistory == nil || status.FREDHistory.State != fredstore.SeedPending {
+		t.Fatalf("Expected pending FRED history seeding, got %+v", status.FREDHistory)
+	}
+
+	srv.FREDHistory.Seed(context.Background(), []fred.Ticker{fred.TickerWALCL}, time.Now().AddDate(-1, 0, 0))
+	resp = doAPIRequest(t, srv, http.MethodGet, "/api/status", "alice-key", "")
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	resp.Body.Close()
+	if status.FREDHistory.State != fredstore.SeedDone || status.FREDHistory.Done != 1 || len(status.FREDHistory.Failed) != 0 {
+		t.Errorf("Expected FRED history seeding of 1 ticker to be done, got %+v", status.FREDHistory)
+	}
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"macro-analyst/pkg/ws"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// MaxStreamIntervalSeconds is the longest interval between updates a
+	// client may request from /ws/prices.
+	MaxStreamIntervalSeconds = 60
+
+	// DefaultStreamDecimals is the rounding of reduced-rate streams that do
+	// not ask for one.
+	DefaultStreamDecimals = 2
+
+	// MaxStreamDecimals is the most decimal places a client may request.
+	MaxStreamDecimals = 8
+
+	// streamOptionsLocal is the context key holding the parsed stream options
+	streamOptionsLocal = "streamOptions"
+)
+
+// StreamOptions configure a /ws/prices stream: the reduced-rate mode for
+// screen-reader-friendly dashboards and the number format of prices.
+type StreamOptions struct {
+	// Interval is the minimum time between two updates of a symbol,
+	// zero for every update
+	Interval time.Duration
+
+	// Decimals is the number of decimal places prices and changes are
+	// rounded to in reduced-rate mode
+	Decimals int
+
+	// Numbers is the format of prices, e.g. strings for clients parsing
+	// them into decimal types
+	Numbers ws.NumberFormat
+
+	// Topics are the topic patterns the client starts with, every topic
+	// when empty
+	Topics []string
+
+	// Encoding is the wire format, for clients that cannot negotiate a
+	// subprotocol; empty keeps the negotiated one
+	Encoding ws.Encoding
+
+	// Currency is the currency prices are converted to, e.g. "EUR"; empty
+	// keeps USDT
+	Currency string
+}
+
+// parseStreamOptions reads the interval (seconds), decimals, numbers,
+// encoding, currency and topics query parameters of a price stream.
+// Rounding only applies with an interval.
+func parseStreamOptions(c *fiber.Ctx) (StreamOptions, error) {
+	var opts StreamOptions
+	numbers, err := ws.ParseNumberFormat(c.Query("numbers"))
+	if err != nil {
+		return opts, err
+	}
+	opts.Numbers = numbers
+
+	if opts.Encoding, err = ws.ParseEncoding(c.Query("encoding")); err != nil {
+		return opts, err
+	}
+	opts.Currency = strings.ToUpper(c.Query("currency"))
+
+	if raw := c.Query("topics"); raw != "" {
+		if opts.Topics, err = ws.ParseTopics(raw); err != nil {
+			return opts, err
+		}
+	}
+
+	if c.Query("interval") == "" {
+		if c.Query("decimals") != "" {
+			return opts, errors.New("decimals requires interval")
+		}
+		return opts, nil
+	}
+
+	seconds, err := strconv.Atoi(c.Query("interval"))
+	if err != nil || seconds < 1 || seconds > MaxStreamIntervalSeconds {
+		return opts, fmt.Errorf("interval must be 1 to %d seconds", MaxStreamIntervalSeconds)
+	}
+	opts.Interval = time.Duration(seconds) * time.Second
+
+	opts.Decimals = DefaultStreamDecimals
+	if raw := c.Query("decimals"); raw != "" {
+		opts.Decimals, err = strconv.Atoi(raw)
+		if err != nil || opts.Decimals < 1 || opts.Decimals > MaxStreamDecimals {
+			return opts, fmt.Errorf("decimals must be 1 to %d", MaxStreamDecimals)
+		}
+	}
+	return opts, nil
+}
+
+// streamOptions validates the stream options of a /ws/prices request before
+// the upgrade, so invalid ones are refused with 400.
+func (s *FiberServer) streamOptions(c *fiber.Ctx) error {
+	opts, err := parseStreamOptions(c)
+	if err == nil && opts.Currency != "" {
+		err = s.checkCurrency(opts.Currency)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	c.Locals(streamOptionsLocal, opts)
+	return c.Next()
+}
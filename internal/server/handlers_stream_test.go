@@ -0,0 +1,48 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"macro-analyst/pkg/ws"
+)
+
+// TestStreamOptionsValidation tests that invalid reduced-rate options are
+// refused before the WebSocket upgrade.
+func TestStreamOptionsValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected int
+	}{
+		{"no options", "", http.StatusUpgradeRequired},
+		{"interval", "?interval=5", http.StatusUpgradeRequired},
+		{"interval and decimals", "?interval=5&decimals=1", http.StatusUpgradeRequired},
+		{"interval too long", "?interval=3600", http.StatusBadRequest},
+		{"interval not a number", "?interval=fast", http.StatusBadRequest},
+		{"decimals without interval", "?decimals=2", http.StatusBadRequest},
+		{"too many decimals", "?interval=5&decimals=12", http.StatusBadRequest},
+		{"string numbers", "?numbers=string", http.StatusUpgradeRequired},
+		{"scaled numbers with interval", "?numbers=scaled&interval=5", http.StatusUpgradeRequired},
+		{"unknown number format", "?numbers=decimal", http.StatusBadRequest},
+		{"topics", "?topics=prices,macro,orderbook:BTC*", http.StatusUpgradeRequired},
+		{"invalid topic", "?topics=prices,mac%20ro", http.StatusBadRequest},
+		{"msgpack encoding", "?encoding=msgpack", http.StatusUpgradeRequired},
+		{"unknown encoding", "?encoding=protobuf", http.StatusBadRequest},
+	}
+
+	srv := New(ws.NewHub())
+	srv.RegisterFiberRoutes()
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, "/ws/prices"+tt.query, nil)
+		resp, err := srv.App.Test(req)
+		if err != nil {
+			t.Fatalf("%s: failed to execute request: %v", tt.name, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expected, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+
+	"macro-analyst/internal/surprise"
+	"macro-analyst/pkg/i18n"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SurpriseHandler returns the rolling macro surprise index and the
+// releases it covers.
+func (s *FiberServer) SurpriseHandler(c *fiber.Ctx) error {
+	return c.JSON(s.Surprises.Index())
+}
+
+// ListExpectationsHandler returns the stored expectations, optionally
+// filtered by the ticker query parameter.
+func (s *FiberServer) ListExpectationsHandler(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"expectations": s.Surprises.List(c.Query("ticker")),
+	})
+}
+
+// SetExpectationHandler adds or replaces the expectation for one release.
+func (s *FiberServer) SetExpectationHandler(c *fiber.Ctx) error {
+	var req surprise.Expectation
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgInvalidBody),
+		})
+	}
+
+	stored, err := s.Surprises.Set(req)
+	if err != nil {
+		return c.Status(expectationErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(stored)
+}
+
+// ImportExpectationsHandler adds or replaces expectations from a JSON array,
+// in the same format as the expectations file. Nothing is stored if any
+// expectation is invalid.
+func (s *FiberServer) ImportExpectationsHandler(c *fiber.Ctx) error {
+	var req []surprise.Expectation
+	if err := json.Unmarshal(c.Body(), &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgInvalidExpectationsBody),
+		})
+	}
+
+	imported, err := s.Surprises.Import(req)
+	if err != nil {
+		return c.Status(expectationErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"imported": imported,
+	})
+}
+
+// DeleteExpectationHandler removes the expectation for one release.
+func (s *FiberServer) DeleteExpectationHandler(c *fiber.Ctx) error {
+	ticker, date := c.Params("ticker"), c.Params("date")
+
+	if err := s.Surprises.Delete(ticker, date); err != nil {
+		return c.Status(expectationErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"removed": ticker + " " + date,
+	})
+}
+
+// expectationErrorStatus maps surprise store errors to HTTP status codes.
+func expectationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, surprise.ErrInvalidExpectation):
+		return fiber.StatusBadRequest
+	case errors.Is(err, surprise.ErrNotFound):
+		return fiber.StatusNotFound
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"macro-analyst/internal/surprise"
+)
+
+// withSurprises gives the server an expectation store.
+func withSurprises(srv *FiberServer) {
+	srv.Surprises = surprise.NewStore()
+}
+
+// TestExpectationCRUD tests adding, listing and removing expectations.
+func TestExpectationCRUD(t *testing.T) {
+	srv := newAPITestServer(withSurprises)
+
+	resp := doAdminRequest(t, srv, http.MethodPost, "/api/admin/expectations", "secret",
+		`{"ticker":"cpiaucsl","date":"2024-05-01","consensus":313.1,"previous":312.2}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	resp = doAdminRequest(t, srv, http.MethodGet, "/api/admin/expectations?ticker=CPIAUCSL", "secret", "")
+	var body struct {
+		Expectations []surprise.Expectation `json:"expectations"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	resp.Body.Close()
+	if len(body.Expectations) != 1 || body.Expectations[0].Ticker != "CPIAUCSL" || *body.Expectations[0].Previous != 312.2 {
+		t.Errorf("Unexpected expectations: %+v", body.Expectations)
+	}
+
+	resp = doAdminRequest(t, srv, http.MethodDelete, "/api/admin/expectations/CPIAUCSL/2024-05-01", "secret", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	resp = doAdminRequest(t, srv, http.MethodDelete, "/api/admin/expectations/CPIAUCSL/2024-05-01", "secret", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", resp.StatusCode)
+	}
+}
+
+// TestExpectationErrors tests that malformed expectations are rejected.
+func TestExpectationErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{"invalid date", "/api/admin/expectations", `{"ticker":"CPIAUCSL","date":"May 2024"}`},
+		{"missing ticker", "/api/admin/expectations", `{"date":"2024-05-01"}`},
+		{"import not an array", "/api/admin/expectations/import", `{"ticker":"CPIAUCSL"}`},
+		{"import invalid entry", "/api/admin/expectations/import", `[{"ticker":"CPIAUCSL","date":"2024-05-01"},{"date":"2024-05-01"}]`},
+	}
+
+	srv := newAPITestServer(withSurprises)
+	for _, tt := range tests {
+		resp := doAdminRequest(t, srv, http.MethodPost, tt.path, "secret", tt.body)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", tt.name, resp.StatusCode)
+		}
+	}
+	if exps := srv.Surprises.List(""); len(exps) != 0 {
+		t.Errorf("Expected nothing stored, got %+v", exps)
+	}
+}
+
+// TestSurpriseHandler tests that imported releases show up in the index.
+func TestSurpriseHandler(t *testing.T) {
+	// Arrange
+	srv := newAPITestServer(withSurprises)
+	today := time.Now().UTC()
+	body, _ := json.Marshal([]map[string]any{
+		{"ticker": "CPIAUCSL", "date": today.AddDate(0, 0, -60).Format(surprise.DateLayout), "consensus": 10, "actual": 11},
+		{"ticker": "CPIAUCSL", "date": today.AddDate(0, 0, -30).Format(surprise.DateLayout), "consensus": 10, "actual": 9},
+		{"ticker": "CPIAUCSL", "date": today.Format(surprise.DateLayout), "consensus": 10, "actual": 13},
+	})
+	resp := doAdminRequest(t, srv, http.MethodPost, "/api/admin/expectations/import", "secret", string(body))
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected import status 200, got %d", resp.StatusCode)
+	}
+
+	// Act
+	resp = doAPIRequest(t, srv, http.MethodGet, "/api/analytics/surprise", "", "")
+	defer resp.Body.Close()
+
+	// Assert
+
+	var index surprise.Index
+	if err := json.NewDecoder(resp.Body).Decode(&index); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(index.Releases) != 3 || index.Value == nil || *index.Value <= 0 {
+		t.Errorf("Expected a positive index over 3 releases, got %+v", index)
+	}
+	if index.WindowDays != 90 {
+		t.Errorf("Expected a 90-day window, got %d", index.WindowDays)
+	}
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"crypto/subtle"
+
+	"macro-analyst/pkg/i18n"
+	"macro-analyst/pkg/usage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// APIKeyHeader carries the API key usage is accounted to
+	APIKeyHeader = "X-API-Key"
+
+	// APIKeyQueryParam carries the API key for WebSocket clients, since
+	// browsers cannot set headers on the upgrade request
+	APIKeyQueryParam = "api_key"
+
+	// usageKeyLocal is the context key holding the request's usage key ID
+	usageKeyLocal = "usageKeyID"
+)
+
+// apiKey returns the API key presented with the request, if any.
+func apiKey(c *fiber.Ctx) string {
+	if key := c.Get(APIKeyHeader); key != "" {
+		return key
+	}
+	return c.Query(APIKeyQueryParam)
+}
+
+// validAPIKey reports whether key is one of the configured API keys.
+func (s *FiberServer) validAPIKey(key string) bool {
+	for _, valid := range s.apiKeys {
+		if key != "" && subtle.ConstantTimeCompare([]byte(key), []byte(valid)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// requireAPIKey rejects requests without one of the configured API keys.
+func (s *FiberServer) requireAPIKey(c *fiber.Ctx) error {
+	if s.validAPIKey(apiKey(c)) {
+		return c.Next()
+	}
+
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+		"error": translate(c, i18n.MsgValidAPIKeyRequired),
+	})
+}
+
+// trackUsage records each request and its response size against the
+// caller's API key. Requests with keys that are not configured count as
+// anonymous, so made-up keys cannot add usage entries.
+func (s *FiberServer) trackUsage(c *fiber.Ctx) error {
+	keyID := usage.AnonymousKeyID
+	if key := apiKey(c); s.validAPIKey(key) {
+		keyID = usage.KeyID(key)
+	}
+	c.Locals(usageKeyLocal, keyID)
+
+	err := c.Next()
+
+	s.Usage.RecordRequest(keyID, len(c.Response().Body()))
+	return err
+}
+
+// UsageHandler returns the usage of the API key presented with the
+// request, which must be one of the configured keys.
+func (s *FiberServer) UsageHandler(c *fiber.Ctx) error {
+	key := apiKey(c)
+	if key == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgAPIKeyRequired),
+		})
+	}
+	if !s.validAPIKey(key) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": translate(c, i18n.MsgValidAPIKeyRequired),
+		})
+	}
+
+	return c.JSON(s.Usage.Usage(usage.KeyID(key)))
+}
+
+// AdminUsageHandler returns usage aggregated across all API keys.
+func (s *FiberServer) AdminUsageHandler(c *fiber.Ctx) error {
+	return c.JSON(s.Usage.Summary())
+}
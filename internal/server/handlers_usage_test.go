@@ -0,0 +1,82 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"macro-analyst/pkg/usage"
+)
+
+// withUsage gives the server a usage tracker.
+func withUsage(srv *FiberServer) {
+	srv.Usage = usage.NewTracker()
+}
+
+// TestUsageHandler tests that requests are accounted to the caller's key.
+func TestUsageHandler(t *testing.T) {
+	srv := newAPITestServer(withUsage)
+
+	doAPIRequest(t, srv, http.MethodGet, "/health", "alice-key", "").Body.Close()
+
+	resp := doAPIRequest(t, srv, http.MethodGet, "/api/usage?api_key=alice-key", "", "")
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var body usage.KeyUsage
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if body.KeyID != usage.KeyID("alice-key") {
+		t.Errorf("Expected key ID %q, got %q", usage.KeyID("alice-key"), body.KeyID)
+	}
+	// The usage request itself is recorded after its response is built
+	if body.Total.Requests != 1 || body.Total.Bytes == 0 {
+		t.Errorf("Expected 1 recorded request with bytes, got %+v", body.Total)
+	}
+}
+
+// TestUsageHandlerRequiresKey tests that /api/usage needs a configured
+// API key.
+func TestUsageHandlerRequiresKey(t *testing.T) {
+	srv := newAPITestServer(withUsage)
+
+	for _, key := range []string{"", "made-up-key"} {
+		resp := doAPIRequest(t, srv, http.MethodGet, "/api/usage", key, "")
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("%q: expected status %d, got %d", key, http.StatusUnauthorized, resp.StatusCode)
+		}
+	}
+}
+
+// TestAdminUsageHandler tests the aggregated admin usage endpoint, with
+// unknown keys counted as anonymous.
+func TestAdminUsageHandler(t *testing.T) {
+	srv := newAPITestServer(withUsage)
+
+	for _, key := range []string{"alice-key", "bob-key", "", "made-up-1", "made-up-2"} {
+		doAPIRequest(t, srv, http.MethodGet, "/health", key, "").Body.Close()
+	}
+
+	resp := doAdminRequest(t, srv, http.MethodGet, "/api/admin/usage", "secret", "")
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var body usage.Summary
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if body.Total.Requests != 5 || len(body.Keys) != 3 {
+		t.Errorf("Expected 5 requests from 3 keys, got %d from %d", body.Total.Requests, len(body.Keys))
+	}
+}
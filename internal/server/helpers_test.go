@@ -0,0 +1,84 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"macro-analyst/internal/config"
+	"macro-analyst/pkg/ws"
+)
+
+// newAPITestServer creates a server with two API keys, alice-key and
+// bob-key, and the admin token secret, lets setup configure it and
+// registers the routes.
+func newAPITestServer(setup func(srv *FiberServer)) *FiberServer {
+	srv := New(ws.NewHub(), config.ServerConfig{
+		APIKeys:    []string{"alice-key", "bob-key"},
+		AdminToken: "secret",
+	})
+	setup(srv)
+	srv.RegisterFiberRoutes()
+	return srv
+}
+
+// doAPIRequest executes a JSON request as the given API key, or
+// anonymously if key is empty.
+func doAPIRequest(t *testing.T, srv *FiberServer, method, path, key, body string) *http.Response {
+	t.Helper()
+
+	header := http.Header{}
+	if key != "" {
+		header.Set(APIKeyHeader, key)
+	}
+	return doRequest(t, srv, method, path, header, body)
+}
+
+// doAdminRequest executes a JSON request against the admin API with the
+// given bearer token, or without one if token is empty.
+func doAdminRequest(t *testing.T, srv *FiberServer, method, path, token, body string) *http.Response {
+	t.Helper()
+
+	header := http.Header{}
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+	return doRequest(t, srv, method, path, header, body)
+}
+
+// doRequest executes a JSON request with the given headers.
+func doRequest(t *testing.T, srv *FiberServer, method, path string, header http.Header, body string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(method, path, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header = header
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := srv.App.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to execute request: %v", err)
+	}
+	return resp
+}
+
+// createResource posts body to a collection as the given API key and
+// returns the created resource.
+func createResource[T any](t *testing.T, srv *FiberServer, path, key, body string) T {
+	t.Helper()
+
+	resp := doAPIRequest(t, srv, http.MethodPost, path, key, body)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", resp.StatusCode)
+	}
+
+	var created T
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return created
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Module is a part of the server that can be switched off, so one binary
+// can run as a lean price streamer or a macro API.
+type Module string
+
+const (
+	// ModuleCrypto covers the Binance feeds and the price streams
+	ModuleCrypto Module = "crypto"
+
+	// ModuleFRED covers the /api/v1/fred routes
+	ModuleFRED Module = "fred"
+
+	// ModuleAnalytics covers /api/analytics and user-defined formulas
+	ModuleAnalytics Module = "analytics"
+
+	// ModuleAlerts covers the stale feed alarm and macro surprise notices
+	ModuleAlerts Module = "alerts"
+
+	// ModuleAdmin covers the /api/admin routes
+	ModuleAdmin Module = "admin"
+)
+
+// Modules lists all modules, which are enabled by default.
+var Modules = []Module{ModuleCrypto, ModuleFRED, ModuleAnalytics, ModuleAlerts, ModuleAdmin}
+
+// ParseModules validates module names, ignoring case.
+func ParseModules(names []string) ([]Module, error) {
+	modules := make([]Module, 0, len(names))
+	for _, name := range names {
+		module := Module(strings.ToLower(name))
+		if !slices.Contains(Modules, module) {
+			return nil, fmt.Errorf("invalid module %q (expected one of %v)", name, Modules)
+		}
+		modules = append(modules, module)
+	}
+	return modules, nil
+}
+
+// ModuleEnabled reports whether a module is switched on.
+func (s *FiberServer) ModuleEnabled(module Module) bool {
+	return s.modules[module]
+}
+
+// ActiveModules returns the enabled modules that are also configured, in
+// the order of Modules: FRED needs an API key and admin a token.
+func (s *FiberServer) ActiveModules() []Module {
+	active := []Module{}
+	for _, module := range Modules {
+		switch {
+		case !s.ModuleEnabled(module):
+		case module == ModuleFRED && s.FREDClient == nil:
+		case module == ModuleAdmin && s.adminToken == "":
+		default:
+			active = append(active, module)
+		}
+	}
+	return active
+}
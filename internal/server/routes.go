@@ -1,9 +1,8 @@
 package server
 
 import (
-	"log"
-
-	"macro-analyst/internal/ws"
+	"macro-analyst/pkg/metrics"
+	"macro-analyst/pkg/ws"
 
 	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
@@ -26,29 +25,109 @@ func (s *FiberServer) RegisterFiberRoutes() {
 func (s *FiberServer) setupMiddleware() {
 	// CORS middleware for cross-origin requests
 	s.App.Use(cors.New(cors.Config{
-		AllowOrigins:     "*",
+		AllowOrigins:     s.corsOrigins,
 		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS,PATCH",
-		AllowHeaders:     "Accept,Authorization,Content-Type",
+		AllowHeaders:     "Accept,Accept-Language,Authorization,Content-Type,X-API-Key",
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
+
+	// Response language from ?lang= or Accept-Language
+	s.App.Use(s.localize)
+
+	// Per-key usage accounting
+	if s.Usage != nil {
+		s.App.Use(s.trackUsage)
+	}
 }
 
 // setupHTTPRoutes registers all HTTP routes.
 func (s *FiberServer) setupHTTPRoutes() {
 	s.App.Get("/", s.HelloWorldHandler)
 	s.App.Get("/health", s.HealthHandler)
+	s.App.Get("/metrics", s.MetricsHandler)
+	s.App.Get("/api/capabilities", s.CapabilitiesHandler)
 
 	// FRED API routes
 	if s.FREDClient != nil {
 		s.setupFREDRoutes()
 	}
+
+	// Long-polling fallback for clients that cannot hold a WebSocket
+	if s.ModuleEnabled(ModuleCrypto) && s.Hub.KeepsHistory() {
+		s.App.Get("/api/poll", s.requireJWT, s.PollHandler)
+	}
+
+	// Progress of the startup work
+	s.App.Get("/api/status", s.StatusHandler)
+
+	// Feed connection history explaining gaps in charts
+	if s.FeedHistory != nil {
+		s.App.Get("/api/status/feed-history", s.FeedHistoryHandler)
+	}
+
+	// Usage accounting routes
+	if s.Usage != nil {
+		s.App.Get("/api/usage", s.UsageHandler)
+	}
+
+	// Crypto feed routes
+	if s.Feeds != nil {
+		s.setupCryptoRoutes()
+	}
+
+	// Price alerts of authenticated users, who receive them on their
+	// price streams
+	if s.Alerts != nil && s.Feeds != nil && s.jwtVerifier != nil && s.ModuleEnabled(ModuleAlerts) {
+		alerts := s.App.Group("/api/alerts", s.requireJWT)
+		alerts.Get("/", s.ListAlertsHandler)
+		alerts.Post("/", s.CreateAlertHandler)
+		alerts.Get("/export", s.ExportAlertsHandler)
+		alerts.Post("/import", s.ImportAlertsHandler)
+		alerts.Delete("/:id", s.DeleteAlertHandler)
+	}
+
+	// User-defined formula routes
+	if s.Formulas != nil && s.ModuleEnabled(ModuleAnalytics) {
+		s.setupFormulaRoutes()
+	}
+
+	// Dashboard template and saved layout routes
+	if s.Dashboards != nil {
+		s.setupDashboardRoutes()
+	}
+
+	// Embeddable widget routes
+	if s.Embeds != nil {
+		s.setupEmbedRoutes()
+	}
+
+	// Market session and release window status
+	if s.Sessions != nil {
+		s.App.Get("/api/sessions", s.SessionsHandler)
+	}
+
+	// Analytics routes combining FRED and crypto series
+	if s.ModuleEnabled(ModuleAnalytics) && (s.FREDClient != nil || s.CryptoHistory != nil || s.Surprises != nil) {
+		s.setupAnalyticsRoutes()
+	}
+
+	// Composite macro metrics derived from FRED series
+	if s.ModuleEnabled(ModuleAnalytics) && s.FREDClient != nil {
+		s.App.Get("/api/macro/net-liquidity", s.NetLiquidityHandler)
+		s.App.Get("/api/macro/aligned", s.MacroAlignedHandler)
+	}
+
+	// Admin API routes
+	if s.ModuleEnabled(ModuleAdmin) && s.adminToken != "" {
+		s.setupAdminRoutes()
+	}
 }
 
 // setupFREDRoutes registers FRED macroeconomic data routes.
 func (s *FiberServer) setupFREDRoutes() {
 	api := s.App.Group("/api/v1")
-	
+
 	fred := api.Group("/fred")
 	fred.Get("/tickers", s.GetAllTickersHandler)
 	fred.Get("/ticker/:symbol", s.GetTickerDataHandler)
@@ -56,54 +135,302 @@ func (s *FiberServer) setupFREDRoutes() {
 	fred.Get("/latest/:symbol", s.GetLatestValueHandler)
 }
 
+// setupCryptoRoutes registers routes exposing the crypto feed state.
+func (s *FiberServer) setupCryptoRoutes() {
+	crypto := s.App.Group("/api/crypto")
+	crypto.Get("/stats", s.CryptoStatsHandler)
+	crypto.Get("/:symbol/recent", s.CryptoRecentHandler)
+	if s.Movers != nil {
+		crypto.Get("/movers", s.MoversHandler)
+	}
+
+	// Polling alternative to /ws/prices
+	s.App.Get("/api/prices", s.PricesHandler)
+
+	// Symbols that may be added or subscribed to
+	s.App.Get("/api/symbols/available", s.AvailableSymbolsHandler)
+
+	// Chart backfills before /ws/candles takes over
+	if s.Klines != nil {
+		s.App.Get("/api/candles", s.CandlesHandler)
+	}
+}
+
+// setupAnalyticsRoutes registers routes deriving data from FRED and crypto series.
+func (s *FiberServer) setupAnalyticsRoutes() {
+	analytics := s.App.Group("/api/analytics")
+	analytics.Get("/align", s.AlignHandler)
+
+	if s.CryptoHistory != nil {
+		analytics.Get("/returns", s.ReturnsHandler)
+		analytics.Get("/seasonality", s.SeasonalityHandler)
+	}
+
+	if s.Surprises != nil {
+		analytics.Get("/surprise", s.SurpriseHandler)
+	}
+}
+
+// setupFormulaRoutes registers routes for user-defined derived series.
+// Reading formulas is public; changing them requires an API key.
+func (s *FiberServer) setupFormulaRoutes() {
+	formulas := s.App.Group("/api/formulas")
+	formulas.Get("/", s.ListFormulasHandler)
+
+	if len(s.apiKeys) > 0 {
+		formulas.Get("/trash", s.requireAPIKey, s.FormulaTrashHandler)
+		formulas.Post("/", s.requireAPIKey, s.CreateFormulaHandler)
+		formulas.Put("/:id", s.requireAPIKey, s.UpdateFormulaHandler)
+		formulas.Delete("/:id", s.requireAPIKey, s.DeleteFormulaHandler)
+		formulas.Post("/:id/restore", s.requireAPIKey, s.RestoreFormulaHandler)
+	}
+
+	formulas.Get("/:id", s.GetFormulaHandler)
+	formulas.Get("/:id/history", s.FormulaHistoryHandler)
+}
+
+// setupDashboardRoutes registers routes for dashboard templates and the
+// variants users save of them. Templates are public; saved dashboards are
+// only visible to and changeable by the API key that saved them.
+func (s *FiberServer) setupDashboardRoutes() {
+	dashboards := s.App.Group("/api/dashboards")
+	dashboards.Get("/", s.ListDashboardsHandler)
+
+	if len(s.apiKeys) > 0 {
+		dashboards.Get("/trash", s.requireAPIKey, s.DashboardTrashHandler)
+		dashboards.Post("/", s.requireAPIKey, s.CreateDashboardHandler)
+		dashboards.Put("/:id", s.requireAPIKey, s.UpdateDashboardHandler)
+		dashboards.Delete("/:id", s.requireAPIKey, s.DeleteDashboardHandler)
+		dashboards.Post("/:id/restore", s.requireAPIKey, s.RestoreDashboardHandler)
+
+		if s.Shares != nil {
+			dashboards.Post("/:id/share", s.requireAPIKey, s.ShareDashboardHandler)
+		}
+	}
+
+	dashboards.Get("/:id", s.GetDashboardHandler)
+
+	// Read-only access through share links, without an API key
+	if s.Shares != nil {
+		shared := s.App.Group("/api/shared/:token", s.requireShareToken)
+		shared.Get("/", s.SharedDashboardHandler)
+		shared.Get("/charts/:widget", s.SharedChartHandler)
+	}
+}
+
+// setupEmbedRoutes registers the embeddable widget pages and the API
+// managing them. Pages are public; managing widgets requires an API key.
+func (s *FiberServer) setupEmbedRoutes() {
+	s.App.Get("/embed/:id", s.requireEmbed, s.EmbedPageHandler)
+
+	if len(s.apiKeys) > 0 {
+		embeds := s.App.Group("/api/embeds", s.requireAPIKey)
+		embeds.Get("/", s.ListEmbedsHandler)
+		embeds.Get("/trash", s.EmbedTrashHandler)
+		embeds.Post("/", s.CreateEmbedHandler)
+		embeds.Put("/:id", s.UpdateEmbedHandler)
+		embeds.Delete("/:id", s.DeleteEmbedHandler)
+		embeds.Post("/:id/restore", s.RestoreEmbedHandler)
+	}
+}
+
+// setupAdminRoutes registers authenticated operational routes.
+func (s *FiberServer) setupAdminRoutes() {
+	admin := s.App.Group("/api/admin", s.requireAdmin)
+
+	if s.Feeds != nil {
+		feeds := admin.Group("/feeds")
+		feeds.Get("/", s.ListFeedsHandler)
+		feeds.Post("/", s.CreateFeedHandler)
+		feeds.Post("/:name/activate", s.ActivateFeedHandler)
+		feeds.Delete("/:name", s.RemoveFeedHandler)
+		feeds.Post("/:name/symbols", s.AddFeedSymbolsHandler)
+		feeds.Delete("/:name/symbols/:symbol", s.RemoveFeedSymbolHandler)
+
+		// Symbols of the feed that serves clients
+		symbols := admin.Group("/symbols")
+		symbols.Get("/", s.ListSymbolsHandler)
+		symbols.Post("/", s.AddSymbolsHandler)
+		symbols.Delete("/:symbol", s.RemoveSymbolHandler)
+	}
+
+	if s.Usage != nil {
+		admin.Get("/usage", s.AdminUsageHandler)
+	}
+
+	if s.Stats != nil {
+		admin.Get("/stats", s.StatsHandler)
+	}
+
+	if s.Surprises != nil {
+		expectations := admin.Group("/expectations")
+		expectations.Get("/", s.ListExpectationsHandler)
+		expectations.Post("/", s.SetExpectationHandler)
+		expectations.Post("/import", s.ImportExpectationsHandler)
+		expectations.Delete("/:ticker/:date", s.DeleteExpectationHandler)
+	}
+
+	if s.ModuleEnabled(ModuleFRED) {
+		tickers := admin.Group("/fred/tickers")
+		tickers.Post("/", s.RegisterTickerHandler)
+		tickers.Delete("/:symbol", s.UnregisterTickerHandler)
+	}
+
+	admin.Post("/drain", s.DrainHandler)
+	admin.Get("/drops", s.DropsHandler)
+
+	clients := admin.Group("/clients")
+	clients.Get("/", s.ListClientsHandler)
+	clients.Delete("/:id", s.DisconnectClientHandler)
+
+	audit := admin.Group("/audit")
+	audit.Get("/", s.AuditStatusHandler)
+	audit.Post("/", s.EnableAuditHandler)
+	audit.Delete("/", s.DisableAuditHandler)
+}
+
 // setupWebSocketRoutes registers all WebSocket routes.
 func (s *FiberServer) setupWebSocketRoutes() {
-	// WebSocket upgrade endpoint for real-time price updates
-	s.App.Get("/ws/prices", websocket.New(s.handleWebSocket))
+	if !s.ModuleEnabled(ModuleCrypto) {
+		return
+	}
+
+	// WebSocket upgrade endpoint for real-time price updates; clients may
+	// negotiate "ndjson" for one line per symbol per tick or "msgpack" for
+	// binary frames
+	s.App.Get("/ws/prices", s.rejectWhileDraining, s.limitConnections, s.requireJWT, s.streamOptions,
+		s.upgrade(s.handleWebSocket, ws.Subprotocols...))
+
+	// Authenticated relay of raw Binance streams for power users
+	if s.RawProxy != nil && len(s.apiKeys) > 0 {
+		s.App.Get("/ws/raw/:stream", s.rejectWhileDraining, s.limitConnections, s.requireAPIKey, s.upgrade(s.handleRawStream))
+	}
+
+	// Price stream of an embedded widget, limited to its symbols and refresh rate
+	if s.Embeds != nil {
+		s.App.Get("/ws/embed/:id", s.rejectWhileDraining, s.limitConnections, s.requireEmbed, s.upgrade(s.handleEmbedStream))
+	}
+
+	// Price stream restricted to the symbols of a shared dashboard
+	if s.Shares != nil && s.Dashboards != nil {
+		s.App.Get("/ws/shared/:token", s.rejectWhileDraining, s.limitConnections, s.requireShareToken,
+			s.upgrade(s.handleSharedStream, ws.Subprotocols...))
+	}
+
+	// OHLCV candle updates of the tracked symbols; "json" is selected so
+	// browsers can offer a "bearer.<jwt>" subprotocol next to it
+	if s.CandleHub != nil {
+		s.App.Get("/ws/candles", s.rejectWhileDraining, s.limitConnections, s.requireJWT,
+			s.upgrade(s.handleCandleStream, string(ws.EncodingJSON)))
+	}
+
+	// Top of the order books of the tracked symbols
+	if s.OrderBookHub != nil {
+		s.App.Get("/ws/orderbook", s.rejectWhileDraining, s.limitConnections, s.requireJWT,
+			s.upgrade(s.handleOrderBookStream, string(ws.EncodingJSON)))
+	}
 }
 
 // handleWebSocket handles WebSocket connections for real-time price streaming.
 func (s *FiberServer) handleWebSocket(c *websocket.Conn) {
+	defer releaseConnection(c)
+
 	// Create a new client for this connection
 	client := &ws.Client{
-		Hub:  s.Hub,
-		Conn: c,
-		Send: make(chan []byte, ClientSendBufferSize),
+		Hub:       s.Hub,
+		Conn:      c,
+		Send:      make(chan []byte, s.sendBufferSize),
+		Encoding:  ws.EncodingFromSubprotocol(c.Subprotocol()),
+		RemoteIP:  c.IP(),
+		UserAgent: c.Headers(fiber.HeaderUserAgent),
+		Stream:    "prices",
+		UserID:    streamUser(c),
+	}
+	if s.Usage != nil {
+		client.Usage = s.Usage
+		client.UsageKey, _ = c.Locals(usageKeyLocal).(string)
+	}
+	if s.Feeds != nil {
+		client.Catalog = s.Feeds.Active
+	}
+	if opts, ok := c.Locals(streamOptionsLocal).(StreamOptions); ok {
+		client.RefreshInterval = opts.Interval
+		client.Decimals = opts.Decimals
+		client.Numbers = opts.Numbers
+		client.Topics = opts.Topics
+		if opts.Encoding != "" {
+			client.Encoding = opts.Encoding
+		}
+		if opts.Currency != "" {
+			client.Currency = opts.Currency
+			client.Rates = s.FXRates
+		}
+	}
+	if s.Preferences != nil {
+		client.Preferences = s.Preferences
+		client.LoadPreferences()
+	}
+	if s.ModuleEnabled(ModuleAlerts) {
+		client.Alerts = s.Alerts
 	}
 
-	// Register the client with the Hub
-	s.Hub.Register() <- client
-
-	// Ensure cleanup on connection close
-	defer func() {
-		s.Hub.Unregister() <- client
-		client.Close()
-	}()
+	// Read commands (subscribe, unsubscribe, ping, set_throttle and the
+	// preference and alert commands) until the connection closes
+	pumpClient(s.Hub, client)
+}
 
-	// Start the write pump in a goroutine to send messages to the client
-	go client.WritePump()
+// handleCandleStream handles WebSocket connections for candle updates.
+// Clients may narrow the symbols with subscribe and unsubscribe commands.
+func (s *FiberServer) handleCandleStream(c *websocket.Conn) {
+	s.serveSymbolStream(c, s.CandleHub, "candles")
+}
 
-	// Keep the connection open and read messages from the client
-	// This allows clients to send commands (e.g., subscribe to specific symbols)
-	s.readLoop(c)
+// handleOrderBookStream handles WebSocket connections for order book
+// updates. Clients may narrow the symbols with subscribe and unsubscribe
+// commands.
+func (s *FiberServer) handleOrderBookStream(c *websocket.Conn) {
+	s.serveSymbolStream(c, s.OrderBookHub, "orderbook")
 }
 
-// readLoop continuously reads messages from the WebSocket connection.
-// This keeps the connection alive and allows clients to send commands.
-func (s *FiberServer) readLoop(c *websocket.Conn) {
-	for {
-		messageType, message, err := c.ReadMessage()
-		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("WebSocket unexpected close error: %v", err)
-			}
-			break
-		}
+// serveSymbolStream streams the broadcasts of a hub of per-symbol messages
+// to a connection until it closes. stream names the endpoint in the admin
+// client listing.
+func (s *FiberServer) serveSymbolStream(c *websocket.Conn, hub *ws.Hub, stream string) {
+	defer releaseConnection(c)
 
-		// Handle client messages (e.g., subscription requests)
-		// TODO: Implement message handling for client commands
-		log.Printf("Received message type %d: %s", messageType, string(message))
+	client := &ws.Client{
+		Hub:       hub,
+		Conn:      c,
+		Send:      make(chan []byte, s.sendBufferSize),
+		UserID:    streamUser(c),
+		RemoteIP:  c.IP(),
+		UserAgent: c.Headers(fiber.HeaderUserAgent),
+		Stream:    stream,
 	}
+	if s.Usage != nil {
+		client.Usage = s.Usage
+		client.UsageKey, _ = c.Locals(usageKeyLocal).(string)
+	}
+
+	pumpClient(hub, client)
+}
+
+// pumpClient registers a client with the hub and pumps its messages until
+// the connection closes. It returns only once WritePump has exited, since
+// the connection is released when the handler returns.
+func pumpClient(hub *ws.Hub, client *ws.Client) {
+	hub.Register() <- client
+
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		client.WritePump()
+	}()
+	client.ReadPump()
+
+	hub.Unregister() <- client
+	client.Close()
+	<-written
 }
 
 // HelloWorldHandler handles the root endpoint.
@@ -116,10 +443,28 @@ func (s *FiberServer) HelloWorldHandler(c *fiber.Ctx) error {
 }
 
 // HealthHandler handles the health check endpoint.
-// Returns server status and the number of active WebSocket clients.
+// Returns server status, the number of active WebSocket clients, the active
+// modules and the deployment region, if configured. While draining it responds with 503 so
+// load balancers stop routing new users to this server.
 func (s *FiberServer) HealthHandler(c *fiber.Ctx) error {
-	return c.JSON(fiber.Map{
+	health := fiber.Map{
 		"status":         "ok",
 		"active_clients": s.Hub.GetClientCount(),
-	})
+		"modules":        s.ActiveModules(),
+	}
+	if region := s.Hub.Region(); region != "" {
+		health["region"] = region
+	}
+	if s.Hub.Draining() {
+		health["status"] = "draining"
+		return c.Status(fiber.StatusServiceUnavailable).JSON(health)
+	}
+	return c.JSON(health)
+}
+
+// MetricsHandler exposes application metrics in the Prometheus text format.
+func (s *FiberServer) MetricsHandler(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, "text/plain; version=0.0.4")
+	_, err := metrics.Default.WriteTo(c)
+	return err
 }
@@ -3,11 +3,14 @@ package server
 import (
 	"io"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
 
-	"macro-analyst/internal/ws"
+	"macro-analyst/internal/config"
+	"macro-analyst/pkg/metrics"
+	"macro-analyst/pkg/ws"
 )
 
 // TestHelloWorldHandler tests the root endpoint response.
@@ -78,7 +81,37 @@ func TestHealthHandler(t *testing.T) {
 		t.Fatalf("Failed to read response body: %v", err)
 	}
 
-	expected := `{"active_clients":0,"status":"ok"}`
+	expected := `{"active_clients":0,"modules":[],"status":"ok"}`
+	if string(body) != expected {
+		t.Errorf("Expected body %q, got %q", expected, string(body))
+	}
+}
+
+// TestHealthHandlerWithRegion tests that the health endpoint reports the region.
+func TestHealthHandlerWithRegion(t *testing.T) {
+	// Arrange
+	hub := ws.NewHub(ws.WithRegion("eu-west-1"))
+	app := fiber.New()
+	server := &FiberServer{App: app, Hub: hub}
+	app.Get("/health", server.HealthHandler)
+
+	// Act
+	req, err := http.NewRequest(http.MethodGet, "/health", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to execute request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Assert
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response body: %v", err)
+	}
+	expected := `{"active_clients":0,"modules":[],"region":"eu-west-1","status":"ok"}`
 	if string(body) != expected {
 		t.Errorf("Expected body %q, got %q", expected, string(body))
 	}
@@ -115,3 +148,164 @@ func TestHealthHandlerWithClients(t *testing.T) {
 		t.Errorf("Expected status OK, got %v", resp.Status)
 	}
 }
+
+// TestMetricsHandler tests the Prometheus metrics endpoint renders the
+// metrics of the Hubs and Ingestors given the default registry.
+func TestMetricsHandler(t *testing.T) {
+	hub := ws.NewHub(ws.WithHubMetrics(ws.NewMetrics(metrics.Default)))
+	app := fiber.New()
+	server := &FiberServer{App: app, Hub: hub}
+	app.Get("/metrics", server.MetricsHandler)
+
+	req, err := http.NewRequest(http.MethodGet, "/metrics", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to execute request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response body: %v", err)
+	}
+
+	if !strings.Contains(string(body), "macro_analyst_feed_stale_alarms_total") {
+		t.Errorf("Expected stale alarm counter in metrics output, got %q", string(body))
+	}
+}
+
+// TestSymbolStreamRoutes tests that /ws/candles and /ws/orderbook are only
+// registered with their hubs and expect a WebSocket upgrade.
+func TestSymbolStreamRoutes(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		hub      *ws.Hub
+		expected int
+	}{
+		{"without candle hub", "/ws/candles", nil, http.StatusNotFound},
+		{"with candle hub", "/ws/candles", ws.NewHub(), http.StatusUpgradeRequired},
+		{"without order book hub", "/ws/orderbook", nil, http.StatusNotFound},
+		{"with order book hub", "/ws/orderbook", ws.NewHub(), http.StatusUpgradeRequired},
+	}
+
+	for _, tt := range tests {
+		srv := New(ws.NewHub())
+		if tt.path == "/ws/candles" {
+			srv.CandleHub = tt.hub
+		} else {
+			srv.OrderBookHub = tt.hub
+		}
+		srv.RegisterFiberRoutes()
+
+		req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+		resp, err := srv.App.Test(req)
+		if err != nil {
+			t.Fatalf("%s: failed to execute request: %v", tt.name, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expected, resp.StatusCode)
+		}
+	}
+}
+
+// TestModuleSwitches tests that disabled modules register no routes and
+// /health reports the active modules.
+func TestModuleSwitches(t *testing.T) {
+	tests := []struct {
+		name     string
+		modules  []string
+		path     string
+		expected int
+		active   string
+	}{
+		{"all modules", nil, "/ws/prices", http.StatusUpgradeRequired, `["crypto","fred","analytics","alerts","admin"]`},
+		{"price streamer", []string{"crypto"}, "/ws/prices", http.StatusUpgradeRequired, `["crypto"]`},
+		{"macro API", []string{"fred", "analytics"}, "/ws/prices", http.StatusNotFound, `["fred","analytics"]`},
+		{"no admin", []string{"crypto", "fred"}, "/api/admin/audit", http.StatusNotFound, `["crypto","fred"]`},
+		{"admin", []string{"admin"}, "/api/admin/audit", http.StatusUnauthorized, `["admin"]`},
+		{"no analytics", []string{"fred"}, "/api/analytics/align", http.StatusNotFound, `["fred"]`},
+	}
+
+	for _, tt := range tests {
+		srv := New(ws.NewHub(), config.ServerConfig{FREDAPIKey: "key", AdminToken: "secret", Modules: tt.modules})
+		srv.RegisterFiberRoutes()
+
+		req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+		resp, err := srv.App.Test(req)
+		if err != nil {
+			t.Fatalf("%s: failed to execute request: %v", tt.name, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s: expected status %d for %s, got %d", tt.name, tt.expected, tt.path, resp.StatusCode)
+		}
+
+		req, _ = http.NewRequest(http.MethodGet, "/health", nil)
+		resp, err = srv.App.Test(req)
+		if err != nil {
+			t.Fatalf("%s: failed to execute request: %v", tt.name, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if !strings.Contains(string(body), `"modules":`+tt.active) {
+			t.Errorf("%s: expected modules %s, got %s", tt.name, tt.active, body)
+		}
+	}
+}
+
+// TestParseModules tests module names are validated case-insensitively.
+func TestParseModules(t *testing.T) {
+	modules, err := ParseModules([]string{"Crypto", "admin"})
+	if err != nil || len(modules) != 2 || modules[0] != ModuleCrypto || modules[1] != ModuleAdmin {
+		t.Errorf("Expected crypto and admin, got %v (%v)", modules, err)
+	}
+	if _, err := ParseModules([]string{"alarms"}); err == nil {
+		t.Error("Expected an error for an unknown module")
+	}
+}
+
+// TestCORSOrigins verifies only the configured origins are allowed.
+func TestCORSOrigins(t *testing.T) {
+	tests := []struct {
+		name     string
+		origins  []string
+		origin   string
+		expected string
+	}{
+		{"every origin by default", nil, "https://example.com", "*"},
+		{"listed origin", []string{"https://a.example.com", "https://b.example.com"}, "https://b.example.com", "https://b.example.com"},
+		{"unlisted origin", []string{"https://a.example.com"}, "https://evil.example.com", ""},
+	}
+
+	for _, tt := range tests {
+		srv := New(ws.NewHub(), config.ServerConfig{CORSOrigins: tt.origins})
+		srv.RegisterFiberRoutes()
+
+		req, err := http.NewRequest(http.MethodGet, "/health", nil)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header.Set("Origin", tt.origin)
+
+		resp, err := srv.App.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to execute request: %v", err)
+		}
+		resp.Body.Close()
+
+		if allowed := resp.Header.Get(fiber.HeaderAccessControlAllowOrigin); allowed != tt.expected {
+			t.Errorf("%s: expected allowed origin %q, got %q", tt.name, tt.expected, allowed)
+		}
+	}
+}
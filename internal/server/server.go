@@ -1,12 +1,35 @@
 package server
 
 import (
-	"macro-analyst/internal/fred"
-	"macro-analyst/internal/ws"
+	"strings"
+	"time"
+
+	"macro-analyst/internal/analytics"
+	"macro-analyst/internal/attribution"
+	"macro-analyst/internal/config"
+	"macro-analyst/internal/connlimit"
+	"macro-analyst/internal/dashboard"
+	"macro-analyst/internal/embed"
+	"macro-analyst/internal/formula"
+	"macro-analyst/internal/fredstore"
+	"macro-analyst/internal/jwt"
+	"macro-analyst/internal/klines"
+	"macro-analyst/internal/sessions"
+	"macro-analyst/internal/share"
+	"macro-analyst/internal/softdelete"
+	"macro-analyst/internal/stats"
+	"macro-analyst/internal/surprise"
+	"macro-analyst/pkg/feedlog"
+	"macro-analyst/pkg/fred"
+	"macro-analyst/pkg/usage"
+	"macro-analyst/pkg/ws"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// AppName is the name the server reports in its Server header.
+const AppName = "macro-analyst"
+
 // FiberServer wraps the Fiber application with WebSocket Hub integration.
 type FiberServer struct {
 	*fiber.App
@@ -16,42 +39,191 @@ type FiberServer struct {
 
 	// FREDClient is the client for fetching macroeconomic data
 	FREDClient fred.Client
-}
 
-// Config holds the configuration for the FiberServer.
-type Config struct {
-	ServerHeader string
-	AppName      string
-	FREDAPIKey   string
-}
+	// FREDHistory holds the FRED observations net liquidity and the
+	// analytics routes read, seeded on start. They fetch from FREDClient
+	// on each request when it is nil; /api/status reports its seeding.
+	FREDHistory *fredstore.Store
 
-// DefaultConfig returns the default server configuration.
-func DefaultConfig() Config {
-	return Config{
-		ServerHeader: "macro-analyst",
-		AppName:      "macro-analyst",
-	}
+	// CryptoHistory loads daily crypto closes for analytics.
+	// Crypto series are unavailable in analytics when it is nil.
+	CryptoHistory analytics.CryptoHistory
+
+	// Feeds manages the running ingestors for blue/green switchover.
+	// Admin feed routes are only registered when it is set.
+	Feeds *ws.FeedSwitch
+
+	// Usage records REST and WebSocket usage per API key.
+	// Usage tracking and /api/usage are only enabled when it is set.
+	Usage *usage.Tracker
+
+	// Attribution credits data sources in responses of the enabled modules.
+	// No attribution is attached when it is nil.
+	Attribution *attribution.Attributor
+
+	// RawProxy relays raw Binance streams to API key holders.
+	// /ws/raw/:stream is only registered when it is set and API keys are configured.
+	RawProxy *ws.RawProxy
+
+	// Stats samples resource usage for capacity planning.
+	// /api/admin/stats is only registered when it is set.
+	Stats *stats.Collector
+
+	// Surprises holds expectations for macro releases and the surprise index.
+	// /api/analytics/surprise and the expectation admin routes are only
+	// registered when it is set.
+	Surprises *surprise.Store
+
+	// Formulas holds user-defined derived series. /api/formulas is only
+	// registered when it is set; changing formulas requires an API key.
+	Formulas *softdelete.Collection[formula.Formula]
+
+	// FormulaEngine computes live formula values reported by /api/formulas.
+	// Formulas are listed without values when it is nil.
+	FormulaEngine *formula.Engine
+
+	// Dashboards holds user-saved dashboard variants. /api/dashboards is
+	// only registered when it is set; saving dashboards requires an API key.
+	Dashboards *softdelete.Collection[dashboard.Dashboard]
+
+	// Shares signs read-only share links for dashboards. /api/shared and
+	// /ws/shared are only registered when it and Dashboards are set.
+	Shares *share.Signer
+
+	// Embeds holds the live ticker widgets served for embedding at
+	// /embed/:id. Those routes are only registered when it is set;
+	// creating widgets requires an API key.
+	Embeds *softdelete.Collection[embed.Widget]
+
+	// EmbedByteCap is how many bytes each embed stream connection receives
+	// per hour before its prices are sampled; uncapped when zero
+	EmbedByteCap int64
+
+	// Sessions knows US market hours and FRED release windows.
+	// /api/sessions is only registered when it is set.
+	Sessions *sessions.Calendar
+
+	// CandleHub broadcasts candle updates, kept apart from Hub so price
+	// clients do not receive them. /ws/candles is only registered when it
+	// is set.
+	CandleHub *ws.Hub
+
+	// OrderBookHub broadcasts order book updates, kept apart from Hub for
+	// the same reason. /ws/orderbook is only registered when it is set.
+	OrderBookHub *ws.Hub
+
+	// PollWait is the longest a /api/poll request waits for a broadcast,
+	// DefaultPollWait when zero
+	PollWait time.Duration
+
+	// ConnLimiter caps WebSocket connections per server and per client IP
+	// and rate limits upgrades. Streams are not limited when it is nil.
+	ConnLimiter *connlimit.Limiter
+
+	// FeedHistory records when the Binance feeds connect and disconnect.
+	// /api/status/feed-history is only registered when it is set.
+	FeedHistory *feedlog.Log
+
+	// Preferences holds the stream settings authenticated /ws/prices
+	// clients saved, applied when they reconnect. Clients cannot save
+	// preferences when it is nil.
+	Preferences *ws.PreferenceStore
+
+	// Alerts holds the price alerts of /ws/prices clients, evaluated by the
+	// active feed. Clients cannot set alerts when it is nil, and
+	// /api/alerts is only registered when it is set and JWTs are required.
+	Alerts *ws.AlertBook
+
+	// FXRates converts prices to the currency /api/prices and /ws/prices
+	// clients ask for with the currency query parameter. Prices are only
+	// served in USDT when it is nil.
+	FXRates *ws.FXRates
+
+	// Movers ranks all Binance USDT pairs by their 24h change and volume.
+	// /api/crypto/movers is only registered when it is set.
+	Movers *ws.MoverTracker
+
+	// Klines serves historical candles fetched from Binance for chart
+	// backfills. /api/candles is only registered when it is set.
+	Klines *klines.Cache
+
+	// Tickers are the FRED series served by the FRED routes, defaults to
+	// fred.DefaultRegistry. Series can be added through the admin API.
+	Tickers *fred.Registry
+
+	// adminToken is the bearer token required by admin routes
+	adminToken string
+
+	// apiKeys are the keys accepted by routes that require an API key
+	apiKeys []string
+
+	// jwtVerifier checks the tokens of price stream clients, nil when
+	// streams are open to anonymous clients
+	jwtVerifier *jwt.Verifier
+
+	// modules are the switched on modules
+	modules map[Module]bool
+
+	// compression negotiates permessage-deflate on WebSocket streams at
+	// compressionLevel, 0 for the default
+	compression      bool
+	compressionLevel int
+
+	// corsOrigins are the origins allowed by CORS, comma-separated
+	corsOrigins string
+
+	// sendBufferSize is the capacity of each stream client's send channel
+	sendBufferSize int
 }
 
-// New creates a new FiberServer instance with the given Hub and configuration.
-func New(hub *ws.Hub, cfg ...Config) *FiberServer {
-	config := DefaultConfig()
+// New creates a new FiberServer instance with the given Hub and the server
+// part of the service configuration. Modules it does not name are off,
+// all of them are on when it names none.
+func New(hub *ws.Hub, cfg ...config.ServerConfig) *FiberServer {
+	var serverConfig config.ServerConfig
 	if len(cfg) > 0 {
-		config = cfg[0]
+		serverConfig = cfg[0]
+	}
+
+	enabled := make(map[Module]bool, len(Modules))
+	for _, module := range Modules {
+		enabled[module] = len(serverConfig.Modules) == 0
+	}
+	for _, name := range serverConfig.Modules {
+		enabled[Module(strings.ToLower(name))] = true
 	}
 
 	var fredClient fred.Client
-	if config.FREDAPIKey != "" {
-		fredClient = fred.NewClient(config.FREDAPIKey)
+	if serverConfig.FREDAPIKey != "" && enabled[ModuleFRED] {
+		fredClient = fred.NewClient(serverConfig.FREDAPIKey)
 	}
 
 	server := &FiberServer{
 		App: fiber.New(fiber.Config{
-			ServerHeader: config.ServerHeader,
-			AppName:      config.AppName,
+			ServerHeader: AppName,
+			AppName:      AppName,
 		}),
 		Hub:        hub,
 		FREDClient: fredClient,
+		Tickers:    fred.DefaultRegistry,
+		adminToken: serverConfig.AdminToken,
+		apiKeys:    serverConfig.APIKeys,
+		modules:    enabled,
+
+		compression:      serverConfig.Compression,
+		compressionLevel: serverConfig.CompressionLevel,
+
+		corsOrigins:    "*",
+		sendBufferSize: ClientSendBufferSize,
+	}
+	if len(serverConfig.CORSOrigins) > 0 {
+		server.corsOrigins = strings.Join(serverConfig.CORSOrigins, ",")
+	}
+	if serverConfig.SendBufferSize > 0 {
+		server.sendBufferSize = serverConfig.SendBufferSize
+	}
+	if serverConfig.JWTSigningKey != "" {
+		server.jwtVerifier = jwt.NewVerifier([]byte(serverConfig.JWTSigningKey), jwt.WithIssuer(serverConfig.JWTIssuer))
 	}
 
 	return server
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"macro-analyst/internal/config"
+	"macro-analyst/pkg/clock"
+	"macro-analyst/pkg/ws"
+	"macro-analyst/pkg/ws/testutil"
+)
+
+// newStreamTestServer serves /ws/prices of a running Hub on a loopback port.
+func newStreamTestServer(t *testing.T, hub *ws.Hub, cfg config.ServerConfig) *testutil.Server {
+	go hub.Run()
+	srv := New(hub, cfg)
+	srv.RegisterFiberRoutes()
+	return testutil.NewServer(t, srv.App)
+}
+
+// priceUpdate renders a multi_update of the given symbols at a price.
+func priceUpdate(price float64, symbols ...string) []byte {
+	update := ws.MultiUpdate{Type: "multi_update"}
+	for _, symbol := range symbols {
+		update.Data = append(update.Data, &ws.PriceUpdate{Symbol: symbol, Price: price})
+	}
+	message, _ := json.Marshal(&update)
+	return message
+}
+
+// TestStreamSubscriptionFiltering verifies a subscribe command narrows the
+// broadcasts a connection receives, end to end.
+func TestStreamSubscriptionFiltering(t *testing.T) {
+	hub := ws.NewHub()
+	server := newStreamTestServer(t, hub, config.ServerConfig{})
+
+	subscribed := server.Dial("/ws/prices")
+	everything := server.Dial("/ws/prices")
+	testutil.WaitForClients(t, hub, 2)
+
+	subscribed.Send(map[string]any{"type": "subscribe", "symbols": []string{"btc"}, "id": "1"})
+	subscribed.ReadType("ack")
+
+	hub.Publish(priceUpdate(50000, "BTCUSDT", "ETHUSDT"))
+
+	if symbols := testutil.Symbols(t, subscribed.ReadType("multi_update")); !reflect.DeepEqual(symbols, []string{"BTCUSDT"}) {
+		t.Errorf("Expected only BTCUSDT for the subscribed client, got %v", symbols)
+	}
+	if symbols := testutil.Symbols(t, everything.ReadType("multi_update")); !reflect.DeepEqual(symbols, []string{"BTCUSDT", "ETHUSDT"}) {
+		t.Errorf("Expected every symbol for the other client, got %v", symbols)
+	}
+
+	hub.Publish(priceUpdate(3000, "ETHUSDT"))
+	subscribed.ExpectNoType("multi_update", 200*time.Millisecond)
+}
+
+// TestStreamBackpressureCoalesce verifies a client with a small send buffer
+// still receives the latest price of a burst under the coalesce policy.
+func TestStreamBackpressureCoalesce(t *testing.T) {
+	hub := ws.NewHub(ws.WithSlowClientPolicy(ws.SlowClientCoalesce))
+	cfg := config.ServerConfig{}
+	cfg.SendBufferSize = 1
+	server := newStreamTestServer(t, hub, cfg)
+
+	conn := server.Dial("/ws/prices")
+	testutil.WaitForClients(t, hub, 1)
+
+	const burst = 200
+	for price := 1; price <= burst; price++ {
+		hub.Publish(priceUpdate(float64(price), "BTCUSDT"))
+	}
+
+	conn.ReadUntil(func(message []byte) bool {
+		return strings.Contains(string(message), `"price":200,`) || strings.Contains(string(message), `"price":200}`)
+	})
+	if hub.GetClientCount() != 1 {
+		t.Errorf("Expected the slow client to stay connected, got %d clients", hub.GetClientCount())
+	}
+}
+
+// TestStreamNDJSON verifies the ndjson subprotocol is negotiated and
+// updates arrive as one line per symbol.
+func TestStreamNDJSON(t *testing.T) {
+	hub := ws.NewHub()
+	server := newStreamTestServer(t, hub, config.ServerConfig{})
+
+	conn := server.Dial("/ws/prices", "ndjson")
+	if conn.Subprotocol() != "ndjson" {
+		t.Fatalf("Expected the ndjson subprotocol, got %q", conn.Subprotocol())
+	}
+	testutil.WaitForClients(t, hub, 1)
+
+	hub.Publish(priceUpdate(50000, "BTCUSDT", "ETHUSDT"))
+	lines := strings.Split(strings.TrimSpace(string(conn.ReadType("price_update"))), "\n")
+	if len(lines) != 2 {
+		t.Errorf("Expected a line per symbol, got %q", lines)
+	}
+}
+
+// TestStreamIdleTimeout verifies a connection that answers the server's
+// pings stays connected while one that does not is reaped.
+func TestStreamIdleTimeout(t *testing.T) {
+	const idleTimeout = 300 * time.Millisecond
+	hub := ws.NewHub(ws.WithIdleTimeout(idleTimeout))
+	server := newStreamTestServer(t, hub, config.ServerConfig{})
+
+	// The client answers pings while it reads
+	live := server.Dial("/ws/prices")
+	go func() {
+		for {
+			if _, _, err := live.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	server.Dial("/ws/prices")
+	testutil.WaitForClients(t, hub, 2)
+
+	testutil.WaitForClients(t, hub, 1)
+	time.Sleep(2 * idleTimeout)
+	if hub.GetClientCount() != 1 || hub.ReapedConnections() != 1 {
+		t.Errorf("Expected only the silent connection reaped, got %d clients and %d reaped", hub.GetClientCount(), hub.ReapedConnections())
+	}
+}
+
+// TestStreamReadDeadline verifies a silent connection is reaped by its read
+// deadline even while the Hub's reaper does not run.
+func TestStreamReadDeadline(t *testing.T) {
+	const idleTimeout = 300 * time.Millisecond
+	frozen := clock.NewFake(time.Date(2024, 2, 20, 12, 0, 0, 0, time.UTC))
+	hub := ws.NewHub(ws.WithHubClock(frozen), ws.WithIdleTimeout(idleTimeout))
+	server := newStreamTestServer(t, hub, config.ServerConfig{})
+
+	server.Dial("/ws/prices")
+	testutil.WaitForClients(t, hub, 1)
+
+	testutil.WaitForClients(t, hub, 0)
+	if reaped := hub.ReapedConnections(); reaped != 1 {
+		t.Errorf("Expected the silent connection reaped, got %d reaped", reaped)
+	}
+}
@@ -0,0 +1,253 @@
+// Package sessions knows when markets are busy: US equity market hours,
+// weekends and holidays, and the windows in which FRED publishes the
+// tracked macro series. Outside all of them the server is in a quiet
+// window, when non-critical work such as FRED polling can pause and heavier
+// jobs such as purges can run:
+//
+//	calendar := sessions.New(sessions.WithHolidays("2024-12-25"))
+//	sched.Every("purge-formulas", time.Hour, sched.When(calendar.Quiet, func(ctx context.Context) {
+//	    formulas.Purge(time.Now())
+//	}))
+package sessions
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	// Market hours are defined in New York time, also on hosts without tzdata
+	_ "time/tzdata"
+)
+
+// DateLayout is the format of holiday dates (New York time)
+const DateLayout = "2006-01-02"
+
+// Session is a phase of the US equity trading day.
+type Session string
+
+const (
+	SessionPreMarket  Session = "pre_market"  // 04:00-09:30 ET on trading days
+	SessionRegular    Session = "regular"     // 09:30-16:00 ET on trading days
+	SessionAfterHours Session = "after_hours" // 16:00-20:00 ET on trading days
+	SessionOvernight  Session = "overnight"   // 20:00-04:00 ET on trading days
+	SessionWeekend    Session = "weekend"
+	SessionHoliday    Session = "holiday"
+)
+
+// Minutes after midnight ET at which the sessions of a trading day start
+const (
+	preMarketOpen  = 4 * 60
+	regularOpen    = 9*60 + 30
+	regularClose   = 16 * 60
+	afterHoursEnds = 20 * 60
+)
+
+// DefaultReleaseWindow is how long after its scheduled time a release may
+// take to appear on FRED.
+const DefaultReleaseWindow = time.Hour
+
+// ReleaseTime is when the source agency publishes a FRED series, in New
+// York time. Monthly releases vary in day, so they are expected on every
+// weekday at their time of day.
+type ReleaseTime struct {
+	Ticker   string
+	Weekdays []time.Weekday // Days the series may be released
+	Hour     int
+	Minute   int
+}
+
+// DefaultReleases are the release times of the tracked FRED series.
+var DefaultReleases = []ReleaseTime{
+	{Ticker: "WALCL", Weekdays: []time.Weekday{time.Thursday}, Hour: 16, Minute: 30},   // H.4.1, weekly
+	{Ticker: "WTREGEN", Weekdays: []time.Weekday{time.Thursday}, Hour: 16, Minute: 30}, // H.4.1, weekly
+	{Ticker: "RRPONTSYD", Weekdays: weekdays, Hour: 13, Minute: 15},                    // NY Fed, daily
+	{Ticker: "FEDFUNDS", Weekdays: weekdays, Hour: 9, Minute: 0},                       // NY Fed, monthly
+	{Ticker: "CPIAUCSL", Weekdays: weekdays, Hour: 8, Minute: 30},                      // BLS, monthly
+	{Ticker: "DTWEXBGS", Weekdays: []time.Weekday{time.Monday}, Hour: 16, Minute: 15},  // H.10, weekly
+}
+
+// weekdays are Monday to Friday
+var weekdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+// Release is an upcoming or ongoing release window of a FRED series.
+type Release struct {
+	Ticker string    `json:"ticker"`
+	At     time.Time `json:"at"`
+}
+
+// Status describes the market session at a point in time.
+type Status struct {
+	Time       time.Time `json:"time"`
+	Session    Session   `json:"session"`
+	MarketOpen bool      `json:"market_open"` // Regular trading hours
+	Quiet      bool      `json:"quiet"`       // Markets closed and no release window open
+
+	// Releases whose window is open
+	Releases []Release `json:"releases"`
+
+	NextOpen    time.Time `json:"next_open"`              // Start of the next regular session
+	NextRelease *Release  `json:"next_release,omitempty"` // Within the next week
+}
+
+// Calendar computes market sessions and release windows.
+type Calendar struct {
+	location      *time.Location
+	holidays      map[string]bool
+	releases      []ReleaseTime
+	releaseWindow time.Duration
+}
+
+// Option configures a Calendar.
+type Option func(*Calendar)
+
+// WithHolidays sets the market holidays (YYYY-MM-DD, New York time), on
+// which the market stays closed all day. Invalid dates are ignored.
+func WithHolidays(dates ...string) Option {
+	return func(c *Calendar) {
+		for _, date := range dates {
+			if _, err := time.Parse(DateLayout, date); err == nil {
+				c.holidays[date] = true
+			}
+		}
+	}
+}
+
+// WithReleases replaces the release times of the tracked series.
+func WithReleases(releases []ReleaseTime) Option {
+	return func(c *Calendar) {
+		c.releases = releases
+	}
+}
+
+// WithReleaseWindow sets how long after its scheduled time a release window
+// stays open.
+func WithReleaseWindow(window time.Duration) Option {
+	return func(c *Calendar) {
+		c.releaseWindow = window
+	}
+}
+
+// New creates a Calendar for US markets.
+func New(opts ...Option) *Calendar {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		// Embedded tzdata makes this unreachable
+		panic(fmt.Sprintf("sessions: %v", err))
+	}
+
+	calendar := &Calendar{
+		location:      location,
+		holidays:      make(map[string]bool),
+		releases:      DefaultReleases,
+		releaseWindow: DefaultReleaseWindow,
+	}
+	for _, opt := range opts {
+		opt(calendar)
+	}
+	return calendar
+}
+
+// Session returns the market session at now.
+func (c *Calendar) Session(now time.Time) Session {
+	local := now.In(c.location)
+	switch {
+	case local.Weekday() == time.Saturday || local.Weekday() == time.Sunday:
+		return SessionWeekend
+	case c.holidays[local.Format(DateLayout)]:
+		return SessionHoliday
+	}
+
+	minute := local.Hour()*60 + local.Minute()
+	switch {
+	case minute < preMarketOpen, minute >= afterHoursEnds:
+		return SessionOvernight
+	case minute < regularOpen:
+		return SessionPreMarket
+	case minute < regularClose:
+		return SessionRegular
+	default:
+		return SessionAfterHours
+	}
+}
+
+// Quiet reports whether now is in a quiet window: outside pre-market,
+// regular and after-hours trading, and outside all release windows.
+func (c *Calendar) Quiet(now time.Time) bool {
+	switch c.Session(now) {
+	case SessionPreMarket, SessionRegular, SessionAfterHours:
+		return false
+	}
+	return len(c.OpenReleases(now)) == 0
+}
+
+// OpenReleases returns the releases whose window is open at now.
+func (c *Calendar) OpenReleases(now time.Time) []Release {
+	releases := c.releasesBetween(now.Add(-c.releaseWindow), now)
+	if releases == nil {
+		releases = []Release{}
+	}
+	return releases
+}
+
+// Status describes the market session at now.
+func (c *Calendar) Status(now time.Time) Status {
+	status := Status{
+		Time:     now,
+		Session:  c.Session(now),
+		Quiet:    c.Quiet(now),
+		Releases: c.OpenReleases(now),
+		NextOpen: c.nextOpen(now),
+	}
+	status.MarketOpen = status.Session == SessionRegular
+
+	if upcoming := c.releasesBetween(now, now.AddDate(0, 0, 7)); len(upcoming) > 0 {
+		status.NextRelease = &upcoming[0]
+	}
+	return status
+}
+
+// nextOpen returns the start of the next regular session after now.
+func (c *Calendar) nextOpen(now time.Time) time.Time {
+	local := now.In(c.location)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, c.location)
+	for {
+		open := time.Date(day.Year(), day.Month(), day.Day(), regularOpen/60, regularOpen%60, 0, 0, c.location)
+		if open.After(now) && c.isTradingDay(day) {
+			return open
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+}
+
+// releasesBetween returns the releases scheduled in (from, to], ordered
+// by time. Releases are not expected on holidays.
+func (c *Calendar) releasesBetween(from, to time.Time) []Release {
+	var releases []Release
+
+	local := from.In(c.location)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, c.location)
+	for ; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if c.holidays[day.Format(DateLayout)] {
+			continue
+		}
+
+		for _, release := range c.releases {
+			at := time.Date(day.Year(), day.Month(), day.Day(), release.Hour, release.Minute, 0, 0, c.location)
+			if slices.Contains(release.Weekdays, day.Weekday()) && at.After(from) && !at.After(to) {
+				releases = append(releases, Release{Ticker: release.Ticker, At: at})
+			}
+		}
+	}
+
+	sort.SliceStable(releases, func(a, b int) bool {
+		return releases[a].At.Before(releases[b].At)
+	})
+	return releases
+}
+
+// isTradingDay reports whether the market opens on day.
+func (c *Calendar) isTradingDay(day time.Time) bool {
+	weekday := day.Weekday()
+	return weekday != time.Saturday && weekday != time.Sunday && !c.holidays[day.Format(DateLayout)]
+}
@@ -0,0 +1,98 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+)
+
+// TestSession verifies sessions in New York time.
+func TestSession(t *testing.T) {
+	calendar := New(WithHolidays("2024-01-15"))
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		expected Session
+		quiet    bool
+	}{
+		{"regular", time.Date(2024, 1, 17, 15, 0, 0, 0, time.UTC), SessionRegular, false},
+		{"pre-market", time.Date(2024, 1, 17, 12, 0, 0, 0, time.UTC), SessionPreMarket, false},
+		{"overnight", time.Date(2024, 1, 17, 3, 0, 0, 0, time.UTC), SessionOvernight, true},
+		{"weekend", time.Date(2024, 1, 20, 15, 0, 0, 0, time.UTC), SessionWeekend, true},
+		{"holiday", time.Date(2024, 1, 15, 15, 0, 0, 0, time.UTC), SessionHoliday, true},
+		{"after hours release", time.Date(2024, 1, 18, 21, 45, 0, 0, time.UTC), SessionAfterHours, false},
+		{"release window closed", time.Date(2024, 1, 19, 1, 30, 0, 0, time.UTC), SessionOvernight, true},
+	}
+
+	for _, tt := range tests {
+		if got := calendar.Session(tt.now); got != tt.expected {
+			t.Errorf("%s: expected session %s, got %s", tt.name, tt.expected, got)
+		}
+		if got := calendar.Quiet(tt.now); got != tt.quiet {
+			t.Errorf("%s: expected quiet %v, got %v", tt.name, tt.quiet, got)
+		}
+	}
+}
+
+// TestQuietOutsideReleaseWindows verifies release windows interrupt quiet
+// windows.
+func TestQuietOutsideReleaseWindows(t *testing.T) {
+	// Thursday 20:30 ET, four hours after the H.4.1 release
+	now := time.Date(2024, 1, 19, 1, 30, 0, 0, time.UTC)
+
+	calendar := New(WithReleaseWindow(5 * time.Hour))
+	if calendar.Quiet(now) {
+		t.Error("Expected no quiet window while a release window is open")
+	}
+
+	releases := calendar.OpenReleases(now)
+	if len(releases) != 2 || releases[0].Ticker != "WALCL" {
+		t.Errorf("Expected the WALCL and WTREGEN release windows, got %+v", releases)
+	}
+}
+
+// TestStatus verifies the next regular open and release, across a weekend,
+// a holiday and a daylight saving time change.
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		holidays    []string
+		now         time.Time
+		nextOpen    time.Time
+		nextRelease string
+	}{
+		{
+			name:        "friday evening",
+			now:         time.Date(2024, 1, 19, 22, 0, 0, 0, time.UTC),
+			nextOpen:    time.Date(2024, 1, 22, 14, 30, 0, 0, time.UTC),
+			nextRelease: "CPIAUCSL",
+		},
+		{
+			name:        "monday holiday",
+			holidays:    []string{"2024-01-22"},
+			now:         time.Date(2024, 1, 19, 22, 0, 0, 0, time.UTC),
+			nextOpen:    time.Date(2024, 1, 23, 14, 30, 0, 0, time.UTC),
+			nextRelease: "CPIAUCSL",
+		},
+		{
+			name:        "daylight saving time starts",
+			now:         time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC),
+			nextOpen:    time.Date(2024, 3, 11, 13, 30, 0, 0, time.UTC),
+			nextRelease: "CPIAUCSL",
+		},
+	}
+
+	for _, tt := range tests {
+		status := New(WithHolidays(tt.holidays...)).Status(tt.now)
+
+		if !status.NextOpen.Equal(tt.nextOpen) {
+			t.Errorf("%s: expected next open %v, got %v", tt.name, tt.nextOpen, status.NextOpen.UTC())
+		}
+		if status.NextRelease == nil || status.NextRelease.Ticker != tt.nextRelease {
+			t.Errorf("%s: expected next release %s, got %+v", tt.name, tt.nextRelease, status.NextRelease)
+		}
+		if status.MarketOpen {
+			t.Errorf("%s: expected the market to be closed", tt.name)
+		}
+	}
+}
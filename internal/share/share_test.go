@@ -0,0 +1,62 @@
+package share
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSignVerify verifies tokens round-trip their scope.
+func TestSignVerify(t *testing.T) {
+	signer := NewSigner([]byte("secret"))
+	widget := 2
+	expiry := time.Now().Add(time.Hour).Unix()
+
+	token, err := signer.Sign(Scope{Dashboard: "abc", Widget: &widget, ExpiresAt: expiry})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	scope, err := signer.Verify(token)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if scope.Dashboard != "abc" || scope.Widget == nil || *scope.Widget != 2 || scope.ExpiresAt != expiry {
+		t.Errorf("Unexpected scope: %+v", scope)
+	}
+	if !scope.Includes(2) || scope.Includes(0) {
+		t.Error("Expected the scope to include only widget 2")
+	}
+}
+
+// TestVerifyErrors verifies forged, malformed and expired tokens are rejected.
+func TestVerifyErrors(t *testing.T) {
+	signer := NewSigner([]byte("secret"))
+	token, _ := signer.Sign(Scope{Dashboard: "abc", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	payload, signature, _ := strings.Cut(token, ".")
+	other, _ := NewSigner([]byte("other")).Sign(Scope{Dashboard: "abc", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	otherPayload, _, _ := strings.Cut(other, ".")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"empty", ""},
+		{"no signature", payload},
+		{"wrong secret", other},
+		{"swapped payload", otherPayload + "x." + signature},
+		{"bad encoding", payload + ".!!!"},
+	}
+
+	for _, tt := range tests {
+		if _, err := signer.Verify(tt.token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: expected ErrInvalidToken, got %v", tt.name, err)
+		}
+	}
+
+	signer.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if _, err := signer.Verify(token); !errors.Is(err, ErrExpired) {
+		t.Errorf("Expected ErrExpired, got %v", err)
+	}
+}
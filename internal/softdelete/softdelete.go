@@ -0,0 +1,191 @@
+// Package softdelete provides an in-memory collection of user-created
+// objects where deletes move objects to a trash they can be restored from
+// until a scheduled purge removes them permanently.
+//
+//	alerts := softdelete.New[Alert]()
+//	alerts.Put(alert.ID, alert)
+//	alerts.Delete(alert.ID)  // Moved to the trash
+//	alerts.Restore(alert.ID) // Back in the collection
+//
+//	sched.Every("purge-alerts", time.Hour, func(ctx context.Context) {
+//	    alerts.Purge(time.Now())
+//	})
+package softdelete
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultRetention is how long deleted objects stay restorable.
+const DefaultRetention = 30 * 24 * time.Hour
+
+var (
+	// ErrNotFound is returned when no live object has the given ID.
+	ErrNotFound = errors.New("not found")
+
+	// ErrNotInTrash is returned when restoring an object that is not deleted.
+	ErrNotInTrash = errors.New("not in trash")
+)
+
+// Deleted is an object in the trash.
+type Deleted[T any] struct {
+	ID        string    `json:"id"`
+	Value     T         `json:"value"`
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// entry is a stored object and its deletion time, zero while live.
+type entry[T any] struct {
+	value     T
+	deletedAt time.Time
+}
+
+// Collection holds objects by ID. It is safe for concurrent use.
+type Collection[T any] struct {
+	mu        sync.RWMutex
+	entries   map[string]*entry[T]
+	retention time.Duration
+	now       func() time.Time
+}
+
+// Option is a functional option for configuring a Collection.
+type Option func(*options)
+
+type options struct {
+	retention time.Duration
+}
+
+// WithRetention sets how long deleted objects stay restorable.
+func WithRetention(retention time.Duration) Option {
+	return func(o *options) {
+		o.retention = retention
+	}
+}
+
+// New creates an empty Collection.
+func New[T any](opts ...Option) *Collection[T] {
+	config := options{retention: DefaultRetention}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return &Collection[T]{
+		entries:   make(map[string]*entry[T]),
+		retention: config.retention,
+		now:       time.Now,
+	}
+}
+
+// Put stores or replaces a live object.
+func (c *Collection[T]) Put(id string, value T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[id] = &entry[T]{value: value}
+}
+
+// Get returns a live object.
+func (c *Collection[T]) Get(id string) (T, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, exists := c.entries[id]
+	if !exists || !entry.deletedAt.IsZero() {
+		var zero T
+		return zero, false
+	}
+	return entry.value, true
+}
+
+// List returns all live objects ordered by ID.
+func (c *Collection[T]) List() []T {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	values := make([]T, 0, len(c.entries))
+	for _, id := range c.sortedIDs() {
+		if entry := c.entries[id]; entry.deletedAt.IsZero() {
+			values = append(values, entry.value)
+		}
+	}
+	return values
+}
+
+// Delete moves a live object to the trash.
+func (c *Collection[T]) Delete(id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.entries[id]
+	if !exists || !entry.deletedAt.IsZero() {
+		return ErrNotFound
+	}
+	entry.deletedAt = c.now()
+	return nil
+}
+
+// Restore moves an object from the trash back into the collection.
+func (c *Collection[T]) Restore(id string) (T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.entries[id]
+	if !exists || entry.deletedAt.IsZero() {
+		var zero T
+		return zero, ErrNotInTrash
+	}
+	entry.deletedAt = time.Time{}
+	return entry.value, nil
+}
+
+// Trash returns the deleted objects, most recently deleted first.
+func (c *Collection[T]) Trash() []Deleted[T] {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	trash := make([]Deleted[T], 0)
+	for id, entry := range c.entries {
+		if entry.deletedAt.IsZero() {
+			continue
+		}
+		trash = append(trash, Deleted[T]{
+			ID:        id,
+			Value:     entry.value,
+			DeletedAt: entry.deletedAt,
+			PurgeAt:   entry.deletedAt.Add(c.retention),
+		})
+	}
+	sort.Slice(trash, func(a, b int) bool {
+		return trash[a].DeletedAt.After(trash[b].DeletedAt)
+	})
+	return trash
+}
+
+// Purge permanently removes objects deleted longer than the retention
+// period before now. It returns the number of removed objects.
+func (c *Collection[T]) Purge(now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	purged := 0
+	for id, entry := range c.entries {
+		if !entry.deletedAt.IsZero() && now.Sub(entry.deletedAt) >= c.retention {
+			delete(c.entries, id)
+			purged++
+		}
+	}
+	return purged
+}
+
+// sortedIDs returns all IDs in ascending order. The caller must hold c.mu.
+func (c *Collection[T]) sortedIDs() []string {
+	ids := make([]string, 0, len(c.entries))
+	for id := range c.entries {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,94 @@
+package softdelete
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestDeleteAndRestore verifies deleted objects leave the collection and
+// come back on restore.
+func TestDeleteAndRestore(t *testing.T) {
+	c := New[string]()
+	c.Put("a", "alpha")
+	c.Put("b", "beta")
+
+	if err := c.Delete("a"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("Expected deleted object to be hidden")
+	}
+	if list := c.List(); len(list) != 1 || list[0] != "beta" {
+		t.Errorf("Expected only live objects, got %v", list)
+	}
+	if trash := c.Trash(); len(trash) != 1 || trash[0].ID != "a" || trash[0].Value != "alpha" {
+		t.Errorf("Unexpected trash: %+v", trash)
+	}
+
+	value, err := c.Restore("a")
+	if err != nil || value != "alpha" {
+		t.Fatalf("Expected alpha to be restored, got %q (%v)", value, err)
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("Expected restored object to be live")
+	}
+	if len(c.Trash()) != 0 {
+		t.Error("Expected empty trash after restore")
+	}
+}
+
+// TestDeleteErrors verifies deleting and restoring missing objects.
+func TestDeleteErrors(t *testing.T) {
+	c := New[int]()
+	c.Put("a", 1)
+
+	if err := c.Delete("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if _, err := c.Restore("a"); !errors.Is(err, ErrNotInTrash) {
+		t.Errorf("Expected ErrNotInTrash for a live object, got %v", err)
+	}
+
+	c.Delete("a")
+	if err := c.Delete("a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an already deleted object, got %v", err)
+	}
+}
+
+// TestPurge verifies only objects past the retention period are removed.
+func TestPurge(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	c := New[string](WithRetention(24 * time.Hour))
+	c.now = func() time.Time { return now }
+
+	c.Put("old", "old")
+	c.Put("recent", "recent")
+	c.Put("live", "live")
+	c.Delete("old")
+
+	now = now.Add(12 * time.Hour)
+	c.Delete("recent")
+
+	trash := c.Trash()
+	if len(trash) != 2 || trash[0].ID != "recent" {
+		t.Fatalf("Expected most recently deleted first, got %+v", trash)
+	}
+	if !trash[0].PurgeAt.Equal(now.Add(24 * time.Hour)) {
+		t.Errorf("Unexpected purge time %v", trash[0].PurgeAt)
+	}
+
+	if purged := c.Purge(now.Add(13 * time.Hour)); purged != 1 {
+		t.Errorf("Expected 1 purged object, got %d", purged)
+	}
+	if _, err := c.Restore("old"); !errors.Is(err, ErrNotInTrash) {
+		t.Error("Expected purged object to be gone")
+	}
+	if _, err := c.Restore("recent"); err != nil {
+		t.Errorf("Expected recent object to be restorable, got %v", err)
+	}
+	if _, ok := c.Get("live"); !ok {
+		t.Error("Expected live object to be unaffected")
+	}
+}
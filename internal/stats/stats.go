@@ -0,0 +1,157 @@
+// Package stats samples server resource usage for capacity planning:
+// goroutines, heap, hub queue and client buffer utilization, and the uptime
+// of each component. Samples are kept in a rolling in-memory history so
+// trends survive between polls of GET /api/admin/stats.
+package stats
+
+import (
+	"runtime"
+	"sync"
+	"time"
+
+	"macro-analyst/pkg/ws"
+)
+
+const (
+	// DefaultHistorySize is the number of samples kept; one hour at the
+	// default interval
+	DefaultHistorySize = 360
+
+	// DefaultInterval is how often a sample is recorded into the history
+	DefaultInterval = 10 * time.Second
+)
+
+// Sample is a point-in-time snapshot of server resource usage.
+type Sample struct {
+	Timestamp                 time.Time `json:"timestamp"`
+	Goroutines                int       `json:"goroutines"`
+	HeapAllocBytes            uint64    `json:"heap_alloc_bytes"`
+	HeapObjects               uint64    `json:"heap_objects"`
+	Clients                   int       `json:"clients"`
+	BroadcastQueueDepth       int       `json:"broadcast_queue_depth"`
+	BroadcastQueueUtilization float64   `json:"broadcast_queue_utilization"` // 0-1
+	ClientBufferUtilization   float64   `json:"client_buffer_utilization"`   // 0-1, fullest client
+}
+
+// Collector samples resource usage and keeps a rolling history.
+type Collector struct {
+	hub       *ws.Hub
+	feeds     *ws.FeedSwitch
+	startedAt time.Time
+	now       func() time.Time
+
+	mu          sync.RWMutex
+	history     []Sample // Ring buffer of recorded samples
+	next        int      // Index the next sample is written to
+	historySize int
+}
+
+// Option is a functional option for configuring the Collector.
+type Option func(*Collector)
+
+// WithHistorySize sets how many samples are kept.
+func WithHistorySize(size int) Option {
+	return func(c *Collector) {
+		c.historySize = size
+	}
+}
+
+// NewCollector creates a Collector for the given hub and feeds. The feed
+// switch may be nil, in which case no feed uptimes are reported.
+func NewCollector(hub *ws.Hub, feeds *ws.FeedSwitch, opts ...Option) *Collector {
+	collector := &Collector{
+		hub:         hub,
+		feeds:       feeds,
+		startedAt:   time.Now(),
+		now:         time.Now,
+		historySize: DefaultHistorySize,
+	}
+
+	for _, opt := range opts {
+		opt(collector)
+	}
+
+	if collector.historySize < 1 {
+		collector.historySize = 1
+	}
+
+	return collector
+}
+
+// Sample takes a snapshot of current resource usage.
+func (c *Collector) Sample() Sample {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	sample := Sample{
+		Timestamp:      c.now().UTC(),
+		Goroutines:     runtime.NumGoroutine(),
+		HeapAllocBytes: mem.HeapAlloc,
+		HeapObjects:    mem.HeapObjects,
+	}
+
+	if c.hub != nil {
+		depth, capacity := c.hub.QueueDepth()
+		sample.Clients = c.hub.GetClientCount()
+		sample.BroadcastQueueDepth = depth
+		if capacity > 0 {
+			sample.BroadcastQueueUtilization = float64(depth) / float64(capacity)
+		}
+		sample.ClientBufferUtilization = c.hub.ClientBufferUtilization()
+	}
+
+	return sample
+}
+
+// Record takes a sample and appends it to the history, evicting the oldest
+// sample once the history is full.
+func (c *Collector) Record() {
+	sample := c.Sample()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.history) < c.historySize {
+		c.history = append(c.history, sample)
+		return
+	}
+	c.history[c.next] = sample
+	c.next = (c.next + 1) % c.historySize
+}
+
+// History returns the recorded samples, oldest first.
+func (c *Collector) History() []Sample {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	history := make([]Sample, 0, len(c.history))
+	history = append(history, c.history[c.next:]...)
+	history = append(history, c.history[:c.next]...)
+	return history
+}
+
+// Uptime returns the uptime in seconds of each component: "server", "hub"
+// and "feed:<name>" for every running feed. Components that have not
+// started are omitted.
+func (c *Collector) Uptime() map[string]float64 {
+	now := c.now()
+	uptime := map[string]float64{
+		"server": now.Sub(c.startedAt).Seconds(),
+	}
+
+	if c.hub != nil {
+		if startedAt := c.hub.StartedAt(); !startedAt.IsZero() {
+			uptime["hub"] = now.Sub(startedAt).Seconds()
+		}
+	}
+
+	if c.feeds != nil {
+		for _, feed := range c.feeds.Feeds() {
+			if feed.StartedAt != nil {
+				uptime["feed:"+feed.Name] = now.Sub(*feed.StartedAt).Seconds()
+			}
+		}
+	}
+
+	return uptime
+}
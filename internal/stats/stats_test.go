@@ -0,0 +1,88 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"macro-analyst/pkg/ws"
+)
+
+// TestSample tests that a sample reports runtime and hub state.
+func TestSample(t *testing.T) {
+	// Arrange
+	hub := ws.NewHub()
+	hub.Publish([]byte("queued"))
+	collector := NewCollector(hub, nil)
+
+	// Act
+	sample := collector.Sample()
+
+	// Assert
+	if sample.Goroutines <= 0 {
+		t.Errorf("Expected goroutines > 0, got %d", sample.Goroutines)
+	}
+	if sample.HeapAllocBytes == 0 {
+		t.Error("Expected heap usage to be reported")
+	}
+	if sample.BroadcastQueueDepth != 1 {
+		t.Errorf("Expected queue depth 1, got %d", sample.BroadcastQueueDepth)
+	}
+	if sample.BroadcastQueueUtilization <= 0 {
+		t.Errorf("Expected queue utilization > 0, got %f", sample.BroadcastQueueUtilization)
+	}
+}
+
+// TestHistoryRollsOver tests that the oldest samples are evicted.
+func TestHistoryRollsOver(t *testing.T) {
+	// Arrange
+	collector := NewCollector(nil, nil, WithHistorySize(3))
+	base := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	tick := 0
+	collector.now = func() time.Time {
+		tick++
+		return base.Add(time.Duration(tick) * time.Second)
+	}
+
+	// Act
+	for range 5 {
+		collector.Record()
+	}
+	history := collector.History()
+
+	// Assert
+	if len(history) != 3 {
+		t.Fatalf("Expected 3 samples, got %d", len(history))
+	}
+	for idx, sample := range history {
+		expected := base.Add(time.Duration(idx+3) * time.Second)
+		if !sample.Timestamp.Equal(expected) {
+			t.Errorf("Sample %d: expected %v, got %v", idx, expected, sample.Timestamp)
+		}
+	}
+}
+
+// TestUptime tests that uptime is reported only for started components.
+func TestUptime(t *testing.T) {
+	// Arrange
+	hub := ws.NewHub()
+	feeds := ws.NewFeedSwitch(hub)
+	if err := feeds.Add(ws.NewIngestor(ws.HubSink(hub))); err != nil {
+		t.Fatalf("Failed to add feed: %v", err)
+	}
+	collector := NewCollector(hub, feeds)
+	collector.startedAt = time.Now().Add(-time.Minute)
+
+	// Act
+	uptime := collector.Uptime()
+
+	// Assert
+	if uptime["server"] < 60 {
+		t.Errorf("Expected server uptime >= 60s, got %f", uptime["server"])
+	}
+	if _, exists := uptime["hub"]; exists {
+		t.Error("Expected no hub uptime before Run")
+	}
+	if len(uptime) != 1 {
+		t.Errorf("Expected only server uptime, got %v", uptime)
+	}
+}
@@ -0,0 +1,71 @@
+package surprise
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+
+	"macro-analyst/pkg/fred"
+)
+
+// DefaultCheckInterval is how often FRED is polled for new releases
+const DefaultCheckInterval = time.Hour
+
+// Notice is pushed to WebSocket clients when a release with an expectation
+// is published.
+type Notice struct {
+	Type         string   `json:"type"` // Always "macro_surprise"
+	Ticker       string   `json:"ticker"`
+	Date         string   `json:"date"`
+	Actual       float64  `json:"actual"`
+	Consensus    float64  `json:"consensus"`
+	Previous     *float64 `json:"previous,omitempty"`
+	Surprise     float64  `json:"surprise"`
+	Standardized *float64 `json:"standardized,omitempty"`
+	Index        *float64 `json:"index,omitempty"` // Surprise index including this release
+	Timestamp    int64    `json:"timestamp"`       // Unix ms when the release was detected
+}
+
+// Check fetches the latest observation of each ticker with pending
+// expectations and records it as the actual of the matching release. It
+// returns the releases published since the last check.
+func (s *Store) Check(ctx context.Context, client fred.Client) []Release {
+	var published []Release
+	for _, ticker := range s.Pending() {
+		latest, err := client.GetLatestValue(ctx, fred.Ticker(ticker))
+		if err != nil {
+			log.Printf("Failed to check %s for a new release: %v", ticker, err)
+			continue
+		}
+
+		// FRED marks missing observations with "."
+		actual, err := strconv.ParseFloat(latest.Value, 64)
+		if err != nil {
+			continue
+		}
+
+		if release, ok := s.RecordActual(ticker, latest.Date, actual); ok {
+			log.Printf("Release %s %s: actual %g vs consensus %g", ticker, latest.Date, actual, release.Consensus)
+			published = append(published, release)
+		}
+	}
+	return published
+}
+
+// NewNotice encodes the WebSocket notice for a published release.
+func NewNotice(release Release, index Index) ([]byte, error) {
+	return json.Marshal(&Notice{
+		Type:         "macro_surprise",
+		Ticker:       release.Ticker,
+		Date:         release.Date,
+		Actual:       *release.Actual,
+		Consensus:    release.Consensus,
+		Previous:     release.Previous,
+		Surprise:     release.Surprise,
+		Standardized: release.Standardized,
+		Index:        index.Value,
+		Timestamp:    release.ReleasedAt.UnixMilli(),
+	})
+}
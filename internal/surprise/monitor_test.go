@@ -0,0 +1,97 @@
+package surprise
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"macro-analyst/pkg/fred"
+)
+
+// stubFREDClient returns fixed latest observations per ticker.
+type stubFREDClient struct {
+	latest map[fred.Ticker]fred.LatestValue
+}
+
+func (c stubFREDClient) GetSeriesObservations(ctx context.Context, ticker fred.Ticker, opts *fred.QueryOptions) (*fred.SeriesData, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c stubFREDClient) GetLatestValue(ctx context.Context, ticker fred.Ticker) (*fred.LatestValue, error) {
+	latest, exists := c.latest[ticker]
+	if !exists {
+		return nil, errors.New("no observations")
+	}
+	return &latest, nil
+}
+
+func (c stubFREDClient) GetMultipleLatest(ctx context.Context, tickers []fred.Ticker) (*fred.MultiTickerResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c stubFREDClient) GetSeriesInfo(ctx context.Context, ticker fred.Ticker) (*fred.FREDSeriesInfo, error) {
+	return nil, errors.New("not implemented")
+}
+
+// TestCheck verifies only releases with a pending expectation matching the
+// latest observation are published.
+func TestCheck(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, 6, 12, 12, 30, 0, 0, time.UTC)
+	store := newTestStore(&now)
+	store.Import([]Expectation{
+		{Ticker: "CPIAUCSL", Date: "2024-05-01", Consensus: 313.1},
+		{Ticker: "FEDFUNDS", Date: "2024-06-01", Consensus: 5.33},
+		{Ticker: "WALCL", Date: "2024-06-12", Consensus: 7300000},
+		{Ticker: "UNRATE", Date: "2024-05-01", Consensus: 3.9},
+	})
+	client := stubFREDClient{latest: map[fred.Ticker]fred.LatestValue{
+		"CPIAUCSL": {Value: "313.5", Date: "2024-05-01"},
+		"FEDFUNDS": {Value: "5.33", Date: "2024-05-01"}, // June not published yet
+		"WALCL":    {Value: ".", Date: "2024-06-12"},    // Missing observation
+	}}
+
+	// Act
+	published := store.Check(context.Background(), client)
+	again := store.Check(context.Background(), client)
+
+	// Assert
+	if len(published) != 1 || published[0].Ticker != "CPIAUCSL" || *published[0].Actual != 313.5 {
+		t.Fatalf("Expected only the CPI release to be published, got %+v", published)
+	}
+	if len(again) != 0 {
+		t.Errorf("Expected no releases on the second check, got %+v", again)
+	}
+}
+
+// TestNewNotice verifies the WebSocket notice fields.
+func TestNewNotice(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, 6, 12, 12, 30, 0, 0, time.UTC)
+	store := newTestStore(&now)
+	store.Set(Expectation{Ticker: "CPIAUCSL", Date: "2024-05-01", Consensus: 313.1})
+	release, _ := store.RecordActual("CPIAUCSL", "2024-05-01", 313.5)
+
+	// Act
+	data, err := NewNotice(release, store.Index())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var notice map[string]any
+	if err := json.Unmarshal(data, &notice); err != nil {
+		t.Fatalf("Failed to decode notice: %v", err)
+	}
+	if notice["type"] != "macro_surprise" || notice["ticker"] != "CPIAUCSL" || notice["actual"] != 313.5 {
+		t.Errorf("Unexpected notice: %v", notice)
+	}
+	if notice["timestamp"] != float64(now.UnixMilli()) {
+		t.Errorf("Expected timestamp %d, got %v", now.UnixMilli(), notice["timestamp"])
+	}
+	if _, exists := notice["standardized"]; exists {
+		t.Errorf("Expected no standardized surprise, got %v", notice["standardized"])
+	}
+}
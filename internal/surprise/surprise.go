@@ -0,0 +1,416 @@
+// Package surprise tracks market expectations for key macro releases and
+// how far the published (actual) values deviated from them.
+//
+// Consensus and previous values are entered per release, identified by a
+// FRED ticker and observation date, either one at a time or imported in
+// bulk. Once FRED publishes the observation, its value is recorded as the
+// actual and the release contributes to a rolling surprise index:
+//
+//	store := surprise.NewStore(surprise.WithFile("data/expectations.json"))
+//	store.Set(surprise.Expectation{Ticker: "CPIAUCSL", Date: "2024-05-01", Consensus: 313.1})
+//
+//	sched.Every("macro-surprise", time.Hour, func(ctx context.Context) {
+//	    for _, release := range store.Check(ctx, fredClient) {
+//	        // Publish release and store.Index()
+//	    }
+//	})
+package surprise
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"macro-analyst/internal/analytics"
+)
+
+const (
+	// DateLayout is the format of observation dates (UTC)
+	DateLayout = "2006-01-02"
+
+	// DefaultWindow is how far back releases count towards the index
+	DefaultWindow = 90 * 24 * time.Hour
+
+	// MinSamples is the fewest released surprises a ticker needs before its
+	// surprises are standardized and counted in the index
+	MinSamples = 3
+)
+
+var (
+	// ErrInvalidExpectation is returned for expectations without a ticker
+	// or with a malformed date.
+	ErrInvalidExpectation = errors.New("invalid expectation")
+
+	// ErrNotFound is returned when no expectation exists for a release.
+	ErrNotFound = errors.New("expectation not found")
+)
+
+// Expectation holds what the market expected for one release of a FRED
+// series. Actual and ReleasedAt are set once FRED publishes the
+// observation, or when importing past releases.
+type Expectation struct {
+	Ticker     string     `json:"ticker"`
+	Date       string     `json:"date"` // Observation date of the release, e.g. "2024-05-01"
+	Consensus  float64    `json:"consensus"`
+	Previous   *float64   `json:"previous,omitempty"`
+	Actual     *float64   `json:"actual,omitempty"`
+	ReleasedAt *time.Time `json:"released_at,omitempty"`
+}
+
+// Release is a published release and its surprise.
+type Release struct {
+	Expectation
+
+	// Surprise is the actual minus the consensus, in the series' units
+	Surprise float64 `json:"surprise"`
+
+	// Standardized is the surprise divided by the standard deviation of the
+	// ticker's surprises, nil until the ticker has MinSamples releases
+	Standardized *float64 `json:"standardized"`
+}
+
+// Index is the rolling surprise index: the mean standardized surprise of
+// the releases within the window. A positive value means data has come in
+// above expectations on average.
+type Index struct {
+	Value      *float64  `json:"value"`
+	WindowDays int       `json:"window_days"`
+	AsOf       time.Time `json:"as_of"`
+	Releases   []Release `json:"releases"` // Releases within the window, newest first
+}
+
+// Store holds expectations by release. It is safe for concurrent use.
+type Store struct {
+	mu           sync.RWMutex
+	expectations map[string]*Expectation // ticker|date -> expectation
+
+	file   string
+	window time.Duration
+	now    func() time.Time
+}
+
+// Option is a functional option for configuring the Store.
+type Option func(*Store)
+
+// WithFile sets the file expectations are persisted to. An empty path
+// disables persistence.
+func WithFile(path string) Option {
+	return func(s *Store) {
+		s.file = path
+	}
+}
+
+// WithWindow sets how far back releases count towards the index.
+func WithWindow(window time.Duration) Option {
+	return func(s *Store) {
+		s.window = window
+	}
+}
+
+// NewStore creates a new Store.
+func NewStore(opts ...Option) *Store {
+	store := &Store{
+		expectations: make(map[string]*Expectation),
+		window:       DefaultWindow,
+		now:          time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(store)
+	}
+
+	return store
+}
+
+// releaseKey identifies a release in the store.
+func releaseKey(ticker, date string) string {
+	return ticker + "|" + date
+}
+
+// normalize validates an expectation and canonicalizes its ticker and date.
+func normalize(exp Expectation) (Expectation, error) {
+	exp.Ticker = strings.ToUpper(strings.TrimSpace(exp.Ticker))
+	if exp.Ticker == "" {
+		return exp, fmt.Errorf("%w: ticker is required", ErrInvalidExpectation)
+	}
+	date, err := time.Parse(DateLayout, strings.TrimSpace(exp.Date))
+	if err != nil {
+		return exp, fmt.Errorf("%w: date %q must be YYYY-MM-DD", ErrInvalidExpectation, exp.Date)
+	}
+	exp.Date = date.Format(DateLayout)
+	if exp.Actual != nil && exp.ReleasedAt == nil {
+		// Past releases imported without a release time count from their date
+		exp.ReleasedAt = &date
+	}
+	return exp, nil
+}
+
+// Set adds or replaces the expectation for a release. An actual value
+// already recorded is kept unless the new expectation carries one.
+func (s *Store) Set(exp Expectation) (Expectation, error) {
+	exp, err := normalize(exp)
+	if err != nil {
+		return exp, err
+	}
+
+	s.mu.Lock()
+	s.put(exp)
+	stored := *s.expectations[releaseKey(exp.Ticker, exp.Date)]
+	s.mu.Unlock()
+
+	return stored, s.Save()
+}
+
+// Import adds or replaces expectations in bulk. Nothing is stored if any
+// expectation is invalid.
+func (s *Store) Import(exps []Expectation) (int, error) {
+	normalized := make([]Expectation, len(exps))
+	for idx, exp := range exps {
+		var err error
+		if normalized[idx], err = normalize(exp); err != nil {
+			return 0, fmt.Errorf("expectation %d: %w", idx, err)
+		}
+	}
+
+	s.mu.Lock()
+	for _, exp := range normalized {
+		s.put(exp)
+	}
+	s.mu.Unlock()
+
+	return len(normalized), s.Save()
+}
+
+// put stores a normalized expectation. The caller must hold s.mu.
+func (s *Store) put(exp Expectation) {
+	key := releaseKey(exp.Ticker, exp.Date)
+	if existing, exists := s.expectations[key]; exists && exp.Actual == nil {
+		exp.Actual = existing.Actual
+		exp.ReleasedAt = existing.ReleasedAt
+	}
+	s.expectations[key] = &exp
+}
+
+// Delete removes the expectation for a release.
+func (s *Store) Delete(ticker, date string) error {
+	key := releaseKey(strings.ToUpper(ticker), date)
+
+	s.mu.Lock()
+	if _, exists := s.expectations[key]; !exists {
+		s.mu.Unlock()
+		return ErrNotFound
+	}
+	delete(s.expectations, key)
+	s.mu.Unlock()
+
+	return s.Save()
+}
+
+// List returns the expectations for a ticker, or for all tickers when
+// ticker is empty, ordered by ticker and date.
+func (s *Store) List(ticker string) []Expectation {
+	ticker = strings.ToUpper(ticker)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	exps := []Expectation{}
+	for _, exp := range s.expectations {
+		if ticker == "" || exp.Ticker == ticker {
+			exps = append(exps, *exp)
+		}
+	}
+	sortExpectations(exps)
+	return exps
+}
+
+// Pending returns the tickers with at least one release not published yet.
+func (s *Store) Pending() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var tickers []string
+	for _, exp := range s.expectations {
+		if exp.Actual == nil && !seen[exp.Ticker] {
+			seen[exp.Ticker] = true
+			tickers = append(tickers, exp.Ticker)
+		}
+	}
+	sort.Strings(tickers)
+	return tickers
+}
+
+// RecordActual records the published value of a release. It reports false
+// if no expectation exists for the release or its actual is already known.
+func (s *Store) RecordActual(ticker, date string, actual float64) (Release, bool) {
+	key := releaseKey(strings.ToUpper(ticker), date)
+
+	s.mu.Lock()
+	exp, exists := s.expectations[key]
+	if !exists || exp.Actual != nil {
+		s.mu.Unlock()
+		return Release{}, false
+	}
+	releasedAt := s.now().UTC()
+	exp.Actual = &actual
+	exp.ReleasedAt = &releasedAt
+	release := s.releases(exp.Ticker)[key]
+	s.mu.Unlock()
+
+	if err := s.Save(); err != nil {
+		log.Printf("Failed to save expectations: %v", err)
+	}
+	return release, true
+}
+
+// Index computes the surprise index over the releases within the window.
+func (s *Store) Index() Index {
+	now := s.now().UTC()
+	cutoff := now.Add(-s.window)
+
+	s.mu.RLock()
+	released := s.releases("")
+	s.mu.RUnlock()
+
+	index := Index{
+		WindowDays: int(s.window / (24 * time.Hour)),
+		AsOf:       now,
+		Releases:   []Release{},
+	}
+	var standardized []float64
+	for _, release := range released {
+		if release.ReleasedAt.Before(cutoff) || release.ReleasedAt.After(now) {
+			continue
+		}
+		index.Releases = append(index.Releases, release)
+		if release.Standardized != nil {
+			standardized = append(standardized, *release.Standardized)
+		}
+	}
+
+	sort.Slice(index.Releases, func(a, b int) bool {
+		return index.Releases[a].ReleasedAt.After(*index.Releases[b].ReleasedAt)
+	})
+	if len(standardized) > 0 {
+		var sum float64
+		for _, value := range standardized {
+			sum += value
+		}
+		value := sum / float64(len(standardized))
+		index.Value = &value
+	}
+	return index
+}
+
+// releases computes the surprises of all published releases of a ticker,
+// or of all tickers when ticker is empty, keyed like expectations. Each
+// surprise is standardized against all published surprises of its ticker.
+// The caller must hold s.mu.
+func (s *Store) releases(ticker string) map[string]Release {
+	byTicker := make(map[string][]float64)
+	releases := make(map[string]Release)
+	for key, exp := range s.expectations {
+		if exp.Actual == nil || (ticker != "" && exp.Ticker != ticker) {
+			continue
+		}
+		surprise := *exp.Actual - exp.Consensus
+		byTicker[exp.Ticker] = append(byTicker[exp.Ticker], surprise)
+		releases[key] = Release{Expectation: *exp, Surprise: surprise}
+	}
+
+	for key, release := range releases {
+		surprises := byTicker[release.Ticker]
+		if len(surprises) < MinSamples {
+			continue
+		}
+		if deviation := analytics.StdDev(surprises); deviation > 0 {
+			value := release.Surprise / deviation
+			release.Standardized = &value
+			releases[key] = release
+		}
+	}
+	return releases
+}
+
+// sortExpectations orders expectations by ticker and date.
+func sortExpectations(exps []Expectation) {
+	sort.Slice(exps, func(a, b int) bool {
+		if exps[a].Ticker != exps[b].Ticker {
+			return exps[a].Ticker < exps[b].Ticker
+		}
+		return exps[a].Date < exps[b].Date
+	})
+}
+
+// Save persists expectations to the configured file. It is a no-op when no
+// file is configured.
+func (s *Store) Save() error {
+	if s.file == "" {
+		return nil
+	}
+
+	exps := s.List("")
+	data, err := json.MarshalIndent(exps, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal expectations: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.file), 0o755); err != nil {
+		return fmt.Errorf("failed to create expectations directory: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves partial expectations
+	tmpFile := s.file + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write expectations: %w", err)
+	}
+	if err := os.Rename(tmpFile, s.file); err != nil {
+		return fmt.Errorf("failed to replace expectations: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads persisted expectations from the configured file. The file
+// holds a JSON array of expectations, so it can also be prepared by hand
+// to import releases at startup. A missing file is not an error.
+func (s *Store) Load() error {
+	if s.file == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read expectations: %w", err)
+	}
+
+	var exps []Expectation
+	if err := json.Unmarshal(data, &exps); err != nil {
+		return fmt.Errorf("failed to parse expectations: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for idx, exp := range exps {
+		exp, err := normalize(exp)
+		if err != nil {
+			return fmt.Errorf("expectation %d: %w", idx, err)
+		}
+		s.put(exp)
+	}
+
+	log.Printf("Loaded %d expectations from %s", len(exps), s.file)
+	return nil
+}
@@ -0,0 +1,218 @@
+package surprise
+
+import (
+	"errors"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"macro-analyst/internal/analytics"
+)
+
+// newTestStore creates a Store with a controllable clock.
+func newTestStore(now *time.Time, opts ...Option) *Store {
+	store := NewStore(opts...)
+	store.now = func() time.Time { return *now }
+	return store
+}
+
+func float64Ptr(value float64) *float64 {
+	return &value
+}
+
+// TestSetNormalizesAndValidates verifies tickers are uppercased and invalid
+// expectations are rejected.
+func TestSetNormalizesAndValidates(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	store := newTestStore(&now)
+
+	stored, err := store.Set(Expectation{Ticker: " cpiaucsl ", Date: "2024-05-01", Consensus: 313.1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stored.Ticker != "CPIAUCSL" || stored.Date != "2024-05-01" {
+		t.Errorf("Expected normalized expectation, got %+v", stored)
+	}
+
+	for _, exp := range []Expectation{
+		{Date: "2024-05-01"},
+		{Ticker: "CPIAUCSL", Date: "05/01/2024"},
+	} {
+		if _, err := store.Set(exp); !errors.Is(err, ErrInvalidExpectation) {
+			t.Errorf("Expected ErrInvalidExpectation for %+v, got %v", exp, err)
+		}
+	}
+
+	if exps := store.List(""); len(exps) != 1 {
+		t.Errorf("Expected 1 expectation, got %d", len(exps))
+	}
+}
+
+// TestSetKeepsRecordedActual verifies updating the consensus of a published
+// release keeps its actual.
+func TestSetKeepsRecordedActual(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	store := newTestStore(&now)
+	store.Set(Expectation{Ticker: "FEDFUNDS", Date: "2024-05-01", Consensus: 5.33})
+	store.RecordActual("FEDFUNDS", "2024-05-01", 5.31)
+
+	stored, _ := store.Set(Expectation{Ticker: "FEDFUNDS", Date: "2024-05-01", Consensus: 5.30})
+
+	if stored.Actual == nil || *stored.Actual != 5.31 || stored.Consensus != 5.30 {
+		t.Errorf("Expected actual to be kept with new consensus, got %+v", stored)
+	}
+}
+
+// TestImportIsAllOrNothing verifies an invalid entry rejects the whole import.
+func TestImportIsAllOrNothing(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	store := newTestStore(&now)
+
+	_, err := store.Import([]Expectation{
+		{Ticker: "CPIAUCSL", Date: "2024-05-01"},
+		{Ticker: "", Date: "2024-05-01"},
+	})
+	if !errors.Is(err, ErrInvalidExpectation) {
+		t.Errorf("Expected ErrInvalidExpectation, got %v", err)
+	}
+	if exps := store.List(""); len(exps) != 0 {
+		t.Errorf("Expected nothing stored, got %+v", exps)
+	}
+
+	imported, err := store.Import([]Expectation{
+		{Ticker: "CPIAUCSL", Date: "2024-05-01"},
+		{Ticker: "FEDFUNDS", Date: "2024-05-01"},
+	})
+	if err != nil || imported != 2 {
+		t.Errorf("Expected 2 imported, got %d (%v)", imported, err)
+	}
+}
+
+// TestRecordActual verifies actuals are recorded once and only for known releases.
+func TestRecordActual(t *testing.T) {
+	now := time.Date(2024, 6, 12, 12, 30, 0, 0, time.UTC)
+	store := newTestStore(&now)
+	store.Set(Expectation{Ticker: "CPIAUCSL", Date: "2024-05-01", Consensus: 313.1, Previous: float64Ptr(312.2)})
+
+	if pending := store.Pending(); len(pending) != 1 || pending[0] != "CPIAUCSL" {
+		t.Errorf("Expected CPIAUCSL pending, got %v", pending)
+	}
+
+	release, ok := store.RecordActual("CPIAUCSL", "2024-05-01", 313.5)
+	if !ok {
+		t.Fatal("Expected the actual to be recorded")
+	}
+	if math.Abs(release.Surprise-0.4) > 1e-9 || !release.ReleasedAt.Equal(now) {
+		t.Errorf("Unexpected release: %+v", release)
+	}
+	if release.Standardized != nil {
+		t.Errorf("Expected no standardized surprise below %d samples, got %v", MinSamples, *release.Standardized)
+	}
+
+	if _, ok := store.RecordActual("CPIAUCSL", "2024-05-01", 313.9); ok {
+		t.Error("Expected a published release not to be recorded again")
+	}
+	if _, ok := store.RecordActual("CPIAUCSL", "2024-06-01", 314.0); ok {
+		t.Error("Expected a release without expectation not to be recorded")
+	}
+	if pending := store.Pending(); len(pending) != 0 {
+		t.Errorf("Expected nothing pending, got %v", pending)
+	}
+}
+
+// TestIndex verifies surprises are standardized per ticker and averaged
+// over the releases within the window.
+func TestIndex(t *testing.T) {
+	now := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	store := newTestStore(&now, WithWindow(90*24*time.Hour))
+	store.Import([]Expectation{
+		// Surprises of +1, -1 and +3 (stddev 2)
+		{Ticker: "CPIAUCSL", Date: "2024-04-01", Consensus: 10, Actual: float64Ptr(11)},
+		{Ticker: "CPIAUCSL", Date: "2024-05-01", Consensus: 10, Actual: float64Ptr(9)},
+		{Ticker: "CPIAUCSL", Date: "2024-06-01", Consensus: 10, Actual: float64Ptr(13)},
+		// Too few releases to be standardized
+		{Ticker: "FEDFUNDS", Date: "2024-06-01", Consensus: 5.33, Actual: float64Ptr(5.5)},
+		// Outside the window
+		{Ticker: "CPIAUCSL", Date: "2024-01-01", Consensus: 10, Actual: float64Ptr(10)},
+		// Not published yet
+		{Ticker: "CPIAUCSL", Date: "2024-07-01", Consensus: 10},
+	})
+
+	index := store.Index()
+
+	if index.WindowDays != 90 || len(index.Releases) != 4 {
+		t.Fatalf("Expected 4 releases in a 90-day window, got %d in %d", len(index.Releases), index.WindowDays)
+	}
+	if index.Releases[0].Date != "2024-06-01" || index.Releases[3].Date != "2024-04-01" {
+		t.Errorf("Expected releases newest first, got %+v", index.Releases)
+	}
+	// Standardized against all four CPI surprises (0, 1, -1, 3)
+	deviation := analytics.StdDev([]float64{0, 1, -1, 3})
+	expected := (1 - 1 + 3) / deviation / 3
+	if index.Value == nil || math.Abs(*index.Value-expected) > 1e-9 {
+		t.Errorf("Expected index %v, got %v", expected, index.Value)
+	}
+}
+
+// TestIndexEmpty verifies an index without releases has no value.
+func TestIndexEmpty(t *testing.T) {
+	now := time.Now()
+	store := newTestStore(&now)
+
+	index := store.Index()
+
+	if index.Value != nil || index.Releases == nil || len(index.Releases) != 0 {
+		t.Errorf("Expected empty index, got %+v", index)
+	}
+}
+
+// TestDelete verifies expectations can be removed.
+func TestDelete(t *testing.T) {
+	now := time.Now()
+	store := newTestStore(&now)
+	store.Set(Expectation{Ticker: "WALCL", Date: "2024-05-01"})
+
+	if err := store.Delete("walcl", "2024-05-01"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := store.Delete("WALCL", "2024-05-01"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+// TestSaveAndLoad verifies expectations and recorded actuals survive a restart.
+func TestSaveAndLoad(t *testing.T) {
+	now := time.Date(2024, 6, 12, 0, 0, 0, 0, time.UTC)
+	file := filepath.Join(t.TempDir(), "data", "expectations.json")
+
+	store := newTestStore(&now, WithFile(file))
+	store.Set(Expectation{Ticker: "CPIAUCSL", Date: "2024-05-01", Consensus: 313.1})
+	store.Set(Expectation{Ticker: "FEDFUNDS", Date: "2024-05-01", Consensus: 5.33})
+	store.RecordActual("FEDFUNDS", "2024-05-01", 5.33)
+
+	restored := newTestStore(&now, WithFile(file))
+	if err := restored.Load(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	exps := restored.List("")
+	if len(exps) != 2 {
+		t.Fatalf("Expected 2 expectations, got %d", len(exps))
+	}
+	if exps[0].Ticker != "CPIAUCSL" || exps[0].Actual != nil {
+		t.Errorf("Unexpected pending expectation: %+v", exps[0])
+	}
+	if exps[1].Actual == nil || *exps[1].Actual != 5.33 || !exps[1].ReleasedAt.Equal(now) {
+		t.Errorf("Unexpected published expectation: %+v", exps[1])
+	}
+}
+
+// TestLoadMissingFile verifies a missing file is not an error.
+func TestLoadMissingFile(t *testing.T) {
+	store := NewStore(WithFile(filepath.Join(t.TempDir(), "missing.json")))
+
+	if err := store.Load(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
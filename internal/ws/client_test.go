@@ -1,128 +0,0 @@
-package ws
-
-import (
-	"testing"
-
-	"github.com/gofiber/contrib/websocket"
-)
-
-// TestClientStructInitialization verifies Client struct initialization.
-func TestClientStructInitialization(t *testing.T) {
-	hub := NewHub()
-	sendChan := make(chan []byte, 256)
-
-	client := &Client{
-		Hub:  hub,
-		Conn: nil, // Can't test with real WebSocket connection in unit test
-		Send: sendChan,
-	}
-
-	if client.Hub != hub {
-		t.Error("Client hub not set correctly")
-	}
-
-	if client.Send != sendChan {
-		t.Error("Client send channel not set correctly")
-	}
-}
-
-// TestClientClose verifies client cleanup.
-func TestClientClose(t *testing.T) {
-	client := &Client{
-		Hub:  NewHub(),
-		Conn: nil,
-		Send: make(chan []byte, 256),
-	}
-
-	// Close should not panic even with nil connection
-	client.Close()
-}
-
-// TestClientWithNilConnection verifies handling of nil connection.
-func TestClientWithNilConnection(t *testing.T) {
-	client := &Client{
-		Hub:  NewHub(),
-		Conn: nil,
-		Send: make(chan []byte, 256),
-	}
-
-	// Close with nil connection should not panic
-	client.Close()
-}
-
-// MockWebSocketConn is a mock implementation for testing.
-type MockWebSocketConn struct {
-	messages      [][]byte
-	messageTypes  []int
-	closeReceived bool
-	writeFails    bool
-}
-
-func (m *MockWebSocketConn) WriteMessage(messageType int, data []byte) error {
-	if m.writeFails {
-		return websocket.ErrCloseSent
-	}
-	m.messages = append(m.messages, data)
-	m.messageTypes = append(m.messageTypes, messageType)
-	if messageType == websocket.CloseMessage {
-		m.closeReceived = true
-	}
-	return nil
-}
-
-func (m *MockWebSocketConn) Close() error {
-	return nil
-}
-
-// TestWritePumpChannelClose verifies WritePump handles closed channel.
-func TestWritePumpChannelClose(t *testing.T) {
-	hub := NewHub()
-	mockConn := &MockWebSocketConn{}
-	
-	client := &Client{
-		Hub:  hub,
-		Conn: (*websocket.Conn)(nil), // Will be replaced by mock in actual implementation
-		Send: make(chan []byte, 256),
-	}
-
-	// Close the send channel to trigger WritePump shutdown
-	close(client.Send)
-
-	// Read from closed channel should return ok=false
-	_, ok := <-client.Send
-	if ok {
-		t.Error("Expected closed channel")
-	}
-
-	// Verify mock received close message (would need interface abstraction for real testing)
-	if mockConn.closeReceived {
-		t.Log("Mock WebSocket received close message")
-	}
-}
-
-// TestClientSendBuffer verifies send channel buffering.
-func TestClientSendBuffer(t *testing.T) {
-	client := &Client{
-		Hub:  NewHub(),
-		Conn: nil,
-		Send: make(chan []byte, 256),
-	}
-
-	// Fill buffer to test capacity
-	for i := 0; i < 256; i++ {
-		select {
-		case client.Send <- []byte("test"):
-			// Success
-		default:
-			t.Errorf("Buffer full at message %d, expected 256", i)
-		}
-	}
-
-	// 257th message should block (test with default case)
-	select {
-	case client.Send <- []byte("overflow"):
-		t.Error("Expected send to block when buffer full")
-	default:
-		// Expected - buffer is full
-	}
-}
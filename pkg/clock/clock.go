@@ -0,0 +1,199 @@
+// Package clock abstracts the current time and tickers so components that
+// run on timers can be tested deterministically.
+//
+// Production code uses Real; tests inject a Fake and advance it explicitly
+// instead of sleeping:
+//
+//	clk := clock.NewFake(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+//	hub := ws.NewHub(ws.WithHubClock(clk))
+//	clk.Advance(time.Second) // fires every ticker and timer due by then
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and creates tickers and timers.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+	NewTimer(d time.Duration) Timer
+}
+
+// Ticker delivers ticks at intervals, like time.Ticker.
+type Ticker interface {
+	C() <-chan time.Time
+	Reset(d time.Duration)
+	Stop()
+}
+
+// Timer delivers a single tick, like time.Timer.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// Real is the system clock.
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTicker(d time.Duration) Ticker { return realTicker{time.NewTicker(d)} }
+
+func (realClock) NewTimer(d time.Duration) Timer { return realTimer{time.NewTimer(d)} }
+
+type realTicker struct{ *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.Ticker.C }
+
+type realTimer struct{ *time.Timer }
+
+func (t realTimer) C() <-chan time.Time { return t.Timer.C }
+
+// Fake is a Clock that only moves when advanced. Tickers and timers fire
+// as Advance passes their deadlines; like their time counterparts they drop
+// ticks the receiver is not ready for.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+	added   chan struct{} // Signaled whenever a ticker or timer is created
+}
+
+// fakeWaiter is a pending Fake ticker or timer.
+type fakeWaiter struct {
+	clock    *Fake
+	c        chan time.Time
+	deadline time.Time
+	period   time.Duration // 0 for timers
+}
+
+// NewFake creates a Fake clock reading now.
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now, added: make(chan struct{}, 1)}
+}
+
+// Now returns the fake time.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// NewTicker creates a ticker firing every d of fake time.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	return fakeTicker{f.addWaiter(d, d)}
+}
+
+// NewTimer creates a timer firing after d of fake time.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	return fakeTimer{f.addWaiter(d, 0)}
+}
+
+// Advance moves the clock forward by d, firing tickers and timers in
+// deadline order as it passes them.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	end := f.now.Add(d)
+	for {
+		sort.Slice(f.waiters, func(a, b int) bool {
+			return f.waiters[a].deadline.Before(f.waiters[b].deadline)
+		})
+		if len(f.waiters) == 0 || f.waiters[0].deadline.After(end) {
+			break
+		}
+
+		waiter := f.waiters[0]
+		f.now = waiter.deadline
+		select {
+		case waiter.c <- f.now:
+		default:
+		}
+
+		if waiter.period > 0 {
+			waiter.deadline = waiter.deadline.Add(waiter.period)
+		} else {
+			f.waiters = f.waiters[1:]
+		}
+	}
+	f.now = end
+}
+
+// BlockUntil waits until at least n tickers and timers are pending, so a
+// test can advance the clock only once the goroutine under test created
+// its ticker.
+func (f *Fake) BlockUntil(n int) {
+	for {
+		f.mu.Lock()
+		pending := len(f.waiters)
+		f.mu.Unlock()
+		if pending >= n {
+			return
+		}
+		<-f.added
+	}
+}
+
+// addWaiter registers a ticker or timer due after d.
+func (f *Fake) addWaiter(d, period time.Duration) *fakeWaiter {
+	f.mu.Lock()
+	waiter := &fakeWaiter{clock: f, c: make(chan time.Time, 1), deadline: f.now.Add(d), period: period}
+	f.waiters = append(f.waiters, waiter)
+	f.mu.Unlock()
+
+	select {
+	case f.added <- struct{}{}:
+	default:
+	}
+	return waiter
+}
+
+// remove unregisters a waiter and reports whether it was pending.
+func (f *Fake) remove(waiter *fakeWaiter) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for idx, pending := range f.waiters {
+		if pending == waiter {
+			f.waiters = append(f.waiters[:idx], f.waiters[idx+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// fakeTicker is a Fake ticker.
+type fakeTicker struct{ waiter *fakeWaiter }
+
+func (t fakeTicker) C() <-chan time.Time { return t.waiter.c }
+
+func (t fakeTicker) Stop() { t.waiter.clock.remove(t.waiter) }
+
+// Reset restarts the ticker with period d from the current fake time.
+func (t fakeTicker) Reset(d time.Duration) {
+	f := t.waiter.clock
+	f.remove(t.waiter)
+
+	f.mu.Lock()
+	t.waiter.deadline = f.now.Add(d)
+	t.waiter.period = d
+	f.waiters = append(f.waiters, t.waiter)
+	f.mu.Unlock()
+}
+
+// fakeTimer is a Fake timer.
+type fakeTimer struct{ waiter *fakeWaiter }
+
+func (t fakeTimer) C() <-chan time.Time { return t.waiter.c }
+
+// Stop stops the timer and reports whether it was pending.
+func (t fakeTimer) Stop() bool { return t.waiter.clock.remove(t.waiter) }
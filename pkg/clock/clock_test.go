@@ -0,0 +1,91 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+// TestFakeTicker verifies tickers fire once per period as the clock advances
+// and drop ticks that are not received.
+func TestFakeTicker(t *testing.T) {
+	clk := NewFake(time.Unix(0, 0))
+	ticker := clk.NewTicker(time.Second)
+
+	clk.Advance(999 * time.Millisecond)
+	if len(ticker.C()) != 0 {
+		t.Fatal("Expected no tick before the period elapsed")
+	}
+
+	clk.Advance(5 * time.Second)
+	if tick := <-ticker.C(); !tick.Equal(time.Unix(1, 0)) {
+		t.Errorf("Expected the first tick at 1s, got %v", tick)
+	}
+	if len(ticker.C()) != 0 {
+		t.Error("Expected ticks the receiver missed to be dropped")
+	}
+	if now := clk.Now(); !now.Equal(time.Unix(5, 999*int64(time.Millisecond))) {
+		t.Errorf("Expected the clock at 5.999s, got %v", now)
+	}
+
+	ticker.Stop()
+	clk.Advance(time.Hour)
+	if len(ticker.C()) != 0 {
+		t.Error("Expected a stopped ticker not to fire")
+	}
+}
+
+// TestFakeTickerReset verifies Reset restarts the period from the current time.
+func TestFakeTickerReset(t *testing.T) {
+	clk := NewFake(time.Unix(0, 0))
+	ticker := clk.NewTicker(time.Second)
+
+	clk.Advance(500 * time.Millisecond)
+	ticker.Reset(2 * time.Second)
+	clk.Advance(time.Second)
+	if len(ticker.C()) != 0 {
+		t.Fatal("Expected no tick at the old period")
+	}
+
+	clk.Advance(time.Second)
+	if tick := <-ticker.C(); !tick.Equal(time.Unix(2, 500*int64(time.Millisecond))) {
+		t.Errorf("Expected a tick at 2.5s, got %v", tick)
+	}
+}
+
+// TestFakeTimer verifies timers fire once and report whether Stop
+// prevented them.
+func TestFakeTimer(t *testing.T) {
+	clk := NewFake(time.Unix(0, 0))
+	fired := clk.NewTimer(time.Minute)
+	stopped := clk.NewTimer(time.Minute)
+
+	if !stopped.Stop() {
+		t.Error("Expected Stop to report a pending timer")
+	}
+	clk.Advance(time.Hour)
+
+	if len(fired.C()) != 1 || len(stopped.C()) != 0 {
+		t.Error("Expected only the running timer to fire")
+	}
+	if fired.Stop() {
+		t.Error("Expected Stop to report a timer that already fired")
+	}
+}
+
+// TestFakeBlockUntil verifies BlockUntil waits for a goroutine to create
+// its ticker.
+func TestFakeBlockUntil(t *testing.T) {
+	clk := NewFake(time.Unix(0, 0))
+	ticks := make(chan time.Time)
+
+	go func() {
+		ticker := clk.NewTicker(time.Second)
+		ticks <- <-ticker.C()
+	}()
+
+	clk.BlockUntil(1)
+	clk.Advance(time.Second)
+	if tick := <-ticks; !tick.Equal(time.Unix(1, 0)) {
+		t.Errorf("Expected a tick at 1s, got %v", tick)
+	}
+}
@@ -0,0 +1,295 @@
+// Package feedlog records when the Binance feeds connect, disconnect and
+// reconnect, persisted across restarts, so gaps in charts can be explained
+// and feed uptime computed:
+//
+//	history := feedlog.New(feedlog.WithFile("data/feed-history.json"))
+//	history.Record("binance", feedlog.Connected, "", 6)
+//	uptime := history.Uptime(time.Now().Add(-24*time.Hour), time.Now())
+package feedlog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultLimit is how many events are kept, oldest dropped first
+const DefaultLimit = 1000
+
+// EventType is what happened to a feed connection.
+type EventType string
+
+const (
+	// Connected is recorded when a feed opens its first connection
+	Connected EventType = "connected"
+
+	// Reconnected is recorded when a feed opens a connection again
+	Reconnected EventType = "reconnected"
+
+	// Disconnected is recorded when a connection closes, with the cause
+	Disconnected EventType = "disconnected"
+
+	// ConnectFailed is recorded when a connection could not be opened
+	ConnectFailed EventType = "connect_failed"
+)
+
+// CauseRestart is the cause of the disconnects Load records for feeds that
+// were still connected when the previous server process exited.
+const CauseRestart = "server restarted"
+
+// Event is a change of a feed's connection.
+type Event struct {
+	Time    time.Time `json:"time"`
+	Feed    string    `json:"feed"` // Source name of the ingestor
+	Type    EventType `json:"type"`
+	Cause   string    `json:"cause,omitempty"`
+	Symbols int       `json:"symbols,omitempty"` // Symbols streamed by the connection
+}
+
+// up reports whether the feed is connected after the event.
+func (e Event) up() bool {
+	return e.Type == Connected || e.Type == Reconnected
+}
+
+// FeedUptime is the connection time of a feed within a window.
+type FeedUptime struct {
+	Feed        string  `json:"feed"`
+	Connected   bool    `json:"connected"`    // Connected at the end of the window
+	Uptime      float64 `json:"uptime"`       // Connected share of the observed time, 0 to 1
+	ConnectedMs int64   `json:"connected_ms"` // Time connected
+	ObservedMs  int64   `json:"observed_ms"`  // Window time since the feed's first event
+	Disconnects int     `json:"disconnects"`  // Disconnects and failed connects
+}
+
+// Log keeps the most recent feed events. It is safe for concurrent use.
+type Log struct {
+	mu     sync.Mutex
+	events []Event // Oldest first
+
+	file  string
+	limit int
+	now   func() time.Time
+}
+
+// Option is a functional option for configuring the Log.
+type Option func(*Log)
+
+// WithFile sets the file events are persisted to. An empty path disables
+// persistence.
+func WithFile(path string) Option {
+	return func(l *Log) {
+		l.file = path
+	}
+}
+
+// WithLimit sets how many events are kept.
+func WithLimit(limit int) Option {
+	return func(l *Log) {
+		l.limit = limit
+	}
+}
+
+// New creates a new Log.
+func New(opts ...Option) *Log {
+	history := &Log{
+		limit: DefaultLimit,
+		now:   time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(history)
+	}
+
+	return history
+}
+
+// Record appends an event of the feed, with the cause if any, and persists
+// the history. Events are rare, so every one is written through.
+func (l *Log) Record(feed string, eventType EventType, cause string, symbols int) {
+	event := Event{Time: l.now(), Feed: feed, Type: eventType, Cause: cause, Symbols: symbols}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.append(event)
+	if err := l.save(); err != nil {
+		log.Printf("Failed to save feed history: %v", err)
+	}
+}
+
+// Events returns the events since the given time, oldest first, optionally
+// only those of one feed.
+func (l *Log) Events(since time.Time, feed string) []Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	events := []Event{}
+	for _, event := range l.events {
+		if event.Time.Before(since) || (feed != "" && event.Feed != feed) {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// Uptime returns the connection time of each feed between since and until,
+// sorted by feed. Time before a feed's first event is not observed, so a
+// feed added during the window is not charged for the time before.
+func (l *Log) Uptime(since, until time.Time) []FeedUptime {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	type state struct {
+		uptime   FeedUptime
+		observed bool
+		up       bool
+		at       time.Time // Start of the current state within the window
+	}
+	feeds := make(map[string]*state)
+
+	// Time spent in the current state up to the given time
+	advance := func(feed *state, to time.Time) {
+		if !feed.observed || !to.After(feed.at) {
+			return
+		}
+		elapsed := to.Sub(feed.at)
+		feed.uptime.ObservedMs += elapsed.Milliseconds()
+		if feed.up {
+			feed.uptime.ConnectedMs += elapsed.Milliseconds()
+		}
+		feed.at = to
+	}
+
+	for _, event := range l.events {
+		if event.Time.After(until) {
+			break
+		}
+		feed, exists := feeds[event.Feed]
+		if !exists {
+			feed = &state{uptime: FeedUptime{Feed: event.Feed}}
+			feeds[event.Feed] = feed
+		}
+
+		advance(feed, event.Time)
+		if !feed.observed {
+			feed.observed = true
+			feed.at = event.Time
+			if feed.at.Before(since) {
+				feed.at = since
+			}
+		}
+		feed.up = event.up()
+		if !event.Time.Before(since) && !feed.up {
+			feed.uptime.Disconnects++
+		}
+	}
+
+	uptimes := make([]FeedUptime, 0, len(feeds))
+	for _, feed := range feeds {
+		advance(feed, until)
+		feed.uptime.Connected = feed.up
+		if feed.uptime.ObservedMs > 0 {
+			feed.uptime.Uptime = float64(feed.uptime.ConnectedMs) / float64(feed.uptime.ObservedMs)
+		}
+		uptimes = append(uptimes, feed.uptime)
+	}
+	sort.Slice(uptimes, func(a, b int) bool {
+		return uptimes[a].Feed < uptimes[b].Feed
+	})
+	return uptimes
+}
+
+// append adds an event, dropping the oldest past the limit. The caller
+// must hold l.mu.
+func (l *Log) append(event Event) {
+	l.events = append(l.events, event)
+	if l.limit > 0 && len(l.events) > l.limit {
+		l.events = append([]Event(nil), l.events[len(l.events)-l.limit:]...)
+	}
+}
+
+// save persists the events to the configured file. It is a no-op when no
+// file is configured. The caller must hold l.mu.
+func (l *Log) save() error {
+	if l.file == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(l.events)
+	if err != nil {
+		return fmt.Errorf("failed to marshal feed history: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.file), 0o755); err != nil {
+		return fmt.Errorf("failed to create feed history directory: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a partial history
+	tmpFile := l.file + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write feed history: %w", err)
+	}
+	if err := os.Rename(tmpFile, l.file); err != nil {
+		return fmt.Errorf("failed to replace feed history: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads persisted events from the configured file, ahead of any
+// recorded since start. Feeds the previous process left connected, i.e. it
+// died without stopping them, are recorded as disconnected by the restart
+// at load time, as the time it died is unknown. A missing file is not an
+// error.
+func (l *Log) Load() error {
+	if l.file == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(l.file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read feed history: %w", err)
+	}
+
+	var events []Event
+	if err := json.Unmarshal(data, &events); err != nil {
+		return fmt.Errorf("failed to parse feed history: %w", err)
+	}
+
+	now := l.now()
+	last := make(map[string]Event)
+	for _, event := range events {
+		last[event.Feed] = event
+	}
+	var restarts []Event
+	for feed, event := range last {
+		if event.up() {
+			restarts = append(restarts, Event{Time: now, Feed: feed, Type: Disconnected, Cause: CauseRestart})
+		}
+	}
+	sort.Slice(restarts, func(a, b int) bool {
+		return restarts[a].Feed < restarts[b].Feed
+	})
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	recorded := l.events
+	l.events = nil
+	for _, event := range append(append(events, restarts...), recorded...) {
+		l.append(event)
+	}
+
+	log.Printf("Loaded %d feed history events from %s", len(events), l.file)
+	return nil
+}
@@ -0,0 +1,120 @@
+package feedlog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestLog creates a Log with a controllable clock.
+func newTestLog(now *time.Time, opts ...Option) *Log {
+	history := New(opts...)
+	history.now = func() time.Time { return *now }
+	return history
+}
+
+// TestUptime verifies connected time is measured per feed within the
+// window, from each feed's first event.
+func TestUptime(t *testing.T) {
+	start := time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC)
+	now := start
+	history := newTestLog(&now)
+
+	history.Record("blue", Connected, "", 6)
+	now = start.Add(6 * time.Hour)
+	history.Record("green", Connected, "", 6)
+	now = start.Add(9 * time.Hour)
+	history.Record("blue", Disconnected, "read: connection reset by peer", 0)
+	now = start.Add(10 * time.Hour)
+	history.Record("blue", ConnectFailed, "dial tcp: i/o timeout", 6)
+	now = start.Add(12 * time.Hour)
+	history.Record("blue", Reconnected, "", 6)
+
+	tests := []struct {
+		name        string
+		since       time.Time
+		until       time.Time
+		feed        int
+		expected    string
+		uptime      float64
+		disconnects int
+	}{
+		{"whole day", start, start.Add(24 * time.Hour), 0, "blue", 21.0 / 24, 2},
+		{"feed added late", start, start.Add(24 * time.Hour), 1, "green", 1, 0},
+		{"window during outage", start.Add(9 * time.Hour), start.Add(12 * time.Hour), 0, "blue", 0, 2},
+		{"state before window", start.Add(13 * time.Hour), start.Add(14 * time.Hour), 0, "blue", 1, 0},
+	}
+
+	for _, tt := range tests {
+		uptimes := history.Uptime(tt.since, tt.until)
+		if len(uptimes) != 2 {
+			t.Fatalf("%s: expected 2 feeds, got %+v", tt.name, uptimes)
+		}
+		uptime := uptimes[tt.feed]
+		if uptime.Feed != tt.expected || uptime.Uptime != tt.uptime || uptime.Disconnects != tt.disconnects {
+			t.Errorf("%s: expected %s with uptime %v and %d disconnects, got %+v",
+				tt.name, tt.expected, tt.uptime, tt.disconnects, uptime)
+		}
+	}
+}
+
+// TestEvents verifies events are filtered by time and feed and the oldest
+// are dropped past the limit.
+func TestEvents(t *testing.T) {
+	now := time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC)
+	history := newTestLog(&now, WithLimit(3))
+
+	for _, feed := range []string{"blue", "green", "blue", "green"} {
+		history.Record(feed, Connected, "", 1)
+		now = now.Add(time.Hour)
+	}
+
+	if events := history.Events(time.Time{}, ""); len(events) != 3 || events[0].Feed != "green" {
+		t.Errorf("Expected the 3 newest events, got %+v", events)
+	}
+	if events := history.Events(now.Add(-2*time.Hour), "green"); len(events) != 1 {
+		t.Errorf("Expected 1 recent green event, got %+v", events)
+	}
+}
+
+// TestLoad verifies events survive a restart and feeds left connected are
+// recorded as disconnected by it.
+func TestLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "feeds", "history.json")
+	now := time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC)
+
+	history := newTestLog(&now, WithFile(file))
+	history.Record("blue", Connected, "", 6)
+	history.Record("green", Connected, "", 6)
+	history.Record("green", Disconnected, "ingestor stopped", 0)
+
+	now = now.Add(time.Hour)
+	restarted := newTestLog(&now, WithFile(file))
+	if err := restarted.Load(); err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+
+	events := restarted.Events(time.Time{}, "")
+	if len(events) != 4 {
+		t.Fatalf("Expected 3 persisted events and 1 restart, got %+v", events)
+	}
+	restart := events[3]
+	if restart.Feed != "blue" || restart.Type != Disconnected || restart.Cause != CauseRestart || !restart.Time.Equal(now) {
+		t.Errorf("Expected blue to be disconnected by the restart, got %+v", restart)
+	}
+}
+
+// TestLoadErrors verifies a missing file is not an error and a corrupt one is.
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	if err := New(WithFile(filepath.Join(dir, "missing.json"))).Load(); err != nil {
+		t.Errorf("Expected no error for a missing file, got %v", err)
+	}
+
+	corrupt := filepath.Join(dir, "corrupt.json")
+	os.WriteFile(corrupt, []byte("{"), 0o644)
+	if err := New(WithFile(corrupt)).Load(); err == nil {
+		t.Error("Expected an error for a corrupt file")
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"net/url"
 	"time"
+
+	"golang.org/x/sync/errgroup"
 )
 
 const (
@@ -19,6 +21,10 @@ const (
 
 	// DefaultLimit for observations.
 	DefaultLimit = 100
+
+	// MaxConcurrentRequests bounds the tickers GetMultipleLatest fetches
+	// at once, well within FRED's limit of 120 requests per minute.
+	MaxConcurrentRequests = 4
 )
 
 // Client defines the interface for FRED API operations.
@@ -35,12 +41,27 @@ type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
+// APIError is returned when FRED answers with a status other than 200.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API returned status %d: %s", e.StatusCode, e.Body)
+}
+
 // QueryOptions provides optional parameters for FRED API queries.
 type QueryOptions struct {
 	StartDate string
 	EndDate   string
 	Limit     int
 	SortOrder string
+
+	// Units is the transformation FRED applies to the observations, one of
+	// the Units constants; levels when empty. Transformations FRED rejects
+	// are computed locally from the levels.
+	Units string
 }
 
 // client implements the Client interface.
@@ -48,26 +69,33 @@ type client struct {
 	apiKey     string
 	httpClient HTTPClient
 	baseURL    string
+
+	limiter      *rateLimiter // Nil for no limit
+	maxRetries   int
+	retryBackoff time.Duration
 }
 
-// NewClient creates a new FRED API client.
-func NewClient(apiKey string) Client {
-	return &client{
-		apiKey: apiKey,
-		httpClient: &http.Client{
-			Timeout: DefaultTimeout,
-		},
-		baseURL: BaseURL,
-	}
+// NewClient creates a new FRED API client. By default it sends at most
+// DefaultRequestsPerMinute requests a minute and retries failed requests
+// DefaultMaxRetries times with exponential backoff.
+func NewClient(apiKey string, opts ...ClientOption) Client {
+	return NewClientWithHTTP(apiKey, &http.Client{Timeout: DefaultTimeout}, opts...)
 }
 
 // NewClientWithHTTP creates a client with a custom HTTP client (for testing).
-func NewClientWithHTTP(apiKey string, httpClient HTTPClient) Client {
-	return &client{
-		apiKey:     apiKey,
-		httpClient: httpClient,
-		baseURL:    BaseURL,
+func NewClientWithHTTP(apiKey string, httpClient HTTPClient, opts ...ClientOption) Client {
+	c := &client{
+		apiKey:       apiKey,
+		httpClient:   httpClient,
+		baseURL:      BaseURL,
+		limiter:      newRateLimiter(DefaultRequestsPerMinute, DefaultBurst),
+		maxRetries:   DefaultMaxRetries,
+		retryBackoff: DefaultRetryBackoff,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // GetSeriesObservations retrieves historical data for a ticker.
@@ -79,14 +107,23 @@ func (c *client) GetSeriesObservations(ctx context.Context, ticker Ticker, opts
 		}
 	}
 
-	// Fetch observations
-	apiURL := c.buildObservationsURL(ticker, opts)
-	resp, err := c.doRequest(ctx, apiURL)
+	units, err := ParseUnits(opts.Units)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch observations for %s: %w", ticker, err)
+		return nil, err
 	}
+	resolved := *opts
+	resolved.Units = units
+	opts = &resolved
 
-	fredResp, err := c.parseObservationsResponse(resp)
+	// Fetch observations
+	fredResp, err := c.fetchObservations(ctx, ticker, opts)
+	if err != nil && units != UnitsLevels && rejectedUnits(err) {
+		fredResp, err = c.fetchObservations(ctx, ticker, levelsOptions(opts))
+		if err == nil {
+			fredResp.Observations, _ = TransformObservations(fredResp.Observations, units)
+			fredResp.Observations = trimObservations(fredResp.Observations, opts)
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -103,7 +140,7 @@ func (c *client) GetSeriesObservations(ctx context.Context, ticker Ticker, opts
 		}
 	}
 
-	return &SeriesData{
+	data := &SeriesData{
 		Ticker:       ticker,
 		Description:  ticker.Description(),
 		Title:        seriesInfo.Title,
@@ -113,7 +150,25 @@ func (c *client) GetSeriesObservations(ctx context.Context, ticker Ticker, opts
 		Frequency:    seriesInfo.Frequency,
 		Notes:        seriesInfo.Notes,
 		LastUpdated:  time.Now(),
-	}, nil
+	}
+	if units != UnitsLevels {
+		data.Transform = units
+		if label, ok := transformedUnits[units]; ok {
+			data.Units, data.UnitsShort = label, label
+		}
+	}
+	data.Values, data.NormalizedUnits = data.TypedObservations()
+	return data, nil
+}
+
+// fetchObservations requests the observations of a ticker.
+func (c *client) fetchObservations(ctx context.Context, ticker Ticker, opts *QueryOptions) (*FREDAPIResponse, error) {
+	resp, err := c.doRequest(ctx, c.buildObservationsURL(ticker, opts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch observations for %s: %w", ticker, err)
+	}
+
+	return c.parseObservationsResponse(resp)
 }
 
 // GetSeriesInfo retrieves metadata for a ticker.
@@ -163,22 +218,47 @@ func (c *client) GetLatestValue(ctx context.Context, ticker Ticker) (*LatestValu
 	}, nil
 }
 
-// GetMultipleLatest retrieves the latest values for multiple tickers.
+// GetMultipleLatest retrieves the latest values for multiple tickers,
+// fetching up to MaxConcurrentRequests at once. A failing ticker does not
+// cancel the others. Values are returned in the order of tickers and the
+// failed tickers are reported in Errors. It returns an error only if every
+// ticker failed.
 func (c *client) GetMultipleLatest(ctx context.Context, tickers []Ticker) (*MultiTickerResponse, error) {
-	results := make([]LatestValue, 0, len(tickers))
+	values := make([]*LatestValue, len(tickers))
+	errs := make([]error, len(tickers))
+
+	// Errors are kept per ticker, so no fetch fails the group
+	var group errgroup.Group
+	group.SetLimit(MaxConcurrentRequests)
+	for idx, ticker := range tickers {
+		group.Go(func() error {
+			values[idx], errs[idx] = c.GetLatestValue(ctx, ticker)
+			return nil
+		})
+	}
+	group.Wait()
 
-	for _, ticker := range tickers {
-		latest, err := c.GetLatestValue(ctx, ticker)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get latest for %s: %w", ticker, err)
+	response := &MultiTickerResponse{
+		Data:      make([]LatestValue, 0, len(tickers)),
+		Timestamp: time.Now(),
+	}
+	var firstErr error
+	for idx, ticker := range tickers {
+		if errs[idx] != nil {
+			if response.Errors == nil {
+				response.Errors = make(map[Ticker]string)
+				firstErr = fmt.Errorf("failed to get latest for %s: %w", ticker, errs[idx])
+			}
+			response.Errors[ticker] = errs[idx].Error()
+			continue
 		}
-		results = append(results, *latest)
+		response.Data = append(response.Data, *values[idx])
 	}
 
-	return &MultiTickerResponse{
-		Data:      results,
-		Timestamp: time.Now(),
-	}, nil
+	if len(response.Data) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return response, nil
 }
 
 // buildObservationsURL constructs the API URL with query parameters.
@@ -200,6 +280,9 @@ func (c *client) buildObservationsURL(ticker Ticker, opts *QueryOptions) string
 	if opts.SortOrder != "" {
 		params.Add("sort_order", opts.SortOrder)
 	}
+	if opts.Units != "" && opts.Units != UnitsLevels {
+		params.Add("units", opts.Units)
+	}
 
 	return fmt.Sprintf("%s/series/observations?%s", c.baseURL, params.Encode())
 }
@@ -214,7 +297,8 @@ func (c *client) buildSeriesURL(ticker Ticker) string {
 	return fmt.Sprintf("%s/series?%s", c.baseURL, params.Encode())
 }
 
-// doRequest performs an HTTP request with context.
+// doRequest performs an HTTP request with context, waiting for the rate
+// limit and retrying on 429, 5xx and network errors.
 func (c *client) doRequest(ctx context.Context, url string) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
@@ -223,18 +307,39 @@ func (c *client) doRequest(ctx context.Context, url string) (*http.Response, err
 
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
+	for retry := 0; ; retry++ {
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx); err != nil {
+				return nil, fmt.Errorf("request failed: %w", err)
+			}
+		}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
-	}
+		var retryAfter string
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			err = fmt.Errorf("request failed: %w", err)
+			if ctx.Err() != nil {
+				return nil, err
+			}
+		} else if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			err = &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+			if !retryable(resp.StatusCode) {
+				return nil, err
+			}
+			retryAfter = resp.Header.Get("Retry-After")
+		} else {
+			return resp, nil
+		}
 
-	return resp, nil
+		if retry >= c.maxRetries {
+			return nil, err
+		}
+		if sleepErr := sleep(ctx, retryDelay(retry, c.retryBackoff, retryAfter)); sleepErr != nil {
+			return nil, err
+		}
+	}
 }
 
 // parseObservationsResponse parses the FRED API observations JSON response.
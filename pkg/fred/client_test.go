@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -255,53 +257,41 @@ func TestGetSeriesObservationsWithNilOptions(t *testing.T) {
 	}
 }
 
-// TestGetMultipleLatest verifies fetching multiple tickers.
-func TestGetMultipleLatest(t *testing.T) {
-	callCount := 0
-	tickers := []string{"50000.5", "3000.2", "0.05"}
-
-	mockHTTP := &MockHTTPClient{
+// latestValuesHTTP serves the given latest value per series, and an error
+// for series without one.
+func latestValuesHTTP(values map[Ticker]string) *MockHTTPClient {
+	return &MockHTTPClient{
 		DoFunc: func(req *http.Request) (*http.Response, error) {
-			// Each ticker makes 2 calls: observations + series info
-			tickerIndex := callCount / 2
-			isSeriesInfo := callCount%2 == 1
-			
-			if isSeriesInfo {
-				// Series info call
-				mockSeriesResp := FREDSeriesResponse{
+			value, ok := values[Ticker(req.URL.Query().Get("series_id"))]
+			if !ok {
+				return nil, fmt.Errorf("network error")
+			}
+
+			var body []byte
+			if strings.HasSuffix(req.URL.Path, "/series") {
+				body, _ = json.Marshal(FREDSeriesResponse{
 					Seriess: []FREDSeriesInfo{
-						{
-							Title:      "Test Series",
-							Units:      "Test Units",
-							UnitsShort: "TU",
-							Frequency:  "Daily",
-						},
+						{Title: "Test Series", Units: "Test Units", UnitsShort: "TU", Frequency: "Daily"},
 					},
-				}
-				body, _ := json.Marshal(mockSeriesResp)
-				callCount++
-				return &http.Response{
-					StatusCode: http.StatusOK,
-					Body:       io.NopCloser(bytes.NewReader(body)),
-				}, nil
+				})
+			} else {
+				body, _ = json.Marshal(FREDAPIResponse{
+					Observations: []Observation{{Date: "2024-01-15", Value: value}},
+				})
 			}
-			
-			// Observations call
-			mockResp := FREDAPIResponse{
-				Observations: []Observation{
-					{Date: "2024-01-15", Value: tickers[tickerIndex]},
-				},
-			}
-			body, _ := json.Marshal(mockResp)
-			callCount++
 			return &http.Response{
 				StatusCode: http.StatusOK,
 				Body:       io.NopCloser(bytes.NewReader(body)),
 			}, nil
 		},
 	}
+}
 
-	client := NewClientWithHTTP("test-key", mockHTTP)
+// TestGetMultipleLatest verifies fetching multiple tickers.
+func TestGetMultipleLatest(t *testing.T) {
+	client := NewClientWithHTTP("test-key", latestValuesHTTP(map[Ticker]string{
+		TickerWALCL: "50000.5", TickerCPIAUCSL: "3000.2", TickerFEDFUNDS: "0.05",
+	}))
 	ctx := context.Background()
 
 	tickerList := []Ticker{TickerWALCL, TickerCPIAUCSL, TickerFEDFUNDS}
@@ -313,6 +303,9 @@ func TestGetMultipleLatest(t *testing.T) {
 	if len(result.Data) != 3 {
 		t.Errorf("Expected 3 results, got %d", len(result.Data))
 	}
+	if result.Errors != nil {
+		t.Errorf("Expected no errors, got %v", result.Errors)
+	}
 
 	for i, data := range result.Data {
 		if data.Ticker != tickerList[i] {
@@ -321,6 +314,73 @@ func TestGetMultipleLatest(t *testing.T) {
 	}
 }
 
+// TestGetMultipleLatestPartial verifies a ticker failing while the others
+// are still being fetched does not cancel them: their values are returned
+// with the error of the failing ticker.
+func TestGetMultipleLatestPartial(t *testing.T) {
+	values := latestValuesHTTP(map[Ticker]string{
+		TickerWALCL: "50000.5", TickerFEDFUNDS: "0.05", TickerTGA: "750000",
+	})
+	failed := make(chan struct{})
+	client := NewClientWithHTTP("test-key", &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if Ticker(req.URL.Query().Get("series_id")) == TickerCPIAUCSL {
+				close(failed)
+				return nil, fmt.Errorf("network error")
+			}
+			// Finish only after CPIAUCSL failed
+			select {
+			case <-failed:
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			}
+			return values.Do(req)
+		},
+	}, WithRetry(0, 0))
+
+	tickers := []Ticker{TickerWALCL, TickerCPIAUCSL, TickerFEDFUNDS, TickerTGA}
+	result, err := client.GetMultipleLatest(context.Background(), tickers)
+	if err != nil {
+		t.Fatalf("GetMultipleLatest failed: %v", err)
+	}
+
+	if len(result.Data) != 3 || result.Data[0].Ticker != TickerWALCL || result.Data[1].Ticker != TickerFEDFUNDS || result.Data[2].Ticker != TickerTGA {
+		t.Errorf("Expected WALCL, FEDFUNDS and WTREGEN in order, got %+v", result.Data)
+	}
+	if len(result.Errors) != 1 || result.Errors[TickerCPIAUCSL] == "" {
+		t.Errorf("Expected an error for CPIAUCSL only, got %v", result.Errors)
+	}
+}
+
+// TestGetMultipleLatestConcurrencyLimit verifies at most
+// MaxConcurrentRequests tickers are fetched at once.
+func TestGetMultipleLatestConcurrencyLimit(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	client := NewClientWithHTTP("test-key", &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				old := peak.Load()
+				if current <= old || peak.CompareAndSwap(old, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			return nil, fmt.Errorf("network error")
+		},
+	}, WithRetry(0, 0))
+
+	tickers := []Ticker{TickerCPIAUCSL, TickerWALCL, TickerFEDFUNDS, TickerTGA, TickerRRPONTSYD, TickerDTWEXBGS}
+	_, err := client.GetMultipleLatest(context.Background(), tickers)
+	if err == nil {
+		t.Fatal("Expected an error when every ticker failed")
+	}
+	if got := peak.Load(); got > MaxConcurrentRequests {
+		t.Errorf("Expected at most %d requests at once, got %d", MaxConcurrentRequests, got)
+	}
+}
+
 // TestGetMultipleLatestWithError verifies error handling.
 func TestGetMultipleLatestWithError(t *testing.T) {
 	mockHTTP := &MockHTTPClient{
@@ -329,7 +389,7 @@ func TestGetMultipleLatestWithError(t *testing.T) {
 		},
 	}
 
-	client := NewClientWithHTTP("test-key", mockHTTP)
+	client := NewClientWithHTTP("test-key", mockHTTP, WithRetry(0, 0))
 	ctx := context.Background()
 
 	tickerList := []Ticker{TickerWALCL}
@@ -665,3 +725,64 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+// newBodyResponse creates a 200 response with the given body.
+func newBodyResponse(body []byte) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}
+}
+
+// FuzzParseObservationsResponse verifies malformed observation responses
+// are rejected with an error instead of a panic.
+func FuzzParseObservationsResponse(f *testing.F) {
+	f.Add([]byte(`{"observations":[{"date":"2024-01-15","value":"100.5"}],"count":1}`))
+	f.Add([]byte(`{"observations":[{"date":"2024-01-15","value":"."}]}`))
+	f.Add([]byte(`{"observations":[{"date":"2024-01-15","value":100.5},{"value":null}]}`))
+	f.Add([]byte(`{"observations":{"date":"2024-01-15"},"count":"1"}`))
+	f.Add([]byte(`{"observations":null,"limit":-1}`))
+	f.Add([]byte(`[]`))
+	f.Add([]byte(``))
+
+	c := &client{}
+	f.Fuzz(func(t *testing.T, body []byte) {
+		result, err := c.parseObservationsResponse(newBodyResponse(body))
+		if (result == nil) == (err == nil) {
+			t.Fatalf("Expected either a result or an error, got %v and %v", result, err)
+		}
+		if err != nil {
+			return
+		}
+
+		// Whatever parsed must serialize again for the API responses
+		if _, err := json.Marshal(result); err != nil {
+			t.Errorf("Failed to marshal parsed response: %v", err)
+		}
+	})
+}
+
+// FuzzParseSeriesResponse verifies malformed series metadata responses are
+// rejected with an error instead of a panic.
+func FuzzParseSeriesResponse(f *testing.F) {
+	f.Add([]byte(`{"seriess":[{"id":"WALCL","title":"Assets","popularity":90}]}`))
+	f.Add([]byte(`{"seriess":[{"id":"WALCL","popularity":"90"}]}`))
+	f.Add([]byte(`{"seriess":[]}`))
+	f.Add([]byte(`{"seriess":{}}`))
+	f.Add([]byte(`null`))
+
+	c := &client{}
+	f.Fuzz(func(t *testing.T, body []byte) {
+		result, err := c.parseSeriesResponse(newBodyResponse(body))
+		if (result == nil) == (err == nil) {
+			t.Fatalf("Expected either a result or an error, got %v and %v", result, err)
+		}
+		if err != nil {
+			return
+		}
+
+		if _, err := json.Marshal(result); err != nil {
+			t.Errorf("Failed to marshal parsed response: %v", err)
+		}
+	})
+}
@@ -1,5 +1,7 @@
 package fred
 
+import "macro-analyst/pkg/i18n"
+
 // Ticker represents a FRED data series identifier.
 type Ticker string
 
@@ -24,16 +26,10 @@ const (
 	TickerDTWEXBGS Ticker = "DTWEXBGS"
 )
 
-// AllTickers returns all supported macro tickers.
+// AllTickers returns all tickers of the default registry: the built-in
+// macro tickers followed by the series registered at runtime.
 func AllTickers() []Ticker {
-	return []Ticker{
-		TickerWALCL,
-		TickerTGA,
-		TickerRRPONTSYD,
-		TickerFEDFUNDS,
-		TickerCPIAUCSL,
-		TickerDTWEXBGS,
-	}
+	return DefaultRegistry.Tickers()
 }
 
 // String returns the string representation of a Ticker.
@@ -41,15 +37,14 @@ func (t Ticker) String() string {
 	return string(t)
 }
 
-// Description returns a human-readable description of the ticker.
+// Description returns a human-readable English description of the ticker.
 func (t Ticker) Description() string {
-	descriptions := map[Ticker]string{
-		TickerWALCL:     "Federal Reserve Total Assets",
-		TickerTGA:       "Treasury General Account",
-		TickerRRPONTSYD: "Overnight Reverse Repo",
-		TickerFEDFUNDS:  "Federal Funds Rate",
-		TickerCPIAUCSL:  "Consumer Price Index (CPI)",
-		TickerDTWEXBGS:  "US Dollar Index",
-	}
-	return descriptions[t]
+	return t.DescriptionIn(i18n.Default)
+}
+
+// DescriptionIn returns the description of the ticker in the given
+// language, from the i18n message catalogs or the default registry.
+// Unknown tickers have none.
+func (t Ticker) DescriptionIn(lang i18n.Lang) string {
+	return DefaultRegistry.DescriptionIn(t, lang)
 }
@@ -1,6 +1,10 @@
 package fred
 
-import "testing"
+import (
+	"testing"
+
+	"macro-analyst/pkg/i18n"
+)
 
 // TestTickerString verifies Ticker string conversion.
 func TestTickerString(t *testing.T) {
@@ -103,3 +107,13 @@ func TestTickerDescriptionsNotEmpty(t *testing.T) {
 		}
 	}
 }
+
+// TestTickerDescriptionIn verifies descriptions come from the language's catalog.
+func TestTickerDescriptionIn(t *testing.T) {
+	if desc := TickerCPIAUCSL.DescriptionIn(i18n.German); desc != "Verbraucherpreisindex (VPI)" {
+		t.Errorf("Expected German CPI description, got '%s'", desc)
+	}
+	if desc := Ticker("UNKNOWN").DescriptionIn(i18n.German); desc != "" {
+		t.Errorf("Expected no description for unknown ticker, got '%s'", desc)
+	}
+}
@@ -0,0 +1,119 @@
+package fred
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Contract tests replay FRED responses recorded from production
+// (testdata/*.json) through the client, so schema drift in the API fails
+// here instead of in production. Fixtures are sanitized: the api_key is
+// only ever in the request URL and never recorded. To refresh one, fetch
+// e.g.
+//
+//	curl 'https://api.stlouisfed.org/fred/series/observations?series_id=WALCL&file_type=json&sort_order=desc&limit=3&api_key=...'
+//
+// and update the expected values below.
+
+// replayFRED returns an HTTP client answering requests for the FRED
+// observations and series endpoints with the recorded fixtures.
+func replayFRED(t *testing.T, observations, series string) *MockHTTPClient {
+	t.Helper()
+
+	fixtures := make(map[string][]byte)
+	for path, fixture := range map[string]string{"/series/observations": observations, "/series?": series} {
+		data, err := os.ReadFile(fixture)
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		fixtures[path] = data
+	}
+
+	return &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			url := req.URL.String()
+			for path, data := range fixtures {
+				if strings.Contains(url, path) {
+					return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(data))}, nil
+				}
+			}
+			t.Fatalf("Unexpected request %s", url)
+			return nil, nil
+		},
+	}
+}
+
+// TestContractSeriesObservations verifies recorded observations and series
+// metadata are parsed into the expected series data.
+func TestContractSeriesObservations(t *testing.T) {
+	// Arrange
+	mockHTTP := replayFRED(t, "testdata/observations_walcl.json", "testdata/series_walcl.json")
+	client := NewClientWithHTTP("test-key", mockHTTP)
+
+	// Act
+	data, err := client.GetSeriesObservations(context.Background(), TickerWALCL, nil)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("GetSeriesObservations failed: %v", err)
+	}
+
+	expected := []Observation{
+		{Date: "2024-01-17", Value: "7677607"},
+		{Date: "2024-01-10", Value: "7713432"},
+		{Date: "2024-01-03", Value: MissingValue},
+	}
+	if len(data.Observations) != len(expected) {
+		t.Fatalf("Expected %d observations, got %d", len(expected), len(data.Observations))
+	}
+	for idx, want := range expected {
+		if data.Observations[idx] != want {
+			t.Errorf("Observation %d: expected %+v, got %+v", idx, want, data.Observations[idx])
+		}
+	}
+
+	if data.Units != "Millions of U.S. Dollars" || data.UnitsShort != "Mil. of U.S. $" ||
+		data.Frequency != "Weekly, As of Wednesday" || data.Notes == "" {
+		t.Errorf("Unexpected series metadata: %+v", data)
+	}
+}
+
+// TestContractSeriesInfo verifies recorded series metadata is parsed
+// completely.
+func TestContractSeriesInfo(t *testing.T) {
+	// Arrange
+	mockHTTP := replayFRED(t, "testdata/observations_walcl.json", "testdata/series_walcl.json")
+	client := NewClientWithHTTP("test-key", mockHTTP)
+
+	// Act
+	info, err := client.GetSeriesInfo(context.Background(), TickerWALCL)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("GetSeriesInfo failed: %v", err)
+	}
+
+	expected := FREDSeriesInfo{
+		ID:                      "WALCL",
+		Title:                   "Assets: Total Assets: Total Assets (Less Eliminations from Consolidation): Wednesday Level",
+		ObservationStart:        "2002-12-18",
+		ObservationEnd:          "2024-01-17",
+		Frequency:               "Weekly, As of Wednesday",
+		FrequencyShort:          "W",
+		Units:                   "Millions of U.S. Dollars",
+		UnitsShort:              "Mil. of U.S. $",
+		SeasonalAdjustment:      "Not Seasonally Adjusted",
+		SeasonalAdjustmentShort: "NSA",
+		LastUpdated:             "2024-01-18 15:31:02-06",
+		Popularity:              88,
+		Notes:                   "Total assets of all Federal Reserve Banks, less eliminations from consolidation.",
+	}
+	if *info != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *info)
+	}
+}
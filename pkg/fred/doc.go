@@ -0,0 +1,19 @@
+// Package fred is a client for the FRED (Federal Reserve Economic Data) API
+// of the St. Louis Fed.
+//
+// The package lives under pkg/ so other projects can import the client and
+// models, and its exported API is kept backwards compatible:
+//
+//	client := fred.NewClient(apiKey)
+//	latest, err := client.GetLatestValue(ctx, fred.TickerCPIAUCSL)
+//
+// Client is an interface, so callers can substitute a fake in tests.
+// Clients stay below FRED's limit of 120 requests per minute and retry
+// requests FRED rejects with 429 or 5xx; WithRateLimit and WithRetry tune
+// both.
+// Ticker descriptions are localized through the module's message catalogs;
+// Description returns the English text.
+//
+// Poller checks the registered tickers for new observations and hands each
+// MacroUpdate to a publish function, such as the WebSocket hub's.
+package fred
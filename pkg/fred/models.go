@@ -0,0 +1,190 @@
+package fred
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MissingValue is the value FRED reports for observations without data
+const MissingValue = "."
+
+// Observation represents a single data point from FRED.
+type Observation struct {
+	Date  string `json:"date"`
+	Value string `json:"value"`
+}
+
+// UnmarshalJSON decodes an observation. FRED sends values as strings, but
+// numeric and null values are accepted as well; null becomes MissingValue.
+func (o *Observation) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Date  string          `json:"date"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	value, err := observationValue(raw.Value)
+	if err != nil {
+		return err
+	}
+
+	o.Date = raw.Date
+	o.Value = value
+	return nil
+}
+
+// observationValue converts a raw observation value to its string form.
+func observationValue(raw json.RawMessage) (string, error) {
+	switch {
+	case len(raw) == 0:
+		return "", nil
+	case string(raw) == "null":
+		return MissingValue, nil
+	case raw[0] == '"':
+		var value string
+		err := json.Unmarshal(raw, &value)
+		return value, err
+	}
+
+	var number json.Number
+	if err := json.Unmarshal(raw, &number); err != nil {
+		return "", fmt.Errorf("invalid observation value %s", raw)
+	}
+	return number.String(), nil
+}
+
+// TypedObservation is an observation with its value parsed.
+type TypedObservation struct {
+	Date    string  `json:"date"`
+	Value   float64 `json:"value"`             // Zero when missing
+	Missing bool    `json:"missing,omitempty"` // FRED has no data for the date
+}
+
+// Typed parses the observation's value. Values that are not numbers, such
+// as MissingValue, are reported as missing.
+func (o Observation) Typed() TypedObservation {
+	value, err := strconv.ParseFloat(o.Value, 64)
+	if err != nil {
+		return TypedObservation{Date: o.Date, Missing: true}
+	}
+	return TypedObservation{Date: o.Date, Value: value}
+}
+
+// unitScales are the multipliers FRED prefixes units with.
+var unitScales = map[string]float64{
+	"Thousands": 1e3,
+	"Millions":  1e6,
+	"Billions":  1e9,
+	"Trillions": 1e12,
+}
+
+// NormalizeUnits splits the multiplier off units such as "Millions of U.S.
+// Dollars", returning the base units ("U.S. Dollars") and the factor that
+// converts values to them (1e6). Other units are returned as they are,
+// with a factor of 1.
+func NormalizeUnits(units string) (string, float64) {
+	scale, base, found := strings.Cut(units, " of ")
+	if factor, ok := unitScales[scale]; found && ok {
+		return base, factor
+	}
+	return units, 1
+}
+
+// SeriesData represents the complete response for a series query.
+type SeriesData struct {
+	Ticker       Ticker        `json:"ticker"`
+	Description  string        `json:"description"`
+	Title        string        `json:"title"`
+	Observations []Observation `json:"observations"`
+	Units        string        `json:"units"`
+	UnitsShort   string        `json:"units_short"`
+	Frequency    string        `json:"frequency"`
+	Notes        string        `json:"notes,omitempty"`
+	LastUpdated  time.Time     `json:"last_updated"`
+
+	// Transform is the units transformation applied to the observations,
+	// such as pc1, when not levels. Units then describe the transformed
+	// values.
+	Transform string `json:"transform,omitempty"`
+
+	// Values are the observations parsed and converted to NormalizedUnits,
+	// so clients need not parse the raw strings
+	Values          []TypedObservation `json:"values,omitempty"`
+	NormalizedUnits string             `json:"normalized_units,omitempty"`
+}
+
+// TypedObservations parses the observations and converts their values from
+// Units to the units NormalizeUnits returns, which it also returns.
+func (d *SeriesData) TypedObservations() ([]TypedObservation, string) {
+	units, factor := NormalizeUnits(d.Units)
+
+	typed := make([]TypedObservation, len(d.Observations))
+	for i, observation := range d.Observations {
+		typed[i] = observation.Typed()
+		if !typed[i].Missing {
+			typed[i].Value *= factor
+		}
+	}
+	return typed, units
+}
+
+// FREDAPIResponse represents the raw response from FRED API observations endpoint.
+type FREDAPIResponse struct {
+	RealtimeStart  string        `json:"realtime_start"`
+	RealtimeEnd    string        `json:"realtime_end"`
+	Observations   []Observation `json:"observations"`
+	Count          int           `json:"count"`
+	Offset         int           `json:"offset"`
+	Limit          int           `json:"limit"`
+	Units          string        `json:"units,omitempty"`
+	UnitsShort     string        `json:"units_short,omitempty"`
+	Frequency      string        `json:"frequency,omitempty"`
+	FrequencyShort string        `json:"frequency_short,omitempty"`
+	OrderBy        string        `json:"order_by,omitempty"`
+	SortOrder      string        `json:"sort_order,omitempty"`
+}
+
+// FREDSeriesResponse represents the response from FRED API series endpoint.
+type FREDSeriesResponse struct {
+	Seriess []FREDSeriesInfo `json:"seriess"`
+}
+
+// FREDSeriesInfo represents metadata about a FRED series.
+type FREDSeriesInfo struct {
+	ID                      string `json:"id"`
+	Title                   string `json:"title"`
+	ObservationStart        string `json:"observation_start"`
+	ObservationEnd          string `json:"observation_end"`
+	Frequency               string `json:"frequency"`
+	FrequencyShort          string `json:"frequency_short"`
+	Units                   string `json:"units"`
+	UnitsShort              string `json:"units_short"`
+	SeasonalAdjustment      string `json:"seasonal_adjustment"`
+	SeasonalAdjustmentShort string `json:"seasonal_adjustment_short"`
+	LastUpdated             string `json:"last_updated"`
+	Popularity              int    `json:"popularity"`
+	Notes                   string `json:"notes"`
+}
+
+// LatestValue represents the most recent data point for a ticker.
+type LatestValue struct {
+	Ticker      Ticker    `json:"ticker"`
+	Description string    `json:"description"`
+	Value       string    `json:"value"`
+	Date        string    `json:"date"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// MultiTickerResponse represents data for multiple tickers.
+type MultiTickerResponse struct {
+	Data      []LatestValue `json:"data"`
+	Timestamp time.Time     `json:"timestamp"`
+
+	// Errors holds the error of each ticker that could not be fetched
+	Errors map[Ticker]string `json:"errors,omitempty"`
+}
@@ -32,6 +32,37 @@ func TestObservationJSON(t *testing.T) {
 	}
 }
 
+// TestObservationMixedValueTypes verifies numeric and null values are
+// accepted alongside the usual strings.
+func TestObservationMixedValueTypes(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected string
+		wantErr  bool
+	}{
+		{`{"date":"2024-01-15","value":"100.5"}`, "100.5", false},
+		{`{"date":"2024-01-15","value":"."}`, MissingValue, false},
+		{`{"date":"2024-01-15","value":100.5}`, "100.5", false},
+		{`{"date":"2024-01-15","value":-2e3}`, "-2e3", false},
+		{`{"date":"2024-01-15","value":null}`, MissingValue, false},
+		{`{"date":"2024-01-15"}`, "", false},
+		{`{"date":"2024-01-15","value":true}`, "", true},
+		{`{"date":"2024-01-15","value":[1]}`, "", true},
+		{`{"date":15,"value":"1"}`, "", true},
+	}
+
+	for _, tt := range tests {
+		var obs Observation
+		err := json.Unmarshal([]byte(tt.data), &obs)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.data, tt.wantErr, err)
+		}
+		if err == nil && obs.Value != tt.expected {
+			t.Errorf("%s: expected value %q, got %q", tt.data, tt.expected, obs.Value)
+		}
+	}
+}
+
 // TestSeriesDataJSON verifies SeriesData JSON serialization.
 func TestSeriesDataJSON(t *testing.T) {
 	now := time.Now()
@@ -295,3 +326,69 @@ func TestMultipleObservations(t *testing.T) {
 		}
 	}
 }
+
+// TestObservationTyped verifies values are parsed and missing ones flagged.
+func TestObservationTyped(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected TypedObservation
+	}{
+		{"310.5", TypedObservation{Date: "2024-01-15", Value: 310.5}},
+		{"-0.25", TypedObservation{Date: "2024-01-15", Value: -0.25}},
+		{MissingValue, TypedObservation{Date: "2024-01-15", Missing: true}},
+		{"", TypedObservation{Date: "2024-01-15", Missing: true}},
+	}
+
+	for _, tt := range tests {
+		if got := (Observation{Date: "2024-01-15", Value: tt.value}).Typed(); got != tt.expected {
+			t.Errorf("Typed() of %q = %+v, expected %+v", tt.value, got, tt.expected)
+		}
+	}
+}
+
+// TestNormalizeUnits tests splitting multipliers off units.
+func TestNormalizeUnits(t *testing.T) {
+	tests := []struct {
+		units          string
+		expectedUnits  string
+		expectedFactor float64
+	}{
+		{"Millions of U.S. Dollars", "U.S. Dollars", 1e6},
+		{"Billions of U.S. Dollars", "U.S. Dollars", 1e9},
+		{"Thousands of Persons", "Persons", 1e3},
+		{"Percent", "Percent", 1},
+		{"Index 1982-1984=100", "Index 1982-1984=100", 1},
+		{"Number of Days", "Number of Days", 1},
+		{"", "", 1},
+	}
+
+	for _, tt := range tests {
+		units, factor := NormalizeUnits(tt.units)
+		if units != tt.expectedUnits || factor != tt.expectedFactor {
+			t.Errorf("NormalizeUnits(%q) = %q, %v, expected %q, %v", tt.units, units, factor, tt.expectedUnits, tt.expectedFactor)
+		}
+	}
+}
+
+// TestTypedObservations verifies observations are parsed and scaled to the
+// normalized units.
+func TestTypedObservations(t *testing.T) {
+	data := &SeriesData{
+		Units:        "Millions of U.S. Dollars",
+		Observations: []Observation{{"2024-01-03", "7713.5"}, {"2024-01-10", MissingValue}},
+	}
+
+	typed, units := data.TypedObservations()
+	expected := []TypedObservation{
+		{Date: "2024-01-03", Value: 7713.5e6},
+		{Date: "2024-01-10", Missing: true},
+	}
+	if units != "U.S. Dollars" || len(typed) != len(expected) {
+		t.Fatalf("expected %d observations in U.S. Dollars, got %+v in %q", len(expected), typed, units)
+	}
+	for i := range expected {
+		if typed[i] != expected[i] {
+			t.Errorf("observation %d: expected %+v, got %+v", i, expected[i], typed[i])
+		}
+	}
+}
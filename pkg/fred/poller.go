@@ -0,0 +1,160 @@
+package fred
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPollInterval is how often the Poller is run to check the tickers
+// that are due.
+const DefaultPollInterval = 15 * time.Minute
+
+// MacroUpdate is pushed to WebSocket clients when a ticker has a new or
+// revised observation.
+type MacroUpdate struct {
+	Type        string `json:"type"` // Always "macro_update"
+	Ticker      Ticker `json:"ticker"`
+	Description string `json:"description"`
+	Date        string `json:"date"`
+	Value       string `json:"value"`
+	Previous    string `json:"previous,omitempty"` // Value of the observation before, if any
+	Units       string `json:"units,omitempty"`
+	Timestamp   int64  `json:"timestamp"` // Unix ms when the observation was detected
+}
+
+// PollerOption configures a Poller.
+type PollerOption func(*Poller)
+
+// WithPollerRegistry sets the registry of the tickers to poll, by default
+// DefaultRegistry.
+func WithPollerRegistry(registry *Registry) PollerOption {
+	return func(p *Poller) {
+		p.registry = registry
+	}
+}
+
+// polledObservation is the latest observation seen of a ticker.
+type polledObservation struct {
+	date  string
+	value string
+}
+
+// Poller checks the registered tickers for new observations, each as
+// often as its frequency warrants, and publishes a MacroUpdate for each.
+// It is safe for concurrent use.
+type Poller struct {
+	mu   sync.Mutex
+	seen map[Ticker]polledObservation
+	due  map[Ticker]time.Time // When each ticker is checked next
+
+	client   Client
+	registry *Registry
+	publish  func(message []byte) bool
+	now      func() time.Time
+}
+
+// NewPoller creates a Poller publishing the encoded updates with publish,
+// e.g. the Hub's Publish, which reports whether the message was accepted.
+func NewPoller(client Client, publish func(message []byte) bool, opts ...PollerOption) *Poller {
+	p := &Poller{
+		seen:     make(map[Ticker]polledObservation),
+		due:      make(map[Ticker]time.Time),
+		client:   client,
+		registry: DefaultRegistry,
+		publish:  publish,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Poll checks the registered tickers that are due and publishes an update
+// for each new or revised observation. The first check of a ticker only
+// records its latest observation. It returns the published updates and the
+// errors of the tickers that could not be checked; those are retried later.
+func (p *Poller) Poll(ctx context.Context) ([]MacroUpdate, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var updates []MacroUpdate
+	var errs []error
+	for _, ticker := range p.registry.Tickers() {
+		now := p.now()
+		if due, scheduled := p.due[ticker]; scheduled && now.Before(due) {
+			continue
+		}
+
+		data, err := p.client.GetSeriesObservations(ctx, ticker, &QueryOptions{Limit: 2, SortOrder: "desc"})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to poll %s: %w", ticker, err))
+			p.due[ticker] = now.Add(recheckInterval(""))
+			continue
+		}
+		p.due[ticker] = now.Add(recheckInterval(data.Frequency))
+
+		var observations []Observation
+		for _, observation := range data.Observations {
+			if observation.Value != MissingValue {
+				observations = append(observations, observation)
+			}
+		}
+		if len(observations) == 0 {
+			continue
+		}
+
+		latest := observations[0]
+		seen, known := p.seen[ticker]
+		if !known || latest.Date < seen.date || (latest.Date == seen.date && latest.Value == seen.value) {
+			p.seen[ticker] = polledObservation{date: latest.Date, value: latest.Value}
+			continue
+		}
+
+		update := MacroUpdate{
+			Type:        "macro_update",
+			Ticker:      ticker,
+			Description: p.registry.DescriptionIn(ticker, ""),
+			Date:        latest.Date,
+			Value:       latest.Value,
+			Units:       data.Units,
+			Timestamp:   now.UnixMilli(),
+		}
+		if len(observations) > 1 {
+			update.Previous = observations[1].Value
+		}
+
+		message, err := json.Marshal(&update)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to encode %s update: %w", ticker, err))
+			continue
+		}
+		if !p.publish(message) {
+			// Not marked as seen, so the next check publishes it again
+			errs = append(errs, fmt.Errorf("%s update dropped", ticker))
+			continue
+		}
+		p.seen[ticker] = polledObservation{date: latest.Date, value: latest.Value}
+		updates = append(updates, update)
+	}
+	return updates, errors.Join(errs...)
+}
+
+// recheckInterval returns how long to wait before checking a series of the
+// given native frequency again: daily series are published every business
+// day, while monthly and rarer ones need not be checked as often.
+func recheckInterval(frequency string) time.Duration {
+	switch f := strings.ToLower(frequency); {
+	case strings.HasPrefix(f, "daily"):
+		return 30 * time.Minute
+	case strings.HasPrefix(f, "weekly"), strings.HasPrefix(f, "biweekly"):
+		return time.Hour
+	default:
+		return 2 * time.Hour
+	}
+}
@@ -0,0 +1,173 @@
+package fred
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// pollerClient serves the observations of each ticker newest first.
+type pollerClient struct {
+	Client
+	observations map[Ticker][]Observation
+	frequency    string
+	calls        map[Ticker]int
+}
+
+func (c *pollerClient) GetSeriesObservations(ctx context.Context, ticker Ticker, opts *QueryOptions) (*SeriesData, error) {
+	c.calls[ticker]++
+	observations, ok := c.observations[ticker]
+	if !ok {
+		return nil, errors.New("series not found")
+	}
+	if len(observations) > opts.Limit {
+		observations = observations[:opts.Limit]
+	}
+	return &SeriesData{Ticker: ticker, Observations: observations, Units: "Percent", Frequency: c.frequency}, nil
+}
+
+// newTestPoller returns a Poller over FEDFUNDS only, with a controllable
+// clock, and the messages it published.
+func newTestPoller(client *pollerClient, accept bool) (*Poller, *time.Time, *[]MacroUpdate) {
+	registry := &Registry{}
+	_, _ = registry.Register(Series{Ticker: TickerFEDFUNDS})
+
+	var published []MacroUpdate
+	poller := NewPoller(client, func(message []byte) bool {
+		var update MacroUpdate
+		_ = json.Unmarshal(message, &update)
+		published = append(published, update)
+		return accept
+	}, WithPollerRegistry(registry))
+
+	now := time.Date(2024, 7, 1, 14, 0, 0, 0, time.UTC)
+	poller.now = func() time.Time { return now }
+	return poller, &now, &published
+}
+
+// TestPollerPublishesNewObservations verifies that the first check only
+// records the latest observation and later ones publish new and revised
+// observations, skipping missing values.
+func TestPollerPublishesNewObservations(t *testing.T) {
+	client := &pollerClient{
+		observations: map[Ticker][]Observation{TickerFEDFUNDS: {{"2024-05-01", "5.33"}, {"2024-04-01", "5.33"}}},
+		frequency:    "Monthly",
+		calls:        make(map[Ticker]int),
+	}
+	poller, now, published := newTestPoller(client, true)
+
+	steps := []struct {
+		name         string
+		observations []Observation
+		expected     []MacroUpdate
+	}{
+		{"baseline", nil, nil},
+		{"unchanged", nil, nil},
+		{"new date", []Observation{{"2024-06-01", "5.30"}, {"2024-05-01", "5.33"}}, []MacroUpdate{
+			{Type: "macro_update", Ticker: TickerFEDFUNDS, Description: "Federal Funds Rate", Date: "2024-06-01", Value: "5.30", Previous: "5.33", Units: "Percent"},
+		}},
+		{"revision", []Observation{{"2024-06-01", "5.31"}, {"2024-05-01", "5.33"}}, []MacroUpdate{
+			{Type: "macro_update", Ticker: TickerFEDFUNDS, Description: "Federal Funds Rate", Date: "2024-06-01", Value: "5.31", Previous: "5.33", Units: "Percent"},
+		}},
+		{"missing", []Observation{{"2024-07-01", MissingValue}, {"2024-06-01", "5.31"}}, nil},
+	}
+
+	for _, step := range steps {
+		if step.observations != nil {
+			client.observations[TickerFEDFUNDS] = step.observations
+		}
+		*now = now.Add(2 * time.Hour)
+		*published = nil
+
+		updates, err := poller.Poll(context.Background())
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", step.name, err)
+		}
+		if len(updates) != len(step.expected) || len(*published) != len(step.expected) {
+			t.Fatalf("%s: expected %d updates, got %+v (published %+v)", step.name, len(step.expected), updates, *published)
+		}
+		for i, expected := range step.expected {
+			expected.Timestamp = now.UnixMilli()
+			if updates[i] != expected || (*published)[i] != expected {
+				t.Errorf("%s: expected %+v, got %+v (published %+v)", step.name, expected, updates[i], (*published)[i])
+			}
+		}
+	}
+}
+
+// TestPollerRespectsFrequency verifies that tickers are not checked again
+// before their frequency's recheck interval has passed.
+func TestPollerRespectsFrequency(t *testing.T) {
+	client := &pollerClient{
+		observations: map[Ticker][]Observation{TickerFEDFUNDS: {{"2024-05-01", "5.33"}}},
+		frequency:    "Daily",
+		calls:        make(map[Ticker]int),
+	}
+	poller, now, _ := newTestPoller(client, true)
+
+	for _, elapsed := range []time.Duration{0, 10 * time.Minute, 10 * time.Minute, 10 * time.Minute} {
+		*now = now.Add(elapsed)
+		if _, err := poller.Poll(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if calls := client.calls[TickerFEDFUNDS]; calls != 2 {
+		t.Errorf("expected 2 checks in 30 minutes of a daily series, got %d", calls)
+	}
+}
+
+// TestPollerErrors verifies that failed checks and dropped updates are
+// reported and retried.
+func TestPollerErrors(t *testing.T) {
+	client := &pollerClient{
+		observations: map[Ticker][]Observation{},
+		calls:        make(map[Ticker]int),
+	}
+	poller, now, published := newTestPoller(client, false)
+
+	if _, err := poller.Poll(context.Background()); err == nil {
+		t.Fatal("expected an error for a failed check")
+	}
+
+	client.observations[TickerFEDFUNDS] = []Observation{{"2024-05-01", "5.33"}}
+	*now = now.Add(2 * time.Hour)
+	if _, err := poller.Poll(context.Background()); err != nil {
+		t.Fatalf("unexpected error on the baseline check: %v", err)
+	}
+
+	client.observations[TickerFEDFUNDS] = []Observation{{"2024-06-01", "5.30"}}
+	for range 2 {
+		*now = now.Add(2 * time.Hour)
+		updates, err := poller.Poll(context.Background())
+		if err == nil || len(updates) != 0 {
+			t.Fatalf("expected the dropped update to be reported, got %+v, %v", updates, err)
+		}
+	}
+	if len(*published) != 2 {
+		t.Errorf("expected the dropped update to be retried, got %d attempts", len(*published))
+	}
+}
+
+// TestRecheckInterval tests the recheck interval of each frequency.
+func TestRecheckInterval(t *testing.T) {
+	tests := []struct {
+		frequency string
+		expected  time.Duration
+	}{
+		{"Daily", 30 * time.Minute},
+		{"Daily, Close", 30 * time.Minute},
+		{"Weekly, Ending Wednesday", time.Hour},
+		{"Biweekly, Ending Wednesday", time.Hour},
+		{"Monthly", 2 * time.Hour},
+		{"Quarterly", 2 * time.Hour},
+		{"", 2 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := recheckInterval(tt.frequency); got != tt.expected {
+			t.Errorf("recheckInterval(%q) = %v, expected %v", tt.frequency, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package fred
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRequestsPerMinute keeps a client below FRED's limit of 120
+	// requests per minute per API key.
+	DefaultRequestsPerMinute = 100
+
+	// DefaultBurst is how many requests may be sent at once after the
+	// client was idle.
+	DefaultBurst = 10
+
+	// DefaultMaxRetries is how often a request failing with 429, a 5xx
+	// status or a network error is retried.
+	DefaultMaxRetries = 3
+
+	// DefaultRetryBackoff is the wait before the first retry; it doubles
+	// with each further retry.
+	DefaultRetryBackoff = 500 * time.Millisecond
+
+	// maxRetryBackoff caps the wait before a retry, including waits FRED
+	// asks for in Retry-After.
+	maxRetryBackoff = 30 * time.Second
+)
+
+// ClientOption configures a client created by NewClient or
+// NewClientWithHTTP.
+type ClientOption func(*client)
+
+// WithRateLimit limits the client to perMinute requests per minute, with
+// bursts of up to burst requests. A perMinute of 0 or less disables the
+// limit.
+func WithRateLimit(perMinute, burst int) ClientOption {
+	return func(c *client) {
+		if perMinute <= 0 {
+			c.limiter = nil
+			return
+		}
+		c.limiter = newRateLimiter(perMinute, burst)
+	}
+}
+
+// WithRetry sets how often failed requests are retried and the wait before
+// the first retry. A maxRetries of 0 disables retries.
+func WithRetry(maxRetries int, backoff time.Duration) ClientOption {
+	return func(c *client) {
+		c.maxRetries = max(maxRetries, 0)
+		c.retryBackoff = backoff
+	}
+}
+
+// rateLimiter is a token bucket. It is safe for concurrent use.
+type rateLimiter struct {
+	mu       sync.Mutex
+	tokens   float64
+	last     time.Time
+	burst    float64
+	interval time.Duration // Time to earn one token
+	now      func() time.Time
+}
+
+// newRateLimiter creates a full bucket earning perMinute tokens a minute.
+func newRateLimiter(perMinute, burst int) *rateLimiter {
+	burst = max(burst, 1)
+	return &rateLimiter{
+		tokens:   float64(burst),
+		burst:    float64(burst),
+		interval: time.Minute / time.Duration(perMinute),
+		now:      time.Now,
+	}
+}
+
+// reserve takes a token and returns how long to wait until it is earned.
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens = min(l.burst, l.tokens+float64(now.Sub(l.last))/float64(l.interval))
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens * float64(l.interval))
+}
+
+// Wait blocks until a request may be sent or ctx is done.
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	return sleep(ctx, l.reserve())
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryable reports whether a request that failed with status is worth
+// retrying: FRED answers 429 when rate limited and 5xx when overloaded.
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// retryDelay returns the wait before the given retry (0 for the first):
+// the Retry-After seconds FRED asked for, otherwise backoff doubled per
+// retry, capped at maxRetryBackoff.
+func retryDelay(retry int, backoff time.Duration, retryAfter string) time.Duration {
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+		return min(time.Duration(seconds)*time.Second, maxRetryBackoff)
+	}
+
+	delay := backoff
+	for range retry {
+		if delay >= maxRetryBackoff {
+			break
+		}
+		delay *= 2
+	}
+	return min(delay, maxRetryBackoff)
+}
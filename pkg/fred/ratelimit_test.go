@@ -0,0 +1,121 @@
+package fred
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestRateLimiterReserve verifies that bursts are served at once, further
+// requests wait for their token and idle time refills the bucket.
+func TestRateLimiterReserve(t *testing.T) {
+	now := time.Date(2024, 7, 1, 14, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter(60, 2)
+	limiter.now = func() time.Time { return now }
+
+	steps := []struct {
+		elapsed  time.Duration
+		expected time.Duration
+	}{
+		{0, 0},
+		{0, 0},
+		{0, time.Second},
+		{0, 2 * time.Second},
+		{10 * time.Second, 0},
+		{0, 0},
+		{0, time.Second},
+	}
+
+	for i, step := range steps {
+		now = now.Add(step.elapsed)
+		if got := limiter.reserve(); got != step.expected {
+			t.Errorf("request %d: expected a wait of %v, got %v", i, step.expected, got)
+		}
+	}
+}
+
+// TestRateLimiterWaitCancelled verifies that waiting stops with the context.
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	limiter := newRateLimiter(1, 1)
+	limiter.reserve()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to end the wait, got %v", err)
+	}
+}
+
+// TestDoRequestRetries verifies which failures are retried and how often.
+func TestDoRequestRetries(t *testing.T) {
+	tests := []struct {
+		name          string
+		failures      []int // Status of each failed attempt; 0 for a network error
+		expectedCalls int
+		expectErr     bool
+	}{
+		{"success", nil, 1, false},
+		{"rate limited", []int{http.StatusTooManyRequests}, 2, false},
+		{"server errors", []int{http.StatusServiceUnavailable, http.StatusBadGateway}, 3, false},
+		{"network error", []int{0}, 2, false},
+		{"bad request", []int{http.StatusBadRequest}, 1, true},
+		{"exhausted", []int{500, 500, 500, 500}, 3, true},
+	}
+
+	for _, tt := range tests {
+		calls := 0
+		mockHTTP := &MockHTTPClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				calls++
+				if calls > len(tt.failures) {
+					return newBodyResponse([]byte("{}")), nil
+				}
+				if status := tt.failures[calls-1]; status != 0 {
+					return &http.Response{
+						StatusCode: status,
+						Header:     http.Header{"Retry-After": {"0"}},
+						Body:       io.NopCloser(bytes.NewReader(nil)),
+					}, nil
+				}
+				return nil, fmt.Errorf("connection reset")
+			},
+		}
+
+		c := NewClientWithHTTP("test-key", mockHTTP, WithRateLimit(0, 0), WithRetry(2, time.Millisecond)).(*client)
+		_, err := c.doRequest(context.Background(), BaseURL+"/test")
+		if (err != nil) != tt.expectErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.expectErr, err)
+		}
+		if calls != tt.expectedCalls {
+			t.Errorf("%s: expected %d attempts, got %d", tt.name, tt.expectedCalls, calls)
+		}
+	}
+}
+
+// TestRetryDelay tests the exponential backoff and Retry-After handling.
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		retry      int
+		retryAfter string
+		expected   time.Duration
+	}{
+		{0, "", time.Second},
+		{1, "", 2 * time.Second},
+		{3, "", 8 * time.Second},
+		{10, "", maxRetryBackoff},
+		{0, "5", 5 * time.Second},
+		{0, "3600", maxRetryBackoff},
+		{1, "soon", 2 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := retryDelay(tt.retry, time.Second, tt.retryAfter); got != tt.expected {
+			t.Errorf("retryDelay(%d, %q) = %v, expected %v", tt.retry, tt.retryAfter, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package fred
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"macro-analyst/pkg/i18n"
+)
+
+var (
+	// ErrInvalidTicker is returned for series IDs FRED cannot have.
+	ErrInvalidTicker = errors.New("invalid ticker")
+
+	// ErrUnknownTicker is returned for series that are not registered.
+	ErrUnknownTicker = errors.New("unknown ticker")
+
+	// ErrBuiltinTicker is returned when removing one of the built-in series.
+	ErrBuiltinTicker = errors.New("built-in ticker cannot be removed")
+)
+
+// tickerPattern matches FRED series IDs, e.g. UNRATE or T10Y2Y.
+var tickerPattern = regexp.MustCompile(`^[A-Z0-9_]{1,30}$`)
+
+// Series describes a registered FRED series.
+type Series struct {
+	Ticker Ticker `json:"symbol"`
+
+	// Description is used where the i18n catalogs have no description of
+	// the ticker, i.e. for every series registered at runtime
+	Description string `json:"description,omitempty"`
+
+	// Units for display, e.g. "Percent" or "Billions of U.S. Dollars"
+	Units string `json:"units,omitempty"`
+}
+
+// builtinSeries are the series every registry starts with.
+var builtinSeries = []Series{
+	{TickerWALCL, "Federal Reserve Total Assets", "Millions of U.S. Dollars"},
+	{TickerTGA, "Treasury General Account", "Millions of U.S. Dollars"},
+	{TickerRRPONTSYD, "Overnight Reverse Repo", "Billions of U.S. Dollars"},
+	{TickerFEDFUNDS, "Federal Funds Rate", "Percent"},
+	{TickerCPIAUCSL, "Consumer Price Index (CPI)", "Index 1982-1984=100"},
+	{TickerDTWEXBGS, "US Dollar Index", "Index Jan 2006=100"},
+}
+
+// Registry holds the FRED series the server serves, in registration order.
+// It is safe for concurrent use.
+type Registry struct {
+	mu     sync.RWMutex
+	series []Series
+}
+
+// DefaultRegistry is the registry used by AllTickers and the Ticker
+// description methods.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry creates a registry holding the built-in series.
+func NewRegistry() *Registry {
+	return &Registry{series: slices.Clone(builtinSeries)}
+}
+
+// NormalizeTicker trims and upper-cases a series ID and validates it.
+func NormalizeTicker(symbol string) (Ticker, error) {
+	ticker := strings.ToUpper(strings.TrimSpace(symbol))
+	if !tickerPattern.MatchString(ticker) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidTicker, symbol)
+	}
+	return Ticker(ticker), nil
+}
+
+// Register adds a series, or replaces the description and units of one
+// that is already registered. It returns the stored series.
+func (r *Registry) Register(series Series) (Series, error) {
+	ticker, err := NormalizeTicker(string(series.Ticker))
+	if err != nil {
+		return Series{}, err
+	}
+	series.Ticker = ticker
+	series.Description = strings.TrimSpace(series.Description)
+	series.Units = strings.TrimSpace(series.Units)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if idx := r.indexOf(ticker); idx >= 0 {
+		r.series[idx] = series
+	} else {
+		r.series = append(r.series, series)
+	}
+	return series, nil
+}
+
+// Unregister removes a series registered at runtime.
+func (r *Registry) Unregister(ticker Ticker) error {
+	if IsBuiltin(ticker) {
+		return fmt.Errorf("%w: %s", ErrBuiltinTicker, ticker)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := r.indexOf(ticker)
+	if idx < 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownTicker, ticker)
+	}
+	r.series = slices.Delete(r.series, idx, idx+1)
+	return nil
+}
+
+// Lookup returns the registered series of a ticker.
+func (r *Registry) Lookup(ticker Ticker) (Series, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if idx := r.indexOf(ticker); idx >= 0 {
+		return r.series[idx], true
+	}
+	return Series{}, false
+}
+
+// Has reports whether a ticker is registered.
+func (r *Registry) Has(ticker Ticker) bool {
+	_, ok := r.Lookup(ticker)
+	return ok
+}
+
+// Series returns a copy of the registered series.
+func (r *Registry) Series() []Series {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Clone(r.series)
+}
+
+// Tickers returns the registered tickers.
+func (r *Registry) Tickers() []Ticker {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tickers := make([]Ticker, len(r.series))
+	for i, series := range r.series {
+		tickers[i] = series.Ticker
+	}
+	return tickers
+}
+
+// DescriptionIn returns the description of a ticker in the given language:
+// the one from the i18n catalogs if there is one, otherwise the registered
+// description. Unknown tickers have none.
+func (r *Registry) DescriptionIn(ticker Ticker, lang i18n.Lang) string {
+	if key := i18n.TickerKey(string(ticker)); i18n.Has(key) {
+		return i18n.T(lang, key)
+	}
+
+	series, _ := r.Lookup(ticker)
+	return series.Description
+}
+
+// indexOf returns the position of a ticker, or -1. The caller holds r.mu.
+func (r *Registry) indexOf(ticker Ticker) int {
+	return slices.IndexFunc(r.series, func(series Series) bool {
+		return series.Ticker == ticker
+	})
+}
+
+// IsBuiltin reports whether a ticker is one of the built-in series.
+func IsBuiltin(ticker Ticker) bool {
+	return slices.ContainsFunc(builtinSeries, func(series Series) bool {
+		return series.Ticker == ticker
+	})
+}
+
+// ParseSeriesList converts a semicolon-separated list of
+// TICKER|description|units items, e.g.
+// "UNRATE|Unemployment Rate|Percent;T10Y2Y|10Y-2Y Treasury Spread",
+// to series. Description and units are optional.
+func ParseSeriesList(value string) ([]Series, error) {
+	var list []Series
+	for _, item := range strings.Split(value, ";") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+
+		fields := strings.Split(item, "|")
+		if len(fields) > 3 {
+			return nil, fmt.Errorf("invalid FRED series %q (expected TICKER|description|units)", item)
+		}
+		ticker, err := NormalizeTicker(fields[0])
+		if err != nil {
+			return nil, err
+		}
+
+		series := Series{Ticker: ticker}
+		if len(fields) > 1 {
+			series.Description = strings.TrimSpace(fields[1])
+		}
+		if len(fields) > 2 {
+			series.Units = strings.TrimSpace(fields[2])
+		}
+		list = append(list, series)
+	}
+	return list, nil
+}
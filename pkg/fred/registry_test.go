@@ -0,0 +1,126 @@
+package fred
+
+import (
+	"errors"
+	"testing"
+
+	"macro-analyst/pkg/i18n"
+)
+
+// TestRegistryRegister verifies that series are validated, normalized and
+// added or replaced in registration order.
+func TestRegistryRegister(t *testing.T) {
+	tests := []struct {
+		name     string
+		series   Series
+		expected error
+	}{
+		{"new", Series{Ticker: " unrate ", Description: "Unemployment Rate", Units: "Percent"}, nil},
+		{"replace", Series{Ticker: "UNRATE", Description: "Unemployment", Units: "%"}, nil},
+		{"empty", Series{Ticker: ""}, ErrInvalidTicker},
+		{"punctuation", Series{Ticker: "UN-RATE"}, ErrInvalidTicker},
+	}
+
+	registry := NewRegistry()
+	for _, tt := range tests {
+		_, err := registry.Register(tt.series)
+		if !errors.Is(err, tt.expected) {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.expected, err)
+		}
+	}
+
+	tickers := registry.Tickers()
+	if len(tickers) != 7 || tickers[6] != "UNRATE" {
+		t.Fatalf("expected UNRATE after the built-ins, got %v", tickers)
+	}
+	if series, _ := registry.Lookup("UNRATE"); series.Description != "Unemployment" || series.Units != "%" {
+		t.Errorf("expected the replaced series, got %+v", series)
+	}
+	if len(NewRegistry().Tickers()) != 6 {
+		t.Error("expected registries not to share series")
+	}
+}
+
+// TestRegistryUnregister verifies that only runtime series can be removed.
+func TestRegistryUnregister(t *testing.T) {
+	registry := NewRegistry()
+	if _, err := registry.Register(Series{Ticker: "UNRATE"}); err != nil {
+		t.Fatalf("failed to register: %v", err)
+	}
+
+	tests := []struct {
+		ticker   Ticker
+		expected error
+	}{
+		{"UNRATE", nil},
+		{"UNRATE", ErrUnknownTicker},
+		{TickerWALCL, ErrBuiltinTicker},
+	}
+
+	for _, tt := range tests {
+		if err := registry.Unregister(tt.ticker); !errors.Is(err, tt.expected) {
+			t.Errorf("%s: expected error %v, got %v", tt.ticker, tt.expected, err)
+		}
+	}
+	if !registry.Has(TickerWALCL) || registry.Has("UNRATE") {
+		t.Errorf("unexpected tickers %v", registry.Tickers())
+	}
+}
+
+// TestRegistryDescriptionIn verifies that catalog descriptions take
+// precedence over registered ones.
+func TestRegistryDescriptionIn(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(Series{Ticker: "UNRATE", Description: "Unemployment Rate"})
+	registry.Register(Series{Ticker: TickerFEDFUNDS, Description: "Fed Funds"})
+
+	tests := []struct {
+		ticker   Ticker
+		lang     i18n.Lang
+		expected string
+	}{
+		{"UNRATE", i18n.German, "Unemployment Rate"},
+		{TickerFEDFUNDS, i18n.English, "Federal Funds Rate"},
+		{"GDP", i18n.English, ""},
+	}
+
+	for _, tt := range tests {
+		if got := registry.DescriptionIn(tt.ticker, tt.lang); got != tt.expected {
+			t.Errorf("%s (%s): expected %q, got %q", tt.ticker, tt.lang, tt.expected, got)
+		}
+	}
+}
+
+// TestParseSeriesList tests parsing of the FRED_TICKERS setting.
+func TestParseSeriesList(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected []Series
+		wantErr  bool
+	}{
+		{"empty", "", nil, false},
+		{"full", "unrate|Unemployment Rate|Percent", []Series{{"UNRATE", "Unemployment Rate", "Percent"}}, false},
+		{"ticker only", "T10Y2Y; GDP|Gross Domestic Product",
+			[]Series{{Ticker: "T10Y2Y"}, {Ticker: "GDP", Description: "Gross Domestic Product"}}, false},
+		{"too many fields", "GDP|a|b|c", nil, true},
+		{"invalid ticker", "G D P|Gross Domestic Product", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSeriesList(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+			continue
+		}
+		if len(got) != len(tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.expected[i] {
+				t.Errorf("%s: expected %+v, got %+v", tt.name, tt.expected[i], got[i])
+			}
+		}
+	}
+}
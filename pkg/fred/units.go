@@ -0,0 +1,179 @@
+package fred
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Units transformations FRED applies to observations, its units parameter
+const (
+	UnitsLevels               = "lin"
+	UnitsChange               = "chg"
+	UnitsChangeYearAgo        = "ch1"
+	UnitsPercentChange        = "pch"
+	UnitsPercentChangeYearAgo = "pc1"
+	UnitsLog                  = "log"
+)
+
+// ErrInvalidUnits is returned for units transformations other than the
+// Units constants.
+var ErrInvalidUnits = errors.New("invalid units transformation")
+
+const (
+	// yearAgoTolerance is how far from a year before an observation the
+	// year-ago observation may be, e.g. 52 weeks earlier for weekly series
+	yearAgoTolerance = 7 * 24 * time.Hour
+
+	// transformPrecision rounds locally transformed values to six decimals
+	transformPrecision = 1e6
+)
+
+// transformedUnits are the units of transformed values, keyed by
+// transformation. Changes keep the units of the series.
+var transformedUnits = map[string]string{
+	UnitsPercentChange:        "Percent Change",
+	UnitsPercentChangeYearAgo: "Percent Change from Year Ago",
+	UnitsLog:                  "Natural Log",
+}
+
+// ParseUnits returns the units transformation for a FRED units code or one
+// of the aliases yoy (pc1), mom (pch) and diff (chg). Empty means levels.
+func ParseUnits(name string) (string, error) {
+	switch name {
+	case "", UnitsLevels:
+		return UnitsLevels, nil
+	case "yoy":
+		return UnitsPercentChangeYearAgo, nil
+	case "mom":
+		return UnitsPercentChange, nil
+	case "diff":
+		return UnitsChange, nil
+	case UnitsChange, UnitsChangeYearAgo, UnitsPercentChange, UnitsPercentChangeYearAgo, UnitsLog:
+		return name, nil
+	}
+	return "", fmt.Errorf("%w %q (expected yoy, mom, diff, %s, %s, %s, %s, %s or %s)", ErrInvalidUnits, name,
+		UnitsLevels, UnitsChange, UnitsChangeYearAgo, UnitsPercentChange, UnitsPercentChangeYearAgo, UnitsLog)
+}
+
+// rejectedUnits reports whether FRED rejected a request, such as one for a
+// transformation the series does not support, so the transformation is
+// computed locally instead.
+func rejectedUnits(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest
+}
+
+// levelsOptions returns the options fetching the levels a transformation
+// of opts is computed from: every observation up to the end date, starting
+// a year before the start date, so the first ones have a year-ago value.
+func levelsOptions(opts *QueryOptions) *QueryOptions {
+	levels := *opts
+	levels.Units = ""
+	levels.Limit = 0
+	levels.SortOrder = "asc"
+	if start, err := time.Parse(time.DateOnly, opts.StartDate); err == nil {
+		levels.StartDate = start.AddDate(-1, 0, -int(yearAgoTolerance.Hours()/24)).Format(time.DateOnly)
+	}
+	return &levels
+}
+
+// TransformObservations computes a units transformation of level
+// observations as FRED does. Changes compare each observation with the
+// previous one, year-ago changes with the one nearest to a year earlier,
+// within a week. Observations may be in either order and keep it; those
+// without a value to compare with are missing.
+func TransformObservations(observations []Observation, units string) ([]Observation, error) {
+	units, err := ParseUnits(units)
+	if err != nil {
+		return nil, err
+	}
+	if units == UnitsLevels {
+		return observations, nil
+	}
+
+	type level struct {
+		date  time.Time
+		value float64
+		ok    bool
+	}
+	levels := make([]level, len(observations))
+	order := make([]int, len(observations)) // Indexes by date
+	for idx, observation := range observations {
+		date, _ := time.Parse(time.DateOnly, observation.Date)
+		typed := observation.Typed()
+		levels[idx] = level{date: date, value: typed.Value, ok: !typed.Missing}
+		order[idx] = idx
+	}
+	sort.SliceStable(order, func(a, b int) bool { return levels[order[a]].date.Before(levels[order[b]].date) })
+
+	transformed := make([]Observation, len(observations))
+	for pos, idx := range order {
+		current := levels[idx]
+		base, hasBase := level{}, false
+		switch units {
+		case UnitsChange, UnitsPercentChange:
+			if pos > 0 {
+				base, hasBase = levels[order[pos-1]], true
+			}
+		case UnitsChangeYearAgo, UnitsPercentChangeYearAgo:
+			yearAgo := current.date.AddDate(-1, 0, 0)
+			nearest := yearAgoTolerance + 1
+			for prev := pos - 1; prev >= 0; prev-- {
+				candidate := levels[order[prev]]
+				distance := yearAgo.Sub(candidate.date).Abs()
+				if distance < nearest {
+					base, hasBase, nearest = candidate, true, distance
+				}
+				if candidate.date.Before(yearAgo.Add(-yearAgoTolerance)) {
+					break
+				}
+			}
+		}
+
+		value := math.NaN()
+		switch {
+		case !current.ok:
+		case units == UnitsLog:
+			if current.value > 0 {
+				value = math.Log(current.value)
+			}
+		case !hasBase || !base.ok:
+		case units == UnitsChange || units == UnitsChangeYearAgo:
+			value = current.value - base.value
+		case base.value != 0:
+			value = (current.value/base.value - 1) * 100
+		}
+
+		transformed[idx] = Observation{Date: observations[idx].Date, Value: MissingValue}
+		if !math.IsNaN(value) {
+			transformed[idx].Value = strconv.FormatFloat(math.Round(value*transformPrecision)/transformPrecision, 'f', -1, 64)
+		}
+	}
+	return transformed, nil
+}
+
+// trimObservations restricts locally transformed observations, fetched in
+// ascending order, to the range, order and limit of opts.
+func trimObservations(observations []Observation, opts *QueryOptions) []Observation {
+	trimmed := make([]Observation, 0, len(observations))
+	for _, observation := range observations {
+		if opts.StartDate == "" || observation.Date >= opts.StartDate {
+			trimmed = append(trimmed, observation)
+		}
+	}
+
+	if opts.SortOrder == "desc" {
+		for a, b := 0, len(trimmed)-1; a < b; a, b = a+1, b-1 {
+			trimmed[a], trimmed[b] = trimmed[b], trimmed[a]
+		}
+	}
+	if opts.Limit > 0 && len(trimmed) > opts.Limit {
+		trimmed = trimmed[:opts.Limit]
+	}
+	return trimmed
+}
@@ -0,0 +1,142 @@
+package fred
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParseUnits tests the transformation names and aliases accepted.
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"", UnitsLevels},
+		{"yoy", UnitsPercentChangeYearAgo},
+		{"mom", UnitsPercentChange},
+		{"diff", UnitsChange},
+		{"log", UnitsLog},
+		{"ch1", UnitsChangeYearAgo},
+	}
+	for _, tt := range tests {
+		if units, err := ParseUnits(tt.name); err != nil || units != tt.expected {
+			t.Errorf("ParseUnits(%q) = %q, %v; expected %q", tt.name, units, err, tt.expected)
+		}
+	}
+
+	if _, err := ParseUnits("qoq"); !errors.Is(err, ErrInvalidUnits) {
+		t.Errorf("Expected ErrInvalidUnits, got %v", err)
+	}
+}
+
+// TestTransformObservations tests computing transformations locally.
+func TestTransformObservations(t *testing.T) {
+	monthly := []Observation{
+		{Date: "2024-03-01", Value: "110"},
+		{Date: "2024-02-01", Value: MissingValue},
+		{Date: "2024-01-01", Value: "105"},
+		{Date: "2023-03-01", Value: "100"},
+	}
+
+	tests := []struct {
+		units    string
+		expected []string // Values in the order of monthly
+	}{
+		{UnitsChange, []string{MissingValue, MissingValue, "5", MissingValue}},
+		{UnitsPercentChangeYearAgo, []string{"10", MissingValue, MissingValue, MissingValue}},
+		{UnitsChangeYearAgo, []string{"10", MissingValue, MissingValue, MissingValue}},
+		{UnitsLog, []string{"4.70048", MissingValue, "4.65396", "4.60517"}},
+	}
+	for _, tt := range tests {
+		transformed, err := TransformObservations(monthly, tt.units)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.units, err)
+		}
+		values := make([]string, len(transformed))
+		for idx, observation := range transformed {
+			if observation.Date != monthly[idx].Date {
+				t.Errorf("%s: expected the order to be kept, got %s at %d", tt.units, observation.Date, idx)
+			}
+			values[idx] = observation.Value
+		}
+		if !reflect.DeepEqual(values, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.units, tt.expected, values)
+		}
+	}
+
+	// Weekly observations a year apart fall on different dates
+	weekly := []Observation{{Date: "2023-01-04", Value: "200"}, {Date: "2024-01-03", Value: "210"}}
+	if transformed, _ := TransformObservations(weekly, UnitsPercentChangeYearAgo); transformed[1].Value != "5" {
+		t.Errorf("Expected a 5%% year-ago change for weekly observations, got %s", transformed[1].Value)
+	}
+}
+
+// TestGetSeriesObservationsUnits verifies the transformation is requested
+// from FRED, and computed locally from the levels when FRED rejects it.
+func TestGetSeriesObservationsUnits(t *testing.T) {
+	respond := func(status int, value any) (*http.Response, error) {
+		body, _ := json.Marshal(value)
+		return &http.Response{StatusCode: status, Body: io.NopCloser(bytes.NewReader(body))}, nil
+	}
+
+	for _, rejected := range []bool{false, true} {
+		var requests []string
+		mockHTTP := &MockHTTPClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				query := req.URL.Query()
+				requests = append(requests, query.Encode())
+				switch {
+				case strings.HasSuffix(req.URL.Path, "/series"):
+					return respond(http.StatusOK, FREDSeriesResponse{Seriess: []FREDSeriesInfo{{Units: "Index 1982-1984=100"}}})
+				case query.Get("units") == UnitsPercentChangeYearAgo && rejected:
+					return respond(http.StatusBadRequest, map[string]string{"error_message": "Bad Request"})
+				case query.Get("units") == UnitsPercentChangeYearAgo:
+					return respond(http.StatusOK, FREDAPIResponse{Observations: []Observation{{Date: "2024-01-01", Value: "3.1"}}})
+				}
+				return respond(http.StatusOK, FREDAPIResponse{Observations: []Observation{
+					{Date: "2022-12-01", Value: "100"},
+					{Date: "2023-01-01", Value: "100"},
+					{Date: "2023-12-01", Value: "103"},
+					{Date: "2024-01-01", Value: "104"},
+				}})
+			},
+		}
+
+		client := NewClientWithHTTP("test-key", mockHTTP, WithRetry(0, 0))
+		data, err := client.GetSeriesObservations(context.Background(), TickerCPIAUCSL, &QueryOptions{
+			StartDate: "2023-12-01",
+			Limit:     1,
+			SortOrder: "desc",
+			Units:     "yoy",
+		})
+		if err != nil {
+			t.Fatalf("rejected=%v: GetSeriesObservations failed: %v", rejected, err)
+		}
+
+		expected := "3.1"
+		if rejected {
+			expected = "4"
+		}
+		if len(data.Observations) != 1 || data.Observations[0].Value != expected || data.Observations[0].Date != "2024-01-01" {
+			t.Errorf("rejected=%v: unexpected observations %+v", rejected, data.Observations)
+		}
+		if data.Transform != UnitsPercentChangeYearAgo || data.Units != "Percent Change from Year Ago" {
+			t.Errorf("rejected=%v: unexpected transform %q or units %q", rejected, data.Transform, data.Units)
+		}
+		if !strings.Contains(requests[0], "units=pc1") {
+			t.Errorf("rejected=%v: expected pc1 to be requested, got %s", rejected, requests[0])
+		}
+	}
+
+	client := NewClientWithHTTP("test-key", &MockHTTPClient{})
+	if _, err := client.GetSeriesObservations(context.Background(), TickerCPIAUCSL, &QueryOptions{Units: "qoq"}); !errors.Is(err, ErrInvalidUnits) {
+		t.Errorf("Expected ErrInvalidUnits, got %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+// Package i18n translates user-facing texts, such as ticker descriptions
+// and error messages, from message catalogs embedded per language.
+//
+// Catalogs live in locales/<lang>.json and map message keys to texts with
+// optional fmt verbs. Keys missing from a catalog fall back to English:
+//
+//	lang := i18n.Match(c.Query("lang"), c.Get("Accept-Language"))
+//	i18n.T(lang, i18n.MsgSymbolRequired, "BTCUSDT")
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Lang is a supported language, as a lower-case ISO 639-1 code.
+type Lang string
+
+const (
+	English    Lang = "en"
+	German     Lang = "de"
+	Vietnamese Lang = "vi"
+
+	// Default is used when no supported language is requested
+	Default = English
+)
+
+// Supported lists the languages with a message catalog.
+var Supported = []Lang{English, German, Vietnamese}
+
+// Message keys of the error texts.
+const (
+	MsgInvalidBody             = "error.invalid_body"
+	MsgInvalidExpectationsBody = "error.invalid_expectations_body"
+	MsgFREDUnavailable         = "error.fred_unavailable"
+	MsgAPIKeyRequired          = "error.api_key_required"
+	MsgValidAPIKeyRequired     = "error.valid_api_key_required"
+	MsgUnauthorized            = "error.unauthorized"
+	MsgDraining                = "error.draining"
+	MsgNoActiveFeed            = "error.no_active_feed"
+	MsgSymbolRequired          = "error.symbol_required" // Takes an example symbol
+	MsgNegativeGracePeriod     = "error.negative_grace_period"
+	MsgValidTokenRequired      = "error.valid_token_required"
+	MsgTooManyConnections      = "error.too_many_connections"
+	MsgUnknownTicker           = "error.unknown_ticker" // Takes the ticker
+)
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// catalogs holds the messages of each supported language.
+var catalogs = loadCatalogs()
+
+func loadCatalogs() map[Lang]map[string]string {
+	catalogs := make(map[Lang]map[string]string, len(Supported))
+	for _, lang := range Supported {
+		data, err := locales.ReadFile("locales/" + string(lang) + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("i18n: missing catalog for %s: %v", lang, err))
+		}
+
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog for %s: %v", lang, err))
+		}
+		catalogs[lang] = messages
+	}
+	return catalogs
+}
+
+// TickerKey returns the message key of a FRED ticker's description.
+func TickerKey(ticker string) string {
+	return "ticker." + ticker
+}
+
+// T returns the message for key in lang, formatted with args. It falls back
+// to English, then to the key itself.
+func T(lang Lang, key string, args ...any) string {
+	message, exists := catalogs[lang][key]
+	if !exists {
+		if message, exists = catalogs[Default][key]; !exists {
+			return key
+		}
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// Has reports whether the English catalog defines key.
+func Has(key string) bool {
+	_, exists := catalogs[Default][key]
+	return exists
+}
+
+// Parse returns the supported language of a tag such as "de" or "de-AT".
+func Parse(tag string) (Lang, bool) {
+	base, _, _ := strings.Cut(strings.TrimSpace(tag), "-")
+	lang := Lang(strings.ToLower(base))
+	for _, supported := range Supported {
+		if lang == supported {
+			return lang, true
+		}
+	}
+	return "", false
+}
+
+// Match returns the first supported language among the given preferences,
+// most important first. Each preference is a language tag, such as an
+// explicit user choice, or an Accept-Language header with quality values.
+// It returns Default if none is supported.
+func Match(preferences ...string) Lang {
+	for _, preference := range preferences {
+		for _, tag := range acceptedTags(preference) {
+			if lang, ok := Parse(tag); ok {
+				return lang
+			}
+		}
+	}
+	return Default
+}
+
+// acceptedTags returns the tags of an Accept-Language value ordered by
+// quality, dropping those with q=0.
+func acceptedTags(header string) []string {
+	type weighted struct {
+		tag     string
+		quality float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		quality := 1.0
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if tag = strings.TrimSpace(tag); tag != "" && quality > 0 {
+			tags = append(tags, weighted{tag, quality})
+		}
+	}
+	sort.SliceStable(tags, func(a, b int) bool {
+		return tags[a].quality > tags[b].quality
+	})
+
+	ordered := make([]string, len(tags))
+	for idx, tag := range tags {
+		ordered[idx] = tag.tag
+	}
+	return ordered
+}
@@ -0,0 +1,66 @@
+package i18n
+
+import (
+	"testing"
+)
+
+// TestCatalogsComplete verifies every catalog translates every English key.
+func TestCatalogsComplete(t *testing.T) {
+	for _, lang := range Supported {
+		for key := range catalogs[Default] {
+			if _, exists := catalogs[lang][key]; !exists {
+				t.Errorf("Catalog %s is missing %s", lang, key)
+			}
+		}
+		for key := range catalogs[lang] {
+			if !Has(key) {
+				t.Errorf("Catalog %s has %s, which English does not define", lang, key)
+			}
+		}
+	}
+}
+
+// TestT verifies messages are translated, formatted and fall back.
+func TestT(t *testing.T) {
+	tests := []struct {
+		lang     Lang
+		key      string
+		args     []any
+		expected string
+	}{
+		{English, MsgSymbolRequired, []any{"BTCUSDT"}, "symbol is required, e.g. BTCUSDT"},
+		{German, TickerKey("FEDFUNDS"), nil, "Leitzins (Federal Funds Rate)"},
+		{Vietnamese, MsgNoActiveFeed, nil, "không có nguồn dữ liệu đang hoạt động"},
+		{Lang("fr"), MsgNoActiveFeed, nil, "no active feed"},
+		{German, "unknown.key", nil, "unknown.key"},
+	}
+
+	for _, tt := range tests {
+		if got := T(tt.lang, tt.key, tt.args...); got != tt.expected {
+			t.Errorf("%s %s: expected %q, got %q", tt.lang, tt.key, tt.expected, got)
+		}
+	}
+}
+
+// TestMatch verifies language negotiation from preferences and Accept-Language.
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		preferences []string
+		expected    Lang
+	}{
+		{"none", nil, Default},
+		{"region subtag", []string{"de-AT"}, German},
+		{"quality order", []string{"fr;q=0.9, vi;q=0.8, de;q=0.5"}, Vietnamese},
+		{"excluded", []string{"de;q=0, vi"}, Vietnamese},
+		{"explicit first", []string{"vi", "de-DE,de;q=0.9"}, Vietnamese},
+		{"unsupported explicit", []string{"fr", "de"}, German},
+		{"unsupported only", []string{"fr-FR, ja"}, Default},
+	}
+
+	for _, tt := range tests {
+		if got := Match(tt.preferences...); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+}
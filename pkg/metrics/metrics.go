@@ -0,0 +1,251 @@
+// Package metrics provides lightweight, dependency-free counters and gauges
+// exposed in the Prometheus text exposition format.
+//
+// Metrics are registered on a Registry (usually Default) and rendered by
+// WriteTo, which the server mounts at GET /metrics:
+//
+//	staleAlarms := metrics.Default.NewCounter(
+//	    "feed_stale_alarms_total",
+//	    "Number of times the market data feed was declared stale.",
+//	)
+//	staleAlarms.Inc()
+//
+// Counters of the same metric split by a label, e.g. a reason, are
+// registered with NewLabeledCounter and rendered as one metric family.
+// Distributions such as latencies are recorded in a Histogram registered
+// with NewHistogram.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Namespace is prepended to every metric name.
+const Namespace = "macro_analyst"
+
+// Default is the process-wide registry used by the application.
+var Default = NewRegistry()
+
+// Counter is a monotonically increasing value. Safe for concurrent use.
+type Counter struct {
+	value atomic.Uint64
+}
+
+// Inc increments the counter by one.
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+// Add increments the counter by n.
+func (c *Counter) Add(n uint64) {
+	c.value.Add(n)
+}
+
+// Value returns the current counter value.
+func (c *Counter) Value() uint64 {
+	return c.value.Load()
+}
+
+// Gauge is a value that can go up and down. Safe for concurrent use.
+type Gauge struct {
+	bits atomic.Uint64
+}
+
+// Set sets the gauge to v.
+func (g *Gauge) Set(v float64) {
+	g.bits.Store(math.Float64bits(v))
+}
+
+// Value returns the current gauge value.
+func (g *Gauge) Value() float64 {
+	return math.Float64frombits(g.bits.Load())
+}
+
+// Histogram counts observed values in cumulative buckets, e.g. latencies in
+// seconds. Safe for concurrent use.
+type Histogram struct {
+	bounds  []float64       // Upper bounds of the buckets, ascending
+	buckets []atomic.Uint64 // Observations per bucket, the last one above every bound
+	count   atomic.Uint64
+	sumBits atomic.Uint64
+}
+
+// Observe records a value.
+func (h *Histogram) Observe(v float64) {
+	h.buckets[sort.SearchFloat64s(h.bounds, v)].Add(1)
+	h.count.Add(1)
+	for {
+		old := h.sumBits.Load()
+		if h.sumBits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			return
+		}
+	}
+}
+
+// Count returns the number of observations.
+func (h *Histogram) Count() uint64 {
+	return h.count.Load()
+}
+
+// Sum returns the sum of the observed values.
+func (h *Histogram) Sum() float64 {
+	return math.Float64frombits(h.sumBits.Load())
+}
+
+// render returns the bucket, sum and count samples of the histogram.
+func (h *Histogram) render(fullName string) string {
+	var b strings.Builder
+	var cumulative uint64
+	for idx, bound := range h.bounds {
+		cumulative += h.buckets[idx].Load()
+		fmt.Fprintf(&b, "%s_bucket{le=\"%g\"} %d\n", fullName, bound, cumulative)
+	}
+	cumulative += h.buckets[len(h.bounds)].Load()
+	fmt.Fprintf(&b, "%s_bucket{le=\"+Inf\"} %d\n", fullName, cumulative)
+	fmt.Fprintf(&b, "%s_sum %g\n%s_count %d", fullName, h.Sum(), fullName, h.Count())
+	return b.String()
+}
+
+// metric is a registered counter, gauge or histogram with its metadata.
+type metric struct {
+	name      string
+	labels    string // Rendered label set, e.g. {reason="expired"}, empty without labels
+	help      string
+	kind      string
+	counter   *Counter
+	gauge     *Gauge
+	histogram *Histogram
+}
+
+// Registry holds a set of named metrics.
+type Registry struct {
+	mu      sync.RWMutex
+	metrics map[string]*metric
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		metrics: make(map[string]*metric),
+	}
+}
+
+// NewCounter registers a counter under name, or returns the existing one
+// if a counter with the same name was already registered.
+func (r *Registry) NewCounter(name, help string) *Counter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if m, exists := r.metrics[name]; exists && m.counter != nil {
+		return m.counter
+	}
+
+	c := &Counter{}
+	r.metrics[name] = &metric{name: name, help: help, kind: "counter", counter: c}
+	return c
+}
+
+// NewLabeledCounter registers the counter of name with label set to value,
+// or returns the existing one. Counters sharing a name are rendered as one
+// metric family with the help of the first one registered.
+func (r *Registry) NewLabeledCounter(name, help, label, value string) *Counter {
+	labels := fmt.Sprintf("{%s=%q}", label, value)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if m, exists := r.metrics[name+labels]; exists && m.counter != nil {
+		return m.counter
+	}
+
+	c := &Counter{}
+	r.metrics[name+labels] = &metric{name: name, labels: labels, help: help, kind: "counter", counter: c}
+	return c
+}
+
+// NewGauge registers a gauge under name, or returns the existing one
+// if a gauge with the same name was already registered.
+func (r *Registry) NewGauge(name, help string) *Gauge {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if m, exists := r.metrics[name]; exists && m.gauge != nil {
+		return m.gauge
+	}
+
+	g := &Gauge{}
+	r.metrics[name] = &metric{name: name, help: help, kind: "gauge", gauge: g}
+	return g
+}
+
+// NewHistogram registers a histogram under name with the given bucket upper
+// bounds, or returns the existing one if a histogram with the same name was
+// already registered.
+func (r *Registry) NewHistogram(name, help string, bounds []float64) *Histogram {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if m, exists := r.metrics[name]; exists && m.histogram != nil {
+		return m.histogram
+	}
+
+	sorted := append([]float64(nil), bounds...)
+	sort.Float64s(sorted)
+	h := &Histogram{bounds: sorted, buckets: make([]atomic.Uint64, len(sorted)+1)}
+	r.metrics[name] = &metric{name: name, help: help, kind: "histogram", histogram: h}
+	return h
+}
+
+// WriteTo renders all registered metrics in the Prometheus text format,
+// sorted by name.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var written int64
+	var family string
+	for _, name := range names {
+		m := r.metrics[name]
+		fullName := Namespace + "_" + m.name
+
+		var value string
+		switch {
+		case m.counter != nil:
+			value = fmt.Sprintf("%d", m.counter.Value())
+		case m.gauge != nil:
+			value = fmt.Sprintf("%g", m.gauge.Value())
+		}
+
+		// Labeled counters of a family sort next to each other and share
+		// one header
+		var header string
+		if m.name != family {
+			header = fmt.Sprintf("# HELP %s %s\n# TYPE %s %s\n", fullName, m.help, fullName, m.kind)
+			family = m.name
+		}
+
+		sample := fmt.Sprintf("%s%s %s", fullName, m.labels, value)
+		if m.histogram != nil {
+			sample = m.histogram.render(fullName)
+		}
+		n, err := fmt.Fprintf(w, "%s%s\n", header, sample)
+		written += int64(n)
+		if err != nil {
+			r.mu.RUnlock()
+			return written, err
+		}
+	}
+	r.mu.RUnlock()
+
+	return written, nil
+}
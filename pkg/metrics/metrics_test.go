@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestCounter verifies counter increments.
+func TestCounter(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("events_total", "Events seen.")
+
+	c.Inc()
+	c.Add(4)
+
+	if c.Value() != 5 {
+		t.Errorf("Expected counter value 5, got %d", c.Value())
+	}
+}
+
+// TestNewCounterReturnsExisting verifies duplicate registration returns the same counter.
+func TestNewCounterReturnsExisting(t *testing.T) {
+	r := NewRegistry()
+	first := r.NewCounter("events_total", "Events seen.")
+	second := r.NewCounter("events_total", "Events seen.")
+
+	if first != second {
+		t.Error("Expected the same counter for duplicate registration")
+	}
+}
+
+// TestGauge verifies gauge set and read.
+func TestGauge(t *testing.T) {
+	r := NewRegistry()
+	g := r.NewGauge("queue_depth", "Queue depth.")
+
+	g.Set(12.5)
+
+	if g.Value() != 12.5 {
+		t.Errorf("Expected gauge value 12.5, got %f", g.Value())
+	}
+}
+
+// TestWriteTo verifies Prometheus text output.
+func TestWriteTo(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("b_total", "B counter.").Add(3)
+	r.NewGauge("a_value", "A gauge.").Set(1.5)
+
+	var buf bytes.Buffer
+	if _, err := r.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+
+	out := buf.String()
+	expected := []string{
+		"# TYPE macro_analyst_a_value gauge\nmacro_analyst_a_value 1.5\n",
+		"# TYPE macro_analyst_b_total counter\nmacro_analyst_b_total 3\n",
+	}
+	for _, want := range expected {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	// Metrics should be sorted by name
+	if strings.Index(out, "a_value") > strings.Index(out, "b_total") {
+		t.Error("Expected metrics to be sorted by name")
+	}
+}
+
+// TestLabeledCounter verifies counters split by a label are rendered as one
+// metric family.
+func TestLabeledCounter(t *testing.T) {
+	r := NewRegistry()
+	expired := r.NewLabeledCounter("dropped_total", "Dropped messages.", "reason", "expired")
+	r.NewLabeledCounter("dropped_total", "Dropped messages.", "reason", "channel_full").Add(2)
+	expired.Inc()
+
+	if r.NewLabeledCounter("dropped_total", "Dropped messages.", "reason", "expired") != expired {
+		t.Error("Expected the same counter for duplicate registration")
+	}
+
+	var buf bytes.Buffer
+	if _, err := r.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+
+	expected := "# HELP macro_analyst_dropped_total Dropped messages.\n" +
+		"# TYPE macro_analyst_dropped_total counter\n" +
+		"macro_analyst_dropped_total{reason=\"channel_full\"} 2\n" +
+		"macro_analyst_dropped_total{reason=\"expired\"} 1\n"
+	if out := buf.String(); out != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out)
+	}
+}
+
+// TestHistogram verifies observations are counted in cumulative buckets
+// and rendered with their sum and count.
+func TestHistogram(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogram("fanout_seconds", "Fan-out time.", []float64{0.01, 0.001})
+	if r.NewHistogram("fanout_seconds", "Fan-out time.", nil) != h {
+		t.Error("Expected the same histogram for duplicate registration")
+	}
+
+	for _, v := range []float64{0.0005, 0.001, 0.005, 0.5} {
+		h.Observe(v)
+	}
+
+	var buf bytes.Buffer
+	if _, err := r.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+
+	expected := "# HELP macro_analyst_fanout_seconds Fan-out time.\n" +
+		"# TYPE macro_analyst_fanout_seconds histogram\n" +
+		"macro_analyst_fanout_seconds_bucket{le=\"0.001\"} 2\n" +
+		"macro_analyst_fanout_seconds_bucket{le=\"0.01\"} 3\n" +
+		"macro_analyst_fanout_seconds_bucket{le=\"+Inf\"} 4\n" +
+		"macro_analyst_fanout_seconds_sum 0.5065\n" +
+		"macro_analyst_fanout_seconds_count 4\n"
+	if out := buf.String(); out != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out)
+	}
+}
@@ -0,0 +1,294 @@
+// Package usage tracks REST and WebSocket usage per API key, bucketed by
+// UTC day, as groundwork for quota tiers.
+//
+// API keys are never stored: usage is recorded under a short key ID derived
+// from a hash of the key, which is also what the usage endpoints report.
+package usage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// AnonymousKeyID groups usage from requests without an API key
+	AnonymousKeyID = "anonymous"
+
+	// DefaultRetentionDays is how many days of usage are kept
+	DefaultRetentionDays = 90
+
+	// DayLayout is the format of usage day buckets (UTC)
+	DayLayout = "2006-01-02"
+)
+
+// Counters holds usage totals.
+type Counters struct {
+	Requests uint64 `json:"requests"` // REST requests served
+	Messages uint64 `json:"messages"` // WebSocket messages delivered
+	Bytes    uint64 `json:"bytes"`    // Response and message bytes sent
+}
+
+// add accumulates other into c.
+func (c *Counters) add(other Counters) {
+	c.Requests += other.Requests
+	c.Messages += other.Messages
+	c.Bytes += other.Bytes
+}
+
+// DayUsage is the usage of a single UTC day.
+type DayUsage struct {
+	Date string `json:"date"`
+	Counters
+}
+
+// KeyUsage is the usage of a single API key.
+type KeyUsage struct {
+	KeyID string     `json:"key_id"`
+	Total Counters   `json:"total"`
+	Days  []DayUsage `json:"days"`
+}
+
+// Summary aggregates usage across all keys for admins.
+type Summary struct {
+	Total Counters   `json:"total"`
+	Days  []DayUsage `json:"days"`
+	Keys  []KeyUsage `json:"keys"`
+}
+
+// Tracker records usage per key and day. It is safe for concurrent use.
+type Tracker struct {
+	mu   sync.Mutex
+	days map[string]map[string]*Counters // Day -> key ID -> counters
+
+	file          string
+	retentionDays int
+	now           func() time.Time
+}
+
+// Option is a functional option for configuring the Tracker.
+type Option func(*Tracker)
+
+// WithFile sets the file usage is persisted to. An empty path disables
+// persistence.
+func WithFile(path string) Option {
+	return func(t *Tracker) {
+		t.file = path
+	}
+}
+
+// WithRetentionDays sets how many days of usage are kept.
+func WithRetentionDays(days int) Option {
+	return func(t *Tracker) {
+		t.retentionDays = days
+	}
+}
+
+// NewTracker creates a new Tracker.
+func NewTracker(opts ...Option) *Tracker {
+	tracker := &Tracker{
+		days:          make(map[string]map[string]*Counters),
+		retentionDays: DefaultRetentionDays,
+		now:           time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(tracker)
+	}
+
+	return tracker
+}
+
+// KeyID derives the identifier usage is recorded under from an API key.
+func KeyID(apiKey string) string {
+	if apiKey == "" {
+		return AnonymousKeyID
+	}
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:6])
+}
+
+// RecordRequest counts a REST request and its response size.
+func (t *Tracker) RecordRequest(keyID string, bytes int) {
+	t.record(keyID, Counters{Requests: 1, Bytes: uint64(bytes)})
+}
+
+// RecordMessage counts a delivered WebSocket message and its size.
+func (t *Tracker) RecordMessage(keyID string, bytes int) {
+	t.record(keyID, Counters{Messages: 1, Bytes: uint64(bytes)})
+}
+
+// record adds usage to today's bucket of the key.
+func (t *Tracker) record(keyID string, usage Counters) {
+	day := t.now().UTC().Format(DayLayout)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	keys, exists := t.days[day]
+	if !exists {
+		keys = make(map[string]*Counters)
+		t.days[day] = keys
+	}
+	counters, exists := keys[keyID]
+	if !exists {
+		counters = &Counters{}
+		keys[keyID] = counters
+	}
+	counters.add(usage)
+}
+
+// Usage returns the usage of a single key, oldest day first.
+func (t *Tracker) Usage(keyID string) KeyUsage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	usage := KeyUsage{KeyID: keyID, Days: []DayUsage{}}
+	for _, day := range t.sortedDays() {
+		if counters, exists := t.days[day][keyID]; exists {
+			usage.Days = append(usage.Days, DayUsage{Date: day, Counters: *counters})
+			usage.Total.add(*counters)
+		}
+	}
+	return usage
+}
+
+// Summary returns usage aggregated per day and per key.
+func (t *Tracker) Summary() Summary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	summary := Summary{Days: []DayUsage{}, Keys: []KeyUsage{}}
+	keys := make(map[string]*KeyUsage)
+
+	for _, day := range t.sortedDays() {
+		dayUsage := DayUsage{Date: day}
+		for keyID, counters := range t.days[day] {
+			dayUsage.add(*counters)
+
+			keyUsage, exists := keys[keyID]
+			if !exists {
+				keyUsage = &KeyUsage{KeyID: keyID}
+				keys[keyID] = keyUsage
+			}
+			keyUsage.Days = append(keyUsage.Days, DayUsage{Date: day, Counters: *counters})
+			keyUsage.Total.add(*counters)
+		}
+		summary.Days = append(summary.Days, dayUsage)
+		summary.Total.add(dayUsage.Counters)
+	}
+
+	for _, keyUsage := range keys {
+		summary.Keys = append(summary.Keys, *keyUsage)
+	}
+	sort.Slice(summary.Keys, func(a, b int) bool {
+		return summary.Keys[a].KeyID < summary.Keys[b].KeyID
+	})
+
+	return summary
+}
+
+// sortedDays returns the tracked days in ascending order. The caller must
+// hold t.mu.
+func (t *Tracker) sortedDays() []string {
+	days := make([]string, 0, len(t.days))
+	for day := range t.days {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+	return days
+}
+
+// prune drops days older than the retention period. The caller must hold t.mu.
+func (t *Tracker) prune() {
+	if t.retentionDays <= 0 {
+		return
+	}
+
+	cutoff := t.now().UTC().AddDate(0, 0, -t.retentionDays).Format(DayLayout)
+	for day := range t.days {
+		if day < cutoff {
+			delete(t.days, day)
+		}
+	}
+}
+
+// Save persists usage to the configured file, dropping days past the
+// retention period. It is a no-op when no file is configured.
+func (t *Tracker) Save() error {
+	if t.file == "" {
+		return nil
+	}
+
+	t.mu.Lock()
+	t.prune()
+	data, err := json.Marshal(t.days)
+	t.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(t.file), 0o755); err != nil {
+		return fmt.Errorf("failed to create usage directory: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves partial usage
+	tmpFile := t.file + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write usage: %w", err)
+	}
+	if err := os.Rename(tmpFile, t.file); err != nil {
+		return fmt.Errorf("failed to replace usage: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads persisted usage from the configured file, adding it to any
+// usage recorded since start. A missing file is not an error.
+func (t *Tracker) Load() error {
+	if t.file == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(t.file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read usage: %w", err)
+	}
+
+	var days map[string]map[string]*Counters
+	if err := json.Unmarshal(data, &days); err != nil {
+		return fmt.Errorf("failed to parse usage: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for day, keys := range days {
+		if _, exists := t.days[day]; !exists {
+			t.days[day] = make(map[string]*Counters)
+		}
+		for keyID, counters := range keys {
+			if existing, exists := t.days[day][keyID]; exists {
+				existing.add(*counters)
+			} else {
+				t.days[day][keyID] = counters
+			}
+		}
+	}
+	t.prune()
+
+	log.Printf("Loaded usage for %d days from %s", len(t.days), t.file)
+	return nil
+}
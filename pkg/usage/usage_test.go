@@ -0,0 +1,126 @@
+package usage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestTracker creates a Tracker with a controllable clock.
+func newTestTracker(now *time.Time, opts ...Option) *Tracker {
+	tracker := NewTracker(opts...)
+	tracker.now = func() time.Time { return *now }
+	return tracker
+}
+
+// TestKeyID verifies keys are hashed and missing keys are anonymous.
+func TestKeyID(t *testing.T) {
+	if id := KeyID(""); id != AnonymousKeyID {
+		t.Errorf("Expected %q for empty key, got %q", AnonymousKeyID, id)
+	}
+
+	id := KeyID("secret-key")
+	if id == "secret-key" || len(id) != 12 {
+		t.Errorf("Expected 12-character hashed key ID, got %q", id)
+	}
+	if KeyID("secret-key") != id {
+		t.Error("Expected key IDs to be stable")
+	}
+}
+
+// TestTrackerUsageByDay verifies usage is bucketed per UTC day and key.
+func TestTrackerUsageByDay(t *testing.T) {
+	now := time.Date(2024, 1, 15, 23, 59, 0, 0, time.UTC)
+	tracker := newTestTracker(&now)
+
+	tracker.RecordRequest("alice", 100)
+	tracker.RecordMessage("alice", 50)
+	tracker.RecordRequest("bob", 10)
+
+	now = now.Add(2 * time.Minute)
+	tracker.RecordMessage("alice", 25)
+
+	usage := tracker.Usage("alice")
+	if len(usage.Days) != 2 {
+		t.Fatalf("Expected 2 days, got %d", len(usage.Days))
+	}
+	if usage.Days[0].Date != "2024-01-15" || usage.Days[0].Requests != 1 || usage.Days[0].Messages != 1 {
+		t.Errorf("Unexpected first day: %+v", usage.Days[0])
+	}
+	if usage.Total.Bytes != 175 || usage.Total.Messages != 2 {
+		t.Errorf("Unexpected total: %+v", usage.Total)
+	}
+
+	if unknown := tracker.Usage("carol"); len(unknown.Days) != 0 {
+		t.Errorf("Expected no usage for unknown key, got %+v", unknown)
+	}
+}
+
+// TestTrackerSummary verifies aggregation across keys.
+func TestTrackerSummary(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	tracker := newTestTracker(&now)
+
+	tracker.RecordRequest("bob", 10)
+	tracker.RecordRequest("alice", 20)
+
+	summary := tracker.Summary()
+
+	if summary.Total.Requests != 2 || summary.Total.Bytes != 30 {
+		t.Errorf("Unexpected total: %+v", summary.Total)
+	}
+	if len(summary.Days) != 1 || summary.Days[0].Requests != 2 {
+		t.Errorf("Unexpected days: %+v", summary.Days)
+	}
+	if len(summary.Keys) != 2 || summary.Keys[0].KeyID != "alice" {
+		t.Errorf("Expected keys sorted by ID, got %+v", summary.Keys)
+	}
+}
+
+// TestTrackerPersistence verifies usage survives a save and load.
+func TestTrackerPersistence(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	file := filepath.Join(t.TempDir(), "usage.json")
+
+	tracker := newTestTracker(&now, WithFile(file))
+	tracker.RecordRequest("alice", 100)
+	if err := tracker.Save(); err != nil {
+		t.Fatalf("Failed to save usage: %v", err)
+	}
+
+	restarted := newTestTracker(&now, WithFile(file))
+	restarted.RecordRequest("alice", 1)
+	if err := restarted.Load(); err != nil {
+		t.Fatalf("Failed to load usage: %v", err)
+	}
+
+	if total := restarted.Usage("alice").Total; total.Requests != 2 || total.Bytes != 101 {
+		t.Errorf("Expected loaded and new usage to be merged, got %+v", total)
+	}
+}
+
+// TestTrackerRetention verifies old days are pruned on save.
+func TestTrackerRetention(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newTestTracker(&now, WithFile(filepath.Join(t.TempDir(), "usage.json")), WithRetentionDays(7))
+
+	tracker.RecordRequest("alice", 1)
+	now = now.AddDate(0, 0, 10)
+	tracker.RecordRequest("alice", 1)
+
+	if err := tracker.Save(); err != nil {
+		t.Fatalf("Failed to save usage: %v", err)
+	}
+
+	if days := tracker.Usage("alice").Days; len(days) != 1 || days[0].Date != "2024-01-11" {
+		t.Errorf("Expected only the recent day to remain, got %+v", days)
+	}
+}
+
+// TestLoadMissingFile verifies a missing usage file is not an error.
+func TestLoadMissingFile(t *testing.T) {
+	tracker := NewTracker(WithFile(filepath.Join(t.TempDir(), "missing.json")))
+	if err := tracker.Load(); err != nil {
+		t.Errorf("Expected no error for missing file, got %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package ws
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ImportValidate checks the rules of an import without looking at the
+	// owner's alerts
+	ImportValidate = "validate"
+
+	// ImportDryRun also checks the rules against the owner's alerts and
+	// reports what applying them would change
+	ImportDryRun = "dry-run"
+
+	// ImportApply registers the rules, all or none of them
+	ImportApply = "apply"
+)
+
+var (
+	// ErrInvalidImportMode is returned for import modes other than
+	// ImportValidate, ImportDryRun and ImportApply.
+	ErrInvalidImportMode = fmt.Errorf("mode must be %s, %s or %s", ImportValidate, ImportDryRun, ImportApply)
+
+	// ErrInvalidAlerts is returned when rules of an import are invalid; the
+	// AlertImport lists them.
+	ErrInvalidAlerts = errors.New("invalid alert rules")
+)
+
+// AlertImport reports what importing alert rules did or, unless applied,
+// would do.
+type AlertImport struct {
+	Mode     string             `json:"mode"`
+	Rules    []AlertRule        `json:"rules"`            // Imported rules, with IDs once applied
+	Replaced int                `json:"replaced"`         // Alerts of the owner removed first
+	Applied  bool               `json:"applied"`          // Whether the rules were registered
+	Errors   []AlertImportError `json:"errors,omitempty"` // Invalid rules, if any
+}
+
+// AlertImportError is an invalid rule of an import.
+type AlertImportError struct {
+	Index int    `json:"index"` // Position of the rule, from 0
+	Error string `json:"error"`
+}
+
+// Import validates rules and, depending on the mode, registers them for
+// the owner. resolve, if not nil, returns the listed symbol a rule's
+// symbol stands for; an ErrUnknownSymbol or ErrInvalidSymbol from it makes
+// the rule invalid, and any other error is returned as is. With replace,
+// the owner's alerts are removed first instead of being added to. Rules
+// are applied all or none: it returns ErrInvalidAlerts if any rule is
+// invalid and ErrTooManyAlerts if the owner would end up with more than
+// MaxAlertsPerOwner alerts.
+func (b *AlertBook) Import(owner string, rules []AlertRule, mode string, replace bool, resolve func(symbol string) (string, error)) (AlertImport, error) {
+	report := AlertImport{Mode: mode, Rules: make([]AlertRule, 0, len(rules))}
+	switch mode {
+	case ImportValidate, ImportDryRun, ImportApply:
+	default:
+		return report, ErrInvalidImportMode
+	}
+
+	for idx, rule := range rules {
+		rule.ID = ""
+		var err error
+		if resolve != nil {
+			var symbol string
+			symbol, err = resolve(rule.Symbol)
+			switch {
+			case err == nil:
+				rule.Symbol = symbol
+			case !errors.Is(err, ErrUnknownSymbol) && !errors.Is(err, ErrInvalidSymbol):
+				return report, err
+			}
+		}
+		if err == nil {
+			err = validateAlertRule(rule)
+		}
+		if err != nil {
+			report.Errors = append(report.Errors, AlertImportError{Index: idx, Error: err.Error()})
+		}
+		report.Rules = append(report.Rules, rule)
+	}
+	if len(report.Errors) > 0 {
+		return report, ErrInvalidAlerts
+	}
+	if mode == ImportValidate {
+		if len(rules) > MaxAlertsPerOwner {
+			return report, ErrTooManyAlerts
+		}
+		return report, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	kept := b.owners[owner]
+	if replace {
+		report.Replaced, kept = kept, 0
+	}
+	if kept+len(rules) > MaxAlertsPerOwner {
+		return report, ErrTooManyAlerts
+	}
+	if mode == ImportDryRun {
+		return report, nil
+	}
+
+	if replace {
+		b.removeOwner(owner)
+	}
+	for idx, rule := range report.Rules {
+		b.nextID++
+		rule.ID = strconv.FormatInt(b.nextID, 10)
+		b.alerts[rule.Symbol] = append(b.alerts[rule.Symbol], &alertState{owner: owner, rule: rule})
+		b.owners[owner]++
+		report.Rules[idx] = rule
+	}
+	report.Applied = true
+	return report, nil
+}
+
+// alertsDocument is the YAML document alert rules are exported as.
+type alertsDocument struct {
+	Alerts *[]AlertRule `yaml:"alerts"` // Nil when the key is missing
+}
+
+// MarshalAlertsYAML writes alert rules as a YAML document ParseAlertsYAML
+// reads, without their IDs, so they can be versioned and imported
+// elsewhere:
+//
+//	alerts:
+//	  - symbol: BTCUSDT
+//	    condition: crosses
+//	    price: 100000
+func MarshalAlertsYAML(rules []AlertRule) []byte {
+	if rules == nil {
+		rules = []AlertRule{}
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	// Alert rules always encode
+	_ = encoder.Encode(alertsDocument{Alerts: &rules})
+	_ = encoder.Close()
+	return buf.Bytes()
+}
+
+// ParseAlertsYAML reads the alert rules of a document MarshalAlertsYAML
+// wrote: an "alerts" list of rules with the keys of AlertRule. Unknown
+// keys are rejected so typos do not import as different rules.
+func ParseAlertsYAML(data []byte) ([]AlertRule, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var document alertsDocument
+	if err := decoder.Decode(&document); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("expected \"alerts:\"")
+		}
+		return nil, err
+	}
+	if document.Alerts == nil {
+		return nil, errors.New("expected \"alerts:\"")
+	}
+	return *document.Alerts, nil
+}
@@ -0,0 +1,179 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestAlertsYAMLRoundTrip verifies that exported alert rules import as the
+// same rules, without their IDs.
+func TestAlertsYAMLRoundTrip(t *testing.T) {
+	rules := []AlertRule{
+		{ID: "1", Symbol: "BTCUSDT", Condition: AlertCrosses, Price: 100000.5},
+		{ID: "2", Symbol: "ETHUSDT", Condition: AlertChange, Percent: -5, WindowMs: 900000},
+	}
+
+	parsed, err := ParseAlertsYAML(MarshalAlertsYAML(rules))
+	if err != nil {
+		t.Fatalf("Failed to parse exported alerts: %v", err)
+	}
+	for idx := range rules {
+		rules[idx].ID = ""
+	}
+	if !reflect.DeepEqual(parsed, rules) {
+		t.Errorf("Expected %+v, got %+v", rules, parsed)
+	}
+
+	if parsed, err := ParseAlertsYAML(MarshalAlertsYAML(nil)); err != nil || len(parsed) != 0 {
+		t.Errorf("Expected no alerts, got %+v (%v)", parsed, err)
+	}
+}
+
+// TestParseAlertsYAML tests the alert documents ParseAlertsYAML accepts.
+func TestParseAlertsYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		expected []AlertRule
+		err      string
+	}{
+		{
+			"comments and quotes",
+			"---\n# BTC levels\nalerts:\n- symbol: \"BTCUSDT\" # unindented item\n  condition: 'crosses'\n  price: 1\n",
+			[]AlertRule{{Symbol: "BTCUSDT", Condition: AlertCrosses, Price: 1}},
+			"",
+		},
+		{"empty list", "alerts: []\n", []AlertRule{}, ""},
+		{"no alerts key", "rules:\n  - symbol: BTCUSDT\n", nil, "line 1: field rules not found"},
+		{"empty document", "", nil, "expected \"alerts:\""},
+		{"scalar alerts", "alerts: BTCUSDT\n", nil, "line 1: cannot unmarshal"},
+		{"item without key", "  - symbol: BTCUSDT\n", nil, "line 1: cannot unmarshal"},
+		{"field outside item", "alerts:\n  symbol: BTCUSDT\n", nil, "line 2: cannot unmarshal"},
+		{"misindented field", "alerts:\n  - symbol: BTCUSDT\n   condition: crosses\n", nil, "did not find expected"},
+		{"field of the next level", "alerts:\n  - symbol: BTCUSDT\n  condition: crosses\n", nil, "did not find expected"},
+		{"unknown key", "alerts:\n  - symbol: BTCUSDT\n    level: 1\n", nil, "line 3: field level not found"},
+		{"duplicate key", "alerts:\n  - price: 1\n    price: 2\n", nil, "line 3"},
+		{"not a number", "alerts:\n  - windowMs: 1m\n", nil, "line 2: cannot unmarshal"},
+		{"unterminated string", "alerts:\n  - symbol: \"BTC\n", nil, "line"},
+	}
+
+	for _, tt := range tests {
+		rules, err := ParseAlertsYAML([]byte(tt.yaml))
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(rules, tt.expected) {
+			t.Errorf("%s: expected %+v, got %+v", tt.name, tt.expected, rules)
+		}
+	}
+}
+
+// TestAlertBookImport tests validating, dry-running and applying alert
+// imports.
+func TestAlertBookImport(t *testing.T) {
+	valid := []AlertRule{
+		{Symbol: "BTCUSDT", Condition: AlertCrosses, Price: 100000},
+		{Symbol: "ETHUSDT", Condition: AlertChange, Percent: 5, WindowMs: 60000},
+	}
+	book := NewAlertBook()
+	book.Add("user:a", AlertRule{Symbol: "SOLUSDT", Condition: AlertCrosses, Price: 200})
+
+	if _, err := book.Import("user:a", valid, "merge", false, nil); !errors.Is(err, ErrInvalidImportMode) {
+		t.Errorf("Expected ErrInvalidImportMode, got %v", err)
+	}
+
+	invalid := append([]AlertRule{{Symbol: "BTCUSDT", Condition: "above"}}, valid...)
+	report, err := book.Import("user:a", invalid, ImportApply, false, nil)
+	if !errors.Is(err, ErrInvalidAlerts) || len(report.Errors) != 1 || report.Errors[0].Index != 0 {
+		t.Errorf("Expected the first rule to be invalid, got %+v (%v)", report, err)
+	}
+	if report.Applied || len(book.List("user:a")) != 1 {
+		t.Errorf("Expected an invalid import to change nothing, got %+v", book.List("user:a"))
+	}
+
+	for _, mode := range []string{ImportValidate, ImportDryRun} {
+		report, err := book.Import("user:a", valid, mode, true, nil)
+		if err != nil || report.Applied || len(report.Rules) != 2 {
+			t.Errorf("%s: expected the rules to be checked only, got %+v (%v)", mode, report, err)
+		}
+		if len(book.List("user:a")) != 1 {
+			t.Errorf("%s: expected the alerts to be unchanged, got %+v", mode, book.List("user:a"))
+		}
+	}
+	if report, _ := book.Import("user:a", valid, ImportDryRun, true, nil); report.Replaced != 1 {
+		t.Errorf("Expected a dry run with replace to report 1 replaced alert, got %d", report.Replaced)
+	}
+
+	report, err = book.Import("user:a", valid, ImportApply, false, nil)
+	if err != nil || !report.Applied || report.Rules[0].ID == "" {
+		t.Fatalf("Expected the rules to be applied with IDs, got %+v (%v)", report, err)
+	}
+	if rules := book.List("user:a"); len(rules) != 3 {
+		t.Errorf("Expected the import to add to the alerts, got %+v", rules)
+	}
+
+	report, err = book.Import("user:a", valid[:1], ImportApply, true, nil)
+	if err != nil || report.Replaced != 3 {
+		t.Errorf("Expected 3 replaced alerts, got %+v (%v)", report, err)
+	}
+	if rules := book.List("user:a"); len(rules) != 1 || rules[0].Symbol != "BTCUSDT" {
+		t.Errorf("Expected only the imported alert, got %+v", rules)
+	}
+
+	many := make([]AlertRule, MaxAlertsPerOwner)
+	for idx := range many {
+		many[idx] = valid[0]
+	}
+	if _, err := book.Import("user:a", many, ImportDryRun, false, nil); !errors.Is(err, ErrTooManyAlerts) {
+		t.Errorf("Expected ErrTooManyAlerts when adding, got %v", err)
+	}
+	if _, err := book.Import("user:a", many, ImportApply, true, nil); err != nil {
+		t.Errorf("Expected replacing with %d alerts to succeed, got %v", MaxAlertsPerOwner, err)
+	}
+	if _, err := book.Import("user:b", append(many, valid[0]), ImportValidate, false, nil); !errors.Is(err, ErrTooManyAlerts) {
+		t.Errorf("Expected validation to reject more than %d alerts, got %v", MaxAlertsPerOwner, err)
+	}
+}
+
+// TestAlertBookImportResolve tests resolving the symbols of imported rules
+// against the listed symbols.
+func TestAlertBookImportResolve(t *testing.T) {
+	ingestor := NewIngestor(HubSink(NewHub()), WithSymbolLister(listing("BTCUSDT", "WBTCUSDT")))
+	resolve := func(symbol string) (string, error) {
+		return ingestor.ValidateSymbol(context.Background(), symbol)
+	}
+	rules := []AlertRule{
+		{Symbol: "btc", Condition: AlertCrosses, Price: 100000},
+		{Symbol: "DOGEUSDT", Condition: AlertCrosses, Price: 1},
+		{Symbol: "WBTC", Condition: AlertCrosses, Price: 100000},
+	}
+
+	book := NewAlertBook()
+	report, err := book.Import("user:a", rules, ImportApply, false, resolve)
+	if !errors.Is(err, ErrInvalidAlerts) || len(report.Errors) != 1 || report.Errors[0].Index != 1 {
+		t.Fatalf("Expected the unlisted symbol to be invalid, got %+v (%v)", report, err)
+	}
+	if report.Rules[0].Symbol != "BTCUSDT" || report.Rules[2].Symbol != "WBTCUSDT" {
+		t.Errorf("Expected resolved symbols, got %+v", report.Rules)
+	}
+
+	unavailable := func(string) (string, error) {
+		return "", ErrSymbolCatalogUnavailable
+	}
+	if _, err := book.Import("user:a", rules[:1], ImportApply, false, unavailable); !errors.Is(err, ErrSymbolCatalogUnavailable) {
+		t.Errorf("Expected ErrSymbolCatalogUnavailable, got %v", err)
+	}
+	if rules := book.List("user:a"); len(rules) != 0 {
+		t.Errorf("Expected no alerts, got %+v", rules)
+	}
+}
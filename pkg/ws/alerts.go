@@ -0,0 +1,380 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// AlertCrosses triggers when the price reaches a level from either
+	// side; the first price evaluated only tells the side
+	AlertCrosses = "crosses"
+
+	// AlertChange triggers when the price moved by a percentage within a
+	// window, up for a positive percentage and down for a negative one
+	AlertChange = "change"
+
+	// MaxAlertsPerOwner caps the alerts of each user or connection
+	MaxAlertsPerOwner = 20
+
+	// MinAlertWindow and MaxAlertWindow bound the window of change alerts,
+	// which are measured against the recent price history
+	MinAlertWindow = time.Second
+	MaxAlertWindow = DefaultRecentWindow
+)
+
+var (
+	// ErrAlertNotFound is returned when removing an alert the owner does
+	// not have.
+	ErrAlertNotFound = errors.New("alert not found")
+
+	// ErrTooManyAlerts is returned when an owner has MaxAlertsPerOwner
+	// alerts already.
+	ErrTooManyAlerts = fmt.Errorf("at most %d alerts are allowed", MaxAlertsPerOwner)
+
+	// ErrNoAlerts is returned by the alert commands of clients of streams
+	// without alerts.
+	ErrNoAlerts = errors.New("alerts are not available on this stream")
+)
+
+// connAlertOwners numbers the connections of anonymous clients that set
+// alerts
+var connAlertOwners atomic.Int64
+
+// AlertRule is a price alert registered by a client, e.g.
+// {"symbol":"BTCUSDT","condition":"crosses","price":100000} or
+// {"symbol":"ETHUSDT","condition":"change","percent":-5,"windowMs":900000}.
+type AlertRule struct {
+	ID        string  `json:"id" yaml:"-"`
+	Symbol    string  `json:"symbol" yaml:"symbol"`
+	Condition string  `json:"condition" yaml:"condition"`                   // "crosses" or "change"
+	Price     float64 `json:"price,omitempty" yaml:"price,omitempty"`       // Level of crosses alerts
+	Percent   float64 `json:"percent,omitempty" yaml:"percent,omitempty"`   // Signed move of change alerts
+	WindowMs  int64   `json:"windowMs,omitempty" yaml:"windowMs,omitempty"` // Window of change alerts
+}
+
+// Alert is sent to the owner of a rule when it triggers. Rules trigger
+// once and are then removed.
+type Alert struct {
+	Type          string    `json:"type"` // Always "alert"
+	Rule          AlertRule `json:"rule"`
+	Price         float64   `json:"price"`                   // Price that triggered the rule
+	ChangePercent float64   `json:"changePercent,omitempty"` // Move within the window of change alerts
+	TriggeredAt   int64     `json:"triggeredAt"`             // Unix ms
+}
+
+// AlertBook holds the price alerts of all clients, evaluated by the
+// ingestor of the active feed on each price update. It is safe for
+// concurrent use.
+type AlertBook struct {
+	mu     sync.Mutex
+	alerts map[string][]*alertState // By symbol
+	owners map[string]int           // Number of alerts per owner
+	nextID int64
+}
+
+// alertState is a rule with its owner and the last price it was evaluated
+// against.
+type alertState struct {
+	owner     string
+	rule      AlertRule
+	lastPrice float64
+}
+
+// triggeredAlert is an alert to deliver to its owner.
+type triggeredAlert struct {
+	owner string
+	alert Alert
+}
+
+// NewAlertBook creates an empty alert book.
+func NewAlertBook() *AlertBook {
+	return &AlertBook{
+		alerts: make(map[string][]*alertState),
+		owners: make(map[string]int),
+	}
+}
+
+// UserAlertOwner returns the owner of the alerts of an authenticated user,
+// which reach every price stream of the user.
+func UserAlertOwner(userID string) string {
+	return "user:" + userID
+}
+
+// Add validates a rule for a resolved symbol and registers it for the
+// owner. It returns the rule with its assigned ID.
+func (b *AlertBook) Add(owner string, rule AlertRule) (AlertRule, error) {
+	if err := validateAlertRule(rule); err != nil {
+		return AlertRule{}, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.owners[owner] >= MaxAlertsPerOwner {
+		return AlertRule{}, ErrTooManyAlerts
+	}
+	b.nextID++
+	rule.ID = strconv.FormatInt(b.nextID, 10)
+	b.alerts[rule.Symbol] = append(b.alerts[rule.Symbol], &alertState{owner: owner, rule: rule})
+	b.owners[owner]++
+	return rule, nil
+}
+
+// validateAlertRule checks the symbol and the parameters of the condition.
+func validateAlertRule(rule AlertRule) error {
+	if !symbolPattern.MatchString(rule.Symbol) {
+		return fmt.Errorf("invalid symbol %q", rule.Symbol)
+	}
+
+	switch rule.Condition {
+	case AlertCrosses:
+		if rule.Price <= 0 {
+			return errors.New("price must be positive")
+		}
+	case AlertChange:
+		window := time.Duration(rule.WindowMs) * time.Millisecond
+		if rule.Percent == 0 {
+			return errors.New("percent must not be zero")
+		}
+		if window < MinAlertWindow || window > MaxAlertWindow {
+			return fmt.Errorf("windowMs must be %d to %d", MinAlertWindow.Milliseconds(), MaxAlertWindow.Milliseconds())
+		}
+	default:
+		return fmt.Errorf("condition must be %s or %s", AlertCrosses, AlertChange)
+	}
+	return nil
+}
+
+// Remove removes an alert of the owner.
+func (b *AlertBook) Remove(owner, id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for symbol, states := range b.alerts {
+		for idx, state := range states {
+			if state.owner == owner && state.rule.ID == id {
+				b.removeAt(symbol, idx)
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrAlertNotFound, id)
+}
+
+// RemoveOwner removes every alert of the owner, e.g. of a connection that
+// closed.
+func (b *AlertBook) RemoveOwner(owner string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.removeOwner(owner)
+}
+
+// removeOwner removes every alert of the owner. b.mu must be held.
+func (b *AlertBook) removeOwner(owner string) {
+	for symbol, states := range b.alerts {
+		for idx := len(states) - 1; idx >= 0; idx-- {
+			if states[idx].owner == owner {
+				b.removeAt(symbol, idx)
+				states = b.alerts[symbol]
+			}
+		}
+	}
+}
+
+// List returns the alerts of the owner, oldest first.
+func (b *AlertBook) List(owner string) []AlertRule {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	rules := []AlertRule{}
+	for _, states := range b.alerts {
+		for _, state := range states {
+			if state.owner == owner {
+				rules = append(rules, state.rule)
+			}
+		}
+	}
+	sort.Slice(rules, func(a, b int) bool { return alertID(rules[a]) < alertID(rules[b]) })
+	return rules
+}
+
+// alertID returns the numeric ID of a rule, which follows its creation.
+func alertID(rule AlertRule) int64 {
+	id, _ := strconv.ParseInt(rule.ID, 10, 64)
+	return id
+}
+
+// removeAt removes the alert at idx of a symbol. b.mu must be held.
+func (b *AlertBook) removeAt(symbol string, idx int) {
+	states := b.alerts[symbol]
+	owner := states[idx].owner
+
+	states = append(states[:idx], states[idx+1:]...)
+	if len(states) == 0 {
+		delete(b.alerts, symbol)
+	} else {
+		b.alerts[symbol] = states
+	}
+
+	if b.owners[owner]--; b.owners[owner] <= 0 {
+		delete(b.owners, owner)
+	}
+}
+
+// evaluate checks the alerts of a symbol against a new price and removes
+// and returns those that triggered. reference returns the price of the
+// symbol a window ago, for change alerts.
+func (b *AlertBook) evaluate(symbol string, price float64, now time.Time, reference func(window time.Duration) (float64, bool)) []triggeredAlert {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	states := b.alerts[symbol]
+	var triggered []triggeredAlert
+	for idx := len(states) - 1; idx >= 0; idx-- {
+		state := states[idx]
+		alert := Alert{Type: "alert", Rule: state.rule, Price: price, TriggeredAt: now.UnixMilli()}
+
+		switch state.rule.Condition {
+		case AlertCrosses:
+			level, last := state.rule.Price, state.lastPrice
+			state.lastPrice = price
+			crossedUp := last < level && price >= level
+			crossedDown := last > level && price <= level
+			if last == 0 || !(crossedUp || crossedDown) {
+				continue
+			}
+		case AlertChange:
+			past, ok := reference(time.Duration(state.rule.WindowMs) * time.Millisecond)
+			if !ok || past <= 0 {
+				continue
+			}
+			alert.ChangePercent = (price - past) / past * 100
+			if (state.rule.Percent > 0 && alert.ChangePercent < state.rule.Percent) ||
+				(state.rule.Percent < 0 && alert.ChangePercent > state.rule.Percent) {
+				continue
+			}
+		}
+
+		triggered = append(triggered, triggeredAlert{owner: state.owner, alert: alert})
+		b.removeAt(symbol, idx)
+		states = b.alerts[symbol]
+	}
+	return triggered
+}
+
+// WithAlerts makes the ingestor evaluate the price alerts of the book on
+// each price update while it is the active feed, and send the alerts that
+// trigger to the clients owning them.
+func WithAlerts(book *AlertBook) IngestorOption {
+	return func(i *Ingestor) {
+		i.alerts = book
+	}
+}
+
+// evaluateAlerts sends the alerts a new price of the symbol triggers.
+func (i *Ingestor) evaluateAlerts(symbol string, price float64, now time.Time) {
+	if i.alerts == nil || !i.audience.IsActiveSource(i.name) {
+		return
+	}
+
+	triggered := i.alerts.evaluate(symbol, price, now, func(window time.Duration) (float64, bool) {
+		points, err := i.Recent(symbol, window)
+		if err != nil || len(points) == 0 {
+			return 0, false
+		}
+		return points[0].Price, true
+	})
+	for _, alert := range triggered {
+		jsonData, err := json.Marshal(&alert.alert)
+		if err != nil {
+			log.Printf("Error marshaling alert: %v", err)
+			continue
+		}
+		if i.audience.SendToOwner(alert.owner, jsonData) == 0 {
+			log.Printf("Alert %s on %s triggered without a connected owner", alert.alert.Rule.ID, symbol)
+		}
+	}
+}
+
+// SendToOwner sends a message to the clients owning alerts as owner, and
+// returns how many it was queued for.
+func (h *Hub) SendToOwner(owner string, message []byte) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	sent := 0
+	now := h.clock.Now()
+	for client := range h.clients {
+		if client.alertOwner() == owner && client.enqueue(message, now) {
+			sent++
+		}
+	}
+	return sent
+}
+
+// alertOwner returns the owner of the client's alerts: its user, if
+// authenticated, or its connection once it set an alert.
+func (c *Client) alertOwner() string {
+	if c.UserID != "" {
+		return UserAlertOwner(c.UserID)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.connAlertOwner
+}
+
+// dropAlerts removes the alerts of an anonymous client whose connection
+// closed. The alerts of authenticated users are kept for their other and
+// later connections.
+func (c *Client) dropAlerts() {
+	if c.Alerts == nil || c.UserID != "" {
+		return
+	}
+	if owner := c.alertOwner(); owner != "" {
+		c.Alerts.RemoveOwner(owner)
+	}
+}
+
+// applyAlertCommand sets or removes an alert of the client, or lists them,
+// and returns the client's alerts for the ack.
+func (c *Client) applyAlertCommand(command Command) ([]AlertRule, error) {
+	if c.Alerts == nil {
+		return nil, ErrNoAlerts
+	}
+
+	c.mu.Lock()
+	if c.UserID == "" && c.connAlertOwner == "" {
+		c.connAlertOwner = "conn:" + strconv.FormatInt(connAlertOwners.Add(1), 10)
+	}
+	c.mu.Unlock()
+	owner := c.alertOwner()
+
+	switch command.Type {
+	case CommandSetAlert:
+		if command.Alert == nil {
+			return nil, errors.New("alert is required")
+		}
+		rule := *command.Alert
+		rule.Symbol = c.resolveSymbol(rule.Symbol)
+		if c.Symbols != nil && !c.Symbols[rule.Symbol] {
+			return nil, fmt.Errorf("symbol %s is outside this stream's scope", rule.Symbol)
+		}
+		if _, err := c.Alerts.Add(owner, rule); err != nil {
+			return nil, err
+		}
+	case CommandRemoveAlert:
+		if err := c.Alerts.Remove(owner, command.AlertID); err != nil {
+			return nil, err
+		}
+	}
+	return c.Alerts.List(owner), nil
+}
@@ -0,0 +1,172 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestAlertBookAdd tests the validation of alert rules.
+func TestAlertBookAdd(t *testing.T) {
+	tests := []struct {
+		name string
+		rule AlertRule
+		err  string
+	}{
+		{"crosses", AlertRule{Symbol: "BTCUSDT", Condition: AlertCrosses, Price: 100000}, ""},
+		{"change", AlertRule{Symbol: "ETHUSDT", Condition: AlertChange, Percent: -5, WindowMs: 60000}, ""},
+		{"invalid symbol", AlertRule{Symbol: "BTC.USDT", Condition: AlertCrosses, Price: 1}, "invalid symbol"},
+		{"unknown condition", AlertRule{Symbol: "BTCUSDT", Condition: "above", Price: 1}, "condition must be"},
+		{"no level", AlertRule{Symbol: "BTCUSDT", Condition: AlertCrosses}, "price must be positive"},
+		{"no move", AlertRule{Symbol: "BTCUSDT", Condition: AlertChange, WindowMs: 60000}, "percent"},
+		{"window too long", AlertRule{Symbol: "BTCUSDT", Condition: AlertChange, Percent: 1, WindowMs: time.Hour.Milliseconds()}, "windowMs"},
+	}
+
+	for _, tt := range tests {
+		rule, err := NewAlertBook().Add("conn:1", tt.rule)
+		if tt.err == "" && (err != nil || rule.ID == "") {
+			t.Errorf("%s: expected an ID, got %+v (%v)", tt.name, rule, err)
+		}
+		if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.err, err)
+		}
+	}
+
+	book := NewAlertBook()
+	for range MaxAlertsPerOwner {
+		book.Add("conn:1", AlertRule{Symbol: "BTCUSDT", Condition: AlertCrosses, Price: 1})
+	}
+	if _, err := book.Add("conn:1", AlertRule{Symbol: "BTCUSDT", Condition: AlertCrosses, Price: 1}); !errors.Is(err, ErrTooManyAlerts) {
+		t.Errorf("Expected ErrTooManyAlerts, got %v", err)
+	}
+	if _, err := book.Add("conn:2", AlertRule{Symbol: "BTCUSDT", Condition: AlertCrosses, Price: 1}); err != nil {
+		t.Errorf("Expected other owners to be unaffected, got %v", err)
+	}
+}
+
+// TestAlertBookEvaluate verifies rules trigger once, when the price crosses
+// their level or moves by their percentage within their window.
+func TestAlertBookEvaluate(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	reference := func(time.Duration) (float64, bool) { return 100, true }
+
+	tests := []struct {
+		name      string
+		rule      AlertRule
+		prices    []float64
+		triggerAt int // Index of the price that triggers, -1 for none
+	}{
+		{"crosses upwards", AlertRule{Condition: AlertCrosses, Price: 105}, []float64{100, 104, 106, 104, 106}, 2},
+		{"crosses downwards", AlertRule{Condition: AlertCrosses, Price: 95}, []float64{100, 95}, 1},
+		{"first price is a baseline", AlertRule{Condition: AlertCrosses, Price: 105}, []float64{110, 111}, -1},
+		{"rises by percent", AlertRule{Condition: AlertChange, Percent: 5, WindowMs: 60000}, []float64{104, 105}, 1},
+		{"falls by percent", AlertRule{Condition: AlertChange, Percent: -5, WindowMs: 60000}, []float64{106, 96, 94}, 2},
+	}
+
+	for _, tt := range tests {
+		book := NewAlertBook()
+		tt.rule.Symbol = "BTCUSDT"
+		if _, err := book.Add("conn:1", tt.rule); err != nil {
+			t.Fatalf("%s: failed to add rule: %v", tt.name, err)
+		}
+
+		triggeredAt := -1
+		for idx, price := range tt.prices {
+			triggered := book.evaluate("BTCUSDT", price, now, reference)
+			if len(triggered) == 0 {
+				continue
+			}
+			if triggeredAt != -1 {
+				t.Errorf("%s: expected the rule to trigger once, triggered again at %v", tt.name, price)
+			}
+			triggeredAt = idx
+			if alert := triggered[0].alert; triggered[0].owner != "conn:1" || alert.Type != "alert" || alert.Price != price {
+				t.Errorf("%s: unexpected alert %+v for %s", tt.name, alert, triggered[0].owner)
+			}
+		}
+
+		if triggeredAt != tt.triggerAt {
+			t.Errorf("%s: expected to trigger at %d, got %d", tt.name, tt.triggerAt, triggeredAt)
+		}
+	}
+}
+
+// TestAlertCommands tests setting, listing and removing alerts with
+// commands.
+func TestAlertCommands(t *testing.T) {
+	client := &Client{Alerts: NewAlertBook()}
+	now := time.Now()
+
+	ack, ok := client.handleCommand([]byte(`{"type":"set_alert","id":"1","alert":{"symbol":"btc","condition":"crosses","price":100000}}`), now).(*CommandAck)
+	if !ok || len(ack.Alerts) != 1 || ack.Alerts[0].Symbol != "BTCUSDT" {
+		t.Fatalf("Expected an ack listing the BTCUSDT alert, got %+v", ack)
+	}
+	id := ack.Alerts[0].ID
+
+	if ack, ok := client.handleCommand([]byte(`{"type":"list_alerts"}`), now).(*CommandAck); !ok || len(ack.Alerts) != 1 {
+		t.Errorf("Expected the alert to be listed, got %+v", ack)
+	}
+	if ack, ok := client.handleCommand([]byte(`{"type":"remove_alert","alertId":"`+id+`"}`), now).(*CommandAck); !ok || len(ack.Alerts) != 0 {
+		t.Errorf("Expected the alert to be removed, got %+v", ack)
+	}
+
+	tests := []struct {
+		name    string
+		client  *Client
+		command string
+		err     string
+	}{
+		{"missing alert", client, `{"type":"set_alert"}`, "alert is required"},
+		{"unknown alert", client, `{"type":"remove_alert","alertId":"99"}`, ErrAlertNotFound.Error()},
+		{"invalid rule", client, `{"type":"set_alert","alert":{"symbol":"BTCUSDT","condition":"crosses"}}`, "price"},
+		{"out of scope", &Client{Alerts: NewAlertBook(), Symbols: map[string]bool{"ETHUSDT": true}},
+			`{"type":"set_alert","alert":{"symbol":"BTCUSDT","condition":"crosses","price":1}}`, "outside"},
+		{"no alerts", &Client{}, `{"type":"list_alerts"}`, ErrNoAlerts.Error()},
+	}
+	for _, tt := range tests {
+		response, ok := tt.client.handleCommand([]byte(tt.command), now).(*CommandError)
+		if !ok || !strings.Contains(response.Error, tt.err) {
+			t.Errorf("%s: expected an error containing %q, got %+v", tt.name, tt.err, response)
+		}
+	}
+}
+
+// TestIngestorSendsAlertsToOwner verifies a triggered alert reaches only the
+// client that set it, and that the alerts of anonymous clients are dropped
+// when they disconnect.
+func TestIngestorSendsAlertsToOwner(t *testing.T) {
+	hub := NewHub()
+	book := NewAlertBook()
+	owner := &Client{Hub: hub, Send: make(chan []byte, 4), Alerts: book}
+	other := &Client{Hub: hub, Send: make(chan []byte, 4), Alerts: book}
+	hub.clients[owner] = true
+	hub.clients[other] = true
+	owner.handleCommand([]byte(`{"type":"set_alert","alert":{"symbol":"BTCUSDT","condition":"crosses","price":50050}}`), time.Now())
+	owner.handleCommand([]byte(`{"type":"set_alert","alert":{"symbol":"BTCUSDT","condition":"crosses","price":60000}}`), time.Now())
+
+	ingestor := NewIngestor(HubSink(hub), WithAlerts(book))
+	var pendingUpdate *MultiUpdate
+	handler := ingestor.createWebSocketHandler(&pendingUpdate)
+	handler(newStatEvent(1000, "50000.00"))
+	handler(newStatEvent(2000, "50100.00"))
+
+	select {
+	case message := <-owner.Send:
+		var alert Alert
+		if err := json.Unmarshal(message, &alert); err != nil || alert.Type != "alert" || alert.Rule.Price != 50050 || alert.Price != 50100 {
+			t.Errorf("Unexpected alert %s", message)
+		}
+	default:
+		t.Fatal("Expected the owner to receive the alert")
+	}
+	if len(other.Send) != 0 {
+		t.Errorf("Expected other clients not to receive the alert, got %s", <-other.Send)
+	}
+
+	hub.unregisterClient(owner)
+	if rules := book.List(owner.alertOwner()); len(rules) != 0 {
+		t.Errorf("Expected the alerts of the closed connection to be dropped, got %+v", rules)
+	}
+}
@@ -0,0 +1,44 @@
+package ws
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// AttributionNotice is broadcast periodically to credit the source of the
+// streamed market data, as upstream terms require.
+type AttributionNotice struct {
+	Type        string `json:"type"` // Always "attribution"
+	Source      string `json:"source"`
+	Citation    string `json:"citation"`
+	URL         string `json:"url,omitempty"`
+	RetrievedAt int64  `json:"retrievedAt"` // Unix ms of the last ingested event
+}
+
+// PublishAttribution broadcasts the source of this feed's data, with its
+// citation, URL and when it was last retrieved, to clients. Nothing is sent
+// for an inactive feed or while the Hub signals backpressure.
+func (i *Ingestor) PublishAttribution(source, citation, url string, retrievedAt time.Time) {
+	if !i.audience.IsActiveSource(i.name) || i.underBackpressure.Load() {
+		return
+	}
+
+	notice := &AttributionNotice{
+		Type:     "attribution",
+		Source:   source,
+		Citation: citation,
+		URL:      url,
+	}
+	if !retrievedAt.IsZero() {
+		notice.RetrievedAt = retrievedAt.UnixMilli()
+	}
+
+	jsonData, err := json.Marshal(notice)
+	if err != nil {
+		log.Printf("Error marshaling attribution: %v", err)
+		return
+	}
+
+	i.publish("", jsonData)
+}
@@ -0,0 +1,39 @@
+package ws
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// TestPublishAttribution verifies attribution notices reach the Hub only
+// from the active feed.
+func TestPublishAttribution(t *testing.T) {
+	hub := NewHub()
+	ingestor := NewIngestor(HubSink(hub))
+	retrievedAt := time.UnixMilli(1708424625120)
+
+	ingestor.PublishAttribution("Binance", "Market data provided by Binance", "https://www.binance.com/", retrievedAt)
+
+	select {
+	case queued := <-hub.broadcast:
+		var notice AttributionNotice
+		if err := json.Unmarshal(queued.data, &notice); err != nil {
+			t.Fatalf("Failed to decode notice: %v", err)
+		}
+		if notice.Type != "attribution" || notice.Source != "Binance" || notice.RetrievedAt != 1708424625120 {
+			t.Errorf("Unexpected notice: %+v", notice)
+		}
+	default:
+		t.Fatal("Expected an attribution notice")
+	}
+
+	hub.SetActiveSource("other")
+	ingestor.PublishAttribution("Binance", "Market data provided by Binance", "https://www.binance.com/", retrievedAt)
+
+	select {
+	case queued := <-hub.broadcast:
+		t.Errorf("Expected no notice, got %s", queued.data)
+	default:
+	}
+}
@@ -0,0 +1,240 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultAuditSampleEvery is the sampling rate of the broadcast audit
+	// when enabling it without one: 1 in 100 broadcasts
+	DefaultAuditSampleEvery = 100
+
+	// DefaultAuditMaxBytes is the size at which the audit file is rotated
+	DefaultAuditMaxBytes = 10 << 20
+)
+
+var (
+	// ErrAuditUnavailable is returned when enabling the broadcast audit of
+	// a Hub without an audit file.
+	ErrAuditUnavailable = errors.New("no broadcast audit file configured")
+
+	// ErrInvalidSampleRate is returned for a sampling rate below 1.
+	ErrInvalidSampleRate = errors.New("sample rate must be at least 1")
+)
+
+// AuditSample is a sampled broadcast as written to the audit file, one
+// JSON object per line, for diagnosing reports of clients showing wrong
+// prices.
+type AuditSample struct {
+	Time         time.Time       `json:"time"`
+	Broadcast    uint64          `json:"broadcast"`     // Number of the broadcast since the audit was enabled
+	Clients      int             `json:"clients"`       // Clients connected at send
+	ChannelDepth int             `json:"channel_depth"` // Messages still queued in the broadcast channel
+	QueuedMs     int64           `json:"queued_ms"`     // Time the message waited in the channel
+	Bytes        int             `json:"bytes"`
+	Payload      json.RawMessage `json:"payload"`
+}
+
+// AuditStatus describes the broadcast audit of a Hub.
+type AuditStatus struct {
+	Enabled     bool   `json:"enabled"`
+	SampleEvery int    `json:"sample_every,omitempty"`
+	File        string `json:"file"`
+	Broadcasts  uint64 `json:"broadcasts"` // Broadcasts seen since the audit was enabled
+	Sampled     uint64 `json:"sampled"`    // Samples written since the audit was enabled
+}
+
+// WithAuditFile sets the file sampled broadcasts are written to once the
+// audit is enabled with EnableAudit. The file is rotated to <path>.1 when it
+// would exceed maxBytes, so the audit uses at most twice that on disk.
+func WithAuditFile(path string, maxBytes int64) HubOption {
+	return func(h *Hub) {
+		if path != "" {
+			h.audit = &auditLog{path: path, maxBytes: maxBytes}
+		}
+	}
+}
+
+// auditLog samples broadcasts to a rotating file while enabled.
+type auditLog struct {
+	mu         sync.Mutex
+	path       string
+	maxBytes   int64
+	every      int // Sample 1 in every broadcasts, 0 while disabled
+	broadcasts uint64
+	sampled    uint64
+	file       *os.File
+	size       int64
+}
+
+// EnableAudit starts writing 1 in every broadcasts to the audit file,
+// restarting the counts if the audit is already enabled.
+func (h *Hub) EnableAudit(every int) error {
+	if h.audit == nil {
+		return ErrAuditUnavailable
+	}
+	if every < 1 {
+		return ErrInvalidSampleRate
+	}
+
+	a := h.audit
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.file == nil {
+		if err := a.open(); err != nil {
+			return err
+		}
+	}
+	a.every = every
+	a.broadcasts, a.sampled = 0, 0
+	log.Printf("Broadcast audit enabled, sampling 1 in %d broadcasts to %s", every, a.path)
+	return nil
+}
+
+// DisableAudit stops sampling broadcasts and closes the audit file.
+func (h *Hub) DisableAudit() error {
+	if h.audit == nil {
+		return nil
+	}
+
+	a := h.audit
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.every = 0
+	if a.file == nil {
+		return nil
+	}
+	err := a.file.Close()
+	a.file = nil
+	log.Printf("Broadcast audit disabled after %d samples", a.sampled)
+	return err
+}
+
+// AuditStatus reports whether broadcasts are sampled and how many were.
+func (h *Hub) AuditStatus() AuditStatus {
+	if h.audit == nil {
+		return AuditStatus{}
+	}
+
+	a := h.audit
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return AuditStatus{
+		Enabled:     a.every > 0,
+		SampleEvery: a.every,
+		File:        a.path,
+		Broadcasts:  a.broadcasts,
+		Sampled:     a.sampled,
+	}
+}
+
+// auditBroadcast writes a broadcast to the audit file if it is sampled.
+// It runs on the Run loop before fan-out, so the client count and channel
+// depth are those the message is sent with.
+func (h *Hub) auditBroadcast(message queuedMessage, now time.Time) {
+	if h.audit == nil {
+		return
+	}
+
+	a := h.audit
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.every == 0 {
+		return
+	}
+	a.broadcasts++
+	if (a.broadcasts-1)%uint64(a.every) != 0 {
+		return
+	}
+
+	h.mu.RLock()
+	clients := len(h.clients)
+	h.mu.RUnlock()
+
+	sample := AuditSample{
+		Time:         now,
+		Broadcast:    a.broadcasts,
+		Clients:      clients,
+		ChannelDepth: len(h.broadcast),
+		QueuedMs:     now.Sub(message.queuedAt).Milliseconds(),
+		Bytes:        len(message.data),
+		Payload:      message.data,
+	}
+	if !json.Valid(sample.Payload) {
+		sample.Payload, _ = json.Marshal(string(message.data))
+	}
+	if err := a.write(sample); err != nil {
+		log.Printf("Error writing broadcast audit sample: %v", err)
+		return
+	}
+	a.sampled++
+}
+
+// open opens the audit file for appending. The caller holds a.mu.
+func (a *auditLog) open() error {
+	if err := os.MkdirAll(filepath.Dir(a.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create audit directory: %w", err)
+	}
+	file, err := os.OpenFile(a.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open audit file: %w", err)
+	}
+
+	a.file, a.size = file, info.Size()
+	return nil
+}
+
+// write appends a sample to the audit file, rotating it first if the
+// sample would exceed the maximum size. The caller holds a.mu.
+func (a *auditLog) write(sample AuditSample) error {
+	line, err := json.Marshal(sample)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if a.file == nil {
+		// A failed rotation left no file open
+		if err := a.open(); err != nil {
+			return err
+		}
+	}
+	if a.maxBytes > 0 && a.size > 0 && a.size+int64(len(line)) > a.maxBytes {
+		if err := a.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := a.file.Write(line)
+	a.size += int64(n)
+	return err
+}
+
+// rotate moves the audit file to <path>.1, replacing an earlier rotation,
+// and starts a new one. The caller holds a.mu.
+func (a *auditLog) rotate() error {
+	err := a.file.Close()
+	a.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to close audit file: %w", err)
+	}
+	if err := os.Rename(a.path, a.path+".1"); err != nil {
+		return fmt.Errorf("failed to rotate audit file: %w", err)
+	}
+	return a.open()
+}
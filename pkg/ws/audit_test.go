@@ -0,0 +1,113 @@
+package ws
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestAuditBroadcastSampling verifies 1 in N broadcasts are written to the
+// audit file with their metadata, and none once the audit is disabled.
+func TestAuditBroadcastSampling(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	hub := NewHub(WithAuditFile(path, DefaultAuditMaxBytes))
+	hub.clients[&Client{}] = true
+	hub.broadcast <- queuedMessage{data: []byte(`{"type":"heartbeat"}`)}
+	if err := hub.EnableAudit(2); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Act
+	now := time.Now()
+	for idx := range 5 {
+		data, _ := json.Marshal(&MultiUpdate{Type: "multi_update", Data: []*PriceUpdate{{Symbol: "BTCUSDT", Price: float64(idx)}}})
+		hub.auditBroadcast(queuedMessage{data: data, queuedAt: now.Add(-10 * time.Millisecond)}, now)
+	}
+	if err := hub.DisableAudit(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	hub.auditBroadcast(queuedMessage{data: []byte(`{}`), queuedAt: now}, now)
+
+	// Assert
+	samples := readAuditSamples(t, path)
+	if len(samples) != 3 {
+		t.Fatalf("Expected broadcasts 1, 3 and 5 to be sampled, got %d samples", len(samples))
+	}
+	first := samples[0]
+	if first.Broadcast != 1 || first.Clients != 1 || first.ChannelDepth != 1 || first.QueuedMs != 10 {
+		t.Errorf("Unexpected sample metadata %+v", first)
+	}
+	var update MultiUpdate
+	if err := json.Unmarshal(samples[2].Payload, &update); err != nil || update.Data[0].Price != 4 {
+		t.Errorf("Expected the payload of broadcast 5, got %s", samples[2].Payload)
+	}
+
+	status := hub.AuditStatus()
+	if status.Enabled || status.Broadcasts != 5 || status.Sampled != 3 {
+		t.Errorf("Unexpected status %+v", status)
+	}
+}
+
+// TestAuditRotation verifies the audit file is rotated before it exceeds
+// its maximum size.
+func TestAuditRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	hub := NewHub(WithAuditFile(path, 400))
+	hub.EnableAudit(1)
+
+	// Each sample is about 170 bytes, so the third one rotates the file
+	now := time.UnixMilli(1708424625120).UTC()
+	for range 3 {
+		hub.auditBroadcast(queuedMessage{data: []byte(`{"type":"heartbeat","timestamp":1708424625120}`), queuedAt: now}, now)
+	}
+
+	if rotated := readAuditSamples(t, path+".1"); len(rotated) != 2 {
+		t.Errorf("Expected 2 samples in the rotated file, got %d", len(rotated))
+	}
+	if current := readAuditSamples(t, path); len(current) != 1 {
+		t.Errorf("Expected 1 sample in the current file, got %d", len(current))
+	}
+}
+
+// TestEnableAuditErrors verifies the audit requires a file and a positive
+// sampling rate.
+func TestEnableAuditErrors(t *testing.T) {
+	if err := NewHub().EnableAudit(10); !errors.Is(err, ErrAuditUnavailable) {
+		t.Errorf("Expected ErrAuditUnavailable, got %v", err)
+	}
+
+	hub := NewHub(WithAuditFile(filepath.Join(t.TempDir(), "audit.jsonl"), 0))
+	if err := hub.EnableAudit(0); !errors.Is(err, ErrInvalidSampleRate) {
+		t.Errorf("Expected ErrInvalidSampleRate, got %v", err)
+	}
+	if hub.AuditStatus().Enabled {
+		t.Error("Expected the audit to stay disabled")
+	}
+}
+
+// readAuditSamples decodes the samples of an audit file.
+func readAuditSamples(t *testing.T, path string) []AuditSample {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open audit file: %v", err)
+	}
+	defer file.Close()
+
+	var samples []AuditSample
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var sample AuditSample
+		if err := json.Unmarshal(scanner.Bytes(), &sample); err != nil {
+			t.Fatalf("Invalid audit line %q: %v", scanner.Text(), err)
+		}
+		samples = append(samples, sample)
+	}
+	return samples
+}
@@ -0,0 +1,152 @@
+package ws
+
+import (
+	"log"
+	"time"
+
+	"macro-analyst/pkg/clock"
+)
+
+const (
+	// DefaultBackpressureThreshold is the broadcast channel utilization (0-1)
+	// above which the Hub starts counting towards backpressure
+	DefaultBackpressureThreshold = 0.75
+
+	// DefaultBackpressureSustain is how long utilization must stay above the
+	// threshold before the Hub signals backpressure
+	DefaultBackpressureSustain = time.Second
+
+	// BackpressureCheckInterval is how often the Hub samples utilization
+	BackpressureCheckInterval = 100 * time.Millisecond
+
+	// DefaultBackpressureThrottleFactor multiplies the throttle interval of
+	// ingestors while backpressure is signaled
+	DefaultBackpressureThrottleFactor = 4
+)
+
+// Backpressure is sent to ingestors when the Hub enters or leaves backpressure.
+type Backpressure struct {
+	Active      bool    // Whether ingestors should slow down
+	Utilization float64 // Broadcast channel utilization (0-1) when the state changed
+}
+
+// WithBackpressure sets the broadcast channel utilization (0-1) that must be
+// exceeded for the sustain duration before the Hub signals backpressure.
+// A threshold of zero disables backpressure signaling.
+func WithBackpressure(threshold float64, sustain time.Duration) HubOption {
+	return func(h *Hub) {
+		h.backpressureThreshold = threshold
+		h.backpressureSustain = sustain
+	}
+}
+
+// WithBackpressureThrottleFactor sets how much the throttle interval is
+// stretched while the Hub signals backpressure.
+func WithBackpressureThrottleFactor(factor int) IngestorOption {
+	return func(i *Ingestor) {
+		i.backpressureFactor = factor
+	}
+}
+
+// SubscribeBackpressure returns a channel receiving backpressure state
+// changes. Only the latest state is buffered, so a slow reader never blocks
+// the Hub. The current state is delivered immediately if backpressure is active.
+func (h *Hub) SubscribeBackpressure() <-chan Backpressure {
+	signals := make(chan Backpressure, 1)
+
+	h.backpressureMu.Lock()
+	defer h.backpressureMu.Unlock()
+
+	h.backpressureSubs[signals] = true
+	if h.backpressure.Active {
+		signals <- h.backpressure
+	}
+	return signals
+}
+
+// UnsubscribeBackpressure stops delivering backpressure state changes to signals.
+func (h *Hub) UnsubscribeBackpressure(signals <-chan Backpressure) {
+	h.backpressureMu.Lock()
+	defer h.backpressureMu.Unlock()
+
+	for sub := range h.backpressureSubs {
+		if sub == signals {
+			delete(h.backpressureSubs, sub)
+		}
+	}
+}
+
+// UnderBackpressure reports whether the Hub currently signals backpressure.
+func (h *Hub) UnderBackpressure() bool {
+	h.backpressureMu.Lock()
+	defer h.backpressureMu.Unlock()
+	return h.backpressure.Active
+}
+
+// checkBackpressure signals backpressure once utilization stayed above the
+// threshold for the sustain duration, and clears it once utilization drops
+// back below the threshold. It is called from the Run loop.
+func (h *Hub) checkBackpressure(now time.Time) {
+	if h.backpressureThreshold <= 0 {
+		return
+	}
+
+	depth, capacity := h.QueueDepth()
+	utilization := float64(depth) / float64(capacity)
+
+	if utilization < h.backpressureThreshold {
+		h.pressureSince = time.Time{}
+		if h.UnderBackpressure() {
+			log.Printf("✓ Broadcast queue drained to %.0f%%, backpressure cleared", utilization*100)
+			h.metrics.backpressureActive.Set(0)
+			h.signalBackpressure(Backpressure{Active: false, Utilization: utilization})
+		}
+		return
+	}
+
+	if h.pressureSince.IsZero() {
+		h.pressureSince = now
+	}
+	if now.Sub(h.pressureSince) >= h.backpressureSustain && !h.UnderBackpressure() {
+		log.Printf("⚠ Broadcast queue at %.0f%% for %s, signaling backpressure",
+			utilization*100, now.Sub(h.pressureSince).Round(time.Millisecond))
+		h.metrics.backpressureSignals.Inc()
+		h.metrics.backpressureActive.Set(1)
+		h.signalBackpressure(Backpressure{Active: true, Utilization: utilization})
+	}
+}
+
+// signalBackpressure records the new state and delivers it to subscribers,
+// replacing any state they have not read yet.
+func (h *Hub) signalBackpressure(state Backpressure) {
+	h.backpressureMu.Lock()
+	defer h.backpressureMu.Unlock()
+
+	h.backpressure = state
+	for signals := range h.backpressureSubs {
+		select {
+		case <-signals:
+		default:
+		}
+		signals <- state
+	}
+}
+
+// applyBackpressure stretches or restores the throttle interval. While
+// backpressure is active, low-priority messages such as attribution notices
+// and data quality warnings are dropped as well.
+func (i *Ingestor) applyBackpressure(state Backpressure, throttleTicker clock.Ticker) {
+	i.underBackpressure.Store(state.Active)
+
+	interval := i.throttleInterval
+	if state.Active && i.backpressureFactor > 1 {
+		interval *= time.Duration(i.backpressureFactor)
+	}
+	throttleTicker.Reset(interval)
+
+	if state.Active {
+		log.Printf("⚠ Backpressure from Hub, throttling %s to %s", i.name, interval)
+	} else {
+		log.Printf("✓ Backpressure cleared, throttling %s back to %s", i.name, interval)
+	}
+}
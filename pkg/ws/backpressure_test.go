@@ -0,0 +1,168 @@
+package ws
+
+import (
+	"testing"
+	"time"
+
+	"macro-analyst/pkg/clock"
+)
+
+// fillBroadcast queues n messages on the hub's broadcast channel.
+func fillBroadcast(t *testing.T, hub *Hub, n int) {
+	t.Helper()
+	for range n {
+		if !hub.Publish([]byte(`{}`)) {
+			t.Fatal("Broadcast channel unexpectedly full")
+		}
+	}
+}
+
+// TestCheckBackpressureRequiresSustainedUtilization tests that a short
+// burst does not signal backpressure but sustained utilization does.
+func TestCheckBackpressureRequiresSustainedUtilization(t *testing.T) {
+	// Arrange
+	hub := NewHub(WithBackpressure(0.5, time.Second))
+	signals := hub.SubscribeBackpressure()
+	fillBroadcast(t, hub, BroadcastBufferSize*3/4)
+	start := time.Now()
+
+	// Act
+	hub.checkBackpressure(start)
+	hub.checkBackpressure(start.Add(500 * time.Millisecond))
+	early := hub.UnderBackpressure()
+	hub.checkBackpressure(start.Add(time.Second))
+
+	// Assert
+	if early {
+		t.Error("Expected no backpressure before the sustain duration")
+	}
+	select {
+	case state := <-signals:
+		if !state.Active {
+			t.Error("Expected an active backpressure signal")
+		}
+		if state.Utilization < 0.5 {
+			t.Errorf("Expected utilization >= 0.5, got %f", state.Utilization)
+		}
+	default:
+		t.Fatal("Expected a backpressure signal")
+	}
+}
+
+// TestCheckBackpressureClears tests that draining the queue clears backpressure.
+func TestCheckBackpressureClears(t *testing.T) {
+	// Arrange
+	hub := NewHub(WithBackpressure(0.5, 0))
+	signals := hub.SubscribeBackpressure()
+	fillBroadcast(t, hub, BroadcastBufferSize*3/4)
+	hub.checkBackpressure(time.Now())
+
+	// Act
+	for len(hub.broadcast) > 0 {
+		<-hub.broadcast
+	}
+	hub.checkBackpressure(time.Now())
+
+	// Assert
+	if hub.UnderBackpressure() {
+		t.Error("Expected backpressure to be cleared")
+	}
+	state := <-signals
+	if state.Active {
+		t.Error("Expected the latest signal to clear backpressure")
+	}
+}
+
+// TestCheckBackpressureDisabled tests that a zero threshold never signals.
+func TestCheckBackpressureDisabled(t *testing.T) {
+	// Arrange
+	hub := NewHub(WithBackpressure(0, 0))
+	fillBroadcast(t, hub, BroadcastBufferSize)
+
+	// Act
+	hub.checkBackpressure(time.Now())
+
+	// Assert
+	if hub.UnderBackpressure() {
+		t.Error("Expected backpressure to be disabled")
+	}
+}
+
+// TestSubscribeBackpressureDeliversCurrentState tests that late subscribers
+// learn about ongoing backpressure.
+func TestSubscribeBackpressureDeliversCurrentState(t *testing.T) {
+	// Arrange
+	hub := NewHub(WithBackpressure(0.5, 0))
+	fillBroadcast(t, hub, BroadcastBufferSize*3/4)
+	hub.checkBackpressure(time.Now())
+
+	// Act
+	signals := hub.SubscribeBackpressure()
+
+	// Assert
+	select {
+	case state := <-signals:
+		if !state.Active {
+			t.Error("Expected an active backpressure signal")
+		}
+	default:
+		t.Error("Expected the current backpressure state on subscribe")
+	}
+}
+
+// TestUnsubscribeBackpressure tests that unsubscribed channels receive no signals.
+func TestUnsubscribeBackpressure(t *testing.T) {
+	// Arrange
+	hub := NewHub(WithBackpressure(0.5, 0))
+	signals := hub.SubscribeBackpressure()
+
+	// Act
+	hub.UnsubscribeBackpressure(signals)
+	fillBroadcast(t, hub, BroadcastBufferSize*3/4)
+	hub.checkBackpressure(time.Now())
+
+	// Assert
+	select {
+	case <-signals:
+		t.Error("Expected no signal after unsubscribing")
+	default:
+	}
+}
+
+// TestApplyBackpressure tests that the ingestor slows down and drops
+// low-priority messages under backpressure.
+func TestApplyBackpressure(t *testing.T) {
+	// Arrange
+	hub := NewHub()
+	clk := clock.NewFake(time.Unix(0, 0))
+	ingestor := NewIngestor(HubSink(hub),
+		WithClock(clk),
+		WithThrottleInterval(10*time.Millisecond),
+		WithBackpressureThrottleFactor(4),
+	)
+	ticker := clk.NewTicker(ingestor.throttleInterval)
+	defer ticker.Stop()
+
+	// Act
+	ingestor.applyBackpressure(Backpressure{Active: true}, ticker)
+	ingestor.reportDataQuality(&DataQualityWarning{Type: "data_quality", Symbol: "BTCUSDT"})
+	clk.Advance(30 * time.Millisecond)
+	tickedEarly := len(ticker.C()) > 0
+	clk.Advance(10 * time.Millisecond)
+
+	// Assert
+	if !ingestor.underBackpressure.Load() {
+		t.Error("Expected the ingestor to be under backpressure")
+	}
+	if depth, _ := hub.QueueDepth(); depth != 0 {
+		t.Errorf("Expected the data quality warning to be dropped, got %d queued", depth)
+	}
+	if tickedEarly || len(ticker.C()) != 1 {
+		t.Error("Expected the throttle interval to be stretched to 40ms")
+	}
+
+	ingestor.applyBackpressure(Backpressure{Active: false}, ticker)
+	if ingestor.underBackpressure.Load() {
+		t.Error("Expected backpressure to be cleared")
+	}
+}
@@ -0,0 +1,78 @@
+package ws
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// DefaultCappedRefreshInterval is how often prices are sent to clients over
+// their hourly byte cap.
+const DefaultCappedRefreshInterval = 30 * time.Second
+
+// byteCapWindow is the period a client's byte cap applies to.
+const byteCapWindow = time.Hour
+
+// BandwidthNotice tells a client it exceeded its hourly byte cap and
+// receives sampled prices until ResetAt.
+type BandwidthNotice struct {
+	Type              string `json:"type"`      // Always "bandwidth_capped"
+	BytesSent         int64  `json:"bytesSent"` // This hour
+	ByteCap           int64  `json:"byteCap"`
+	RefreshIntervalMs int64  `json:"refreshIntervalMs"`
+	ResetAt           int64  `json:"resetAt"` // Unix ms when the full stream resumes
+}
+
+// BytesSent returns how many bytes were written to the client.
+func (c *Client) BytesSent() int64 {
+	return c.bytesSent.Load()
+}
+
+// cappedRefreshInterval returns the refresh interval of the client while it
+// is over its byte cap.
+func (c *Client) cappedRefreshInterval() time.Duration {
+	if c.CappedRefreshInterval > 0 {
+		return c.CappedRefreshInterval
+	}
+	return DefaultCappedRefreshInterval
+}
+
+// recordSent counts bytes written to the client at now. When they exceed
+// HourlyByteCap, prices are sampled until the hour is over and it returns
+// the notice to send; otherwise nil. It is called from WritePump only.
+func (c *Client) recordSent(bytes int, now time.Time) []byte {
+	c.bytesSent.Add(int64(bytes))
+	if c.HourlyByteCap <= 0 {
+		return nil
+	}
+
+	if c.hourStart.IsZero() || now.Sub(c.hourStart) >= byteCapWindow {
+		c.hourStart = now
+		c.hourBytes = 0
+
+		c.mu.Lock()
+		c.capped = false
+		c.mu.Unlock()
+	}
+	c.hourBytes += int64(bytes)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.capped || c.hourBytes <= c.HourlyByteCap {
+		return nil
+	}
+	c.capped = true
+
+	notice, err := json.Marshal(&BandwidthNotice{
+		Type:              "bandwidth_capped",
+		BytesSent:         c.hourBytes,
+		ByteCap:           c.HourlyByteCap,
+		RefreshIntervalMs: c.cappedRefreshInterval().Milliseconds(),
+		ResetAt:           c.hourStart.Add(byteCapWindow).UnixMilli(),
+	})
+	if err != nil {
+		log.Printf("Error marshaling bandwidth notice: %v", err)
+		return nil
+	}
+	return notice
+}
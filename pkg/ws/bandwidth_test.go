@@ -0,0 +1,74 @@
+package ws
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// TestRecordSentByteCap verifies that a client over its hourly byte cap is
+// notified once, sampled at the capped interval and restored the next hour.
+func TestRecordSentByteCap(t *testing.T) {
+	start := time.Date(2024, 7, 1, 14, 0, 0, 0, time.UTC)
+	client := &Client{HourlyByteCap: 100, RefreshInterval: time.Second}
+
+	steps := []struct {
+		name             string
+		bytes            int
+		elapsed          time.Duration
+		expectNotice     bool
+		expectedInterval time.Duration
+	}{
+		{"below cap", 60, 0, false, time.Second},
+		{"at cap", 40, time.Minute, false, time.Second},
+		{"over cap", 10, time.Minute, true, DefaultCappedRefreshInterval},
+		{"still capped", 500, 10 * time.Minute, false, DefaultCappedRefreshInterval},
+		{"next hour", 10, time.Hour, false, time.Second},
+	}
+
+	now := start
+	for _, step := range steps {
+		now = now.Add(step.elapsed)
+		notice := client.recordSent(step.bytes, now)
+		if (notice != nil) != step.expectNotice {
+			t.Fatalf("%s: expected notice %v, got %s", step.name, step.expectNotice, notice)
+		}
+		if got := client.refreshInterval(); got != step.expectedInterval {
+			t.Errorf("%s: expected a refresh interval of %v, got %v", step.name, step.expectedInterval, got)
+		}
+
+		if notice == nil {
+			continue
+		}
+		var decoded BandwidthNotice
+		if err := json.Unmarshal(notice, &decoded); err != nil {
+			t.Fatalf("%s: invalid notice: %v", step.name, err)
+		}
+		expected := BandwidthNotice{
+			Type:              "bandwidth_capped",
+			BytesSent:         110,
+			ByteCap:           100,
+			RefreshIntervalMs: DefaultCappedRefreshInterval.Milliseconds(),
+			ResetAt:           start.Add(time.Hour).UnixMilli(),
+		}
+		if decoded != expected {
+			t.Errorf("%s: expected %+v, got %+v", step.name, expected, decoded)
+		}
+	}
+
+	if got := client.BytesSent(); got != 620 {
+		t.Errorf("expected 620 bytes sent in total, got %d", got)
+	}
+}
+
+// TestRecordSentUncapped verifies that clients without a cap are only
+// counted.
+func TestRecordSentUncapped(t *testing.T) {
+	client := &Client{CappedRefreshInterval: time.Minute}
+	if notice := client.recordSent(1<<20, time.Now()); notice != nil {
+		t.Errorf("expected no notice without a cap, got %s", notice)
+	}
+	if client.refreshInterval() != 0 || client.BytesSent() != 1<<20 {
+		t.Errorf("expected an unthrottled client with 1 MiB sent, got %v and %d", client.refreshInterval(), client.BytesSent())
+	}
+}
@@ -0,0 +1,70 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+)
+
+// BinanceRESTTimeout bounds each Binance REST request
+const BinanceRESTTimeout = 10 * time.Second
+
+// WithBinanceCredentials sets the Binance API key and secret used for REST
+// requests. The key is sent with market data requests too, so they count
+// against the account's limits rather than the shared anonymous ones.
+// Neither value is ever logged.
+func WithBinanceCredentials(apiKey, secretKey string) IngestorOption {
+	return func(i *Ingestor) {
+		i.binanceAPIKey = apiKey
+		i.binanceSecretKey = secretKey
+	}
+}
+
+// HasBinanceCredentials reports whether Binance API credentials are configured.
+func (i *Ingestor) HasBinanceCredentials() bool {
+	return i.binanceAPIKey != ""
+}
+
+// CheckBinance verifies the Binance REST API is reachable and, if a key and
+// secret are configured, that Binance accepts them.
+func (i *Ingestor) CheckBinance(ctx context.Context) error {
+	client := i.restClient()
+	if err := client.NewPingService().Do(ctx); err != nil {
+		return fmt.Errorf("binance unreachable: %w", err)
+	}
+
+	if i.binanceAPIKey != "" && i.binanceSecretKey != "" {
+		if _, err := client.NewGetAccountService().Do(ctx); err != nil {
+			return fmt.Errorf("binance credentials rejected: %w", err)
+		}
+	}
+	return nil
+}
+
+// restClient creates a Binance REST client with the configured credentials.
+func (i *Ingestor) restClient() *binance.Client {
+	client := binance.NewClient(i.binanceAPIKey, i.binanceSecretKey)
+	client.HTTPClient = &http.Client{
+		Timeout:   BinanceRESTTimeout,
+		Transport: &apiKeyTransport{apiKey: i.binanceAPIKey, base: http.DefaultTransport},
+	}
+	return client
+}
+
+// apiKeyTransport adds the Binance API key header to every request. The
+// SDK only sends it to account endpoints on its own.
+type apiKeyTransport struct {
+	apiKey string
+	base   http.RoundTripper
+}
+
+func (t *apiKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.apiKey != "" && req.Header.Get("X-MBX-APIKEY") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("X-MBX-APIKEY", t.apiKey)
+	}
+	return t.base.RoundTrip(req)
+}
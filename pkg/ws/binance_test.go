@@ -0,0 +1,45 @@
+package ws
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestAPIKeyTransport verifies the API key header is added to requests.
+func TestAPIKeyTransport(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("X-MBX-APIKEY")
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &apiKeyTransport{apiKey: "key", base: http.DefaultTransport}}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if received != "key" {
+		t.Errorf("Expected API key header, got %q", received)
+	}
+}
+
+// TestRestClientCredentials verifies credentials reach the REST client.
+func TestRestClientCredentials(t *testing.T) {
+	anonymous := NewIngestor(HubSink(NewHub()))
+	if anonymous.HasBinanceCredentials() {
+		t.Error("Expected no credentials by default")
+	}
+
+	ingestor := NewIngestor(HubSink(NewHub()), WithBinanceCredentials("key", "secret"))
+	if !ingestor.HasBinanceCredentials() {
+		t.Error("Expected credentials to be configured")
+	}
+
+	client := ingestor.restClient()
+	if client.APIKey != "key" || client.SecretKey != "secret" {
+		t.Error("Expected REST client to use the configured credentials")
+	}
+}
@@ -0,0 +1,177 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+
+	"github.com/adshao/go-binance/v2"
+)
+
+// CandleIntervals are the kline intervals the candle mode can stream
+var CandleIntervals = []string{"1m", "3m", "5m", "15m", "30m", "1h", "2h", "4h", "1d"}
+
+// DefaultCandleIntervals are streamed when no intervals are configured
+var DefaultCandleIntervals = []string{"1m", "5m", "1h"}
+
+// CandleUpdate is an OHLCV candle of one symbol and interval. Binance sends
+// the open candle about every two seconds while it forms; the last update
+// of each candle has Closed set.
+type CandleUpdate struct {
+	Type      string  `json:"type"` // Always "candle"
+	Symbol    string  `json:"symbol"`
+	Interval  string  `json:"interval"`  // e.g. "1m"
+	OpenTime  int64   `json:"openTime"`  // Unix ms
+	CloseTime int64   `json:"closeTime"` // Unix ms
+	Open      float64 `json:"open"`
+	High      float64 `json:"high"`
+	Low       float64 `json:"low"`
+	Close     float64 `json:"close"`
+	Volume    float64 `json:"volume"` // Base asset volume
+	Trades    int64   `json:"trades"`
+	Closed    bool    `json:"closed"`    // Final update of the candle
+	EventTime int64   `json:"eventTime"` // Exchange event time (Unix ms)
+}
+
+// WithCandles switches the ingestor to candle mode: instead of ticker
+// updates it streams Binance klines of the given intervals and broadcasts
+// each update as a CandleUpdate. Candles are not throttled, so closed
+// candles always reach clients.
+func WithCandles(intervals []string) IngestorOption {
+	return func(i *Ingestor) {
+		if len(intervals) > 0 {
+			i.stream = &candleStream{intervals: intervals}
+		}
+	}
+}
+
+// candleStream streams the klines of the tracked symbols.
+type candleStream struct {
+	intervals []string
+}
+
+// ParseCandleIntervals validates kline intervals such as "1m" or "1h".
+func ParseCandleIntervals(intervals []string) ([]string, error) {
+	for _, interval := range intervals {
+		if !slices.Contains(CandleIntervals, interval) {
+			return nil, fmt.Errorf("invalid candle interval %q (expected one of %v)", interval, CandleIntervals)
+		}
+	}
+	return intervals, nil
+}
+
+// CandleMode reports whether the ingestor streams candles instead of
+// ticker updates.
+func (i *Ingestor) CandleMode() bool {
+	_, ok := i.stream.(*candleStream)
+	return ok
+}
+
+// serve connects to the Binance kline streams of all symbols and intervals
+// in one connection and broadcasts candle updates, re-establishing the
+// connection whenever symbols are added or removed.
+func (s *candleStream) serve(i *Ingestor) {
+	if len(i.GetSymbols()) == 0 {
+		log.Println("No symbols to track")
+		return
+	}
+
+	klineHandler := i.createKlineHandler()
+	errHandler := i.createErrorHandler()
+
+	i.runStream(func(symbols []string) (chan struct{}, chan struct{}, error) {
+		streams := make(map[string][]string, len(symbols))
+		for _, symbol := range symbols {
+			streams[symbol] = s.intervals
+		}
+		return binance.WsCombinedKlineServeMultiInterval(streams, klineHandler, errHandler)
+	}, nil)
+}
+
+func (s *candleStream) replays(kind string) bool {
+	return kind == "candle"
+}
+
+// createKlineHandler creates a handler broadcasting Binance kline events.
+func (i *Ingestor) createKlineHandler() func(*binance.WsKlineEvent) {
+	return func(event *binance.WsKlineEvent) {
+		i.markEventReceived(i.clock.Now())
+
+		candle, err := convertKlineEvent(event)
+		i.recordEvent(event.Symbol, err)
+		if err != nil {
+			return
+		}
+
+		i.publishCandle(candle)
+	}
+}
+
+// publishCandle broadcasts a candle update unless another feed is active.
+func (i *Ingestor) publishCandle(candle *CandleUpdate) {
+	if !i.audience.IsActiveSource(i.name) {
+		return
+	}
+
+	data, err := json.Marshal(candle)
+	if err != nil {
+		log.Printf("Error marshaling candle: %v", err)
+		return
+	}
+	if !i.publish(SymbolTopic(TopicCandles, candle.Symbol), data) {
+		return
+	}
+
+	i.mu.Lock()
+	if symbol := i.findSymbol(candle.Symbol); symbol != nil {
+		symbol.UpdatesBroadcast++
+	}
+	i.mu.Unlock()
+}
+
+// convertKlineEvent converts a Binance kline event to our CandleUpdate
+// format. It returns an error if a price or the volume fails to parse or a
+// price is not positive.
+func convertKlineEvent(event *binance.WsKlineEvent) (*CandleUpdate, error) {
+	kline := event.Kline
+	candle := &CandleUpdate{
+		Type:      "candle",
+		Symbol:    event.Symbol,
+		Interval:  kline.Interval,
+		OpenTime:  kline.StartTime,
+		CloseTime: kline.EndTime,
+		Trades:    kline.TradeNum,
+		Closed:    kline.IsFinal,
+		EventTime: event.Time,
+	}
+
+	for _, field := range []struct {
+		name  string
+		value string
+		dest  *float64
+	}{
+		{"open", kline.Open, &candle.Open},
+		{"high", kline.High, &candle.High},
+		{"low", kline.Low, &candle.Low},
+		{"close", kline.Close, &candle.Close},
+	} {
+		price, err := strconv.ParseFloat(field.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s price %q for %s: %w", field.name, field.value, event.Symbol, err)
+		}
+		if price <= 0 {
+			return nil, fmt.Errorf("non-positive %s price %q for %s", field.name, field.value, event.Symbol)
+		}
+		*field.dest = price
+	}
+
+	volume, err := strconv.ParseFloat(kline.Volume, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid volume %q for %s: %w", kline.Volume, event.Symbol, err)
+	}
+	candle.Volume = volume
+
+	return candle, nil
+}
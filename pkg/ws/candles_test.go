@@ -0,0 +1,114 @@
+package ws
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+)
+
+// TestConvertKlineEvent verifies kline events are converted to candles.
+func TestConvertKlineEvent(t *testing.T) {
+	event := &binance.WsKlineEvent{
+		Symbol: "BTCUSDT",
+		Time:   1708424625120,
+		Kline: binance.WsKline{
+			StartTime: 1708424580000,
+			EndTime:   1708424639999,
+			Interval:  "1m",
+			Open:      "51234.50",
+			High:      "51250.00",
+			Low:       "51220.10",
+			Close:     "51240.20",
+			Volume:    "12.34",
+			TradeNum:  321,
+			IsFinal:   true,
+		},
+	}
+
+	candle, err := convertKlineEvent(event)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := CandleUpdate{
+		Type: "candle", Symbol: "BTCUSDT", Interval: "1m",
+		OpenTime: 1708424580000, CloseTime: 1708424639999,
+		Open: 51234.5, High: 51250, Low: 51220.1, Close: 51240.2, Volume: 12.34,
+		Trades: 321, Closed: true, EventTime: 1708424625120,
+	}
+	if *candle != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *candle)
+	}
+}
+
+// TestConvertKlineEventInvalid verifies candles with unparsable or
+// non-positive prices are rejected.
+func TestConvertKlineEventInvalid(t *testing.T) {
+	valid := binance.WsKline{Interval: "1m", Open: "1", High: "1", Low: "1", Close: "1", Volume: "0"}
+
+	tests := []struct {
+		name   string
+		modify func(*binance.WsKline)
+	}{
+		{"invalid open", func(k *binance.WsKline) { k.Open = "invalid" }},
+		{"zero low", func(k *binance.WsKline) { k.Low = "0" }},
+		{"negative close", func(k *binance.WsKline) { k.Close = "-1" }},
+		{"invalid volume", func(k *binance.WsKline) { k.Volume = "" }},
+	}
+
+	for _, tt := range tests {
+		kline := valid
+		tt.modify(&kline)
+
+		if candle, err := convertKlineEvent(&binance.WsKlineEvent{Symbol: "BTCUSDT", Kline: kline}); err == nil {
+			t.Errorf("%s: expected an error, got %+v", tt.name, candle)
+		}
+	}
+}
+
+// TestParseCandleIntervals verifies only Binance kline intervals are accepted.
+func TestParseCandleIntervals(t *testing.T) {
+	if _, err := ParseCandleIntervals([]string{"1m", "5m", "1h"}); err != nil {
+		t.Errorf("Expected valid intervals, got: %v", err)
+	}
+	if _, err := ParseCandleIntervals([]string{"1m", "7m"}); err == nil {
+		t.Error("Expected an error for an unsupported interval")
+	}
+}
+
+// TestKlineHandlerPublishesCandles verifies kline events are broadcast as
+// candle updates and counted per symbol.
+func TestKlineHandlerPublishesCandles(t *testing.T) {
+	// Arrange
+	hub := NewHub()
+	ingestor := NewIngestor(HubSink(hub), WithSymbols([]string{"BTCUSDT"}), WithCandles([]string{"1m"}))
+	handler := ingestor.createKlineHandler()
+
+	// Act
+	handler(&binance.WsKlineEvent{Symbol: "BTCUSDT", Kline: binance.WsKline{
+		Interval: "1m", Open: "100", High: "110", Low: "90", Close: "105", Volume: "2",
+	}})
+
+	// Assert
+	select {
+	case queued := <-hub.broadcast:
+		var candle CandleUpdate
+		if err := json.Unmarshal(queued.data, &candle); err != nil {
+			t.Fatalf("Failed to decode candle: %v", err)
+		}
+		if candle.Type != "candle" || candle.Symbol != "BTCUSDT" || candle.Close != 105 {
+			t.Errorf("Unexpected candle: %+v", candle)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Timeout waiting for candle")
+	}
+
+	if !ingestor.CandleMode() {
+		t.Error("Expected candle mode")
+	}
+	if stats := ingestor.Stats(); stats[0].UpdatesBroadcast != 1 || stats[0].EventsReceived != 1 {
+		t.Errorf("Expected 1 event and 1 broadcast, got %+v", stats[0])
+	}
+}
@@ -0,0 +1,232 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+)
+
+// SymbolCatalogTTL is how long the catalog of symbols trading on Binance is
+// cached before AddSymbol reloads it
+const SymbolCatalogTTL = time.Hour
+
+var (
+	// ErrInvalidSymbol is returned for names that are not trading symbols
+	// such as BTCUSDT.
+	ErrInvalidSymbol = errors.New("invalid symbol")
+
+	// ErrUnknownSymbol is returned for symbols Binance does not trade.
+	ErrUnknownSymbol = errors.New("symbol not trading on Binance")
+
+	// ErrSymbolCatalogUnavailable is returned when symbols cannot be
+	// validated because the Binance catalog could not be loaded.
+	ErrSymbolCatalogUnavailable = errors.New("binance symbol catalog unavailable")
+)
+
+// SymbolLister returns the symbols currently trading on the exchange.
+type SymbolLister func(ctx context.Context) ([]string, error)
+
+// WithSymbolLister replaces the Binance exchangeInfo lookup new symbols are
+// validated against.
+func WithSymbolLister(list SymbolLister) IngestorOption {
+	return func(i *Ingestor) {
+		i.listSymbols = list
+	}
+}
+
+// symbolCatalog caches the symbols trading on Binance. mu serializes
+// reloads; symbols may be read without waiting for one.
+type symbolCatalog struct {
+	mu        sync.Mutex
+	symbols   atomic.Pointer[map[string]bool]
+	fetchedAt time.Time
+}
+
+// DefaultQuoteAsset quotes symbols given as a bare asset such as "btc", and
+// stands in for USD, which Binance does not quote
+const DefaultQuoteAsset = "USDT"
+
+// quoteAssets are the quote assets recognized at the end of a symbol
+var quoteAssets = []string{"USDT", "USDC", "FDUSD", "BUSD", "TUSD", "BTC", "ETH", "BNB", "EUR", "TRY"}
+
+// NormalizeSymbol returns the Binance symbol for a symbol or an alias,
+// ignoring case: "btc", "BTC-USD", "btcusdt" and "BTC/USDT" all become
+// BTCUSDT. Use ResolveSymbol or ValidateSymbol where the symbols of the
+// feed or exchange are known, as they also resolve assets ending in a
+// quote asset, such as WBTC.
+func NormalizeSymbol(name string) string {
+	return symbolCandidates(name)[0]
+}
+
+// symbolCandidates returns the symbols an alias may stand for, most likely
+// first.
+func symbolCandidates(name string) []string {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '-' || r == '/' || r == '_'
+	})
+
+	switch {
+	case len(parts) == 2 && parts[1] == "USD":
+		return []string{parts[0] + DefaultQuoteAsset}
+	case len(parts) == 2:
+		return []string{parts[0] + parts[1]}
+	case len(parts) != 1:
+		return []string{name}
+	case hasQuoteAsset(name):
+		return []string{name, name + DefaultQuoteAsset}
+	case strings.HasSuffix(name, "USD"):
+		return []string{name + "T", name}
+	default:
+		return []string{name + DefaultQuoteAsset, name}
+	}
+}
+
+// hasQuoteAsset reports whether a symbol ends in a quote asset, following
+// a base asset.
+func hasQuoteAsset(symbol string) bool {
+	for _, quote := range quoteAssets {
+		if len(symbol) > len(quote) && strings.HasSuffix(symbol, quote) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveSymbol returns the symbol the ingestor tracks for a symbol or an
+// alias, or NormalizeSymbol's guess if it tracks none of its candidates.
+func (i *Ingestor) ResolveSymbol(name string) string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	for _, candidate := range symbolCandidates(name) {
+		if i.findSymbol(candidate) != nil {
+			return candidate
+		}
+	}
+	return NormalizeSymbol(name)
+}
+
+// ValidateSymbol resolves a symbol or an alias against the symbols Binance
+// trades. It returns ErrInvalidSymbol, ErrUnknownSymbol or
+// ErrSymbolCatalogUnavailable.
+func (i *Ingestor) ValidateSymbol(ctx context.Context, name string) (string, error) {
+	candidates := symbolCandidates(name)
+	if !ValidSymbol(candidates[0]) {
+		return "", fmt.Errorf("%w %q", ErrInvalidSymbol, name)
+	}
+
+	listed, err := i.tradingSymbols(ctx)
+	if err != nil {
+		return "", err
+	}
+	return matchSymbol(listed, candidates)
+}
+
+// LookupSymbol is ValidateSymbol against the catalog as last loaded, for
+// callers that must not wait for Binance, such as WebSocket commands. It
+// returns ErrSymbolCatalogUnavailable until the catalog has been loaded.
+func (i *Ingestor) LookupSymbol(name string) (string, error) {
+	candidates := symbolCandidates(name)
+	if !ValidSymbol(candidates[0]) {
+		return "", fmt.Errorf("%w %q", ErrInvalidSymbol, name)
+	}
+
+	listed := i.catalog.symbols.Load()
+	if listed == nil {
+		return "", ErrSymbolCatalogUnavailable
+	}
+	return matchSymbol(*listed, candidates)
+}
+
+// matchSymbol returns the first of the candidates of a symbol that is
+// listed, or ErrUnknownSymbol.
+func matchSymbol(listed map[string]bool, candidates []string) (string, error) {
+	for _, candidate := range candidates {
+		if listed[candidate] {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnknownSymbol, candidates[0])
+}
+
+// LoadSymbolCatalog loads the catalog of symbols trading on Binance, unless
+// it is cached, so that subscribe commands are validated from the start. It
+// returns the number of symbols.
+func (i *Ingestor) LoadSymbolCatalog(ctx context.Context) (int, error) {
+	listed, err := i.tradingSymbols(ctx)
+	return len(listed), err
+}
+
+// AvailableSymbols returns the symbols trading on Binance, sorted, or
+// ErrSymbolCatalogUnavailable.
+func (i *Ingestor) AvailableSymbols(ctx context.Context) ([]string, error) {
+	listed, err := i.tradingSymbols(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make([]string, 0, len(listed))
+	for symbol := range listed {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols, nil
+}
+
+// tradingSymbols returns the cached catalog, reloading it once it expired.
+// A failed reload keeps using the expired catalog, as listings rarely
+// change within hours.
+func (i *Ingestor) tradingSymbols(ctx context.Context) (map[string]bool, error) {
+	catalog := &i.catalog
+	catalog.mu.Lock()
+	defer catalog.mu.Unlock()
+
+	now := i.clock.Now()
+	cached := catalog.symbols.Load()
+	if cached != nil && now.Sub(catalog.fetchedAt) < SymbolCatalogTTL {
+		return *cached, nil
+	}
+
+	symbols, err := i.listSymbols(ctx)
+	if err != nil {
+		if cached != nil {
+			log.Printf("⚠ Failed to reload Binance symbols, using catalog from %s: %v",
+				catalog.fetchedAt.Format(time.RFC3339), err)
+			return *cached, nil
+		}
+		return nil, fmt.Errorf("%w: %v", ErrSymbolCatalogUnavailable, err)
+	}
+
+	listed := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		listed[symbol] = true
+	}
+	catalog.symbols.Store(&listed)
+	catalog.fetchedAt = now
+	return listed, nil
+}
+
+// listBinanceSymbols reads the symbols trading on Binance from exchangeInfo.
+func (i *Ingestor) listBinanceSymbols(ctx context.Context) ([]string, error) {
+	info, err := i.restClient().NewExchangeInfoService().Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch exchange info: %w", err)
+	}
+
+	symbols := make([]string, 0, len(info.Symbols))
+	for _, symbol := range info.Symbols {
+		if symbol.Status == string(binance.SymbolStatusTypeTrading) {
+			symbols = append(symbols, symbol.Symbol)
+		}
+	}
+	return symbols, nil
+}
@@ -0,0 +1,201 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"macro-analyst/pkg/clock"
+)
+
+// listing returns a SymbolLister of fixed symbols for tests.
+func listing(symbols ...string) SymbolLister {
+	return func(ctx context.Context) ([]string, error) {
+		return symbols, nil
+	}
+}
+
+// TestValidateSymbol verifies symbols are normalized and checked against
+// the catalog.
+func TestValidateSymbol(t *testing.T) {
+	ingestor := NewIngestor(HubSink(NewHub()), WithSymbolLister(listing("BTCUSDT", "DOGEUSDT", "WBTCUSDT")))
+
+	tests := []struct {
+		name     string
+		symbol   string
+		expected string
+		err      error
+	}{
+		{"listed", "DOGEUSDT", "DOGEUSDT", nil},
+		{"lower case", " dogeusdt ", "DOGEUSDT", nil},
+		{"asset", "doge", "DOGEUSDT", nil},
+		{"asset ending in a quote asset", "wbtc", "WBTCUSDT", nil},
+		{"pair", "DOGE-USD", "DOGEUSDT", nil},
+		{"malformed", "DOGE.USDT", "", ErrInvalidSymbol},
+		{"empty", "", "", ErrInvalidSymbol},
+		{"not listed", "TESTUSDT", "", ErrUnknownSymbol},
+	}
+
+	for _, tt := range tests {
+		symbol, err := ingestor.ValidateSymbol(context.Background(), tt.symbol)
+		if symbol != tt.expected || !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %q and %v, got %q and %v", tt.name, tt.expected, tt.err, symbol, err)
+		}
+	}
+}
+
+// TestLookupSymbol verifies symbols are checked against the catalog as last
+// loaded, which is unavailable until it loads.
+func TestLookupSymbol(t *testing.T) {
+	ingestor := NewIngestor(HubSink(NewHub()), WithSymbolLister(listing("BTCUSDT", "DOGEUSDT")))
+
+	if _, err := ingestor.LookupSymbol("DOGEUSDT"); !errors.Is(err, ErrSymbolCatalogUnavailable) {
+		t.Errorf("Expected ErrSymbolCatalogUnavailable before loading, got %v", err)
+	}
+	if count, err := ingestor.LoadSymbolCatalog(context.Background()); count != 2 || err != nil {
+		t.Fatalf("Expected 2 symbols loaded, got %d and %v", count, err)
+	}
+
+	tests := []struct {
+		symbol   string
+		expected string
+		err      error
+	}{
+		{"doge", "DOGEUSDT", nil},
+		{"TESTUSDT", "", ErrUnknownSymbol},
+		{"DOGE.USDT", "", ErrInvalidSymbol},
+	}
+	for _, tt := range tests {
+		symbol, err := ingestor.LookupSymbol(tt.symbol)
+		if symbol != tt.expected || !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %q and %v, got %q and %v", tt.symbol, tt.expected, tt.err, symbol, err)
+		}
+	}
+}
+
+// TestAvailableSymbols verifies the catalog is listed sorted.
+func TestAvailableSymbols(t *testing.T) {
+	ingestor := NewIngestor(HubSink(NewHub()), WithSymbolLister(listing("ETHUSDT", "BTCUSDT", "ETHBTC")))
+
+	symbols, err := ingestor.AvailableSymbols(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to list symbols: %v", err)
+	}
+	if got := strings.Join(symbols, ","); got != "BTCUSDT,ETHBTC,ETHUSDT" {
+		t.Errorf("Expected sorted symbols, got %s", got)
+	}
+}
+
+// TestNormalizeSymbol verifies aliases resolve to Binance symbols.
+func TestNormalizeSymbol(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"BTCUSDT", "BTCUSDT"},
+		{"btcusdt", "BTCUSDT"},
+		{"btc", "BTCUSDT"},
+		{" BTC ", "BTCUSDT"},
+		{"BTC-USD", "BTCUSDT"},
+		{"btc/usdt", "BTCUSDT"},
+		{"ETH_BTC", "ETHBTC"},
+		{"ETHBTC", "ETHBTC"},
+		{"BTCUSD", "BTCUSDT"},
+		{"BTCFDUSD", "BTCFDUSD"},
+	}
+
+	for _, tt := range tests {
+		if symbol := NormalizeSymbol(tt.name); symbol != tt.expected {
+			t.Errorf("%q: expected %s, got %s", tt.name, tt.expected, symbol)
+		}
+	}
+}
+
+// TestResolveSymbol verifies aliases prefer the symbols the ingestor tracks.
+func TestResolveSymbol(t *testing.T) {
+	ingestor := NewIngestor(HubSink(NewHub()), WithSymbols([]string{"BTCUSDT", "WBTCUSDT", "ETHBTC"}))
+
+	for name, expected := range map[string]string{
+		"btc": "BTCUSDT", "wbtc": "WBTCUSDT", "ethbtc": "ETHBTC", "sol": "SOLUSDT",
+	} {
+		if symbol := ingestor.ResolveSymbol(name); symbol != expected {
+			t.Errorf("%q: expected %s, got %s", name, expected, symbol)
+		}
+	}
+}
+
+// TestSymbolCatalogCache verifies the catalog is loaded once per TTL, and
+// an expired catalog is kept when reloading fails.
+func TestSymbolCatalogCache(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 2, 20, 12, 0, 0, 0, time.UTC))
+	calls := 0
+	var listErr error
+	ingestor := NewIngestor(HubSink(NewHub()), WithClock(fake), WithSymbolLister(func(ctx context.Context) ([]string, error) {
+		calls++
+		if listErr != nil {
+			return nil, listErr
+		}
+		if calls == 1 {
+			return []string{"DOGEUSDT"}, nil
+		}
+		return []string{"DOGEUSDT", "NEWUSDT"}, nil
+	}))
+
+	ingestor.ValidateSymbol(context.Background(), "DOGEUSDT")
+	if _, err := ingestor.ValidateSymbol(context.Background(), "NEWUSDT"); !errors.Is(err, ErrUnknownSymbol) || calls != 1 {
+		t.Errorf("Expected the cached catalog without NEWUSDT, got %v after %d loads", err, calls)
+	}
+
+	fake.Advance(SymbolCatalogTTL)
+	if _, err := ingestor.ValidateSymbol(context.Background(), "NEWUSDT"); err != nil || calls != 2 {
+		t.Errorf("Expected the reloaded catalog to list NEWUSDT, got %v after %d loads", err, calls)
+	}
+
+	fake.Advance(SymbolCatalogTTL)
+	listErr = errors.New("binance unreachable")
+	if _, err := ingestor.ValidateSymbol(context.Background(), "NEWUSDT"); err != nil {
+		t.Errorf("Expected the expired catalog to be kept, got %v", err)
+	}
+}
+
+// TestSymbolCatalogUnavailable verifies symbols are rejected when the
+// catalog never loaded.
+func TestSymbolCatalogUnavailable(t *testing.T) {
+	ingestor := NewIngestor(HubSink(NewHub()), WithSymbolLister(func(ctx context.Context) ([]string, error) {
+		return nil, errors.New("binance unreachable")
+	}))
+
+	added, err := ingestor.AddSymbol(context.Background(), "DOGEUSDT")
+	if added || !errors.Is(err, ErrSymbolCatalogUnavailable) {
+		t.Errorf("Expected ErrSymbolCatalogUnavailable, got %v, %v", added, err)
+	}
+}
+
+// TestAddSymbolConcurrent verifies concurrent adds of the same symbol in
+// different cases track it once.
+func TestAddSymbolConcurrent(t *testing.T) {
+	ingestor := NewIngestor(HubSink(NewHub()), WithSymbols(nil), WithSymbolLister(listing("DOGEUSDT")))
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	added := 0
+	for _, name := range []string{"DOGEUSDT", "dogeusdt", "DogeUSDT", "DOGEUSDT"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ok, _ := ingestor.AddSymbol(context.Background(), name); ok {
+				mu.Lock()
+				added++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if symbols := ingestor.GetSymbols(); added != 1 || len(symbols) != 1 || symbols[0] != "DOGEUSDT" {
+		t.Errorf("Expected DOGEUSDT to be added once, got %d adds and %v", added, symbols)
+	}
+}
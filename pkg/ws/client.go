@@ -0,0 +1,418 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"macro-analyst/pkg/clock"
+	"macro-analyst/pkg/usage"
+
+	"github.com/gofiber/contrib/websocket"
+)
+
+const (
+	// DefaultWriteTimeout bounds each write to a client connection
+	DefaultWriteTimeout = 10 * time.Second
+
+	// DefaultMaxMessageAge is the longest a price update may wait in a
+	// client's send buffer before it is skipped at write time
+	DefaultMaxMessageAge = 5 * time.Second
+)
+
+// WithWriteTimeout sets the deadline of each write to a client connection.
+// A client that does not accept a write in time is disconnected. Zero
+// disables the deadline.
+func WithWriteTimeout(timeout time.Duration) HubOption {
+	return func(h *Hub) {
+		h.writeTimeout = timeout
+	}
+}
+
+// WithMaxMessageAge sets how long price updates may wait in a client's send
+// buffer. Older ones are skipped at write time, so a briefly stalled client
+// resumes with fresh prices instead of replaying its backlog. Other
+// messages, such as command responses, are always written. Zero disables
+// the check.
+func WithMaxMessageAge(age time.Duration) HubOption {
+	return func(h *Hub) {
+		h.maxMessageAge = age
+	}
+}
+
+// Client represents a single WebSocket connection from a client.
+// It holds the connection, a reference to the Hub, and a buffered send channel.
+type Client struct {
+	// Hub is the central message broker this client is connected to
+	Hub *Hub
+
+	// Conn is the underlying WebSocket connection
+	Conn *websocket.Conn
+
+	// Send is a buffered channel of outbound messages
+	Send chan []byte
+
+	// Encoding is the wire format negotiated via Sec-WebSocket-Protocol
+	Encoding Encoding
+
+	// Topics are the topic patterns the client is subscribed to when it
+	// registers, every topic when empty. Clients change them with
+	// subscribe_topics and unsubscribe_topics commands.
+	Topics []string
+
+	// Symbols, if set, restricts the price updates delivered to the client
+	// to these symbols, e.g. for viewers of a shared dashboard
+	Symbols map[string]bool
+
+	// RefreshInterval, if set, limits the client to one price update per
+	// interval carrying the latest price of each symbol, e.g. for embedded
+	// widgets or mobile clients saving bandwidth. Clients change it with set_throttle commands.
+	RefreshInterval time.Duration
+
+	// MinRefreshInterval is the shortest RefreshInterval set_throttle
+	// commands may set, e.g. the refresh rate of an embedded widget
+	MinRefreshInterval time.Duration
+
+	// Decimals, if positive, rounds the prices and changes of throttled
+	// clients to this many decimal places, e.g. for screen readers
+	Decimals int
+
+	// UserID is the subject of the JWT the client authenticated with,
+	// empty for anonymous clients
+	UserID string
+
+	// RemoteIP, UserAgent and Stream describe the connection in the admin
+	// client listing, Stream naming the endpoint, e.g. "prices" or "embed"
+	RemoteIP  string
+	UserAgent string
+	Stream    string
+
+	// Numbers is the format of the prices in price updates, JSON numbers
+	// when empty
+	Numbers NumberFormat
+
+	// Preferences, if set, lets clients with a UserID save their stream
+	// settings, which LoadPreferences applies to their next connections
+	Preferences *PreferenceStore
+
+	// Alerts, if set, lets the client register price alerts with set_alert
+	// commands, sent to it as alert messages when they trigger
+	Alerts *AlertBook
+
+	// Catalog, if set, returns the feed whose catalog of symbols trading on
+	// Binance the symbols of subscribe commands are checked against, so a
+	// typo is rejected instead of subscribing to nothing. It is called per
+	// command so the check follows feed switchovers.
+	Catalog func() *Ingestor
+
+	// Currency, if set, is the currency of the prices in the client's price
+	// updates, converted with Rates, e.g. "EUR"
+	Currency string
+	Rates    *FXRates
+
+	// Usage, if set, records delivered messages under UsageKey
+	Usage    *usage.Tracker
+	UsageKey string
+
+	// HourlyByteCap, if positive, is how many bytes the client receives per
+	// hour before its prices are sampled every CappedRefreshInterval
+	// (DefaultCappedRefreshInterval when zero) for the rest of the hour,
+	// e.g. for free embeds
+	HourlyByteCap         int64
+	CappedRefreshInterval time.Duration
+
+	// mu protects the settings clients change with commands, which the Hub
+	// and WritePump read concurrently: RefreshInterval and the subscription
+	mu             sync.RWMutex
+	subscribed     map[string]bool // Symbols subscribed to, nil for every symbol
+	excluded       map[string]bool // Symbols unsubscribed from while subscribed to every symbol
+	fields         map[string]bool // Price update fields sent besides the symbol, nil for every field
+	projection     string          // Sorted fields, identifying clients that share a rendering
+	topicsNarrowed bool            // A subscribe_topics replaced the default of every topic
+	capped         bool            // Over HourlyByteCap for the current hour
+	connAlertOwner string          // Owner of the alerts of an anonymous client, once it set one
+
+	// scope identifies the symbols the client receives, so clients of the
+	// same scope share a filtered rendering. It is computed on first use
+	// and reset when the subscription changes.
+	scope atomic.Pointer[string]
+
+	// backlog holds the prices the Hub could not queue under the coalesce
+	// slow client policy, owned by the Hub's Run loop
+	backlog *coalescer
+
+	// queued holds the messages in Send with their enqueue times, oldest
+	// first, for the write-time age check. queueMu keeps it in step with
+	// Send.
+	queueMu sync.Mutex
+	queued  []queuedFrame
+
+	// bytesSent counts the bytes written; hourStart and hourBytes the ones
+	// of the current byte cap hour, owned by WritePump
+	bytesSent atomic.Int64
+	hourStart time.Time
+	hourBytes int64
+
+	// id and connectedAt are set by the Hub on registration; messagesSent
+	// and messagesDropped count the messages written to and dropped for
+	// the client
+	id              uint64
+	connectedAt     time.Time
+	messagesSent    atomic.Int64
+	messagesDropped atomic.Int64
+
+	// lastSeen is when the last frame of the client arrived, in Unix
+	// nanoseconds, for the idle timeout
+	lastSeen atomic.Int64
+
+	// timedOut is set when ReadPump hit its read deadline, so the Run loop
+	// reaps the client it unregisters
+	timedOut atomic.Bool
+}
+
+// WritePump pumps messages from the Hub to the WebSocket connection.
+// A goroutine running WritePump is started for each connection. The
+// application ensures that there is at most one writer to a connection by
+// executing all writes from this goroutine.
+//
+// While the client has a RefreshInterval, price updates are written at most
+// once per interval, each symbol with its latest price rounded to Decimals,
+// and other messages as they arrive. Coalesced updates carry only the
+// client's fields.
+func (c *Client) WritePump() {
+	var (
+		clk      = c.clock()
+		prices   = newCoalescer(c.Decimals, c.Numbers)
+		interval time.Duration
+		ticker   clock.Ticker
+		tick     <-chan time.Time
+		ping     <-chan time.Time
+	)
+	defer func() {
+		if ticker != nil {
+			ticker.Stop()
+		}
+		c.Conn.Close()
+	}()
+
+	// Ping the client so it proves it is alive within the idle timeout
+	if pingInterval := c.pingInterval(); pingInterval > 0 {
+		pinger := clk.NewTicker(pingInterval)
+		defer pinger.Stop()
+		ping = pinger.C()
+	}
+
+	for {
+		// Follow refresh interval changes made with set_throttle
+		if next := c.refreshInterval(); next != interval {
+			interval = next
+			if ticker != nil {
+				ticker.Stop()
+				ticker, tick = nil, nil
+			}
+			if interval > 0 {
+				ticker = clk.NewTicker(interval)
+				tick = ticker.C()
+			} else if pending, _ := prices.flush(clk.Now()); pending != nil {
+				if err := c.write(c.project(pending)); err != nil {
+					return
+				}
+			}
+		}
+
+		var message []byte
+		select {
+		case received, ok := <-c.Send:
+			if !ok {
+				// The Hub closed the channel, send close message
+				c.setWriteDeadline()
+				if err := c.Conn.WriteMessage(websocket.CloseMessage, []byte{}); err != nil {
+					log.Printf("Error sending close message: %v", err)
+				}
+				return
+			}
+			if now := clk.Now(); c.expired(received, c.dequeued(received), now) {
+				c.Hub.recordClientDrop(c, DropExpired)
+				continue
+			}
+			if interval == 0 || !prices.add(received) {
+				message = received
+			} else if now := clk.Now(); prices.due(now, interval) {
+				message, _ = prices.flush(now)
+				message = c.project(message)
+			}
+
+		case now := <-tick:
+			if prices.due(now, interval) {
+				message, _ = prices.flush(now)
+				message = c.project(message)
+			}
+
+		case <-ping:
+			c.setWriteDeadline()
+			if err := c.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				log.Printf("Error pinging client: %v", err)
+				return
+			}
+		}
+
+		if message == nil {
+			continue
+		}
+		if err := c.write(message); err != nil {
+			return
+		}
+	}
+}
+
+// refreshInterval returns the client's current RefreshInterval, at least
+// the capped one while the client is over its byte cap.
+func (c *Client) refreshInterval() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.capped {
+		return max(c.RefreshInterval, c.cappedRefreshInterval())
+	}
+	return c.RefreshInterval
+}
+
+// clock returns the clock of the client's Hub.
+func (c *Client) clock() clock.Clock {
+	if c.Hub == nil {
+		return clock.Real
+	}
+	return c.Hub.clock
+}
+
+// queuedFrame is a message in Send and the time it was queued.
+type queuedFrame struct {
+	message  []byte
+	queuedAt time.Time
+}
+
+// enqueue queues a message without blocking, stamped with its enqueue time.
+// It reports whether the message was queued.
+func (c *Client) enqueue(message []byte, now time.Time) bool {
+	c.queueMu.Lock()
+	defer c.queueMu.Unlock()
+
+	select {
+	case c.Send <- message:
+		c.queued = append(c.queued, queuedFrame{message: message, queuedAt: now})
+		return true
+	default:
+		return false
+	}
+}
+
+// discardOldest drops the oldest queued message, if any.
+func (c *Client) discardOldest() {
+	c.queueMu.Lock()
+	defer c.queueMu.Unlock()
+
+	select {
+	case message := <-c.Send:
+		c.takeQueued(message)
+	default:
+	}
+}
+
+// dequeued returns the enqueue time of a message received from Send, or
+// the zero time if it was queued without one.
+func (c *Client) dequeued(message []byte) time.Time {
+	c.queueMu.Lock()
+	defer c.queueMu.Unlock()
+	return c.takeQueued(message)
+}
+
+// takeQueued removes a message received from Send from the queue and
+// returns its enqueue time. The message is looked up rather than taken
+// from the front, as WritePump may not have taken the message it received
+// yet when discardOldest receives the next one. The caller holds queueMu.
+func (c *Client) takeQueued(message []byte) time.Time {
+	for idx, frame := range c.queued {
+		if sameMessage(frame.message, message) {
+			c.queued = slices.Delete(c.queued, idx, idx+1)
+			return frame.queuedAt
+		}
+	}
+	return time.Time{}
+}
+
+// sameMessage reports whether a and b are the same message, not just
+// equal bytes.
+func sameMessage(a, b []byte) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
+
+// expired reports whether a message is a price update that waited in Send
+// longer than the Hub's maximum message age.
+func (c *Client) expired(message []byte, queuedAt, now time.Time) bool {
+	if c.Hub == nil || c.Hub.maxMessageAge <= 0 || queuedAt.IsZero() || now.Sub(queuedAt) <= c.Hub.maxMessageAge {
+		return false
+	}
+
+	// Only the first line of NDJSON messages is needed for the type
+	var envelope struct {
+		Type string `json:"type"`
+	}
+	if err := json.NewDecoder(bytes.NewReader(message)).Decode(&envelope); err != nil {
+		return false
+	}
+	return envelope.Type == "multi_update" || envelope.Type == "price_update"
+}
+
+// setWriteDeadline bounds the next write by the Hub's write timeout.
+func (c *Client) setWriteDeadline() {
+	if c.Hub != nil && c.Hub.writeTimeout > 0 {
+		c.Conn.SetWriteDeadline(time.Now().Add(c.Hub.writeTimeout))
+	}
+}
+
+// write sends a message to the WebSocket connection and records its usage.
+// It notifies the client when the message took it over its byte cap.
+// MessagePack clients receive binary frames; messages rendered for them
+// once per broadcast pass as they are, while the JSON ones addressed to
+// the client alone, e.g. command responses, are encoded here. NDJSON
+// clients likewise receive the updates coalesced for them as lines.
+func (c *Client) write(message []byte) error {
+	messageType := websocket.TextMessage
+	switch c.Encoding {
+	case EncodingNDJSON:
+		if isJSON(message) && !bytes.HasSuffix(message, []byte("\n")) {
+			message = toNDJSON(message)
+		}
+	case EncodingMsgPack:
+		messageType = websocket.BinaryMessage
+		if isJSON(message) {
+			message = toMsgPack(message)
+		}
+	}
+
+	c.setWriteDeadline()
+	if err := c.Conn.WriteMessage(messageType, message); err != nil {
+		log.Printf("Error writing message to client: %v", err)
+		return err
+	}
+
+	c.messagesSent.Add(1)
+	if c.Usage != nil {
+		c.Usage.RecordMessage(c.UsageKey, len(message))
+	}
+	if notice := c.recordSent(len(message), c.clock().Now()); notice != nil {
+		return c.write(notice)
+	}
+	return nil
+}
+
+// Close gracefully closes the client connection and cleans up resources.
+func (c *Client) Close() {
+	if c.Conn != nil {
+		c.Conn.Close()
+	}
+}
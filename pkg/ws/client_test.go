@@ -0,0 +1,205 @@
+package ws
+
+import (
+	"testing"
+	"time"
+
+	"macro-analyst/pkg/clock"
+
+	"github.com/gofiber/contrib/websocket"
+)
+
+// TestClientStructInitialization verifies Client struct initialization.
+func TestClientStructInitialization(t *testing.T) {
+	hub := NewHub()
+	sendChan := make(chan []byte, 256)
+
+	client := &Client{
+		Hub:  hub,
+		Conn: nil, // Can't test with real WebSocket connection in unit test
+		Send: sendChan,
+	}
+
+	if client.Hub != hub {
+		t.Error("Client hub not set correctly")
+	}
+
+	if client.Send != sendChan {
+		t.Error("Client send channel not set correctly")
+	}
+}
+
+// TestClientClose verifies client cleanup.
+func TestClientClose(t *testing.T) {
+	client := &Client{
+		Hub:  NewHub(),
+		Conn: nil,
+		Send: make(chan []byte, 256),
+	}
+
+	// Close should not panic even with nil connection
+	client.Close()
+}
+
+// TestClientWithNilConnection verifies handling of nil connection.
+func TestClientWithNilConnection(t *testing.T) {
+	client := &Client{
+		Hub:  NewHub(),
+		Conn: nil,
+		Send: make(chan []byte, 256),
+	}
+
+	// Close with nil connection should not panic
+	client.Close()
+}
+
+// MockWebSocketConn is a mock implementation for testing.
+type MockWebSocketConn struct {
+	messages      [][]byte
+	messageTypes  []int
+	closeReceived bool
+	writeFails    bool
+}
+
+func (m *MockWebSocketConn) WriteMessage(messageType int, data []byte) error {
+	if m.writeFails {
+		return websocket.ErrCloseSent
+	}
+	m.messages = append(m.messages, data)
+	m.messageTypes = append(m.messageTypes, messageType)
+	if messageType == websocket.CloseMessage {
+		m.closeReceived = true
+	}
+	return nil
+}
+
+func (m *MockWebSocketConn) Close() error {
+	return nil
+}
+
+// TestWritePumpChannelClose verifies WritePump handles closed channel.
+func TestWritePumpChannelClose(t *testing.T) {
+	hub := NewHub()
+	mockConn := &MockWebSocketConn{}
+	
+	client := &Client{
+		Hub:  hub,
+		Conn: (*websocket.Conn)(nil), // Will be replaced by mock in actual implementation
+		Send: make(chan []byte, 256),
+	}
+
+	// Close the send channel to trigger WritePump shutdown
+	close(client.Send)
+
+	// Read from closed channel should return ok=false
+	_, ok := <-client.Send
+	if ok {
+		t.Error("Expected closed channel")
+	}
+
+	// Verify mock received close message (would need interface abstraction for real testing)
+	if mockConn.closeReceived {
+		t.Log("Mock WebSocket received close message")
+	}
+}
+
+// TestClientSendBuffer verifies send channel buffering.
+func TestClientSendBuffer(t *testing.T) {
+	client := &Client{
+		Hub:  NewHub(),
+		Conn: nil,
+		Send: make(chan []byte, 256),
+	}
+
+	// Fill buffer to test capacity
+	for i := 0; i < 256; i++ {
+		select {
+		case client.Send <- []byte("test"):
+			// Success
+		default:
+			t.Errorf("Buffer full at message %d, expected 256", i)
+		}
+	}
+
+	// 257th message should block (test with default case)
+	select {
+	case client.Send <- []byte("overflow"):
+		t.Error("Expected send to block when buffer full")
+	default:
+		// Expected - buffer is full
+	}
+}
+
+// TestClientQueueTimes verifies messages queued by the Hub are stamped
+// with their enqueue time, in step with the send buffer.
+func TestClientQueueTimes(t *testing.T) {
+	clk := clock.NewFake(time.Unix(100, 0))
+	hub := NewHub(WithHubClock(clk), WithSlowClientPolicy(SlowClientDropOldest))
+	client := &Client{Hub: hub, Send: make(chan []byte, 2)}
+	hub.clients[client] = true
+
+	for _, message := range []string{"first", "second", "third"} {
+		hub.broadcastMessage([]byte(`{"type":"` + message + `"}`))
+		clk.Advance(time.Second)
+	}
+
+	for _, expected := range []int64{101, 102} {
+		message := <-client.Send
+		if queuedAt := client.dequeued(message); queuedAt.Unix() != expected {
+			t.Errorf("Expected enqueue time %d, got %v", expected, queuedAt)
+		}
+	}
+	if queuedAt := client.dequeued([]byte(`{"type":"second"}`)); !queuedAt.IsZero() {
+		t.Errorf("Expected no enqueue time left, got %v", queuedAt)
+	}
+}
+
+// TestClientQueueTimesDiscardWhileWriting verifies a message keeps its
+// enqueue time when the oldest queued one is discarded between WritePump
+// receiving it and looking up its time.
+func TestClientQueueTimesDiscardWhileWriting(t *testing.T) {
+	client := &Client{Send: make(chan []byte, 2)}
+	client.enqueue([]byte("first"), time.Unix(100, 0))
+	client.enqueue([]byte("second"), time.Unix(101, 0))
+
+	received := <-client.Send
+	client.discardOldest()
+	client.enqueue([]byte("third"), time.Unix(102, 0))
+
+	if queuedAt := client.dequeued(received); queuedAt.Unix() != 100 {
+		t.Errorf("Expected the enqueue time of %s, got %v", received, queuedAt)
+	}
+	if queuedAt := client.dequeued(<-client.Send); queuedAt.Unix() != 102 {
+		t.Errorf("Expected the enqueue time of the third message, got %v", queuedAt)
+	}
+}
+
+// TestClientExpired verifies only price updates older than the maximum
+// message age are skipped at write time.
+func TestClientExpired(t *testing.T) {
+	now := time.Unix(100, 0)
+	stale := now.Add(-DefaultMaxMessageAge - time.Second)
+	prices := `{"type":"multi_update","data":[{"symbol":"BTCUSDT","price":1}]}`
+
+	tests := []struct {
+		name     string
+		maxAge   time.Duration
+		message  string
+		queuedAt time.Time
+		expected bool
+	}{
+		{"stale prices", DefaultMaxMessageAge, prices, stale, true},
+		{"stale NDJSON prices", DefaultMaxMessageAge, `{"type":"price_update","symbol":"BTCUSDT"}` + "\n", stale, true},
+		{"fresh prices", DefaultMaxMessageAge, prices, now.Add(-time.Second), false},
+		{"stale command response", DefaultMaxMessageAge, `{"type":"subscribed","symbols":["BTCUSDT"]}`, stale, false},
+		{"unknown enqueue time", DefaultMaxMessageAge, prices, time.Time{}, false},
+		{"disabled", 0, prices, stale, false},
+	}
+
+	for _, tt := range tests {
+		client := &Client{Hub: NewHub(WithMaxMessageAge(tt.maxAge))}
+		if expired := client.expired([]byte(tt.message), tt.queuedAt, now); expired != tt.expected {
+			t.Errorf("%s: expected expired %v, got %v", tt.name, tt.expected, expired)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package ws
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// nextClientID numbers the clients of every Hub, so IDs are unique across
+// the price, candle and order book hubs of a server.
+var nextClientID atomic.Uint64
+
+// ClientInfo describes a connected client for operational debugging.
+type ClientInfo struct {
+	ID              uint64    `json:"id"`
+	Stream          string    `json:"stream,omitempty"`
+	RemoteIP        string    `json:"remoteIp"`
+	UserAgent       string    `json:"userAgent,omitempty"`
+	UserID          string    `json:"userId,omitempty"`
+	ConnectedAt     time.Time `json:"connectedAt"`
+	Encoding        Encoding  `json:"encoding"`
+	Topics          []string  `json:"topics"`
+	Symbols         []string  `json:"symbols,omitempty"` // Every symbol when empty
+	MessagesSent    int64     `json:"messagesSent"`
+	MessagesDropped int64     `json:"messagesDropped"`
+	BytesSent       int64     `json:"bytesSent"`
+	Queued          int       `json:"queued"`
+}
+
+// identify numbers a registering client and stamps its connect time, from
+// which its idle timeout runs until its first frame. It is called with h.mu
+// held.
+func (h *Hub) identify(client *Client) {
+	if client.id == 0 {
+		client.id = nextClientID.Add(1)
+	}
+	if client.connectedAt.IsZero() {
+		client.connectedAt = h.clock.Now()
+	}
+	if client.lastSeen.Load() == 0 {
+		client.touch(client.connectedAt)
+	}
+}
+
+// recordClientDrop counts a message for the client dropped for reason.
+func (h *Hub) recordClientDrop(client *Client, reason DropReason) {
+	client.messagesDropped.Add(1)
+	h.recordDrop(reason)
+}
+
+// ID returns the number the Hub gave the client on registration, zero
+// before.
+func (c *Client) ID() uint64 {
+	return c.id
+}
+
+// Clients describes the connected clients, oldest first.
+func (h *Hub) Clients() []ClientInfo {
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(h.clients))
+	for client := range h.clients {
+		clients = append(clients, client)
+	}
+	h.mu.RUnlock()
+
+	infos := make([]ClientInfo, 0, len(clients))
+	for _, client := range clients {
+		infos = append(infos, ClientInfo{
+			ID:              client.id,
+			Stream:          client.Stream,
+			RemoteIP:        client.RemoteIP,
+			UserAgent:       client.UserAgent,
+			UserID:          client.UserID,
+			ConnectedAt:     client.connectedAt,
+			Encoding:        client.Encoding,
+			Topics:          sortedTopics(h.topics.Patterns(client)),
+			Symbols:         client.subscribedSymbols(),
+			MessagesSent:    client.messagesSent.Load(),
+			MessagesDropped: client.messagesDropped.Load(),
+			BytesSent:       client.BytesSent(),
+			Queued:          len(client.Send),
+		})
+	}
+	sort.Slice(infos, func(a, b int) bool {
+		return infos[a].ID < infos[b].ID
+	})
+	return infos
+}
+
+// Disconnect closes the connection of the client with the ID. It reports
+// whether the client is connected to this Hub.
+func (h *Hub) Disconnect(id uint64) bool {
+	h.mu.RLock()
+	var found *Client
+	for client := range h.clients {
+		if client.id == id {
+			found = client
+			break
+		}
+	}
+	h.mu.RUnlock()
+
+	if found == nil {
+		return false
+	}
+	h.unregister <- found
+	return true
+}
+
+// subscribedSymbols returns the symbols the client receives prices of,
+// sorted, or nil for every symbol.
+func (c *Client) subscribedSymbols() []string {
+	c.mu.RLock()
+	subscribed := c.subscribed
+	if subscribed == nil {
+		subscribed = c.Symbols
+	}
+	symbols := make([]string, 0, len(subscribed))
+	for symbol, ok := range subscribed {
+		if ok {
+			symbols = append(symbols, symbol)
+		}
+	}
+	c.mu.RUnlock()
+
+	if len(symbols) == 0 {
+		return nil
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// sortedTopics sorts topic patterns in place, returning them.
+func sortedTopics(topics []string) []string {
+	sort.Strings(topics)
+	return topics
+}
@@ -0,0 +1,60 @@
+package ws
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"macro-analyst/pkg/clock"
+)
+
+// TestHubClients verifies connected clients are listed oldest first with
+// their metadata, subscriptions and dropped messages, and that Disconnect
+// closes the connection of a listed client.
+func TestHubClients(t *testing.T) {
+	connected := time.Date(2024, 2, 20, 12, 0, 0, 0, time.UTC)
+	hub := NewHub(WithHubClock(clock.NewFake(connected)), WithSlowClientPolicy(SlowClientDropNewest))
+	go hub.Run()
+
+	first := &Client{Hub: hub, Send: make(chan []byte, 1), RemoteIP: "10.0.0.1", UserAgent: "test", Stream: "prices"}
+	second := &Client{Hub: hub, Send: make(chan []byte, 1), Stream: "share", Symbols: map[string]bool{"ETHUSDT": true}}
+	hub.register <- first
+	hub.register <- second
+	flushHub(hub)
+
+	// The second update does not fit the send buffer
+	for range 2 {
+		hub.deliver(first, []byte(`{"type":"heartbeat"}`), &renderCache{})
+	}
+
+	clients := hub.Clients()
+	if len(clients) != 2 || clients[0].ID != first.ID() || clients[1].ID != second.ID() || first.ID() == 0 {
+		t.Fatalf("Expected both clients oldest first, got %+v", clients)
+	}
+	info := clients[0]
+	if info.RemoteIP != "10.0.0.1" || info.UserAgent != "test" || info.Stream != "prices" || !info.ConnectedAt.Equal(connected) {
+		t.Errorf("Unexpected metadata %+v", info)
+	}
+	if info.Queued != 1 || info.MessagesDropped != 1 || info.Symbols != nil {
+		t.Errorf("Expected 1 queued and 1 dropped message for every symbol, got %+v", info)
+	}
+	if !reflect.DeepEqual(clients[1].Symbols, []string{"ETHUSDT"}) {
+		t.Errorf("Expected the shared symbols, got %v", clients[1].Symbols)
+	}
+
+	if hub.Disconnect(first.ID() + second.ID()) {
+		t.Error("Expected no client with an unknown ID")
+	}
+	if !hub.Disconnect(first.ID()) {
+		t.Fatal("Expected the client disconnected")
+	}
+	flushHub(hub)
+	if clients := hub.Clients(); len(clients) != 1 || clients[0].ID != second.ID() {
+		t.Errorf("Expected only the second client left, got %+v", clients)
+	}
+	if _, open := <-first.Send; open {
+		if _, open = <-first.Send; open {
+			t.Error("Expected the send channel of the disconnected client closed")
+		}
+	}
+}
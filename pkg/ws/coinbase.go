@@ -0,0 +1,87 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// NewCoinbaseSource creates an ExchangeSource of the Coinbase Advanced
+// Trade ticker channel. Symbols stream the product of the same quote
+// asset, so USDT prices are never blended with USD ones.
+func NewCoinbaseSource() ExchangeSource {
+	return newStreamSource(ExchangeCoinbase, CoinbaseStreamURL, coinbaseProtocol{})
+}
+
+// coinbaseProtocol is the Coinbase Advanced Trade ticker channel protocol.
+type coinbaseProtocol struct{}
+
+// coinbaseMessage is a ticker channel message of Coinbase.
+type coinbaseMessage struct {
+	Channel   string `json:"channel"`
+	Timestamp string `json:"timestamp"`
+	Events    []struct {
+		Tickers []struct {
+			ProductID     string `json:"product_id"`
+			Price         string `json:"price"`
+			Volume        string `json:"volume_24_h"`
+			ChangePercent string `json:"price_percent_chg_24_h"`
+		} `json:"tickers"`
+	} `json:"events"`
+}
+
+// product returns the Coinbase product of a symbol, e.g. BTC-USDT for
+// BTCUSDT.
+func (coinbaseProtocol) product(symbol string) (string, bool) {
+	base, quote, ok := splitSymbol(symbol)
+	if !ok {
+		return "", false
+	}
+	return base + "-" + quote, true
+}
+
+func (coinbaseProtocol) subscribe(products []string) any {
+	return map[string]any{"type": "subscribe", "channel": "ticker", "product_ids": products}
+}
+
+func (coinbaseProtocol) unsubscribe(products []string) any {
+	return map[string]any{"type": "unsubscribe", "channel": "ticker", "product_ids": products}
+}
+
+// parse returns the tickers of a ticker channel message. Coinbase sends
+// decimal strings and one timestamp per message.
+func (coinbaseProtocol) parse(message []byte) ([]exchangeTicker, error) {
+	var decoded coinbaseMessage
+	if err := json.Unmarshal(message, &decoded); err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+	if decoded.Channel != "ticker" {
+		return nil, nil
+	}
+
+	var eventTime int64
+	if timestamp, err := time.Parse(time.RFC3339Nano, decoded.Timestamp); err == nil {
+		eventTime = timestamp.UnixMilli()
+	}
+
+	var tickers []exchangeTicker
+	for _, event := range decoded.Events {
+		for _, ticker := range event.Tickers {
+			price, err := strconv.ParseFloat(ticker.Price, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid price %q for %s", ticker.Price, ticker.ProductID)
+			}
+			changePercent, _ := strconv.ParseFloat(ticker.ChangePercent, 64)
+			volume, _ := strconv.ParseFloat(ticker.Volume, 64)
+			tickers = append(tickers, exchangeTicker{
+				product:       ticker.ProductID,
+				price:         price,
+				changePercent: changePercent,
+				volume:        volume,
+				eventTime:     eventTime,
+			})
+		}
+	}
+	return tickers, nil
+}
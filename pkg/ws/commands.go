@@ -0,0 +1,393 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+)
+
+const (
+	// MaxCommandSize is the largest client frame accepted by ReadPump;
+	// larger frames close the connection
+	MaxCommandSize = 4096
+
+	// MaxCommandSymbols is the most symbols one subscribe or unsubscribe
+	// command may name
+	MaxCommandSymbols = 100
+
+	// MinThrottleInterval and MaxThrottleInterval bound the refresh
+	// interval a client may set with set_throttle
+	MinThrottleInterval = 250 * time.Millisecond
+	MaxThrottleInterval = time.Minute
+)
+
+// Client command types
+const (
+	CommandSubscribe   = "subscribe"
+	CommandUnsubscribe = "unsubscribe"
+	CommandPing        = "ping"
+	CommandSetThrottle = "set_throttle"
+
+	// CommandSavePreferences saves the client's subscription, fields and
+	// refresh interval for its user; CommandClearPreferences removes them
+	CommandSavePreferences  = "save_preferences"
+	CommandClearPreferences = "clear_preferences"
+
+	// CommandSubscribeTopics and CommandUnsubscribeTopics change the topics
+	// the client receives messages of
+	CommandSubscribeTopics   = "subscribe_topics"
+	CommandUnsubscribeTopics = "unsubscribe_topics"
+
+	// CommandSetAlert, CommandRemoveAlert and CommandListAlerts manage the
+	// client's price alerts
+	CommandSetAlert    = "set_alert"
+	CommandRemoveAlert = "remove_alert"
+	CommandListAlerts  = "list_alerts"
+)
+
+// Commands lists the command types clients may send.
+var Commands = []string{
+	CommandSubscribe, CommandUnsubscribe, CommandPing, CommandSetThrottle,
+	CommandSavePreferences, CommandClearPreferences,
+	CommandSubscribeTopics, CommandUnsubscribeTopics,
+	CommandSetAlert, CommandRemoveAlert, CommandListAlerts,
+}
+
+// symbolPattern matches trading symbols such as BTCUSDT
+var symbolPattern = regexp.MustCompile(`^[A-Z0-9]{2,20}$`)
+
+// Command is a JSON message sent by a client to control its stream, e.g.
+// {"type":"subscribe","symbols":["BTCUSDT"],"id":"1"}.
+type Command struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id,omitempty"` // Echoed in the response
+	Symbols    []string `json:"symbols,omitempty"`
+	IntervalMs *int64   `json:"intervalMs,omitempty"` // set_throttle, 0 for every update
+
+	// Price update fields to receive (subscribe), empty for every field
+	Fields []string `json:"fields,omitempty"`
+
+	// Topic patterns (subscribe_topics, unsubscribe_topics)
+	Topics []string `json:"topics,omitempty"`
+
+	// Alert to set (set_alert) and ID of the alert to remove (remove_alert)
+	Alert   *AlertRule `json:"alert,omitempty"`
+	AlertID string     `json:"alertId,omitempty"`
+}
+
+// CommandAck confirms a command with the resulting stream settings.
+type CommandAck struct {
+	Type    string `json:"type"` // Always "ack"
+	ID      string `json:"id,omitempty"`
+	Command string `json:"command"`
+
+	// Symbols subscribed to, null while subscribed to every symbol
+	Symbols []string `json:"symbols"`
+
+	// Symbols unsubscribed from while subscribed to every symbol
+	Excluded []string `json:"excluded,omitempty"`
+
+	// Refresh interval, 0 for every update
+	IntervalMs int64 `json:"intervalMs"`
+
+	// Price update fields received besides the symbol, omitted for every field
+	Fields []string `json:"fields,omitempty"`
+
+	// Topic patterns subscribed to, in acks of topic commands
+	Topics []string `json:"topics,omitempty"`
+
+	// The client's price alerts, in acks of alert commands
+	Alerts []AlertRule `json:"alerts,omitempty"`
+
+	// Last-known price updates of the symbols named in a subscribe command,
+	// in the client's fields and number format, so the client can render
+	// them without waiting for the next update
+	Prices []json.RawMessage `json:"prices,omitempty"`
+}
+
+// Pong answers a ping command.
+type Pong struct {
+	Type       string `json:"type"` // Always "pong"
+	ID         string `json:"id,omitempty"`
+	ServerTime int64  `json:"serverTime"` // Unix ms
+}
+
+// CommandError reports a command that was rejected.
+type CommandError struct {
+	Type    string `json:"type"` // Always "error"
+	ID      string `json:"id,omitempty"`
+	Command string `json:"command,omitempty"`
+	Error   string `json:"error"`
+}
+
+// ReadPump reads commands from the WebSocket connection and answers each
+// with an ack, a pong or an error until the connection closes. A goroutine
+// running ReadPump is started for each connection, next to WritePump.
+// Under an idle timeout, a connection that delivers no frame, not even a
+// pong, before the read deadline is reaped.
+func (c *Client) ReadPump() {
+	c.Conn.SetReadLimit(MaxCommandSize)
+	c.extendReadDeadline()
+
+	// Control frames prove the client alive too
+	c.Conn.SetPongHandler(func(string) error {
+		c.received()
+		return nil
+	})
+	answerPing := c.Conn.PingHandler()
+	c.Conn.SetPingHandler(func(data string) error {
+		c.received()
+		return answerPing(data)
+	})
+
+	for {
+		_, message, err := c.Conn.ReadMessage()
+		if err != nil {
+			var netErr net.Error
+			switch {
+			case errors.As(err, &netErr) && netErr.Timeout():
+				c.timedOut.Store(true)
+				c.Hub.unregister <- c
+			case websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure):
+				log.Printf("WebSocket unexpected close error: %v", err)
+			}
+			return
+		}
+		c.received()
+
+		response, err := json.Marshal(c.handleCommand(message, c.clock().Now()))
+		if err != nil {
+			log.Printf("Error marshaling command response: %v", err)
+			continue
+		}
+		if !c.Hub.SendTo(c, response) {
+			log.Println("⚠ Client gone or send buffer full, dropping command response")
+		}
+	}
+}
+
+// handleCommand applies a command and returns the response to send.
+func (c *Client) handleCommand(message []byte, now time.Time) any {
+	var command Command
+	if err := json.Unmarshal(message, &command); err != nil {
+		return &CommandError{Type: "error", Error: "invalid command: expected a JSON object"}
+	}
+
+	var (
+		alerts []AlertRule
+		err    error
+	)
+	switch command.Type {
+	case CommandPing:
+		return &Pong{Type: "pong", ID: command.ID, ServerTime: now.UnixMilli()}
+	case CommandSubscribe, CommandUnsubscribe:
+		err = c.applySubscription(command)
+	case CommandSetThrottle:
+		err = c.applyThrottle(command)
+	case CommandSavePreferences, CommandClearPreferences:
+		err = c.applyPreferenceCommand(command)
+	case CommandSubscribeTopics, CommandUnsubscribeTopics:
+		err = c.applyTopicCommand(command)
+	case CommandSetAlert, CommandRemoveAlert, CommandListAlerts:
+		alerts, err = c.applyAlertCommand(command)
+	default:
+		err = fmt.Errorf("unknown command type %q (expected one of %s)", command.Type, strings.Join(Commands, ", "))
+	}
+	if err != nil {
+		return &CommandError{Type: "error", ID: command.ID, Command: command.Type, Error: err.Error()}
+	}
+
+	ack := c.ack(command)
+	switch command.Type {
+	case CommandSubscribe:
+		ack.Prices = c.currentPrices(command.Symbols)
+	case CommandSubscribeTopics, CommandUnsubscribeTopics:
+		ack.Topics = c.topicPatterns()
+	case CommandSetAlert, CommandRemoveAlert, CommandListAlerts:
+		ack.Alerts = alerts
+	}
+	return ack
+}
+
+// applySubscription validates and applies a subscribe or unsubscribe
+// command. The first subscribe narrows the stream from every symbol to the
+// named ones; unsubscribing before that excludes symbols instead. A
+// subscribe may also select the price update fields to receive, with or
+// without symbols.
+func (c *Client) applySubscription(command Command) error {
+	if command.Fields != nil && command.Type != CommandSubscribe {
+		return fmt.Errorf("fields are only accepted with %s", CommandSubscribe)
+	}
+	if (command.Fields == nil || len(command.Symbols) > 0) &&
+		(len(command.Symbols) == 0 || len(command.Symbols) > MaxCommandSymbols) {
+		return fmt.Errorf("symbols must name 1 to %d symbols", MaxCommandSymbols)
+	}
+	fields, err := parseFields(command.Fields)
+	if err != nil {
+		return err
+	}
+
+	symbols := make([]string, len(command.Symbols))
+	for idx, symbol := range command.Symbols {
+		symbol = c.resolveSymbol(symbol)
+		if !symbolPattern.MatchString(symbol) {
+			return fmt.Errorf("invalid symbol %q", command.Symbols[idx])
+		}
+		if c.Symbols != nil && !c.Symbols[symbol] {
+			return fmt.Errorf("symbol %s is outside this stream's scope", symbol)
+		}
+		if c.Symbols == nil && command.Type == CommandSubscribe {
+			var err error
+			if symbol, err = c.checkListed(command.Symbols[idx], symbol); err != nil {
+				return err
+			}
+		}
+		symbols[idx] = symbol
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, symbol := range symbols {
+		switch {
+		case command.Type == CommandUnsubscribe && c.subscribed != nil:
+			delete(c.subscribed, symbol)
+		case command.Type == CommandUnsubscribe:
+			if c.excluded == nil {
+				c.excluded = make(map[string]bool)
+			}
+			c.excluded[symbol] = true
+		default:
+			if c.subscribed == nil {
+				c.subscribed = make(map[string]bool)
+				c.excluded = nil
+			}
+			c.subscribed[symbol] = true
+		}
+	}
+	c.scope.Store(nil)
+	if command.Fields != nil {
+		c.fields = fields
+		c.projection = strings.Join(sortedSymbols(fields), ",")
+	}
+	return nil
+}
+
+// resolveSymbol returns the symbol for a symbol or an alias, preferring the
+// symbols of the client's scope.
+func (c *Client) resolveSymbol(name string) string {
+	for _, candidate := range symbolCandidates(name) {
+		if c.Symbols[candidate] {
+			return candidate
+		}
+	}
+	return NormalizeSymbol(name)
+}
+
+// checkListed resolves a subscribed symbol or alias against the Catalog,
+// returning resolved, the symbol resolveSymbol found for it, while the
+// catalog has not been loaded.
+func (c *Client) checkListed(name, resolved string) (string, error) {
+	if c.Catalog == nil {
+		return resolved, nil
+	}
+	catalog := c.Catalog()
+	if catalog == nil {
+		return resolved, nil
+	}
+	symbol, err := catalog.LookupSymbol(name)
+	if errors.Is(err, ErrSymbolCatalogUnavailable) {
+		return resolved, nil
+	}
+	return symbol, err
+}
+
+// applyThrottle validates and applies a set_throttle command.
+func (c *Client) applyThrottle(command Command) error {
+	if command.IntervalMs == nil {
+		return errors.New("intervalMs is required")
+	}
+
+	interval := time.Duration(*command.IntervalMs) * time.Millisecond
+	if interval != 0 && (interval < MinThrottleInterval || interval > MaxThrottleInterval) {
+		return fmt.Errorf("intervalMs must be 0 or %d to %d",
+			MinThrottleInterval.Milliseconds(), MaxThrottleInterval.Milliseconds())
+	}
+	if interval < c.MinRefreshInterval {
+		return fmt.Errorf("intervalMs must be at least %d for this stream", c.MinRefreshInterval.Milliseconds())
+	}
+
+	c.mu.Lock()
+	c.RefreshInterval = interval
+	c.mu.Unlock()
+	return nil
+}
+
+// ack confirms a command with the client's current stream settings.
+func (c *Client) ack(command Command) *CommandAck {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return &CommandAck{
+		Type:       "ack",
+		ID:         command.ID,
+		Command:    command.Type,
+		Symbols:    sortedSymbols(c.subscribed),
+		Excluded:   sortedSymbols(c.excluded),
+		IntervalMs: c.RefreshInterval.Milliseconds(),
+		Fields:     sortedSymbols(c.fields),
+	}
+}
+
+// currentPrices returns the price updates of the symbols, or their aliases,
+// from the Hub's snapshot of the last-known state.
+func (c *Client) currentPrices(symbols []string) []json.RawMessage {
+	if c.Hub == nil || len(symbols) == 0 {
+		return nil
+	}
+
+	named := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		named[c.resolveSymbol(symbol)] = true
+	}
+
+	var prices []json.RawMessage
+	for _, frame := range c.Hub.Snapshot() {
+		frame = filterSymbols(frame, func(symbol string) bool { return named[symbol] })
+		if frame == nil {
+			continue
+		}
+
+		var update struct {
+			Data []json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(c.project(frame), &update); err != nil {
+			continue
+		}
+		prices = append(prices, update.Data...)
+	}
+	return prices
+}
+
+// sortedSymbols returns the symbols, or fields, of a set in order, nil for
+// a nil set.
+func sortedSymbols(set map[string]bool) []string {
+	if set == nil {
+		return nil
+	}
+
+	symbols := make([]string, 0, len(set))
+	for symbol := range set {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
@@ -0,0 +1,281 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestHandleCommandSubscriptions verifies subscribe and unsubscribe commands
+// change which price updates a client receives.
+func TestHandleCommandSubscriptions(t *testing.T) {
+	update, _ := json.Marshal(&MultiUpdate{Type: "multi_update", Data: []*PriceUpdate{
+		{Symbol: "BTCUSDT"}, {Symbol: "ETHUSDT"}, {Symbol: "SOLUSDT"},
+	}})
+	delivered := func(client *Client) []string {
+		var cache renderCache
+		payload := client.payloadFor(update, &cache)
+		if payload == nil {
+			return nil
+		}
+		var received MultiUpdate
+		json.Unmarshal(payload, &received)
+		var symbols []string
+		for _, priceUpdate := range received.Data {
+			symbols = append(symbols, priceUpdate.Symbol)
+		}
+		return symbols
+	}
+
+	tests := []struct {
+		name     string
+		commands []string
+		expected string
+	}{
+		{"no commands", nil, "BTCUSDT,ETHUSDT,SOLUSDT"},
+		{"subscribe narrows", []string{`{"type":"subscribe","symbols":["btcusdt"]}`}, "BTCUSDT"},
+		{"subscribe aliases", []string{`{"type":"subscribe","symbols":["btc","SOL-USD"]}`}, "BTCUSDT,SOLUSDT"},
+		{"subscribe adds", []string{
+			`{"type":"subscribe","symbols":["BTCUSDT"]}`,
+			`{"type":"subscribe","symbols":["SOLUSDT"]}`,
+		}, "BTCUSDT,SOLUSDT"},
+		{"unsubscribe excludes", []string{`{"type":"unsubscribe","symbols":["ETHUSDT"]}`}, "BTCUSDT,SOLUSDT"},
+		{"unsubscribe everything", []string{
+			`{"type":"subscribe","symbols":["BTCUSDT"]}`,
+			`{"type":"unsubscribe","symbols":["BTCUSDT"]}`,
+		}, ""},
+	}
+
+	for _, tt := range tests {
+		client := &Client{Send: make(chan []byte, 1)}
+		for _, command := range tt.commands {
+			if _, ok := client.handleCommand([]byte(command), time.Now()).(*CommandAck); !ok {
+				t.Fatalf("%s: expected %s to be acknowledged", tt.name, command)
+			}
+		}
+
+		if got := strings.Join(delivered(client), ","); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
+
+// TestHandleCommandSubscribeCatalog verifies subscriptions to symbols not
+// trading on Binance are rejected once the catalog of the active feed has
+// loaded.
+func TestHandleCommandSubscribeCatalog(t *testing.T) {
+	catalog := NewIngestor(HubSink(NewHub()), WithSymbolLister(listing("BTCUSDT", "DOGEUSDT")))
+	active := catalog
+	client := &Client{Catalog: func() *Ingestor { return active }}
+	subscribe := []byte(`{"type":"subscribe","symbols":["doge","BTCUSTD"]}`)
+
+	if _, ok := client.handleCommand(subscribe, time.Now()).(*CommandAck); !ok {
+		t.Fatal("Expected subscribe to be acknowledged before the catalog loaded")
+	}
+
+	catalog.LoadSymbolCatalog(context.Background())
+	response, ok := client.handleCommand(subscribe, time.Now()).(*CommandError)
+	if !ok || !strings.Contains(response.Error, "BTCUSTD") {
+		t.Errorf("Expected an error naming BTCUSTD, got %+v", response)
+	}
+
+	ack, ok := client.handleCommand([]byte(`{"type":"subscribe","symbols":["doge"]}`), time.Now()).(*CommandAck)
+	if !ok || !slices.Contains(ack.Symbols, "DOGEUSDT") {
+		t.Errorf("Expected DOGEUSDT to be subscribed, got %+v", ack)
+	}
+
+	// After a switchover the new feed's catalog applies, not yet loaded
+	active = NewIngestor(HubSink(NewHub()), WithSymbolLister(listing("BTCUSDT")))
+	if _, ok := client.handleCommand(subscribe, time.Now()).(*CommandAck); !ok {
+		t.Error("Expected subscribe to be checked against the active feed")
+	}
+}
+
+// TestHandleCommandAck verifies acks echo the id and the resulting settings.
+func TestHandleCommandAck(t *testing.T) {
+	client := &Client{}
+	client.handleCommand([]byte(`{"type":"subscribe","symbols":["ETHUSDT","BTCUSDT"]}`), time.Now())
+
+	response := client.handleCommand([]byte(`{"type":"set_throttle","id":"7","intervalMs":2000}`), time.Now())
+	ack, ok := response.(*CommandAck)
+	if !ok {
+		t.Fatalf("Expected an ack, got %+v", response)
+	}
+	if ack.ID != "7" || ack.Command != CommandSetThrottle || ack.IntervalMs != 2000 {
+		t.Errorf("Unexpected ack %+v", ack)
+	}
+	if strings.Join(ack.Symbols, ",") != "BTCUSDT,ETHUSDT" {
+		t.Errorf("Expected sorted subscriptions, got %v", ack.Symbols)
+	}
+	if client.refreshInterval() != 2*time.Second {
+		t.Errorf("Expected a 2s refresh interval, got %s", client.refreshInterval())
+	}
+}
+
+// TestHandleCommandFields verifies subscribe commands select the price
+// update fields and an empty list restores every field.
+func TestHandleCommandFields(t *testing.T) {
+	client := &Client{}
+
+	response := client.handleCommand([]byte(`{"type":"subscribe","fields":["price","symbol","changePercent"]}`), time.Now())
+	ack, ok := response.(*CommandAck)
+	if !ok {
+		t.Fatalf("Expected an ack, got %+v", response)
+	}
+	if strings.Join(ack.Fields, ",") != "changePercent,price" || ack.Symbols != nil {
+		t.Errorf("Expected sorted fields without narrowing symbols, got %+v", ack)
+	}
+
+	ack, _ = client.handleCommand([]byte(`{"type":"subscribe","symbols":["BTCUSDT"],"fields":[]}`), time.Now()).(*CommandAck)
+	if ack == nil || ack.Fields != nil || strings.Join(ack.Symbols, ",") != "BTCUSDT" {
+		t.Errorf("Expected every field for BTCUSDT, got %+v", ack)
+	}
+}
+
+// TestHandleCommandAckPrices verifies subscribe acks carry the last-known
+// prices of the named symbols in the client's fields.
+func TestHandleCommandAckPrices(t *testing.T) {
+	snapshot, _ := json.Marshal(&MultiUpdate{Type: "multi_update", Data: []*PriceUpdate{
+		{Symbol: "BTCUSDT", Price: 45000.5, Volume: 12}, {Symbol: "ETHUSDT", Price: 2500.25, Volume: 30},
+	}})
+	hub := NewHub()
+	hub.SetSnapshot(snapshot)
+
+	tests := []struct {
+		name     string
+		command  string
+		expected []string
+	}{
+		{"named symbols", `{"type":"subscribe","symbols":["btc","SOLUSDT"],"fields":["price"]}`,
+			[]string{`{"price":45000.5,"symbol":"BTCUSDT"}`}},
+		{"fields only", `{"type":"subscribe","fields":["volume"]}`, nil},
+		{"unsubscribe", `{"type":"unsubscribe","symbols":["ETHUSDT"]}`, nil},
+	}
+
+	for _, tt := range tests {
+		client := &Client{Hub: hub}
+		ack, ok := client.handleCommand([]byte(tt.command), time.Now()).(*CommandAck)
+		if !ok {
+			t.Fatalf("%s: expected an ack", tt.name)
+		}
+
+		var prices []string
+		for _, price := range ack.Prices {
+			prices = append(prices, string(price))
+		}
+		if !slices.Equal(prices, tt.expected) {
+			t.Errorf("%s: expected prices %v, got %v", tt.name, tt.expected, prices)
+		}
+	}
+}
+
+// TestHandleCommandPing verifies pings are answered with the server time.
+func TestHandleCommandPing(t *testing.T) {
+	now := time.UnixMilli(1708424625120)
+	response := (&Client{}).handleCommand([]byte(`{"type":"ping","id":"a"}`), now)
+
+	pong, ok := response.(*Pong)
+	if !ok || pong.ID != "a" || pong.ServerTime != now.UnixMilli() {
+		t.Errorf("Expected a pong with id and server time, got %+v", response)
+	}
+}
+
+// TestHandleCommandErrors verifies invalid commands are rejected without
+// changing the client's settings.
+func TestHandleCommandErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  *Client
+		command string
+	}{
+		{"not json", &Client{}, `subscribe BTCUSDT`},
+		{"unknown type", &Client{}, `{"type":"snapshot"}`},
+		{"no symbols", &Client{}, `{"type":"subscribe"}`},
+		{"invalid symbol", &Client{}, `{"type":"subscribe","symbols":["BTC.USDT"]}`},
+		{"outside scope", &Client{Symbols: map[string]bool{"BTCUSDT": true}}, `{"type":"subscribe","symbols":["ETHUSDT"]}`},
+		{"no interval", &Client{}, `{"type":"set_throttle"}`},
+		{"invalid field", &Client{}, `{"type":"subscribe","symbols":["BTCUSDT"],"fields":["bid"]}`},
+		{"fields on unsubscribe", &Client{}, `{"type":"unsubscribe","symbols":["BTCUSDT"],"fields":["price"]}`},
+		{"interval too short", &Client{}, `{"type":"set_throttle","intervalMs":10}`},
+		{"interval too long", &Client{}, `{"type":"set_throttle","intervalMs":3600000}`},
+		{"below stream minimum", &Client{MinRefreshInterval: 5 * time.Second}, `{"type":"set_throttle","intervalMs":0}`},
+		{"preferences without store", &Client{UserID: "alice"}, `{"type":"save_preferences"}`},
+		{"anonymous preferences", &Client{Preferences: NewPreferenceStore()}, `{"type":"clear_preferences"}`},
+	}
+
+	for _, tt := range tests {
+		response := tt.client.handleCommand([]byte(tt.command), time.Now())
+
+		commandErr, ok := response.(*CommandError)
+		if !ok || commandErr.Type != "error" || commandErr.Error == "" {
+			t.Errorf("%s: expected an error, got %+v", tt.name, response)
+		}
+		if tt.client.subscribed != nil || tt.client.fields != nil || tt.client.RefreshInterval != 0 {
+			t.Errorf("%s: expected settings to be unchanged", tt.name)
+		}
+	}
+}
+
+// TestSendTo verifies command responses only reach registered clients.
+func TestSendTo(t *testing.T) {
+	hub := NewHub()
+	client := &Client{Send: make(chan []byte, 1)}
+
+	if hub.SendTo(client, []byte(`{"type":"pong"}`)) {
+		t.Error("Expected no delivery to an unregistered client")
+	}
+
+	hub.clients[client] = true
+	if !hub.SendTo(client, []byte(`{"type":"pong"}`)) {
+		t.Error("Expected delivery to a registered client")
+	}
+	if hub.SendTo(client, []byte(`{"type":"pong"}`)) {
+		t.Error("Expected a full send buffer to drop the response")
+	}
+}
+
+// FuzzHandleCommand verifies arbitrary client frames are answered with an
+// ack, a pong or an error, never a panic, and that rejected commands leave
+// the client's settings unchanged.
+func FuzzHandleCommand(f *testing.F) {
+	f.Add([]byte(`{"type":"subscribe","symbols":["BTCUSDT"],"id":"1"}`))
+	f.Add([]byte(`{"type":"unsubscribe","symbols":["ethusdt"," SOLUSDT "]}`))
+	f.Add([]byte(`{"type":"subscribe","fields":["price","volume"]}`))
+	f.Add([]byte(`{"type":"set_throttle","intervalMs":500}`))
+	f.Add([]byte(`{"type":"set_throttle","intervalMs":-9223372036854775808}`))
+	f.Add([]byte(`{"type":"ping","id":{"nested":true}}`))
+	f.Add([]byte(`{"type":"subscribe","symbols":[1,null,""]}`))
+	f.Add([]byte(`[]`))
+	f.Add([]byte(``))
+
+	f.Fuzz(func(t *testing.T, message []byte) {
+		client := &Client{MinRefreshInterval: time.Second}
+
+		switch response := client.handleCommand(message, time.Now()).(type) {
+		case *CommandAck:
+			if interval := client.refreshInterval(); interval != 0 && (interval < time.Second || interval > MaxThrottleInterval) {
+				t.Errorf("Accepted refresh interval %s out of bounds", interval)
+			}
+			for _, symbol := range append(response.Symbols, response.Excluded...) {
+				if !symbolPattern.MatchString(symbol) {
+					t.Errorf("Accepted invalid symbol %q", symbol)
+				}
+			}
+			for _, field := range response.Fields {
+				if !slices.Contains(ProjectableFields, field) {
+					t.Errorf("Accepted invalid field %q", field)
+				}
+			}
+		case *Pong:
+		case *CommandError:
+			if client.subscribed != nil || client.excluded != nil || client.fields != nil || client.RefreshInterval != 0 {
+				t.Errorf("Rejected command %q changed the client's settings", message)
+			}
+		default:
+			t.Fatalf("Unexpected response %T", response)
+		}
+	})
+}
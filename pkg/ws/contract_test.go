@@ -0,0 +1,176 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/gorilla/websocket"
+)
+
+// Contract tests replay Binance stream frames recorded from production
+// (testdata/binance_*.jsonl, one combined-stream frame per line) through the
+// SDK and the ingestor, so schema drift in the upstream API or an SDK
+// upgrade fails here instead of in production. To refresh a fixture, record
+// frames with e.g.
+//
+//	websocat 'wss://stream.binance.com:9443/stream?streams=btcusdt@ticker' | head -n 1
+//
+// and update the expected values below. Frames carry only public market
+// data, so no sanitizing beyond trimming is needed.
+
+// replayBinance serves the frames of a fixture on a local combined stream
+// endpoint and points the SDK at it for the duration of the test.
+func replayBinance(t *testing.T, fixture string) {
+	t.Helper()
+
+	data, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	frames := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for _, frame := range frames {
+			if err := conn.WriteMessage(websocket.TextMessage, frame); err != nil {
+				return
+			}
+		}
+		// Keep the stream open until the ingestor disconnects
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	endpoint := binance.BaseCombinedMainURL
+	binance.BaseCombinedMainURL = "ws" + strings.TrimPrefix(server.URL, "http") + "/stream?streams="
+	t.Cleanup(func() { binance.BaseCombinedMainURL = endpoint })
+}
+
+// receiveBroadcasts decodes broadcasts of the given type until done returns
+// true or a second passes.
+func receiveBroadcasts[T any](t *testing.T, hub *Hub, messageType string, done func(received []T) bool) []T {
+	t.Helper()
+
+	var received []T
+	timeout := time.After(time.Second)
+	for !done(received) {
+		select {
+		case queued := <-hub.broadcast:
+			var envelope struct {
+				Type string `json:"type"`
+			}
+			json.Unmarshal(queued.data, &envelope)
+			if envelope.Type != messageType {
+				continue
+			}
+
+			var message T
+			if err := json.Unmarshal(queued.data, &message); err != nil {
+				t.Fatalf("Failed to decode %s: %v", messageType, err)
+			}
+			received = append(received, message)
+		case <-timeout:
+			t.Fatalf("Timeout waiting for %s broadcasts, got %d", messageType, len(received))
+		}
+	}
+	return received
+}
+
+// TestContractBinanceTicker verifies recorded 24h ticker frames are
+// ingested into the expected price updates.
+func TestContractBinanceTicker(t *testing.T) {
+	// Arrange
+	replayBinance(t, "testdata/binance_ticker.jsonl")
+	hub := NewHub()
+	ingestor := NewIngestor(HubSink(hub),
+		WithSymbols([]string{"BTCUSDT", "ETHUSDT"}),
+		WithThrottleInterval(10*time.Millisecond),
+	)
+
+	// Act
+	go ingestor.StartMultiSymbol()
+	defer ingestor.Stop()
+
+	prices := make(map[string]*PriceUpdate)
+	receiveBroadcasts(t, hub, "multi_update", func(received []MultiUpdate) bool {
+		for _, update := range received {
+			for _, price := range update.Data {
+				prices[price.Symbol] = price
+			}
+		}
+		return len(prices) == 2
+	})
+
+	// Assert
+	expected := []PriceUpdate{
+		{Symbol: "BTCUSDT", Price: 51234.2, Change: -123.45, ChangePercent: -0.2404, Volume: 25432, EventTime: 1708424625120, ChangeReference: ChangeReference24h},
+		{Symbol: "ETHUSDT", Price: 2950.2, Change: 45.67, ChangePercent: 1.5724, Volume: 412345, EventTime: 1708424625342, ChangeReference: ChangeReference24h},
+	}
+	for _, want := range expected {
+		got := prices[want.Symbol]
+		if got.Price != want.Price || got.Change != want.Change || got.ChangePercent != want.ChangePercent ||
+			got.Volume != want.Volume || got.EventTime != want.EventTime || got.ChangeReference != want.ChangeReference {
+			t.Errorf("%s: expected %+v, got %+v", want.Symbol, want, *got)
+		}
+	}
+	for _, stats := range ingestor.Stats() {
+		if stats.ParseFailures != 0 {
+			t.Errorf("%s: expected no parse failures, got %d", stats.Symbol, stats.ParseFailures)
+		}
+	}
+}
+
+// TestContractBinanceKline verifies recorded kline frames are ingested into
+// the expected candles.
+func TestContractBinanceKline(t *testing.T) {
+	// Arrange
+	replayBinance(t, "testdata/binance_kline.jsonl")
+	hub := NewHub()
+	ingestor := NewIngestor(HubSink(hub), WithSymbols([]string{"BTCUSDT"}), WithCandles([]string{"1m", "1h"}))
+
+	// Act
+	go ingestor.stream.serve(ingestor)
+	defer ingestor.Stop()
+
+	candles := receiveBroadcasts(t, hub, "candle", func(received []CandleUpdate) bool {
+		return len(received) == 2
+	})
+
+	// Assert
+	expected := []CandleUpdate{
+		{
+			Type: "candle", Symbol: "BTCUSDT", Interval: "1m",
+			OpenTime: 1708424580000, CloseTime: 1708424639999,
+			Open: 51230, High: 51250, Low: 51220.1, Close: 51234.2, Volume: 12.345,
+			Trades: 1001, Closed: false, EventTime: 1708424625120,
+		},
+		{
+			Type: "candle", Symbol: "BTCUSDT", Interval: "1h",
+			OpenTime: 1708421400000, CloseTime: 1708424999999,
+			Open: 51100, High: 51400, Low: 50980, Close: 51260, Volume: 845.12,
+			Trades: 2200001, Closed: true, EventTime: 1708425000000,
+		},
+	}
+	for idx, want := range expected {
+		if candles[idx] != want {
+			t.Errorf("Candle %d: expected %+v, got %+v", idx, want, candles[idx])
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ConversionQuoteAsset is the quote asset of the prices FXRates converts,
+// and one side of every conversion pair
+const ConversionQuoteAsset = "USDT"
+
+var (
+	// ErrUnknownCurrency is returned for a currency without a conversion pair
+	ErrUnknownCurrency = errors.New("unknown currency")
+
+	// ErrNoRate is returned while no price of a currency's conversion pair
+	// has arrived yet
+	ErrNoRate = errors.New("no exchange rate yet")
+)
+
+// conversionPair is a Binance pair of a currency against
+// ConversionQuoteAsset, e.g. EURUSDT, or USDTJPY when quoted in the
+// currency.
+type conversionPair struct {
+	currency string
+	inverted bool // Quoted in the currency, e.g. JPY per USDT
+}
+
+// FXRates holds the exchange rates of fiat currencies, streamed by the
+// price ingestor as Binance conversion pairs, to show prices in EUR, GBP or
+// JPY instead of USDT. It is safe for concurrent use and shared by the
+// feeds.
+type FXRates struct {
+	mu    sync.RWMutex
+	pairs map[string]conversionPair // By pair symbol
+	rates map[string]float64        // Units of currency per USDT, by currency
+}
+
+// NewFXRates creates exchange rates streamed from Binance pairs of a
+// currency against ConversionQuoteAsset, e.g. "EURUSDT" or "USDTJPY".
+func NewFXRates(pairs []string) (*FXRates, error) {
+	rates := &FXRates{
+		pairs: make(map[string]conversionPair, len(pairs)),
+		rates: make(map[string]float64, len(pairs)),
+	}
+	for _, pair := range pairs {
+		pair = strings.ToUpper(strings.TrimSpace(pair))
+		var conversion conversionPair
+		switch {
+		case strings.HasSuffix(pair, ConversionQuoteAsset):
+			conversion.currency = strings.TrimSuffix(pair, ConversionQuoteAsset)
+		case strings.HasPrefix(pair, ConversionQuoteAsset):
+			conversion = conversionPair{currency: strings.TrimPrefix(pair, ConversionQuoteAsset), inverted: true}
+		}
+		if len(conversion.currency) < 3 {
+			return nil, fmt.Errorf("invalid conversion pair %q: expected a currency and %s", pair, ConversionQuoteAsset)
+		}
+		rates.pairs[pair] = conversion
+	}
+	return rates, nil
+}
+
+// Pairs returns the conversion pairs, sorted.
+func (r *FXRates) Pairs() []string {
+	pairs := make([]string, 0, len(r.pairs))
+	for pair := range r.pairs {
+		pairs = append(pairs, pair)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// Currencies returns the currencies prices may be converted to, sorted.
+func (r *FXRates) Currencies() []string {
+	currencies := make([]string, 0, len(r.pairs))
+	for _, pair := range r.pairs {
+		currencies = append(currencies, pair.currency)
+	}
+	sort.Strings(currencies)
+	return currencies
+}
+
+// Supports returns ErrUnknownCurrency unless prices may be converted to
+// currency.
+func (r *FXRates) Supports(currency string) error {
+	for _, pair := range r.pairs {
+		if pair.currency == currency {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w %q, expected one of %v", ErrUnknownCurrency, currency, r.Currencies())
+}
+
+// Rate returns the units of currency per USDT, ErrUnknownCurrency or
+// ErrNoRate.
+func (r *FXRates) Rate(currency string) (float64, error) {
+	if err := r.Supports(currency); err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rate, ok := r.rates[currency]
+	if !ok {
+		return 0, fmt.Errorf("%w for %s", ErrNoRate, currency)
+	}
+	return rate, nil
+}
+
+// update sets the rate of the currency of a conversion pair from its last
+// price. It reports whether symbol is a conversion pair.
+func (r *FXRates) update(symbol, lastPrice string) bool {
+	pair, ok := r.pairs[symbol]
+	if !ok {
+		return false
+	}
+	price, err := strconv.ParseFloat(lastPrice, 64)
+	if err != nil || price <= 0 {
+		return true
+	}
+
+	rate := price
+	if !pair.inverted {
+		rate = 1 / price
+	}
+	r.mu.Lock()
+	r.rates[pair.currency] = rate
+	r.mu.Unlock()
+	return true
+}
+
+// Convert returns copies of the prices with the prices and changes of the
+// symbols quoted in USDT converted to currency, tagged with it. Others are
+// copied unchanged. The percentage change stays that of the USDT price.
+func (r *FXRates) Convert(prices []*PriceUpdate, currency string) ([]*PriceUpdate, error) {
+	rate, err := r.Rate(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([]*PriceUpdate, 0, len(prices))
+	for _, price := range prices {
+		update := *price
+		if strings.HasSuffix(update.Symbol, ConversionQuoteAsset) {
+			update.Price = roundDecimals(update.Price * rate)
+			update.Change = roundDecimals(update.Change * rate)
+			update.Currency = currency
+		}
+		converted = append(converted, &update)
+	}
+	return converted, nil
+}
+
+// convertMessage converts the prices of a multi_update to currency. Other
+// messages, and updates while the rate is unknown, pass unchanged.
+func (r *FXRates) convertMessage(message []byte, currency string) []byte {
+	if parseFrameHeader(message).Type != "multi_update" {
+		return message
+	}
+	var update MultiUpdate
+	if err := json.Unmarshal(message, &update); err != nil {
+		return message
+	}
+
+	prices, err := r.Convert(update.Data, currency)
+	if err != nil {
+		return message
+	}
+	update.Data = prices
+	converted, err := json.Marshal(&update)
+	if err != nil {
+		return message
+	}
+	return converted
+}
+
+// WithConversionRates makes the ingestor stream the conversion pairs of
+// rates along with its symbols, keeping their prices out of the broadcasts
+// unless they are tracked symbols too.
+func WithConversionRates(rates *FXRates) IngestorOption {
+	return func(i *Ingestor) {
+		i.rates = rates
+	}
+}
+
+// streamSymbols returns the symbols of the ticker stream: the tracked
+// symbols and the conversion pairs.
+func (i *Ingestor) streamSymbols(symbols []string) []string {
+	if i.rates == nil {
+		return symbols
+	}
+	streamed := append([]string(nil), symbols...)
+	for _, pair := range i.rates.Pairs() {
+		if !slices.Contains(symbols, pair) {
+			streamed = append(streamed, pair)
+		}
+	}
+	return streamed
+}
+
+// updateConversionRate updates the exchange rate of a conversion pair's
+// ticker event. It reports whether the event is for an untracked
+// conversion pair, which is not broadcast.
+func (i *Ingestor) updateConversionRate(symbol, lastPrice string) bool {
+	if i.rates == nil || !i.rates.update(symbol, lastPrice) {
+		return false
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.findSymbol(symbol) == nil
+}
@@ -0,0 +1,105 @@
+package ws
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestNewFXRates tests the parsing of conversion pairs.
+func TestNewFXRates(t *testing.T) {
+	rates, err := NewFXRates([]string{"eurusdt", " USDTJPY "})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(rates.Pairs(), []string{"EURUSDT", "USDTJPY"}) || !reflect.DeepEqual(rates.Currencies(), []string{"EUR", "JPY"}) {
+		t.Errorf("Unexpected pairs %v and currencies %v", rates.Pairs(), rates.Currencies())
+	}
+
+	for _, pair := range []string{"EURBTC", "USDT", "EUUSDT"} {
+		if _, err := NewFXRates([]string{pair}); err == nil {
+			t.Errorf("Expected an error for %q", pair)
+		}
+	}
+}
+
+// TestFXRatesConvert verifies rates follow their pairs either way round and
+// convert the prices of USDT pairs only.
+func TestFXRatesConvert(t *testing.T) {
+	rates, _ := NewFXRates([]string{"EURUSDT", "USDTJPY"})
+	if _, err := rates.Rate("EUR"); !errors.Is(err, ErrNoRate) {
+		t.Errorf("Expected ErrNoRate before the first price, got %v", err)
+	}
+	if _, err := rates.Rate("GBP"); !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("Expected ErrUnknownCurrency, got %v", err)
+	}
+
+	if !rates.update("EURUSDT", "1.25") || !rates.update("USDTJPY", "150") || rates.update("BTCUSDT", "50000") {
+		t.Fatal("Expected only the conversion pairs to update rates")
+	}
+	if rate, _ := rates.Rate("JPY"); rate != 150 {
+		t.Errorf("Expected 150 JPY per USDT, got %v", rate)
+	}
+
+	prices := []*PriceUpdate{
+		{Symbol: "BTCUSDT", Price: 50000, Change: 500, ChangePercent: 1},
+		{Symbol: "ETHBTC", Price: 0.05},
+	}
+	converted, err := rates.Convert(prices, "EUR")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if btc := converted[0]; btc.Price != 40000 || btc.Change != 400 || btc.ChangePercent != 1 || btc.Currency != "EUR" {
+		t.Errorf("Unexpected converted price %+v", btc)
+	}
+	if eth := converted[1]; eth.Price != 0.05 || eth.Currency != "" {
+		t.Errorf("Expected the BTC pair unchanged, got %+v", eth)
+	}
+	if prices[0].Price != 50000 {
+		t.Error("Expected the prices left unchanged")
+	}
+}
+
+// TestConversionPairEvents verifies conversion pairs are streamed along with
+// the symbols and update the rates without being broadcast.
+func TestConversionPairEvents(t *testing.T) {
+	rates, _ := NewFXRates([]string{"EURUSDT", "BTCUSDT"})
+	ingestor := NewIngestor(HubSink(NewHub()), WithSymbols([]string{"BTCUSDT"}), WithConversionRates(rates))
+	if streamed := ingestor.streamSymbols([]string{"BTCUSDT"}); !reflect.DeepEqual(streamed, []string{"BTCUSDT", "EURUSDT"}) {
+		t.Errorf("Expected the conversion pair streamed once more, got %v", streamed)
+	}
+
+	var pendingUpdate *MultiUpdate
+	handler := ingestor.createWebSocketHandler(&pendingUpdate)
+	event := newStatEvent(1000, "1.25")
+	event.Symbol = "EURUSDT"
+	handler(event)
+	if pendingUpdate != nil {
+		t.Fatalf("Expected no update for the conversion pair, got %+v", pendingUpdate.Data)
+	}
+	if rate, err := rates.Rate("EUR"); err != nil || rate != 0.8 {
+		t.Errorf("Expected 0.8 EUR per USDT, got %v, %v", rate, err)
+	}
+
+	// A tracked symbol that is a conversion pair too is broadcast as well
+	handler(newStatEvent(1000, "50000"))
+	if pendingUpdate == nil || len(pendingUpdate.Data) != 1 {
+		t.Fatal("Expected the tracked symbol to be queued")
+	}
+}
+
+// TestPayloadForCurrency verifies a client with a currency receives its
+// price updates converted.
+func TestPayloadForCurrency(t *testing.T) {
+	rates, _ := NewFXRates([]string{"EURUSDT"})
+	rates.update("EURUSDT", "1.25")
+	client := &Client{Currency: "EUR", Rates: rates}
+
+	payload := client.payloadFor([]byte(`{"type":"multi_update","data":[{"symbol":"BTCUSDT","price":50000}]}`), &renderCache{})
+	if prices := decodePrices(t, payload); prices["BTCUSDT"] != 40000 {
+		t.Errorf("Expected the price in EUR, got %s", payload)
+	}
+	if heartbeat := client.payloadFor([]byte(`{"type":"heartbeat"}`), &renderCache{}); string(heartbeat) != `{"type":"heartbeat"}` {
+		t.Errorf("Expected other messages unchanged, got %s", heartbeat)
+	}
+}
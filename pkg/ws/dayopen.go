@@ -0,0 +1,109 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+)
+
+// DayLayout identifies a UTC calendar day for day-open tracking.
+const DayLayout = "2006-01-02"
+
+// DayOpenFetcher returns the opening price of the current UTC day for a
+// symbol.
+type DayOpenFetcher func(ctx context.Context, symbol string) (float64, error)
+
+// WithDayOpenFetcher replaces the Binance REST lookup of UTC day opens.
+func WithDayOpenFetcher(fetch DayOpenFetcher) IngestorOption {
+	return func(i *Ingestor) {
+		i.fetchDayOpen = fetch
+	}
+}
+
+// fetchBinanceDayOpen reads the open of the current daily kline, which
+// Binance aligns to 00:00 UTC.
+func (i *Ingestor) fetchBinanceDayOpen(ctx context.Context, symbol string) (float64, error) {
+	klines, err := i.restClient().NewKlinesService().
+		Symbol(symbol).
+		Interval("1d").
+		Limit(1).
+		Do(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch daily kline for %s: %w", symbol, err)
+	}
+	if len(klines) == 0 {
+		return 0, fmt.Errorf("no daily kline for %s", symbol)
+	}
+
+	open, err := strconv.ParseFloat(klines[0].Open, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid daily open %q for %s: %w", klines[0].Open, symbol, err)
+	}
+	return open, nil
+}
+
+// RefreshDayOpens loads the UTC midnight open of every tracked symbol.
+// It runs on start and at each UTC rollover; symbols whose lookup fails
+// keep using the first price seen that day.
+func (i *Ingestor) RefreshDayOpens(ctx context.Context) {
+	day := i.clock.Now().UTC().Format(DayLayout)
+
+	for _, name := range i.GetSymbols() {
+		open, err := i.fetchDayOpen(ctx, name)
+		if err != nil {
+			log.Printf("⚠ Failed to refresh day open: %v", err)
+			continue
+		}
+
+		i.mu.Lock()
+		if symbol := i.findSymbol(name); symbol != nil {
+			symbol.DayOpen = open
+			symbol.DayOpenDate = day
+		}
+		i.mu.Unlock()
+	}
+}
+
+// applyDayOpen fills the change since UTC midnight of a price update. If no
+// open is known for the update's day yet, its price becomes the open.
+func (i *Ingestor) applyDayOpen(update *PriceUpdate) {
+	day := updateDay(update.EventTime, time.UnixMilli(update.ReceivedAt))
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	symbol := i.findSymbol(update.Symbol)
+	if symbol == nil {
+		return
+	}
+
+	if symbol.DayOpenDate != day {
+		symbol.DayOpen = update.Price
+		symbol.DayOpenDate = day
+	}
+
+	update.SinceMidnightChangePercent = sinceMidnightChange(symbol, update.Price, day)
+}
+
+// sinceMidnightChange returns the percentage change from the symbol's day
+// open, or nil if no open is known for the given day. The caller must hold
+// i.mu.
+func sinceMidnightChange(symbol *Symbol, price float64, day string) *float64 {
+	if symbol.DayOpenDate != day || symbol.DayOpen <= 0 {
+		return nil
+	}
+
+	change := (price - symbol.DayOpen) / symbol.DayOpen * 100
+	return &change
+}
+
+// updateDay returns the UTC day of an update, preferring the exchange event
+// time so late-arriving events count toward the day they happened.
+func updateDay(eventTime int64, receivedAt time.Time) string {
+	if eventTime != 0 {
+		return time.UnixMilli(eventTime).UTC().Format(DayLayout)
+	}
+	return receivedAt.UTC().Format(DayLayout)
+}
@@ -0,0 +1,86 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestApplyDayOpenUsesFirstPriceOfDay verifies the fallback open.
+func TestApplyDayOpenUsesFirstPriceOfDay(t *testing.T) {
+	ingestor := NewIngestor(HubSink(NewHub()), WithSymbols([]string{"BTCUSDT"}))
+	eventTime := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC).UnixMilli()
+
+	first := &PriceUpdate{Symbol: "BTCUSDT", Price: 100, EventTime: eventTime}
+	ingestor.applyDayOpen(first)
+
+	second := &PriceUpdate{Symbol: "BTCUSDT", Price: 110, EventTime: eventTime + 1000}
+	ingestor.applyDayOpen(second)
+
+	if first.SinceMidnightChangePercent == nil || *first.SinceMidnightChangePercent != 0 {
+		t.Errorf("Expected 0%% change for first update, got %v", first.SinceMidnightChangePercent)
+	}
+	if second.SinceMidnightChangePercent == nil || *second.SinceMidnightChangePercent != 10 {
+		t.Errorf("Expected 10%% change, got %v", second.SinceMidnightChangePercent)
+	}
+}
+
+// TestApplyDayOpenRollsOverAtMidnight verifies a new UTC day resets the open.
+func TestApplyDayOpenRollsOverAtMidnight(t *testing.T) {
+	ingestor := NewIngestor(HubSink(NewHub()), WithSymbols([]string{"BTCUSDT"}))
+	midnight := time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)
+
+	ingestor.applyDayOpen(&PriceUpdate{
+		Symbol:    "BTCUSDT",
+		Price:     100,
+		EventTime: midnight.Add(-time.Second).UnixMilli(),
+	})
+
+	update := &PriceUpdate{Symbol: "BTCUSDT", Price: 120, EventTime: midnight.UnixMilli()}
+	ingestor.applyDayOpen(update)
+
+	if update.SinceMidnightChangePercent == nil || *update.SinceMidnightChangePercent != 0 {
+		t.Errorf("Expected 0%% change after rollover, got %v", update.SinceMidnightChangePercent)
+	}
+
+	ingestor.mu.RLock()
+	symbol := ingestor.findSymbol("BTCUSDT")
+	open, day := symbol.DayOpen, symbol.DayOpenDate
+	ingestor.mu.RUnlock()
+
+	if open != 120 || day != "2024-01-16" {
+		t.Errorf("Expected open 120 on 2024-01-16, got %v on %s", open, day)
+	}
+}
+
+// TestRefreshDayOpens verifies fetched opens replace the fallback.
+func TestRefreshDayOpens(t *testing.T) {
+	fetch := func(ctx context.Context, symbol string) (float64, error) {
+		if symbol == "ETHUSDT" {
+			return 0, errors.New("unavailable")
+		}
+		return 200, nil
+	}
+	ingestor := NewIngestor(HubSink(NewHub()),
+		WithSymbols([]string{"BTCUSDT", "ETHUSDT"}),
+		WithDayOpenFetcher(fetch),
+	)
+
+	ingestor.RefreshDayOpens(context.Background())
+
+	update := &PriceUpdate{Symbol: "BTCUSDT", Price: 210, ReceivedAt: time.Now().UnixMilli()}
+	ingestor.applyDayOpen(update)
+
+	if update.SinceMidnightChangePercent == nil || *update.SinceMidnightChangePercent != 5 {
+		t.Errorf("Expected 5%% change from fetched open, got %v", update.SinceMidnightChangePercent)
+	}
+
+	ingestor.mu.RLock()
+	ethDay := ingestor.findSymbol("ETHUSDT").DayOpenDate
+	ingestor.mu.RUnlock()
+
+	if ethDay != "" {
+		t.Errorf("Expected no day open for failed fetch, got %s", ethDay)
+	}
+}
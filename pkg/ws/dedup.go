@@ -0,0 +1,33 @@
+package ws
+
+import "github.com/adshao/go-binance/v2"
+
+// isDuplicateEvent reports whether an event carries nothing new for its
+// symbol: it is not newer than the last applied event, or it repeats the
+// last known values exactly. After a reconnect Binance often replays the
+// last stat event per symbol, which would otherwise reach clients as an
+// artificial update and reset their staleness indicators. Events for
+// symbols restored from a snapshot always pass so they are marked live.
+// Suppressed events are counted per symbol.
+func (i *Ingestor) isDuplicateEvent(event *binance.WsMarketStatEvent) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	symbol := i.findSymbol(event.Symbol)
+	if symbol == nil || symbol.Restored || symbol.LastEventTime == 0 {
+		return false
+	}
+
+	stale := event.Time <= symbol.LastEventTime
+	repeated := event.LastPrice == symbol.LastPrice &&
+		event.PriceChange == symbol.LastPriceChange &&
+		event.PriceChangePercent == symbol.LastChange &&
+		event.BaseVolume == symbol.LastVolume
+	if !stale && !repeated {
+		return false
+	}
+
+	symbol.DuplicatesSuppressed++
+	i.metrics.duplicateEvents.Inc()
+	return true
+}
@@ -0,0 +1,96 @@
+package ws
+
+import (
+	"testing"
+
+	"github.com/adshao/go-binance/v2"
+)
+
+// newStatEvent creates a valid BTCUSDT stat event.
+func newStatEvent(eventTime int64, price string) *binance.WsMarketStatEvent {
+	return &binance.WsMarketStatEvent{
+		Symbol:             "BTCUSDT",
+		Time:               eventTime,
+		LastPrice:          price,
+		PriceChange:        "100.00",
+		PriceChangePercent: "0.20",
+		BaseVolume:         "1000",
+	}
+}
+
+// TestDuplicateEventIsSuppressed verifies a replayed event after a reconnect
+// does not reach clients.
+func TestDuplicateEventIsSuppressed(t *testing.T) {
+	tests := []struct {
+		name  string
+		event *binance.WsMarketStatEvent
+	}{
+		{"same values, newer event time", newStatEvent(2000, "50000.00")},
+		{"same event time", newStatEvent(1000, "50001.00")},
+		{"older event time", newStatEvent(500, "50001.00")},
+	}
+
+	for _, tt := range tests {
+		// Arrange
+		ingestor := NewIngestor(HubSink(NewHub()))
+		var pendingUpdate *MultiUpdate
+		handler := ingestor.createWebSocketHandler(&pendingUpdate)
+		handler(newStatEvent(1000, "50000.00"))
+		pendingUpdate = nil
+
+		// Act
+		handler(tt.event)
+
+		// Assert
+		if pendingUpdate != nil {
+			t.Errorf("%s: expected the event to be suppressed", tt.name)
+		}
+		if stats := ingestor.Stats()[0]; stats.DuplicatesSuppressed != 1 {
+			t.Errorf("%s: expected 1 suppressed duplicate, got %d", tt.name, stats.DuplicatesSuppressed)
+		}
+	}
+}
+
+// TestChangedEventIsNotSuppressed verifies a newer event with new values passes.
+func TestChangedEventIsNotSuppressed(t *testing.T) {
+	// Arrange
+	ingestor := NewIngestor(HubSink(NewHub()))
+	var pendingUpdate *MultiUpdate
+	handler := ingestor.createWebSocketHandler(&pendingUpdate)
+	handler(newStatEvent(1000, "50000.00"))
+	pendingUpdate = nil
+
+	// Act
+	handler(newStatEvent(2000, "50001.00"))
+
+	// Assert
+	if pendingUpdate == nil || len(pendingUpdate.Data) != 1 {
+		t.Fatal("Expected the changed event to be queued")
+	}
+	if pendingUpdate.Data[0].Price != 50001.00 {
+		t.Errorf("Expected price 50001, got %f", pendingUpdate.Data[0].Price)
+	}
+}
+
+// TestRestoredSymbolIsNotSuppressed verifies the first live event for a
+// symbol restored from a snapshot passes even if it repeats the values.
+func TestRestoredSymbolIsNotSuppressed(t *testing.T) {
+	// Arrange
+	ingestor := NewIngestor(HubSink(NewHub()))
+	var pendingUpdate *MultiUpdate
+	handler := ingestor.createWebSocketHandler(&pendingUpdate)
+	handler(newStatEvent(1000, "50000.00"))
+	pendingUpdate = nil
+	ingestor.findSymbol("BTCUSDT").Restored = true
+
+	// Act
+	handler(newStatEvent(2000, "50000.00"))
+
+	// Assert
+	if pendingUpdate == nil {
+		t.Error("Expected the live event for a restored symbol to be queued")
+	}
+	if ingestor.findSymbol("BTCUSDT").Restored {
+		t.Error("Expected the symbol to be marked live")
+	}
+}
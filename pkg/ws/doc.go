@@ -35,6 +35,16 @@
 // Implements throttling to prevent overwhelming clients with high-frequency updates.
 // Uses adshao/go-binance SDK for reliable WebSocket connections with auto-reconnect.
 //
+// # Public API
+//
+// The package lives under pkg/ so other projects can import the Hub, Client
+// and Ingestor, and its exported API is kept backwards compatible. It only
+// depends on other public packages: pkg/clock for WithHubClock, pkg/feedlog
+// for WithFeedHistory, pkg/usage for Client.Usage and pkg/metrics for
+// NewMetrics. Importing it registers no metrics; pass NewMetrics of a
+// registry to WithHubMetrics and WithMetrics to expose them. The HTTP routes
+// and configuration stay in internal/server and cmd/api.
+//
 // # Usage
 //
 // Basic setup:
@@ -44,7 +54,7 @@
 //	go hub.Run()
 //
 //	// Create and start the Ingestor (connects to Binance)
-//	ingestor := ws.NewIngestor(hub)
+//	ingestor := ws.NewIngestor(ws.HubSink(hub))
 //	go ingestor.Start()
 //
 //	// Register WebSocket endpoint
@@ -67,7 +77,7 @@
 //
 // The Ingestor supports throttle interval configuration:
 //
-//	ingestor := ws.NewIngestor(hub,
+//	ingestor := ws.NewIngestor(ws.HubSink(hub),
 //	    ws.WithThrottleInterval(500 * time.Millisecond),
 //	)
 //
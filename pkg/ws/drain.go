@@ -0,0 +1,131 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"macro-analyst/pkg/clock"
+)
+
+// DefaultDrainGracePeriod is how long draining spreads reconnect hints
+// before the remaining connections are closed
+const DefaultDrainGracePeriod = 30 * time.Second
+
+// ErrAlreadyDraining is returned when a drain is already in progress.
+var ErrAlreadyDraining = errors.New("hub is already draining")
+
+// ReconnectHint is sent to each client while the server drains for
+// maintenance, asking it to reconnect to another host before its
+// connection is closed.
+type ReconnectHint struct {
+	Type    string `json:"type"`             // Always "reconnect_to"
+	URL     string `json:"url,omitempty"`    // Alternate host to reconnect to; empty means retry the same URL later
+	Region  string `json:"region,omitempty"` // Deployment region of the draining server
+	CloseAt int64  `json:"closeAt"`          // Unix ms when the connection will be closed
+}
+
+// Drain stops the Hub from accepting new clients and spreads reconnect
+// hints to the connected clients evenly over the grace period, so they do
+// not all reconnect to the alternate host at once. Clients still connected
+// when the grace period ends are disconnected. It returns the number of
+// clients being drained.
+func (h *Hub) Drain(reconnectTo string, grace time.Duration) (int, error) {
+	if !h.draining.CompareAndSwap(false, true) {
+		return 0, ErrAlreadyDraining
+	}
+
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(h.clients))
+	for client := range h.clients {
+		clients = append(clients, client)
+	}
+	h.mu.RUnlock()
+
+	start := h.clock.Now()
+	hint, err := reconnectHint(reconnectTo, h.region, start.Add(grace))
+	if err != nil {
+		h.draining.Store(false)
+		return 0, err
+	}
+
+	log.Printf("Draining %d clients over %s (reconnect to %q)", len(clients), grace, reconnectTo)
+	go h.runDrain(clients, hint, start, grace)
+
+	return len(clients), nil
+}
+
+// runDrain sends the hint to each client at its slot in the grace period,
+// then disconnects the clients that are still connected.
+func (h *Hub) runDrain(clients []*Client, hint []byte, start time.Time, grace time.Duration) {
+	for idx, client := range clients {
+		sleepUntil(h.clock, drainSlot(start, grace, idx, len(clients)))
+		h.sendTo(client, hint)
+	}
+
+	sleepUntil(h.clock, start.Add(grace))
+
+	remaining := 0
+	for _, client := range clients {
+		if h.isRegistered(client) {
+			remaining++
+			h.unregister <- client
+		}
+	}
+	log.Printf("Drain complete, closed %d remaining clients", remaining)
+}
+
+// reconnectHint encodes the hint sent to draining clients.
+func reconnectHint(reconnectTo, region string, closeAt time.Time) ([]byte, error) {
+	return json.Marshal(&ReconnectHint{
+		Type:    "reconnect_to",
+		URL:     reconnectTo,
+		Region:  region,
+		CloseAt: closeAt.UnixMilli(),
+	})
+}
+
+// drainSlot returns when the idx-th of count clients gets its hint, so the
+// hints are spread evenly over the grace period.
+func drainSlot(start time.Time, grace time.Duration, idx, count int) time.Time {
+	return start.Add(grace * time.Duration(idx) / time.Duration(count))
+}
+
+// sleepUntil blocks until the clock reaches t.
+func sleepUntil(clk clock.Clock, t time.Time) {
+	wait := t.Sub(clk.Now())
+	if wait <= 0 {
+		return
+	}
+	timer := clk.NewTimer(wait)
+	<-timer.C()
+}
+
+// sendTo delivers a message to a single client if it is still connected.
+// The send happens under the read lock so the Run loop cannot close the
+// client's channel concurrently.
+func (h *Hub) sendTo(client *Client, message []byte) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if !h.clients[client] {
+		return
+	}
+	if client.Encoding == EncodingNDJSON {
+		message = toNDJSON(message)
+	}
+	client.enqueue(message, h.clock.Now())
+}
+
+// isRegistered reports whether the client is still connected to the Hub.
+func (h *Hub) isRegistered(client *Client) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.clients[client]
+}
+
+// Draining reports whether the Hub is draining and refuses new clients.
+func (h *Hub) Draining() bool {
+	return h.draining.Load()
+}
@@ -0,0 +1,125 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"macro-analyst/pkg/clock"
+)
+
+// TestDrainSendsHintsAndClosesClients verifies the reconnect hints are
+// spread over the grace period on the hub clock and the clients are
+// disconnected once it ends.
+func TestDrainSendsHintsAndClosesClients(t *testing.T) {
+	// Arrange
+	start := time.Unix(1000, 0)
+	clk := clock.NewFake(start)
+	hub := NewHub(WithRegion("eu-west-1"), WithHubClock(clk))
+	go hub.Run()
+	clients := []*Client{
+		{Hub: hub, Send: make(chan []byte, 8)},
+		{Hub: hub, Send: make(chan []byte, 8)},
+	}
+	for _, client := range clients {
+		hub.Register() <- client
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	// Act
+	count, err := hub.Drain("wss://standby.example.com/ws/prices", 10*time.Second)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 drained clients, got %d", count)
+	}
+	if !hub.Draining() {
+		t.Error("Expected the hub to be draining")
+	}
+
+	// One hint goes out at once, the other halfway through the grace period
+	var first int
+	var message []byte
+	select {
+	case message = <-clients[0].Send:
+	case message = <-clients[1].Send:
+		first = 1
+	case <-time.After(time.Second):
+		t.Fatal("Expected a reconnect hint")
+	}
+	checkHint(t, message, start.Add(10*time.Second))
+
+	second := clients[1-first]
+	clk.BlockUntil(2) // Backpressure ticker and the drain timer
+	select {
+	case message := <-second.Send:
+		t.Fatalf("Expected no hint before its slot, got %s", message)
+	default:
+	}
+	clk.Advance(5 * time.Second)
+	select {
+	case message = <-second.Send:
+	case <-time.After(time.Second):
+		t.Fatal("Expected a reconnect hint halfway through the grace period")
+	}
+	checkHint(t, message, start.Add(10*time.Second))
+
+	clk.BlockUntil(2)
+	for idx, client := range clients {
+		if !hub.isRegistered(client) {
+			t.Errorf("Client %d: expected to stay connected until the grace period ends", idx)
+		}
+	}
+	clk.Advance(5 * time.Second)
+
+	for idx, client := range clients {
+		select {
+		case _, ok := <-client.Send:
+			if ok {
+				t.Errorf("Client %d: expected the send channel to be closed", idx)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("Client %d: expected to be disconnected after the grace period", idx)
+		}
+	}
+}
+
+// checkHint verifies a reconnect hint sent to a draining client.
+func checkHint(t *testing.T, message []byte, closeAt time.Time) {
+	t.Helper()
+
+	var hint ReconnectHint
+	if err := json.Unmarshal(message, &hint); err != nil {
+		t.Fatalf("Failed to decode hint: %v", err)
+	}
+	if hint.Type != "reconnect_to" || hint.URL != "wss://standby.example.com/ws/prices" {
+		t.Errorf("Unexpected hint %+v", hint)
+	}
+	if hint.Region != "eu-west-1" {
+		t.Errorf("Expected region eu-west-1, got %s", hint.Region)
+	}
+	if hint.CloseAt != closeAt.UnixMilli() {
+		t.Errorf("Expected closeAt %d, got %d", closeAt.UnixMilli(), hint.CloseAt)
+	}
+}
+
+// TestDrainTwice verifies a second drain is rejected.
+func TestDrainTwice(t *testing.T) {
+	// Arrange
+	hub := NewHub()
+	if _, err := hub.Drain("", time.Millisecond); err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+
+	// Act
+	_, err := hub.Drain("", time.Millisecond)
+
+	// Assert
+	if !errors.Is(err, ErrAlreadyDraining) {
+		t.Errorf("Expected ErrAlreadyDraining, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package ws
+
+import (
+	"sync"
+	"time"
+)
+
+// DropReason classifies why a message did not reach a client.
+type DropReason string
+
+const (
+	// DropChannelFull is a message rejected by Publish because the
+	// broadcast channel was full
+	DropChannelFull DropReason = "channel_full"
+
+	// DropExpired is a message that waited longer than the message TTL in
+	// the broadcast channel, or a price update older than the maximum
+	// message age when a client's turn to write it came
+	DropExpired DropReason = "expired"
+
+	// DropSlowClient is a message discarded or held back for a client whose
+	// send buffer was full, counted once per client
+	DropSlowClient DropReason = "slow_client"
+
+	// DropNoSubscribers is a broadcast while no client was connected
+	DropNoSubscribers DropReason = "no_subscribers"
+)
+
+// DropReasons lists the reasons drops are counted by.
+var DropReasons = []DropReason{DropChannelFull, DropExpired, DropSlowClient, DropNoSubscribers}
+
+// DropWindow is the period DropSummary covers, kept in one minute buckets.
+const DropWindow = time.Hour
+
+const dropBucketCount = int(DropWindow / time.Minute)
+
+// DropSummary counts the drops of a Hub over the last DropWindow.
+type DropSummary struct {
+	Since   time.Time             `json:"since"`
+	Total   uint64                `json:"total"`
+	Reasons map[DropReason]uint64 `json:"reasons"` // Every reason, including those without drops
+}
+
+// dropBucket holds the drops of one minute.
+type dropBucket struct {
+	minute int64 // Unix minute the counts belong to
+	counts map[DropReason]uint64
+}
+
+// dropLog keeps per-minute drop counts of the last DropWindow in a ring.
+type dropLog struct {
+	mu      sync.Mutex
+	buckets [dropBucketCount]dropBucket
+}
+
+// recordDrop counts a message dropped for reason.
+func (h *Hub) recordDrop(reason DropReason) {
+	h.metrics.droppedMessages[reason].Inc()
+
+	minute := h.clock.Now().Unix() / 60
+
+	h.drops.mu.Lock()
+	defer h.drops.mu.Unlock()
+
+	bucket := &h.drops.buckets[minute%int64(dropBucketCount)]
+	if bucket.minute != minute || bucket.counts == nil {
+		*bucket = dropBucket{minute: minute, counts: make(map[DropReason]uint64)}
+	}
+	bucket.counts[reason]++
+}
+
+// DropSummary returns the drops of the last DropWindow by reason.
+func (h *Hub) DropSummary() DropSummary {
+	now := h.clock.Now()
+	minute := now.Unix() / 60
+
+	summary := DropSummary{
+		Since:   now.Add(-DropWindow),
+		Reasons: make(map[DropReason]uint64, len(DropReasons)),
+	}
+	for _, reason := range DropReasons {
+		summary.Reasons[reason] = 0
+	}
+
+	h.drops.mu.Lock()
+	defer h.drops.mu.Unlock()
+
+	for _, bucket := range h.drops.buckets {
+		if bucket.minute <= minute-int64(dropBucketCount) || bucket.minute > minute {
+			continue
+		}
+		for reason, count := range bucket.counts {
+			summary.Reasons[reason] += count
+			summary.Total += count
+		}
+	}
+	return summary
+}
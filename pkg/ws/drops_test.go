@@ -0,0 +1,43 @@
+package ws
+
+import (
+	"testing"
+	"time"
+
+	"macro-analyst/pkg/clock"
+)
+
+// TestDropSummary verifies drops are counted by reason and age out of the
+// summary after an hour.
+func TestDropSummary(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 2, 20, 12, 0, 0, 0, time.UTC))
+	hub := NewHub(WithHubClock(clk), WithSlowClientPolicy(SlowClientDropNewest))
+
+	hub.broadcastMessage([]byte(`{"type":"heartbeat"}`))
+	clk.Advance(30 * time.Minute)
+
+	client := &Client{Send: make(chan []byte, 1)}
+	hub.clients[client] = true
+	hub.broadcastMessage([]byte(`{"type":"heartbeat"}`))
+	hub.broadcastMessage([]byte(`{"type":"heartbeat"}`))
+	hub.recordDrop(DropExpired)
+
+	summary := hub.DropSummary()
+	if summary.Total != 3 || summary.Reasons[DropNoSubscribers] != 1 ||
+		summary.Reasons[DropSlowClient] != 1 || summary.Reasons[DropExpired] != 1 {
+		t.Errorf("Expected one drop per reason, got %+v", summary)
+	}
+	if summary.Reasons[DropChannelFull] != 0 || len(summary.Reasons) != len(DropReasons) {
+		t.Errorf("Expected every reason in the summary, got %+v", summary.Reasons)
+	}
+
+	clk.Advance(45 * time.Minute)
+	if summary := hub.DropSummary(); summary.Total != 2 || summary.Reasons[DropNoSubscribers] != 0 {
+		t.Errorf("Expected drops older than an hour to age out, got %+v", summary)
+	}
+
+	clk.Advance(2 * time.Hour)
+	if summary := hub.DropSummary(); summary.Total != 0 {
+		t.Errorf("Expected no drops, got %+v", summary)
+	}
+}
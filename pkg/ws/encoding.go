@@ -0,0 +1,84 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// ProtocolVersion is the version of the WebSocket message protocol. It is
+// raised when messages or commands change in ways existing clients cannot
+// handle; new message types and fields do not raise it.
+const ProtocolVersion = 1
+
+// Encoding identifies the wire format a client negotiated at connect time.
+type Encoding string
+
+const (
+	// EncodingJSON sends each broadcast as a single JSON document (default)
+	EncodingJSON Encoding = "json"
+
+	// EncodingNDJSON sends one JSON line per symbol instead of a
+	// multi_update array, for line-oriented consumers
+	EncodingNDJSON Encoding = "ndjson"
+
+	// EncodingMsgPack sends each message as a binary MessagePack frame with
+	// the structure of the JSON document, for bandwidth-sensitive clients
+	EncodingMsgPack Encoding = "msgpack"
+)
+
+// Subprotocols lists the Sec-WebSocket-Protocol values the server accepts,
+// in order of server preference.
+var Subprotocols = []string{string(EncodingJSON), string(EncodingNDJSON), string(EncodingMsgPack)}
+
+// EncodingFromSubprotocol maps a negotiated subprotocol to an Encoding,
+// defaulting to JSON when none was negotiated.
+func EncodingFromSubprotocol(subprotocol string) Encoding {
+	switch Encoding(subprotocol) {
+	case EncodingNDJSON, EncodingMsgPack:
+		return Encoding(subprotocol)
+	}
+	return EncodingJSON
+}
+
+// ParseEncoding validates an encoding requested by name, e.g. in a query
+// parameter. An empty name returns an empty Encoding, leaving the
+// negotiated subprotocol in effect.
+func ParseEncoding(name string) (Encoding, error) {
+	if name == "" || slices.Contains(Subprotocols, name) {
+		return Encoding(name), nil
+	}
+	return "", fmt.Errorf("invalid encoding %q (expected one of %v)", name, Subprotocols)
+}
+
+// ndjsonPriceLine is a single NDJSON line for one symbol's price update.
+type ndjsonPriceLine struct {
+	Type string `json:"type"` // Always "price_update"
+	*PriceUpdate
+}
+
+// toNDJSON renders a broadcast message as newline-delimited JSON.
+// A multi_update becomes one line per symbol; any other message is
+// passed through as a single line.
+func toNDJSON(message []byte) []byte {
+	var update MultiUpdate
+	if err := json.Unmarshal(message, &update); err != nil || update.Type != "multi_update" {
+		line := bytes.TrimSpace(message)
+		out := make([]byte, len(line), len(line)+1)
+		copy(out, line)
+		return append(out, '\n')
+	}
+
+	var buf bytes.Buffer
+	for _, priceUpdate := range update.Data {
+		line, err := json.Marshal(ndjsonPriceLine{Type: "price_update", PriceUpdate: priceUpdate})
+		if err != nil {
+			continue
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes()
+}
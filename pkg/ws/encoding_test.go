@@ -0,0 +1,129 @@
+package ws
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestEncodingFromSubprotocol verifies subprotocol negotiation mapping.
+func TestEncodingFromSubprotocol(t *testing.T) {
+	tests := []struct {
+		subprotocol string
+		expected    Encoding
+	}{
+		{"", EncodingJSON},
+		{"json", EncodingJSON},
+		{"ndjson", EncodingNDJSON},
+		{"msgpack", EncodingMsgPack},
+		{"unknown", EncodingJSON},
+	}
+
+	for _, tt := range tests {
+		if got := EncodingFromSubprotocol(tt.subprotocol); got != tt.expected {
+			t.Errorf("Subprotocol %q: expected %s, got %s", tt.subprotocol, tt.expected, got)
+		}
+	}
+}
+
+// TestToNDJSONMultiUpdate verifies one line is written per symbol.
+func TestToNDJSONMultiUpdate(t *testing.T) {
+	message, _ := json.Marshal(&MultiUpdate{
+		Type: "multi_update",
+		Data: []*PriceUpdate{
+			{Symbol: "BTCUSDT", Price: 50000},
+			{Symbol: "ETHUSDT", Price: 3000},
+		},
+	})
+
+	lines := strings.Split(strings.TrimSuffix(string(toNDJSON(message)), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d: %q", len(lines), lines)
+	}
+
+	var line struct {
+		Type   string  `json:"type"`
+		Symbol string  `json:"symbol"`
+		Price  float64 `json:"price"`
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &line); err != nil {
+		t.Fatalf("Failed to decode line: %v", err)
+	}
+
+	if line.Type != "price_update" || line.Symbol != "ETHUSDT" || line.Price != 3000 {
+		t.Errorf("Unexpected line: %+v", line)
+	}
+}
+
+// TestToNDJSONPassthrough verifies other messages become a single line.
+func TestToNDJSONPassthrough(t *testing.T) {
+	message := []byte(`{"type":"feed_status","status":"stale"}`)
+
+	out := toNDJSON(message)
+	if string(out) != string(message)+"\n" {
+		t.Errorf("Expected message as a single line, got %q", out)
+	}
+
+	// The original message must not be modified
+	if string(message) != `{"type":"feed_status","status":"stale"}` {
+		t.Error("toNDJSON modified its input")
+	}
+}
+
+// TestHubBroadcastPerEncoding verifies each client receives its negotiated format.
+func TestHubBroadcastPerEncoding(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	jsonClient := &Client{Hub: hub, Send: make(chan []byte, 256)}
+	ndjsonClient := &Client{Hub: hub, Send: make(chan []byte, 256), Encoding: EncodingNDJSON}
+	hub.register <- jsonClient
+	hub.register <- ndjsonClient
+
+	message, _ := json.Marshal(&MultiUpdate{
+		Type: "multi_update",
+		Data: []*PriceUpdate{{Symbol: "BTCUSDT"}, {Symbol: "ETHUSDT"}},
+	})
+	hub.Publish(message)
+
+	select {
+	case msg := <-jsonClient.Send:
+		if string(msg) != string(message) {
+			t.Errorf("JSON client: expected original message, got %s", msg)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Error("JSON client: timeout waiting for message")
+	}
+
+	select {
+	case msg := <-ndjsonClient.Send:
+		if strings.Count(string(msg), "\n") != 2 {
+			t.Errorf("NDJSON client: expected 2 lines, got %q", msg)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Error("NDJSON client: timeout waiting for message")
+	}
+}
+
+// TestThrottledNDJSONCoalesced verifies throttled NDJSON clients receive JSON
+// updates to coalesce, written as one line per symbol.
+func TestThrottledNDJSONCoalesced(t *testing.T) {
+	client := &Client{Encoding: EncodingNDJSON, RefreshInterval: 2 * time.Second}
+	message, _ := json.Marshal(&MultiUpdate{
+		Type: "multi_update",
+		Data: []*PriceUpdate{{Symbol: "BTCUSDT", Price: 50000}, {Symbol: "ETHUSDT", Price: 3000}},
+	})
+
+	prices := newCoalescer(0, NumbersFloat)
+	payload := client.payloadFor(message, &renderCache{})
+	if !prices.add(payload) {
+		t.Fatalf("Expected a JSON update to coalesce, got %q", payload)
+	}
+	flushed, _ := prices.flush(time.Now())
+
+	lines := strings.Split(strings.TrimSuffix(string(toNDJSON(flushed)), "\n"), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"type":"price_update"`) {
+		t.Errorf("Expected one line per symbol, got %q", lines)
+	}
+}
@@ -0,0 +1,537 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"macro-analyst/pkg/clock"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/gorilla/websocket"
+)
+
+// Names of the exchanges prices are streamed from, tagged on the price
+// updates of ingestors aggregating several of them
+const (
+	ExchangeBinance  = "binance"
+	ExchangeCoinbase = "coinbase"
+	ExchangeKraken   = "kraken"
+)
+
+const (
+	// CoinbaseStreamURL is the Coinbase Advanced Trade market data stream
+	CoinbaseStreamURL = "wss://advanced-trade-ws.coinbase.com"
+
+	// KrakenStreamURL is the Kraken v2 public stream
+	KrakenStreamURL = "wss://ws.kraken.com/v2"
+
+	// ExchangeReconnectDelay is how long an ingestor waits before
+	// reconnecting an exchange source whose connection failed or closed
+	ExchangeReconnectDelay = 5 * time.Second
+
+	// exchangeEventBuffer is the buffer of an exchange source's events;
+	// events are dropped while the ingestor falls further behind
+	exchangeEventBuffer = 256
+)
+
+// ErrUnknownExchange is returned for exchanges without an ExchangeSource.
+var ErrUnknownExchange = errors.New("unknown exchange")
+
+// Exchanges lists the exchanges NewExchangeSource connects to.
+var Exchanges = []string{ExchangeBinance, ExchangeCoinbase, ExchangeKraken}
+
+// ExchangeSource streams the ticker prices of an exchange as price updates
+// keyed by Binance symbols such as BTCUSDT.
+//
+// Connect opens a connection, and Subscribe replaces the symbols it streams.
+// Events returns the updates of the current connection, closed once the
+// connection is lost or closed by Close.
+type ExchangeSource interface {
+	Name() string
+	Connect(ctx context.Context) error
+	Subscribe(symbols []string) error
+	Events() <-chan *PriceUpdate
+	Close() error
+}
+
+// NewExchangeSource returns the ExchangeSource of an exchange by name, or
+// ErrUnknownExchange.
+func NewExchangeSource(name string) (ExchangeSource, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case ExchangeBinance:
+		return NewBinanceSource(), nil
+	case ExchangeCoinbase:
+		return NewCoinbaseSource(), nil
+	case ExchangeKraken:
+		return NewKrakenSource(), nil
+	}
+	return nil, fmt.Errorf("%w %q, expected one of %v", ErrUnknownExchange, name, Exchanges)
+}
+
+// ParseExchanges returns the ExchangeSources of the named exchanges.
+func ParseExchanges(names []string) ([]ExchangeSource, error) {
+	sources := make([]ExchangeSource, 0, len(names))
+	for _, name := range names {
+		source, err := NewExchangeSource(name)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// WithExchanges makes the ingestor aggregate the prices of other exchanges
+// with its own: in ticker mode it streams its symbols from each source and
+// broadcasts their updates alongside its Binance ones, each tagged with the
+// name of its exchange. Only the ingestor's own prices are cached, snapshot
+// and checked against alerts.
+func WithExchanges(sources ...ExchangeSource) IngestorOption {
+	return func(i *Ingestor) {
+		i.exchanges = append(i.exchanges, sources...)
+	}
+}
+
+// startExchanges streams the tracked symbols from the exchange sources into
+// the pending update until the ingestor stops.
+func (i *Ingestor) startExchanges(pendingUpdate **MultiUpdate) {
+	for _, source := range i.exchanges {
+		changed := make(chan struct{}, 1)
+		i.mu.Lock()
+		i.exchangeSignals = append(i.exchangeSignals, changed)
+		i.mu.Unlock()
+
+		go i.runExchange(source, changed, pendingUpdate)
+	}
+}
+
+// runExchange keeps an exchange source connected and subscribed to the
+// tracked symbols, reconnecting after ExchangeReconnectDelay whenever
+// connecting fails or the connection is lost.
+func (i *Ingestor) runExchange(source ExchangeSource, changed <-chan struct{}, pendingUpdate **MultiUpdate) {
+	defer source.Close()
+
+	for {
+		err := source.Connect(i.ctx)
+		if err == nil {
+			err = source.Subscribe(i.GetSymbols())
+		}
+		if err == nil {
+			log.Printf("Connected to %s for %d symbols", source.Name(), len(i.GetSymbols()))
+			if !i.forwardExchange(source, changed, pendingUpdate) {
+				return
+			}
+			log.Printf("⚠ %s connection lost", source.Name())
+		} else {
+			log.Printf("Failed to connect to %s: %v", source.Name(), err)
+		}
+
+		timer := i.clock.NewTimer(ExchangeReconnectDelay)
+		select {
+		case <-i.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+	}
+}
+
+// forwardExchange queues the updates of a connected exchange source for
+// broadcast and resubscribes it whenever the symbols change. It reports
+// false once the ingestor stopped, true when the connection was lost.
+func (i *Ingestor) forwardExchange(source ExchangeSource, changed <-chan struct{}, pendingUpdate **MultiUpdate) bool {
+	events := source.Events()
+	for {
+		select {
+		case <-i.ctx.Done():
+			return false
+		case <-changed:
+			if err := source.Subscribe(i.GetSymbols()); err != nil {
+				log.Printf("Failed to resubscribe %s: %v", source.Name(), err)
+				source.Close()
+			}
+		case update, ok := <-events:
+			if !ok {
+				return true
+			}
+			i.queueExchangeUpdate(source.Name(), update, pendingUpdate)
+		}
+	}
+}
+
+// queueExchangeUpdate tags an update of an exchange source and queues it,
+// unless its symbol is no longer tracked.
+func (i *Ingestor) queueExchangeUpdate(exchange string, update *PriceUpdate, pendingUpdate **MultiUpdate) {
+	i.mu.RLock()
+	tracked := i.findSymbol(update.Symbol) != nil
+	i.mu.RUnlock()
+	if !tracked {
+		return
+	}
+
+	update.Exchange = exchange
+	update.Timestamp = i.formatTimestamp(update.EventTime, time.UnixMilli(update.ReceivedAt))
+	i.queuePriceUpdate(pendingUpdate, update)
+}
+
+// signalExchanges tells the exchange sources to resubscribe to the tracked
+// symbols. The caller must hold i.mu.
+func (i *Ingestor) signalExchanges() {
+	for _, changed := range i.exchangeSignals {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// splitSymbol returns the base and quote assets of a Binance symbol.
+func splitSymbol(symbol string) (base, quote string, ok bool) {
+	for _, quote := range quoteAssets {
+		if len(symbol) > len(quote) && strings.HasSuffix(symbol, quote) {
+			return strings.TrimSuffix(symbol, quote), quote, true
+		}
+	}
+	return "", "", false
+}
+
+// binanceSource streams Binance ticker prices as an ExchangeSource. It also
+// opens the combined ticker stream of an ingestor's own Binance feed (see
+// marketStats), so every Binance ticker stream is served the same way.
+type binanceSource struct {
+	clock clock.Clock
+	serve marketStatServer
+
+	mu     sync.Mutex
+	events chan *PriceUpdate
+	stopC  chan struct{} // Stops the stream of the current subscription
+}
+
+// marketStatServer opens a Binance combined ticker stream of symbols, e.g.
+// binance.WsCombinedMarketStatServe.
+type marketStatServer func(symbols []string, handler binance.WsMarketStatHandler, errHandler binance.ErrHandler) (doneC, stopC chan struct{}, err error)
+
+// NewBinanceSource creates an ExchangeSource of Binance ticker prices, e.g.
+// to aggregate them into a feed that does not stream from Binance itself.
+func NewBinanceSource() ExchangeSource {
+	return newBinanceSource()
+}
+
+// newBinanceSource creates a binanceSource streaming from Binance.
+func newBinanceSource() *binanceSource {
+	return &binanceSource{clock: clock.Real, serve: binance.WsCombinedMarketStatServe}
+}
+
+// marketStats returns a streamServer of the source's combined ticker
+// stream passing the raw events to handler, for an ingestor that needs
+// more of an event than the price update of Events carries.
+func (s *binanceSource) marketStats(handler binance.WsMarketStatHandler, errHandler binance.ErrHandler) streamServer {
+	return func(symbols []string) (chan struct{}, chan struct{}, error) {
+		return s.serve(symbols, handler, errHandler)
+	}
+}
+
+// Name returns ExchangeBinance.
+func (s *binanceSource) Name() string {
+	return ExchangeBinance
+}
+
+// Connect prepares a new connection. Binance combined streams connect per
+// subscription, so the stream opens on Subscribe.
+func (s *binanceSource) Connect(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = make(chan *PriceUpdate, exchangeEventBuffer)
+	return nil
+}
+
+// Subscribe replaces the stream with one of the given symbols.
+func (s *binanceSource) Subscribe(symbols []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.events == nil {
+		return errors.New("binance source is not connected")
+	}
+	if s.stopC != nil {
+		close(s.stopC)
+		s.stopC = nil
+	}
+	if len(symbols) == 0 {
+		return nil
+	}
+
+	var stopC chan struct{}
+	handler := func(event *binance.WsMarketStatEvent) {
+		update, err := convertMarketStatEvent(event, s.clock.Now())
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.stopC == stopC {
+			trySend(s.events, update)
+		}
+	}
+	errHandler := func(err error) {
+		log.Printf("Binance source error: %v", err)
+	}
+
+	doneC, stopC, err := s.serve(symbols, handler, errHandler)
+	if err != nil {
+		return err
+	}
+	s.stopC = stopC
+
+	// A stream closing by itself ends the connection
+	go func() {
+		<-doneC
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.stopC == stopC {
+			s.stopC = nil
+			close(s.events)
+			s.events = nil
+		}
+	}()
+	return nil
+}
+
+// Events returns the updates of the current connection.
+func (s *binanceSource) Events() <-chan *PriceUpdate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.events
+}
+
+// Close stops the stream and closes Events.
+func (s *binanceSource) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopC != nil {
+		close(s.stopC)
+		s.stopC = nil
+	}
+	if s.events != nil {
+		close(s.events)
+		s.events = nil
+	}
+	return nil
+}
+
+// trySend sends an update without blocking, dropping it if the buffer is
+// full.
+func trySend(events chan *PriceUpdate, update *PriceUpdate) {
+	select {
+	case events <- update:
+	default:
+	}
+}
+
+// ExchangeConn is a WebSocket connection to an exchange.
+type ExchangeConn interface {
+	ReadMessage() (messageType int, data []byte, err error)
+	WriteJSON(v any) error
+	Close() error
+}
+
+// exchangeTicker is a ticker price read from an exchange stream.
+type exchangeTicker struct {
+	product       string // Exchange product, e.g. "BTC-USD"
+	price         float64
+	changePercent float64
+	volume        float64
+	eventTime     int64 // Unix ms, zero if the exchange sends none
+}
+
+// exchangeProtocol is the ticker channel protocol of an exchange stream.
+type exchangeProtocol interface {
+	// product returns the exchange product of a Binance symbol
+	product(symbol string) (string, bool)
+
+	// subscribe and unsubscribe return the messages changing the
+	// subscription to the ticker channel of products
+	subscribe(products []string) any
+	unsubscribe(products []string) any
+
+	// parse returns the tickers of a message, none for other messages
+	parse(message []byte) ([]exchangeTicker, error)
+}
+
+// streamSource is an ExchangeSource of the ticker channel of an exchange
+// WebSocket stream.
+type streamSource struct {
+	name     string
+	url      string
+	protocol exchangeProtocol
+	dial     func(ctx context.Context, url string) (ExchangeConn, error)
+	clock    clock.Clock
+
+	mu       sync.Mutex
+	conn     ExchangeConn
+	events   chan *PriceUpdate
+	products map[string]string // Subscribed symbols by exchange product
+}
+
+// newStreamSource creates an ExchangeSource streaming from url.
+func newStreamSource(name, url string, protocol exchangeProtocol) *streamSource {
+	return &streamSource{
+		name:     name,
+		url:      url,
+		protocol: protocol,
+		dial:     dialExchange,
+		clock:    clock.Real,
+	}
+}
+
+// dialExchange connects to an exchange stream.
+func dialExchange(ctx context.Context, url string) (ExchangeConn, error) {
+	ctx, cancel := context.WithTimeout(ctx, RawStreamDialTimeout)
+	defer cancel()
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Name returns the name of the exchange.
+func (s *streamSource) Name() string {
+	return s.name
+}
+
+// Connect opens a connection and starts reading its tickers.
+func (s *streamSource) Connect(ctx context.Context) error {
+	conn, err := s.dial(ctx, s.url)
+	if err != nil {
+		return err
+	}
+	events := make(chan *PriceUpdate, exchangeEventBuffer)
+
+	s.mu.Lock()
+	s.conn, s.events, s.products = conn, events, nil
+	s.mu.Unlock()
+
+	go s.readTickers(conn, events)
+	return nil
+}
+
+// readTickers converts the tickers of a connection to price updates until
+// it closes, then closes its events.
+func (s *streamSource) readTickers(conn ExchangeConn, events chan *PriceUpdate) {
+	defer close(events)
+	defer conn.Close()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		tickers, err := s.protocol.parse(message)
+		if err != nil {
+			log.Printf("⚠ Skipping %s message: %v", s.name, err)
+			continue
+		}
+		now := s.clock.Now()
+		for _, ticker := range tickers {
+			s.mu.Lock()
+			symbol, ok := s.products[ticker.product]
+			s.mu.Unlock()
+			if !ok || ticker.price <= 0 {
+				continue
+			}
+			trySend(events, ticker.update(symbol, now))
+		}
+	}
+}
+
+// Subscribe unsubscribes the products of symbols no longer named and
+// subscribes the new ones. Symbols the exchange does not list under a
+// known quote asset are skipped.
+func (s *streamSource) Subscribe(symbols []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return fmt.Errorf("%s source is not connected", s.name)
+	}
+
+	products := make(map[string]string, len(symbols))
+	for _, symbol := range symbols {
+		if product, ok := s.protocol.product(symbol); ok {
+			products[product] = symbol
+		}
+	}
+
+	var added, removed []string
+	for product := range products {
+		if _, ok := s.products[product]; !ok {
+			added = append(added, product)
+		}
+	}
+	for product := range s.products {
+		if _, ok := products[product]; !ok {
+			removed = append(removed, product)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+
+	if len(removed) > 0 {
+		if err := s.conn.WriteJSON(s.protocol.unsubscribe(removed)); err != nil {
+			return err
+		}
+	}
+	if len(added) > 0 {
+		if err := s.conn.WriteJSON(s.protocol.subscribe(added)); err != nil {
+			return err
+		}
+	}
+	s.products = products
+	return nil
+}
+
+// Events returns the updates of the current connection.
+func (s *streamSource) Events() <-chan *PriceUpdate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.events
+}
+
+// Close closes the connection, which closes Events once reading stopped.
+func (s *streamSource) Close() error {
+	s.mu.Lock()
+	conn := s.conn
+	s.conn = nil
+	s.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+	return conn.Close()
+}
+
+// update converts a ticker of a symbol received at now to a price update,
+// deriving the absolute change from the percentage.
+func (t exchangeTicker) update(symbol string, now time.Time) *PriceUpdate {
+	open := t.price / (1 + t.changePercent/100)
+	return &PriceUpdate{
+		Symbol:        symbol,
+		Price:         t.price,
+		Change:        roundDecimals(t.price - open),
+		ChangePercent: t.changePercent,
+		Volume:        int64(t.volume),
+		EventTime:     t.eventTime,
+		ReceivedAt:    now.UnixMilli(),
+	}
+}
@@ -0,0 +1,254 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"macro-analyst/pkg/clock"
+
+	"github.com/adshao/go-binance/v2"
+)
+
+// fakeExchangeConn is an ExchangeConn reading queued messages and recording
+// the messages written.
+type fakeExchangeConn struct {
+	messages chan []byte
+
+	mu      sync.Mutex
+	written []string
+	closed  bool
+}
+
+func newFakeExchangeConn() *fakeExchangeConn {
+	return &fakeExchangeConn{messages: make(chan []byte, 8)}
+}
+
+func (c *fakeExchangeConn) ReadMessage() (int, []byte, error) {
+	message, ok := <-c.messages
+	if !ok {
+		return 0, nil, io.EOF
+	}
+	return 1, message, nil
+}
+
+func (c *fakeExchangeConn) WriteJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.written = append(c.written, string(data))
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *fakeExchangeConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		c.closed = true
+		close(c.messages)
+	}
+	return nil
+}
+
+// TestExchangeProducts verifies Binance symbols map to the products of
+// other exchanges of the same quote asset.
+func TestExchangeProducts(t *testing.T) {
+	tests := []struct {
+		symbol   string
+		coinbase string
+		kraken   string
+	}{
+		{"BTCUSDT", "BTC-USDT", "BTC/USDT"},
+		{"BTCUSDC", "BTC-USDC", "BTC/USDC"},
+		{"ETHBTC", "ETH-BTC", "ETH/BTC"},
+		{"SOLEUR", "SOL-EUR", "SOL/EUR"},
+		{"USDT", "", ""},
+	}
+
+	for _, tt := range tests {
+		if product, _ := (coinbaseProtocol{}).product(tt.symbol); product != tt.coinbase {
+			t.Errorf("%s: expected Coinbase product %q, got %q", tt.symbol, tt.coinbase, product)
+		}
+		if product, _ := (krakenProtocol{}).product(tt.symbol); product != tt.kraken {
+			t.Errorf("%s: expected Kraken pair %q, got %q", tt.symbol, tt.kraken, product)
+		}
+	}
+}
+
+// TestExchangeParse verifies ticker messages of each exchange are parsed
+// and other messages ignored.
+func TestExchangeParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol exchangeProtocol
+		message  string
+		expected []exchangeTicker
+	}{
+		{
+			"coinbase ticker", coinbaseProtocol{},
+			`{"channel":"ticker","timestamp":"2024-01-02T03:04:05.5Z","events":[{"type":"update","tickers":[
+				{"type":"ticker","product_id":"BTC-USD","price":"42000.5","volume_24_h":"1234.5","price_percent_chg_24_h":"5"}]}]}`,
+			[]exchangeTicker{{product: "BTC-USD", price: 42000.5, changePercent: 5, volume: 1234.5, eventTime: 1704164645500}},
+		},
+		{"coinbase subscriptions", coinbaseProtocol{}, `{"channel":"subscriptions","events":[]}`, nil},
+		{
+			"kraken ticker", krakenProtocol{},
+			`{"channel":"ticker","type":"update","data":[{"symbol":"BTC/USD","last":42001,"volume":99.5,"change_pct":-1.5}]}`,
+			[]exchangeTicker{{product: "BTC/USD", price: 42001, changePercent: -1.5, volume: 99.5}},
+		},
+		{"kraken heartbeat", krakenProtocol{}, `{"channel":"heartbeat"}`, nil},
+	}
+
+	for _, tt := range tests {
+		tickers, err := tt.protocol.parse([]byte(tt.message))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if len(tickers) == 0 && len(tt.expected) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(tickers, tt.expected) {
+			t.Errorf("%s: expected %+v, got %+v", tt.name, tt.expected, tickers)
+		}
+	}
+
+	if _, err := (coinbaseProtocol{}).parse([]byte(`{"channel":"ticker","events":[{"tickers":[{"product_id":"BTC-USD","price":"x"}]}]}`)); err == nil {
+		t.Error("Expected an error for an invalid Coinbase price")
+	}
+}
+
+// TestStreamSource verifies a stream source subscribes to the changes of
+// its symbols and converts tickers of subscribed products.
+func TestStreamSource(t *testing.T) {
+	conn := newFakeExchangeConn()
+	source := newStreamSource(ExchangeCoinbase, "wss://example.test", coinbaseProtocol{})
+	source.dial = func(ctx context.Context, url string) (ExchangeConn, error) {
+		return conn, nil
+	}
+
+	if err := source.Subscribe([]string{"BTCUSDT"}); err == nil {
+		t.Error("Expected subscribing before connecting to fail")
+	}
+	if err := source.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	source.Subscribe([]string{"BTCUSDT", "ETHUSDT"})
+	source.Subscribe([]string{"BTCUSDT"})
+
+	expected := []string{
+		`{"channel":"ticker","product_ids":["BTC-USDT","ETH-USDT"],"type":"subscribe"}`,
+		`{"channel":"ticker","product_ids":["ETH-USDT"],"type":"unsubscribe"}`,
+	}
+	if !reflect.DeepEqual(conn.written, expected) {
+		t.Errorf("Expected messages %v, got %v", expected, conn.written)
+	}
+
+	conn.messages <- []byte(`{"channel":"ticker","events":[{"tickers":[
+		{"product_id":"BTC-USD","price":"41900"},
+		{"product_id":"ETH-USDT","price":"2500"},
+		{"product_id":"BTC-USDT","price":"42000","price_percent_chg_24_h":"5"}]}]}`)
+	select {
+	case update := <-source.Events():
+		if update.Symbol != "BTCUSDT" || update.Price != 42000 || update.Change != 2000 {
+			t.Errorf("Expected a BTCUSDT update at 42000 up 2000, got %+v", update)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for an update")
+	}
+
+	source.Close()
+	select {
+	case _, ok := <-source.Events():
+		if ok {
+			t.Error("Expected no further updates")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected Events to close with the connection")
+	}
+}
+
+// TestIngestorAggregatesExchanges verifies updates of exchange sources are
+// tagged and queued next to the Binance ones of the same symbol.
+func TestIngestorAggregatesExchanges(t *testing.T) {
+	ingestor := NewIngestor(HubSink(NewHub()), WithSymbols([]string{"BTCUSDT"}))
+	pending := &MultiUpdate{Type: "multi_update", Data: []*PriceUpdate{
+		{Symbol: "BTCUSDT", Price: 42000, Exchange: ExchangeBinance},
+	}}
+
+	ingestor.queueExchangeUpdate(ExchangeKraken, &PriceUpdate{Symbol: "BTCUSDT", Price: 42010}, &pending)
+	ingestor.queueExchangeUpdate(ExchangeKraken, &PriceUpdate{Symbol: "BTCUSDT", Price: 42020}, &pending)
+	ingestor.queueExchangeUpdate(ExchangeKraken, &PriceUpdate{Symbol: "ETHUSDT", Price: 2500}, &pending)
+
+	if len(pending.Data) != 2 {
+		t.Fatalf("Expected a Binance and a Kraken update, got %d", len(pending.Data))
+	}
+	if kraken := pending.Data[1]; kraken.Exchange != ExchangeKraken || kraken.Price != 42020 || kraken.Timestamp == "" {
+		t.Errorf("Expected the latest Kraken update, tagged and stamped, got %+v", kraken)
+	}
+}
+
+// TestNewExchangeSource verifies exchanges are looked up by name.
+func TestNewExchangeSource(t *testing.T) {
+	sources, err := ParseExchanges([]string{"Coinbase", " kraken", "binance"})
+	if err != nil || len(sources) != 3 || sources[1].Name() != ExchangeKraken {
+		t.Errorf("Expected three sources, got %v and %v", sources, err)
+	}
+	if _, err := NewExchangeSource("bitstamp"); !errors.Is(err, ErrUnknownExchange) {
+		t.Errorf("Expected ErrUnknownExchange, got %v", err)
+	}
+}
+
+// TestIngestorStreamsFromBinanceSource verifies the ingestor's own ticker
+// stream is served by its Binance source, including conversion pairs.
+func TestIngestorStreamsFromBinanceSource(t *testing.T) {
+	ingestor := NewIngestor(HubSink(NewHub()), WithSymbols([]string{"BTCUSDT"}), WithClock(clock.NewFake(time.Unix(0, 0))))
+
+	served := make(chan binance.WsMarketStatHandler, 1)
+	var streamed []string
+	ingestor.binance.serve = func(symbols []string, handler binance.WsMarketStatHandler, errHandler binance.ErrHandler) (chan struct{}, chan struct{}, error) {
+		streamed = symbols
+		doneC, stopC := make(chan struct{}), make(chan struct{})
+		go func() {
+			<-stopC
+			close(doneC)
+		}()
+		served <- handler
+		return doneC, stopC, nil
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		ingestor.StartMultiSymbol()
+		close(finished)
+	}()
+
+	select {
+	case handler := <-served:
+		handler(&binance.WsMarketStatEvent{Symbol: "BTCUSDT", LastPrice: "42000", PriceChange: "100", PriceChangePercent: "0.24", BaseVolume: "10", Time: 1})
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the Binance source to be served")
+	}
+
+	if !reflect.DeepEqual(streamed, []string{"BTCUSDT"}) {
+		t.Errorf("Expected the tracked symbols to be streamed, got %v", streamed)
+	}
+	if price, err := ingestor.GetCurrentPrice("BTCUSDT"); err != nil || price != "42000" {
+		t.Errorf("Expected the event to update the price to 42000, got %q and %v", price, err)
+	}
+
+	ingestor.Stop()
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the stream to end once the ingestor stopped")
+	}
+}
@@ -0,0 +1,53 @@
+package ws
+
+import (
+	"log"
+	"time"
+)
+
+// DefaultFailoverTimeout is how long the active feed may stay silent before a
+// live standby feed is promoted
+const DefaultFailoverTimeout = 30 * time.Second
+
+// Failover promotes a standby feed when the active one has been silent for
+// longer than the timeout, e.g. because its Binance connection died without
+// closing. The first standby that received an event within the timeout is
+// activated, and it stays active when the old feed recovers. Feeds are only
+// failed over once one is pinned as the active source, which Create does
+// when starting a standby. It returns the name of the promoted feed.
+func (f *FeedSwitch) Failover(now time.Time, timeout time.Duration) (string, bool) {
+	f.mu.RLock()
+	active, exists := f.feeds[f.hub.ActiveSource()]
+	if !exists || timeout <= 0 || !feedSilent(active, now, timeout) {
+		f.mu.RUnlock()
+		return "", false
+	}
+
+	var standby *Ingestor
+	for _, name := range f.order {
+		if ingestor := f.feeds[name]; ingestor != active && !feedSilent(ingestor, now, timeout) && !ingestor.LastEventAt().IsZero() {
+			standby = ingestor
+			break
+		}
+	}
+	f.mu.RUnlock()
+
+	if standby == nil {
+		log.Printf("Feed %q is silent, but no standby feed is live to fail over to", active.Name())
+		return "", false
+	}
+
+	f.hub.SetActiveSource(standby.Name())
+	log.Printf("Failed over from silent feed %q to %q", active.Name(), standby.Name())
+	return standby.Name(), true
+}
+
+// feedSilent reports whether a started feed received no event within the
+// timeout. Feeds that have not started are not considered silent.
+func feedSilent(ingestor *Ingestor, now time.Time, timeout time.Duration) bool {
+	lastSeen := ingestor.LastEventAt()
+	if lastSeen.IsZero() {
+		lastSeen = ingestor.StartedAt()
+	}
+	return !lastSeen.IsZero() && now.Sub(lastSeen) > timeout
+}
@@ -0,0 +1,58 @@
+package ws
+
+import (
+	"testing"
+	"time"
+)
+
+// TestFeedSwitchFailover verifies a live standby is promoted only when the
+// active feed has been silent for longer than the timeout.
+func TestFeedSwitchFailover(t *testing.T) {
+	now := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	timeout := DefaultFailoverTimeout
+
+	tests := []struct {
+		name            string
+		pinned          bool
+		activeSilence   time.Duration
+		standbySilence  time.Duration // 0 for a standby without events
+		expectPromotion bool
+	}{
+		{"silent active, live standby", true, time.Minute, time.Second, true},
+		{"live active", true, 10 * time.Second, time.Second, false},
+		{"silent standby", true, time.Minute, time.Minute, false},
+		{"standby without events", true, time.Minute, 0, false},
+		{"merged sources", false, time.Minute, time.Second, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := NewHub()
+			feeds := newTestFeedSwitch(hub)
+			primary, standby := NewIngestor(HubSink(hub)), NewIngestor(HubSink(hub), WithSourceName("green"))
+			feeds.Add(primary)
+			feeds.Add(standby)
+			if tt.pinned {
+				hub.SetActiveSource(primary.Name())
+			}
+
+			primary.markEventReceived(now.Add(-tt.activeSilence))
+			standby.startedAt = now.Add(-time.Hour)
+			if tt.standbySilence > 0 {
+				standby.markEventReceived(now.Add(-tt.standbySilence))
+			}
+
+			promoted, ok := feeds.Failover(now, timeout)
+
+			if ok != tt.expectPromotion {
+				t.Fatalf("Expected promotion %v, got %v (%q)", tt.expectPromotion, ok, promoted)
+			}
+			if ok && (promoted != "green" || !hub.IsActiveSource("green")) {
+				t.Errorf("Expected the standby to be active, got %q active", hub.ActiveSource())
+			}
+			if !ok && tt.pinned && !hub.IsActiveSource(primary.Name()) {
+				t.Errorf("Expected the primary to stay active, got %q", hub.ActiveSource())
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package ws
+
+import (
+	"macro-analyst/pkg/feedlog"
+)
+
+// Causes recorded when the ingestor closes its Binance connection itself
+const (
+	causeSymbolsChanged = "symbols changed"
+	causeStopped        = "ingestor stopped"
+	causeStreamClosed   = "connection closed by Binance"
+)
+
+// WithFeedHistory sets the log Binance connects, disconnects and reconnects
+// are recorded in. Ingestors sharing the log are told apart by source name.
+func WithFeedHistory(history *feedlog.Log) IngestorOption {
+	return func(i *Ingestor) {
+		i.feedHistory = history
+	}
+}
+
+// recordFeedEvent records a change of the Binance connection, if a feed
+// history is configured.
+func (i *Ingestor) recordFeedEvent(eventType feedlog.EventType, cause string, symbols int) {
+	if i.feedHistory != nil {
+		i.feedHistory.Record(i.name, eventType, cause, symbols)
+	}
+}
+
+// recordConnected records that a Binance connection was opened.
+func (i *Ingestor) recordConnected(eventType feedlog.EventType, symbols int) {
+	i.streamConnected.Store(true)
+	i.recordFeedEvent(eventType, "", symbols)
+}
+
+// recordDisconnected records that the Binance connection closed, once per
+// connection, as both Stop and the stream loop report it.
+func (i *Ingestor) recordDisconnected(cause string) {
+	if i.streamConnected.CompareAndSwap(true, false) {
+		i.recordFeedEvent(feedlog.Disconnected, cause, 0)
+	}
+}
+
+// setStreamError remembers the last error reported by the Binance stream,
+// the cause of the disconnect that usually follows.
+func (i *Ingestor) setStreamError(err error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.streamErr = err
+}
+
+// disconnectCause returns why the Binance stream closed by itself and
+// forgets the stream error.
+func (i *Ingestor) disconnectCause() string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	err := i.streamErr
+	i.streamErr = nil
+	switch {
+	case i.ctx.Err() != nil:
+		return causeStopped
+	case err != nil:
+		return err.Error()
+	default:
+		return causeStreamClosed
+	}
+}
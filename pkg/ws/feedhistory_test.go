@@ -0,0 +1,96 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"macro-analyst/pkg/feedlog"
+)
+
+// TestRunStreamRecordsFeedHistory verifies the stream loop records its
+// connects, reconnects and disconnects with their causes.
+func TestRunStreamRecordsFeedHistory(t *testing.T) {
+	// Arrange
+	history := feedlog.New()
+	ingestor := NewIngestor(HubSink(NewHub()), WithSourceName("blue"), WithSymbols([]string{"BTCUSDT"}),
+		WithFeedHistory(history), WithSymbolLister(listing("ETHUSDT")), WithReconnectBackoff(0))
+	errHandler := ingestor.createErrorHandler()
+
+	connections := 0
+	serve := func(symbols []string) (chan struct{}, chan struct{}, error) {
+		connections++
+		doneC, stopC := make(chan struct{}), make(chan struct{})
+		switch connections {
+		case 1:
+			go func() {
+				<-stopC
+				close(doneC)
+			}()
+			go ingestor.AddSymbol(context.Background(), "ETHUSDT")
+		default:
+			go func() {
+				errHandler(errors.New("connection reset by peer"))
+				close(doneC)
+			}()
+		}
+		return doneC, stopC, nil
+	}
+
+	// Act
+	finished := make(chan struct{})
+	go func() {
+		ingestor.runStream(serve, nil)
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the stream loop to end once the connection dropped")
+	}
+	ingestor.Stop()
+
+	// Assert
+	expected := []feedlog.Event{
+		{Feed: "blue", Type: feedlog.Connected, Symbols: 1},
+		{Feed: "blue", Type: feedlog.Disconnected, Cause: causeSymbolsChanged},
+		{Feed: "blue", Type: feedlog.Reconnected, Symbols: 2},
+		{Feed: "blue", Type: feedlog.Disconnected, Cause: "connection reset by peer"},
+	}
+	events := history.Events(time.Time{}, "")
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %+v", len(expected), events)
+	}
+	for idx, event := range events {
+		event.Time = time.Time{}
+		if event != expected[idx] {
+			t.Errorf("Event %d: expected %+v, got %+v", idx, expected[idx], event)
+		}
+	}
+}
+
+// TestStopRecordsDisconnect verifies stopping a connected ingestor records
+// one disconnect, and a failed connect is recorded with its error.
+func TestStopRecordsDisconnect(t *testing.T) {
+	history := feedlog.New()
+	ingestor := NewIngestor(HubSink(NewHub()), WithFeedHistory(history), WithReconnectBackoff(0))
+
+	ingestor.runStream(func(symbols []string) (chan struct{}, chan struct{}, error) {
+		return nil, nil, errors.New("dial tcp: no such host")
+	}, nil)
+	ingestor.recordConnected(feedlog.Connected, 6)
+	ingestor.Stop()
+	ingestor.Stop()
+
+	events := history.Events(time.Time{}, DefaultSourceName)
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %+v", events)
+	}
+	if events[0].Type != feedlog.ConnectFailed || events[0].Cause != "dial tcp: no such host" {
+		t.Errorf("Expected the failed connect with its error, got %+v", events[0])
+	}
+	if events[2].Type != feedlog.Disconnected || events[2].Cause != causeStopped {
+		t.Errorf("Expected a disconnect by stopping, got %+v", events[2])
+	}
+}
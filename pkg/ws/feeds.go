@@ -0,0 +1,281 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrFeedNotFound is returned when no feed with the given name exists.
+	ErrFeedNotFound = errors.New("feed not found")
+
+	// ErrFeedExists is returned when adding a feed whose name is taken.
+	ErrFeedExists = errors.New("feed already exists")
+
+	// ErrFeedActive is returned when removing the feed that serves clients.
+	ErrFeedActive = errors.New("feed is active")
+
+	// ErrSymbolNotFound is returned when removing a symbol a feed does not track.
+	ErrSymbolNotFound = errors.New("symbol not found")
+)
+
+// FeedInfo describes a running feed for the admin API.
+type FeedInfo struct {
+	Name        string     `json:"name"`
+	Symbols     []string   `json:"symbols"`
+	Active      bool       `json:"active"`
+	LastEventAt *time.Time `json:"last_event_at,omitempty"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+}
+
+// FeedSwitch manages several named ingestors feeding the same Hub, enabling
+// blue/green switchover: a standby feed with a new configuration runs in the
+// shadow of the active one until it is validated and promoted.
+type FeedSwitch struct {
+	hub      *Hub
+	baseOpts []IngestorOption
+
+	mu    sync.RWMutex
+	feeds map[string]*Ingestor
+	order []string // Feed names in the order they were added
+
+	// start launches a newly created feed; replaced in tests
+	start func(*Ingestor)
+}
+
+// NewFeedSwitch creates a FeedSwitch for the given Hub. The options are
+// applied to every feed created at runtime via Create.
+func NewFeedSwitch(hub *Hub, opts ...IngestorOption) *FeedSwitch {
+	return &FeedSwitch{
+		hub:      hub,
+		baseOpts: opts,
+		feeds:    make(map[string]*Ingestor),
+		start: func(ingestor *Ingestor) {
+			go ingestor.Start()
+		},
+	}
+}
+
+// Add registers an already constructed ingestor. The caller is responsible
+// for starting it.
+func (f *FeedSwitch) Add(ingestor *Ingestor) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, exists := f.feeds[ingestor.Name()]; exists {
+		return fmt.Errorf("%w: %s", ErrFeedExists, ingestor.Name())
+	}
+
+	f.feeds[ingestor.Name()] = ingestor
+	f.order = append(f.order, ingestor.Name())
+	return nil
+}
+
+// Create starts a new standby feed tracking the given symbols, which Binance
+// must trade. If no feed was explicitly activated yet, the existing feeds
+// are pinned first so the standby's data does not reach clients before it
+// is promoted.
+func (f *FeedSwitch) Create(ctx context.Context, name string, symbols []string) (*Ingestor, error) {
+	if name == "" {
+		return nil, errors.New("feed name is required")
+	}
+	if len(symbols) == 0 {
+		return nil, errors.New("at least one symbol is required")
+	}
+
+	opts := append(append([]IngestorOption{}, f.baseOpts...), WithSourceName(name))
+	ingestor := NewIngestor(HubSink(f.hub), opts...)
+	normalized := make([]string, len(symbols))
+	for idx, symbol := range symbols {
+		var err error
+		if normalized[idx], err = ingestor.ValidateSymbol(ctx, symbol); err != nil {
+			return nil, err
+		}
+	}
+	WithSymbols(normalized)(ingestor)
+
+	f.mu.Lock()
+	if _, exists := f.feeds[name]; exists {
+		f.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrFeedExists, name)
+	}
+
+	if f.hub.ActiveSource() == "" && len(f.order) > 0 {
+		f.hub.SetActiveSource(f.order[0])
+	}
+
+	f.feeds[name] = ingestor
+	f.order = append(f.order, name)
+	f.mu.Unlock()
+
+	log.Printf("Starting standby feed %q with symbols %v", name, normalized)
+	f.start(ingestor)
+	return ingestor, nil
+}
+
+// Activate cuts clients over to the named feed.
+func (f *FeedSwitch) Activate(name string) error {
+	f.mu.RLock()
+	_, exists := f.feeds[name]
+	f.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrFeedNotFound, name)
+	}
+
+	f.hub.SetActiveSource(name)
+	log.Printf("Cut over to feed %q", name)
+	return nil
+}
+
+// Remove stops and removes the named feed. The active feed cannot be removed.
+func (f *FeedSwitch) Remove(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ingestor, exists := f.feeds[name]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrFeedNotFound, name)
+	}
+
+	if active := f.hub.ActiveSource(); active == name || (active == "" && len(f.feeds) == 1) {
+		return fmt.Errorf("%w: %s", ErrFeedActive, name)
+	}
+
+	ingestor.Stop()
+	delete(f.feeds, name)
+	for idx, feedName := range f.order {
+		if feedName == name {
+			f.order = append(f.order[:idx], f.order[idx+1:]...)
+			break
+		}
+	}
+
+	log.Printf("Removed feed %q", name)
+	return nil
+}
+
+// AddSymbols adds symbols to the named feed at runtime. The feed
+// re-establishes its Binance stream, so clients receive the new symbols
+// within seconds. Symbols the feed already tracks are ignored, and none are
+// added unless Binance trades all of them. It returns the feed's symbols.
+func (f *FeedSwitch) AddSymbols(ctx context.Context, name string, symbols []string) ([]string, error) {
+	if len(symbols) == 0 {
+		return nil, errors.New("at least one symbol is required")
+	}
+
+	ingestor, err := f.feed(name)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := make([]string, len(symbols))
+	for idx, symbol := range symbols {
+		if normalized[idx], err = ingestor.ValidateSymbol(ctx, symbol); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, symbol := range normalized {
+		if _, err := ingestor.AddSymbol(ctx, symbol); err != nil {
+			return nil, err
+		}
+	}
+	return ingestor.GetSymbols(), nil
+}
+
+// RemoveSymbol stops streaming a symbol on the named feed at runtime. It
+// returns the feed's remaining symbols.
+func (f *FeedSwitch) RemoveSymbol(name, symbol string) ([]string, error) {
+	ingestor, err := f.feed(name)
+	if err != nil {
+		return nil, err
+	}
+
+	symbol = ingestor.ResolveSymbol(symbol)
+	if !ingestor.RemoveSymbol(symbol) {
+		return nil, fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
+	}
+	return ingestor.GetSymbols(), nil
+}
+
+// feed returns the named feed.
+func (f *FeedSwitch) feed(name string) (*Ingestor, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	ingestor, exists := f.feeds[name]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrFeedNotFound, name)
+	}
+	return ingestor, nil
+}
+
+// Feeds returns information about all feeds in the order they were added.
+func (f *FeedSwitch) Feeds() []FeedInfo {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	infos := make([]FeedInfo, 0, len(f.order))
+	for _, name := range f.order {
+		ingestor := f.feeds[name]
+		info := FeedInfo{
+			Name:    name,
+			Symbols: ingestor.GetSymbols(),
+			Active:  f.hub.IsActiveSource(name),
+		}
+		if lastEventAt := ingestor.LastEventAt(); !lastEventAt.IsZero() {
+			info.LastEventAt = &lastEventAt
+		}
+		if startedAt := ingestor.StartedAt(); !startedAt.IsZero() {
+			info.StartedAt = &startedAt
+		}
+		infos = append(infos, info)
+	}
+
+	return infos
+}
+
+// Active returns the feed whose data reaches clients. When updates from all
+// sources are merged, the first added feed is returned. Returns nil if there
+// are no feeds.
+func (f *FeedSwitch) Active() *Ingestor {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if ingestor, exists := f.feeds[f.hub.ActiveSource()]; exists {
+		return ingestor
+	}
+	if len(f.order) > 0 {
+		return f.feeds[f.order[0]]
+	}
+	return nil
+}
+
+// RefreshDayOpens reloads the UTC midnight opens of every feed.
+func (f *FeedSwitch) RefreshDayOpens(ctx context.Context) {
+	f.mu.RLock()
+	ingestors := make([]*Ingestor, 0, len(f.order))
+	for _, name := range f.order {
+		ingestors = append(ingestors, f.feeds[name])
+	}
+	f.mu.RUnlock()
+
+	for _, ingestor := range ingestors {
+		ingestor.RefreshDayOpens(ctx)
+	}
+}
+
+// StopAll stops every feed.
+func (f *FeedSwitch) StopAll() {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for _, name := range f.order {
+		f.feeds[name].Stop()
+	}
+}
@@ -0,0 +1,181 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// newTestFeedSwitch creates a FeedSwitch that does not connect to Binance.
+func newTestFeedSwitch(hub *Hub) *FeedSwitch {
+	feeds := NewFeedSwitch(hub, WithSymbolLister(listing("BTCUSDT", "DOGEUSDT")))
+	feeds.start = func(*Ingestor) {}
+	return feeds
+}
+
+// TestFeedSwitchCreatePinsActiveFeed verifies a standby feed starts in the shadow.
+func TestFeedSwitchCreatePinsActiveFeed(t *testing.T) {
+	hub := NewHub()
+	feeds := newTestFeedSwitch(hub)
+
+	if err := feeds.Add(NewIngestor(HubSink(hub))); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	standby, err := feeds.Create(context.Background(), "green", []string{"DOGEUSDT"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	if hub.ActiveSource() != DefaultSourceName {
+		t.Errorf("Expected active source %q, got %q", DefaultSourceName, hub.ActiveSource())
+	}
+
+	if hub.IsActiveSource(standby.Name()) {
+		t.Error("Standby feed should not be active")
+	}
+
+	symbols := standby.GetSymbols()
+	if len(symbols) != 1 || symbols[0] != "DOGEUSDT" {
+		t.Errorf("Expected standby symbols [DOGEUSDT], got %v", symbols)
+	}
+
+	infos := feeds.Feeds()
+	if len(infos) != 2 || infos[0].Name != DefaultSourceName || infos[1].Name != "green" {
+		t.Errorf("Unexpected feeds: %+v", infos)
+	}
+}
+
+// TestFeedSwitchCreateValidation verifies invalid or duplicate feeds are rejected.
+func TestFeedSwitchCreateValidation(t *testing.T) {
+	hub := NewHub()
+	feeds := newTestFeedSwitch(hub)
+	feeds.Add(NewIngestor(HubSink(hub)))
+
+	if _, err := feeds.Create(context.Background(), "", []string{"BTCUSDT"}); err == nil {
+		t.Error("Expected error for empty name")
+	}
+
+	if _, err := feeds.Create(context.Background(), "green", nil); err == nil {
+		t.Error("Expected error for empty symbols")
+	}
+
+	if _, err := feeds.Create(context.Background(), DefaultSourceName, []string{"BTCUSDT"}); !errors.Is(err, ErrFeedExists) {
+		t.Errorf("Expected ErrFeedExists, got %v", err)
+	}
+
+	if _, err := feeds.Create(context.Background(), "green", []string{"TESTUSDT"}); !errors.Is(err, ErrUnknownSymbol) {
+		t.Errorf("Expected ErrUnknownSymbol, got %v", err)
+	}
+	if len(feeds.Feeds()) != 1 {
+		t.Errorf("Expected no feed for unknown symbols, got %+v", feeds.Feeds())
+	}
+}
+
+// TestFeedSwitchActivateAndRemove verifies cutover and removal of the old feed.
+func TestFeedSwitchActivateAndRemove(t *testing.T) {
+	hub := NewHub()
+	feeds := newTestFeedSwitch(hub)
+	feeds.Add(NewIngestor(HubSink(hub)))
+	feeds.Create(context.Background(), "green", []string{"BTCUSDT"})
+
+	if err := feeds.Activate("missing"); !errors.Is(err, ErrFeedNotFound) {
+		t.Errorf("Expected ErrFeedNotFound, got %v", err)
+	}
+
+	if err := feeds.Activate("green"); err != nil {
+		t.Fatalf("Activate failed: %v", err)
+	}
+
+	if feeds.Active().Name() != "green" {
+		t.Errorf("Expected active feed green, got %s", feeds.Active().Name())
+	}
+
+	if err := feeds.Remove("green"); !errors.Is(err, ErrFeedActive) {
+		t.Errorf("Expected ErrFeedActive, got %v", err)
+	}
+
+	if err := feeds.Remove(DefaultSourceName); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+
+	if len(feeds.Feeds()) != 1 {
+		t.Errorf("Expected 1 feed after removal, got %d", len(feeds.Feeds()))
+	}
+}
+
+// TestFeedSwitchSymbols verifies symbols are added to and removed from a
+// running feed.
+func TestFeedSwitchSymbols(t *testing.T) {
+	hub := NewHub()
+	feeds := newTestFeedSwitch(hub)
+	feeds.Add(NewIngestor(HubSink(hub), WithSymbols([]string{"BTCUSDT"}), WithSymbolLister(listing("BTCUSDT", "DOGEUSDT"))))
+
+	symbols, err := feeds.AddSymbols(context.Background(), DefaultSourceName, []string{"dogeusdt", "BTCUSDT"})
+	if err != nil {
+		t.Fatalf("AddSymbols failed: %v", err)
+	}
+	if len(symbols) != 2 || symbols[1] != "DOGEUSDT" {
+		t.Errorf("Expected BTCUSDT and DOGEUSDT, got %v", symbols)
+	}
+
+	if _, err := feeds.AddSymbols(context.Background(), DefaultSourceName, []string{"DOGE.USDT"}); !errors.Is(err, ErrInvalidSymbol) {
+		t.Errorf("Expected ErrInvalidSymbol, got %v", err)
+	}
+	if _, err := feeds.AddSymbols(context.Background(), DefaultSourceName, []string{"BTCUSDT", "TESTUSDT"}); !errors.Is(err, ErrUnknownSymbol) {
+		t.Errorf("Expected ErrUnknownSymbol, got %v", err)
+	}
+	if _, err := feeds.AddSymbols(context.Background(), "missing", []string{"DOGEUSDT"}); !errors.Is(err, ErrFeedNotFound) {
+		t.Errorf("Expected ErrFeedNotFound, got %v", err)
+	}
+
+	if symbols, err := feeds.RemoveSymbol(DefaultSourceName, "DOGEUSDT"); err != nil || len(symbols) != 1 {
+		t.Errorf("Expected DOGEUSDT to be removed, got %v, %v", symbols, err)
+	}
+	if _, err := feeds.RemoveSymbol(DefaultSourceName, "DOGEUSDT"); !errors.Is(err, ErrSymbolNotFound) {
+		t.Errorf("Expected ErrSymbolNotFound, got %v", err)
+	}
+}
+
+// TestHubAcceptUpdatesDeduplicates verifies symbol+event time deduplication.
+func TestHubAcceptUpdatesDeduplicates(t *testing.T) {
+	hub := NewHub()
+
+	first := hub.acceptUpdates("blue", []*PriceUpdate{
+		{Symbol: "BTCUSDT", EventTime: 100},
+		{Symbol: "ETHUSDT", EventTime: 100},
+	})
+	if len(first) != 2 {
+		t.Fatalf("Expected 2 accepted updates, got %d", len(first))
+	}
+
+	// Same events arriving from a second feed are duplicates
+	second := hub.acceptUpdates("green", []*PriceUpdate{
+		{Symbol: "BTCUSDT", EventTime: 100},
+		{Symbol: "ETHUSDT", EventTime: 101},
+	})
+	if len(second) != 1 || second[0].Symbol != "ETHUSDT" {
+		t.Errorf("Expected only newer ETHUSDT update, got %+v", second)
+	}
+
+	// Updates without event time are never deduplicated
+	third := hub.acceptUpdates("blue", []*PriceUpdate{{Symbol: "BTCUSDT"}})
+	if len(third) != 1 {
+		t.Errorf("Expected update without event time to be accepted, got %d", len(third))
+	}
+}
+
+// TestHubAcceptUpdatesInactiveSource verifies inactive feeds never reach clients.
+func TestHubAcceptUpdatesInactiveSource(t *testing.T) {
+	hub := NewHub()
+	hub.SetActiveSource("blue")
+
+	accepted := hub.acceptUpdates("green", []*PriceUpdate{{Symbol: "BTCUSDT", EventTime: 100}})
+	if len(accepted) != 0 {
+		t.Errorf("Expected no updates from inactive source, got %d", len(accepted))
+	}
+
+	if !hub.IsActiveSource("blue") || hub.IsActiveSource("green") {
+		t.Error("IsActiveSource returned unexpected result")
+	}
+}
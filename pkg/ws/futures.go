@@ -0,0 +1,269 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+const (
+	// DefaultFuturesInterval is the throttle interval of futures ingestors
+	DefaultFuturesInterval = time.Second
+
+	// DefaultOpenInterestInterval is how often futures ingestors poll the
+	// open interest, which Binance does not stream
+	DefaultOpenInterestInterval = time.Minute
+)
+
+// MarkPriceUpdate is the mark price and funding rate of a perpetual
+// futures contract.
+type MarkPriceUpdate struct {
+	Type            string  `json:"type"` // Always "mark_price"
+	Symbol          string  `json:"symbol"`
+	MarkPrice       float64 `json:"markPrice"`
+	IndexPrice      float64 `json:"indexPrice"`
+	FundingRate     float64 `json:"fundingRate"`     // Rate of the next funding, e.g. 0.0001 for 0.01%
+	NextFundingTime int64   `json:"nextFundingTime"` // Unix ms
+	EventTime       int64   `json:"eventTime"`       // Binance event time (Unix ms)
+	ReceivedAt      int64   `json:"receivedAt"`      // Server receive time (Unix ms)
+}
+
+// OpenInterestUpdate is the open interest of a perpetual futures contract.
+type OpenInterestUpdate struct {
+	Type         string  `json:"type"` // Always "open_interest"
+	Symbol       string  `json:"symbol"`
+	OpenInterest float64 `json:"openInterest"` // Open contracts in the base asset
+	EventTime    int64   `json:"eventTime"`    // Binance time of the figure (Unix ms)
+	ReceivedAt   int64   `json:"receivedAt"`   // Server receive time (Unix ms)
+}
+
+// WithFutures switches the ingestor to futures mode: instead of ticker
+// updates it streams the mark prices and funding rates of the symbols'
+// USDⓈ-M perpetual contracts, broadcast once per throttle interval for the
+// symbols that changed, and polls their open interest every
+// openInterestInterval, since derivatives positioning matters to macro
+// traders.
+func WithFutures(openInterestInterval time.Duration) IngestorOption {
+	return func(i *Ingestor) {
+		if openInterestInterval <= 0 {
+			openInterestInterval = DefaultOpenInterestInterval
+		}
+		i.stream = &futuresStream{
+			openInterestInterval: openInterestInterval,
+			pending:              make(map[string]*MarkPriceUpdate),
+		}
+	}
+}
+
+// futuresStream streams the mark prices and polls the open interest of the
+// perpetual contracts of the tracked symbols.
+type futuresStream struct {
+	openInterestInterval time.Duration
+	baseURL              string // Futures REST endpoint, empty for Binance's
+
+	// mu protects the mark prices pending broadcast
+	mu      sync.Mutex
+	pending map[string]*MarkPriceUpdate
+}
+
+// FuturesMode reports whether the ingestor streams futures data instead of
+// ticker updates.
+func (i *Ingestor) FuturesMode() bool {
+	_, ok := i.stream.(*futuresStream)
+	return ok
+}
+
+// serve connects to the Binance mark price streams of all symbols in one
+// connection and broadcasts mark prices at the throttle interval,
+// re-establishing the connection whenever symbols are added or removed,
+// while polling the open interest in the background.
+func (s *futuresStream) serve(i *Ingestor) {
+	if len(i.GetSymbols()) == 0 {
+		log.Println("No symbols to track")
+		return
+	}
+
+	go s.pollOpenInterest(i)
+
+	throttleTicker := i.clock.NewTicker(i.throttleInterval)
+	defer throttleTicker.Stop()
+
+	markPriceHandler := s.markPriceHandler(i)
+	errHandler := i.createErrorHandler()
+
+	i.runStream(func(symbols []string) (chan struct{}, chan struct{}, error) {
+		return futures.WsCombinedMarkPriceServe(symbols, markPriceHandler, errHandler)
+	}, func() {
+		i.startPeriodicBroadcast(throttleTicker, func() { s.broadcast(i) })
+	})
+}
+
+func (s *futuresStream) replays(kind string) bool {
+	return kind == "mark_price" || kind == "open_interest"
+}
+
+// markPriceHandler creates a handler keeping the latest mark price of each
+// symbol until the next broadcast.
+func (s *futuresStream) markPriceHandler(i *Ingestor) func(*futures.WsMarkPriceEvent) {
+	return func(event *futures.WsMarkPriceEvent) {
+		now := i.clock.Now()
+		i.markEventReceived(now)
+
+		update, err := convertMarkPriceEvent(event, now)
+		i.recordEvent(event.Symbol, err)
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.pending[event.Symbol] = update
+		s.mu.Unlock()
+	}
+}
+
+// broadcast broadcasts the mark prices received since the last broadcast,
+// one message per symbol. Like trade statistics they go out alongside the
+// price updates of whichever feed is active.
+func (s *futuresStream) broadcast(i *Ingestor) {
+	s.mu.Lock()
+	updates := make([]*MarkPriceUpdate, 0, len(s.pending))
+	for _, update := range s.pending {
+		updates = append(updates, update)
+	}
+	clear(s.pending)
+	s.mu.Unlock()
+
+	sort.Slice(updates, func(a, b int) bool {
+		return updates[a].Symbol < updates[b].Symbol
+	})
+
+	for _, update := range updates {
+		i.publishFutures(update.Symbol, update)
+	}
+}
+
+// pollOpenInterest broadcasts the open interest of every symbol right away
+// and then every open interest interval until the ingestor stops.
+func (s *futuresStream) pollOpenInterest(i *Ingestor) {
+	ticker := i.clock.NewTicker(s.openInterestInterval)
+	defer ticker.Stop()
+
+	for {
+		s.broadcastOpenInterest(i.ctx, i)
+
+		select {
+		case <-i.ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// broadcastOpenInterest fetches and broadcasts the open interest of each
+// symbol. Symbols without a perpetual contract are logged and skipped.
+func (s *futuresStream) broadcastOpenInterest(ctx context.Context, i *Ingestor) {
+	client := s.client(i)
+	for _, symbol := range i.GetSymbols() {
+		interest, err := client.NewGetOpenInterestService().Symbol(symbol).Do(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Failed to fetch open interest of %s: %v", symbol, err)
+			continue
+		}
+
+		update, err := convertOpenInterest(interest, i.clock.Now())
+		if err != nil {
+			log.Printf("⚠ Skipping open interest: %v", err)
+			continue
+		}
+		i.publishFutures(symbol, update)
+	}
+}
+
+// publishFutures publishes a futures message of a symbol on TopicFutures.
+func (i *Ingestor) publishFutures(symbol string, message any) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("Error marshaling futures update: %v", err)
+		return
+	}
+	if !i.publish(TopicFutures, data) {
+		return
+	}
+
+	i.mu.Lock()
+	if tracked := i.findSymbol(symbol); tracked != nil {
+		tracked.UpdatesBroadcast++
+	}
+	i.mu.Unlock()
+}
+
+// client creates a Binance futures REST client with the credentials of the
+// ingestor.
+func (s *futuresStream) client(i *Ingestor) *futures.Client {
+	client := futures.NewClient(i.binanceAPIKey, i.binanceSecretKey)
+	client.HTTPClient = &http.Client{
+		Timeout:   BinanceRESTTimeout,
+		Transport: &apiKeyTransport{apiKey: i.binanceAPIKey, base: http.DefaultTransport},
+	}
+	if s.baseURL != "" {
+		client.BaseURL = s.baseURL
+	}
+	return client
+}
+
+// convertMarkPriceEvent converts a Binance mark price event received at
+// now. It returns an error if the mark price fails to parse or is not
+// positive, or the index price or funding rate fails to parse.
+func convertMarkPriceEvent(event *futures.WsMarkPriceEvent, now time.Time) (*MarkPriceUpdate, error) {
+	markPrice, err := strconv.ParseFloat(event.MarkPrice, 64)
+	if err != nil || markPrice <= 0 {
+		return nil, fmt.Errorf("invalid mark price %q for %s", event.MarkPrice, event.Symbol)
+	}
+	indexPrice, err := strconv.ParseFloat(event.IndexPrice, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid index price %q for %s", event.IndexPrice, event.Symbol)
+	}
+	fundingRate, err := strconv.ParseFloat(event.FundingRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid funding rate %q for %s", event.FundingRate, event.Symbol)
+	}
+
+	return &MarkPriceUpdate{
+		Type:            "mark_price",
+		Symbol:          event.Symbol,
+		MarkPrice:       markPrice,
+		IndexPrice:      indexPrice,
+		FundingRate:     fundingRate,
+		NextFundingTime: event.NextFundingTime,
+		EventTime:       event.Time,
+		ReceivedAt:      now.UnixMilli(),
+	}, nil
+}
+
+// convertOpenInterest converts the open interest of a symbol received at
+// now. It returns an error if it fails to parse or is negative.
+func convertOpenInterest(interest *futures.OpenInterest, now time.Time) (*OpenInterestUpdate, error) {
+	openInterest, err := strconv.ParseFloat(interest.OpenInterest, 64)
+	if err != nil || openInterest < 0 {
+		return nil, fmt.Errorf("invalid open interest %q for %s", interest.OpenInterest, interest.Symbol)
+	}
+
+	return &OpenInterestUpdate{
+		Type:         "open_interest",
+		Symbol:       interest.Symbol,
+		OpenInterest: openInterest,
+		EventTime:    interest.Time,
+		ReceivedAt:   now.UnixMilli(),
+	}, nil
+}
@@ -0,0 +1,109 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// TestConvertMarkPriceEvent tests the conversion and validation of mark
+// price events.
+func TestConvertMarkPriceEvent(t *testing.T) {
+	now := time.UnixMilli(1708424625012)
+	tests := []struct {
+		name  string
+		event futures.WsMarkPriceEvent
+		valid bool
+	}{
+		{"valid", futures.WsMarkPriceEvent{Symbol: "BTCUSDT", MarkPrice: "51240.1", IndexPrice: "51228.4", FundingRate: "-0.0001"}, true},
+		{"zero mark price", futures.WsMarkPriceEvent{Symbol: "BTCUSDT", MarkPrice: "0", IndexPrice: "51228.4", FundingRate: "0.0001"}, false},
+		{"invalid index price", futures.WsMarkPriceEvent{Symbol: "BTCUSDT", MarkPrice: "51240.1", IndexPrice: "", FundingRate: "0.0001"}, false},
+		{"invalid funding rate", futures.WsMarkPriceEvent{Symbol: "BTCUSDT", MarkPrice: "51240.1", IndexPrice: "51228.4", FundingRate: "abc"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			update, err := convertMarkPriceEvent(&tt.event, now)
+			if !tt.valid {
+				if err == nil {
+					t.Errorf("Expected an error, got %+v", update)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if update.Type != "mark_price" || update.MarkPrice != 51240.1 || update.FundingRate != -0.0001 || update.ReceivedAt != now.UnixMilli() {
+				t.Errorf("Unexpected update %+v", update)
+			}
+		})
+	}
+}
+
+// TestBroadcastMarkPrices verifies the latest mark price of each symbol is
+// broadcast once on the futures topic.
+func TestBroadcastMarkPrices(t *testing.T) {
+	hub := NewHub()
+	ingestor := NewIngestor(HubSink(hub), WithSymbols([]string{"BTCUSDT", "ETHUSDT"}), WithFutures(0))
+	stream, ok := ingestor.stream.(*futuresStream)
+	if !ok || stream.openInterestInterval != DefaultOpenInterestInterval {
+		t.Fatal("Expected futures mode with the default open interest interval")
+	}
+
+	handler := stream.markPriceHandler(ingestor)
+	for _, price := range []string{"51000", "51240.1"} {
+		handler(&futures.WsMarkPriceEvent{Symbol: "BTCUSDT", MarkPrice: price, IndexPrice: "51228.4", FundingRate: "0.0001"})
+	}
+	handler(&futures.WsMarkPriceEvent{Symbol: "ETHUSDT", MarkPrice: "bad", IndexPrice: "3000", FundingRate: "0.0001"})
+	stream.broadcast(ingestor)
+	stream.broadcast(ingestor)
+
+	if len(hub.broadcast) != 1 {
+		t.Fatalf("Expected one mark price broadcast, got %d", len(hub.broadcast))
+	}
+	queued := <-hub.broadcast
+	var update MarkPriceUpdate
+	if err := json.Unmarshal(queued.data, &update); err != nil {
+		t.Fatalf("Invalid update %s: %v", queued.data, err)
+	}
+	if queued.topic != TopicFutures || update.Symbol != "BTCUSDT" || update.MarkPrice != 51240.1 {
+		t.Errorf("Expected the latest BTCUSDT mark price on %q, got %s on %q", TopicFutures, queued.data, queued.topic)
+	}
+}
+
+// TestBroadcastOpenInterest verifies the open interest of each symbol is
+// fetched and broadcast, skipping symbols without a perpetual contract.
+func TestBroadcastOpenInterest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		symbol := r.URL.Query().Get("symbol")
+		if r.URL.Path != "/fapi/v1/openInterest" || symbol != "BTCUSDT" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":-1121,"msg":"Invalid symbol."}`))
+			return
+		}
+		w.Write([]byte(`{"openInterest":"81234.567","symbol":"BTCUSDT","time":1708424620000}`))
+	}))
+	defer server.Close()
+
+	hub := NewHub()
+	ingestor := NewIngestor(HubSink(hub), WithSymbols([]string{"BTCUSDT", "SPOTONLY"}), WithFutures(time.Minute))
+	stream := ingestor.stream.(*futuresStream)
+	stream.baseURL = server.URL
+	stream.broadcastOpenInterest(context.Background(), ingestor)
+
+	if len(hub.broadcast) != 1 {
+		t.Fatalf("Expected one open interest broadcast, got %d", len(hub.broadcast))
+	}
+	queued := <-hub.broadcast
+	var update OpenInterestUpdate
+	if err := json.Unmarshal(queued.data, &update); err != nil {
+		t.Fatalf("Invalid update %s: %v", queued.data, err)
+	}
+	if queued.topic != TopicFutures || update.Type != "open_interest" || update.OpenInterest != 81234.567 || update.EventTime != 1708424620000 {
+		t.Errorf("Unexpected open interest %s on %q", queued.data, queued.topic)
+	}
+}
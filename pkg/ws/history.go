@@ -0,0 +1,57 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// MaxKlinesPerRequest is the most klines Binance returns per request
+const MaxKlinesPerRequest = 1000
+
+// DailyClose is the closing price of a day, stamped with the UTC open time
+// of its kline.
+type DailyClose struct {
+	Time  time.Time
+	Close float64
+}
+
+// DailyCloses returns the daily closing prices of a symbol between start
+// and end (inclusive) from Binance daily klines, oldest first.
+func (i *Ingestor) DailyCloses(ctx context.Context, symbol string, start, end time.Time) ([]DailyClose, error) {
+	var points []DailyClose
+
+	from := start.UnixMilli()
+	to := end.UnixMilli()
+	for from <= to {
+		klines, err := i.restClient().NewKlinesService().
+			Symbol(symbol).
+			Interval("1d").
+			StartTime(from).
+			EndTime(to).
+			Limit(MaxKlinesPerRequest).
+			Do(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch daily klines for %s: %w", symbol, err)
+		}
+
+		for _, kline := range klines {
+			closePrice, err := strconv.ParseFloat(kline.Close, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid close %q for %s: %w", kline.Close, symbol, err)
+			}
+			points = append(points, DailyClose{
+				Time:  time.UnixMilli(kline.OpenTime).UTC(),
+				Close: closePrice,
+			})
+		}
+
+		if len(klines) < MaxKlinesPerRequest {
+			break
+		}
+		from = klines[len(klines)-1].CloseTime + 1
+	}
+
+	return points, nil
+}
@@ -0,0 +1,483 @@
+package ws
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"macro-analyst/pkg/clock"
+	"macro-analyst/pkg/metrics"
+)
+
+const (
+	// BroadcastBufferSize is the buffer size for the broadcast channel
+	BroadcastBufferSize = 256
+
+	// DefaultMessageTTL is the maximum time a message may wait in the
+	// broadcast channel before it is considered stale and dropped
+	DefaultMessageTTL = 2 * time.Second
+)
+
+// queuedMessage is a broadcast message stamped with its enqueue time.
+type queuedMessage struct {
+	data     []byte
+	topic    string // Empty for every client
+	queuedAt time.Time
+}
+
+// HubOption is a functional option for configuring the Hub.
+type HubOption func(*Hub)
+
+// WithMessageTTL sets how long a message may wait in the broadcast channel
+// before fan-out. Older messages are dropped so a stalled Run loop does not
+// flush a burst of outdated prices. Zero disables expiry.
+func WithMessageTTL(ttl time.Duration) HubOption {
+	return func(h *Hub) {
+		h.messageTTL = ttl
+	}
+}
+
+// WithBroadcastBuffer sets how many messages may wait in the broadcast
+// channel for fan-out, BroadcastBufferSize by default.
+func WithBroadcastBuffer(size int) HubOption {
+	return func(h *Hub) {
+		if size > 0 {
+			h.broadcast = make(chan queuedMessage, size)
+		}
+	}
+}
+
+// WithHubClock sets the clock used for message ages, backpressure checks,
+// uptime and drain schedules. Tests inject a clock.Fake.
+func WithHubClock(clk clock.Clock) HubOption {
+	return func(h *Hub) {
+		h.clock = clk
+	}
+}
+
+// WithFanoutObserver sets the function each broadcast message's fan-out
+// time is reported to, the hub_broadcast_fanout_seconds histogram by
+// default.
+func WithFanoutObserver(observe func(time.Duration)) HubOption {
+	return func(h *Hub) {
+		h.observeFanout = observe
+	}
+}
+
+// Hub maintains the set of active clients and broadcasts messages to them.
+// It acts as the central message broker using Go channels for concurrent communication.
+type Hub struct {
+	// clients holds all currently connected clients
+	clients map[*Client]bool
+
+	// topics holds the topic patterns each client is subscribed to
+	topics *Subscriptions
+
+	// broadcast is the channel for inbound messages from data sources
+	broadcast chan queuedMessage
+
+	// legacyBroadcast feeds messages sent on Broadcast's channel to
+	// enqueue, started by the first call to Broadcast
+	legacyBroadcast     chan []byte
+	legacyBroadcastOnce sync.Once
+
+	// messageTTL is the maximum age of a message at fan-out time
+	messageTTL time.Duration
+
+	// slowClientPolicy handles broadcasts for clients with a full send buffer
+	slowClientPolicy SlowClientPolicy
+
+	// writeTimeout bounds each write of the clients' WritePump, and
+	// maxMessageAge is the age at which they skip queued price updates
+	writeTimeout  time.Duration
+	maxMessageAge time.Duration
+
+	// idleTimeout is how long clients may stay silent before they are
+	// reaped, zero for ever; reaped counts the reaped connections
+	idleTimeout time.Duration
+	reaped      atomic.Int64
+
+	// clock stamps and ages messages and drives the backpressure checks
+	clock clock.Clock
+
+	// metrics records the Hub's drops, fan-out time and backpressure, and
+	// observeFanout receives the fan-out time of each broadcast message
+	metrics       *Metrics
+	observeFanout func(time.Duration)
+
+	// region is the deployment region tagged on outgoing messages
+	region string
+
+	// draining is set while clients are moved off this server for maintenance
+	draining atomic.Bool
+
+	// startedAt is when Run was called, in Unix nanoseconds
+	startedAt atomic.Int64
+
+	// Backpressure detection on the broadcast channel
+	backpressureThreshold float64
+	backpressureSustain   time.Duration
+	pressureSince         time.Time // Owned by the Run loop
+
+	// backpressureMu protects the signaled state and its subscribers
+	backpressureMu   sync.Mutex
+	backpressure     Backpressure
+	backpressureSubs map[chan Backpressure]bool
+
+	// register is the channel for requests to register new clients
+	register chan *Client
+
+	// unregister is the channel for requests to unregister clients
+	unregister chan *Client
+
+	// mu protects concurrent access to the clients map
+	mu sync.RWMutex
+
+	// snapshot is the latest full state, sent to clients as they connect.
+	// It holds several frames when the state exceeds the maximum payload size.
+	snapshot [][]byte
+
+	// sourceMu protects the feed switchover state below
+	sourceMu sync.Mutex
+
+	// activeSource is the only ingestor whose updates reach clients.
+	// Empty means updates from all sources are merged.
+	activeSource string
+
+	// lastEventTimes holds the newest delivered exchange event time per symbol,
+	// used to deduplicate updates when several ingestors run side by side
+	lastEventTimes map[string]int64
+
+	// audit samples broadcasts to a file while enabled, nil without a file
+	audit *auditLog
+
+	// history keeps the recent broadcasts for long-polling clients
+	history broadcastHistory
+
+	// drops counts the messages that did not reach clients by reason
+	drops dropLog
+}
+
+// NewHub creates and initializes a new Hub instance.
+func NewHub(opts ...HubOption) *Hub {
+	hub := &Hub{
+		clients:    make(map[*Client]bool),
+		topics:     NewSubscriptions(),
+		broadcast:  make(chan queuedMessage, BroadcastBufferSize),
+		messageTTL: DefaultMessageTTL,
+		clock:      clock.Real,
+		register:   make(chan *Client),
+		unregister: make(chan *Client),
+
+		slowClientPolicy: SlowClientDisconnect,
+		writeTimeout:     DefaultWriteTimeout,
+		maxMessageAge:    DefaultMaxMessageAge,
+
+		backpressureThreshold: DefaultBackpressureThreshold,
+		backpressureSustain:   DefaultBackpressureSustain,
+		backpressureSubs:      make(map[chan Backpressure]bool),
+
+		lastEventTimes: make(map[string]int64),
+		history:        broadcastHistory{size: DefaultBroadcastHistory},
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(hub)
+	}
+
+	if hub.metrics == nil {
+		hub.metrics = NewMetrics(metrics.NewRegistry())
+	}
+	if hub.observeFanout == nil {
+		hub.observeFanout = func(d time.Duration) {
+			hub.metrics.broadcastFanout.Observe(d.Seconds())
+		}
+	}
+
+	return hub
+}
+
+// Run starts the hub's main loop to handle client registration, unregistration,
+// and message broadcasting. This should be run in a separate goroutine.
+func (h *Hub) Run() {
+	h.startedAt.Store(h.clock.Now().UnixNano())
+
+	pressureTicker := h.clock.NewTicker(BackpressureCheckInterval)
+	defer pressureTicker.Stop()
+
+	var reap <-chan time.Time
+	if h.idleTimeout > 0 {
+		reapTicker := h.clock.NewTicker(h.idleTimeout / 2)
+		defer reapTicker.Stop()
+		reap = reapTicker.C()
+	}
+
+	for {
+		select {
+		case client := <-h.register:
+			h.registerClient(client)
+
+		case client := <-h.unregister:
+			if client.timedOut.Load() {
+				h.reap(client)
+			} else {
+				h.unregisterClient(client)
+			}
+
+		case message := <-h.broadcast:
+			now := h.clock.Now()
+			if h.isExpired(message, now) {
+				h.recordDrop(DropExpired)
+				continue
+			}
+			h.auditBroadcast(message, now)
+			h.recordBroadcast(message.data)
+			if message.topic == "" {
+				h.broadcastMessage(message.data)
+			} else {
+				h.broadcastTopic(message.topic, message.data)
+			}
+			h.observeFanout(h.clock.Now().Sub(now))
+
+		case now := <-pressureTicker.C():
+			h.checkBackpressure(now)
+			h.flushBacklogs()
+
+		case now := <-reap:
+			h.reapIdle(now)
+		}
+	}
+}
+
+// isExpired reports whether a queued message is older than the message TTL.
+func (h *Hub) isExpired(message queuedMessage, now time.Time) bool {
+	return h.messageTTL > 0 && now.Sub(message.queuedAt) > h.messageTTL
+}
+
+// registerClient adds a new client to the hub.
+func (h *Hub) registerClient(client *Client) {
+	h.subscribeTopics(client)
+
+	h.mu.Lock()
+	h.identify(client)
+	h.clients[client] = true
+	clientCount := len(h.clients)
+	snapshot := h.snapshot
+	h.mu.Unlock()
+
+	// Give the new client the current state without waiting for the next
+	// tick, if it receives prices
+	if !h.receives(client, TopicPrices) {
+		snapshot = nil
+	}
+	for _, frame := range snapshot {
+		var cache renderCache
+		if frame = client.payloadFor(frame, &cache); frame == nil {
+			continue
+		}
+		client.enqueue(frame, h.clock.Now())
+	}
+
+	log.Printf("New client connected! Total active clients: %d", clientCount)
+}
+
+// unregisterClient removes a client from the hub and closes its send
+// channel. It reports false if the client was not registered.
+func (h *Hub) unregisterClient(client *Client) bool {
+	h.mu.Lock()
+	if _, exists := h.clients[client]; exists {
+		delete(h.clients, client)
+		h.topics.RemoveClient(client)
+		close(client.Send)
+		clientCount := len(h.clients)
+		h.mu.Unlock()
+		client.dropAlerts()
+		log.Printf("Client disconnected! Remaining clients: %d", clientCount)
+		return true
+	}
+	h.mu.Unlock()
+	return false
+}
+
+// broadcastMessage sends a message to all connected clients, whatever their
+// topics. Clients whose send channel is full are handled by the slow client
+// policy.
+func (h *Hub) broadcastMessage(message []byte) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if len(h.clients) == 0 {
+		h.recordDrop(DropNoSubscribers)
+		return
+	}
+
+	// Rendered at most once per broadcast for each encoding and projection
+	// of the connected clients
+	var cache renderCache
+
+	for client := range h.clients {
+		h.deliver(client, message, &cache)
+	}
+}
+
+// SendTo queues a message for one client, e.g. the response to a command,
+// unless the client is no longer registered. It reports whether the message
+// was queued; a full send buffer drops it without disconnecting the client.
+func (h *Hub) SendTo(client *Client, message []byte) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if !h.clients[client] {
+		return false
+	}
+	return client.enqueue(message, h.clock.Now())
+}
+
+// GetClientCount returns the number of currently connected clients.
+// This method is safe for concurrent use.
+func (h *Hub) GetClientCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// StartedAt returns when the Run loop was started, or the zero time if it
+// is not running.
+func (h *Hub) StartedAt() time.Time {
+	if nanos := h.startedAt.Load(); nanos != 0 {
+		return time.Unix(0, nanos)
+	}
+	return time.Time{}
+}
+
+// QueueDepth returns the number of messages waiting in the broadcast
+// channel and its capacity.
+func (h *Hub) QueueDepth() (depth, capacity int) {
+	return len(h.broadcast), cap(h.broadcast)
+}
+
+// ClientBufferUtilization returns the fill ratio (0-1) of the fullest
+// client send buffer, an early sign of slow clients about to be dropped.
+func (h *Hub) ClientBufferUtilization() float64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	utilization := 0.0
+	for client := range h.clients {
+		if capacity := cap(client.Send); capacity > 0 {
+			utilization = max(utilization, float64(len(client.Send))/float64(capacity))
+		}
+	}
+	return utilization
+}
+
+// SetSnapshot stores the latest full state frames that newly registered
+// clients receive immediately. Passing no frames clears the snapshot.
+func (h *Hub) SetSnapshot(snapshot ...[]byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.snapshot = snapshot
+}
+
+// Snapshot returns the latest full state frames, or nil if none is set.
+func (h *Hub) Snapshot() [][]byte {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.snapshot
+}
+
+// SetActiveSource selects the ingestor whose updates reach clients.
+// An empty name merges updates from all sources.
+func (h *Hub) SetActiveSource(name string) {
+	h.sourceMu.Lock()
+	defer h.sourceMu.Unlock()
+	h.activeSource = name
+}
+
+// ActiveSource returns the selected ingestor name, or "" when merging all sources.
+func (h *Hub) ActiveSource() string {
+	h.sourceMu.Lock()
+	defer h.sourceMu.Unlock()
+	return h.activeSource
+}
+
+// IsActiveSource reports whether updates from the named ingestor reach clients.
+func (h *Hub) IsActiveSource(name string) bool {
+	h.sourceMu.Lock()
+	defer h.sourceMu.Unlock()
+	return h.activeSource == "" || h.activeSource == name
+}
+
+// acceptUpdates filters price updates from the given source, dropping all of
+// them if the source is inactive and any update whose symbol and event time
+// were already delivered. Updates without an event time are always accepted.
+func (h *Hub) acceptUpdates(source string, updates []*PriceUpdate) []*PriceUpdate {
+	h.sourceMu.Lock()
+	defer h.sourceMu.Unlock()
+
+	if h.activeSource != "" && h.activeSource != source {
+		return nil
+	}
+
+	accepted := updates[:0]
+	for _, update := range updates {
+		if update.EventTime != 0 {
+			if last, seen := h.lastEventTimes[update.key()]; seen && update.EventTime <= last {
+				continue
+			}
+			h.lastEventTimes[update.key()] = update.EventTime
+		}
+		accepted = append(accepted, update)
+	}
+
+	return accepted
+}
+
+// Publish queues a message for all clients without blocking. It reports
+// whether the message was accepted; it is rejected if the channel is full
+// and counted as a DropChannelFull drop.
+// External data sources use it to reach clients. Sources should slow down
+// before that point when SubscribeBackpressure signals backpressure.
+func (h *Hub) Publish(data []byte) bool {
+	return h.enqueue(queuedMessage{data: data, queuedAt: h.clock.Now()})
+}
+
+// enqueue queues a message for the Run loop without blocking.
+func (h *Hub) enqueue(message queuedMessage) bool {
+	select {
+	case h.broadcast <- message:
+		return true
+	default:
+		h.recordDrop(DropChannelFull)
+		return false
+	}
+}
+
+// Broadcast returns a channel for sending messages to all clients.
+// Messages are queued as by Publish when the Hub reads them off the
+// channel, so those that do not fit are dropped instead of blocking.
+//
+// Deprecated: Use Publish, which reports whether a message was accepted.
+func (h *Hub) Broadcast() chan<- []byte {
+	h.legacyBroadcastOnce.Do(func() {
+		h.legacyBroadcast = make(chan []byte, BroadcastBufferSize)
+		go func() {
+			for data := range h.legacyBroadcast {
+				h.Publish(data)
+			}
+		}()
+	})
+	return h.legacyBroadcast
+}
+
+// Register returns the register channel for adding new clients.
+func (h *Hub) Register() chan<- *Client {
+	return h.register
+}
+
+// Unregister returns the unregister channel for removing clients.
+func (h *Hub) Unregister() chan<- *Client {
+	return h.unregister
+}
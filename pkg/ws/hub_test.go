@@ -3,8 +3,16 @@ package ws
 import (
 	"testing"
 	"time"
+
+	"macro-analyst/pkg/clock"
 )
 
+// flushHub returns once the Run loop has handled every request sent to it
+// before, by sending a no-op unregistration through it.
+func flushHub(hub *Hub) {
+	hub.unregister <- &Client{}
+}
+
 // TestNewHub verifies Hub initialization.
 func TestNewHub(t *testing.T) {
 	hub := NewHub()
@@ -42,9 +50,6 @@ func TestHubGetClientCount(t *testing.T) {
 	// Start hub in background
 	go hub.Run()
 
-	// Give hub time to start
-	time.Sleep(10 * time.Millisecond)
-
 	// Verify count is still 0
 	if count := hub.GetClientCount(); count != 0 {
 		t.Errorf("Expected 0 clients after Run(), got %d", count)
@@ -73,9 +78,6 @@ func TestHubConcurrentAccess(t *testing.T) {
 	hub := NewHub()
 	go hub.Run()
 
-	// Give hub time to start
-	time.Sleep(10 * time.Millisecond)
-
 	// Spawn multiple goroutines reading client count
 	done := make(chan bool)
 	for i := 0; i < 10; i++ {
@@ -98,8 +100,6 @@ func TestHubRegisterClient(t *testing.T) {
 	hub := NewHub()
 	go hub.Run()
 
-	time.Sleep(10 * time.Millisecond)
-
 	client := &Client{
 		Hub:  hub,
 		Send: make(chan []byte, 256),
@@ -107,7 +107,7 @@ func TestHubRegisterClient(t *testing.T) {
 
 	// Register client
 	hub.register <- client
-	time.Sleep(10 * time.Millisecond)
+	flushHub(hub)
 
 	if count := hub.GetClientCount(); count != 1 {
 		t.Errorf("Expected 1 client after registration, got %d", count)
@@ -119,8 +119,6 @@ func TestHubUnregisterClient(t *testing.T) {
 	hub := NewHub()
 	go hub.Run()
 
-	time.Sleep(10 * time.Millisecond)
-
 	client := &Client{
 		Hub:  hub,
 		Send: make(chan []byte, 256),
@@ -128,10 +126,10 @@ func TestHubUnregisterClient(t *testing.T) {
 
 	// Register then unregister
 	hub.register <- client
-	time.Sleep(10 * time.Millisecond)
+	flushHub(hub)
 
 	hub.unregister <- client
-	time.Sleep(10 * time.Millisecond)
+	flushHub(hub)
 
 	if count := hub.GetClientCount(); count != 0 {
 		t.Errorf("Expected 0 clients after unregistration, got %d", count)
@@ -149,19 +147,17 @@ func TestHubBroadcast(t *testing.T) {
 	hub := NewHub()
 	go hub.Run()
 
-	time.Sleep(10 * time.Millisecond)
-
 	client := &Client{
 		Hub:  hub,
 		Send: make(chan []byte, 256),
 	}
 
 	hub.register <- client
-	time.Sleep(10 * time.Millisecond)
+	flushHub(hub)
 
 	// Broadcast a message
 	testMessage := []byte("test message")
-	hub.broadcast <- testMessage
+	hub.Publish(testMessage)
 
 	// Wait for message to be delivered
 	select {
@@ -174,13 +170,37 @@ func TestHubBroadcast(t *testing.T) {
 	}
 }
 
+// TestHubBroadcastChannel verifies messages sent on the deprecated
+// Broadcast channel are published.
+func TestHubBroadcastChannel(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	client := &Client{
+		Hub:  hub,
+		Send: make(chan []byte, 256),
+	}
+
+	hub.register <- client
+	flushHub(hub)
+
+	hub.Broadcast() <- []byte("test message")
+
+	select {
+	case msg := <-client.Send:
+		if string(msg) != "test message" {
+			t.Errorf("Expected message test message, got %s", msg)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Error("Timeout waiting for broadcast message")
+	}
+}
+
 // TestHubBroadcastToMultipleClients verifies broadcasting to multiple clients.
 func TestHubBroadcastToMultipleClients(t *testing.T) {
 	hub := NewHub()
 	go hub.Run()
 
-	time.Sleep(10 * time.Millisecond)
-
 	clients := []*Client{
 		{Hub: hub, Send: make(chan []byte, 256)},
 		{Hub: hub, Send: make(chan []byte, 256)},
@@ -191,7 +211,7 @@ func TestHubBroadcastToMultipleClients(t *testing.T) {
 	for _, client := range clients {
 		hub.register <- client
 	}
-	time.Sleep(10 * time.Millisecond)
+	flushHub(hub)
 
 	if count := hub.GetClientCount(); count != 3 {
 		t.Errorf("Expected 3 clients, got %d", count)
@@ -199,7 +219,7 @@ func TestHubBroadcastToMultipleClients(t *testing.T) {
 
 	// Broadcast a message
 	testMessage := []byte("broadcast to all")
-	hub.broadcast <- testMessage
+	hub.Publish(testMessage)
 
 	// Verify all clients received the message
 	for i, client := range clients {
@@ -219,8 +239,6 @@ func TestHubUnregisterNonExistentClient(t *testing.T) {
 	hub := NewHub()
 	go hub.Run()
 
-	time.Sleep(10 * time.Millisecond)
-
 	client := &Client{
 		Hub:  hub,
 		Send: make(chan []byte, 256),
@@ -228,7 +246,7 @@ func TestHubUnregisterNonExistentClient(t *testing.T) {
 
 	// Try to unregister without registering first (should not panic)
 	hub.unregister <- client
-	time.Sleep(10 * time.Millisecond)
+	flushHub(hub)
 
 	if count := hub.GetClientCount(); count != 0 {
 		t.Errorf("Expected 0 clients, got %d", count)
@@ -240,8 +258,6 @@ func TestHubBroadcastWithFullChannel(t *testing.T) {
 	hub := NewHub()
 	go hub.Run()
 
-	time.Sleep(10 * time.Millisecond)
-
 	// Create a client with a small buffer
 	client := &Client{
 		Hub:  hub,
@@ -249,16 +265,21 @@ func TestHubBroadcastWithFullChannel(t *testing.T) {
 	}
 
 	hub.register <- client
-	time.Sleep(10 * time.Millisecond)
+	flushHub(hub)
 
 	// Fill the channel
 	client.Send <- []byte("filling")
 
 	// Try to broadcast (should trigger removal of client)
-	hub.broadcast <- []byte("test")
-	time.Sleep(50 * time.Millisecond)
+	hub.Publish([]byte("test"))
+
+	// Client should be removed once the Run loop handled the broadcast and
+	// the resulting unregistration
+	deadline := time.Now().Add(time.Second)
+	for hub.GetClientCount() != 0 && time.Now().Before(deadline) {
+		flushHub(hub)
+	}
 
-	// Client should be removed
 	if count := hub.GetClientCount(); count != 0 {
 		t.Errorf("Expected 0 clients after channel full, got %d", count)
 	}
@@ -269,8 +290,6 @@ func TestMultipleRegisterSameClient(t *testing.T) {
 	hub := NewHub()
 	go hub.Run()
 
-	time.Sleep(10 * time.Millisecond)
-
 	client := &Client{
 		Hub:  hub,
 		Send: make(chan []byte, 256),
@@ -279,7 +298,7 @@ func TestMultipleRegisterSameClient(t *testing.T) {
 	// Register same client twice
 	hub.register <- client
 	hub.register <- client
-	time.Sleep(10 * time.Millisecond)
+	flushHub(hub)
 
 	// Should still only count as 1 client (map behavior)
 	if count := hub.GetClientCount(); count != 1 {
@@ -287,3 +306,99 @@ func TestMultipleRegisterSameClient(t *testing.T) {
 	}
 }
 
+// TestHubSendsSnapshotOnRegister verifies new clients receive the current snapshot.
+func TestHubSendsSnapshotOnRegister(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	hub.SetSnapshot([]byte("snapshot"))
+
+	client := &Client{
+		Hub:  hub,
+		Send: make(chan []byte, 256),
+	}
+	hub.register <- client
+
+	select {
+	case msg := <-client.Send:
+		if string(msg) != "snapshot" {
+			t.Errorf("Expected snapshot message, got %s", msg)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Error("Timeout waiting for snapshot message")
+	}
+}
+
+// TestHubPublishWithFullChannel verifies Publish does not block on overflow.
+func TestHubPublishWithFullChannel(t *testing.T) {
+	hub := NewHub()
+
+	for i := 0; i < BroadcastBufferSize; i++ {
+		if !hub.Publish([]byte("filler")) {
+			t.Fatalf("Expected message %d to be accepted", i)
+		}
+	}
+
+	if hub.Publish([]byte("overflow")) {
+		t.Error("Expected Publish to reject a message when the channel is full")
+	}
+}
+
+// TestHubDropsExpiredMessages verifies messages older than the TTL are not
+// fanned out after a stall.
+func TestHubDropsExpiredMessages(t *testing.T) {
+	clk := clock.NewFake(time.Unix(0, 0))
+	hub := NewHub(WithHubClock(clk), WithMessageTTL(20*time.Millisecond))
+
+	client := &Client{Hub: hub, Send: make(chan []byte, 256)}
+
+	// Queue a message while the Run loop is stalled
+	hub.Publish([]byte("stale"))
+	clk.Advance(40 * time.Millisecond)
+
+	go hub.Run()
+	hub.register <- client
+	hub.Publish([]byte("fresh"))
+
+	select {
+	case msg := <-client.Send:
+		if string(msg) != "fresh" {
+			t.Errorf("Expected fresh message, got %s", msg)
+		}
+	case <-time.After(time.Second):
+		t.Error("Timeout waiting for fresh message")
+	}
+}
+
+// TestHubMessageTTLDisabled verifies a zero TTL never expires messages.
+func TestHubMessageTTLDisabled(t *testing.T) {
+	hub := NewHub(WithMessageTTL(0))
+	message := queuedMessage{data: []byte("old"), queuedAt: time.Now().Add(-time.Hour)}
+
+	if hub.isExpired(message, time.Now()) {
+		t.Error("Expected no expiry with TTL disabled")
+	}
+}
+
+// TestHubObservesBroadcastFanout verifies each broadcast message reports
+// its fan-out time, measured on the hub clock.
+func TestHubObservesBroadcastFanout(t *testing.T) {
+	observed := make(chan time.Duration, 2)
+	clk := clock.NewFake(time.Unix(0, 0))
+	hub := NewHub(WithHubClock(clk), WithFanoutObserver(func(d time.Duration) { observed <- d }))
+	go hub.Run()
+
+	hub.Publish([]byte(`{"type":"heartbeat"}`))
+	hub.PublishTopic(TopicMacro, []byte(`{"type":"macro_update"}`))
+
+	for range 2 {
+		select {
+		case d := <-observed:
+			if d != 0 {
+				t.Errorf("Expected no fan-out time on a stopped clock, got %v", d)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for a fan-out observation")
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package ws
+
+import (
+	"log"
+	"time"
+)
+
+// DefaultIdleTimeout is how long a client may stay silent, answering no
+// ping, before it is considered dead
+const DefaultIdleTimeout = 60 * time.Second
+
+// WithIdleTimeout closes the connections of clients that send no frame
+// within timeout. Clients are pinged every half of it, so a live client's
+// pongs keep it connected even if it sends no commands; a dead one is
+// reaped instead of lingering until its send buffer fills up, by the Hub or
+// by the read deadline of its ReadPump, whichever notices first. The API
+// server passes DefaultIdleTimeout unless configured otherwise. Zero, like
+// leaving the option out, disables pings and reaping.
+func WithIdleTimeout(timeout time.Duration) HubOption {
+	return func(h *Hub) {
+		h.idleTimeout = timeout
+	}
+}
+
+// ReapedConnections returns how many idle connections the Hub closed.
+func (h *Hub) ReapedConnections() int64 {
+	return h.reaped.Load()
+}
+
+// pingInterval returns how often WritePump pings the client, zero for
+// never.
+func (c *Client) pingInterval() time.Duration {
+	if c.Hub == nil {
+		return 0
+	}
+	return c.Hub.idleTimeout / 2
+}
+
+// touch records that a frame of the client arrived at now.
+func (c *Client) touch(now time.Time) {
+	c.lastSeen.Store(now.UnixNano())
+}
+
+// received records that a frame of the client arrived and gives ReadPump
+// another idle timeout to read the next one.
+func (c *Client) received() {
+	c.touch(c.clock().Now())
+	c.extendReadDeadline()
+}
+
+// extendReadDeadline sets the read deadline of the connection to the idle
+// timeout from now, if enabled. Connection deadlines are wall-clock times.
+func (c *Client) extendReadDeadline() {
+	if c.Hub != nil && c.Hub.idleTimeout > 0 {
+		c.Conn.SetReadDeadline(time.Now().Add(c.Hub.idleTimeout))
+	}
+}
+
+// reapIdle unregisters the clients that sent no frame within the idle
+// timeout, which closes their connections. It is called from the Run loop.
+func (h *Hub) reapIdle(now time.Time) {
+	deadline := now.Add(-h.idleTimeout).UnixNano()
+
+	h.mu.RLock()
+	var idle []*Client
+	for client := range h.clients {
+		if client.lastSeen.Load() < deadline {
+			idle = append(idle, client)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, client := range idle {
+		h.reap(client)
+	}
+}
+
+// reap unregisters a client that sent no frame within the idle timeout,
+// counting it unless it was already gone. It is called from the Run loop,
+// for clients found idle and for those ReadPump unregisters after its read
+// deadline passed.
+func (h *Hub) reap(client *Client) {
+	if !h.unregisterClient(client) {
+		return
+	}
+	log.Printf("⚠ Closed connection %d from %s, silent for over %s", client.id, client.RemoteIP, h.idleTimeout)
+	h.reaped.Add(1)
+	h.metrics.reapedConnections.Inc()
+}
@@ -0,0 +1,72 @@
+package ws
+
+import (
+	"testing"
+	"time"
+
+	"macro-analyst/pkg/clock"
+)
+
+// TestPingInterval verifies clients are pinged every half of the idle
+// timeout, and not at all without one.
+func TestPingInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   *Client
+		expected time.Duration
+	}{
+		{"idle timeout", &Client{Hub: NewHub(WithIdleTimeout(time.Minute))}, 30 * time.Second},
+		{"disabled", &Client{Hub: NewHub()}, 0},
+		{"no hub", &Client{}, 0},
+	}
+
+	for _, tt := range tests {
+		if pingInterval := tt.client.pingInterval(); pingInterval != tt.expected {
+			t.Errorf("%s: expected pings every %s, got %s", tt.name, tt.expected, pingInterval)
+		}
+	}
+}
+
+// TestReapIdle verifies clients that sent no frame within the idle timeout
+// are unregistered and counted, while clients seen since stay connected.
+func TestReapIdle(t *testing.T) {
+	connected := time.Date(2024, 2, 20, 12, 0, 0, 0, time.UTC)
+	hub := NewHub(WithHubClock(clock.NewFake(connected)), WithIdleTimeout(time.Minute))
+
+	silent := &Client{Hub: hub, Send: make(chan []byte, 1)}
+	active := &Client{Hub: hub, Send: make(chan []byte, 1)}
+	hub.registerClient(silent)
+	hub.registerClient(active)
+	active.touch(connected.Add(50 * time.Second))
+
+	hub.reapIdle(connected.Add(59 * time.Second))
+	if hub.GetClientCount() != 2 || hub.ReapedConnections() != 0 {
+		t.Fatalf("Expected no client reaped within the timeout, got %d clients", hub.GetClientCount())
+	}
+
+	hub.reapIdle(connected.Add(61 * time.Second))
+	if hub.GetClientCount() != 1 || hub.ReapedConnections() != 1 {
+		t.Fatalf("Expected the silent client reaped, got %d clients and %d reaped", hub.GetClientCount(), hub.ReapedConnections())
+	}
+	if _, open := <-silent.Send; open {
+		t.Error("Expected the send channel of the reaped client closed")
+	}
+}
+
+// TestReapOnce verifies a client reaped by both the Hub and its read
+// deadline is counted once.
+func TestReapOnce(t *testing.T) {
+	hub := NewHub(WithIdleTimeout(time.Minute))
+	go hub.Run()
+	client := &Client{Hub: hub, Send: make(chan []byte, 1)}
+	hub.Register() <- client
+
+	// As ReadPump does on its read deadline, then the Hub's reaper
+	client.timedOut.Store(true)
+	hub.Unregister() <- client
+	hub.Unregister() <- client
+	hub.Unregister() <- &Client{} // Returns once the Run loop handled both
+	if hub.GetClientCount() != 0 || hub.ReapedConnections() != 1 {
+		t.Errorf("Expected the client reaped once, got %d clients and %d reaped", hub.GetClientCount(), hub.ReapedConnections())
+	}
+}
@@ -0,0 +1,880 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"macro-analyst/pkg/clock"
+	"macro-analyst/pkg/feedlog"
+	"macro-analyst/pkg/metrics"
+
+	"github.com/adshao/go-binance/v2"
+)
+
+const (
+	// DefaultThrottleInterval is the default time between price broadcasts
+	// to prevent overwhelming the clients
+	DefaultThrottleInterval = 500 * time.Millisecond
+
+	// MaxUpdatesPerSecond limits the number of updates sent to clients
+	MaxUpdatesPerSecond = 10
+
+	// DefaultSourceName identifies an ingestor when no name is configured
+	DefaultSourceName = "primary"
+)
+
+// PriceUpdate represents a single price update for a financial instrument.
+type PriceUpdate struct {
+	Symbol        string  `json:"symbol"`             // Trading symbol (e.g., "BTCUSDT")
+	Price         float64 `json:"price"`              // Current price
+	Change        float64 `json:"change"`             // Absolute price change
+	ChangePercent float64 `json:"changePercent"`      // Percentage change
+	Volume        int64   `json:"volume"`             // Trading volume
+	Timestamp     string  `json:"timestamp"`          // Update timestamp from the configured source
+	EventTime     int64   `json:"eventTime"`          // Exchange event time (Unix ms)
+	ReceivedAt    int64   `json:"receivedAt"`         // Server receive time (Unix ms)
+	Restored      bool    `json:"restored,omitempty"` // Restored from a snapshot, not yet live
+
+	// Percentage change since 00:00 UTC, omitted until the day open is known
+	SinceMidnightChangePercent *float64 `json:"sinceMidnightChangePercent,omitempty"`
+
+	// Price Change and ChangePercent are measured from
+	ChangeReference ChangeReference `json:"changeReference,omitempty"`
+
+	// Currency Price and Change are converted to, omitted for the quote
+	// asset of the symbol
+	Currency string `json:"currency,omitempty"`
+
+	// Exchange the price is from, set when the feed aggregates several
+	// exchanges (see WithExchanges)
+	Exchange string `json:"exchange,omitempty"`
+}
+
+// MultiUpdate represents a batch of price updates for multiple symbols.
+type MultiUpdate struct {
+	Type string         `json:"type"` // Always "multi_update"
+	Data []*PriceUpdate `json:"data"` // Array of price updates
+
+	// Set when an update was split to respect the maximum payload size:
+	// this frame is part Part (1-based) of Total
+	Part  int `json:"part,omitempty"`
+	Total int `json:"total,omitempty"`
+
+	// Deployment region of the server that sent the update, if configured
+	Region string `json:"region,omitempty"`
+}
+
+// Symbol represents a trading symbol being tracked.
+type Symbol struct {
+	Name            string
+	LastPrice       string
+	LastChange      string
+	LastPriceChange string
+	LastVolume      string
+	LastUpdateAt    time.Time
+	LastEventTime   int64 // Exchange event time (Unix ms) of the last update
+	Restored        bool  // Data was restored from a snapshot and no live event arrived yet
+
+	// Opening price of the UTC day DayOpenDate (YYYY-MM-DD)
+	DayOpen     float64
+	DayOpenDate string
+
+	// Price at the change anchor time, a decimal string, empty until loaded
+	AnchorPrice string
+
+	// Counters for debugging symbols that appear frozen on the frontend
+	EventsReceived   uint64
+	UpdatesBroadcast uint64
+	ParseFailures    uint64
+
+	// Events dropped as repeats of the last known event
+	DuplicatesSuppressed uint64
+
+	// Updates held back below the significance threshold, and the price
+	// the threshold is measured from
+	InsignificantSuppressed uint64
+	LastQueuedPrice         float64
+
+	// Events and parse failures in the current data quality window
+	windowEvents   int
+	windowFailures int
+
+	// Prices of the last minutes for sparklines
+	recent recentHistory
+}
+
+// SymbolStats reports per-symbol ingestion and broadcast counters.
+type SymbolStats struct {
+	Symbol               string     `json:"symbol"`
+	EventsReceived       uint64     `json:"events_received"`
+	UpdatesBroadcast     uint64     `json:"updates_broadcast"`
+	ParseFailures        uint64     `json:"parse_failures"`
+	DuplicatesSuppressed uint64     `json:"duplicates_suppressed"`
+	BelowThreshold       uint64     `json:"below_threshold"`
+	LastEventAt          *time.Time `json:"last_event_at,omitempty"`
+}
+
+// Ingestor connects to Binance WebSocket and publishes real-time market
+// data to its Sink, e.g. the clients of a Hub. It implements throttling to
+// prevent overwhelming clients with too many updates.
+type Ingestor struct {
+	name             string   // Source name used by the Hub for feed switchover
+	sink             Sink     // Receives every frame the ingestor publishes
+	audience         audience // Clients of the Hub behind the sink, if any
+	metrics          *Metrics
+	symbols          []*Symbol
+	mu               sync.RWMutex // Protects symbols and their cached data
+	pendingMu        sync.Mutex   // Protects the updates pending broadcast
+	throttleInterval time.Duration
+	clock            clock.Clock // Stamps events and drives the throttle and watchdog
+	ctx              context.Context
+	cancel           context.CancelFunc
+
+	// Binance connection: binance serves the ticker stream, stopC closes
+	// the current one, resubscribe asks the stream loop to reconnect after
+	// the symbols changed
+	binance     *binanceSource
+	stopC       chan struct{}
+	resubscribe chan struct{}
+
+	// Connection history: streamErr is the last error of the current stream
+	feedHistory     *feedlog.Log
+	streamErr       error
+	streamConnected atomic.Bool
+
+	// Wait before reconnecting after a failure, 0 to give up instead, and
+	// the consecutive reconnects attempted before giving up, 0 for no limit
+	reconnectBackoff time.Duration
+	maxReconnects    int
+
+	// Stale feed watchdog
+	staleFeedTimeout time.Duration
+	staleFeedWebhook string
+	httpClient       *http.Client
+	startedAt        time.Time
+	lastEventAt      atomic.Int64 // Unix nanoseconds of the last Binance event
+	feedStale        bool
+
+	// Snapshot persistence across restarts
+	snapshotFile string
+
+	// Data quality monitoring
+	dataQualityThreshold float64
+
+	// Minimum price moves before a symbol is broadcast again
+	significance       Threshold
+	symbolSignificance map[string]Threshold
+
+	// Source of the human-readable PriceUpdate timestamp
+	timestampSource TimestampSource
+
+	// Lookup of UTC midnight opens for the since-midnight change
+	fetchDayOpen DayOpenFetcher
+
+	// Price changes are measured from, and the lookup of anchor prices
+	changeReference ChangeReference
+	changeAnchor    time.Time
+	fetchAnchor     AnchorFetcher
+
+	// Symbols trading on Binance, which AddSymbol accepts
+	listSymbols SymbolLister
+	catalog     symbolCatalog
+
+	// 24h statistics TrackTopSymbols ranks the pairs by, and the symbols it
+	// added, protected by mu
+	listTickerStats TickerStatsLister
+	autoTracked     map[string]bool
+
+	// Largest serialized MultiUpdate before it is split into frames
+	maxPayloadSize int
+
+	// Throttle stretch while the Hub signals backpressure
+	backpressureFactor int
+	underBackpressure  atomic.Bool
+
+	// Binance REST credentials, never logged
+	binanceAPIKey    string
+	binanceSecretKey string
+
+	// Market data streamed from Binance, tickerStream unless an option
+	// selects another stream
+	stream marketStream
+
+	// Exchange rates updated from the conversion pairs streamed along with
+	// the symbols, nil for none
+	rates *FXRates
+
+	// In-memory history of recent prices per symbol
+	recentWindow     time.Duration
+	recentResolution time.Duration
+
+	// Other exchanges whose prices are aggregated with the Binance ones,
+	// and the signals resubscribing them to changed symbols, protected by mu
+	exchanges       []ExchangeSource
+	exchangeSignals []chan struct{}
+
+	// Price alerts evaluated on each update while this is the active feed
+	alerts *AlertBook
+
+	// Recording replayed, or mock prices generated, instead of streaming
+	// from Binance; nil when live
+	playback playback
+}
+
+// IngestorOption is a functional option for configuring the Ingestor.
+type IngestorOption func(*Ingestor)
+
+// WithThrottleInterval sets the minimum interval between broadcasts.
+func WithThrottleInterval(interval time.Duration) IngestorOption {
+	return func(i *Ingestor) {
+		i.throttleInterval = interval
+	}
+}
+
+// WithClock sets the clock used for event timestamps, the broadcast
+// throttle and the stale feed watchdog. Tests inject a clock.Fake.
+func WithClock(clk clock.Clock) IngestorOption {
+	return func(i *Ingestor) {
+		i.clock = clk
+	}
+}
+
+// WithSourceName sets the name identifying this ingestor's data to the Hub.
+// Names distinguish feeds when several ingestors run side by side.
+func WithSourceName(name string) IngestorOption {
+	return func(i *Ingestor) {
+		i.name = name
+	}
+}
+
+// WithSymbols replaces the default symbol set tracked by the ingestor.
+func WithSymbols(names []string) IngestorOption {
+	return func(i *Ingestor) {
+		symbols := make([]*Symbol, len(names))
+		for idx, name := range names {
+			symbols[idx] = &Symbol{Name: name}
+		}
+		i.symbols = symbols
+	}
+}
+
+// NewIngestor creates a new Ingestor with default crypto symbols,
+// publishing to sink, e.g. HubSink(hub).
+func NewIngestor(sink Sink, opts ...IngestorOption) *Ingestor {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Initialize with popular crypto trading symbols
+	symbols := []*Symbol{
+		{Name: "BTCUSDT"},
+		{Name: "ETHUSDT"},
+		{Name: "BNBUSDT"},
+		{Name: "SOLUSDT"},
+		{Name: "ADAUSDT"},
+		{Name: "XRPUSDT"},
+	}
+
+	ingestor := &Ingestor{
+		name:             DefaultSourceName,
+		sink:             sink,
+		symbols:          symbols,
+		throttleInterval: DefaultThrottleInterval,
+		clock:            clock.Real,
+		ctx:              ctx,
+		cancel:           cancel,
+		resubscribe:      make(chan struct{}, 1),
+		staleFeedTimeout: DefaultStaleFeedTimeout,
+		reconnectBackoff: DefaultReconnectBackoff,
+		httpClient:       &http.Client{Timeout: WebhookTimeout},
+
+		dataQualityThreshold: DefaultDataQualityThreshold,
+		timestampSource:      TimestampSourceReceived,
+		changeReference:      ChangeReference24h,
+		maxPayloadSize:       DefaultMaxPayloadSize,
+		backpressureFactor:   DefaultBackpressureThrottleFactor,
+		recentWindow:         DefaultRecentWindow,
+		recentResolution:     DefaultRecentResolution,
+		stream:               tickerStream{},
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(ingestor)
+	}
+
+	if ingestor.fetchDayOpen == nil {
+		ingestor.fetchDayOpen = ingestor.fetchBinanceDayOpen
+	}
+	if ingestor.fetchAnchor == nil {
+		ingestor.fetchAnchor = ingestor.fetchBinanceAnchor
+	}
+	if ingestor.listTickerStats == nil {
+		ingestor.listTickerStats = ingestor.ListTickerStats
+	}
+	if ingestor.listSymbols == nil {
+		ingestor.listSymbols = ingestor.listBinanceSymbols
+	}
+	if ingestor.binance == nil {
+		ingestor.binance = newBinanceSource()
+	}
+	if ingestor.metrics == nil {
+		ingestor.metrics = NewMetrics(metrics.NewRegistry())
+	}
+	if found, ok := findAudience(ingestor.sink); ok {
+		ingestor.audience = found
+	} else {
+		ingestor.audience = noAudience{}
+	}
+
+	return ingestor
+}
+
+// Start begins streaming real-time data from Binance WebSocket, or plays
+// back a recording or mock prices instead. By default it connects to
+// Binance's Combined Ticker Stream for multiple symbols and broadcasts
+// updates with throttling to prevent client overload.
+func (i *Ingestor) Start() {
+	log.Printf("Price Ingestor started - connecting to Binance WebSocket")
+	log.Printf("Tracking symbols: %v", i.GetSymbols())
+
+	// Watch for silent stream death while clients are connected
+	i.mu.Lock()
+	i.startedAt = i.clock.Now()
+	i.mu.Unlock()
+	go i.runFeedWatchdog()
+
+	if i.playback != nil {
+		i.playback.play(i)
+		return
+	}
+	i.stream.serve(i)
+}
+
+// StartMultiSymbol connects to Binance WebSocket for multiple symbols.
+// It streams all symbols over one combined ticker connection of its Binance
+// source, and re-establishes that connection whenever symbols are added or removed.
+func (i *Ingestor) StartMultiSymbol() {
+	if len(i.GetSymbols()) == 0 {
+		log.Println("No symbols to track")
+		return
+	}
+
+	throttleTicker := i.clock.NewTicker(i.throttleInterval)
+	defer throttleTicker.Stop()
+
+	var pendingUpdate *MultiUpdate
+
+	wsHandler := i.createWebSocketHandler(&pendingUpdate)
+	errHandler := i.createErrorHandler()
+	i.startExchanges(&pendingUpdate)
+
+	stream := i.binance.marketStats(wsHandler, errHandler)
+	i.runStream(func(symbols []string) (chan struct{}, chan struct{}, error) {
+		return stream(i.streamSymbols(symbols))
+	}, func() {
+		i.startThrottledBroadcast(throttleTicker, &pendingUpdate)
+	})
+}
+
+// streamServer opens a Binance combined stream for the given symbols.
+type streamServer func(symbols []string) (doneC, stopC chan struct{}, err error)
+
+// runStream keeps a Binance stream of the tracked symbols open until the
+// ingestor stops, re-establishing it whenever symbols change and, with
+// backoff, whenever connecting fails or the stream closes. connected, if
+// set, runs once after the first connection.
+func (i *Ingestor) runStream(serve streamServer, connected func()) {
+	connectEvent := feedlog.Connected
+	failures, reconnecting := 0, false
+	for {
+		symbols := i.GetSymbols()
+		if len(symbols) == 0 {
+			log.Println("No symbols to track, waiting for symbols to be added")
+			if !i.waitForResubscribe() {
+				return
+			}
+			continue
+		}
+
+		log.Printf("Connecting to Binance for %d symbols...", len(symbols))
+		doneC, err := i.connectToBinance(symbols, serve)
+		if err != nil {
+			log.Printf("Failed to connect to Binance: %v", err)
+			i.recordFeedEvent(feedlog.ConnectFailed, err.Error(), len(symbols))
+			failures++
+			if reconnecting = i.waitToReconnect(failures); !reconnecting {
+				return
+			}
+			continue
+		}
+		i.setStreamError(nil)
+		i.recordConnected(connectEvent, len(symbols))
+		connectEvent = feedlog.Reconnected
+		connectedAt := i.clock.Now()
+		if reconnecting {
+			i.reportStreamStatus(StreamStatusConnected, failures, 0)
+			reconnecting = false
+		}
+
+		if connected != nil {
+			connected()
+			connected = nil
+		}
+		if i.waitForShutdown(doneC) {
+			log.Printf("Symbols changed, re-establishing Binance stream")
+			continue
+		}
+
+		// Back off further while connections keep dropping right away
+		if i.clock.Now().Sub(connectedAt) > maxReconnectBackoff {
+			failures = 0
+		}
+		failures++
+		if reconnecting = i.waitToReconnect(failures); !reconnecting {
+			return
+		}
+	}
+}
+
+// createWebSocketHandler creates a handler for incoming WebSocket events.
+func (i *Ingestor) createWebSocketHandler(pendingUpdate **MultiUpdate) func(*binance.WsMarketStatEvent) {
+	return func(event *binance.WsMarketStatEvent) {
+		i.markEventReceived(i.clock.Now())
+
+		// Conversion pairs only update the exchange rates, unless tracked
+		if i.updateConversionRate(event.Symbol, event.LastPrice) {
+			return
+		}
+
+		// Skip invalid events so charts keep the last good value instead of zero
+		priceUpdate, err := i.convertEventToPriceUpdate(event)
+		i.recordEvent(event.Symbol, err)
+		if err != nil {
+			return
+		}
+
+		// Drop replays of the last event, e.g. right after a reconnect
+		if i.isDuplicateEvent(event) {
+			return
+		}
+
+		i.updateSymbolData(event)
+		i.recordRecent(priceUpdate.Symbol, priceUpdate.Price, i.clock.Now())
+		i.evaluateAlerts(priceUpdate.Symbol, priceUpdate.Price, i.clock.Now())
+
+		// Hold back moves too small to matter, e.g. for stable pairs
+		if !i.isSignificant(priceUpdate) {
+			return
+		}
+
+		i.applyDayOpen(priceUpdate)
+		i.applyChangeReference(priceUpdate, event.LastPrice, event.PriceChange)
+		if len(i.exchanges) > 0 {
+			priceUpdate.Exchange = ExchangeBinance
+		}
+		i.queuePriceUpdate(pendingUpdate, priceUpdate)
+	}
+}
+
+// createErrorHandler creates an error handler for WebSocket errors.
+func (i *Ingestor) createErrorHandler() func(error) {
+	return func(err error) {
+		log.Printf("Binance WebSocket error: %v", err)
+		i.setStreamError(err)
+	}
+}
+
+// connectToBinance establishes a WebSocket connection to Binance.
+func (i *Ingestor) connectToBinance(symbols []string, serve streamServer) (chan struct{}, error) {
+	doneC, stopC, err := serve(symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	i.mu.Lock()
+	i.stopC = stopC
+	i.mu.Unlock()
+	return doneC, nil
+}
+
+// closeStream closes the current Binance connection, if any.
+func (i *Ingestor) closeStream() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.stopC != nil {
+		close(i.stopC)
+		i.stopC = nil
+	}
+}
+
+// requestResubscribe asks the stream loop to reconnect with the current
+// symbols, and the exchange sources to resubscribe. Requests made while one
+// is pending are merged. The caller must hold i.mu.
+func (i *Ingestor) requestResubscribe() {
+	select {
+	case i.resubscribe <- struct{}{}:
+	default:
+	}
+	i.signalExchanges()
+}
+
+// queuePriceUpdate adds or updates a price update in the pending queue.
+func (i *Ingestor) queuePriceUpdate(pendingUpdate **MultiUpdate, priceUpdate *PriceUpdate) {
+	i.pendingMu.Lock()
+	defer i.pendingMu.Unlock()
+
+	if *pendingUpdate == nil {
+		*pendingUpdate = &MultiUpdate{
+			Type: "multi_update",
+			Data: []*PriceUpdate{priceUpdate},
+		}
+		return
+	}
+
+	i.updateOrAppendPrice(*pendingUpdate, priceUpdate)
+}
+
+// updateOrAppendPrice updates an existing symbol of the same exchange or
+// appends a new one.
+func (i *Ingestor) updateOrAppendPrice(multiUpdate *MultiUpdate, priceUpdate *PriceUpdate) {
+	for idx, existing := range multiUpdate.Data {
+		if existing.key() == priceUpdate.key() {
+			multiUpdate.Data[idx] = priceUpdate
+			return
+		}
+	}
+	multiUpdate.Data = append(multiUpdate.Data, priceUpdate)
+}
+
+// startThrottledBroadcast starts a goroutine that broadcasts updates at a controlled rate.
+// The rate drops while the Hub signals backpressure.
+func (i *Ingestor) startThrottledBroadcast(throttleTicker clock.Ticker, pendingUpdate **MultiUpdate) {
+	backpressure := i.audience.SubscribeBackpressure()
+
+	go func() {
+		defer i.audience.UnsubscribeBackpressure(backpressure)
+
+		for {
+			select {
+			case <-i.ctx.Done():
+				log.Println("Ingestor stopped")
+				return
+			case state := <-backpressure:
+				i.applyBackpressure(state, throttleTicker)
+			case <-throttleTicker.C():
+				i.broadcastPendingUpdates(pendingUpdate)
+			}
+		}
+	}()
+}
+
+// broadcastPendingUpdates marshals and broadcasts pending updates to the hub.
+func (i *Ingestor) broadcastPendingUpdates(pendingUpdate **MultiUpdate) {
+	// Take the pending updates so the stream handler can queue new ones
+	i.pendingMu.Lock()
+	update := *pendingUpdate
+	*pendingUpdate = nil
+	i.pendingMu.Unlock()
+
+	if update == nil || len(update.Data) == 0 {
+		return
+	}
+
+	// Drop updates from inactive feeds and ones another feed already delivered
+	update.Data = i.audience.acceptUpdates(i.name, update.Data)
+	if len(update.Data) == 0 {
+		return
+	}
+
+	frames, err := i.encodeFrames(update)
+	if err != nil {
+		log.Printf("Error marshaling update: %v", err)
+		return
+	}
+
+	sent := true
+	for _, frame := range frames {
+		sent = i.sendToHub(frame, len(update.Data)) && sent
+	}
+	if sent {
+		i.recordBroadcast(update.Data)
+	}
+
+	// Keep the Hub snapshot current for newly connecting clients
+	i.refreshHubSnapshot()
+}
+
+// sendToHub publishes data to the sink with overflow protection. It
+// reports whether the Hub behind the sink accepted the data.
+func (i *Ingestor) sendToHub(data []byte, updateCount int) bool {
+	if !i.publish(TopicPrices, data) {
+		return false
+	}
+	log.Printf("✓ Broadcasted %d symbol updates", updateCount)
+	return true
+}
+
+// recordBroadcast counts the broadcast updates per symbol.
+func (i *Ingestor) recordBroadcast(updates []*PriceUpdate) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, update := range updates {
+		if symbol := i.findSymbol(update.Symbol); symbol != nil {
+			symbol.UpdatesBroadcast++
+		}
+	}
+}
+
+// waitForShutdown waits for WebSocket closure, context cancellation or a
+// change of symbols. It reports whether the stream should be re-established;
+// in that case the current connection is closed first.
+func (i *Ingestor) waitForShutdown(doneC chan struct{}) bool {
+	select {
+	case <-doneC:
+		log.Println("Binance WebSocket connection closed")
+		i.recordDisconnected(i.disconnectCause())
+		return false
+	case <-i.ctx.Done():
+		log.Println("Ingestor context cancelled")
+		i.recordDisconnected(causeStopped)
+		return false
+	case <-i.resubscribe:
+		i.closeStream()
+		<-doneC
+		i.recordDisconnected(causeSymbolsChanged)
+		return true
+	}
+}
+
+// waitForResubscribe waits for symbols to be added while none are tracked.
+// It reports false if the ingestor was stopped instead.
+func (i *Ingestor) waitForResubscribe() bool {
+	select {
+	case <-i.ctx.Done():
+		return false
+	case <-i.resubscribe:
+		return true
+	}
+}
+
+// Stop gracefully stops the ingestor and closes its WebSocket connection.
+func (i *Ingestor) Stop() {
+	log.Println("Stopping Price Ingestor...")
+	i.cancel()
+	i.closeStream()
+	i.recordDisconnected(causeStopped)
+}
+
+// updateSymbolData updates the cached symbol data from a Binance event.
+func (i *Ingestor) updateSymbolData(event *binance.WsMarketStatEvent) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	symbol := i.findSymbol(event.Symbol)
+	if symbol != nil {
+		symbol.LastPrice = event.LastPrice
+		symbol.LastChange = event.PriceChangePercent
+		symbol.LastPriceChange = event.PriceChange
+		symbol.LastVolume = event.BaseVolume
+		symbol.LastUpdateAt = i.clock.Now()
+		symbol.LastEventTime = event.Time
+		symbol.Restored = false
+	}
+}
+
+// convertEventToPriceUpdate converts a Binance event to our PriceUpdate
+// format, stamped with the ingestor's clock and timestamp source.
+func (i *Ingestor) convertEventToPriceUpdate(event *binance.WsMarketStatEvent) (*PriceUpdate, error) {
+	receivedAt := i.clock.Now()
+	update, err := convertMarketStatEvent(event, receivedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	i.observeLatency(event.Time, receivedAt)
+	update.Timestamp = i.formatTimestamp(event.Time, receivedAt)
+	return update, nil
+}
+
+// convertMarketStatEvent converts a Binance ticker event received at
+// receivedAt. It returns an error if any numeric field fails to parse or
+// the price is not positive, since such events would otherwise show up as
+// zero prices.
+func convertMarketStatEvent(event *binance.WsMarketStatEvent, receivedAt time.Time) (*PriceUpdate, error) {
+	price, err := strconv.ParseFloat(event.LastPrice, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid last price %q for %s: %w", event.LastPrice, event.Symbol, err)
+	}
+	if price <= 0 {
+		return nil, fmt.Errorf("non-positive last price %q for %s", event.LastPrice, event.Symbol)
+	}
+
+	change, err := strconv.ParseFloat(event.PriceChange, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid price change %q for %s: %w", event.PriceChange, event.Symbol, err)
+	}
+
+	changePercent, err := strconv.ParseFloat(event.PriceChangePercent, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid price change percent %q for %s: %w", event.PriceChangePercent, event.Symbol, err)
+	}
+
+	volume, err := strconv.ParseFloat(event.BaseVolume, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base volume %q for %s: %w", event.BaseVolume, event.Symbol, err)
+	}
+
+	return &PriceUpdate{
+		Symbol:        event.Symbol,
+		Price:         price,
+		Change:        change,
+		ChangePercent: changePercent,
+		Volume:        int64(volume),
+		EventTime:     event.Time,
+		ReceivedAt:    receivedAt.UnixMilli(),
+	}, nil
+}
+
+// key identifies the symbol of an update among the updates of every
+// exchange: the symbol, suffixed with the exchange if tagged.
+func (u *PriceUpdate) key() string {
+	if u.Exchange == "" {
+		return u.Symbol
+	}
+	return u.Symbol + "@" + u.Exchange
+}
+
+// Stats returns ingestion and broadcast counters for all tracked symbols.
+func (i *Ingestor) Stats() []SymbolStats {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	stats := make([]SymbolStats, len(i.symbols))
+	for idx, symbol := range i.symbols {
+		stats[idx] = SymbolStats{
+			Symbol:               symbol.Name,
+			EventsReceived:       symbol.EventsReceived,
+			UpdatesBroadcast:     symbol.UpdatesBroadcast,
+			ParseFailures:        symbol.ParseFailures,
+			DuplicatesSuppressed: symbol.DuplicatesSuppressed,
+			BelowThreshold:       symbol.InsignificantSuppressed,
+		}
+		if symbol.EventsReceived > 0 {
+			lastEventAt := symbol.LastUpdateAt
+			stats[idx].LastEventAt = &lastEventAt
+		}
+	}
+	return stats
+}
+
+// AddSymbol adds a trading symbol to the ingestor's watchlist after
+// normalizing its case and validating it against the Binance catalog (see
+// ValidateSymbol). A running ingestor re-establishes its Binance stream to
+// include it. It returns false if the symbol is already tracked; a symbol
+// tracked by TrackTopSymbols then stays tracked when it leaves the top.
+func (i *Ingestor) AddSymbol(ctx context.Context, name string) (bool, error) {
+	symbol, err := i.ValidateSymbol(ctx, name)
+	if err != nil {
+		return false, err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.findSymbol(symbol) != nil {
+		// Adding an automatically tracked symbol keeps it tracked
+		delete(i.autoTracked, symbol)
+		return false, nil
+	}
+
+	i.symbols = append(i.symbols, &Symbol{Name: symbol})
+	i.requestResubscribe()
+	log.Printf("Added symbol: %s", symbol)
+	return true, nil
+}
+
+// RemoveSymbol removes a symbol from the ingestor's watchlist. A running
+// ingestor re-establishes its Binance stream without it.
+func (i *Ingestor) RemoveSymbol(name string) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for idx, symbol := range i.symbols {
+		if symbol.Name == name {
+			// Remove symbol by swapping with last element and truncating
+			i.symbols[idx] = i.symbols[len(i.symbols)-1]
+			i.symbols = i.symbols[:len(i.symbols)-1]
+			delete(i.autoTracked, name)
+			i.requestResubscribe()
+			log.Printf("Removed symbol: %s", name)
+			return true
+		}
+	}
+	return false
+}
+
+// ValidSymbol reports whether name looks like a Binance trading symbol
+// such as BTCUSDT.
+func ValidSymbol(name string) bool {
+	return symbolPattern.MatchString(name)
+}
+
+// GetCurrentPrice returns the last known price of a symbol.
+func (i *Ingestor) GetCurrentPrice(name string) (string, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	symbol := i.findSymbol(name)
+	if symbol == nil {
+		return "", fmt.Errorf("symbol not found: %s", name)
+	}
+
+	if symbol.LastPrice == "" {
+		return "", fmt.Errorf("no price data yet for: %s", name)
+	}
+
+	return symbol.LastPrice, nil
+}
+
+// Name returns the source name of the ingestor.
+func (i *Ingestor) Name() string {
+	return i.name
+}
+
+// StartedAt returns when the ingestor was started, or the zero time if it
+// has not been started.
+func (i *Ingestor) StartedAt() time.Time {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.startedAt
+}
+
+// GetSymbols returns a copy of all tracked symbols.
+func (i *Ingestor) GetSymbols() []string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	symbols := make([]string, len(i.symbols))
+	for idx, symbol := range i.symbols {
+		symbols[idx] = symbol.Name
+	}
+	return symbols
+}
+
+// findSymbol returns the symbol with the given name, or nil if not found.
+// The caller must hold i.mu.
+func (i *Ingestor) findSymbol(name string) *Symbol {
+	for _, symbol := range i.symbols {
+		if symbol.Name == name {
+			return symbol
+		}
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package ws
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -11,13 +13,13 @@ import (
 // TestNewIngestor verifies Ingestor initialization with default symbols.
 func TestNewIngestor(t *testing.T) {
 	hub := NewHub()
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub))
 
 	if ingestor == nil {
 		t.Fatal("NewIngestor returned nil")
 	}
 
-	if ingestor.hub != hub {
+	if audience, ok := ingestor.audience.(hubSink); !ok || audience.Hub != hub {
 		t.Error("Ingestor hub not set correctly")
 	}
 
@@ -46,7 +48,7 @@ func TestIngestorWithOptions(t *testing.T) {
 	hub := NewHub()
 	customInterval := 2 * time.Second
 
-	ingestor := NewIngestor(hub,
+	ingestor := NewIngestor(HubSink(hub),
 		WithThrottleInterval(customInterval),
 	)
 
@@ -58,10 +60,10 @@ func TestIngestorWithOptions(t *testing.T) {
 // TestAddSymbol verifies adding new symbols to the ingestor.
 func TestAddSymbol(t *testing.T) {
 	hub := NewHub()
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub), WithSymbolLister(listing("DOGEUSDT")))
 
 	initialCount := len(ingestor.symbols)
-	ingestor.AddSymbol("DOGEUSDT")
+	ingestor.AddSymbol(context.Background(), "DOGEUSDT")
 
 	if len(ingestor.symbols) != initialCount+1 {
 		t.Errorf("Expected %d symbols, got %d", initialCount+1, len(ingestor.symbols))
@@ -84,14 +86,14 @@ func TestAddSymbol(t *testing.T) {
 // TestRemoveSymbol verifies removing symbols from the ingestor.
 func TestRemoveSymbol(t *testing.T) {
 	hub := NewHub()
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub), WithSymbolLister(listing("DOGEUSDT")))
 
 	// Add a test symbol
-	ingestor.AddSymbol("TESTUSDT")
+	ingestor.AddSymbol(context.Background(), "DOGEUSDT")
 	initialCount := len(ingestor.symbols)
 
 	// Remove the symbol
-	removed := ingestor.RemoveSymbol("TESTUSDT")
+	removed := ingestor.RemoveSymbol("DOGEUSDT")
 	if !removed {
 		t.Error("RemoveSymbol returned false for existing symbol")
 	}
@@ -110,7 +112,7 @@ func TestRemoveSymbol(t *testing.T) {
 // TestGetCurrentPrice verifies price retrieval.
 func TestGetCurrentPrice(t *testing.T) {
 	hub := NewHub()
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub))
 
 	// Test existing symbol (but no data yet since we haven't connected)
 	_, err := ingestor.GetCurrentPrice("BTCUSDT")
@@ -128,7 +130,7 @@ func TestGetCurrentPrice(t *testing.T) {
 // TestGetCurrentPriceWithData verifies price retrieval after data is set.
 func TestGetCurrentPriceWithData(t *testing.T) {
 	hub := NewHub()
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub))
 
 	// Simulate price update
 	event := &binance.WsMarketStatEvent{
@@ -153,7 +155,7 @@ func TestGetCurrentPriceWithData(t *testing.T) {
 // TestGetSymbols verifies symbol list retrieval.
 func TestGetSymbols(t *testing.T) {
 	hub := NewHub()
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub))
 
 	symbols := ingestor.GetSymbols()
 
@@ -211,7 +213,7 @@ func TestMultiUpdate(t *testing.T) {
 // TestStopIngestor verifies graceful shutdown.
 func TestStopIngestor(t *testing.T) {
 	hub := NewHub()
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub))
 
 	// Stop should not panic
 	ingestor.Stop()
@@ -228,7 +230,7 @@ func TestStopIngestor(t *testing.T) {
 // TestConvertEventToPriceUpdate verifies event conversion to PriceUpdate.
 func TestConvertEventToPriceUpdate(t *testing.T) {
 	hub := NewHub()
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub))
 
 	event := &binance.WsMarketStatEvent{
 		Symbol:             "BTCUSDT",
@@ -238,7 +240,10 @@ func TestConvertEventToPriceUpdate(t *testing.T) {
 		BaseVolume:         "1000.75",
 	}
 
-	priceUpdate := ingestor.convertEventToPriceUpdate(event)
+	priceUpdate, err := ingestor.convertEventToPriceUpdate(event)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
 
 	if priceUpdate.Symbol != "BTCUSDT" {
 		t.Errorf("Expected symbol BTCUSDT, got %s", priceUpdate.Symbol)
@@ -268,7 +273,7 @@ func TestConvertEventToPriceUpdate(t *testing.T) {
 // TestConvertEventWithInvalidData verifies handling of invalid string data.
 func TestConvertEventWithInvalidData(t *testing.T) {
 	hub := NewHub()
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub))
 
 	event := &binance.WsMarketStatEvent{
 		Symbol:             "BTCUSDT",
@@ -278,30 +283,123 @@ func TestConvertEventWithInvalidData(t *testing.T) {
 		BaseVolume:         "invalid",
 	}
 
-	priceUpdate := ingestor.convertEventToPriceUpdate(event)
+	priceUpdate, err := ingestor.convertEventToPriceUpdate(event)
+
+	// Invalid events are rejected instead of producing zero prices
+	if err == nil {
+		t.Error("Expected error for invalid input, got nil")
+	}
+
+	if priceUpdate != nil {
+		t.Errorf("Expected nil price update for invalid input, got %+v", priceUpdate)
+	}
+}
+
+// TestConvertEventWithInvalidFields verifies each invalid field is rejected.
+func TestConvertEventWithInvalidFields(t *testing.T) {
+	ingestor := NewIngestor(HubSink(NewHub()))
+
+	valid := binance.WsMarketStatEvent{
+		Symbol:             "BTCUSDT",
+		LastPrice:          "50000.50",
+		PriceChange:        "100.25",
+		PriceChangePercent: "0.20",
+		BaseVolume:         "1000.75",
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*binance.WsMarketStatEvent)
+	}{
+		{"zero price", func(e *binance.WsMarketStatEvent) { e.LastPrice = "0" }},
+		{"negative price", func(e *binance.WsMarketStatEvent) { e.LastPrice = "-1" }},
+		{"invalid change", func(e *binance.WsMarketStatEvent) { e.PriceChange = "" }},
+		{"invalid change percent", func(e *binance.WsMarketStatEvent) { e.PriceChangePercent = "abc" }},
+		{"invalid volume", func(e *binance.WsMarketStatEvent) { e.BaseVolume = "n/a" }},
+	}
+
+	for _, tt := range tests {
+		event := valid
+		tt.modify(&event)
 
-	// Should default to zero values when parsing fails
-	if priceUpdate.Price != 0 {
-		t.Errorf("Expected price 0 for invalid input, got %f", priceUpdate.Price)
+		if _, err := ingestor.convertEventToPriceUpdate(&event); err == nil {
+			t.Errorf("%s: expected error, got nil", tt.name)
+		}
 	}
+}
+
+// TestInvalidEventIsSkipped verifies invalid events are not queued or cached.
+func TestInvalidEventIsSkipped(t *testing.T) {
+	ingestor := NewIngestor(HubSink(NewHub()))
 
-	if priceUpdate.Change != 0 {
-		t.Errorf("Expected change 0 for invalid input, got %f", priceUpdate.Change)
+	var pendingUpdate *MultiUpdate
+	handler := ingestor.createWebSocketHandler(&pendingUpdate)
+
+	handler(&binance.WsMarketStatEvent{
+		Symbol:             "BTCUSDT",
+		LastPrice:          "invalid",
+		PriceChange:        "100.00",
+		PriceChangePercent: "0.20",
+		BaseVolume:         "1000",
+	})
+
+	if pendingUpdate != nil {
+		t.Error("Invalid event should not be queued")
+	}
+
+	if _, err := ingestor.GetCurrentPrice("BTCUSDT"); err == nil {
+		t.Error("Invalid event should not update cached price")
+	}
+}
+
+// TestDataQualityWarning verifies a warning is broadcast above the failure threshold.
+func TestDataQualityWarning(t *testing.T) {
+	hub := NewHub()
+	ingestor := NewIngestor(HubSink(hub), WithDataQualityThreshold(0.1))
+
+	// 20% failures within one window
+	for n := 0; n < DataQualityWindow; n++ {
+		var err error
+		if n%5 == 0 {
+			err = fmt.Errorf("invalid")
+		}
+		ingestor.recordEvent("BTCUSDT", err)
+	}
+
+	select {
+	case queued := <-hub.broadcast:
+		msg := queued.data
+		var warning DataQualityWarning
+		if err := json.Unmarshal(msg, &warning); err != nil {
+			t.Fatalf("Failed to decode warning: %v", err)
+		}
+		if warning.Type != "data_quality" || warning.Symbol != "BTCUSDT" {
+			t.Errorf("Unexpected warning: %+v", warning)
+		}
+		if warning.Failures != DataQualityWindow/5 || warning.Events != DataQualityWindow {
+			t.Errorf("Unexpected window counts: %+v", warning)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Timeout waiting for data_quality warning")
 	}
 
-	if priceUpdate.ChangePercent != 0 {
-		t.Errorf("Expected change percent 0 for invalid input, got %f", priceUpdate.ChangePercent)
+	// A clean window should not warn
+	for n := 0; n < DataQualityWindow; n++ {
+		ingestor.recordEvent("BTCUSDT", nil)
 	}
 
-	if priceUpdate.Volume != 0 {
-		t.Errorf("Expected volume 0 for invalid input, got %d", priceUpdate.Volume)
+	select {
+	case queued := <-hub.broadcast:
+		msg := queued.data
+		t.Errorf("Expected no warning for clean window, got %s", msg)
+	default:
 	}
 }
 
 // TestUpdateSymbolData verifies symbol data is updated correctly.
 func TestUpdateSymbolData(t *testing.T) {
 	hub := NewHub()
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub))
 
 	event := &binance.WsMarketStatEvent{
 		Symbol:             "BTCUSDT",
@@ -339,7 +437,7 @@ func TestUpdateSymbolData(t *testing.T) {
 // TestUpdateSymbolDataForNonExistentSymbol verifies behavior when symbol doesn't exist.
 func TestUpdateSymbolDataForNonExistentSymbol(t *testing.T) {
 	hub := NewHub()
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub))
 
 	event := &binance.WsMarketStatEvent{
 		Symbol:             "NONEXISTENT",
@@ -361,7 +459,7 @@ func TestUpdateSymbolDataForNonExistentSymbol(t *testing.T) {
 // TestFindSymbol verifies symbol lookup functionality.
 func TestFindSymbol(t *testing.T) {
 	hub := NewHub()
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub))
 
 	// Test finding existing symbol
 	symbol := ingestor.findSymbol("BTCUSDT")
@@ -380,10 +478,42 @@ func TestFindSymbol(t *testing.T) {
 	}
 }
 
+// TestSymbolChangeReestablishesStream verifies adding a symbol closes the
+// current Binance connection so the stream loop reconnects.
+func TestSymbolChangeReestablishesStream(t *testing.T) {
+	// Arrange
+	ingestor := NewIngestor(HubSink(NewHub()), WithSymbolLister(listing("DOGEUSDT")))
+	stopC, doneC := make(chan struct{}), make(chan struct{})
+	ingestor.stopC = stopC
+	go func() {
+		<-stopC
+		close(doneC)
+	}()
+
+	// Act
+	added, _ := ingestor.AddSymbol(context.Background(), "DOGEUSDT")
+	duplicate, _ := ingestor.AddSymbol(context.Background(), "dogeusdt")
+	reconnect := ingestor.waitForShutdown(doneC)
+
+	// Assert
+	if !added || duplicate {
+		t.Errorf("Expected only the first add to succeed, got %v and %v", added, duplicate)
+	}
+	if !reconnect {
+		t.Error("Expected the stream to be re-established")
+	}
+	if ingestor.stopC != nil {
+		t.Error("Expected the old connection to be closed")
+	}
+	if len(ingestor.resubscribe) != 0 {
+		t.Error("Expected repeated changes to be merged into one reconnect")
+	}
+}
+
 // TestStartMultiSymbolWithEmptySymbols verifies behavior with no symbols.
 func TestStartMultiSymbolWithEmptySymbols(t *testing.T) {
 	hub := NewHub()
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub))
 
 	// Remove all symbols
 	for len(ingestor.symbols) > 0 {
@@ -399,7 +529,7 @@ func TestStartMultiSymbolWithEmptySymbols(t *testing.T) {
 // TestQueuePriceUpdate verifies price update queuing logic.
 func TestQueuePriceUpdate(t *testing.T) {
 	hub := NewHub()
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub))
 
 	var pendingUpdate *MultiUpdate
 
@@ -458,7 +588,7 @@ func TestQueuePriceUpdate(t *testing.T) {
 // TestUpdateOrAppendPrice verifies the update/append logic for price updates.
 func TestUpdateOrAppendPrice(t *testing.T) {
 	hub := NewHub()
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub))
 
 	multiUpdate := &MultiUpdate{
 		Type: "multi_update",
@@ -491,7 +621,7 @@ func TestUpdateOrAppendPrice(t *testing.T) {
 // TestConcurrentSymbolAccess verifies thread-safe symbol operations.
 func TestConcurrentSymbolAccess(t *testing.T) {
 	hub := NewHub()
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub))
 
 	done := make(chan bool)
 
@@ -515,7 +645,7 @@ func TestConcurrentSymbolAccess(t *testing.T) {
 // TestMultipleStopCalls verifies Stop can be called multiple times safely.
 func TestMultipleStopCalls(t *testing.T) {
 	hub := NewHub()
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub))
 
 	// Multiple Stop calls should not panic
 	ingestor.Stop()
@@ -534,7 +664,7 @@ func TestMultipleStopCalls(t *testing.T) {
 // TestCreateWebSocketHandler verifies WebSocket handler creation.
 func TestCreateWebSocketHandler(t *testing.T) {
 	hub := NewHub()
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub))
 
 	var pendingUpdate *MultiUpdate
 	handler := ingestor.createWebSocketHandler(&pendingUpdate)
@@ -565,7 +695,7 @@ func TestCreateWebSocketHandler(t *testing.T) {
 // TestCreateErrorHandler verifies error handler creation.
 func TestCreateErrorHandler(t *testing.T) {
 	hub := NewHub()
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub))
 
 	handler := ingestor.createErrorHandler()
 
@@ -580,16 +710,17 @@ func TestCreateErrorHandler(t *testing.T) {
 // TestSendToHub verifies sending data to hub with proper logging.
 func TestSendToHub(t *testing.T) {
 	hub := NewHub()
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub))
 
 	testData := []byte("test data")
-	
+
 	// Send without running hub (so we can verify it's in the channel)
 	ingestor.sendToHub(testData, 5)
 
 	// Verify data is in the hub's broadcast channel
 	select {
-	case msg := <-hub.broadcast:
+	case queued := <-hub.broadcast:
+		msg := queued.data
 		if string(msg) != string(testData) {
 			t.Errorf("Expected %s, got %s", testData, msg)
 		}
@@ -601,12 +732,12 @@ func TestSendToHub(t *testing.T) {
 // TestSendToHubWithFullChannel verifies overflow protection.
 func TestSendToHubWithFullChannel(t *testing.T) {
 	hub := NewHub()
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub))
 
 	// Don't run hub.Run() so broadcast channel fills up
 	// Fill the channel to capacity
 	for i := 0; i < BroadcastBufferSize; i++ {
-		hub.broadcast <- []byte("filler")
+		hub.Publish([]byte("filler"))
 	}
 
 	// This should not block or panic
@@ -622,7 +753,7 @@ func TestBroadcastPendingUpdatesWithNilUpdate(t *testing.T) {
 	go hub.Run()
 	time.Sleep(10 * time.Millisecond)
 
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub))
 
 	var pendingUpdate *MultiUpdate
 	// Should not panic with nil
@@ -639,7 +770,7 @@ func TestBroadcastPendingUpdatesWithEmptyData(t *testing.T) {
 	go hub.Run()
 	time.Sleep(10 * time.Millisecond)
 
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub))
 
 	pendingUpdate := &MultiUpdate{
 		Type: "multi_update",
@@ -660,7 +791,7 @@ func TestBroadcastPendingUpdatesWithEmptyData(t *testing.T) {
 // TestBroadcastPendingUpdatesWithValidData verifies successful broadcast.
 func TestBroadcastPendingUpdatesWithValidData(t *testing.T) {
 	hub := NewHub()
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub))
 
 	pendingUpdate := &MultiUpdate{
 		Type: "multi_update",
@@ -673,7 +804,8 @@ func TestBroadcastPendingUpdatesWithValidData(t *testing.T) {
 
 	// Verify data was sent to hub's broadcast channel
 	select {
-	case msg := <-hub.broadcast:
+	case queued := <-hub.broadcast:
+		msg := queued.data
 		if len(msg) == 0 {
 			t.Error("Received empty message")
 		}
@@ -691,18 +823,18 @@ func TestBroadcastPendingUpdatesWithValidData(t *testing.T) {
 // TestRemoveSymbolOrderIndependence verifies symbol removal maintains correctness.
 func TestRemoveSymbolOrderIndependence(t *testing.T) {
 	hub := NewHub()
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub), WithSymbolLister(listing("DOGEUSDT", "LTCUSDT", "DOTUSDT")))
 
-	ingestor.AddSymbol("TEST1")
-	ingestor.AddSymbol("TEST2")
-	ingestor.AddSymbol("TEST3")
+	for _, symbol := range []string{"DOGEUSDT", "LTCUSDT", "DOTUSDT"} {
+		ingestor.AddSymbol(context.Background(), symbol)
+	}
 
 	initialCount := len(ingestor.symbols)
 
 	// Remove middle symbol
-	removed := ingestor.RemoveSymbol("TEST2")
+	removed := ingestor.RemoveSymbol("LTCUSDT")
 	if !removed {
-		t.Error("Failed to remove TEST2")
+		t.Error("Failed to remove LTCUSDT")
 	}
 
 	if len(ingestor.symbols) != initialCount-1 {
@@ -710,23 +842,23 @@ func TestRemoveSymbolOrderIndependence(t *testing.T) {
 	}
 
 	// Verify other symbols still exist
-	if ingestor.findSymbol("TEST1") == nil {
-		t.Error("TEST1 should still exist")
+	if ingestor.findSymbol("DOGEUSDT") == nil {
+		t.Error("DOGEUSDT should still exist")
 	}
 
-	if ingestor.findSymbol("TEST3") == nil {
-		t.Error("TEST3 should still exist")
+	if ingestor.findSymbol("DOTUSDT") == nil {
+		t.Error("DOTUSDT should still exist")
 	}
 
-	if ingestor.findSymbol("TEST2") != nil {
-		t.Error("TEST2 should be removed")
+	if ingestor.findSymbol("LTCUSDT") != nil {
+		t.Error("LTCUSDT should be removed")
 	}
 }
 
 // TestDefaultThrottleInterval verifies default throttle setting.
 func TestDefaultThrottleInterval(t *testing.T) {
 	hub := NewHub()
-	ingestor := NewIngestor(hub)
+	ingestor := NewIngestor(HubSink(hub))
 
 	if ingestor.throttleInterval != DefaultThrottleInterval {
 		t.Errorf("Expected default interval %v, got %v", DefaultThrottleInterval, ingestor.throttleInterval)
@@ -752,4 +884,55 @@ func TestSymbolStructInitialization(t *testing.T) {
 	}
 }
 
+// TestSymbolStats verifies per-symbol event, broadcast and parse failure counters.
+func TestSymbolStats(t *testing.T) {
+	hub := NewHub()
+	ingestor := NewIngestor(HubSink(hub), WithSymbols([]string{"BTCUSDT", "ETHUSDT"}))
 
+	var pendingUpdate *MultiUpdate
+	handler := ingestor.createWebSocketHandler(&pendingUpdate)
+
+	handler(&binance.WsMarketStatEvent{
+		Symbol:             "BTCUSDT",
+		LastPrice:          "50000.00",
+		PriceChange:        "100.00",
+		PriceChangePercent: "0.20",
+		BaseVolume:         "1000",
+	})
+	handler(&binance.WsMarketStatEvent{
+		Symbol:             "BTCUSDT",
+		LastPrice:          "invalid",
+		PriceChange:        "100.00",
+		PriceChangePercent: "0.20",
+		BaseVolume:         "1000",
+	})
+
+	ingestor.broadcastPendingUpdates(&pendingUpdate)
+
+	stats := ingestor.Stats()
+	if len(stats) != 2 {
+		t.Fatalf("Expected stats for 2 symbols, got %d", len(stats))
+	}
+
+	btc := stats[0]
+	if btc.Symbol != "BTCUSDT" {
+		t.Fatalf("Expected first symbol BTCUSDT, got %s", btc.Symbol)
+	}
+	if btc.EventsReceived != 2 {
+		t.Errorf("Expected 2 events received, got %d", btc.EventsReceived)
+	}
+	if btc.UpdatesBroadcast != 1 {
+		t.Errorf("Expected 1 update broadcast, got %d", btc.UpdatesBroadcast)
+	}
+	if btc.ParseFailures != 1 {
+		t.Errorf("Expected 1 parse failure, got %d", btc.ParseFailures)
+	}
+	if btc.LastEventAt == nil {
+		t.Error("Expected LastEventAt to be set")
+	}
+
+	eth := stats[1]
+	if eth.EventsReceived != 0 || eth.LastEventAt != nil {
+		t.Errorf("Expected no activity for ETHUSDT, got %+v", eth)
+	}
+}
@@ -0,0 +1,74 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// NewKrakenSource creates an ExchangeSource of the Kraken v2 ticker
+// channel. Symbols stream the pair of the same quote asset, so USDT prices
+// are never blended with USD ones.
+func NewKrakenSource() ExchangeSource {
+	return newStreamSource(ExchangeKraken, KrakenStreamURL, krakenProtocol{})
+}
+
+// krakenProtocol is the Kraken v2 ticker channel protocol.
+type krakenProtocol struct{}
+
+// krakenMessage is a ticker channel message of Kraken.
+type krakenMessage struct {
+	Channel string `json:"channel"`
+	Data    []struct {
+		Symbol        string  `json:"symbol"`
+		Last          float64 `json:"last"`
+		Volume        float64 `json:"volume"`
+		ChangePercent float64 `json:"change_pct"`
+		Timestamp     string  `json:"timestamp"`
+	} `json:"data"`
+}
+
+// product returns the Kraken pair of a symbol, e.g. BTC/USDT for BTCUSDT.
+func (krakenProtocol) product(symbol string) (string, bool) {
+	base, quote, ok := splitSymbol(symbol)
+	if !ok {
+		return "", false
+	}
+	return base + "/" + quote, true
+}
+
+func (krakenProtocol) subscribe(products []string) any {
+	return map[string]any{"method": "subscribe", "params": map[string]any{"channel": "ticker", "symbol": products}}
+}
+
+func (krakenProtocol) unsubscribe(products []string) any {
+	return map[string]any{"method": "unsubscribe", "params": map[string]any{"channel": "ticker", "symbol": products}}
+}
+
+// parse returns the tickers of a ticker channel message. Kraken sends JSON
+// numbers, and a timestamp only in newer versions of the channel.
+func (krakenProtocol) parse(message []byte) ([]exchangeTicker, error) {
+	var decoded krakenMessage
+	if err := json.Unmarshal(message, &decoded); err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+	if decoded.Channel != "ticker" {
+		return nil, nil
+	}
+
+	tickers := make([]exchangeTicker, 0, len(decoded.Data))
+	for _, ticker := range decoded.Data {
+		var eventTime int64
+		if timestamp, err := time.Parse(time.RFC3339Nano, ticker.Timestamp); err == nil {
+			eventTime = timestamp.UnixMilli()
+		}
+		tickers = append(tickers, exchangeTicker{
+			product:       ticker.Symbol,
+			price:         ticker.Last,
+			changePercent: ticker.ChangePercent,
+			volume:        ticker.Volume,
+			eventTime:     eventTime,
+		})
+	}
+	return tickers, nil
+}
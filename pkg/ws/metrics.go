@@ -0,0 +1,112 @@
+package ws
+
+import "macro-analyst/pkg/metrics"
+
+// Metrics are the counters, gauges and histograms of Hubs and Ingestors.
+// Hubs and Ingestors given none record to a registry of their own that is
+// never rendered, so importing the package registers nothing.
+type Metrics struct {
+	// Hub
+	droppedMessages     map[DropReason]*metrics.Counter
+	broadcastFanout     *metrics.Histogram
+	reapedConnections   *metrics.Counter
+	backpressureSignals *metrics.Counter
+	backpressureActive  *metrics.Gauge
+
+	// Ingestor
+	duplicateEvents      *metrics.Counter
+	payloadSplits        *metrics.Counter
+	parseFailures        *metrics.Counter
+	dataQualityWarnings  *metrics.Counter
+	binanceReconnects    *metrics.Counter
+	insignificantUpdates *metrics.Counter
+	feedLatency          *metrics.Gauge
+	staleFeedAlarms      *metrics.Counter
+	feedStale            *metrics.Gauge
+}
+
+// NewMetrics registers the metrics of Hubs and Ingestors on a registry,
+// e.g. metrics.Default. Metrics of the same registry are shared, so drops
+// are counted across all Hubs given them.
+func NewMetrics(registry *metrics.Registry) *Metrics {
+	droppedMessages := make(map[DropReason]*metrics.Counter, len(DropReasons))
+	for _, reason := range DropReasons {
+		droppedMessages[reason] = registry.NewLabeledCounter(
+			"hub_messages_dropped_total",
+			"Number of messages that did not reach clients, by reason.",
+			"reason", string(reason),
+		)
+	}
+
+	return &Metrics{
+		droppedMessages: droppedMessages,
+		broadcastFanout: registry.NewHistogram(
+			"hub_broadcast_fanout_seconds",
+			"Time from receiving a broadcast message to queuing it for every client.",
+			[]float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25},
+		),
+		reapedConnections: registry.NewCounter(
+			"hub_reaped_connections_total",
+			"Number of client connections closed for sending no frame within the idle timeout.",
+		),
+		backpressureSignals: registry.NewCounter(
+			"hub_backpressure_signals_total",
+			"Number of times the Hub signaled backpressure to ingestors.",
+		),
+		backpressureActive: registry.NewGauge(
+			"hub_backpressure",
+			"1 if the Hub is currently signaling backpressure, 0 otherwise.",
+		),
+
+		duplicateEvents: registry.NewCounter(
+			"duplicate_events_suppressed_total",
+			"Number of Binance events dropped because they repeated the last known event.",
+		),
+		payloadSplits: registry.NewCounter(
+			"payload_splits_total",
+			"Number of MultiUpdate broadcasts split into several frames to stay under the maximum payload size.",
+		),
+		parseFailures: registry.NewCounter(
+			"price_parse_failures_total",
+			"Number of Binance events skipped because a numeric field failed to parse.",
+		),
+		dataQualityWarnings: registry.NewCounter(
+			"data_quality_warnings_total",
+			"Number of data_quality warnings broadcast for symbols with high parse failure rates.",
+		),
+		binanceReconnects: registry.NewCounter(
+			"binance_reconnects_total",
+			"Number of reconnect attempts scheduled after a failed connect or a dropped Binance stream.",
+		),
+		insignificantUpdates: registry.NewCounter(
+			"insignificant_updates_suppressed_total",
+			"Number of price updates held back because the price moved less than the significance threshold.",
+		),
+		feedLatency: registry.NewGauge(
+			"feed_latency_seconds",
+			"Delay between the Binance event time and server receive time of the last event.",
+		),
+		staleFeedAlarms: registry.NewCounter(
+			"feed_stale_alarms_total",
+			"Number of times the Binance feed was declared stale while clients were connected.",
+		),
+		feedStale: registry.NewGauge(
+			"feed_stale",
+			"1 if the Binance feed is currently considered stale, 0 otherwise.",
+		),
+	}
+}
+
+// WithMetrics makes the ingestor record its metrics to m.
+func WithMetrics(m *Metrics) IngestorOption {
+	return func(i *Ingestor) {
+		i.metrics = m
+	}
+}
+
+// WithHubMetrics makes the Hub record its metrics to m.
+func WithHubMetrics(m *Metrics) HubOption {
+	return func(h *Hub) {
+		h.metrics = m
+	}
+}
@@ -0,0 +1,160 @@
+package ws
+
+import (
+	"log"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/url"
+	"time"
+
+	"macro-analyst/pkg/feedlog"
+
+	"github.com/adshao/go-binance/v2"
+)
+
+const (
+	// mockVolatility is the standard deviation of the relative price move
+	// of a symbol per mock update
+	mockVolatility = 0.001
+
+	// mockDefaultPrice is the starting price of symbols without one in
+	// mockStartPrices
+	mockDefaultPrice = 100.0
+)
+
+// mockStartPrices are plausible starting prices of the default symbols.
+var mockStartPrices = map[string]float64{
+	"BTCUSDT": 60000,
+	"ETHUSDT": 3000,
+	"BNBUSDT": 600,
+	"SOLUSDT": 150,
+	"ADAUSDT": 0.45,
+	"XRPUSDT": 0.55,
+}
+
+// mockSource is the source of the mock prices of an ingestor.
+type mockSource struct {
+	seed uint64
+}
+
+// WithMockData makes the ingestor generate random-walk prices for its
+// symbols at the throttle interval instead of connecting to Binance. The
+// same seed and clock produce the same prices, so tests are deterministic.
+// A recording set by WithReplay takes precedence.
+func WithMockData(seed uint64) IngestorOption {
+	return func(i *Ingestor) {
+		if !i.ReplayMode() {
+			i.playback = &mockSource{seed: seed}
+		}
+	}
+}
+
+// NewMockIngestor creates an Ingestor generating random-walk prices for
+// offline development; WithMockData among opts sets the seed.
+func NewMockIngestor(sink Sink, opts ...IngestorOption) *Ingestor {
+	return NewIngestor(sink, append([]IngestorOption{WithMockData(rand.Uint64())}, opts...)...)
+}
+
+// MockMode reports whether the ingestor generates mock prices.
+func (i *Ingestor) MockMode() bool {
+	_, ok := i.playback.(*mockSource)
+	return ok
+}
+
+// BinanceReachable reports whether the Binance WebSocket endpoint accepts
+// connections within timeout, e.g. to fall back to mock data offline.
+func BinanceReachable(timeout time.Duration) bool {
+	endpoint, err := url.Parse(binance.BaseWsMainURL)
+	if err != nil {
+		return false
+	}
+	conn, err := net.DialTimeout("tcp", endpoint.Host, timeout)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// mockSymbol is the random walk of a symbol.
+type mockSymbol struct {
+	open   float64
+	price  float64
+	volume float64
+}
+
+// play broadcasts mock prices of the tracked symbols at the throttle
+// interval until the ingestor stops.
+func (m *mockSource) play(i *Ingestor) {
+	log.Printf("Generating mock prices for %v", i.GetSymbols())
+	i.recordConnected(feedlog.Connected, len(i.GetSymbols()))
+
+	rng := rand.New(rand.NewPCG(m.seed, 0))
+	walks := make(map[string]*mockSymbol)
+
+	ticker := i.clock.NewTicker(i.throttleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-i.ctx.Done():
+			return
+		case now := <-ticker.C():
+			i.publishMockPrices(rng, walks, now)
+		}
+	}
+}
+
+// publishMockPrices moves each tracked symbol one step along its random
+// walk and broadcasts the prices like a live update.
+func (i *Ingestor) publishMockPrices(rng *rand.Rand, walks map[string]*mockSymbol, now time.Time) {
+	i.markEventReceived(now)
+
+	symbols := i.GetSymbols()
+	update := &MultiUpdate{Type: "multi_update", Data: make([]*PriceUpdate, 0, len(symbols))}
+	for _, name := range symbols {
+		walk := walks[name]
+		if walk == nil {
+			start, ok := mockStartPrices[name]
+			if !ok {
+				start = mockDefaultPrice
+			}
+			walk = &mockSymbol{open: start, price: start}
+			walks[name] = walk
+		}
+		walk.price *= math.Exp(mockVolatility * rng.NormFloat64())
+		walk.volume += rng.Float64() * 1000
+
+		price := roundMockPrice(walk.price)
+		update.Data = append(update.Data, &PriceUpdate{
+			Symbol:        name,
+			Price:         price,
+			Change:        roundMockPrice(price - walk.open),
+			ChangePercent: math.Round((price/walk.open-1)*1e4) / 100,
+			Volume:        int64(walk.volume),
+			Timestamp:     i.formatTimestamp(now.UnixMilli(), now),
+			EventTime:     now.UnixMilli(),
+			ReceivedAt:    now.UnixMilli(),
+		})
+	}
+
+	i.cachePriceUpdates(update.Data, now)
+	for _, price := range update.Data {
+		i.recordRecent(price.Symbol, price.Price, now)
+		i.evaluateAlerts(price.Symbol, price.Price, now)
+	}
+	i.broadcastPendingUpdates(&update)
+}
+
+// roundMockPrice rounds a mock price to the decimals a market of its
+// magnitude quotes.
+func roundMockPrice(price float64) float64 {
+	scale := 1e6
+	switch abs := math.Abs(price); {
+	case abs >= 100:
+		scale = 1e2
+	case abs >= 1:
+		scale = 1e4
+	}
+	return math.Round(price*scale) / scale
+}
@@ -0,0 +1,82 @@
+package ws
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"macro-analyst/pkg/clock"
+)
+
+// mockFrames runs a mock ingestor with a seed for a number of updates and
+// returns the frames it broadcast.
+func mockFrames(t *testing.T, seed uint64, updates int) ([]string, *Ingestor) {
+	t.Helper()
+
+	clk := clock.NewFake(time.Date(2024, 2, 20, 12, 0, 0, 0, time.UTC))
+	hub := NewHub()
+	ingestor := NewMockIngestor(HubSink(hub),
+		WithMockData(seed),
+		WithClock(clk),
+		WithThrottleInterval(time.Second),
+		WithSymbols([]string{"BTCUSDT", "DOGEUSDT"}),
+	)
+	go ingestor.playback.play(ingestor)
+	defer ingestor.Stop()
+
+	var frames []string
+	for range updates {
+		clk.BlockUntil(1)
+		clk.Advance(time.Second)
+		select {
+		case queued := <-hub.broadcast:
+			frames = append(frames, string(queued.data))
+		case <-time.After(time.Second):
+			t.Fatal("Expected a mock update")
+		}
+	}
+	return frames, ingestor
+}
+
+// TestMockIngestor verifies mock prices are plausible random walks from the
+// symbols' starting prices, and the same seed yields the same updates.
+func TestMockIngestor(t *testing.T) {
+	frames, ingestor := mockFrames(t, 42, 3)
+	if !ingestor.MockMode() {
+		t.Fatal("Expected mock mode")
+	}
+
+	again, _ := mockFrames(t, 42, 3)
+	for idx := range frames {
+		if frames[idx] != again[idx] {
+			t.Errorf("Expected the same updates for the same seed, got %s and %s", frames[idx], again[idx])
+		}
+	}
+	if other, _ := mockFrames(t, 7, 1); other[0] == frames[0] {
+		t.Error("Expected other updates for another seed")
+	}
+
+	prices := decodePrices(t, []byte(frames[2]))
+	for symbol, start := range map[string]float64{"BTCUSDT": 60000, "DOGEUSDT": mockDefaultPrice} {
+		if math.Abs(prices[symbol]/start-1) > 0.02 {
+			t.Errorf("Expected %s near %v, got %v", symbol, start, prices[symbol])
+		}
+	}
+	if cached := ingestor.Prices(); len(cached) != 2 || cached[0].Price != prices["BTCUSDT"] {
+		t.Errorf("Expected the mock prices cached, got %+v", cached)
+	}
+}
+
+// TestRoundMockPrice tests the decimals of mock prices.
+func TestRoundMockPrice(t *testing.T) {
+	tests := map[float64]float64{
+		60123.4567:  60123.46,
+		3.14159265:  3.1416,
+		0.123456789: 0.123457,
+	}
+	for price, expected := range tests {
+		if rounded := roundMockPrice(price); rounded != expected {
+			t.Errorf("roundMockPrice(%v) = %v, expected %v", price, rounded, expected)
+		}
+	}
+}
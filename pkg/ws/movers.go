@@ -0,0 +1,178 @@
+package ws
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"macro-analyst/pkg/clock"
+)
+
+const (
+	// DefaultMoversInterval is how often the 24h statistics of all Binance
+	// pairs are polled for the top movers
+	DefaultMoversInterval = time.Minute
+
+	// DefaultMoversLimit and MaxMoversLimit bound how many pairs each top
+	// movers list holds
+	DefaultMoversLimit = 10
+	MaxMoversLimit     = 100
+
+	// DefaultMinQuoteVolume is the 24h quote volume a pair needs to count
+	// as a gainer or loser, so illiquid pairs do not crowd the lists
+	DefaultMinQuoteVolume = 1_000_000
+)
+
+// ErrMoversUnavailable is returned until the first poll succeeded.
+var ErrMoversUnavailable = errors.New("top movers not loaded yet")
+
+// TickerStats is the 24h rolling window statistics of a pair.
+type TickerStats struct {
+	Symbol        string  `json:"symbol"`
+	LastPrice     float64 `json:"lastPrice"`
+	ChangePercent float64 `json:"changePercent"`
+	QuoteVolume   float64 `json:"quoteVolume"`
+	Trades        int64   `json:"trades"`
+}
+
+// TickerStatsLister returns the 24h statistics of every pair on the
+// exchange.
+type TickerStatsLister func(ctx context.Context) ([]TickerStats, error)
+
+// Movers are the pairs that moved most over the last 24 hours.
+type Movers struct {
+	Gainers   []TickerStats `json:"gainers"`
+	Losers    []TickerStats `json:"losers"`
+	Volume    []TickerStats `json:"volume"` // By quote volume
+	Pairs     int           `json:"pairs"`  // Pairs considered
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+// MoverTracker polls the 24h statistics of all pairs quoted in one asset
+// and ranks them. It is safe for concurrent use.
+type MoverTracker struct {
+	mu        sync.RWMutex
+	stats     []TickerStats
+	updatedAt time.Time
+
+	list  TickerStatsLister
+	quote string
+	clock clock.Clock
+}
+
+// NewMoverTracker creates a MoverTracker over the pairs quoted in
+// DefaultQuoteAsset.
+func NewMoverTracker(list TickerStatsLister) *MoverTracker {
+	return &MoverTracker{list: list, quote: DefaultQuoteAsset, clock: clock.Real}
+}
+
+// Refresh polls the statistics of all pairs. Pairs without trades in the
+// last 24 hours, such as delisted ones, are left out. A failed poll keeps
+// the previous statistics.
+func (t *MoverTracker) Refresh(ctx context.Context) error {
+	all, err := t.list(ctx)
+	if err != nil {
+		return err
+	}
+
+	stats := make([]TickerStats, 0, len(all))
+	for _, s := range all {
+		if hasQuote(s.Symbol, t.quote) && s.Trades > 0 {
+			stats = append(stats, s)
+		}
+	}
+
+	t.mu.Lock()
+	t.stats = stats
+	t.updatedAt = t.clock.Now()
+	t.mu.Unlock()
+	return nil
+}
+
+// Top returns up to limit gainers, losers and volume leaders. Gainers and
+// losers only include pairs with at least minQuoteVolume of 24h volume.
+func (t *MoverTracker) Top(limit int, minQuoteVolume float64) (Movers, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.updatedAt.IsZero() {
+		return Movers{}, ErrMoversUnavailable
+	}
+
+	liquid := make([]TickerStats, 0, len(t.stats))
+	for _, s := range t.stats {
+		if s.QuoteVolume >= minQuoteVolume {
+			liquid = append(liquid, s)
+		}
+	}
+
+	byChange := func(a, b TickerStats) int {
+		return cmp.Or(cmp.Compare(b.ChangePercent, a.ChangePercent), strings.Compare(a.Symbol, b.Symbol))
+	}
+	gainers := slices.Clone(liquid)
+	slices.SortFunc(gainers, byChange)
+	losers := slices.Clone(liquid)
+	slices.SortFunc(losers, func(a, b TickerStats) int { return byChange(b, a) })
+	volume := slices.Clone(t.stats)
+	slices.SortFunc(volume, func(a, b TickerStats) int {
+		return cmp.Or(cmp.Compare(b.QuoteVolume, a.QuoteVolume), strings.Compare(a.Symbol, b.Symbol))
+	})
+
+	return Movers{
+		Gainers:   firstN(gainers, limit, func(s TickerStats) bool { return s.ChangePercent > 0 }),
+		Losers:    firstN(losers, limit, func(s TickerStats) bool { return s.ChangePercent < 0 }),
+		Volume:    firstN(volume, limit, func(TickerStats) bool { return true }),
+		Pairs:     len(t.stats),
+		UpdatedAt: t.updatedAt,
+	}, nil
+}
+
+// firstN returns up to n leading stats that match keep, stopping at the
+// first one that does not.
+func firstN(stats []TickerStats, n int, keep func(TickerStats) bool) []TickerStats {
+	top := []TickerStats{}
+	for _, s := range stats {
+		if len(top) == n || !keep(s) {
+			break
+		}
+		top = append(top, s)
+	}
+	return top
+}
+
+// hasQuote reports whether a symbol is a base asset quoted in quote.
+func hasQuote(symbol, quote string) bool {
+	return len(symbol) > len(quote) && strings.HasSuffix(symbol, quote)
+}
+
+// ListTickerStats reads the 24h statistics of every Binance pair.
+func (i *Ingestor) ListTickerStats(ctx context.Context) ([]TickerStats, error) {
+	all, err := i.restClient().NewListPriceChangeStatsService().Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch 24h ticker statistics: %w", err)
+	}
+
+	stats := make([]TickerStats, 0, len(all))
+	for _, s := range all {
+		price, errPrice := strconv.ParseFloat(s.LastPrice, 64)
+		change, errChange := strconv.ParseFloat(s.PriceChangePercent, 64)
+		volume, errVolume := strconv.ParseFloat(s.QuoteVolume, 64)
+		if errPrice != nil || errChange != nil || errVolume != nil {
+			continue
+		}
+		stats = append(stats, TickerStats{
+			Symbol:        s.Symbol,
+			LastPrice:     price,
+			ChangePercent: change,
+			QuoteVolume:   volume,
+			Trades:        s.Count,
+		})
+	}
+	return stats, nil
+}
@@ -0,0 +1,101 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// moverSymbols returns the symbols of stats in order.
+func moverSymbols(stats []TickerStats) []string {
+	symbols := []string{}
+	for _, s := range stats {
+		symbols = append(symbols, s.Symbol)
+	}
+	return symbols
+}
+
+// TestMoverTrackerTop verifies the ranking of gainers, losers and volume
+// leaders among the USDT pairs traded in the last 24 hours.
+func TestMoverTrackerTop(t *testing.T) {
+	tracker := NewMoverTracker(func(ctx context.Context) ([]TickerStats, error) {
+		return []TickerStats{
+			{Symbol: "BTCUSDT", ChangePercent: 2, QuoteVolume: 900_000_000, Trades: 100},
+			{Symbol: "ETHUSDT", ChangePercent: -3, QuoteVolume: 400_000_000, Trades: 100},
+			{Symbol: "SOLUSDT", ChangePercent: 8, QuoteVolume: 50_000_000, Trades: 100},
+			{Symbol: "DOGEUSDT", ChangePercent: -6, QuoteVolume: 20_000_000, Trades: 100},
+			{Symbol: "TINYUSDT", ChangePercent: 90, QuoteVolume: 5_000, Trades: 3},
+			{Symbol: "GONEUSDT", ChangePercent: -99, QuoteVolume: 0, Trades: 0},
+			{Symbol: "ETHBTC", ChangePercent: 12, QuoteVolume: 1_000, Trades: 100},
+			{Symbol: "FLATUSDT", ChangePercent: 0, QuoteVolume: 10_000_000, Trades: 100},
+		}, nil
+	})
+
+	if _, err := tracker.Top(DefaultMoversLimit, DefaultMinQuoteVolume); !errors.Is(err, ErrMoversUnavailable) {
+		t.Fatalf("Expected ErrMoversUnavailable before the first refresh, got %v", err)
+	}
+	if err := tracker.Refresh(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		limit     int
+		minVolume float64
+		gainers   []string
+		losers    []string
+		volume    []string
+	}{
+		{"liquid", DefaultMoversLimit, DefaultMinQuoteVolume,
+			[]string{"SOLUSDT", "BTCUSDT"}, []string{"DOGEUSDT", "ETHUSDT"},
+			[]string{"BTCUSDT", "ETHUSDT", "SOLUSDT", "DOGEUSDT", "FLATUSDT", "TINYUSDT"}},
+		{"no minimum", DefaultMoversLimit, 0,
+			[]string{"TINYUSDT", "SOLUSDT", "BTCUSDT"}, []string{"DOGEUSDT", "ETHUSDT"},
+			[]string{"BTCUSDT", "ETHUSDT", "SOLUSDT", "DOGEUSDT", "FLATUSDT", "TINYUSDT"}},
+		{"limited", 1, DefaultMinQuoteVolume,
+			[]string{"SOLUSDT"}, []string{"DOGEUSDT"}, []string{"BTCUSDT"}},
+	}
+
+	for _, tt := range tests {
+		movers, err := tracker.Top(tt.limit, tt.minVolume)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if movers.Pairs != 6 {
+			t.Errorf("%s: expected 6 traded USDT pairs, got %d", tt.name, movers.Pairs)
+		}
+		if got := moverSymbols(movers.Gainers); !slices.Equal(got, tt.gainers) {
+			t.Errorf("%s: expected gainers %v, got %v", tt.name, tt.gainers, got)
+		}
+		if got := moverSymbols(movers.Losers); !slices.Equal(got, tt.losers) {
+			t.Errorf("%s: expected losers %v, got %v", tt.name, tt.losers, got)
+		}
+		if got := moverSymbols(movers.Volume); !slices.Equal(got, tt.volume) {
+			t.Errorf("%s: expected volume leaders %v, got %v", tt.name, tt.volume, got)
+		}
+	}
+}
+
+// TestMoverTrackerRefreshError verifies a failed poll keeps the previous
+// statistics.
+func TestMoverTrackerRefreshError(t *testing.T) {
+	fail := false
+	tracker := NewMoverTracker(func(ctx context.Context) ([]TickerStats, error) {
+		if fail {
+			return nil, errors.New("rate limited")
+		}
+		return []TickerStats{{Symbol: "BTCUSDT", ChangePercent: 2, QuoteVolume: 900_000_000, Trades: 100}}, nil
+	})
+
+	tracker.Refresh(context.Background())
+	fail = true
+	if err := tracker.Refresh(context.Background()); err == nil {
+		t.Error("Expected the poll error")
+	}
+
+	movers, err := tracker.Top(DefaultMoversLimit, DefaultMinQuoteVolume)
+	if err != nil || len(movers.Gainers) != 1 {
+		t.Errorf("Expected the previous statistics, got %+v (%v)", movers, err)
+	}
+}
@@ -0,0 +1,117 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// toMsgPack converts a JSON message to MessagePack with the same structure:
+// integers stay integers, other numbers become float64 and object keys are
+// sorted. Messages that are not valid JSON are returned unchanged.
+func toMsgPack(message []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(message))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return message
+	}
+	return appendMsgPack(make([]byte, 0, len(message)), value)
+}
+
+// isJSON reports whether a message is a JSON object or array rather than
+// MessagePack, whose maps and arrays never start with '{' or '['.
+func isJSON(message []byte) bool {
+	return len(message) > 0 && (message[0] == '{' || message[0] == '[')
+}
+
+// appendMsgPack appends the MessagePack encoding of a decoded JSON value.
+func appendMsgPack(buf []byte, value any) []byte {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, 0xc0)
+	case bool:
+		if v {
+			return append(buf, 0xc3)
+		}
+		return append(buf, 0xc2)
+	case json.Number:
+		if n, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return appendMsgPackInt(buf, n)
+		}
+		f, _ := v.Float64()
+		buf = append(buf, 0xcb)
+		return binary.BigEndian.AppendUint64(buf, math.Float64bits(f))
+	case string:
+		return appendMsgPackString(buf, v)
+	case []any:
+		buf = appendMsgPackHeader(buf, len(v), 0x90, 0xdc)
+		for _, item := range v {
+			buf = appendMsgPack(buf, item)
+		}
+		return buf
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		buf = appendMsgPackHeader(buf, len(v), 0x80, 0xde)
+		for _, key := range keys {
+			buf = appendMsgPackString(buf, key)
+			buf = appendMsgPack(buf, v[key])
+		}
+		return buf
+	}
+	return append(buf, 0xc0)
+}
+
+// appendMsgPackInt appends an integer in its shortest encoding.
+func appendMsgPackInt(buf []byte, n int64) []byte {
+	switch {
+	case n >= 0 && n <= math.MaxInt8:
+		return append(buf, byte(n))
+	case n < 0 && n >= -32:
+		return append(buf, byte(n))
+	case n >= math.MinInt8 && n <= math.MaxInt8:
+		return append(buf, 0xd0, byte(n))
+	case n >= math.MinInt16 && n <= math.MaxInt16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xd1), uint16(n))
+	case n >= math.MinInt32 && n <= math.MaxInt32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xd2), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(buf, 0xd3), uint64(n))
+}
+
+// appendMsgPackString appends a UTF-8 string.
+func appendMsgPackString(buf []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		buf = append(buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		buf = append(buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		buf = binary.BigEndian.AppendUint16(append(buf, 0xda), uint16(n))
+	default:
+		buf = binary.BigEndian.AppendUint32(append(buf, 0xdb), uint32(n))
+	}
+	return append(buf, s...)
+}
+
+// appendMsgPackHeader appends the header of an array or map of n elements:
+// the fix type for up to 15, otherwise the 16-bit type or the 32-bit type
+// that follows it.
+func appendMsgPackHeader(buf []byte, n int, fix, type16 byte) []byte {
+	switch {
+	case n < 16:
+		return append(buf, fix|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, type16), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(buf, type16+1), uint32(n))
+}
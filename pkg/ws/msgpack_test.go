@@ -0,0 +1,51 @@
+package ws
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestToMsgPack verifies JSON values are encoded in their shortest
+// MessagePack form.
+func TestToMsgPack(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		expected []byte
+	}{
+		{"fixmap with sorted keys", `{"b":true,"a":null}`, []byte{0x82, 0xa1, 'a', 0xc0, 0xa1, 'b', 0xc3}},
+		{"positive fixint", `[1,127]`, []byte{0x92, 0x01, 0x7f}},
+		{"negative fixint and int8", `[-1,-100]`, []byte{0x92, 0xff, 0xd0, 0x9c}},
+		{"int16 and int32", `[1000,100000]`, []byte{0x92, 0xd1, 0x03, 0xe8, 0xd2, 0x00, 0x01, 0x86, 0xa0}},
+		{"float64", `[0.5]`, []byte{0x91, 0xcb, 0x3f, 0xe0, 0, 0, 0, 0, 0, 0}},
+		{"str8", `["` + string(bytes.Repeat([]byte("x"), 40)) + `"]`, append([]byte{0x91, 0xd9, 40}, bytes.Repeat([]byte("x"), 40)...)},
+		{"not JSON", `price`, []byte("price")},
+	}
+
+	for _, tt := range tests {
+		if got := toMsgPack([]byte(tt.json)); !bytes.Equal(got, tt.expected) {
+			t.Errorf("%s: expected % x, got % x", tt.name, tt.expected, got)
+		}
+	}
+}
+
+// TestRenderMsgPackOncePerBroadcast verifies MessagePack clients share one
+// rendering, while throttled ones receive JSON to coalesce.
+func TestRenderMsgPackOncePerBroadcast(t *testing.T) {
+	message := []byte(`{"type":"multi_update","data":[{"symbol":"BTCUSDT","price":45000.5}]}`)
+	first := &Client{Encoding: EncodingMsgPack}
+	second := &Client{Encoding: EncodingMsgPack}
+	throttled := &Client{Encoding: EncodingMsgPack, RefreshInterval: 1}
+
+	var cache renderCache
+	payload := first.payloadFor(message, &cache)
+	if isJSON(payload) || payload[0] != 0x82 {
+		t.Fatalf("Expected a MessagePack map, got % x", payload)
+	}
+	if again := second.payloadFor(message, &cache); &again[0] != &payload[0] {
+		t.Error("Expected the rendering to be shared")
+	}
+	if coalesced := throttled.payloadFor(message, &cache); !bytes.Equal(coalesced, message) {
+		t.Errorf("Expected JSON for a throttled client, got % x", coalesced)
+	}
+}
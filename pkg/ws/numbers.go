@@ -0,0 +1,144 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// NumberFormat is how price updates carry the price and the absolute
+// change of a symbol, selected per client at connect time.
+type NumberFormat string
+
+const (
+	// NumbersFloat sends JSON numbers (default)
+	NumbersFloat NumberFormat = "float"
+
+	// NumbersString sends decimal strings such as "0.3", like Binance does,
+	// so clients can parse them into decimal types without float artifacts
+	NumbersString NumberFormat = "string"
+
+	// NumbersScaled sends integers with a shared priceScale: a price of
+	// 6432112 with a priceScale of 2 is 64321.12
+	NumbersScaled NumberFormat = "scaled"
+)
+
+// NumberFormats are the formats clients may select.
+var NumberFormats = []NumberFormat{NumbersFloat, NumbersString, NumbersScaled}
+
+// MaxPriceDecimals is the precision of formatted prices, the most decimal
+// places Binance quotes.
+const MaxPriceDecimals = 8
+
+// priceFields are the price update fields formatted by a NumberFormat
+var priceFields = []string{"price", "change"}
+
+// ParseNumberFormat validates a number format, defaulting to NumbersFloat
+// when empty.
+func ParseNumberFormat(value string) (NumberFormat, error) {
+	if value == "" {
+		return NumbersFloat, nil
+	}
+	format := NumberFormat(strings.ToLower(value))
+	if !slices.Contains(NumberFormats, format) {
+		return "", fmt.Errorf("invalid number format %q (expected one of %v)", value, NumberFormats)
+	}
+	return format, nil
+}
+
+// formatNumbers rewrites the prices of the price updates in a payload to
+// the given format. Other messages and NumbersFloat pass unchanged.
+func formatNumbers(payload []byte, format NumberFormat) []byte {
+	if format != NumbersString && format != NumbersScaled {
+		return payload
+	}
+
+	return rewriteUpdates(payload, func(update map[string]json.RawMessage) {
+		decimals := make(map[string]string, len(priceFields))
+		for _, field := range priceFields {
+			var value float64
+			if raw, ok := update[field]; ok && json.Unmarshal(raw, &value) == nil {
+				decimals[field] = decimalString(value)
+			}
+		}
+		if len(decimals) == 0 {
+			return
+		}
+
+		if format == NumbersString {
+			for field, decimal := range decimals {
+				update[field], _ = json.Marshal(decimal)
+			}
+			return
+		}
+
+		// All prices of an update share the scale of the most precise one
+		scale := 0
+		for _, decimal := range decimals {
+			if _, fraction, found := strings.Cut(decimal, "."); found {
+				scale = max(scale, len(fraction))
+			}
+		}
+		for field, decimal := range decimals {
+			update[field] = json.RawMessage(scaledInteger(decimal, scale))
+		}
+		update["priceScale"] = json.RawMessage(strconv.Itoa(scale))
+	})
+}
+
+// parseNumbers restores the JSON number prices of price updates formatted
+// by formatNumbers, e.g. so throttled clients can coalesce them.
+func parseNumbers(payload []byte) []byte {
+	return rewriteUpdates(payload, func(update map[string]json.RawMessage) {
+		var scale int
+		if raw, ok := update["priceScale"]; ok {
+			json.Unmarshal(raw, &scale)
+			delete(update, "priceScale")
+		}
+
+		for _, field := range priceFields {
+			raw, ok := update[field]
+			if !ok {
+				continue
+			}
+			var decimal string
+			if json.Unmarshal(raw, &decimal) == nil {
+				raw = json.RawMessage(decimal)
+			}
+			value, err := strconv.ParseFloat(string(raw), 64)
+			if err != nil {
+				continue
+			}
+			update[field], _ = json.Marshal(value / math.Pow10(scale))
+		}
+	})
+}
+
+// decimalString formats a price with at most MaxPriceDecimals places and
+// without trailing zeros, dropping the artifacts of float arithmetic.
+func decimalString(value float64) string {
+	decimal := strconv.FormatFloat(value, 'f', MaxPriceDecimals, 64)
+	decimal = strings.TrimRight(decimal, "0")
+	decimal = strings.TrimSuffix(decimal, ".")
+	if decimal == "-0" {
+		return "0"
+	}
+	return decimal
+}
+
+// scaledInteger returns the digits of a decimal string as an integer of
+// the given scale, e.g. "64321.1" at scale 2 is 6432110.
+func scaledInteger(decimal string, scale int) string {
+	whole, fraction, _ := strings.Cut(decimal, ".")
+	digits := strings.TrimLeft(whole+fraction+strings.Repeat("0", scale-len(fraction)), "-0")
+	if digits == "" {
+		return "0"
+	}
+	if strings.HasPrefix(whole, "-") {
+		return "-" + digits
+	}
+	return digits
+}
@@ -0,0 +1,100 @@
+package ws
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// TestFormatNumbers verifies prices are sent as decimal strings or scaled
+// integers without float artifacts.
+func TestFormatNumbers(t *testing.T) {
+	update, _ := json.Marshal(&MultiUpdate{Type: "multi_update", Data: []*PriceUpdate{
+		{Symbol: "BTCUSDT", Price: 0.1 + 0.2, Change: -0.5, ChangePercent: 1.5},
+	}})
+
+	tests := []struct {
+		name     string
+		payload  []byte
+		format   NumberFormat
+		expected string
+	}{
+		{"float", update, NumbersFloat, string(update)},
+		{"string", update, NumbersString,
+			`{"data":[{"change":"-0.5","changePercent":1.5,"eventTime":0,"price":"0.3","receivedAt":0,"symbol":"BTCUSDT","timestamp":"","volume":0}],"type":"multi_update"}`},
+		{"scaled", update, NumbersScaled,
+			`{"data":[{"change":-5,"changePercent":1.5,"eventTime":0,"price":3,"priceScale":1,"receivedAt":0,"symbol":"BTCUSDT","timestamp":"","volume":0}],"type":"multi_update"}`},
+		{"ndjson", []byte(`{"type":"price_update","symbol":"ETHUSDT","price":3012.25}` + "\n"), NumbersScaled,
+			`{"price":301225,"priceScale":2,"symbol":"ETHUSDT","type":"price_update"}` + "\n"},
+		{"other message", []byte(`{"type":"heartbeat","timestamp":1}`), NumbersString, `{"type":"heartbeat","timestamp":1}`},
+	}
+
+	for _, tt := range tests {
+		if got := string(formatNumbers(tt.payload, tt.format)); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+}
+
+// TestParseNumbers verifies formatted prices are restored to numbers.
+func TestParseNumbers(t *testing.T) {
+	for _, format := range []NumberFormat{NumbersString, NumbersScaled} {
+		update, _ := json.Marshal(&MultiUpdate{Type: "multi_update", Data: []*PriceUpdate{
+			{Symbol: "BTCUSDT", Price: 64321.12, Change: -12.5},
+		}})
+
+		var restored MultiUpdate
+		if err := json.Unmarshal(parseNumbers(formatNumbers(update, format)), &restored); err != nil {
+			t.Fatalf("%s: failed to decode restored update: %v", format, err)
+		}
+		if got := restored.Data[0]; got.Price != 64321.12 || got.Change != -12.5 {
+			t.Errorf("%s: expected the original prices, got %+v", format, got)
+		}
+	}
+}
+
+// TestParseNumberFormat verifies number formats are validated.
+func TestParseNumberFormat(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected NumberFormat
+		valid    bool
+	}{
+		{"", NumbersFloat, true},
+		{"string", NumbersString, true},
+		{"SCALED", NumbersScaled, true},
+		{"decimal", "", false},
+	}
+
+	for _, tt := range tests {
+		format, err := ParseNumberFormat(tt.value)
+		if (err == nil) != tt.valid || format != tt.expected {
+			t.Errorf("%q: expected %q (valid %v), got %q, %v", tt.value, tt.expected, tt.valid, format, err)
+		}
+	}
+}
+
+// TestCoalescedUpdatesFormatted verifies throttled clients coalesce updates
+// rendered in their number format and receive them in that format.
+func TestCoalescedUpdatesFormatted(t *testing.T) {
+	client := &Client{Numbers: NumbersString}
+	prices := newCoalescer(0, client.Numbers)
+	for _, price := range []float64{50000, 50001.5} {
+		update, _ := json.Marshal(&MultiUpdate{Type: "multi_update", Data: []*PriceUpdate{{Symbol: "BTCUSDT", Price: price}}})
+		var cache renderCache
+		if !prices.add(client.payloadFor(update, &cache)) {
+			t.Fatal("Expected the formatted update to be coalesced")
+		}
+	}
+
+	flushed, _ := prices.flush(time.Now())
+
+	var received struct {
+		Data []struct {
+			Price string `json:"price"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(client.project(flushed), &received); err != nil || len(received.Data) != 1 || received.Data[0].Price != "50001.5" {
+		t.Errorf("Expected the latest price as a string, got %s", client.project(flushed))
+	}
+}
@@ -0,0 +1,237 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/common"
+
+	"macro-analyst/pkg/clock"
+)
+
+// OrderBookDepths are the levels per side Binance partial book depth
+// streams offer
+var OrderBookDepths = []int{5, 10, 20}
+
+const (
+	// DefaultOrderBookDepth is the number of bid and ask levels streamed
+	// when the configured depth is invalid
+	DefaultOrderBookDepth = 10
+
+	// DefaultOrderBookInterval is the throttle interval of order book
+	// ingestors, slower than price updates as books are much larger
+	DefaultOrderBookInterval = time.Second
+)
+
+// orderBookUpdateSpeed is the update speed requested from Binance. Books
+// are collected at that rate and broadcast at the throttle interval.
+const orderBookUpdateSpeed = "100ms"
+
+// OrderBookLevel is a price level of an order book.
+type OrderBookLevel struct {
+	Price    float64 `json:"price"`
+	Quantity float64 `json:"quantity"` // Base asset quantity
+}
+
+// OrderBookUpdate is the top of the order book of one symbol, best levels
+// first.
+type OrderBookUpdate struct {
+	Type         string           `json:"type"` // Always "orderbook"
+	Symbol       string           `json:"symbol"`
+	Bids         []OrderBookLevel `json:"bids"`         // Highest price first
+	Asks         []OrderBookLevel `json:"asks"`         // Lowest price first
+	LastUpdateID int64            `json:"lastUpdateId"` // Binance book update ID
+	ReceivedAt   int64            `json:"receivedAt"`   // Server receive time (Unix ms)
+}
+
+// WithOrderBook switches the ingestor to order book mode: instead of ticker
+// updates it streams the Binance partial book depth of the given number of
+// levels per side and broadcasts the latest book of each symbol that
+// changed once per throttle interval, as depth updates arrive far more
+// often than clients need them.
+func WithOrderBook(depth int) IngestorOption {
+	return func(i *Ingestor) {
+		if depth > 0 {
+			i.stream = &orderBookStream{depth: depth, pending: make(map[string]*OrderBookUpdate)}
+		}
+	}
+}
+
+// orderBookStream streams the partial books of the tracked symbols.
+type orderBookStream struct {
+	depth int // Levels per side
+
+	mu      sync.Mutex                  // Protects pending
+	pending map[string]*OrderBookUpdate // Latest book per symbol since the last broadcast
+}
+
+// ParseOrderBookDepth validates the number of levels per side of order
+// books, 5, 10 or 20.
+func ParseOrderBookDepth(value string) (int, error) {
+	depth, err := strconv.Atoi(value)
+	if err != nil || !slices.Contains(OrderBookDepths, depth) {
+		return 0, fmt.Errorf("invalid order book depth %q (expected one of %v)", value, OrderBookDepths)
+	}
+	return depth, nil
+}
+
+// OrderBookMode reports whether the ingestor streams order books instead of
+// ticker updates.
+func (i *Ingestor) OrderBookMode() bool {
+	_, ok := i.stream.(*orderBookStream)
+	return ok
+}
+
+// serve connects to the Binance partial book depth streams of all symbols
+// in one connection and broadcasts the latest books at the throttle
+// interval, re-establishing the connection whenever symbols are added or
+// removed.
+func (s *orderBookStream) serve(i *Ingestor) {
+	if len(i.GetSymbols()) == 0 {
+		log.Println("No symbols to track")
+		return
+	}
+
+	throttleTicker := i.clock.NewTicker(i.throttleInterval)
+	defer throttleTicker.Stop()
+
+	depthHandler := s.depthHandler(i)
+	errHandler := i.createErrorHandler()
+	levels := fmt.Sprintf("%d@%s", s.depth, orderBookUpdateSpeed)
+
+	i.runStream(func(symbols []string) (chan struct{}, chan struct{}, error) {
+		streams := make(map[string]string, len(symbols))
+		for _, symbol := range symbols {
+			streams[symbol] = levels
+		}
+		return binance.WsCombinedPartialDepthServe(streams, depthHandler, errHandler)
+	}, func() {
+		i.startPeriodicBroadcast(throttleTicker, func() { s.broadcast(i) })
+	})
+}
+
+func (s *orderBookStream) replays(kind string) bool {
+	return kind == "orderbook"
+}
+
+// depthHandler creates a handler keeping the latest book of each symbol
+// until the next broadcast.
+func (s *orderBookStream) depthHandler(i *Ingestor) func(*binance.WsPartialDepthEvent) {
+	return func(event *binance.WsPartialDepthEvent) {
+		now := i.clock.Now()
+		i.markEventReceived(now)
+
+		book, err := convertDepthEvent(event)
+		i.recordEvent(event.Symbol, err)
+		if err != nil {
+			return
+		}
+		book.ReceivedAt = now.UnixMilli()
+
+		s.mu.Lock()
+		s.pending[book.Symbol] = book
+		s.mu.Unlock()
+	}
+}
+
+// startPeriodicBroadcast starts a goroutine that calls broadcast at the
+// throttle interval, less often while the Hub signals backpressure.
+func (i *Ingestor) startPeriodicBroadcast(throttleTicker clock.Ticker, broadcast func()) {
+	backpressure := i.audience.SubscribeBackpressure()
+
+	go func() {
+		defer i.audience.UnsubscribeBackpressure(backpressure)
+
+		for {
+			select {
+			case <-i.ctx.Done():
+				log.Println("Ingestor stopped")
+				return
+			case state := <-backpressure:
+				i.applyBackpressure(state, throttleTicker)
+			case <-throttleTicker.C():
+				broadcast()
+			}
+		}
+	}()
+}
+
+// broadcast broadcasts the books received since the last broadcast, one
+// message per symbol, unless another feed is active.
+func (s *orderBookStream) broadcast(i *Ingestor) {
+	s.mu.Lock()
+	books := s.pending
+	s.pending = make(map[string]*OrderBookUpdate)
+	s.mu.Unlock()
+
+	if len(books) == 0 || !i.audience.IsActiveSource(i.name) {
+		return
+	}
+
+	symbols := make([]string, 0, len(books))
+	for symbol := range books {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	for _, symbol := range symbols {
+		data, err := json.Marshal(books[symbol])
+		if err != nil {
+			log.Printf("Error marshaling order book: %v", err)
+			continue
+		}
+		if !i.publish(SymbolTopic(TopicOrderBook, symbol), data) {
+			continue
+		}
+
+		i.mu.Lock()
+		if tracked := i.findSymbol(symbol); tracked != nil {
+			tracked.UpdatesBroadcast++
+		}
+		i.mu.Unlock()
+	}
+}
+
+// convertDepthEvent converts a Binance partial depth event to our
+// OrderBookUpdate format. It returns an error if a price or quantity fails
+// to parse, a price is not positive or a quantity is negative.
+func convertDepthEvent(event *binance.WsPartialDepthEvent) (*OrderBookUpdate, error) {
+	book := &OrderBookUpdate{
+		Type:         "orderbook",
+		Symbol:       event.Symbol,
+		LastUpdateID: event.LastUpdateID,
+	}
+
+	var err error
+	if book.Bids, err = convertLevels(event.Symbol, event.Bids); err != nil {
+		return nil, err
+	}
+	if book.Asks, err = convertLevels(event.Symbol, event.Asks); err != nil {
+		return nil, err
+	}
+	return book, nil
+}
+
+// convertLevels parses the price levels of one side of a book.
+func convertLevels(symbol string, levels []common.PriceLevel) ([]OrderBookLevel, error) {
+	converted := make([]OrderBookLevel, len(levels))
+	for idx, level := range levels {
+		price, err := strconv.ParseFloat(level.Price, 64)
+		if err != nil || price <= 0 {
+			return nil, fmt.Errorf("invalid order book price %q for %s", level.Price, symbol)
+		}
+		quantity, err := strconv.ParseFloat(level.Quantity, 64)
+		if err != nil || quantity < 0 {
+			return nil, fmt.Errorf("invalid order book quantity %q for %s", level.Quantity, symbol)
+		}
+		converted[idx] = OrderBookLevel{Price: price, Quantity: quantity}
+	}
+	return converted, nil
+}
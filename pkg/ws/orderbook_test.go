@@ -0,0 +1,121 @@
+package ws
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/adshao/go-binance/v2"
+)
+
+// TestConvertDepthEvent verifies partial depth events are converted to
+// order book updates.
+func TestConvertDepthEvent(t *testing.T) {
+	event := &binance.WsPartialDepthEvent{
+		Symbol:       "BTCUSDT",
+		LastUpdateID: 160,
+		Bids:         []binance.Bid{{Price: "51234.50", Quantity: "1.5"}, {Price: "51234.00", Quantity: "0.25"}},
+		Asks:         []binance.Ask{{Price: "51235.00", Quantity: "2"}},
+	}
+
+	book, err := convertDepthEvent(event)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := &OrderBookUpdate{
+		Type: "orderbook", Symbol: "BTCUSDT", LastUpdateID: 160,
+		Bids: []OrderBookLevel{{51234.5, 1.5}, {51234, 0.25}},
+		Asks: []OrderBookLevel{{51235, 2}},
+	}
+	if !reflect.DeepEqual(book, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, book)
+	}
+}
+
+// TestConvertDepthEventInvalid verifies books with unparsable prices or
+// quantities are rejected.
+func TestConvertDepthEventInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		level binance.Bid
+	}{
+		{"invalid price", binance.Bid{Price: "invalid", Quantity: "1"}},
+		{"zero price", binance.Bid{Price: "0", Quantity: "1"}},
+		{"invalid quantity", binance.Bid{Price: "1", Quantity: ""}},
+		{"negative quantity", binance.Bid{Price: "1", Quantity: "-1"}},
+	}
+
+	for _, tt := range tests {
+		event := &binance.WsPartialDepthEvent{Symbol: "BTCUSDT", Asks: []binance.Ask{tt.level}}
+		if book, err := convertDepthEvent(event); err == nil {
+			t.Errorf("%s: expected an error, got %+v", tt.name, book)
+		}
+	}
+}
+
+// TestParseOrderBookDepth verifies only the depths Binance streams are
+// accepted.
+func TestParseOrderBookDepth(t *testing.T) {
+	if depth, err := ParseOrderBookDepth("20"); err != nil || depth != 20 {
+		t.Errorf("Expected depth 20, got %d, %v", depth, err)
+	}
+	for _, value := range []string{"15", "ten", ""} {
+		if _, err := ParseOrderBookDepth(value); err == nil {
+			t.Errorf("%q: expected an error", value)
+		}
+	}
+}
+
+// TestBroadcastOrderBooks verifies only the latest book of each symbol is
+// broadcast per interval, and counted per symbol.
+func TestBroadcastOrderBooks(t *testing.T) {
+	// Arrange
+	hub := NewHub()
+	ingestor := NewIngestor(HubSink(hub), WithSymbols([]string{"BTCUSDT", "ETHUSDT"}), WithOrderBook(5))
+	books := ingestor.stream.(*orderBookStream)
+	handler := books.depthHandler(ingestor)
+
+	// Act
+	for _, bid := range []string{"100", "101"} {
+		handler(&binance.WsPartialDepthEvent{Symbol: "BTCUSDT", Bids: []binance.Bid{{Price: bid, Quantity: "1"}}})
+	}
+	handler(&binance.WsPartialDepthEvent{Symbol: "ETHUSDT", Asks: []binance.Ask{{Price: "10", Quantity: "3"}}})
+	books.broadcast(ingestor)
+	books.broadcast(ingestor)
+
+	// Assert
+	if len(hub.broadcast) != 2 {
+		t.Fatalf("Expected one book per symbol, got %d messages", len(hub.broadcast))
+	}
+	var book OrderBookUpdate
+	if err := json.Unmarshal((<-hub.broadcast).data, &book); err != nil {
+		t.Fatalf("Failed to decode order book: %v", err)
+	}
+	if book.Type != "orderbook" || book.Symbol != "BTCUSDT" || book.Bids[0].Price != 101 || book.ReceivedAt == 0 {
+		t.Errorf("Expected the latest BTCUSDT book, got %+v", book)
+	}
+
+	if !ingestor.OrderBookMode() {
+		t.Error("Expected order book mode")
+	}
+	if stats := ingestor.Stats(); stats[0].UpdatesBroadcast != 1 || stats[0].EventsReceived != 2 {
+		t.Errorf("Expected 2 events and 1 broadcast, got %+v", stats[0])
+	}
+}
+
+// TestBroadcastOrderBooksInactiveSource verifies books of an inactive feed
+// are not broadcast.
+func TestBroadcastOrderBooksInactiveSource(t *testing.T) {
+	hub := NewHub()
+	hub.SetActiveSource("green")
+	ingestor := NewIngestor(HubSink(hub), WithSymbols([]string{"BTCUSDT"}), WithOrderBook(5))
+
+	books := ingestor.stream.(*orderBookStream)
+	books.depthHandler(ingestor)(&binance.WsPartialDepthEvent{Symbol: "BTCUSDT"})
+	books.broadcast(ingestor)
+
+	if len(hub.broadcast) != 0 {
+		t.Errorf("Expected no broadcast, got %d messages", len(hub.broadcast))
+	}
+}
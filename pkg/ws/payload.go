@@ -0,0 +1,107 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+// DefaultMaxPayloadSize is the largest serialized broadcast, in bytes,
+// before a MultiUpdate is split into several frames. It stays well below
+// the frame limits of common proxies.
+const DefaultMaxPayloadSize = 64 * 1024
+
+// WithMaxPayloadSize sets the largest serialized MultiUpdate in bytes. Larger
+// updates are split into frames carrying part/total indicators. Zero
+// disables splitting.
+func WithMaxPayloadSize(size int) IngestorOption {
+	return func(i *Ingestor) {
+		i.maxPayloadSize = size
+	}
+}
+
+// encodeFrames marshals a MultiUpdate into one or more frames no larger
+// than the maximum payload size. A single update that exceeds the limit on
+// its own is still sent, alone in its frame.
+func (i *Ingestor) encodeFrames(update *MultiUpdate) ([][]byte, error) {
+	update.Region = i.audience.Region()
+
+	jsonData, err := json.Marshal(update)
+	if err != nil {
+		return nil, err
+	}
+	if i.maxPayloadSize <= 0 || len(jsonData) <= i.maxPayloadSize {
+		return [][]byte{jsonData}, nil
+	}
+
+	chunks, err := i.splitUpdates(update.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	frames := make([][]byte, len(chunks))
+	for idx, chunk := range chunks {
+		frames[idx], err = json.Marshal(&MultiUpdate{
+			Type:   update.Type,
+			Data:   chunk,
+			Part:   idx + 1,
+			Total:  len(chunks),
+			Region: update.Region,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	i.metrics.payloadSplits.Inc()
+	log.Printf("⚠ Update of %d bytes exceeds max payload size %d, split into %d frames",
+		len(jsonData), i.maxPayloadSize, len(frames))
+	return frames, nil
+}
+
+// splitUpdates groups price updates so each group, wrapped in a MultiUpdate
+// frame, stays within the maximum payload size.
+func (i *Ingestor) splitUpdates(updates []*PriceUpdate) ([][]*PriceUpdate, error) {
+	// Size of the frame envelope with room for the largest part/total values
+	envelope, err := json.Marshal(&MultiUpdate{
+		Type:   "multi_update",
+		Part:   len(updates),
+		Total:  len(updates),
+		Region: i.audience.Region(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	budget := i.maxPayloadSize - len(envelope)
+
+	var (
+		chunks  [][]*PriceUpdate
+		current []*PriceUpdate
+		size    int
+	)
+	for _, update := range updates {
+		encoded, err := json.Marshal(update)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal update for %s: %w", update.Symbol, err)
+		}
+
+		// Each element after the first also needs a separating comma
+		needed := len(encoded)
+		if len(current) > 0 {
+			needed++
+		}
+
+		if len(current) > 0 && size+needed > budget {
+			chunks = append(chunks, current)
+			current, size = nil, 0
+			needed = len(encoded)
+		}
+		current = append(current, update)
+		size += needed
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+
+	return chunks, nil
+}
@@ -0,0 +1,108 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+// testUpdates builds n distinct price updates.
+func testUpdates(n int) []*PriceUpdate {
+	updates := make([]*PriceUpdate, n)
+	for idx := range updates {
+		updates[idx] = &PriceUpdate{
+			Symbol:    fmt.Sprintf("SYM%03dUSDT", idx),
+			Price:     float64(idx + 1),
+			Timestamp: "10:30:00.000",
+		}
+	}
+	return updates
+}
+
+// TestEncodeFramesUnderLimit verifies small updates are not split.
+func TestEncodeFramesUnderLimit(t *testing.T) {
+	ingestor := NewIngestor(HubSink(NewHub()))
+
+	frames, err := ingestor.encodeFrames(&MultiUpdate{Type: "multi_update", Data: testUpdates(3)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(frames) != 1 {
+		t.Fatalf("Expected 1 frame, got %d", len(frames))
+	}
+
+	var update MultiUpdate
+	if err := json.Unmarshal(frames[0], &update); err != nil {
+		t.Fatalf("Failed to decode frame: %v", err)
+	}
+	if update.Part != 0 || update.Total != 0 {
+		t.Errorf("Expected no part/total on unsplit frame, got %d/%d", update.Part, update.Total)
+	}
+}
+
+// TestEncodeFramesSplitsLargeUpdates verifies oversized updates are split
+// into frames within the limit that together carry every symbol.
+func TestEncodeFramesSplitsLargeUpdates(t *testing.T) {
+	maxSize := 512
+	ingestor := NewIngestor(HubSink(NewHub()), WithMaxPayloadSize(maxSize))
+	updates := testUpdates(20)
+
+	frames, err := ingestor.encodeFrames(&MultiUpdate{Type: "multi_update", Data: updates})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(frames) < 2 {
+		t.Fatalf("Expected the update to be split, got %d frame", len(frames))
+	}
+
+	symbols := 0
+	for idx, frame := range frames {
+		if len(frame) > maxSize {
+			t.Errorf("Frame %d is %d bytes, exceeds limit %d", idx, len(frame), maxSize)
+		}
+
+		var update MultiUpdate
+		if err := json.Unmarshal(frame, &update); err != nil {
+			t.Fatalf("Failed to decode frame %d: %v", idx, err)
+		}
+		if update.Type != "multi_update" || update.Part != idx+1 || update.Total != len(frames) {
+			t.Errorf("Frame %d: unexpected header %s %d/%d", idx, update.Type, update.Part, update.Total)
+		}
+		symbols += len(update.Data)
+	}
+
+	if symbols != len(updates) {
+		t.Errorf("Expected %d symbols across frames, got %d", len(updates), symbols)
+	}
+}
+
+// TestEncodeFramesOversizedSingleUpdate verifies an update larger than the
+// limit on its own is still delivered.
+func TestEncodeFramesOversizedSingleUpdate(t *testing.T) {
+	ingestor := NewIngestor(HubSink(NewHub()), WithMaxPayloadSize(10))
+
+	frames, err := ingestor.encodeFrames(&MultiUpdate{Type: "multi_update", Data: testUpdates(2)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(frames) != 2 {
+		t.Errorf("Expected one frame per update, got %d", len(frames))
+	}
+}
+
+// TestEncodeFramesDisabled verifies a zero limit never splits.
+func TestEncodeFramesDisabled(t *testing.T) {
+	ingestor := NewIngestor(HubSink(NewHub()), WithMaxPayloadSize(0))
+
+	frames, err := ingestor.encodeFrames(&MultiUpdate{Type: "multi_update", Data: testUpdates(100)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(frames) != 1 {
+		t.Errorf("Expected 1 frame with splitting disabled, got %d", len(frames))
+	}
+}
@@ -0,0 +1,137 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// DefaultBroadcastHistory is how many recent broadcasts the Hub keeps for
+// long-polling clients
+const DefaultBroadcastHistory = 256
+
+// SequencedBroadcast is a broadcast message with its sequence number.
+type SequencedBroadcast struct {
+	Seq  uint64          `json:"seq"`
+	Data json.RawMessage `json:"data"`
+}
+
+// BroadcastPage holds the broadcasts newer than a sequence number.
+type BroadcastPage struct {
+	Messages []SequencedBroadcast `json:"messages"`
+
+	// LastSeq is the sequence number of the newest broadcast; clients pass
+	// it as since_seq to continue
+	LastSeq uint64 `json:"last_seq"`
+
+	// Missed is set when broadcasts after the requested sequence number were
+	// evicted from the history or the server restarted, so the client should
+	// resync its state, e.g. from /api/prices
+	Missed bool `json:"missed"`
+}
+
+// WithBroadcastHistory sets how many recent broadcasts are kept for
+// long-polling clients. Zero disables the history.
+func WithBroadcastHistory(size int) HubOption {
+	return func(h *Hub) {
+		h.history.size = size
+	}
+}
+
+// broadcastHistory is a ring of the most recent broadcasts, numbered from 1.
+type broadcastHistory struct {
+	mu       sync.Mutex
+	size     int
+	messages []SequencedBroadcast // Ring, oldest at start once full
+	start    int
+	lastSeq  uint64
+	updated  chan struct{} // Closed and replaced on each broadcast
+}
+
+// KeepsHistory reports whether the Hub keeps recent broadcasts for
+// long-polling clients.
+func (h *Hub) KeepsHistory() bool {
+	return h.history.size > 0
+}
+
+// recordBroadcast appends a broadcast to the history and wakes waiting
+// pollers.
+func (h *Hub) recordBroadcast(data []byte) {
+	hist := &h.history
+	if hist.size <= 0 {
+		return
+	}
+
+	hist.mu.Lock()
+	defer hist.mu.Unlock()
+
+	hist.lastSeq++
+	message := SequencedBroadcast{Seq: hist.lastSeq, Data: data}
+	if len(hist.messages) < hist.size {
+		hist.messages = append(hist.messages, message)
+	} else {
+		hist.messages[hist.start] = message
+		hist.start = (hist.start + 1) % hist.size
+	}
+
+	if hist.updated != nil {
+		close(hist.updated)
+		hist.updated = nil
+	}
+}
+
+// PollBroadcasts returns the broadcasts after sinceSeq, waiting until there
+// is one or ctx is done. A sinceSeq of 0 returns the whole history.
+func (h *Hub) PollBroadcasts(ctx context.Context, sinceSeq uint64) BroadcastPage {
+	for {
+		page, updated := h.broadcastsSince(sinceSeq)
+		if len(page.Messages) > 0 || updated == nil {
+			return page
+		}
+
+		select {
+		case <-updated:
+		case <-ctx.Done():
+			return page
+		}
+	}
+}
+
+// broadcastsSince returns the broadcasts after sinceSeq and, if there are
+// none, a channel closed on the next broadcast.
+func (h *Hub) broadcastsSince(sinceSeq uint64) (BroadcastPage, <-chan struct{}) {
+	hist := &h.history
+	hist.mu.Lock()
+	defer hist.mu.Unlock()
+
+	page := BroadcastPage{Messages: []SequencedBroadcast{}, LastSeq: hist.lastSeq}
+	if hist.size <= 0 {
+		return page, nil
+	}
+
+	// Sequence numbers restart with the server
+	if sinceSeq > hist.lastSeq {
+		page.Missed = true
+		sinceSeq = 0
+	}
+
+	oldest := hist.lastSeq - uint64(len(hist.messages)) + 1
+	if sinceSeq > 0 && sinceSeq+1 < oldest {
+		page.Missed = true
+	}
+
+	for idx := range hist.messages {
+		message := hist.messages[(hist.start+idx)%len(hist.messages)]
+		if message.Seq > sinceSeq {
+			page.Messages = append(page.Messages, message)
+		}
+	}
+	if len(page.Messages) > 0 {
+		return page, nil
+	}
+
+	if hist.updated == nil {
+		hist.updated = make(chan struct{})
+	}
+	return page, hist.updated
+}
@@ -0,0 +1,74 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestPollBroadcastsSince verifies polls return the broadcasts after the
+// requested sequence number and flag gaps.
+func TestPollBroadcastsSince(t *testing.T) {
+	hub := NewHub(WithBroadcastHistory(3))
+	for idx := 1; idx <= 5; idx++ {
+		hub.recordBroadcast([]byte(fmt.Sprintf(`{"n":%d}`, idx)))
+	}
+
+	tests := []struct {
+		name     string
+		sinceSeq uint64
+		expected []uint64
+		missed   bool
+	}{
+		{"whole history", 0, []uint64{3, 4, 5}, false},
+		{"newer", 3, []uint64{4, 5}, false},
+		{"oldest kept follows", 2, []uint64{3, 4, 5}, false},
+		{"evicted", 1, []uint64{3, 4, 5}, true},
+		{"after server restart", 9, []uint64{3, 4, 5}, true},
+	}
+
+	for _, tt := range tests {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		page := hub.PollBroadcasts(ctx, tt.sinceSeq)
+
+		var seqs []uint64
+		for _, message := range page.Messages {
+			seqs = append(seqs, message.Seq)
+		}
+		if fmt.Sprint(seqs) != fmt.Sprint(tt.expected) || page.Missed != tt.missed || page.LastSeq != 5 {
+			t.Errorf("%s: expected %v (missed %v), got %v (missed %v, last %d)",
+				tt.name, tt.expected, tt.missed, seqs, page.Missed, page.LastSeq)
+		}
+	}
+}
+
+// TestPollBroadcastsWaits verifies a poll without newer broadcasts returns
+// with the next broadcast, or empty once its context is done.
+func TestPollBroadcastsWaits(t *testing.T) {
+	hub := NewHub()
+	hub.recordBroadcast([]byte(`{"n":1}`))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if page := hub.PollBroadcasts(ctx, 1); len(page.Messages) != 0 || page.LastSeq != 1 {
+		t.Errorf("Expected an empty page after the timeout, got %+v", page)
+	}
+
+	received := make(chan BroadcastPage)
+	go func() {
+		received <- hub.PollBroadcasts(context.Background(), 1)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	hub.recordBroadcast([]byte(`{"n":2}`))
+
+	select {
+	case page := <-received:
+		if len(page.Messages) != 1 || page.Messages[0].Seq != 2 || string(page.Messages[0].Data) != `{"n":2}` {
+			t.Errorf("Expected broadcast 2, got %+v", page)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the poll to return with the next broadcast")
+	}
+}
@@ -0,0 +1,130 @@
+package ws
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoPreferences is returned by the preference commands of clients that
+// cannot save preferences: anonymous ones and those of other streams.
+var ErrNoPreferences = errors.New("preferences require an authenticated price stream")
+
+// Preferences are the stream settings a user saved with save_preferences,
+// applied to each of their connections before it receives any update.
+type Preferences struct {
+	// Symbols subscribed to, nil for every symbol
+	Symbols []string `json:"symbols,omitempty"`
+
+	// Symbols unsubscribed from while subscribed to every symbol
+	Excluded []string `json:"excluded,omitempty"`
+
+	// Price update fields received besides the symbol, nil for every field
+	Fields []string `json:"fields,omitempty"`
+
+	// Refresh interval, 0 for every update
+	IntervalMs int64 `json:"intervalMs,omitempty"`
+}
+
+// PreferenceStore holds the saved preferences of each user in memory. It is
+// safe for concurrent use.
+type PreferenceStore struct {
+	mu          sync.RWMutex
+	preferences map[string]Preferences
+}
+
+// NewPreferenceStore creates an empty store.
+func NewPreferenceStore() *PreferenceStore {
+	return &PreferenceStore{preferences: make(map[string]Preferences)}
+}
+
+// Get returns the saved preferences of a user.
+func (s *PreferenceStore) Get(userID string) (Preferences, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	preferences, ok := s.preferences[userID]
+	return preferences, ok
+}
+
+// Set saves the preferences of a user, replacing earlier ones.
+func (s *PreferenceStore) Set(userID string, preferences Preferences) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.preferences[userID] = preferences
+}
+
+// Delete removes the preferences of a user. It reports whether there were
+// any.
+func (s *PreferenceStore) Delete(userID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.preferences[userID]
+	delete(s.preferences, userID)
+	return ok
+}
+
+// LoadPreferences applies the saved preferences of the client's user. A
+// RefreshInterval set at connect, e.g. from a query parameter, is kept.
+// Call it before registering the client with the Hub so no update is sent
+// with other settings. It reports whether preferences were applied.
+func (c *Client) LoadPreferences() bool {
+	if c.Preferences == nil || c.UserID == "" {
+		return false
+	}
+	preferences, ok := c.Preferences.Get(c.UserID)
+	if !ok {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.subscribed, c.excluded, c.fields = symbolSet(preferences.Symbols), symbolSet(preferences.Excluded), symbolSet(preferences.Fields)
+	c.projection = strings.Join(sortedSymbols(c.fields), ",")
+	c.scope.Store(nil)
+	if c.RefreshInterval == 0 {
+		c.RefreshInterval = max(time.Duration(preferences.IntervalMs)*time.Millisecond, c.MinRefreshInterval)
+	}
+	return true
+}
+
+// applyPreferenceCommand saves or clears the preferences of the client's
+// user.
+func (c *Client) applyPreferenceCommand(command Command) error {
+	if c.Preferences == nil || c.UserID == "" {
+		return ErrNoPreferences
+	}
+	if command.Type == CommandClearPreferences {
+		c.Preferences.Delete(c.UserID)
+		return nil
+	}
+
+	c.mu.RLock()
+	preferences := Preferences{
+		Symbols:    sortedSymbols(c.subscribed),
+		Excluded:   sortedSymbols(c.excluded),
+		Fields:     sortedSymbols(c.fields),
+		IntervalMs: c.RefreshInterval.Milliseconds(),
+	}
+	c.mu.RUnlock()
+
+	c.Preferences.Set(c.UserID, preferences)
+	return nil
+}
+
+// symbolSet converts a list to a set, nil for a nil list.
+func symbolSet(symbols []string) map[string]bool {
+	if symbols == nil {
+		return nil
+	}
+
+	set := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		set[symbol] = true
+	}
+	return set
+}
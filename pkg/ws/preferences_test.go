@@ -0,0 +1,69 @@
+package ws
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPreferencesRoundTrip verifies saved preferences are applied to the
+// user's next connection and cleared ones are not.
+func TestPreferencesRoundTrip(t *testing.T) {
+	store := NewPreferenceStore()
+	first := &Client{UserID: "alice", Preferences: store}
+	for _, command := range []string{
+		`{"type":"subscribe","symbols":["ETHUSDT","BTCUSDT"],"fields":["price"]}`,
+		`{"type":"set_throttle","intervalMs":2000}`,
+		`{"type":"save_preferences","id":"1"}`,
+	} {
+		if response, ok := first.handleCommand([]byte(command), time.Now()).(*CommandAck); !ok {
+			t.Fatalf("expected an ack for %s, got %+v", command, response)
+		}
+	}
+
+	second := &Client{UserID: "alice", Preferences: store}
+	if !second.LoadPreferences() {
+		t.Fatal("expected the saved preferences to be applied")
+	}
+	ack := second.ack(Command{Type: CommandPing})
+	if strings.Join(ack.Symbols, ",") != "BTCUSDT,ETHUSDT" || strings.Join(ack.Fields, ",") != "price" || ack.IntervalMs != 2000 {
+		t.Errorf("expected the saved settings, got %+v", ack)
+	}
+	if second.projection != "price" {
+		t.Errorf("expected the projection of the saved fields, got %q", second.projection)
+	}
+
+	if other := (&Client{UserID: "bob", Preferences: store}); other.LoadPreferences() {
+		t.Error("expected no preferences for another user")
+	}
+
+	second.handleCommand([]byte(`{"type":"clear_preferences"}`), time.Now())
+	if (&Client{UserID: "alice", Preferences: store}).LoadPreferences() {
+		t.Error("expected cleared preferences not to be applied")
+	}
+}
+
+// TestLoadPreferencesInterval verifies a refresh interval set at connect
+// takes precedence and the stream minimum is respected.
+func TestLoadPreferencesInterval(t *testing.T) {
+	store := NewPreferenceStore()
+	store.Set("alice", Preferences{IntervalMs: 1000})
+
+	tests := []struct {
+		name     string
+		client   *Client
+		expected time.Duration
+	}{
+		{"saved", &Client{}, time.Second},
+		{"set at connect", &Client{RefreshInterval: 5 * time.Second}, 5 * time.Second},
+		{"stream minimum", &Client{MinRefreshInterval: 3 * time.Second}, 3 * time.Second},
+	}
+
+	for _, tt := range tests {
+		tt.client.UserID, tt.client.Preferences = "alice", store
+		tt.client.LoadPreferences()
+		if got := tt.client.refreshInterval(); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
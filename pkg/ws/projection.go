@@ -0,0 +1,174 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ProjectableFields are the price update fields a client may select with
+// the fields of a subscribe command. The symbol, and the exchange of
+// aggregated prices, are always sent.
+var ProjectableFields = []string{
+	"price", "change", "changePercent", "volume", "timestamp",
+	"eventTime", "receivedAt", "restored", "sinceMidnightChangePercent",
+	"changeReference",
+}
+
+// renderCache holds the payloads rendered for one broadcast, so clients
+// with the same encoding, projection and number format, and the same
+// symbol scope and currency, share a single rendering.
+type renderCache struct {
+	rendered map[string][]byte
+}
+
+// render returns the message in the given encoding with only the projected
+// fields and prices in the given format, rendering it once per encoding,
+// projection and number format.
+func (rc *renderCache) render(message []byte, encoding Encoding, fields map[string]bool, projection string, numbers NumberFormat) []byte {
+	if numbers == NumbersFloat {
+		numbers = ""
+	}
+	if encoding != EncodingNDJSON && encoding != EncodingMsgPack && fields == nil && numbers == "" {
+		return message
+	}
+
+	key := string(encoding) + "|" + projection + "|" + string(numbers)
+	return rc.renderOnce(key, func() []byte {
+		payload := message
+		if encoding == EncodingNDJSON {
+			payload = toNDJSON(payload)
+		}
+		payload = formatNumbers(projectFields(payload, fields), numbers)
+		if encoding == EncodingMsgPack {
+			payload = toMsgPack(payload)
+		}
+		return payload
+	})
+}
+
+// renderOnce returns the payload cached under key, rendering and caching
+// it first if there is none. A nil payload is cached as well.
+func (rc *renderCache) renderOnce(key string, render func() []byte) []byte {
+	if payload, ok := rc.rendered[key]; ok {
+		return payload
+	}
+
+	payload := render()
+	if rc.rendered == nil {
+		rc.rendered = make(map[string][]byte)
+	}
+	rc.rendered[key] = payload
+	return payload
+}
+
+// projectFields strips the price updates of a multi_update, or of the
+// price_update lines of NDJSON, down to the symbol and the given fields.
+// Other messages and a nil field set pass unchanged.
+func projectFields(payload []byte, fields map[string]bool) []byte {
+	if fields == nil {
+		return payload
+	}
+	return rewriteUpdates(payload, func(update map[string]json.RawMessage) {
+		projectObject(update, fields, "type", "exchange")
+	})
+}
+
+// rewriteUpdates applies rewrite to each decoded price update of a
+// multi_update, or of each price_update line of NDJSON, and encodes the
+// result. Other messages pass unchanged.
+func rewriteUpdates(payload []byte, rewrite func(update map[string]json.RawMessage)) []byte {
+	if payload == nil {
+		return nil
+	}
+
+	// NDJSON: one object per line
+	if bytes.HasSuffix(payload, []byte("\n")) {
+		var buf bytes.Buffer
+		for _, line := range bytes.SplitAfter(payload, []byte("\n")) {
+			if len(line) == 0 {
+				continue
+			}
+			var object map[string]json.RawMessage
+			if err := json.Unmarshal(line, &object); err != nil || string(object["type"]) != `"price_update"` {
+				buf.Write(line)
+				continue
+			}
+			rewrite(object)
+			rewritten, err := json.Marshal(object)
+			if err != nil {
+				buf.Write(line)
+				continue
+			}
+			buf.Write(rewritten)
+			buf.WriteByte('\n')
+		}
+		return buf.Bytes()
+	}
+
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &envelope); err != nil || string(envelope["type"]) != `"multi_update"` {
+		return payload
+	}
+	var updates []map[string]json.RawMessage
+	if err := json.Unmarshal(envelope["data"], &updates); err != nil {
+		return payload
+	}
+
+	for _, update := range updates {
+		rewrite(update)
+	}
+	data, err := json.Marshal(updates)
+	if err != nil {
+		return payload
+	}
+	envelope["data"] = data
+
+	rewritten, err := json.Marshal(envelope)
+	if err != nil {
+		return payload
+	}
+	return rewritten
+}
+
+// projectObject keeps the symbol, the given fields and the extra keys of a
+// decoded price update.
+func projectObject(object map[string]json.RawMessage, fields map[string]bool, extra ...string) map[string]json.RawMessage {
+	for key := range object {
+		if key != "symbol" && !fields[key] && !slices.Contains(extra, key) {
+			delete(object, key)
+		}
+	}
+	return object
+}
+
+// parseFields validates the fields of a subscribe command and returns them
+// as a set, nil for an empty list, which restores every field.
+func parseFields(names []string) (map[string]bool, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	fields := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "symbol" {
+			continue
+		}
+		if !slices.Contains(ProjectableFields, name) {
+			return nil, fmt.Errorf("invalid field %q (expected any of %s)", name, strings.Join(ProjectableFields, ", "))
+		}
+		fields[name] = true
+	}
+	return fields, nil
+}
+
+// project strips a message down to the client's fields and formats its
+// prices, e.g. the coalesced updates of a throttled client.
+func (c *Client) project(message []byte) []byte {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return formatNumbers(projectFields(message, c.fields), c.Numbers)
+}
@@ -0,0 +1,92 @@
+package ws
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestProjectFields verifies price updates are stripped down to the symbol
+// and the selected fields.
+func TestProjectFields(t *testing.T) {
+	fields := map[string]bool{"price": true}
+	update, _ := json.Marshal(&MultiUpdate{Type: "multi_update", Region: "eu-west", Data: []*PriceUpdate{
+		{Symbol: "BTCUSDT", Price: 50000, Change: 100, Volume: 1000, EventTime: 1},
+	}})
+
+	tests := []struct {
+		name     string
+		payload  []byte
+		expected string
+	}{
+		{"multi_update", update, `{"data":[{"price":50000,"symbol":"BTCUSDT"}],"region":"eu-west","type":"multi_update"}`},
+		{"ndjson", toNDJSON(update), `{"price":50000,"symbol":"BTCUSDT","type":"price_update"}` + "\n"},
+		{"other message", []byte(`{"type":"heartbeat","timestamp":1}`), `{"type":"heartbeat","timestamp":1}`},
+	}
+
+	for _, tt := range tests {
+		if got := string(projectFields(tt.payload, fields)); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+
+	if got := projectFields(update, nil); string(got) != string(update) {
+		t.Errorf("Expected no projection to pass unchanged, got %s", got)
+	}
+}
+
+// TestBroadcastProjectionGroups verifies clients with the same fields share
+// one rendering while others receive the full update.
+func TestBroadcastProjectionGroups(t *testing.T) {
+	// Arrange
+	hub := NewHub()
+	subscribe := `{"type":"subscribe","fields":["changePercent","price"]}`
+	first := &Client{Send: make(chan []byte, 1)}
+	second := &Client{Send: make(chan []byte, 1)}
+	full := &Client{Send: make(chan []byte, 1)}
+	for _, client := range []*Client{first, second} {
+		if _, ok := client.handleCommand([]byte(subscribe), time.Now()).(*CommandAck); !ok {
+			t.Fatalf("Expected %s to be acknowledged", subscribe)
+		}
+	}
+	hub.clients[first] = true
+	hub.clients[second] = true
+	hub.clients[full] = true
+
+	update, _ := json.Marshal(&MultiUpdate{Type: "multi_update", Data: []*PriceUpdate{
+		{Symbol: "BTCUSDT", Price: 50000, ChangePercent: 0.2, Volume: 1000},
+	}})
+
+	// Act
+	hub.broadcastMessage(update)
+
+	// Assert
+	projected, other := <-first.Send, <-second.Send
+	if &projected[0] != &other[0] {
+		t.Error("Expected clients with the same fields to share one rendering")
+	}
+	if strings.Contains(string(projected), "volume") || !strings.Contains(string(projected), `"changePercent":0.2`) {
+		t.Errorf("Unexpected projected update %s", projected)
+	}
+	if received := <-full.Send; string(received) != string(update) {
+		t.Errorf("Expected the full update, got %s", received)
+	}
+}
+
+// TestCoalescedUpdatesProjected verifies throttled clients receive only
+// their fields in coalesced updates.
+func TestCoalescedUpdatesProjected(t *testing.T) {
+	client := &Client{}
+	client.handleCommand([]byte(`{"type":"subscribe","fields":["price"]}`), time.Now())
+
+	prices := newCoalescer(0, NumbersFloat)
+	update, _ := json.Marshal(&MultiUpdate{Type: "multi_update", Data: []*PriceUpdate{{Symbol: "BTCUSDT", Price: 50000}}})
+	prices.add(projectFields(update, client.fields))
+	flushed, _ := prices.flush(time.Now())
+
+	expected := `{"data":[{"price":50000,"symbol":"BTCUSDT"}],"type":"multi_update"}`
+	if got := string(client.project(flushed)); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
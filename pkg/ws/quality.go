@@ -0,0 +1,97 @@
+package ws
+
+import (
+	"encoding/json"
+	"log"
+)
+
+const (
+	// DataQualityWindow is the number of events per symbol over which the
+	// parse failure rate is evaluated
+	DataQualityWindow = 100
+
+	// DefaultDataQualityThreshold is the parse failure rate above which a
+	// data_quality warning is broadcast
+	DefaultDataQualityThreshold = 0.05
+)
+
+// DataQualityWarning is broadcast when a symbol's parse failure rate
+// exceeds the configured threshold within a window of events.
+type DataQualityWarning struct {
+	Type        string  `json:"type"`        // Always "data_quality"
+	Symbol      string  `json:"symbol"`      // Affected trading symbol
+	FailureRate float64 `json:"failureRate"` // Parse failures / events in the window
+	Failures    int     `json:"failures"`    // Parse failures in the window
+	Events      int     `json:"events"`      // Events in the window
+}
+
+// WithDataQualityThreshold sets the parse failure rate (0-1) above which a
+// data_quality warning is broadcast for a symbol.
+func WithDataQualityThreshold(rate float64) IngestorOption {
+	return func(i *Ingestor) {
+		i.dataQualityThreshold = rate
+	}
+}
+
+// recordEvent counts an incoming event for the symbol and, once a window
+// of events is complete, warns clients if too many of them were invalid.
+func (i *Ingestor) recordEvent(name string, parseErr error) {
+	if parseErr != nil {
+		i.metrics.parseFailures.Inc()
+		log.Printf("⚠ Skipping invalid event: %v", parseErr)
+	}
+
+	i.mu.Lock()
+	symbol := i.findSymbol(name)
+	if symbol == nil {
+		i.mu.Unlock()
+		return
+	}
+
+	symbol.EventsReceived++
+	symbol.windowEvents++
+	if parseErr != nil {
+		symbol.ParseFailures++
+		symbol.windowFailures++
+	}
+
+	if symbol.windowEvents < DataQualityWindow {
+		i.mu.Unlock()
+		return
+	}
+
+	warning := &DataQualityWarning{
+		Type:        "data_quality",
+		Symbol:      symbol.Name,
+		FailureRate: float64(symbol.windowFailures) / float64(symbol.windowEvents),
+		Failures:    symbol.windowFailures,
+		Events:      symbol.windowEvents,
+	}
+	symbol.windowEvents = 0
+	symbol.windowFailures = 0
+	i.mu.Unlock()
+
+	if warning.FailureRate > i.dataQualityThreshold {
+		i.reportDataQuality(warning)
+	}
+}
+
+// reportDataQuality broadcasts a data_quality warning to clients.
+func (i *Ingestor) reportDataQuality(warning *DataQualityWarning) {
+	i.metrics.dataQualityWarnings.Inc()
+	log.Printf("⚠ Data quality warning for %s: %d of %d events failed to parse",
+		warning.Symbol, warning.Failures, warning.Events)
+
+	// Low priority, dropped while the Hub signals backpressure
+	if !i.audience.IsActiveSource(i.name) || i.underBackpressure.Load() {
+		return
+	}
+
+	jsonData, err := json.Marshal(warning)
+	if err != nil {
+		log.Printf("Error marshaling data quality warning: %v", err)
+		return
+	}
+
+	i.publish("", jsonData)
+}
@@ -0,0 +1,337 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sync"
+	"time"
+
+	"macro-analyst/pkg/clock"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// BinanceRawStreamURL is the base URL of Binance raw streams
+	BinanceRawStreamURL = "wss://stream.binance.com:9443/ws/"
+
+	// DefaultMaxRawStreamsPerUser limits concurrent raw streams per user
+	DefaultMaxRawStreamsPerUser = 5
+
+	// RawSubscriberBufferSize is the buffer of each raw stream subscriber;
+	// messages are dropped for subscribers that fall further behind
+	RawSubscriberBufferSize = 64
+
+	// RawStreamDialTimeout bounds connecting to an upstream stream
+	RawStreamDialTimeout = 10 * time.Second
+)
+
+var (
+	// ErrInvalidRawStream is returned for malformed stream names.
+	ErrInvalidRawStream = errors.New("invalid stream name")
+
+	// ErrRawStreamLimit is returned when a user has too many open streams.
+	ErrRawStreamLimit = errors.New("raw stream limit reached")
+
+	// rawStreamPattern matches Binance stream names such as "btcusdt@trade"
+	// or "ethusdt@depth5@100ms"
+	rawStreamPattern = regexp.MustCompile(`^[a-z0-9_]+(@[a-z0-9_]+)*$`)
+)
+
+// RawConn is an upstream raw stream connection.
+type RawConn interface {
+	ReadMessage() (messageType int, data []byte, err error)
+	Close() error
+}
+
+// RawDialer opens an upstream connection for a stream.
+type RawDialer func(ctx context.Context, stream string) (RawConn, error)
+
+// RawProxy relays raw Binance streams to clients, sharing one upstream
+// connection per stream among all subscribers.
+type RawProxy struct {
+	dial              RawDialer
+	maxStreamsPerUser int
+	region            string
+	clock             clock.Clock
+
+	mu          sync.Mutex
+	upstreams   map[string]*rawUpstream
+	userStreams map[string]int // Open subscriptions per user
+}
+
+// rawUpstream is a shared upstream connection and its subscribers.
+type rawUpstream struct {
+	stream      string
+	conn        RawConn
+	subscribers map[*RawSubscription]bool
+}
+
+// RawSubscription delivers the messages of one raw stream to one client.
+type RawSubscription struct {
+	// C receives upstream messages. It is closed when the upstream
+	// connection ends or the subscription is closed.
+	C <-chan []byte
+
+	send     chan []byte
+	proxy    *RawProxy
+	upstream *rawUpstream
+	user     string
+	once     sync.Once
+}
+
+// RawProxyOption is a functional option for configuring the RawProxy.
+type RawProxyOption func(*RawProxy)
+
+// WithMaxRawStreamsPerUser sets how many raw streams a user may open at once.
+func WithMaxRawStreamsPerUser(limit int) RawProxyOption {
+	return func(p *RawProxy) {
+		p.maxStreamsPerUser = limit
+	}
+}
+
+// WithRawDialer replaces the connection to Binance, e.g. in tests.
+func WithRawDialer(dial RawDialer) RawProxyOption {
+	return func(p *RawProxy) {
+		p.dial = dial
+	}
+}
+
+// WithRawRegion sets the deployment region named in drain reconnect hints.
+func WithRawRegion(region string) RawProxyOption {
+	return func(p *RawProxy) {
+		p.region = region
+	}
+}
+
+// WithRawClock sets the clock used for drain schedules. Tests inject a
+// clock.Fake.
+func WithRawClock(clk clock.Clock) RawProxyOption {
+	return func(p *RawProxy) {
+		p.clock = clk
+	}
+}
+
+// NewRawProxy creates a RawProxy connecting to Binance raw streams.
+func NewRawProxy(opts ...RawProxyOption) *RawProxy {
+	proxy := &RawProxy{
+		dial:              dialBinanceRawStream,
+		maxStreamsPerUser: DefaultMaxRawStreamsPerUser,
+		clock:             clock.Real,
+		upstreams:         make(map[string]*rawUpstream),
+		userStreams:       make(map[string]int),
+	}
+
+	for _, opt := range opts {
+		opt(proxy)
+	}
+
+	return proxy
+}
+
+// dialBinanceRawStream connects to a Binance raw stream.
+func dialBinanceRawStream(ctx context.Context, stream string) (RawConn, error) {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, BinanceRawStreamURL+stream, nil)
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Subscribe attaches the user to a raw stream, connecting upstream if no
+// other client is subscribed to it yet.
+func (p *RawProxy) Subscribe(user, stream string) (*RawSubscription, error) {
+	if !rawStreamPattern.MatchString(stream) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRawStream, stream)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.maxStreamsPerUser > 0 && p.userStreams[user] >= p.maxStreamsPerUser {
+		return nil, fmt.Errorf("%w: at most %d streams", ErrRawStreamLimit, p.maxStreamsPerUser)
+	}
+
+	upstream, exists := p.upstreams[stream]
+	if !exists {
+		// Dial without holding the lock so other streams keep flowing
+		p.mu.Unlock()
+		ctx, cancel := context.WithTimeout(context.Background(), RawStreamDialTimeout)
+		conn, err := p.dial(ctx, stream)
+		cancel()
+		p.mu.Lock()
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", stream, err)
+		}
+
+		if upstream, exists = p.upstreams[stream]; exists {
+			// Another client connected the stream in the meantime
+			conn.Close()
+		} else {
+			upstream = &rawUpstream{
+				stream:      stream,
+				conn:        conn,
+				subscribers: make(map[*RawSubscription]bool),
+			}
+			p.upstreams[stream] = upstream
+			go p.relay(upstream)
+			log.Printf("Opened raw upstream stream %s", stream)
+		}
+	}
+
+	send := make(chan []byte, RawSubscriberBufferSize)
+	sub := &RawSubscription{
+		C:        send,
+		send:     send,
+		proxy:    p,
+		upstream: upstream,
+		user:     user,
+	}
+	upstream.subscribers[sub] = true
+	p.userStreams[user]++
+
+	return sub, nil
+}
+
+// relay fans upstream messages out to subscribers until the connection ends.
+func (p *RawProxy) relay(upstream *rawUpstream) {
+	for {
+		_, message, err := upstream.conn.ReadMessage()
+		if err != nil {
+			p.closeUpstream(upstream, err)
+			return
+		}
+
+		p.mu.Lock()
+		for sub := range upstream.subscribers {
+			select {
+			case sub.send <- message:
+			default:
+				// Slow subscriber, drop the message rather than stall the stream
+			}
+		}
+		p.mu.Unlock()
+	}
+}
+
+// closeUpstream detaches every subscriber after the upstream connection ended.
+func (p *RawProxy) closeUpstream(upstream *rawUpstream, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.upstreams[upstream.stream] != upstream {
+		// Already closed after the last subscriber left
+		return
+	}
+
+	log.Printf("Raw upstream stream %s closed: %v", upstream.stream, err)
+	delete(p.upstreams, upstream.stream)
+	for sub := range upstream.subscribers {
+		p.detach(sub)
+	}
+}
+
+// detach removes a subscription and closes its channel. The caller must
+// hold p.mu.
+func (p *RawProxy) detach(sub *RawSubscription) {
+	delete(sub.upstream.subscribers, sub)
+	close(sub.send)
+
+	p.userStreams[sub.user]--
+	if p.userStreams[sub.user] <= 0 {
+		delete(p.userStreams, sub.user)
+	}
+}
+
+// Close ends the subscription, closing the upstream connection if it was
+// the last subscriber.
+func (s *RawSubscription) Close() {
+	s.once.Do(func() {
+		p := s.proxy
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		if !s.upstream.subscribers[s] {
+			// Already detached by an upstream failure
+			return
+		}
+		p.detach(s)
+
+		if len(s.upstream.subscribers) == 0 && p.upstreams[s.upstream.stream] == s.upstream {
+			delete(p.upstreams, s.upstream.stream)
+			s.upstream.conn.Close()
+			log.Printf("Closed raw upstream stream %s (no subscribers)", s.upstream.stream)
+		}
+	})
+}
+
+// UpstreamCount returns the number of open upstream connections.
+func (p *RawProxy) UpstreamCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.upstreams)
+}
+
+// Drain spreads reconnect hints to the raw stream subscribers over the
+// grace period, like Hub.Drain, then closes every upstream connection so
+// the remaining relays end. It returns the number of subscribers drained.
+func (p *RawProxy) Drain(reconnectTo string, grace time.Duration) (int, error) {
+	start := p.clock.Now()
+	hint, err := reconnectHint(reconnectTo, p.region, start.Add(grace))
+	if err != nil {
+		return 0, err
+	}
+
+	p.mu.Lock()
+	var subs []*RawSubscription
+	for _, upstream := range p.upstreams {
+		for sub := range upstream.subscribers {
+			subs = append(subs, sub)
+		}
+	}
+	p.mu.Unlock()
+
+	log.Printf("Draining %d raw stream subscribers over %s (reconnect to %q)", len(subs), grace, reconnectTo)
+	go p.runDrain(subs, hint, start, grace)
+
+	return len(subs), nil
+}
+
+// runDrain sends the hint to each subscriber at its slot in the grace
+// period, then closes the upstream connections.
+func (p *RawProxy) runDrain(subs []*RawSubscription, hint []byte, start time.Time, grace time.Duration) {
+	for idx, sub := range subs {
+		sleepUntil(p.clock, drainSlot(start, grace, idx, len(subs)))
+
+		p.mu.Lock()
+		if sub.upstream.subscribers[sub] {
+			select {
+			case sub.send <- hint:
+			default:
+				// Slow subscriber, it is closed with the others below
+			}
+		}
+		p.mu.Unlock()
+	}
+
+	sleepUntil(p.clock, start.Add(grace))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	remaining := 0
+	for stream, upstream := range p.upstreams {
+		delete(p.upstreams, stream)
+		upstream.conn.Close()
+		for sub := range upstream.subscribers {
+			remaining++
+			p.detach(sub)
+		}
+	}
+	log.Printf("Raw stream drain complete, closed %d remaining subscribers", remaining)
+}
@@ -0,0 +1,225 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"macro-analyst/pkg/clock"
+)
+
+// fakeRawConn is an upstream connection fed by the test.
+type fakeRawConn struct {
+	messages  chan []byte
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newFakeRawConn() *fakeRawConn {
+	return &fakeRawConn{
+		messages: make(chan []byte, 16),
+		closed:   make(chan struct{}),
+	}
+}
+
+func (c *fakeRawConn) ReadMessage() (int, []byte, error) {
+	select {
+	case message := <-c.messages:
+		return 1, message, nil
+	case <-c.closed:
+		return 0, nil, errors.New("connection closed")
+	}
+}
+
+func (c *fakeRawConn) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return nil
+}
+
+// newTestRawProxy creates a RawProxy whose dials are counted and recorded.
+func newTestRawProxy(opts ...RawProxyOption) (*RawProxy, map[string]*fakeRawConn, *int) {
+	conns := make(map[string]*fakeRawConn)
+	dials := 0
+	var mu sync.Mutex
+
+	dial := func(ctx context.Context, stream string) (RawConn, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		dials++
+		conn := newFakeRawConn()
+		conns[stream] = conn
+		return conn, nil
+	}
+
+	return NewRawProxy(append([]RawProxyOption{WithRawDialer(dial)}, opts...)...), conns, &dials
+}
+
+// TestRawProxySharesUpstream verifies subscribers of a stream share one
+// upstream connection and both receive its messages.
+func TestRawProxySharesUpstream(t *testing.T) {
+	proxy, conns, dials := newTestRawProxy()
+
+	first, err := proxy.Subscribe("alice", "btcusdt@trade")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	second, err := proxy.Subscribe("bob", "btcusdt@trade")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if *dials != 1 {
+		t.Errorf("Expected 1 upstream dial, got %d", *dials)
+	}
+
+	conns["btcusdt@trade"].messages <- []byte(`{"e":"trade"}`)
+
+	for name, sub := range map[string]*RawSubscription{"first": first, "second": second} {
+		select {
+		case msg := <-sub.C:
+			if string(msg) != `{"e":"trade"}` {
+				t.Errorf("%s: unexpected message %s", name, msg)
+			}
+		case <-time.After(100 * time.Millisecond):
+			t.Errorf("%s: timeout waiting for relayed message", name)
+		}
+	}
+}
+
+// TestRawProxyClosesIdleUpstream verifies the upstream connection closes
+// when its last subscriber leaves.
+func TestRawProxyClosesIdleUpstream(t *testing.T) {
+	proxy, conns, _ := newTestRawProxy()
+
+	first, _ := proxy.Subscribe("alice", "ethusdt@ticker")
+	second, _ := proxy.Subscribe("bob", "ethusdt@ticker")
+
+	first.Close()
+	if proxy.UpstreamCount() != 1 {
+		t.Error("Expected upstream to stay open while a subscriber remains")
+	}
+
+	second.Close()
+	if proxy.UpstreamCount() != 0 {
+		t.Error("Expected upstream to close after the last subscriber left")
+	}
+
+	select {
+	case <-conns["ethusdt@ticker"].closed:
+	default:
+		t.Error("Expected upstream connection to be closed")
+	}
+}
+
+// TestRawProxyUserLimit verifies the per-user stream limit.
+func TestRawProxyUserLimit(t *testing.T) {
+	proxy, _, _ := newTestRawProxy(WithMaxRawStreamsPerUser(2))
+
+	proxy.Subscribe("alice", "btcusdt@trade")
+	third, _ := proxy.Subscribe("alice", "ethusdt@trade")
+
+	if _, err := proxy.Subscribe("alice", "solusdt@trade"); !errors.Is(err, ErrRawStreamLimit) {
+		t.Errorf("Expected ErrRawStreamLimit, got %v", err)
+	}
+	if _, err := proxy.Subscribe("bob", "solusdt@trade"); err != nil {
+		t.Errorf("Expected other users to be unaffected, got %v", err)
+	}
+
+	third.Close()
+	if _, err := proxy.Subscribe("alice", "solusdt@trade"); err != nil {
+		t.Errorf("Expected a freed slot to be reusable, got %v", err)
+	}
+}
+
+// TestRawProxyUpstreamFailure verifies subscribers are closed when the
+// upstream connection ends.
+func TestRawProxyUpstreamFailure(t *testing.T) {
+	proxy, conns, _ := newTestRawProxy()
+
+	sub, _ := proxy.Subscribe("alice", "btcusdt@trade")
+	conns["btcusdt@trade"].Close()
+
+	select {
+	case _, ok := <-sub.C:
+		if ok {
+			t.Error("Expected subscription channel to be closed")
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Timeout waiting for subscription to close")
+	}
+
+	// Closing after the upstream failure must not panic
+	sub.Close()
+
+	if proxy.UpstreamCount() != 0 {
+		t.Error("Expected failed upstream to be removed")
+	}
+}
+
+// TestRawProxyDrain verifies draining hints every subscriber to reconnect
+// and closes the upstream connections when the grace period ends.
+func TestRawProxyDrain(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1000, 0))
+	proxy, conns, _ := newTestRawProxy(WithRawClock(clk), WithRawRegion("eu-west-1"))
+
+	sub, _ := proxy.Subscribe("alice", "btcusdt@trade")
+
+	count, err := proxy.Drain("wss://standby.example.com/ws/raw/btcusdt@trade", 10*time.Second)
+	if err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 drained subscriber, got %d", count)
+	}
+
+	select {
+	case msg := <-sub.C:
+		var hint ReconnectHint
+		if err := json.Unmarshal(msg, &hint); err != nil {
+			t.Fatalf("Failed to decode hint: %v", err)
+		}
+		if hint.Type != "reconnect_to" || hint.Region != "eu-west-1" {
+			t.Errorf("Unexpected hint %+v", hint)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Timeout waiting for the reconnect hint")
+	}
+
+	clk.BlockUntil(1)
+	clk.Advance(10 * time.Second)
+
+	select {
+	case _, ok := <-sub.C:
+		if ok {
+			t.Error("Expected subscription channel to be closed")
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Timeout waiting for subscription to close")
+	}
+	select {
+	case <-conns["btcusdt@trade"].closed:
+	default:
+		t.Error("Expected upstream connection to be closed")
+	}
+	if proxy.UpstreamCount() != 0 {
+		t.Error("Expected drained upstream to be removed")
+	}
+}
+
+// TestRawProxyInvalidStream verifies stream name validation.
+func TestRawProxyInvalidStream(t *testing.T) {
+	proxy, _, dials := newTestRawProxy()
+
+	for _, stream := range []string{"", "BTCUSDT@trade", "btcusdt/../x", "btcusdt@"} {
+		if _, err := proxy.Subscribe("alice", stream); !errors.Is(err, ErrInvalidRawStream) {
+			t.Errorf("%q: expected ErrInvalidRawStream, got %v", stream, err)
+		}
+	}
+
+	if *dials != 0 {
+		t.Errorf("Expected no dials for invalid streams, got %d", *dials)
+	}
+}
@@ -0,0 +1,124 @@
+package ws
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultRecentWindow is how far back the recent price history of each
+	// symbol reaches
+	DefaultRecentWindow = 15 * time.Minute
+
+	// DefaultRecentResolution is the spacing of recent price points
+	DefaultRecentResolution = time.Second
+)
+
+// RecentPrice is the last price of a symbol within one resolution step of
+// its recent history.
+type RecentPrice struct {
+	Timestamp int64   `json:"timestamp"` // Start of the step (Unix ms)
+	Price     float64 `json:"price"`
+}
+
+// recentHistory is a fixed-size ring of a symbol's recent prices, one per
+// resolution step with updates.
+type recentHistory struct {
+	points []RecentPrice
+	next   int // Index of the oldest point once the ring is full
+}
+
+// WithRecentHistory sets the window and resolution of the in-memory recent
+// price history of each symbol, e.g. 15 minutes at 1s resolution. The ring
+// holds window/resolution points per symbol; a zero window disables it.
+func WithRecentHistory(window, resolution time.Duration) IngestorOption {
+	return func(i *Ingestor) {
+		i.recentWindow = window
+		i.recentResolution = resolution
+	}
+}
+
+// recentSize returns the number of points kept per symbol.
+func (i *Ingestor) recentSize() int {
+	if i.recentWindow <= 0 || i.recentResolution <= 0 {
+		return 0
+	}
+	return int(i.recentWindow / i.recentResolution)
+}
+
+// recordRecent records a price of the named symbol at now, replacing the
+// last point if it falls into the same resolution step.
+func (i *Ingestor) recordRecent(name string, price float64, now time.Time) {
+	size := i.recentSize()
+	if size == 0 {
+		return
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	symbol := i.findSymbol(name)
+	if symbol == nil {
+		return
+	}
+
+	history := &symbol.recent
+	point := RecentPrice{Timestamp: now.Truncate(i.recentResolution).UnixMilli(), Price: price}
+	if last := history.last(); last != nil && last.Timestamp == point.Timestamp {
+		*last = point
+		return
+	}
+
+	if len(history.points) < size {
+		history.points = append(history.points, point)
+		return
+	}
+	history.points[history.next] = point
+	history.next = (history.next + 1) % size
+}
+
+// last returns the newest point, or nil if the history is empty.
+func (h *recentHistory) last() *RecentPrice {
+	if len(h.points) == 0 {
+		return nil
+	}
+	idx := h.next - 1
+	if idx < 0 {
+		idx = len(h.points) - 1
+	}
+	return &h.points[idx]
+}
+
+// Recent returns the recent prices of the named symbol within window of
+// now, oldest first. A window outside the configured one returns the whole
+// history. It returns ErrSymbolNotFound for symbols that are not tracked.
+func (i *Ingestor) Recent(name string, window time.Duration) ([]RecentPrice, error) {
+	if window <= 0 || window > i.recentWindow {
+		window = i.recentWindow
+	}
+	since := i.clock.Now().Add(-window).UnixMilli()
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	symbol := i.findSymbol(name)
+	if symbol == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSymbolNotFound, name)
+	}
+
+	history := symbol.recent
+	points := make([]RecentPrice, 0, len(history.points))
+	for _, segment := range [][]RecentPrice{history.points[history.next:], history.points[:history.next]} {
+		for _, point := range segment {
+			if point.Timestamp >= since {
+				points = append(points, point)
+			}
+		}
+	}
+	return points, nil
+}
+
+// RecentResolution returns the spacing of recent price points.
+func (i *Ingestor) RecentResolution() time.Duration {
+	return i.recentResolution
+}
@@ -0,0 +1,76 @@
+package ws
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"macro-analyst/pkg/clock"
+)
+
+// TestRecentHistory verifies prices are kept one per resolution step, the
+// ring evicts the oldest points and queries are limited to the window.
+func TestRecentHistory(t *testing.T) {
+	// Arrange
+	clk := clock.NewFake(time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC))
+	ingestor := NewIngestor(HubSink(NewHub()),
+		WithClock(clk),
+		WithSymbols([]string{"BTCUSDT"}),
+		WithRecentHistory(5*time.Second, time.Second),
+	)
+
+	// Act: two updates in the first second, then one per second for 6s
+	ingestor.recordRecent("BTCUSDT", 100, clk.Now())
+	ingestor.recordRecent("BTCUSDT", 101, clk.Now().Add(500*time.Millisecond))
+	for n := 1; n <= 6; n++ {
+		clk.Advance(time.Second)
+		ingestor.recordRecent("BTCUSDT", float64(101+n), clk.Now())
+	}
+
+	// Assert: the ring holds the last 5 steps, oldest first
+	points, err := ingestor.Recent("BTCUSDT", 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(points) != 5 || points[0].Price != 103 || points[4].Price != 107 {
+		t.Fatalf("Expected prices 103 to 107, got %+v", points)
+	}
+	if points[4].Timestamp != clk.Now().UnixMilli() {
+		t.Errorf("Expected the newest point at %d, got %d", clk.Now().UnixMilli(), points[4].Timestamp)
+	}
+
+	recent, _ := ingestor.Recent("BTCUSDT", 2*time.Second)
+	if len(recent) != 3 || recent[0].Price != 105 {
+		t.Errorf("Expected the last 3 points, got %+v", recent)
+	}
+}
+
+// TestRecentHistoryFirstStep verifies the last price of a step replaces
+// earlier ones.
+func TestRecentHistoryFirstStep(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC))
+	ingestor := NewIngestor(HubSink(NewHub()), WithClock(clk), WithSymbols([]string{"BTCUSDT"}))
+
+	ingestor.recordRecent("BTCUSDT", 100, clk.Now())
+	ingestor.recordRecent("BTCUSDT", 101, clk.Now().Add(900*time.Millisecond))
+
+	points, _ := ingestor.Recent("BTCUSDT", 0)
+	if len(points) != 1 || points[0].Price != 101 {
+		t.Errorf("Expected one point at 101, got %+v", points)
+	}
+}
+
+// TestRecentHistoryErrors verifies untracked symbols are reported and a
+// zero window disables the history.
+func TestRecentHistoryErrors(t *testing.T) {
+	ingestor := NewIngestor(HubSink(NewHub()), WithSymbols([]string{"BTCUSDT"}), WithRecentHistory(0, time.Second))
+
+	if _, err := ingestor.Recent("DOGEUSDT", 0); !errors.Is(err, ErrSymbolNotFound) {
+		t.Errorf("Expected ErrSymbolNotFound, got %v", err)
+	}
+
+	ingestor.recordRecent("BTCUSDT", 100, time.Now())
+	if points, _ := ingestor.Recent("BTCUSDT", 0); len(points) != 0 {
+		t.Errorf("Expected no history when disabled, got %+v", points)
+	}
+}
@@ -0,0 +1,116 @@
+package ws
+
+import (
+	"encoding/json"
+	"log"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	// DefaultReconnectBackoff is the wait before reconnecting after a failed
+	// connect or a dropped Binance stream, doubled per consecutive failure
+	DefaultReconnectBackoff = time.Second
+
+	// maxReconnectBackoff caps the wait between reconnect attempts
+	maxReconnectBackoff = time.Minute
+
+	// StreamStatusReconnecting is reported while waiting to reconnect
+	StreamStatusReconnecting = "reconnecting"
+
+	// StreamStatusConnected is reported once a reconnect succeeded
+	StreamStatusConnected = "connected"
+
+	// StreamStatusFailed is reported when the feed gave up reconnecting
+	StreamStatusFailed = "failed"
+)
+
+// StreamStatus is broadcast to clients while the active feed reconnects to
+// Binance, so they know prices are stale until it is back.
+type StreamStatus struct {
+	Type      string `json:"type"`                // Always "stream_status"
+	Feed      string `json:"feed"`                // Source name of the ingestor
+	Status    string `json:"status"`              // "reconnecting", "connected" or "failed"
+	Attempt   int    `json:"attempt,omitempty"`   // Consecutive reconnect attempt, from 1
+	RetryInMs int64  `json:"retryInMs,omitempty"` // Wait before the attempt
+	Region    string `json:"region,omitempty"`    // Deployment region of the server
+}
+
+// WithReconnectBackoff sets the wait before reconnecting to Binance after a
+// failed connect or a dropped stream, doubled per consecutive failure up to
+// a minute. A zero duration disables reconnecting: the stream loop ends on
+// the first failure.
+func WithReconnectBackoff(backoff time.Duration) IngestorOption {
+	return func(i *Ingestor) {
+		i.reconnectBackoff = backoff
+	}
+}
+
+// WithMaxReconnectAttempts sets how many consecutive reconnects are
+// attempted before the feed gives up. Zero, the default, retries forever.
+func WithMaxReconnectAttempts(attempts int) IngestorOption {
+	return func(i *Ingestor) {
+		i.maxReconnects = attempts
+	}
+}
+
+// waitToReconnect waits out the backoff after the given number of
+// consecutive failures, or until the symbols change, telling clients that
+// prices are stale meanwhile. It reports false if reconnecting is disabled,
+// the attempts are exhausted or the ingestor stopped.
+func (i *Ingestor) waitToReconnect(failures int) bool {
+	if i.reconnectBackoff <= 0 || i.ctx.Err() != nil {
+		return false
+	}
+	if i.maxReconnects > 0 && failures > i.maxReconnects {
+		log.Printf("⚠ Giving up on Binance after %d reconnect attempts", i.maxReconnects)
+		i.reportStreamStatus(StreamStatusFailed, i.maxReconnects, 0)
+		return false
+	}
+
+	delay := i.reconnectDelay(failures)
+	log.Printf("Reconnecting to Binance in %v (attempt %d)", delay.Round(time.Millisecond), failures)
+	i.metrics.binanceReconnects.Inc()
+	i.reportStreamStatus(StreamStatusReconnecting, failures, delay)
+
+	timer := i.clock.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-i.ctx.Done():
+		return false
+	case <-timer.C():
+		return true
+	case <-i.resubscribe:
+		return true
+	}
+}
+
+// reconnectDelay returns the exponential backoff after the given number of
+// consecutive failures, with jitter between half and all of it so servers
+// do not reconnect to Binance at once after an outage.
+func (i *Ingestor) reconnectDelay(failures int) time.Duration {
+	delay := min(i.reconnectBackoff<<min(failures-1, 10), maxReconnectBackoff)
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// reportStreamStatus broadcasts the connection status of the active feed.
+func (i *Ingestor) reportStreamStatus(status string, attempt int, retryIn time.Duration) {
+	if !i.audience.IsActiveSource(i.name) {
+		return
+	}
+
+	jsonData, err := json.Marshal(&StreamStatus{
+		Type:      "stream_status",
+		Feed:      i.name,
+		Status:    status,
+		Attempt:   attempt,
+		RetryInMs: retryIn.Milliseconds(),
+		Region:    i.audience.Region(),
+	})
+	if err != nil {
+		log.Printf("Error marshaling stream status: %v", err)
+		return
+	}
+
+	i.publish("", jsonData)
+}
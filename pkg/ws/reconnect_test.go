@@ -0,0 +1,138 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"macro-analyst/pkg/clock"
+	"macro-analyst/pkg/feedlog"
+)
+
+// readStreamStatus reads and decodes the next stream status from the hub
+// broadcast channel.
+func readStreamStatus(t *testing.T, hub *Hub) StreamStatus {
+	t.Helper()
+
+	select {
+	case queued := <-hub.broadcast:
+		var status StreamStatus
+		if err := json.Unmarshal(queued.data, &status); err != nil {
+			t.Fatalf("Failed to decode stream status: %v", err)
+		}
+		return status
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for stream status broadcast")
+		return StreamStatus{}
+	}
+}
+
+// TestRunStreamReconnectsAfterFailure verifies the stream loop retries a
+// failed connect after the backoff instead of ending, and tells clients
+// while it reconnects.
+func TestRunStreamReconnectsAfterFailure(t *testing.T) {
+	// Arrange
+	clk := clock.NewFake(time.Unix(0, 0))
+	history := feedlog.New()
+	hub := NewHub()
+	ingestor := NewIngestor(HubSink(hub), WithClock(clk), WithFeedHistory(history))
+
+	attempts := 0
+	serve := func(symbols []string) (chan struct{}, chan struct{}, error) {
+		if attempts++; attempts == 1 {
+			return nil, nil, errors.New("dial tcp: i/o timeout")
+		}
+		doneC, stopC := make(chan struct{}), make(chan struct{})
+		go func() {
+			<-stopC
+			close(doneC)
+		}()
+		return doneC, stopC, nil
+	}
+
+	// Act
+	finished := make(chan struct{})
+	go func() {
+		ingestor.runStream(serve, nil)
+		close(finished)
+	}()
+	reconnecting := readStreamStatus(t, hub)
+	clk.BlockUntil(1)
+	clk.Advance(DefaultReconnectBackoff)
+	connected := readStreamStatus(t, hub)
+	ingestor.Stop()
+	<-finished
+
+	// Assert
+	if reconnecting.Type != "stream_status" || reconnecting.Status != StreamStatusReconnecting || reconnecting.Attempt != 1 || reconnecting.Feed != DefaultSourceName {
+		t.Errorf("Expected a first reconnect attempt, got %+v", reconnecting)
+	}
+	if reconnecting.RetryInMs < DefaultReconnectBackoff.Milliseconds()/2 || reconnecting.RetryInMs > DefaultReconnectBackoff.Milliseconds() {
+		t.Errorf("Expected a retry within the backoff, got %dms", reconnecting.RetryInMs)
+	}
+	if connected.Status != StreamStatusConnected {
+		t.Errorf("Expected the stream to be reported connected, got %+v", connected)
+	}
+
+	events := history.Events(time.Time{}, "")
+	if len(events) != 3 || events[0].Type != feedlog.ConnectFailed || events[1].Type != feedlog.Connected || events[2].Type != feedlog.Disconnected {
+		t.Errorf("Expected a failed connect, a connect and a disconnect, got %+v", events)
+	}
+}
+
+// TestRunStreamGivesUpAfterMaxAttempts verifies the stream loop ends and
+// reports the failure once the reconnect attempts are exhausted.
+func TestRunStreamGivesUpAfterMaxAttempts(t *testing.T) {
+	clk := clock.NewFake(time.Unix(0, 0))
+	hub := NewHub()
+	ingestor := NewIngestor(HubSink(hub), WithClock(clk), WithMaxReconnectAttempts(1))
+
+	finished := make(chan struct{})
+	go func() {
+		ingestor.runStream(func(symbols []string) (chan struct{}, chan struct{}, error) {
+			return nil, nil, errors.New("dial tcp: no such host")
+		}, nil)
+		close(finished)
+	}()
+
+	if status := readStreamStatus(t, hub); status.Status != StreamStatusReconnecting {
+		t.Errorf("Expected a reconnect attempt, got %+v", status)
+	}
+	clk.BlockUntil(1)
+	clk.Advance(DefaultReconnectBackoff)
+	if status := readStreamStatus(t, hub); status.Status != StreamStatusFailed || status.Attempt != 1 {
+		t.Errorf("Expected the feed to give up after 1 attempt, got %+v", status)
+	}
+
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the stream loop to end")
+	}
+}
+
+// TestReconnectDelay verifies the backoff doubles per failure up to the cap,
+// with jitter between half and all of it.
+func TestReconnectDelay(t *testing.T) {
+	ingestor := NewIngestor(HubSink(NewHub()), WithReconnectBackoff(time.Second))
+
+	tests := []struct {
+		failures int
+		maxDelay time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{4, 8 * time.Second},
+		{10, maxReconnectBackoff},
+		{100, maxReconnectBackoff},
+	}
+
+	for _, tt := range tests {
+		for range 20 {
+			if delay := ingestor.reconnectDelay(tt.failures); delay < tt.maxDelay/2 || delay > tt.maxDelay {
+				t.Errorf("Failure %d: expected a delay between %v and %v, got %v", tt.failures, tt.maxDelay/2, tt.maxDelay, delay)
+			}
+		}
+	}
+}
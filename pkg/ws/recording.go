@@ -0,0 +1,131 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"macro-analyst/pkg/clock"
+)
+
+const (
+	// RecordingExt is the extension of recording files
+	RecordingExt = ".ndjson"
+
+	// recordingNameFormat names a recording file by the UTC time of its
+	// first frame, e.g. 20240220T143000Z.ndjson, so names sort by time
+	recordingNameFormat = "20060102T150405Z"
+)
+
+// RecordedFrame is one line of a recording: a frame an Ingestor published
+// and when.
+type RecordedFrame struct {
+	Time int64           `json:"time"` // Unix ms the frame was published
+	Data json.RawMessage `json:"data"`
+}
+
+// Recorder is a Sink appending frames with their time to NDJSON files in a
+// directory, for replaying them later with WithReplay. A new file is
+// started on each UTC day, named by the time of its first frame.
+type Recorder struct {
+	mu    sync.Mutex
+	dir   string
+	clock clock.Clock
+	file  *os.File
+	day   string // UTC day of file, YYYY-MM-DD
+}
+
+// NewRecorder returns a Recorder writing to dir, which is created with the
+// first frame.
+func NewRecorder(dir string) *Recorder {
+	return &Recorder{dir: dir, clock: clock.Real}
+}
+
+// Publish appends a JSON frame to the current recording file.
+func (r *Recorder) Publish(data []byte) error {
+	now := r.clock.Now().UTC()
+	line, err := json.Marshal(&RecordedFrame{Time: now.UnixMilli(), Data: data})
+	if err != nil {
+		return fmt.Errorf("failed to record frame: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if day := now.Format(time.DateOnly); r.file == nil || day != r.day {
+		if err := r.rotate(now); err != nil {
+			return err
+		}
+	}
+	_, err = r.file.Write(append(line, '\n'))
+	return err
+}
+
+// rotate closes the current file and starts one named after now. The
+// caller holds r.mu.
+func (r *Recorder) rotate(now time.Time) error {
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create recording directory: %w", err)
+	}
+	path := filepath.Join(r.dir, now.Format(recordingNameFormat)+RecordingExt)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open recording file: %w", err)
+	}
+
+	r.file, r.day = file, now.Format(time.DateOnly)
+	return nil
+}
+
+// Close closes the current recording file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// frameHeader is the part of a frame identifying its kind and topic.
+type frameHeader struct {
+	Type   string `json:"type"`
+	Symbol string `json:"symbol"`
+}
+
+// parseFrameHeader returns the type and symbol of a JSON frame.
+func parseFrameHeader(data []byte) frameHeader {
+	var header frameHeader
+	json.Unmarshal(data, &header)
+	return header
+}
+
+// frameTopic returns the topic a frame of an Ingestor is published on, or
+// "" for frames published to every client.
+func frameTopic(data []byte) string {
+	header := parseFrameHeader(data)
+	switch header.Type {
+	case "multi_update":
+		return TopicPrices
+	case "trade_stats":
+		return TopicTrades
+	case "mark_price", "open_interest":
+		return TopicFutures
+	case "candle":
+		return SymbolTopic(TopicCandles, header.Symbol)
+	case "orderbook":
+		return SymbolTopic(TopicOrderBook, header.Symbol)
+	}
+	return ""
+}
@@ -0,0 +1,72 @@
+package ws
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"macro-analyst/pkg/clock"
+)
+
+// TestRecorder verifies frames are recorded with their time, one file per
+// UTC day named by its first frame.
+func TestRecorder(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "recordings")
+	clk := clock.NewFake(time.Date(2024, 2, 20, 23, 59, 0, 0, time.UTC))
+	recorder := NewRecorder(dir)
+	recorder.clock = clk
+	defer recorder.Close()
+
+	for _, frame := range []string{`{"type":"multi_update","data":[]}`, `{"type":"candle", "symbol":"BTCUSDT"}`} {
+		if err := recorder.Publish([]byte(frame)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	clk.Advance(2 * time.Minute)
+	if err := recorder.Publish([]byte(`{"type":"trade_stats","symbol":"BTCUSDT"}`)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := recorder.Publish([]byte(`not json`)); err == nil {
+		t.Error("Expected an error for a frame that is not JSON")
+	}
+
+	first, err := os.ReadFile(filepath.Join(dir, "20240220T235900Z.ndjson"))
+	if err != nil {
+		t.Fatalf("Expected the first day's recording: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(first)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 frames on the first day, got %q", lines)
+	}
+	var record RecordedFrame
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatalf("Invalid line %s: %v", lines[1], err)
+	}
+	if record.Time != time.Date(2024, 2, 20, 23, 59, 0, 0, time.UTC).UnixMilli() || string(record.Data) != `{"type":"candle","symbol":"BTCUSDT"}` {
+		t.Errorf("Unexpected record %d %s", record.Time, record.Data)
+	}
+
+	if second, err := os.ReadFile(filepath.Join(dir, "20240221T000100Z.ndjson")); err != nil || strings.Count(string(second), "\n") != 1 {
+		t.Errorf("Expected the next day's frame in a new file, got %q, %v", second, err)
+	}
+}
+
+// TestFrameTopic tests the topics of recorded frames.
+func TestFrameTopic(t *testing.T) {
+	tests := map[string]string{
+		`{"type":"multi_update","data":[]}`:         TopicPrices,
+		`{"type":"trade_stats","symbol":"BTCUSDT"}`: TopicTrades,
+		`{"type":"mark_price","symbol":"BTCUSDT"}`:  TopicFutures,
+		`{"type":"candle","symbol":"BTCUSDT"}`:      "candles:BTCUSDT",
+		`{"type":"orderbook","symbol":"ETHUSDT"}`:   "orderbook:ETHUSDT",
+		`{"type":"heartbeat"}`:                      "",
+	}
+	for frame, expected := range tests {
+		if topic := frameTopic([]byte(frame)); topic != expected {
+			t.Errorf("frameTopic(%s) = %q, expected %q", frame, topic, expected)
+		}
+	}
+}
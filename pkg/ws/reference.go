@@ -0,0 +1,157 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ChangeReference is the price the change and changePercent of price
+// updates are measured from.
+type ChangeReference string
+
+const (
+	// ChangeReference24h measures from the price 24 hours ago, like the
+	// Binance ticker (default)
+	ChangeReference24h ChangeReference = "24h"
+
+	// ChangeReferenceMidnight measures from the open at 00:00 UTC
+	ChangeReferenceMidnight ChangeReference = "midnight"
+
+	// ChangeReferenceAnchor measures from the price at a configured time,
+	// e.g. the start of a week or an event
+	ChangeReferenceAnchor ChangeReference = "anchor"
+)
+
+// ChangeReferences are the references that may be configured.
+var ChangeReferences = []ChangeReference{ChangeReference24h, ChangeReferenceMidnight, ChangeReferenceAnchor}
+
+// ChangePercentDecimals is the precision of computed change percentages
+const ChangePercentDecimals = 4
+
+// ParseChangeReference validates a change reference.
+func ParseChangeReference(value string) (ChangeReference, error) {
+	reference := ChangeReference(value)
+	if !slices.Contains(ChangeReferences, reference) {
+		return "", fmt.Errorf("invalid change reference %q (expected one of %v)", value, ChangeReferences)
+	}
+	return reference, nil
+}
+
+// WithChangeReference sets the price changes are measured from. anchor is
+// the time of the anchor price, used with ChangeReferenceAnchor only.
+func WithChangeReference(reference ChangeReference, anchor time.Time) IngestorOption {
+	return func(i *Ingestor) {
+		i.changeReference = reference
+		i.changeAnchor = anchor
+	}
+}
+
+// AnchorFetcher returns the price of a symbol at the given time, as the
+// decimal string Binance quotes.
+type AnchorFetcher func(ctx context.Context, symbol string, at time.Time) (string, error)
+
+// WithAnchorFetcher replaces the Binance REST lookup of anchor prices.
+func WithAnchorFetcher(fetch AnchorFetcher) IngestorOption {
+	return func(i *Ingestor) {
+		i.fetchAnchor = fetch
+	}
+}
+
+// fetchBinanceAnchor reads the open of the minute kline starting at the
+// anchor time.
+func (i *Ingestor) fetchBinanceAnchor(ctx context.Context, symbol string, at time.Time) (string, error) {
+	klines, err := i.restClient().NewKlinesService().
+		Symbol(symbol).
+		Interval("1m").
+		StartTime(at.UnixMilli()).
+		Limit(1).
+		Do(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch anchor kline for %s: %w", symbol, err)
+	}
+	if len(klines) == 0 {
+		return "", fmt.Errorf("no kline at %s for %s", at.Format(time.RFC3339), symbol)
+	}
+	return klines[0].Open, nil
+}
+
+// RefreshAnchors loads the anchor price of every tracked symbol when
+// changes are measured from an anchor. Symbols whose lookup fails are
+// measured from the 24h open.
+func (i *Ingestor) RefreshAnchors(ctx context.Context) {
+	if i.changeReference != ChangeReferenceAnchor {
+		return
+	}
+
+	for _, name := range i.GetSymbols() {
+		price, err := i.fetchAnchor(ctx, name, i.changeAnchor)
+		if err == nil {
+			_, err = decimal.NewFromString(price)
+		}
+		if err != nil {
+			log.Printf("⚠ Failed to refresh anchor price: %v", err)
+			continue
+		}
+
+		i.mu.Lock()
+		if symbol := i.findSymbol(name); symbol != nil {
+			symbol.AnchorPrice = price
+		}
+		i.mu.Unlock()
+	}
+}
+
+// applyChangeReference measures the change of a price update from the
+// configured reference, given the last price and 24h price change Binance
+// sent as decimal strings.
+func (i *Ingestor) applyChangeReference(update *PriceUpdate, lastPrice, priceChange string) {
+	day := updateDay(update.EventTime, time.UnixMilli(update.ReceivedAt))
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	if symbol := i.findSymbol(update.Symbol); symbol != nil {
+		i.referenceChange(update, symbol, lastPrice, priceChange, day)
+	}
+}
+
+// referenceChange sets the change and changePercent of an update from the
+// symbol's reference price and records the reference used. It computes in
+// decimal, so the change of a price quoted as 0.3 from 0.1 is 0.2, not
+// 0.19999999999999998. The 24h open, the last price less the 24h change,
+// stands in while the configured reference price is unknown. Updates whose
+// prices fail to parse are left unchanged. The caller must hold i.mu.
+func (i *Ingestor) referenceChange(update *PriceUpdate, symbol *Symbol, lastPrice, priceChange, day string) {
+	last, err := decimal.NewFromString(lastPrice)
+	if err != nil {
+		return
+	}
+
+	var reference decimal.Decimal
+	used := ChangeReference24h
+	switch {
+	case i.changeReference == ChangeReferenceMidnight && symbol.DayOpenDate == day && symbol.DayOpen > 0:
+		reference, used = decimal.NewFromFloat(symbol.DayOpen), ChangeReferenceMidnight
+	case i.changeReference == ChangeReferenceAnchor && symbol.AnchorPrice != "":
+		reference, used = decimal.RequireFromString(symbol.AnchorPrice), ChangeReferenceAnchor
+	default:
+		change, err := decimal.NewFromString(priceChange)
+		if err != nil {
+			return
+		}
+		reference = last.Sub(change)
+	}
+	if !reference.IsPositive() {
+		return
+	}
+
+	change := last.Sub(reference)
+	update.Change = change.Round(MaxPriceDecimals).InexactFloat64()
+	update.ChangePercent = change.Div(reference).Shift(2).Round(ChangePercentDecimals).InexactFloat64()
+	update.ChangeReference = used
+}
@@ -0,0 +1,92 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestChangeReference verifies changes are measured in decimal from the
+// configured reference, falling back to the 24h open while it is unknown.
+func TestChangeReference(t *testing.T) {
+	eventTime := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC).UnixMilli()
+
+	tests := []struct {
+		name          string
+		reference     ChangeReference
+		symbol        Symbol
+		lastPrice     string
+		priceChange   string
+		change        float64
+		changePercent float64
+		used          ChangeReference
+	}{
+		{"24h", ChangeReference24h, Symbol{}, "0.3", "0.2", 0.2, 200, ChangeReference24h},
+		{"24h rounding", ChangeReference24h, Symbol{}, "3000.50", "-12.00", -12, -0.3983, ChangeReference24h},
+		{"midnight", ChangeReferenceMidnight, Symbol{DayOpen: 2800, DayOpenDate: "2024-01-15"},
+			"3000.50", "-12.00", 200.5, 7.1607, ChangeReferenceMidnight},
+		{"stale midnight", ChangeReferenceMidnight, Symbol{DayOpen: 2800, DayOpenDate: "2024-01-14"},
+			"3000.50", "-12.00", -12, -0.3983, ChangeReference24h},
+		{"anchor", ChangeReferenceAnchor, Symbol{AnchorPrice: "0.00001000"},
+			"0.00001234", "0", 0.00000234, 23.4, ChangeReferenceAnchor},
+		{"anchor unknown", ChangeReferenceAnchor, Symbol{}, "3000.50", "-12.00", -12, -0.3983, ChangeReference24h},
+	}
+
+	for _, tt := range tests {
+		ingestor := NewIngestor(HubSink(NewHub()), WithChangeReference(tt.reference, time.Time{}))
+		symbol := tt.symbol
+		update := &PriceUpdate{Symbol: "BTCUSDT", EventTime: eventTime}
+
+		ingestor.referenceChange(update, &symbol, tt.lastPrice, tt.priceChange, updateDay(eventTime, time.Time{}))
+
+		if update.Change != tt.change || update.ChangePercent != tt.changePercent || update.ChangeReference != tt.used {
+			t.Errorf("%s: expected %v (%v%%) from %s, got %v (%v%%) from %s", tt.name,
+				tt.change, tt.changePercent, tt.used, update.Change, update.ChangePercent, update.ChangeReference)
+		}
+	}
+}
+
+// TestRefreshAnchors verifies fetched anchor prices are used for changes,
+// and symbols whose lookup fails keep the 24h reference.
+func TestRefreshAnchors(t *testing.T) {
+	anchor := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	fetch := func(ctx context.Context, symbol string, at time.Time) (string, error) {
+		if symbol == "ETHUSDT" {
+			return "", errors.New("unavailable")
+		}
+		if !at.Equal(anchor) {
+			t.Errorf("Expected anchor %v, got %v", anchor, at)
+		}
+		return "40000.00", nil
+	}
+	ingestor := NewIngestor(HubSink(NewHub()),
+		WithSymbols([]string{"BTCUSDT", "ETHUSDT"}),
+		WithChangeReference(ChangeReferenceAnchor, anchor),
+		WithAnchorFetcher(fetch),
+	)
+
+	ingestor.RefreshAnchors(context.Background())
+
+	btc := &PriceUpdate{Symbol: "BTCUSDT", ReceivedAt: time.Now().UnixMilli()}
+	ingestor.applyChangeReference(btc, "41000.00", "100.00")
+	if btc.Change != 1000 || btc.ChangePercent != 2.5 || btc.ChangeReference != ChangeReferenceAnchor {
+		t.Errorf("Expected 2.5%% from the anchor, got %+v", btc)
+	}
+
+	eth := &PriceUpdate{Symbol: "ETHUSDT", ReceivedAt: time.Now().UnixMilli()}
+	ingestor.applyChangeReference(eth, "2100.00", "100.00")
+	if eth.Change != 100 || eth.ChangePercent != 5 || eth.ChangeReference != ChangeReference24h {
+		t.Errorf("Expected 5%% from the 24h open, got %+v", eth)
+	}
+}
+
+// TestParseChangeReference verifies configuration values are validated.
+func TestParseChangeReference(t *testing.T) {
+	if reference, err := ParseChangeReference("midnight"); err != nil || reference != ChangeReferenceMidnight {
+		t.Errorf("Expected midnight, got %q, %v", reference, err)
+	}
+	if _, err := ParseChangeReference("week"); err == nil {
+		t.Error("Expected an error for an invalid reference")
+	}
+}
@@ -0,0 +1,50 @@
+package ws
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// DefaultHeartbeatInterval is how often a heartbeat message is broadcast
+const DefaultHeartbeatInterval = 30 * time.Second
+
+// Heartbeat is broadcast periodically so clients can tell a quiet market
+// from a dead connection, and which region's replica they are connected to.
+type Heartbeat struct {
+	Type       string `json:"type"`             // Always "heartbeat"
+	Region     string `json:"region,omitempty"` // Deployment region of the server
+	ServerTime int64  `json:"serverTime"`       // Unix ms when the heartbeat was sent
+	Clients    int    `json:"clients"`          // Connected clients on this server
+}
+
+// WithRegion tags heartbeats, feed status messages and price updates with
+// the deployment region, for debugging which replica served a message in a
+// multi-region deployment. Topics are local to the Hub and are not
+// prefixed with it.
+func WithRegion(region string) HubOption {
+	return func(h *Hub) {
+		h.region = region
+	}
+}
+
+// Region returns the configured deployment region, or "" if unset.
+func (h *Hub) Region() string {
+	return h.region
+}
+
+// PublishHeartbeat broadcasts a heartbeat to all clients.
+func (h *Hub) PublishHeartbeat(now time.Time) {
+	jsonData, err := json.Marshal(&Heartbeat{
+		Type:       "heartbeat",
+		Region:     h.region,
+		ServerTime: now.UnixMilli(),
+		Clients:    h.GetClientCount(),
+	})
+	if err != nil {
+		log.Printf("Error marshaling heartbeat: %v", err)
+		return
+	}
+
+	h.Publish(jsonData)
+}
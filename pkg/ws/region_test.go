@@ -0,0 +1,69 @@
+package ws
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// TestPublishHeartbeat verifies heartbeats carry the region and server time.
+func TestPublishHeartbeat(t *testing.T) {
+	// Arrange
+	hub := NewHub(WithRegion("eu-west-1"))
+	now := time.Date(2024, 2, 20, 10, 23, 45, 0, time.UTC)
+
+	// Act
+	hub.PublishHeartbeat(now)
+
+	// Assert
+	queued := <-hub.broadcast
+	var heartbeat Heartbeat
+	if err := json.Unmarshal(queued.data, &heartbeat); err != nil {
+		t.Fatalf("Failed to decode heartbeat: %v", err)
+	}
+	if heartbeat.Type != "heartbeat" {
+		t.Errorf("Expected type heartbeat, got %s", heartbeat.Type)
+	}
+	if heartbeat.Region != "eu-west-1" {
+		t.Errorf("Expected region eu-west-1, got %s", heartbeat.Region)
+	}
+	if heartbeat.ServerTime != now.UnixMilli() {
+		t.Errorf("Expected server time %d, got %d", now.UnixMilli(), heartbeat.ServerTime)
+	}
+}
+
+// TestMultiUpdateRegion verifies price updates are tagged only when a region is set.
+func TestMultiUpdateRegion(t *testing.T) {
+	tests := []struct {
+		region   string
+		expected string
+	}{
+		{"us-east-1", "us-east-1"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		// Arrange
+		ingestor := NewIngestor(HubSink(NewHub(WithRegion(tt.region))))
+		update := &MultiUpdate{Type: "multi_update", Data: []*PriceUpdate{{Symbol: "BTCUSDT", Price: 1}}}
+
+		// Act
+		frames, err := ingestor.encodeFrames(update)
+		if err != nil {
+			t.Fatalf("Failed to encode frames: %v", err)
+		}
+
+		// Assert
+		var decoded map[string]any
+		if err := json.Unmarshal(frames[0], &decoded); err != nil {
+			t.Fatalf("Failed to decode frame: %v", err)
+		}
+		region, exists := decoded["region"]
+		if tt.expected == "" && exists {
+			t.Errorf("Expected no region field, got %v", region)
+		}
+		if tt.expected != "" && region != tt.expected {
+			t.Errorf("Expected region %s, got %v", tt.expected, region)
+		}
+	}
+}
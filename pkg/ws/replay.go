@@ -0,0 +1,206 @@
+package ws
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"macro-analyst/pkg/feedlog"
+)
+
+// DefaultReplayMaxGap caps the wait between replayed frames, so gaps in a
+// recording, such as restarts, do not stall the replay
+const DefaultReplayMaxGap = 10 * time.Second
+
+// causeReplayFinished is the disconnect cause once a replay has ended
+const causeReplayFinished = "replay finished"
+
+// replaySource is the recording an ingestor replays instead of streaming
+// from Binance.
+type replaySource struct {
+	path  string
+	speed float64
+	loop  bool
+}
+
+// WithReplay makes the ingestor re-broadcast a recording of a Recorder
+// instead of connecting to Binance, for UI development and demos. path is
+// a recording file or a directory, whose recordings are replayed in order.
+// speed scales the recorded pace, e.g. 10 for ten times as fast; with loop
+// the replay starts over at the end. Each ingestor replays the messages of
+// its mode: price updates, candles, order books or trade statistics.
+func WithReplay(path string, speed float64, loop bool) IngestorOption {
+	return func(i *Ingestor) {
+		if path == "" {
+			return
+		}
+		if speed <= 0 {
+			speed = 1
+		}
+		i.playback = &replaySource{path: path, speed: speed, loop: loop}
+	}
+}
+
+// ReplayMode reports whether the ingestor replays a recording.
+func (i *Ingestor) ReplayMode() bool {
+	_, ok := i.playback.(*replaySource)
+	return ok
+}
+
+// play re-broadcasts the recording until it ends, or until the ingestor
+// stops when looping.
+func (r *replaySource) play(i *Ingestor) {
+	files, err := recordingFiles(r.path)
+	if err != nil {
+		log.Printf("Failed to start replay: %v", err)
+		return
+	}
+
+	log.Printf("Replaying %d recordings from %s at %gx", len(files), r.path, r.speed)
+	i.recordConnected(feedlog.Connected, len(i.GetSymbols()))
+	for {
+		for _, file := range files {
+			if !r.replayFile(i, file) {
+				return
+			}
+		}
+		if !r.loop {
+			log.Printf("Replay of %s finished", r.path)
+			i.recordDisconnected(causeReplayFinished)
+			return
+		}
+		log.Printf("Replay of %s finished, starting over", r.path)
+	}
+}
+
+// recordingFiles returns the recording at path, or the recordings in the
+// directory at path in name order, which is the order they were recorded.
+func recordingFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(path, "*"+RecordingExt))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no %s recordings in %s", RecordingExt, path)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// replayFile re-broadcasts the frames of the ingestor's type in a recording
+// at the replay speed. It returns false once the ingestor stopped.
+func (r *replaySource) replayFile(i *Ingestor, path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Printf("Failed to open recording: %v", err)
+		return i.ctx.Err() == nil
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var previous int64
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			var record RecordedFrame
+			if err := json.Unmarshal(line, &record); err != nil {
+				log.Printf("Skipping invalid line of recording %s: %v", path, err)
+			} else if kind := parseFrameHeader(record.Data).Type; i.stream.replays(kind) {
+				if previous != 0 && !r.wait(i, time.Duration(record.Time-previous)*time.Millisecond) {
+					return false
+				}
+				previous = record.Time
+				i.replayFrame(kind, record.Data)
+			}
+		}
+		if readErr != nil {
+			return i.ctx.Err() == nil
+		}
+	}
+}
+
+// wait waits for the recorded gap between two frames at the replay speed,
+// capped at DefaultReplayMaxGap. It returns false once the ingestor
+// stopped.
+func (r *replaySource) wait(i *Ingestor, gap time.Duration) bool {
+	wait := min(time.Duration(float64(gap)/r.speed), DefaultReplayMaxGap)
+	if wait <= 0 {
+		return i.ctx.Err() == nil
+	}
+
+	timer := i.clock.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-i.ctx.Done():
+		return false
+	case <-timer.C():
+		return true
+	}
+}
+
+// replayFrame publishes a recorded frame while this is the active feed.
+// Replayed prices update the cached symbol data, recent history and alerts
+// like live ones.
+func (i *Ingestor) replayFrame(kind string, data []byte) {
+	now := i.clock.Now()
+	i.markEventReceived(now)
+
+	var update MultiUpdate
+	if kind == "multi_update" {
+		if err := json.Unmarshal(data, &update); err != nil {
+			log.Printf("Skipping invalid recorded update: %v", err)
+			return
+		}
+		i.cachePriceUpdates(update.Data, now)
+		for _, price := range update.Data {
+			i.recordRecent(price.Symbol, price.Price, now)
+			i.evaluateAlerts(price.Symbol, price.Price, now)
+		}
+	}
+
+	if !i.audience.IsActiveSource(i.name) {
+		return
+	}
+	if i.publish(frameTopic(data), data) && kind == "multi_update" {
+		i.recordBroadcast(update.Data)
+		i.refreshHubSnapshot()
+	}
+}
+
+// cachePriceUpdates updates the cached data of the symbols of replayed or
+// mock prices, tracking the symbols that are not tracked yet.
+func (i *Ingestor) cachePriceUpdates(prices []*PriceUpdate, now time.Time) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, price := range prices {
+		symbol := i.findSymbol(price.Symbol)
+		if symbol == nil {
+			symbol = &Symbol{Name: price.Symbol}
+			i.symbols = append(i.symbols, symbol)
+		}
+		symbol.LastPrice = strconv.FormatFloat(price.Price, 'f', -1, 64)
+		symbol.LastChange = strconv.FormatFloat(price.ChangePercent, 'f', -1, 64)
+		symbol.LastPriceChange = strconv.FormatFloat(price.Change, 'f', -1, 64)
+		symbol.LastVolume = strconv.FormatInt(price.Volume, 10)
+		symbol.LastUpdateAt = now
+		symbol.LastEventTime = price.EventTime
+		symbol.Restored = false
+		symbol.EventsReceived++
+	}
+}
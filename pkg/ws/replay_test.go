@@ -0,0 +1,85 @@
+package ws
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"macro-analyst/pkg/clock"
+)
+
+// TestReplay verifies a recording is re-broadcast at the replay speed with
+// long gaps capped, updating the cached prices, and that an ingestor only
+// replays the messages of its mode, skipping recorded status frames.
+func TestReplay(t *testing.T) {
+	dir := t.TempDir()
+	recording := `{"time":1000,"data":{"type":"multi_update","data":[{"symbol":"BTCUSDT","price":50000,"changePercent":1.5,"volume":10,"eventTime":900}]}}
+{"time":1500,"data":{"type":"candle","symbol":"BTCUSDT","interval":"1m"}}
+{"time":2000,"data":{"type":"stream_status","status":"reconnecting","attempt":1}}
+not a record
+{"time":3000,"data":{"type":"multi_update","data":[{"symbol":"ETHUSDT","price":3000}]}}
+{"time":63000,"data":{"type":"multi_update","data":[{"symbol":"BTCUSDT","price":51000}]}}
+`
+	if err := os.WriteFile(filepath.Join(dir, "20240220T000000Z.ndjson"), []byte(recording), 0o644); err != nil {
+		t.Fatalf("Failed to write recording: %v", err)
+	}
+
+	clk := clock.NewFake(time.Unix(0, 0))
+	hub := NewHub()
+	ingestor := NewIngestor(HubSink(hub), WithClock(clk), WithSymbols([]string{"BTCUSDT"}), WithReplay(dir, 2, false))
+	if !ingestor.ReplayMode() {
+		t.Fatal("Expected replay mode")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ingestor.playback.play(ingestor)
+		close(done)
+	}()
+
+	expectFrame := func(symbol string) {
+		t.Helper()
+		select {
+		case queued := <-hub.broadcast:
+			if queued.topic != TopicPrices || frameSymbols(t, queued.data)[0] != symbol {
+				t.Errorf("Expected a %s price update, got %s on %q", symbol, queued.data, queued.topic)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected a %s price update", symbol)
+		}
+	}
+
+	expectFrame("BTCUSDT")
+	clk.BlockUntil(1)
+	clk.Advance(time.Second) // 2s recorded at twice the speed
+	expectFrame("ETHUSDT")
+	clk.BlockUntil(1)
+	clk.Advance(DefaultReplayMaxGap) // 60s recorded, capped
+	expectFrame("BTCUSDT")
+	<-done
+
+	prices := ingestor.Prices()
+	if len(prices) != 2 || prices[0].Symbol != "BTCUSDT" || prices[0].Price != 51000 || prices[1].Symbol != "ETHUSDT" {
+		t.Errorf("Expected the replayed prices cached, got %+v", prices)
+	}
+	select {
+	case queued := <-hub.broadcast:
+		t.Errorf("Expected no other frame, got %s", queued.data)
+	default:
+	}
+}
+
+// frameSymbols returns the symbols of a multi_update frame.
+func frameSymbols(t *testing.T, data []byte) []string {
+	t.Helper()
+
+	var symbols []string
+	for symbol := range decodePrices(t, data) {
+		symbols = append(symbols, symbol)
+	}
+	if len(symbols) == 0 {
+		t.Fatalf("Expected prices in %s", data)
+	}
+	return symbols
+}
@@ -0,0 +1,132 @@
+package ws
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// filterSymbols restricts a broadcast message to the symbols a client
+// receives, e.g. the scope of a shared dashboard or its subscriptions. A
+// multi_update keeps only the price updates of included symbols, and a
+// data_quality warning, a candle, an order book, trade stats or futures data pass only for one of them; nil means
+// nothing is left to deliver. Other messages are not symbol-specific and pass unchanged.
+func filterSymbols(message []byte, includes func(symbol string) bool) []byte {
+	var envelope struct {
+		Type   string `json:"type"`
+		Symbol string `json:"symbol"`
+	}
+	if err := json.Unmarshal(message, &envelope); err != nil {
+		return message
+	}
+
+	switch envelope.Type {
+	case "data_quality", "candle", "orderbook", "trade_stats", "mark_price", "open_interest":
+		if !includes(envelope.Symbol) {
+			return nil
+		}
+	case "multi_update":
+		var update MultiUpdate
+		if err := json.Unmarshal(message, &update); err != nil {
+			return nil
+		}
+
+		data := update.Data[:0]
+		for _, priceUpdate := range update.Data {
+			if includes(priceUpdate.Symbol) {
+				data = append(data, priceUpdate)
+			}
+		}
+		if len(data) == 0 {
+			return nil
+		}
+		if len(data) == len(update.Data) {
+			return message
+		}
+
+		update.Data = data
+		filtered, err := json.Marshal(&update)
+		if err != nil {
+			return nil
+		}
+		return filtered
+	}
+
+	return message
+}
+
+// payloadFor renders a broadcast message for the client's symbol scope,
+// subscriptions, currency, encoding and fields. cache shares the renderings
+// of the message across the clients of one broadcast with the same
+// settings. It returns nil if the message is outside what the client
+// receives.
+func (c *Client) payloadFor(message []byte, cache *renderCache) []byte {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	// Throttled clients coalesce JSON price updates; write encodes them
+	encoding := c.Encoding
+	if (encoding == EncodingNDJSON || encoding == EncodingMsgPack) && (c.RefreshInterval > 0 || c.capped) {
+		encoding = EncodingJSON
+	}
+
+	converts := c.Currency != "" && c.Rates != nil
+	if c.Symbols == nil && c.subscribed == nil && len(c.excluded) == 0 && !converts {
+		return cache.render(message, encoding, c.fields, c.projection, c.Numbers)
+	}
+
+	// Filtered and converted messages are rendered once per scope and
+	// currency besides the encoding, projection and number format
+	var currency string
+	if converts {
+		currency = c.Currency
+	}
+	key := strings.Join([]string{"scope", c.scopeKey(), currency, string(encoding), c.projection, string(c.Numbers)}, "|")
+	return cache.renderOnce(key, func() []byte {
+		message := filterSymbols(message, c.includes)
+		if message == nil {
+			return nil
+		}
+		if converts {
+			message = c.Rates.convertMessage(message, c.Currency)
+		}
+		if encoding == EncodingNDJSON {
+			message = toNDJSON(message)
+		}
+		message = formatNumbers(projectFields(message, c.fields), c.Numbers)
+		if encoding == EncodingMsgPack {
+			message = toMsgPack(message)
+		}
+		return message
+	})
+}
+
+// scopeKey identifies the symbols the client receives: its scope,
+// subscriptions and exclusions. It is computed once until they change. The
+// caller holds c.mu.
+func (c *Client) scopeKey() string {
+	if scope := c.scope.Load(); scope != nil {
+		return *scope
+	}
+
+	set := func(symbols map[string]bool) string {
+		if symbols == nil {
+			return "*"
+		}
+		return strings.Join(sortedSymbols(symbols), ",")
+	}
+	scope := set(c.Symbols) + ";" + set(c.subscribed) + ";" + set(c.excluded)
+	c.scope.Store(&scope)
+	return scope
+}
+
+// includes reports whether the client receives updates of a symbol. The
+// caller holds c.mu.
+func (c *Client) includes(symbol string) bool {
+	if c.Symbols != nil && !c.Symbols[symbol] {
+		return false
+	}
+	if c.subscribed != nil {
+		return c.subscribed[symbol]
+	}
+	return !c.excluded[symbol]
+}
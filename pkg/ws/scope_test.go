@@ -0,0 +1,110 @@
+package ws
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestFilterSymbols verifies scoped clients only receive their symbols.
+func TestFilterSymbols(t *testing.T) {
+	symbols := func(symbol string) bool { return symbol == "BTCUSDT" }
+	update, _ := json.Marshal(&MultiUpdate{
+		Type:   "multi_update",
+		Data:   []*PriceUpdate{{Symbol: "BTCUSDT", Price: 50000}, {Symbol: "ETHUSDT", Price: 3000}},
+		Region: "eu-west",
+	})
+
+	var filtered MultiUpdate
+	if err := json.Unmarshal(filterSymbols(update, symbols), &filtered); err != nil {
+		t.Fatalf("Failed to decode filtered update: %v", err)
+	}
+	if len(filtered.Data) != 1 || filtered.Data[0].Symbol != "BTCUSDT" || filtered.Region != "eu-west" {
+		t.Errorf("Expected only BTCUSDT with the region kept, got %+v", filtered)
+	}
+
+	other, _ := json.Marshal(&MultiUpdate{Type: "multi_update", Data: []*PriceUpdate{{Symbol: "ETHUSDT"}}})
+	if message := filterSymbols(other, symbols); message != nil {
+		t.Errorf("Expected update without scoped symbols to be dropped, got %s", message)
+	}
+
+	warning, _ := json.Marshal(&DataQualityWarning{Type: "data_quality", Symbol: "ETHUSDT"})
+	if message := filterSymbols(warning, symbols); message != nil {
+		t.Errorf("Expected warning for another symbol to be dropped, got %s", message)
+	}
+
+	candle, _ := json.Marshal(&CandleUpdate{Type: "candle", Symbol: "ETHUSDT", Interval: "1m"})
+	if message := filterSymbols(candle, symbols); message != nil {
+		t.Errorf("Expected candle of another symbol to be dropped, got %s", message)
+	}
+
+	book, _ := json.Marshal(&OrderBookUpdate{Type: "orderbook", Symbol: "ETHUSDT"})
+	if message := filterSymbols(book, symbols); message != nil {
+		t.Errorf("Expected order book of another symbol to be dropped, got %s", message)
+	}
+
+	stats, _ := json.Marshal(&TradeStats{Type: "trade_stats", Symbol: "ETHUSDT"})
+	if message := filterSymbols(stats, symbols); message != nil {
+		t.Errorf("Expected trade stats of another symbol to be dropped, got %s", message)
+	}
+
+	heartbeat := []byte(`{"type":"heartbeat","timestamp":1}`)
+	if message := filterSymbols(heartbeat, symbols); string(message) != string(heartbeat) {
+		t.Errorf("Expected heartbeat to pass unchanged, got %s", message)
+	}
+}
+
+// TestBroadcastScopedClient verifies the Hub filters broadcasts for clients
+// restricted to a set of symbols.
+func TestBroadcastScopedClient(t *testing.T) {
+	hub := NewHub()
+	scoped := &Client{Send: make(chan []byte, 1), Symbols: map[string]bool{"BTCUSDT": true}}
+	hub.clients[scoped] = true
+
+	eth, _ := json.Marshal(&MultiUpdate{Type: "multi_update", Data: []*PriceUpdate{{Symbol: "ETHUSDT"}}})
+	hub.broadcastMessage(eth)
+	if len(scoped.Send) != 0 {
+		t.Fatalf("Expected no message for an unscoped symbol, got %s", <-scoped.Send)
+	}
+
+	btc, _ := json.Marshal(&MultiUpdate{Type: "multi_update", Data: []*PriceUpdate{{Symbol: "BTCUSDT"}}})
+	hub.broadcastMessage(btc)
+	if len(scoped.Send) != 1 {
+		t.Error("Expected the BTCUSDT update to be delivered")
+	}
+}
+
+// TestRenderScopedOncePerBroadcast verifies clients with the same symbol
+// scope and currency share one filtered rendering, while other scopes and
+// currencies are rendered on their own.
+func TestRenderScopedOncePerBroadcast(t *testing.T) {
+	rates, _ := NewFXRates([]string{"EURUSDT"})
+	rates.update("EURUSDT", "1.25")
+	message := []byte(`{"type":"multi_update","data":[{"symbol":"BTCUSDT","price":50000},{"symbol":"ETHUSDT","price":3000}]}`)
+	first := &Client{Symbols: map[string]bool{"BTCUSDT": true}}
+	second := &Client{Symbols: map[string]bool{"BTCUSDT": true}}
+	other := &Client{Symbols: map[string]bool{"ETHUSDT": true}}
+	converted := &Client{Symbols: map[string]bool{"BTCUSDT": true}, Currency: "EUR", Rates: rates}
+
+	var cache renderCache
+	payload := first.payloadFor(message, &cache)
+	if prices := decodePrices(t, payload); len(prices) != 1 || prices["BTCUSDT"] != 50000 {
+		t.Fatalf("Expected only BTCUSDT, got %s", payload)
+	}
+	if again := second.payloadFor(message, &cache); &again[0] != &payload[0] {
+		t.Error("Expected clients of the same scope to share the rendering")
+	}
+	if prices := decodePrices(t, other.payloadFor(message, &cache)); len(prices) != 1 || prices["ETHUSDT"] != 3000 {
+		t.Errorf("Expected only ETHUSDT for another scope, got %v", prices)
+	}
+	if prices := decodePrices(t, converted.payloadFor(message, &cache)); prices["BTCUSDT"] != 40000 {
+		t.Errorf("Expected the price in EUR for another currency, got %v", prices)
+	}
+
+	scope := second.scopeKey()
+	if err := second.applySubscription(Command{Type: CommandUnsubscribe, Symbols: []string{"BTCUSDT"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if second.scopeKey() == scope {
+		t.Error("Expected the scope to change with the subscription")
+	}
+}
@@ -0,0 +1,108 @@
+package ws
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// basisPointsSuffix marks a Threshold given in basis points, e.g. "5bps"
+const basisPointsSuffix = "bps"
+
+// Threshold is the minimum price move, since the price last queued for
+// clients, for a symbol to be included in the next multi_update. The zero
+// Threshold lets every move through.
+type Threshold struct {
+	Absolute    float64 // Move in quote currency, e.g. 0.5 USDT
+	BasisPoints float64 // Move relative to the last queued price, 1 bps = 0.01%
+}
+
+// ParseThreshold converts a configuration value to a Threshold: a plain
+// number is an absolute move, a number suffixed with "bps" a relative one.
+func ParseThreshold(value string) (Threshold, error) {
+	value = strings.TrimSpace(value)
+	number, relative := strings.CutSuffix(value, basisPointsSuffix)
+
+	move, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	if err != nil || move < 0 || math.IsInf(move, 0) {
+		return Threshold{}, fmt.Errorf("invalid significance threshold %q (expected e.g. \"0.5\" or \"5bps\")", value)
+	}
+
+	if relative {
+		return Threshold{BasisPoints: move}, nil
+	}
+	return Threshold{Absolute: move}, nil
+}
+
+// ParseSymbolThresholds converts SYMBOL=threshold items, e.g.
+// "BTCUSDT=5bps", to per-symbol thresholds.
+func ParseSymbolThresholds(items []string) (map[string]Threshold, error) {
+	thresholds := make(map[string]Threshold, len(items))
+	for _, item := range items {
+		symbol, value, found := strings.Cut(item, "=")
+		if !found || strings.TrimSpace(symbol) == "" {
+			return nil, fmt.Errorf("invalid symbol threshold %q (expected SYMBOL=threshold)", item)
+		}
+
+		threshold, err := ParseThreshold(value)
+		if err != nil {
+			return nil, err
+		}
+		thresholds[strings.ToUpper(strings.TrimSpace(symbol))] = threshold
+	}
+	return thresholds, nil
+}
+
+// Significant reports whether a move from last to price reaches the
+// threshold. Any move from an unknown (zero) last price is significant.
+func (t Threshold) Significant(last, price float64) bool {
+	if last == 0 {
+		return true
+	}
+
+	move := math.Abs(price - last)
+	if t.Absolute > 0 && move < t.Absolute {
+		return false
+	}
+	if t.BasisPoints > 0 && move/last*10000 < t.BasisPoints {
+		return false
+	}
+	return true
+}
+
+// WithSignificanceThreshold sets the minimum price move for a symbol to be
+// broadcast, globally and overridden per symbol.
+func WithSignificanceThreshold(global Threshold, perSymbol map[string]Threshold) IngestorOption {
+	return func(i *Ingestor) {
+		i.significance = global
+		i.symbolSignificance = perSymbol
+	}
+}
+
+// isSignificant reports whether an update moved its symbol's price enough
+// since the price last queued for broadcast, and if so records it as
+// queued. The first update of a symbol always passes. Held-back updates are
+// counted per symbol.
+func (i *Ingestor) isSignificant(update *PriceUpdate) bool {
+	threshold, exists := i.symbolSignificance[update.Symbol]
+	if !exists {
+		threshold = i.significance
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	symbol := i.findSymbol(update.Symbol)
+	if symbol == nil {
+		return true
+	}
+	if !threshold.Significant(symbol.LastQueuedPrice, update.Price) {
+		symbol.InsignificantSuppressed++
+		i.metrics.insignificantUpdates.Inc()
+		return false
+	}
+
+	symbol.LastQueuedPrice = update.Price
+	return true
+}
@@ -0,0 +1,104 @@
+package ws
+
+import "testing"
+
+// TestParseThreshold verifies absolute and basis point thresholds are parsed.
+func TestParseThreshold(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected Threshold
+		wantErr  bool
+	}{
+		{"0.5", Threshold{Absolute: 0.5}, false},
+		{"5bps", Threshold{BasisPoints: 5}, false},
+		{" 2.5 bps", Threshold{BasisPoints: 2.5}, false},
+		{"0", Threshold{}, false},
+		{"-1", Threshold{}, true},
+		{"bps", Threshold{}, true},
+		{"1%", Threshold{}, true},
+	}
+
+	for _, tt := range tests {
+		threshold, err := ParseThreshold(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: expected error %v, got %v", tt.value, tt.wantErr, err)
+		}
+		if err == nil && threshold != tt.expected {
+			t.Errorf("%q: expected %+v, got %+v", tt.value, tt.expected, threshold)
+		}
+	}
+}
+
+// TestParseSymbolThresholds verifies per-symbol thresholds are keyed by
+// upper-case symbol.
+func TestParseSymbolThresholds(t *testing.T) {
+	thresholds, err := ParseSymbolThresholds([]string{"btcusdt=10", "USDCUSDT=1bps"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if thresholds["BTCUSDT"].Absolute != 10 || thresholds["USDCUSDT"].BasisPoints != 1 {
+		t.Errorf("Unexpected thresholds %+v", thresholds)
+	}
+
+	if _, err := ParseSymbolThresholds([]string{"BTCUSDT"}); err == nil {
+		t.Error("Expected an error for an item without a threshold")
+	}
+}
+
+// TestThresholdSignificant verifies moves are compared to absolute and
+// relative thresholds.
+func TestThresholdSignificant(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold Threshold
+		last      float64
+		price     float64
+		expected  bool
+	}{
+		{"no threshold", Threshold{}, 100, 100.0001, true},
+		{"below absolute", Threshold{Absolute: 0.5}, 100, 100.4, false},
+		{"absolute reached downwards", Threshold{Absolute: 0.5}, 100, 99.5, true},
+		{"below basis points", Threshold{BasisPoints: 5}, 100, 100.04, false},
+		{"basis points reached", Threshold{BasisPoints: 5}, 100, 100.06, true},
+		{"unknown last price", Threshold{Absolute: 1000}, 0, 100, true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.threshold.Significant(tt.last, tt.price); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+// TestInsignificantUpdateIsHeldBack verifies small moves are not queued
+// while moves accumulating past the threshold are.
+func TestInsignificantUpdateIsHeldBack(t *testing.T) {
+	// Arrange
+	ingestor := NewIngestor(HubSink(NewHub()), WithSignificanceThreshold(
+		Threshold{Absolute: 1000},
+		map[string]Threshold{"BTCUSDT": {Absolute: 10}},
+	))
+	var pendingUpdate *MultiUpdate
+	handler := ingestor.createWebSocketHandler(&pendingUpdate)
+	handler(newStatEvent(1000, "50000.00"))
+	pendingUpdate = nil
+
+	// Act
+	handler(newStatEvent(2000, "50006.00"))
+	held := pendingUpdate
+	handler(newStatEvent(3000, "50012.00"))
+
+	// Assert
+	if held != nil {
+		t.Error("Expected a move below the symbol threshold to be held back")
+	}
+	if pendingUpdate == nil || pendingUpdate.Data[0].Price != 50012 {
+		t.Errorf("Expected the accumulated move to be queued, got %+v", pendingUpdate)
+	}
+	if stats := ingestor.Stats()[0]; stats.BelowThreshold != 1 {
+		t.Errorf("Expected 1 update below threshold, got %d", stats.BelowThreshold)
+	}
+	if price, _ := ingestor.GetCurrentPrice("BTCUSDT"); price != "50012.00" {
+		t.Errorf("Expected the current price to stay live, got %s", price)
+	}
+}
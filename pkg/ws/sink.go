@@ -0,0 +1,227 @@
+package ws
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrSinkFull is returned by a Hub sink when its broadcast channel is full.
+var ErrSinkFull = errors.New("broadcast channel full")
+
+// Sink receives the frames an Ingestor publishes: a Hub sink delivering
+// them to WebSocket clients, a message queue producer or a file recorder.
+type Sink interface {
+	Publish(data []byte) error
+}
+
+// SinkFunc adapts a function to a Sink.
+type SinkFunc func(data []byte) error
+
+// Publish calls f(data).
+func (f SinkFunc) Publish(data []byte) error {
+	return f(data)
+}
+
+// topicSink is implemented by sinks routing frames by topic, like a Hub
+// sink; other sinks receive the frames of every topic through Publish.
+type topicSink interface {
+	PublishTopic(topic string, data []byte) error
+}
+
+// publishTopic publishes a frame on a topic, "" for every client, to a
+// sink.
+func publishTopic(sink Sink, topic string, data []byte) error {
+	if routed, ok := sink.(topicSink); ok {
+		return routed.PublishTopic(topic, data)
+	}
+	return sink.Publish(data)
+}
+
+// HubSink returns a Sink publishing frames to the clients of a Hub
+// subscribed to their topics. Ingestors publishing to it, directly or
+// behind a FanOut, also follow the Hub's active feed and backpressure and
+// keep its snapshot and owners' alerts current.
+func HubSink(hub *Hub) Sink {
+	return hubSink{hub}
+}
+
+// hubSink is the Sink of a Hub, and the audience of the ingestors
+// publishing to it.
+type hubSink struct {
+	*Hub
+}
+
+// Publish publishes a frame on the topic of its kind.
+func (s hubSink) Publish(data []byte) error {
+	return s.PublishTopic(frameTopic(data), data)
+}
+
+// PublishTopic publishes a frame on a topic, or ErrSinkFull if the Hub's
+// broadcast channel is full.
+func (s hubSink) PublishTopic(topic string, data []byte) error {
+	if !s.Hub.PublishTopic(topic, data) {
+		return ErrSinkFull
+	}
+	return nil
+}
+
+// FanOut returns a Sink publishing each frame to every sink, even when some
+// fail, and returning their errors joined.
+func FanOut(sinks ...Sink) Sink {
+	return fanOut(sinks)
+}
+
+// fanOut is the Sink of FanOut.
+type fanOut []Sink
+
+// Publish publishes a frame to every sink.
+func (f fanOut) Publish(data []byte) error {
+	return f.each(func(sink Sink) error {
+		return sink.Publish(data)
+	})
+}
+
+// PublishTopic publishes a frame on a topic to every sink.
+func (f fanOut) PublishTopic(topic string, data []byte) error {
+	return f.each(func(sink Sink) error {
+		return publishTopic(sink, topic, data)
+	})
+}
+
+// each calls publish for every sink and returns their errors joined.
+func (f fanOut) each(publish func(Sink) error) error {
+	var errs []error
+	for _, sink := range f {
+		if err := publish(sink); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// audience is what an Ingestor learns of the clients its frames reach
+// through a Hub sink: whether its feed is active, the Hub's backpressure
+// and region, and where the snapshot and owners' alerts go.
+type audience interface {
+	IsActiveSource(name string) bool
+	acceptUpdates(source string, updates []*PriceUpdate) []*PriceUpdate
+	SubscribeBackpressure() <-chan Backpressure
+	UnsubscribeBackpressure(signals <-chan Backpressure)
+	SetSnapshot(snapshot ...[]byte)
+	SendToOwner(owner string, message []byte) int
+	GetClientCount() int
+	Region() string
+}
+
+// findAudience returns the audience of the first Hub sink of a sink,
+// looking behind fan-outs. It reports false if the sink has none.
+func findAudience(sink Sink) (audience, bool) {
+	switch sink := sink.(type) {
+	case hubSink:
+		return sink, true
+	case fanOut:
+		for _, member := range sink {
+			if found, ok := findAudience(member); ok {
+				return found, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// noAudience is the audience of an ingestor publishing to no Hub, e.g.
+// only recording: its feed is always active and no client is connected.
+type noAudience struct{}
+
+func (noAudience) IsActiveSource(string) bool { return true }
+
+func (noAudience) acceptUpdates(_ string, updates []*PriceUpdate) []*PriceUpdate {
+	return updates
+}
+
+func (noAudience) SubscribeBackpressure() <-chan Backpressure { return nil }
+
+func (noAudience) UnsubscribeBackpressure(<-chan Backpressure) {}
+
+func (noAudience) SetSnapshot(...[]byte) {}
+
+func (noAudience) SendToOwner(string, []byte) int { return 0 }
+
+func (noAudience) GetClientCount() int { return 0 }
+
+func (noAudience) Region() string { return "" }
+
+// WriterSink records frames to a writer, one per line. As frames are JSON
+// without newlines, a recording is a JSON Lines file.
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink returns a Sink recording frames to w.
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// OpenFileSink returns a Sink appending frames to the file at path, which
+// is created with its directory if needed. Close the sink when done.
+func OpenFileSink(path string) (*WriterSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create recording directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording file: %w", err)
+	}
+	return NewWriterSink(file), nil
+}
+
+// Publish writes a frame followed by a newline.
+func (s *WriterSink) Publish(data []byte) error {
+	line := make([]byte, 0, len(data)+1)
+	line = append(append(line, data...), '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.w.Write(line)
+	return err
+}
+
+// Close closes the writer if it is an io.Closer.
+func (s *WriterSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if closer, ok := s.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// WithSink adds a sink receiving every frame the ingestor publishes, next
+// to the sink it was created with. Several WithSink options feed several
+// sinks.
+func WithSink(sink Sink) IngestorOption {
+	return func(i *Ingestor) {
+		if sink != nil {
+			i.sink = FanOut(i.sink, sink)
+		}
+	}
+}
+
+// publish publishes a frame on a topic, "" for every client, to the
+// ingestor's sink. It reports whether a Hub behind the sink accepted the
+// frame; the other sinks receive it either way, so a recording is not
+// missing frames the clients were spared under load.
+func (i *Ingestor) publish(topic string, data []byte) bool {
+	err := publishTopic(i.sink, topic, data)
+	if err != nil && !errors.Is(err, ErrSinkFull) {
+		log.Printf("Error publishing to sink: %v", err)
+	}
+	return !errors.Is(err, ErrSinkFull)
+}
@@ -0,0 +1,88 @@
+package ws
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestIngestorSinks verifies the ingestor publishes frames to its sinks
+// next to the Hub, including frames the full Hub drops.
+func TestIngestorSinks(t *testing.T) {
+	hub := NewHub(WithBroadcastBuffer(1))
+	var recorded bytes.Buffer
+	failing := SinkFunc(func([]byte) error { return errors.New("producer unavailable") })
+	ingestor := NewIngestor(HubSink(hub), WithSink(failing), WithSink(NewWriterSink(&recorded)), WithSink(nil))
+
+	if !ingestor.publish(TopicPrices, []byte(`{"n":1}`)) {
+		t.Error("Expected the Hub to accept the first frame")
+	}
+	if ingestor.publish(TopicPrices, []byte(`{"n":2}`)) {
+		t.Error("Expected the full Hub to drop the second frame")
+	}
+
+	if recorded.String() != "{\"n\":1}\n{\"n\":2}\n" {
+		t.Errorf("Expected both frames recorded one per line, got %q", recorded.String())
+	}
+	if queued := <-hub.broadcast; queued.topic != TopicPrices || string(queued.data) != `{"n":1}` {
+		t.Errorf("Expected the first frame queued on the prices topic, got %+v", queued)
+	}
+}
+
+// TestFanOut verifies every sink receives a frame even when another fails,
+// and the failures are returned.
+func TestFanOut(t *testing.T) {
+	hub := NewHub(WithBroadcastBuffer(1))
+	var first, second bytes.Buffer
+	sink := FanOut(NewWriterSink(&first), HubSink(hub), NewWriterSink(&second))
+
+	if err := sink.Publish([]byte("a")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := sink.Publish([]byte("b")); !errors.Is(err, ErrSinkFull) {
+		t.Errorf("Expected ErrSinkFull from the full Hub, got %v", err)
+	}
+	if first.String() != "a\nb\n" || second.String() != "a\nb\n" {
+		t.Errorf("Expected every frame in both recordings, got %q and %q", first.String(), second.String())
+	}
+}
+
+// TestIngestorFindsHubBehindFanOut verifies an ingestor publishing to a
+// fan-out follows the Hub behind it, and sends its status frames to every
+// sink.
+func TestIngestorFindsHubBehindFanOut(t *testing.T) {
+	hub := NewHub()
+	var recorded bytes.Buffer
+	ingestor := NewIngestor(FanOut(NewWriterSink(&recorded), HubSink(hub)), WithSourceName("standby"))
+
+	hub.SetActiveSource("primary")
+	if ingestor.audience.IsActiveSource(ingestor.name) {
+		t.Error("Expected the standby feed to follow the Hub's active feed")
+	}
+
+	hub.SetActiveSource("standby")
+	ingestor.reportStreamStatus(StreamStatusReconnecting, 1, time.Second)
+	queued := <-hub.broadcast
+	if queued.topic != "" || !strings.Contains(string(queued.data), `"stream_status"`) {
+		t.Errorf("Expected the stream status queued for every client, got %+v", queued)
+	}
+	if recorded.String() != string(queued.data)+"\n" {
+		t.Errorf("Expected the stream status recorded, got %q", recorded.String())
+	}
+}
+
+// TestIngestorWithoutHub verifies an ingestor publishing to no Hub treats
+// its feed as active and publishes every frame to its sink.
+func TestIngestorWithoutHub(t *testing.T) {
+	var recorded bytes.Buffer
+	ingestor := NewIngestor(NewWriterSink(&recorded))
+
+	if !ingestor.audience.IsActiveSource(ingestor.name) || ingestor.audience.GetClientCount() != 0 {
+		t.Error("Expected the feed active without clients")
+	}
+	if !ingestor.publish(TopicPrices, []byte(`{"n":1}`)) || recorded.String() != "{\"n\":1}\n" {
+		t.Errorf("Expected the frame recorded, got %q", recorded.String())
+	}
+}
@@ -0,0 +1,129 @@
+package ws
+
+import (
+	"fmt"
+	"log"
+)
+
+// SlowClientPolicy decides what the Hub does with a broadcast for a client
+// whose send buffer is full, e.g. a phone on a poor mobile connection.
+type SlowClientPolicy string
+
+const (
+	// SlowClientDisconnect drops the client, which reconnects and starts
+	// again from the snapshot (default)
+	SlowClientDisconnect SlowClientPolicy = "disconnect"
+
+	// SlowClientDropOldest discards the oldest queued message to make room
+	SlowClientDropOldest SlowClientPolicy = "drop-oldest"
+
+	// SlowClientDropNewest discards the broadcast that does not fit
+	SlowClientDropNewest SlowClientPolicy = "drop-newest"
+
+	// SlowClientCoalesce holds back price updates, keeping the latest per
+	// symbol, and sends them as one multi_update once the buffer has room.
+	// Other messages that do not fit are discarded.
+	SlowClientCoalesce SlowClientPolicy = "coalesce"
+)
+
+// SlowClientPolicies lists the valid policies.
+var SlowClientPolicies = []SlowClientPolicy{
+	SlowClientDisconnect, SlowClientDropOldest, SlowClientDropNewest, SlowClientCoalesce,
+}
+
+// ParseSlowClientPolicy converts a configuration value to a SlowClientPolicy.
+func ParseSlowClientPolicy(value string) (SlowClientPolicy, error) {
+	for _, policy := range SlowClientPolicies {
+		if SlowClientPolicy(value) == policy {
+			return policy, nil
+		}
+	}
+	return "", fmt.Errorf("invalid slow client policy %q (expected one of %v)", value, SlowClientPolicies)
+}
+
+// WithSlowClientPolicy sets what happens to broadcasts for clients whose
+// send buffer is full.
+func WithSlowClientPolicy(policy SlowClientPolicy) HubOption {
+	return func(h *Hub) {
+		h.slowClientPolicy = policy
+	}
+}
+
+// deliver queues a broadcast for a client, applying the slow client policy
+// if its send buffer is full. It is called from the Run loop only, which
+// owns the clients' backlogs.
+func (h *Hub) deliver(client *Client, message []byte, cache *renderCache) {
+	// Once prices are held back, newer ones join them so they are never
+	// delivered out of order
+	if client.backlog != nil && len(client.backlog.pending) > 0 && client.backlog.add(message) {
+		h.flushBacklog(client)
+		return
+	}
+
+	payload := client.payloadFor(message, cache)
+	if payload == nil {
+		return
+	}
+
+	now := h.clock.Now()
+	if client.enqueue(payload, now) {
+		return
+	}
+
+	switch h.slowClientPolicy {
+	case SlowClientDropNewest:
+		h.recordClientDrop(client, DropSlowClient)
+
+	case SlowClientDropOldest:
+		client.discardOldest()
+		client.enqueue(payload, now)
+		h.recordClientDrop(client, DropSlowClient)
+
+	case SlowClientCoalesce:
+		if client.backlog == nil {
+			client.backlog = newCoalescer(0, "")
+		}
+		client.backlog.add(message)
+		h.recordClientDrop(client, DropSlowClient)
+
+	default:
+		h.recordClientDrop(client, DropSlowClient)
+
+		// Schedule for removal, which closes the send channel
+		go func(c *Client) {
+			h.unregister <- c
+		}(client)
+	}
+}
+
+// flushBacklogs sends the held back prices of the clients whose send buffer
+// has room again, so they reach them even when no broadcast follows a
+// burst. It is called from the Run loop only.
+func (h *Hub) flushBacklogs() {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for client := range h.clients {
+		if client.backlog != nil && len(client.backlog.pending) > 0 && len(client.Send) < cap(client.Send) {
+			h.flushBacklog(client)
+		}
+	}
+}
+
+// flushBacklog sends the held back prices of a client as one multi_update,
+// keeping them for the next broadcast if the send buffer is still full.
+func (h *Hub) flushBacklog(client *Client) {
+	message, err := client.backlog.flush(h.clock.Now())
+	if err != nil {
+		log.Printf("Error encoding held back prices: %v", err)
+		return
+	}
+
+	payload := client.payloadFor(message, &renderCache{})
+	if payload == nil {
+		return
+	}
+	if !client.enqueue(payload, h.clock.Now()) {
+		client.backlog.add(message)
+	}
+}
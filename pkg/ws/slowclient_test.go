@@ -0,0 +1,140 @@
+package ws
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// priceMessage returns a multi_update with one price per symbol.
+func priceMessage(t *testing.T, prices map[string]float64) []byte {
+	t.Helper()
+
+	update := &MultiUpdate{Type: "multi_update"}
+	for symbol, price := range prices {
+		update.Data = append(update.Data, &PriceUpdate{Symbol: symbol, Price: price})
+	}
+	message, err := json.Marshal(update)
+	if err != nil {
+		t.Fatalf("Failed to marshal update: %v", err)
+	}
+	return message
+}
+
+// decodePrices returns the prices of a multi_update by symbol.
+func decodePrices(t *testing.T, message []byte) map[string]float64 {
+	t.Helper()
+
+	var update MultiUpdate
+	if err := json.Unmarshal(message, &update); err != nil {
+		t.Fatalf("Failed to decode update %s: %v", message, err)
+	}
+	prices := make(map[string]float64)
+	for _, priceUpdate := range update.Data {
+		prices[priceUpdate.Symbol] = priceUpdate.Price
+	}
+	return prices
+}
+
+// TestSlowClientDrop verifies the drop policies keep the client and discard
+// the oldest or the newest message.
+func TestSlowClientDrop(t *testing.T) {
+	tests := []struct {
+		policy   SlowClientPolicy
+		expected []string
+	}{
+		{SlowClientDropOldest, []string{"second", "third"}},
+		{SlowClientDropNewest, []string{"first", "second"}},
+	}
+
+	for _, tt := range tests {
+		hub := NewHub(WithSlowClientPolicy(tt.policy))
+		client := &Client{Send: make(chan []byte, 2)}
+		hub.clients[client] = true
+
+		for _, message := range []string{"first", "second", "third"} {
+			hub.broadcastMessage([]byte(`{"type":"` + message + `"}`))
+		}
+
+		if !hub.clients[client] {
+			t.Errorf("%s: expected the client to stay registered", tt.policy)
+		}
+		for _, expected := range tt.expected {
+			if message := string(<-client.Send); message != `{"type":"`+expected+`"}` {
+				t.Errorf("%s: expected %s, got %s", tt.policy, expected, message)
+			}
+		}
+	}
+}
+
+// TestSlowClientDisconnect verifies slow clients are unregistered by default.
+func TestSlowClientDisconnect(t *testing.T) {
+	hub := NewHub()
+	client := &Client{Send: make(chan []byte, 1)}
+	hub.clients[client] = true
+
+	hub.broadcastMessage([]byte(`{"type":"first"}`))
+	hub.broadcastMessage([]byte(`{"type":"second"}`))
+
+	if removed := <-hub.unregister; removed != client {
+		t.Error("Expected the slow client to be unregistered")
+	}
+}
+
+// TestSlowClientCoalesce verifies held back prices keep the latest per
+// symbol and are sent in one message once the buffer has room.
+func TestSlowClientCoalesce(t *testing.T) {
+	hub := NewHub(WithSlowClientPolicy(SlowClientCoalesce))
+	client := &Client{Send: make(chan []byte, 1)}
+	hub.clients[client] = true
+
+	hub.broadcastMessage(priceMessage(t, map[string]float64{"BTCUSDT": 1}))
+	hub.broadcastMessage(priceMessage(t, map[string]float64{"BTCUSDT": 2, "ETHUSDT": 10}))
+	hub.broadcastMessage([]byte(`{"type":"heartbeat"}`))
+	hub.broadcastMessage(priceMessage(t, map[string]float64{"BTCUSDT": 3}))
+
+	if prices := decodePrices(t, <-client.Send); prices["BTCUSDT"] != 1 {
+		t.Errorf("Expected the first update to be delivered, got %v", prices)
+	}
+	if len(client.Send) != 0 {
+		t.Fatalf("Expected later messages to be held back, got %s", <-client.Send)
+	}
+
+	hub.broadcastMessage(priceMessage(t, map[string]float64{"ETHUSDT": 11}))
+	prices := decodePrices(t, <-client.Send)
+	if len(prices) != 2 || prices["BTCUSDT"] != 3 || prices["ETHUSDT"] != 11 {
+		t.Errorf("Expected the latest price per symbol, got %v", prices)
+	}
+	if !hub.clients[client] || len(client.backlog.pending) != 0 {
+		t.Error("Expected the client to stay registered with an empty backlog")
+	}
+}
+
+// TestSlowClientBacklogFlushed verifies held back prices are sent once the
+// send buffer has room, without waiting for another broadcast.
+func TestSlowClientBacklogFlushed(t *testing.T) {
+	hub := NewHub(WithSlowClientPolicy(SlowClientCoalesce))
+	client := &Client{Send: make(chan []byte, 1)}
+	hub.clients[client] = true
+
+	hub.broadcastMessage(priceMessage(t, map[string]float64{"BTCUSDT": 1}))
+	hub.broadcastMessage(priceMessage(t, map[string]float64{"BTCUSDT": 2}))
+	hub.flushBacklogs()
+	if prices := decodePrices(t, <-client.Send); prices["BTCUSDT"] != 1 {
+		t.Fatalf("Expected the first update to be delivered, got %v", prices)
+	}
+
+	hub.flushBacklogs()
+	if prices := decodePrices(t, <-client.Send); prices["BTCUSDT"] != 2 {
+		t.Errorf("Expected the held back price once the buffer had room, got %v", prices)
+	}
+}
+
+// TestParseSlowClientPolicy verifies configuration values are validated.
+func TestParseSlowClientPolicy(t *testing.T) {
+	if policy, err := ParseSlowClientPolicy("coalesce"); err != nil || policy != SlowClientCoalesce {
+		t.Errorf("Expected coalesce, got %q, %v", policy, err)
+	}
+	if _, err := ParseSlowClientPolicy("kick"); err == nil {
+		t.Error("Expected an error for an invalid policy")
+	}
+}
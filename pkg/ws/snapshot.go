@@ -0,0 +1,199 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	// SnapshotMaxAge is the oldest snapshot that is still restored on boot.
+	// Older data is more misleading than no data at all.
+	SnapshotMaxAge = time.Hour
+)
+
+// symbolSnapshot is the persisted form of a Symbol's last-known data.
+type symbolSnapshot struct {
+	Name            string    `json:"name"`
+	LastPrice       string    `json:"lastPrice"`
+	LastChange      string    `json:"lastChange"`
+	LastPriceChange string    `json:"lastPriceChange"`
+	LastVolume      string    `json:"lastVolume"`
+	LastUpdateAt    time.Time `json:"lastUpdateAt"`
+	LastEventTime   int64     `json:"lastEventTime"`
+}
+
+// ingestorSnapshot is the on-disk snapshot written on shutdown.
+type ingestorSnapshot struct {
+	SavedAt time.Time        `json:"savedAt"`
+	Symbols []symbolSnapshot `json:"symbols"`
+}
+
+// WithSnapshotFile sets the file used to persist last-known symbol data
+// across restarts. An empty path disables persistence.
+func WithSnapshotFile(path string) IngestorOption {
+	return func(i *Ingestor) {
+		i.snapshotFile = path
+	}
+}
+
+// SaveSnapshot writes the last-known data of all symbols to the snapshot
+// file. It is a no-op when no snapshot file is configured.
+func (i *Ingestor) SaveSnapshot() error {
+	if i.snapshotFile == "" {
+		return nil
+	}
+
+	snapshot := ingestorSnapshot{SavedAt: i.clock.Now()}
+
+	i.mu.RLock()
+	for _, symbol := range i.symbols {
+		if symbol.LastPrice == "" {
+			continue
+		}
+		snapshot.Symbols = append(snapshot.Symbols, symbolSnapshot{
+			Name:            symbol.Name,
+			LastPrice:       symbol.LastPrice,
+			LastChange:      symbol.LastChange,
+			LastPriceChange: symbol.LastPriceChange,
+			LastVolume:      symbol.LastVolume,
+			LastUpdateAt:    symbol.LastUpdateAt,
+			LastEventTime:   symbol.LastEventTime,
+		})
+	}
+	i.mu.RUnlock()
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(i.snapshotFile), 0o755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a partial snapshot
+	tmpFile := i.snapshotFile + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmpFile, i.snapshotFile); err != nil {
+		return fmt.Errorf("failed to replace snapshot: %w", err)
+	}
+
+	log.Printf("Saved snapshot of %d symbols to %s", len(snapshot.Symbols), i.snapshotFile)
+	return nil
+}
+
+// RestoreSnapshot loads last-known symbol data from the snapshot file and
+// publishes it to the Hub, flagged as restored until live data resumes.
+// A missing or outdated snapshot is not an error.
+func (i *Ingestor) RestoreSnapshot() error {
+	if i.snapshotFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(i.snapshotFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snapshot ingestorSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+
+	if age := i.clock.Now().Sub(snapshot.SavedAt); age > SnapshotMaxAge {
+		log.Printf("Skipping snapshot from %s (older than %s)", age.Round(time.Second), SnapshotMaxAge)
+		return nil
+	}
+
+	restored := 0
+	i.mu.Lock()
+	for _, saved := range snapshot.Symbols {
+		symbol := i.findSymbol(saved.Name)
+		if symbol == nil || symbol.LastPrice != "" {
+			// Not tracked anymore, or live data already arrived
+			continue
+		}
+		symbol.LastPrice = saved.LastPrice
+		symbol.LastChange = saved.LastChange
+		symbol.LastPriceChange = saved.LastPriceChange
+		symbol.LastVolume = saved.LastVolume
+		symbol.LastUpdateAt = saved.LastUpdateAt
+		symbol.LastEventTime = saved.LastEventTime
+		symbol.Restored = true
+		restored++
+	}
+	i.mu.Unlock()
+
+	log.Printf("Restored snapshot of %d symbols from %s", restored, i.snapshotFile)
+	i.refreshHubSnapshot()
+	return nil
+}
+
+// refreshHubSnapshot publishes the full last-known state of all symbols
+// to the Hub so newly connecting clients can render immediately.
+func (i *Ingestor) refreshHubSnapshot() {
+	snapshot := &MultiUpdate{Type: "multi_update", Data: i.Prices()}
+	if len(snapshot.Data) == 0 || !i.audience.IsActiveSource(i.name) {
+		return
+	}
+
+	frames, err := i.encodeFrames(snapshot)
+	if err != nil {
+		log.Printf("Error marshaling snapshot: %v", err)
+		return
+	}
+
+	i.audience.SetSnapshot(frames...)
+}
+
+// Prices returns the cached last-known data of all symbols with a price, in
+// the PriceUpdate format streamed to clients.
+func (i *Ingestor) Prices() []*PriceUpdate {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	prices := []*PriceUpdate{}
+	for _, symbol := range i.symbols {
+		if symbol.LastPrice == "" {
+			continue
+		}
+		prices = append(prices, i.symbolToPriceUpdate(symbol))
+	}
+	return prices
+}
+
+// symbolToPriceUpdate converts cached symbol data to our PriceUpdate format.
+func (i *Ingestor) symbolToPriceUpdate(symbol *Symbol) *PriceUpdate {
+	price, _ := strconv.ParseFloat(symbol.LastPrice, 64)
+	change, _ := strconv.ParseFloat(symbol.LastPriceChange, 64)
+	changePercent, _ := strconv.ParseFloat(symbol.LastChange, 64)
+	volume, _ := strconv.ParseFloat(symbol.LastVolume, 64)
+
+	day := updateDay(symbol.LastEventTime, symbol.LastUpdateAt)
+	update := &PriceUpdate{
+		Symbol:        symbol.Name,
+		Price:         price,
+		Change:        change,
+		ChangePercent: changePercent,
+		Volume:        int64(volume),
+		Timestamp:     i.formatTimestamp(symbol.LastEventTime, symbol.LastUpdateAt),
+		EventTime:     symbol.LastEventTime,
+		ReceivedAt:    symbol.LastUpdateAt.UnixMilli(),
+		Restored:      symbol.Restored,
+
+		SinceMidnightChangePercent: sinceMidnightChange(symbol, price, day),
+	}
+	i.referenceChange(update, symbol, symbol.LastPrice, symbol.LastPriceChange, day)
+	return update
+}
@@ -0,0 +1,140 @@
+package ws
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+)
+
+// TestSaveAndRestoreSnapshot verifies symbol data survives a restart.
+func TestSaveAndRestoreSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+
+	ingestor := NewIngestor(HubSink(NewHub()), WithSnapshotFile(path))
+	ingestor.updateSymbolData(&binance.WsMarketStatEvent{
+		Symbol:             "BTCUSDT",
+		LastPrice:          "50000.00",
+		PriceChange:        "100.00",
+		PriceChangePercent: "0.20",
+		BaseVolume:         "1000",
+	})
+
+	if err := ingestor.SaveSnapshot(); err != nil {
+		t.Fatalf("SaveSnapshot failed: %v", err)
+	}
+
+	// Simulate a restart with a fresh ingestor and hub
+	hub := NewHub()
+	restarted := NewIngestor(HubSink(hub), WithSnapshotFile(path))
+	if err := restarted.RestoreSnapshot(); err != nil {
+		t.Fatalf("RestoreSnapshot failed: %v", err)
+	}
+
+	price, err := restarted.GetCurrentPrice("BTCUSDT")
+	if err != nil {
+		t.Fatalf("Expected restored price, got error: %v", err)
+	}
+	if price != "50000.00" {
+		t.Errorf("Expected restored price 50000.00, got %s", price)
+	}
+
+	symbol := restarted.findSymbol("BTCUSDT")
+	if !symbol.Restored {
+		t.Error("Restored symbol should be flagged as restored")
+	}
+
+	// The hub snapshot should carry the restored flag for new clients
+	var snapshot MultiUpdate
+	if err := json.Unmarshal(hub.Snapshot()[0], &snapshot); err != nil {
+		t.Fatalf("Failed to decode hub snapshot: %v", err)
+	}
+	if len(snapshot.Data) != 1 {
+		t.Fatalf("Expected 1 symbol in hub snapshot, got %d", len(snapshot.Data))
+	}
+	if !snapshot.Data[0].Restored || snapshot.Data[0].Price != 50000 || snapshot.Data[0].Change != 100 {
+		t.Errorf("Unexpected snapshot entry: %+v", snapshot.Data[0])
+	}
+
+	// Live data clears the restored flag
+	restarted.updateSymbolData(&binance.WsMarketStatEvent{Symbol: "BTCUSDT", LastPrice: "50100.00"})
+	if symbol.Restored {
+		t.Error("Live update should clear the restored flag")
+	}
+}
+
+// TestRestoreSnapshotMissingFile verifies a missing snapshot is not an error.
+func TestRestoreSnapshotMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.json")
+	ingestor := NewIngestor(HubSink(NewHub()), WithSnapshotFile(path))
+
+	if err := ingestor.RestoreSnapshot(); err != nil {
+		t.Errorf("Expected no error for missing snapshot, got %v", err)
+	}
+}
+
+// TestRestoreSnapshotTooOld verifies outdated snapshots are ignored.
+func TestRestoreSnapshotTooOld(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	data, _ := json.Marshal(ingestorSnapshot{
+		SavedAt: time.Now().Add(-2 * SnapshotMaxAge),
+		Symbols: []symbolSnapshot{{Name: "BTCUSDT", LastPrice: "1.00"}},
+	})
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("Failed to write snapshot: %v", err)
+	}
+
+	hub := NewHub()
+	ingestor := NewIngestor(HubSink(hub), WithSnapshotFile(path))
+	if err := ingestor.RestoreSnapshot(); err != nil {
+		t.Fatalf("RestoreSnapshot failed: %v", err)
+	}
+
+	if _, err := ingestor.GetCurrentPrice("BTCUSDT"); err == nil {
+		t.Error("Expected outdated snapshot to be ignored")
+	}
+	if hub.Snapshot() != nil {
+		t.Error("Hub snapshot should not be set from an outdated snapshot")
+	}
+}
+
+// TestSnapshotDisabled verifies persistence is a no-op without a file.
+func TestSnapshotDisabled(t *testing.T) {
+	ingestor := NewIngestor(HubSink(NewHub()))
+
+	if err := ingestor.SaveSnapshot(); err != nil {
+		t.Errorf("Expected no error when disabled, got %v", err)
+	}
+	if err := ingestor.RestoreSnapshot(); err != nil {
+		t.Errorf("Expected no error when disabled, got %v", err)
+	}
+}
+
+// TestPrices verifies cached prices are reported for symbols with data only.
+func TestPrices(t *testing.T) {
+	ingestor := NewIngestor(HubSink(NewHub()), WithSymbols([]string{"BTCUSDT", "ETHUSDT"}))
+	if prices := ingestor.Prices(); prices == nil || len(prices) != 0 {
+		t.Fatalf("Expected an empty list before any update, got %+v", prices)
+	}
+
+	ingestor.updateSymbolData(&binance.WsMarketStatEvent{
+		Symbol:             "ETHUSDT",
+		LastPrice:          "3000.50",
+		PriceChange:        "-12.00",
+		PriceChangePercent: "-0.40",
+		BaseVolume:         "2500",
+		Time:               1708424625120,
+	})
+
+	prices := ingestor.Prices()
+	if len(prices) != 1 {
+		t.Fatalf("Expected 1 price, got %d", len(prices))
+	}
+	if got := prices[0]; got.Symbol != "ETHUSDT" || got.Price != 3000.5 || got.Change != -12 ||
+		got.ChangePercent != -0.3983 || got.Volume != 2500 || got.EventTime != 1708424625120 || got.ReceivedAt == 0 {
+		t.Errorf("Unexpected price %+v", got)
+	}
+}
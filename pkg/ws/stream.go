@@ -0,0 +1,37 @@
+package ws
+
+// marketStream is the Binance market data an Ingestor streams: ticker
+// prices unless WithCandles, WithOrderBook, WithTrades or WithFutures
+// selects another stream. Each stream keeps its own messages pending
+// broadcast.
+type marketStream interface {
+	// serve streams from Binance and broadcasts until the ingestor stops
+	serve(i *Ingestor)
+
+	// replays reports whether recorded messages of a type belong to the
+	// stream
+	replays(kind string) bool
+}
+
+// playback broadcasts the messages of an Ingestor from another source than
+// Binance: a recording with WithReplay or random prices with WithMockData.
+type playback interface {
+	play(i *Ingestor)
+}
+
+// tickerStream streams the 24h ticker prices of the tracked symbols, along
+// with the prices of other exchanges.
+type tickerStream struct{}
+
+func (tickerStream) serve(i *Ingestor) {
+	// Load today's opens so the since-midnight change is exact from the start
+	go i.RefreshDayOpens(i.ctx)
+	go i.RefreshAnchors(i.ctx)
+
+	// Start the multi-symbol stream
+	i.StartMultiSymbol()
+}
+
+func (tickerStream) replays(kind string) bool {
+	return kind == "multi_update"
+}
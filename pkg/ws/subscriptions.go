@@ -0,0 +1,207 @@
+package ws
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+// TopicWildcard matches any run of characters in a subscription pattern,
+// e.g. "prices:*USDT" or "macro:*".
+const TopicWildcard = "*"
+
+// ErrInvalidPattern is returned for empty subscription patterns.
+var ErrInvalidPattern = errors.New("invalid subscription pattern")
+
+// Subscriptions maps topic patterns to the clients subscribed to them.
+//
+// Patterns are indexed in a prefix trie keyed by their literal prefix (the
+// part before the first wildcard), so matching a topic only walks the
+// characters of that topic and inspects the patterns stored along the way
+// instead of testing every subscription.
+//
+// The Hub keeps the topic subscriptions of its clients in one.
+type Subscriptions struct {
+	mu   sync.RWMutex
+	root *trieNode
+}
+
+// trieNode is one character of a literal pattern prefix.
+type trieNode struct {
+	children map[byte]*trieNode
+
+	// patterns holds the clients of each pattern whose literal prefix ends
+	// at this node, keyed by the full pattern
+	patterns map[string]map[*Client]bool
+}
+
+// NewSubscriptions creates an empty subscription index.
+func NewSubscriptions() *Subscriptions {
+	return &Subscriptions{root: newTrieNode()}
+}
+
+func newTrieNode() *trieNode {
+	return &trieNode{
+		children: make(map[byte]*trieNode),
+		patterns: make(map[string]map[*Client]bool),
+	}
+}
+
+// Subscribe adds the client to the given topic pattern.
+func (s *Subscriptions) Subscribe(client *Client, pattern string) error {
+	if pattern == "" {
+		return ErrInvalidPattern
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	node := s.root
+	for _, c := range []byte(literalPrefix(pattern)) {
+		child, exists := node.children[c]
+		if !exists {
+			child = newTrieNode()
+			node.children[c] = child
+		}
+		node = child
+	}
+
+	clients, exists := node.patterns[pattern]
+	if !exists {
+		clients = make(map[*Client]bool)
+		node.patterns[pattern] = clients
+	}
+	clients[client] = true
+	return nil
+}
+
+// Unsubscribe removes the client from the given topic pattern. It reports
+// whether the client was subscribed.
+func (s *Subscriptions) Unsubscribe(client *Client, pattern string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.root.remove(client, pattern, literalPrefix(pattern))
+}
+
+// remove deletes the client from pattern below n, pruning empty nodes.
+func (n *trieNode) remove(client *Client, pattern, prefix string) bool {
+	if prefix == "" {
+		clients, exists := n.patterns[pattern]
+		if !exists || !clients[client] {
+			return false
+		}
+		delete(clients, client)
+		if len(clients) == 0 {
+			delete(n.patterns, pattern)
+		}
+		return true
+	}
+
+	child, exists := n.children[prefix[0]]
+	if !exists {
+		return false
+	}
+
+	removed := child.remove(client, pattern, prefix[1:])
+	if len(child.children) == 0 && len(child.patterns) == 0 {
+		delete(n.children, prefix[0])
+	}
+	return removed
+}
+
+// RemoveClient drops every subscription of the client, e.g. on disconnect.
+func (s *Subscriptions) RemoveClient(client *Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, pattern := range s.root.clientPatterns(client, nil) {
+		s.root.remove(client, pattern, literalPrefix(pattern))
+	}
+}
+
+// Patterns returns the patterns the client is subscribed to.
+func (s *Subscriptions) Patterns(client *Client) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.root.clientPatterns(client, nil)
+}
+
+// clientPatterns collects the patterns below n that include the client.
+func (n *trieNode) clientPatterns(client *Client, patterns []string) []string {
+	for pattern, clients := range n.patterns {
+		if clients[client] {
+			patterns = append(patterns, pattern)
+		}
+	}
+	for _, child := range n.children {
+		patterns = child.clientPatterns(client, patterns)
+	}
+	return patterns
+}
+
+// Match returns the clients subscribed to a pattern matching the topic.
+// Each client appears once, even if several of its patterns match.
+func (s *Subscriptions) Match(topic string) []*Client {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[*Client]bool)
+	var matched []*Client
+
+	node := s.root
+	for depth := 0; node != nil; depth++ {
+		for pattern, clients := range node.patterns {
+			if !matchPattern(pattern[depth:], topic[depth:]) {
+				continue
+			}
+			for client := range clients {
+				if !seen[client] {
+					seen[client] = true
+					matched = append(matched, client)
+				}
+			}
+		}
+
+		if depth == len(topic) {
+			break
+		}
+		node = node.children[topic[depth]]
+	}
+
+	return matched
+}
+
+// literalPrefix returns the part of a pattern before its first wildcard.
+func literalPrefix(pattern string) string {
+	if idx := strings.Index(pattern, TopicWildcard); idx >= 0 {
+		return pattern[:idx]
+	}
+	return pattern
+}
+
+// matchPattern reports whether topic matches pattern, where each wildcard
+// matches any run of characters, including none.
+func matchPattern(pattern, topic string) bool {
+	parts := strings.Split(pattern, TopicWildcard)
+	if len(parts) == 1 {
+		return pattern == topic
+	}
+
+	if !strings.HasPrefix(topic, parts[0]) {
+		return false
+	}
+	topic = topic[len(parts[0]):]
+
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		idx := strings.Index(topic, part)
+		if idx < 0 {
+			return false
+		}
+		topic = topic[idx+len(part):]
+	}
+
+	return strings.HasSuffix(topic, last)
+}
@@ -0,0 +1,133 @@
+package ws
+
+import (
+	"sort"
+	"testing"
+)
+
+// TestMatchPattern verifies wildcard matching.
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		topic    string
+		expected bool
+	}{
+		{"prices:BTCUSDT", "prices:BTCUSDT", true},
+		{"prices:BTCUSDT", "prices:ETHUSDT", false},
+		{"prices:*USDT", "prices:BTCUSDT", true},
+		{"prices:*USDT", "prices:BTCEUR", false},
+		{"macro:*", "macro:GDP", true},
+		{"macro:*", "macro:", true},
+		{"macro:*", "prices:GDP", false},
+		{"*", "anything", true},
+		{"orderbook:*:*", "orderbook:BTCUSDT:10", true},
+		{"a*b*b", "abb", true},
+		{"a*b*b", "ab", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchPattern(tt.pattern, tt.topic); got != tt.expected {
+			t.Errorf("matchPattern(%q, %q) = %v, expected %v", tt.pattern, tt.topic, got, tt.expected)
+		}
+	}
+}
+
+// TestSubscriptionsMatch verifies exact and wildcard subscriptions.
+func TestSubscriptionsMatch(t *testing.T) {
+	subs := NewSubscriptions()
+	usdt := &Client{}
+	macro := &Client{}
+	btc := &Client{}
+
+	subs.Subscribe(usdt, "prices:*USDT")
+	subs.Subscribe(macro, "macro:*")
+	subs.Subscribe(btc, "prices:BTCUSDT")
+	subs.Subscribe(btc, "prices:BTC*")
+
+	tests := []struct {
+		topic    string
+		expected []*Client
+	}{
+		{"prices:BTCUSDT", []*Client{usdt, btc}},
+		{"prices:ETHUSDT", []*Client{usdt}},
+		{"prices:BTCEUR", []*Client{btc}},
+		{"macro:CPIAUCSL", []*Client{macro}},
+		{"candles:BTCUSDT", nil},
+	}
+
+	for _, tt := range tests {
+		matched := subs.Match(tt.topic)
+		if len(matched) != len(tt.expected) {
+			t.Errorf("%s: expected %d clients, got %d", tt.topic, len(tt.expected), len(matched))
+			continue
+		}
+		for _, client := range tt.expected {
+			if !containsClient(matched, client) {
+				t.Errorf("%s: expected client %p to match", tt.topic, client)
+			}
+		}
+	}
+}
+
+// TestSubscriptionsUnsubscribe verifies removal and trie pruning.
+func TestSubscriptionsUnsubscribe(t *testing.T) {
+	subs := NewSubscriptions()
+	client := &Client{}
+
+	subs.Subscribe(client, "prices:*USDT")
+
+	if !subs.Unsubscribe(client, "prices:*USDT") {
+		t.Error("Expected Unsubscribe to report an existing subscription")
+	}
+	if subs.Unsubscribe(client, "prices:*USDT") {
+		t.Error("Expected second Unsubscribe to report no subscription")
+	}
+	if matched := subs.Match("prices:BTCUSDT"); len(matched) != 0 {
+		t.Errorf("Expected no matches after unsubscribe, got %d", len(matched))
+	}
+	if len(subs.root.children) != 0 {
+		t.Error("Expected empty trie nodes to be pruned")
+	}
+}
+
+// TestSubscriptionsRemoveClient verifies all patterns of a client are dropped.
+func TestSubscriptionsRemoveClient(t *testing.T) {
+	subs := NewSubscriptions()
+	client := &Client{}
+	other := &Client{}
+
+	subs.Subscribe(client, "prices:*")
+	subs.Subscribe(client, "macro:GDP")
+	subs.Subscribe(other, "macro:GDP")
+
+	patterns := subs.Patterns(client)
+	sort.Strings(patterns)
+	if len(patterns) != 2 || patterns[0] != "macro:GDP" || patterns[1] != "prices:*" {
+		t.Errorf("Unexpected patterns: %v", patterns)
+	}
+
+	subs.RemoveClient(client)
+
+	if len(subs.Patterns(client)) != 0 {
+		t.Error("Expected no patterns after RemoveClient")
+	}
+	if matched := subs.Match("macro:GDP"); len(matched) != 1 || matched[0] != other {
+		t.Errorf("Expected only the other client to remain, got %v", matched)
+	}
+}
+
+// TestSubscribeRejectsEmptyPattern verifies pattern validation.
+func TestSubscribeRejectsEmptyPattern(t *testing.T) {
+	if err := NewSubscriptions().Subscribe(&Client{}, ""); err != ErrInvalidPattern {
+		t.Errorf("Expected ErrInvalidPattern, got %v", err)
+	}
+}
+
+func containsClient(clients []*Client, client *Client) bool {
+	for _, c := range clients {
+		if c == client {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,208 @@
+// Package testutil runs end-to-end WebSocket tests: it serves a Fiber app on
+// a loopback port and connects real clients, which go through the HTTP
+// upgrade, the Hub and the client pumps like production traffic:
+//
+//	srv := testutil.NewServer(t, app)
+//	conn := srv.Dial("/ws/prices")
+//	testutil.WaitForClients(t, hub, 1)
+//	hub.Publish(update)
+//	message := conn.ReadType("multi_update")
+//
+// Connections and the server are closed when the test ends.
+package testutil
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"macro-analyst/pkg/ws"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gorilla/websocket"
+)
+
+// DefaultTimeout bounds each read and wait of the harness
+const DefaultTimeout = 2 * time.Second
+
+// Server serves a Fiber app on a loopback port.
+type Server struct {
+	// URL is the WebSocket base URL of the server, e.g. ws://127.0.0.1:41234
+	URL string
+
+	t testing.TB
+}
+
+// NewServer serves app on a free loopback port until the test ends.
+func NewServer(t testing.TB, app *fiber.App) *Server {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	go app.Listener(listener)
+	t.Cleanup(func() { app.Shutdown() })
+
+	return &Server{URL: "ws://" + listener.Addr().String(), t: t}
+}
+
+// Dial upgrades a connection to path, e.g. "/ws/prices?symbols=BTCUSDT",
+// offering the given subprotocols. The test fails if the upgrade does.
+func (s *Server) Dial(path string, subprotocols ...string) *Conn {
+	s.t.Helper()
+
+	conn, status, err := s.DialErr(path, nil, subprotocols...)
+	if err != nil {
+		s.t.Fatalf("Failed to connect to %s (status %d): %v", path, status, err)
+	}
+	return conn
+}
+
+// DialErr upgrades a connection to path with extra request headers, and
+// returns the HTTP status and error of a rejected upgrade instead of
+// failing the test.
+func (s *Server) DialErr(path string, header http.Header, subprotocols ...string) (*Conn, int, error) {
+	dialer := websocket.Dialer{HandshakeTimeout: DefaultTimeout, Subprotocols: subprotocols}
+	conn, resp, err := dialer.Dial(s.URL+path, header)
+	if err != nil {
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		return nil, status, err
+	}
+	s.t.Cleanup(func() { conn.Close() })
+
+	return &Conn{Conn: conn, t: s.t}, resp.StatusCode, nil
+}
+
+// Conn is a client connection of a test.
+type Conn struct {
+	*websocket.Conn
+
+	t testing.TB
+}
+
+// Send writes a value as a JSON text message, e.g. a command.
+func (c *Conn) Send(value any) {
+	c.t.Helper()
+
+	if err := c.WriteJSON(value); err != nil {
+		c.t.Fatalf("Failed to send %v: %v", value, err)
+	}
+}
+
+// Read returns the next message, failing the test if none arrives within
+// DefaultTimeout.
+func (c *Conn) Read() []byte {
+	c.t.Helper()
+
+	message, err := c.read(DefaultTimeout)
+	if err != nil {
+		c.t.Fatalf("Expected a message: %v", err)
+	}
+	return message
+}
+
+// ReadType returns the next message of a type, such as "multi_update" or
+// "ack", skipping the others, e.g. heartbeats.
+func (c *Conn) ReadType(messageType string) []byte {
+	c.t.Helper()
+
+	deadline := time.Now().Add(DefaultTimeout)
+	for {
+		message, err := c.read(time.Until(deadline))
+		if err != nil {
+			c.t.Fatalf("Expected a %s message: %v", messageType, err)
+		}
+		if typeOf(message) == messageType {
+			return message
+		}
+	}
+}
+
+// ReadUntil reads messages until match accepts one, which it returns,
+// failing the test if none does within DefaultTimeout.
+func (c *Conn) ReadUntil(match func(message []byte) bool) []byte {
+	c.t.Helper()
+
+	deadline := time.Now().Add(DefaultTimeout)
+	for {
+		message, err := c.read(time.Until(deadline))
+		if err != nil {
+			c.t.Fatalf("Expected a matching message: %v", err)
+		}
+		if match(message) {
+			return message
+		}
+	}
+}
+
+// ExpectNoType fails the test if a message of the type arrives within
+// wait.
+func (c *Conn) ExpectNoType(messageType string, wait time.Duration) {
+	c.t.Helper()
+
+	deadline := time.Now().Add(wait)
+	for {
+		message, err := c.read(time.Until(deadline))
+		if err != nil {
+			return
+		}
+		if typeOf(message) == messageType {
+			c.t.Fatalf("Expected no %s message, got %s", messageType, message)
+		}
+	}
+}
+
+// read returns the next message within timeout. A timeout leaves the
+// connection unusable, so it is only expected at the end of a test.
+func (c *Conn) read(timeout time.Duration) ([]byte, error) {
+	c.SetReadDeadline(time.Now().Add(max(timeout, 0)))
+	_, message, err := c.ReadMessage()
+	return message, err
+}
+
+// typeOf returns the type field of a JSON message, or of the first line of
+// NDJSON messages.
+func typeOf(message []byte) string {
+	line, _, _ := strings.Cut(string(message), "\n")
+	var envelope struct {
+		Type string `json:"type"`
+	}
+	json.Unmarshal([]byte(line), &envelope)
+	return envelope.Type
+}
+
+// Symbols returns the symbols of a multi_update message.
+func Symbols(t testing.TB, message []byte) []string {
+	t.Helper()
+
+	var update ws.MultiUpdate
+	if err := json.Unmarshal(message, &update); err != nil {
+		t.Fatalf("Expected a multi_update, got %s: %v", message, err)
+	}
+	symbols := make([]string, len(update.Data))
+	for idx, price := range update.Data {
+		symbols[idx] = price.Symbol
+	}
+	return symbols
+}
+
+// WaitForClients waits until the Hub has n registered clients, as
+// connections register asynchronously after the upgrade.
+func WaitForClients(t testing.TB, hub *ws.Hub, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(DefaultTimeout)
+	for hub.GetClientCount() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d clients, got %d", n, hub.GetClientCount())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
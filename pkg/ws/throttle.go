@@ -0,0 +1,87 @@
+package ws
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"time"
+)
+
+// coalescer merges the price updates of several multi_update messages,
+// keeping the latest update per symbol, for clients that receive prices at
+// a lower rate than the feed produces them.
+type coalescer struct {
+	pending   map[string]*PriceUpdate
+	region    string
+	lastFlush time.Time
+	decimals  int          // Decimal places of flushed values, unrounded if not positive
+	numbers   NumberFormat // Format of the prices of added updates
+}
+
+func newCoalescer(decimals int, numbers NumberFormat) *coalescer {
+	return &coalescer{pending: make(map[string]*PriceUpdate), decimals: decimals, numbers: numbers}
+}
+
+// add merges a multi_update into the pending updates and reports whether
+// it did; other messages are left for the caller to deliver as they are.
+func (co *coalescer) add(message []byte) bool {
+	if co.numbers == NumbersString || co.numbers == NumbersScaled {
+		message = parseNumbers(message)
+	}
+
+	var update MultiUpdate
+	if err := json.Unmarshal(message, &update); err != nil || update.Type != "multi_update" {
+		return false
+	}
+
+	for _, priceUpdate := range update.Data {
+		co.pending[priceUpdate.key()] = priceUpdate
+	}
+	co.region = update.Region
+	return true
+}
+
+// due reports whether pending updates should be flushed at now, i.e. at
+// least interval passed since the last flush.
+func (co *coalescer) due(now time.Time, interval time.Duration) bool {
+	return len(co.pending) > 0 && now.Sub(co.lastFlush) >= interval
+}
+
+// flush returns the pending updates as one multi_update ordered by symbol
+// and exchange, or nil if there are none.
+func (co *coalescer) flush(now time.Time) ([]byte, error) {
+	if len(co.pending) == 0 {
+		return nil, nil
+	}
+
+	update := &MultiUpdate{Type: "multi_update", Region: co.region}
+	for _, priceUpdate := range co.pending {
+		if co.decimals > 0 {
+			priceUpdate.round(co.decimals)
+		}
+		update.Data = append(update.Data, priceUpdate)
+	}
+	sort.Slice(update.Data, func(a, b int) bool {
+		return update.Data[a].key() < update.Data[b].key()
+	})
+
+	co.pending = make(map[string]*PriceUpdate)
+	co.lastFlush = now
+	return json.Marshal(update)
+}
+
+// round rounds the price and changes of an update to decimals places.
+func (u *PriceUpdate) round(decimals int) {
+	scale := math.Pow(10, float64(decimals))
+	roundTo := func(value float64) float64 {
+		return math.Round(value*scale) / scale
+	}
+
+	u.Price = roundTo(u.Price)
+	u.Change = roundTo(u.Change)
+	u.ChangePercent = roundTo(u.ChangePercent)
+	if u.SinceMidnightChangePercent != nil {
+		rounded := roundTo(*u.SinceMidnightChangePercent)
+		u.SinceMidnightChangePercent = &rounded
+	}
+}
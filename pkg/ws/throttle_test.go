@@ -0,0 +1,80 @@
+package ws
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// TestCoalescer verifies throttled clients get the latest price per symbol
+// at most once per interval.
+func TestCoalescer(t *testing.T) {
+	prices := newCoalescer(0, NumbersFloat)
+	start := time.Unix(1700000000, 0)
+
+	for _, update := range []*MultiUpdate{
+		{Type: "multi_update", Data: []*PriceUpdate{{Symbol: "ETHUSDT", Price: 3000}, {Symbol: "BTCUSDT", Price: 50000}}},
+		{Type: "multi_update", Data: []*PriceUpdate{{Symbol: "BTCUSDT", Price: 50100}}},
+	} {
+		message, _ := json.Marshal(update)
+		if !prices.add(message) {
+			t.Fatal("Expected the multi_update to be merged")
+		}
+	}
+	if prices.add([]byte(`{"type":"heartbeat"}`)) {
+		t.Error("Expected other messages to be left to the caller")
+	}
+
+	if !prices.due(start, 5*time.Second) {
+		t.Fatal("Expected the first flush to be due immediately")
+	}
+	message, err := prices.flush(start)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var merged MultiUpdate
+	json.Unmarshal(message, &merged)
+	if len(merged.Data) != 2 || merged.Data[0].Symbol != "BTCUSDT" || merged.Data[0].Price != 50100 {
+		t.Errorf("Expected the latest price per symbol ordered by symbol, got %+v", merged.Data)
+	}
+
+	next, _ := json.Marshal(&MultiUpdate{Type: "multi_update", Data: []*PriceUpdate{{Symbol: "BTCUSDT"}}})
+	prices.add(next)
+	if prices.due(start.Add(time.Second), 5*time.Second) {
+		t.Error("Expected no flush before the interval passed")
+	}
+	if !prices.due(start.Add(5*time.Second), 5*time.Second) {
+		t.Error("Expected a flush once the interval passed")
+	}
+}
+
+// TestCoalescerRounding verifies flushed values are rounded for clients
+// asking for fewer decimals.
+func TestCoalescerRounding(t *testing.T) {
+	sinceMidnight := 1.23456
+	prices := newCoalescer(2, NumbersFloat)
+	message, _ := json.Marshal(&MultiUpdate{Type: "multi_update", Data: []*PriceUpdate{{
+		Symbol:                     "BTCUSDT",
+		Price:                      50123.456789,
+		Change:                     -12.3456,
+		ChangePercent:              0.024651,
+		SinceMidnightChangePercent: &sinceMidnight,
+	}}})
+	prices.add(message)
+
+	flushed, err := prices.flush(time.Unix(1700000000, 0))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var merged MultiUpdate
+	json.Unmarshal(flushed, &merged)
+	update := merged.Data[0]
+	if update.Price != 50123.46 || update.Change != -12.35 || update.ChangePercent != 0.02 {
+		t.Errorf("Expected values rounded to 2 decimals, got %+v", update)
+	}
+	if update.SinceMidnightChangePercent == nil || *update.SinceMidnightChangePercent != 1.23 {
+		t.Errorf("Expected since-midnight change rounded to 1.23, got %v", update.SinceMidnightChangePercent)
+	}
+}
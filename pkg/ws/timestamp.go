@@ -0,0 +1,58 @@
+package ws
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimestampSource selects which clock fills the human-readable
+// PriceUpdate timestamp. Both clocks are always sent as eventTime and
+// receivedAt so clients can chart either one.
+type TimestampSource string
+
+const (
+	// TimestampSourceReceived uses the server receive time (default)
+	TimestampSourceReceived TimestampSource = "received"
+
+	// TimestampSourceEvent uses the Binance event time, which keeps candles
+	// correct when ingestion pauses and catches up
+	TimestampSourceEvent TimestampSource = "event"
+
+	// TimestampLayout is the format of the human-readable timestamp
+	TimestampLayout = "15:04:05.000"
+)
+
+// ParseTimestampSource converts a configuration value to a TimestampSource.
+func ParseTimestampSource(value string) (TimestampSource, error) {
+	switch TimestampSource(value) {
+	case TimestampSourceReceived, TimestampSourceEvent:
+		return TimestampSource(value), nil
+	default:
+		return "", fmt.Errorf("invalid timestamp source %q (expected %q or %q)",
+			value, TimestampSourceReceived, TimestampSourceEvent)
+	}
+}
+
+// WithTimestampSource selects which clock fills the PriceUpdate timestamp.
+func WithTimestampSource(source TimestampSource) IngestorOption {
+	return func(i *Ingestor) {
+		i.timestampSource = source
+	}
+}
+
+// formatTimestamp renders the human-readable timestamp from the configured
+// source, falling back to the receive time when no event time is known.
+func (i *Ingestor) formatTimestamp(eventTime int64, receivedAt time.Time) string {
+	if i.timestampSource == TimestampSourceEvent && eventTime != 0 {
+		return time.UnixMilli(eventTime).Format(TimestampLayout)
+	}
+	return receivedAt.Format(TimestampLayout)
+}
+
+// observeLatency records the delay between the exchange event and its receipt.
+func (i *Ingestor) observeLatency(eventTime int64, receivedAt time.Time) {
+	if eventTime == 0 {
+		return
+	}
+	i.metrics.feedLatency.Set(receivedAt.Sub(time.UnixMilli(eventTime)).Seconds())
+}
@@ -0,0 +1,72 @@
+package ws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+)
+
+// TestParseTimestampSource verifies configuration parsing.
+func TestParseTimestampSource(t *testing.T) {
+	for _, value := range []string{"received", "event"} {
+		if _, err := ParseTimestampSource(value); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", value, err)
+		}
+	}
+
+	if _, err := ParseTimestampSource("exchange"); err == nil {
+		t.Error("Expected error for invalid timestamp source")
+	}
+}
+
+// TestPriceUpdateCarriesBothTimes verifies event and receive times are exposed.
+func TestPriceUpdateCarriesBothTimes(t *testing.T) {
+	eventTime := time.Date(2024, 1, 15, 10, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		source   TimestampSource
+		expected func(receivedAt int64) string
+	}{
+		{TimestampSourceEvent, func(int64) string { return eventTime.Format(TimestampLayout) }},
+		{TimestampSourceReceived, func(receivedAt int64) string {
+			return time.UnixMilli(receivedAt).Format(TimestampLayout)
+		}},
+	}
+
+	for _, tt := range tests {
+		ingestor := NewIngestor(HubSink(NewHub()), WithTimestampSource(tt.source))
+
+		update, err := ingestor.convertEventToPriceUpdate(&binance.WsMarketStatEvent{
+			Time:               eventTime.UnixMilli(),
+			Symbol:             "BTCUSDT",
+			LastPrice:          "50000.00",
+			PriceChange:        "100.00",
+			PriceChangePercent: "0.20",
+			BaseVolume:         "1000",
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if update.EventTime != eventTime.UnixMilli() {
+			t.Errorf("%s: expected eventTime %d, got %d", tt.source, eventTime.UnixMilli(), update.EventTime)
+		}
+		if update.ReceivedAt == 0 {
+			t.Errorf("%s: expected receivedAt to be set", tt.source)
+		}
+		if want := tt.expected(update.ReceivedAt); update.Timestamp != want {
+			t.Errorf("%s: expected timestamp %s, got %s", tt.source, want, update.Timestamp)
+		}
+	}
+}
+
+// TestFormatTimestampWithoutEventTime verifies the receive time fallback.
+func TestFormatTimestampWithoutEventTime(t *testing.T) {
+	ingestor := NewIngestor(HubSink(NewHub()), WithTimestampSource(TimestampSourceEvent))
+	receivedAt := time.Date(2024, 1, 15, 10, 30, 0, 0, time.Local)
+
+	if got := ingestor.formatTimestamp(0, receivedAt); got != "10:30:00.000" {
+		t.Errorf("Expected receive time fallback 10:30:00.000, got %s", got)
+	}
+}
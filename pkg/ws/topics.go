@@ -0,0 +1,154 @@
+package ws
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Topics of the published messages. Candle and order book updates are
+// published per symbol, e.g. "candles:BTCUSDT" (see SymbolTopic).
+const (
+	TopicPrices    = "prices"
+	TopicTrades    = "trades"
+	TopicFutures   = "futures"
+	TopicMacro     = "macro"
+	TopicFormulas  = "formulas"
+	TopicCandles   = "candles"
+	TopicOrderBook = "orderbook"
+)
+
+// MaxCommandTopics is the most topics one topic command may name
+const MaxCommandTopics = 20
+
+// topicPattern matches topics and topic patterns such as "orderbook:*USDT"
+var topicPattern = regexp.MustCompile(`^[A-Za-z0-9_.:*-]{1,64}$`)
+
+// SymbolTopic returns the topic of a symbol's messages, e.g.
+// "orderbook:BTCUSDT".
+func SymbolTopic(topic, symbol string) string {
+	return topic + ":" + symbol
+}
+
+// ParseTopics converts a comma-separated list of topic patterns, e.g.
+// "prices,macro,orderbook:BTC*", to a list.
+func ParseTopics(value string) ([]string, error) {
+	var topics []string
+	for _, topic := range strings.Split(value, ",") {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			topics = append(topics, topic)
+		}
+	}
+	return topics, validateTopics(topics)
+}
+
+// validateTopics checks the number and syntax of topic patterns.
+func validateTopics(topics []string) error {
+	if len(topics) == 0 || len(topics) > MaxCommandTopics {
+		return fmt.Errorf("topics must name 1 to %d topics", MaxCommandTopics)
+	}
+	for _, topic := range topics {
+		if !topicPattern.MatchString(topic) {
+			return fmt.Errorf("invalid topic %q", topic)
+		}
+	}
+	return nil
+}
+
+// PublishTopic queues a message for the clients subscribed to a matching
+// topic pattern, without blocking. Messages published with Publish reach
+// every client regardless of topics. It reports whether the message was
+// accepted, like Publish.
+func (h *Hub) PublishTopic(topic string, data []byte) bool {
+	return h.enqueue(queuedMessage{data: data, topic: topic, queuedAt: h.clock.Now()})
+}
+
+// broadcastTopic sends a message to the clients subscribed to the topic.
+func (h *Hub) broadcastTopic(topic string, message []byte) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var cache renderCache
+	delivered := false
+	for _, client := range h.topics.Match(topic) {
+		if h.clients[client] {
+			h.deliver(client, message, &cache)
+			delivered = true
+		}
+	}
+	if !delivered {
+		h.recordDrop(DropNoSubscribers)
+	}
+}
+
+// subscribeTopics subscribes a registering client to its Topics, or to
+// every topic if it has none.
+func (h *Hub) subscribeTopics(client *Client) {
+	topics := client.Topics
+	if len(topics) == 0 {
+		topics = []string{TopicWildcard}
+	}
+	for _, topic := range topics {
+		if err := h.topics.Subscribe(client, topic); err != nil {
+			continue
+		}
+	}
+}
+
+// receives reports whether a client is subscribed to a topic.
+func (h *Hub) receives(client *Client, topic string) bool {
+	for _, pattern := range h.topics.Patterns(client) {
+		if matchPattern(pattern, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyTopicCommand validates and applies a subscribe_topics or
+// unsubscribe_topics command. Clients that connected without Topics receive
+// every topic until their first subscribe_topics, which narrows the stream
+// to the named topics.
+func (c *Client) applyTopicCommand(command Command) error {
+	if c.Hub == nil {
+		return errors.New("topics are not available on this stream")
+	}
+	if err := validateTopics(command.Topics); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if command.Type == CommandUnsubscribeTopics {
+		for _, topic := range command.Topics {
+			c.Hub.topics.Unsubscribe(c, topic)
+		}
+		return nil
+	}
+
+	if !c.topicsNarrowed && len(c.Topics) == 0 {
+		c.Hub.topics.Unsubscribe(c, TopicWildcard)
+	}
+	c.topicsNarrowed = true
+	for _, topic := range command.Topics {
+		if err := c.Hub.topics.Subscribe(c, topic); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// topicPatterns returns the sorted topic patterns the client is subscribed
+// to.
+func (c *Client) topicPatterns() []string {
+	if c.Hub == nil {
+		return nil
+	}
+
+	patterns := c.Hub.topics.Patterns(c)
+	sort.Strings(patterns)
+	return patterns
+}
@@ -0,0 +1,99 @@
+package ws
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// receivedMessages drains the messages queued for a client.
+func receivedMessages(client *Client) []string {
+	var messages []string
+	for {
+		select {
+		case message := <-client.Send:
+			messages = append(messages, string(message))
+		default:
+			return messages
+		}
+	}
+}
+
+// TestPublishTopic verifies topic messages only reach the clients
+// subscribed to a matching pattern, while Publish reaches every client.
+func TestPublishTopic(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	everything := &Client{Hub: hub, Send: make(chan []byte, 16)}
+	macro := &Client{Hub: hub, Send: make(chan []byte, 16), Topics: []string{TopicMacro}}
+	books := &Client{Hub: hub, Send: make(chan []byte, 16)}
+	for _, client := range []*Client{everything, macro, books} {
+		hub.register <- client
+	}
+	flushHub(hub)
+
+	response := books.handleCommand([]byte(`{"type":"subscribe_topics","topics":["orderbook:BTC*"]}`), time.Now())
+	if ack, ok := response.(*CommandAck); !ok || strings.Join(ack.Topics, ",") != "orderbook:BTC*" {
+		t.Fatalf("expected the stream narrowed to the BTC order books, got %+v", response)
+	}
+
+	hub.PublishTopic(TopicPrices, []byte("price"))
+	hub.PublishTopic(TopicMacro, []byte("macro"))
+	hub.PublishTopic(SymbolTopic(TopicOrderBook, "BTCUSDT"), []byte("btc book"))
+	hub.PublishTopic(SymbolTopic(TopicOrderBook, "ETHUSDT"), []byte("eth book"))
+	hub.Publish([]byte("heartbeat"))
+	flushHub(hub)
+
+	tests := []struct {
+		name     string
+		client   *Client
+		expected string
+	}{
+		{"every topic", everything, "price,macro,btc book,eth book,heartbeat"},
+		{"topics at connect", macro, "macro,heartbeat"},
+		{"narrowed by command", books, "btc book,heartbeat"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(receivedMessages(tt.client), ","); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+
+	books.handleCommand([]byte(`{"type":"unsubscribe_topics","topics":["orderbook:BTC*"]}`), time.Now())
+	hub.unregister <- everything
+	hub.PublishTopic(SymbolTopic(TopicOrderBook, "BTCUSDT"), []byte("btc book"))
+	flushHub(hub)
+	if got := receivedMessages(books); len(got) != 0 {
+		t.Errorf("expected nothing after unsubscribing, got %v", got)
+	}
+	if drops := hub.DropSummary().Reasons[DropNoSubscribers]; drops != 1 {
+		t.Errorf("expected the unsubscribed topic to count as a drop, got %d", drops)
+	}
+}
+
+// TestParseTopics tests parsing topic lists.
+func TestParseTopics(t *testing.T) {
+	tests := []struct {
+		value     string
+		expected  string
+		expectErr bool
+	}{
+		{"prices", "prices", false},
+		{" prices , macro,orderbook:*USDT ", "prices,macro,orderbook:*USDT", false},
+		{"", "", true},
+		{",", "", true},
+		{"prices,mac ro", "", true},
+		{strings.Repeat("a,", MaxCommandTopics+1), "", true},
+	}
+
+	for _, tt := range tests {
+		topics, err := ParseTopics(tt.value)
+		if (err != nil) != tt.expectErr {
+			t.Errorf("ParseTopics(%q): expected error %v, got %v", tt.value, tt.expectErr, err)
+		}
+		if err == nil && strings.Join(topics, ",") != tt.expected {
+			t.Errorf("ParseTopics(%q) = %v, expected %s", tt.value, topics, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package ws
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"log"
+	"slices"
+	"strings"
+)
+
+// peggedAssets are stablecoins and fiat currencies, whose pairs against
+// USDT barely move and are left out of the top symbols
+var peggedAssets = []string{"USDC", "FDUSD", "BUSD", "TUSD", "USDP", "DAI", "EUR", "TRY"}
+
+// SymbolsChanged is broadcast to clients when TrackTopSymbols changed the
+// tracked symbols, so they can update their watchlists.
+type SymbolsChanged struct {
+	Type    string   `json:"type"` // Always "symbols_changed"
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Symbols []string `json:"symbols"`          // Symbols tracked now
+	Region  string   `json:"region,omitempty"` // Deployment region of the server
+}
+
+// WithTickerStatsLister replaces the Binance 24h statistics lookup
+// TrackTopSymbols ranks the pairs by.
+func WithTickerStatsLister(list TickerStatsLister) IngestorOption {
+	return func(i *Ingestor) {
+		i.listTickerStats = list
+	}
+}
+
+// TrackTopSymbols tracks the n pairs quoted in DefaultQuoteAsset with the
+// highest 24h quote volume, adding the new leaders and removing the
+// symbols it added before that fell out of the top n; symbols configured
+// or added otherwise are kept. Pairs of stablecoins and fiat currencies are
+// left out. Clients are sent a SymbolsChanged message if the symbols
+// changed.
+func (i *Ingestor) TrackTopSymbols(ctx context.Context, n int) error {
+	stats, err := i.listTickerStats(ctx)
+	if err != nil {
+		return err
+	}
+	top := topByVolume(stats, n)
+
+	i.mu.Lock()
+	added, removed := i.applyTopSymbols(top)
+	symbols := make([]string, len(i.symbols))
+	for idx, symbol := range i.symbols {
+		symbols[idx] = symbol.Name
+	}
+	i.mu.Unlock()
+
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+	log.Printf("Top %d symbols by volume: added %v, removed %v", n, added, removed)
+	i.publishSymbolsChanged(added, removed, symbols)
+	return nil
+}
+
+// topByVolume returns the symbols of the n pairs quoted in
+// DefaultQuoteAsset with the highest quote volume, other than the pairs of
+// pegged assets and pairs without trades.
+func topByVolume(stats []TickerStats, n int) []string {
+	ranked := make([]TickerStats, 0, len(stats))
+	for _, s := range stats {
+		base := strings.TrimSuffix(s.Symbol, DefaultQuoteAsset)
+		if hasQuote(s.Symbol, DefaultQuoteAsset) && s.Trades > 0 && !slices.Contains(peggedAssets, base) {
+			ranked = append(ranked, s)
+		}
+	}
+	slices.SortFunc(ranked, func(a, b TickerStats) int {
+		return cmp.Or(cmp.Compare(b.QuoteVolume, a.QuoteVolume), strings.Compare(a.Symbol, b.Symbol))
+	})
+
+	top := make([]string, 0, n)
+	for _, s := range ranked[:min(n, len(ranked))] {
+		top = append(top, s.Symbol)
+	}
+	return top
+}
+
+// applyTopSymbols tracks the top symbols and stops tracking the ones added
+// automatically before that are no longer among them. The caller must hold
+// i.mu.
+func (i *Ingestor) applyTopSymbols(top []string) (added, removed []string) {
+	if i.autoTracked == nil {
+		i.autoTracked = make(map[string]bool)
+	}
+
+	for _, name := range top {
+		if i.findSymbol(name) == nil {
+			i.symbols = append(i.symbols, &Symbol{Name: name})
+			i.autoTracked[name] = true
+			added = append(added, name)
+		}
+	}
+	i.symbols = slices.DeleteFunc(i.symbols, func(symbol *Symbol) bool {
+		if !i.autoTracked[symbol.Name] || slices.Contains(top, symbol.Name) {
+			return false
+		}
+		delete(i.autoTracked, symbol.Name)
+		removed = append(removed, symbol.Name)
+		return true
+	})
+
+	if len(added) > 0 || len(removed) > 0 {
+		i.requestResubscribe()
+	}
+	return added, removed
+}
+
+// publishSymbolsChanged tells clients that the tracked symbols changed.
+func (i *Ingestor) publishSymbolsChanged(added, removed, symbols []string) {
+	jsonData, err := json.Marshal(&SymbolsChanged{
+		Type:    "symbols_changed",
+		Added:   nonNil(added),
+		Removed: nonNil(removed),
+		Symbols: symbols,
+		Region:  i.audience.Region(),
+	})
+	if err != nil {
+		log.Printf("Error marshaling symbols change: %v", err)
+		return
+	}
+	i.publish("", jsonData)
+}
+
+// nonNil returns names, or an empty list instead of nil so it encodes as
+// [] rather than null.
+func nonNil(names []string) []string {
+	if names == nil {
+		return []string{}
+	}
+	return names
+}
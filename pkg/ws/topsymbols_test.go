@@ -0,0 +1,91 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// TestTopByVolume verifies pairs are ranked by quote volume, leaving out
+// other quote assets, stablecoins and pairs without trades.
+func TestTopByVolume(t *testing.T) {
+	stats := []TickerStats{
+		{Symbol: "BTCUSDT", QuoteVolume: 900, Trades: 10},
+		{Symbol: "USDCUSDT", QuoteVolume: 1000, Trades: 10},
+		{Symbol: "ETHBTC", QuoteVolume: 800, Trades: 10},
+		{Symbol: "ETHUSDT", QuoteVolume: 500, Trades: 10},
+		{Symbol: "SOLUSDT", QuoteVolume: 500, Trades: 10},
+		{Symbol: "OLDUSDT", QuoteVolume: 700},
+	}
+
+	tests := []struct {
+		n        int
+		expected []string
+	}{
+		{2, []string{"BTCUSDT", "ETHUSDT"}},
+		{10, []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"}},
+		{0, []string{}},
+	}
+	for _, tt := range tests {
+		if top := topByVolume(stats, tt.n); !reflect.DeepEqual(top, tt.expected) {
+			t.Errorf("Top %d: expected %v, got %v", tt.n, tt.expected, top)
+		}
+	}
+}
+
+// TestTrackTopSymbols verifies leaders are added and automatically added
+// symbols removed once they leave the top, while configured symbols stay
+// and clients are told about each change.
+func TestTrackTopSymbols(t *testing.T) {
+	stats := []TickerStats{
+		{Symbol: "BTCUSDT", QuoteVolume: 900, Trades: 1},
+		{Symbol: "ETHUSDT", QuoteVolume: 800, Trades: 1},
+	}
+	hub := NewHub()
+	ingestor := NewIngestor(HubSink(hub),
+		WithSymbols([]string{"XRPUSDT"}),
+		WithTickerStatsLister(func(ctx context.Context) ([]TickerStats, error) {
+			return stats, nil
+		}),
+	)
+	track := func() *SymbolsChanged {
+		t.Helper()
+		if err := ingestor.TrackTopSymbols(context.Background(), 2); err != nil {
+			t.Fatalf("Failed to track the top symbols: %v", err)
+		}
+		select {
+		case message := <-hub.broadcast:
+			var changed SymbolsChanged
+			json.Unmarshal(message.data, &changed)
+			return &changed
+		default:
+			return nil
+		}
+	}
+
+	changed := track()
+	if changed == nil || !reflect.DeepEqual(changed.Added, []string{"BTCUSDT", "ETHUSDT"}) || len(changed.Removed) != 0 {
+		t.Fatalf("Expected BTCUSDT and ETHUSDT added, got %+v", changed)
+	}
+	if changed := track(); changed != nil {
+		t.Errorf("Expected no message while the top is unchanged, got %+v", changed)
+	}
+
+	stats = []TickerStats{
+		{Symbol: "SOLUSDT", QuoteVolume: 950, Trades: 1},
+		{Symbol: "BTCUSDT", QuoteVolume: 900, Trades: 1},
+		{Symbol: "XRPUSDT", QuoteVolume: 100, Trades: 1},
+	}
+	changed = track()
+	if changed == nil || !reflect.DeepEqual(changed.Added, []string{"SOLUSDT"}) || !reflect.DeepEqual(changed.Removed, []string{"ETHUSDT"}) {
+		t.Fatalf("Expected SOLUSDT added and ETHUSDT removed, got %+v", changed)
+	}
+
+	symbols := ingestor.GetSymbols()
+	slices.Sort(symbols)
+	if !reflect.DeepEqual(symbols, []string{"BTCUSDT", "SOLUSDT", "XRPUSDT"}) {
+		t.Errorf("Expected the configured XRPUSDT kept, got %v", symbols)
+	}
+}
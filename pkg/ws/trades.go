@@ -0,0 +1,245 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/shopspring/decimal"
+)
+
+// DefaultTradeInterval is the throttle interval of trade ingestors
+const DefaultTradeInterval = time.Second
+
+// TradeStats summarizes the aggregated trades of one symbol over the
+// rolling window ending at its latest trade.
+type TradeStats struct {
+	Type          string  `json:"type"` // Always "trade_stats"
+	Symbol        string  `json:"symbol"`
+	WindowSeconds int     `json:"windowSeconds"`
+	VWAP          float64 `json:"vwap"`       // Volume-weighted average price
+	Trades        int64   `json:"trades"`     // Trades executed, counting those aggregated by Binance
+	BuyVolume     float64 `json:"buyVolume"`  // Base asset volume bought by takers
+	SellVolume    float64 `json:"sellVolume"` // Base asset volume sold by takers
+	LastPrice     float64 `json:"lastPrice"`
+	EventTime     int64   `json:"eventTime"`  // Trade time of the latest trade (Unix ms)
+	ReceivedAt    int64   `json:"receivedAt"` // Server receive time (Unix ms)
+}
+
+// trade is an aggregated trade within the rolling window.
+type trade struct {
+	price    float64
+	quantity float64
+	count    int64 // Trades aggregated by Binance
+	buy      bool  // Whether the taker bought
+	time     int64 // Trade time (Unix ms)
+}
+
+// tradeWindow holds the trades of one symbol within the rolling window,
+// oldest first.
+type tradeWindow struct {
+	trades     []trade
+	receivedAt int64
+}
+
+// add appends a trade and evicts those that left the window ending at it.
+func (w *tradeWindow) add(t trade, window time.Duration) {
+	w.trades = append(w.trades, t)
+
+	cutoff := t.time - window.Milliseconds()
+	evict := 0
+	for evict < len(w.trades) && w.trades[evict].time <= cutoff {
+		evict++
+	}
+	w.trades = w.trades[evict:]
+}
+
+// stats sums the trades of the window. Sums are recomputed on every call
+// instead of kept incrementally, so evictions do not accumulate rounding
+// errors, and rounded to MaxPriceDecimals.
+func (w *tradeWindow) stats(symbol string, window time.Duration) *TradeStats {
+	stats := &TradeStats{
+		Type:          "trade_stats",
+		Symbol:        symbol,
+		WindowSeconds: int(window.Seconds()),
+		ReceivedAt:    w.receivedAt,
+	}
+
+	var notional, volume float64
+	for _, t := range w.trades {
+		notional += t.price * t.quantity
+		volume += t.quantity
+		stats.Trades += t.count
+		if t.buy {
+			stats.BuyVolume += t.quantity
+		} else {
+			stats.SellVolume += t.quantity
+		}
+	}
+	if volume > 0 {
+		stats.VWAP = roundDecimals(notional / volume)
+	}
+	stats.BuyVolume = roundDecimals(stats.BuyVolume)
+	stats.SellVolume = roundDecimals(stats.SellVolume)
+	if len(w.trades) > 0 {
+		latest := w.trades[len(w.trades)-1]
+		stats.LastPrice = latest.price
+		stats.EventTime = latest.time
+	}
+	return stats
+}
+
+// roundDecimals rounds a sum of prices or quantities to MaxPriceDecimals,
+// e.g. 0.30000000000000004 to 0.3.
+func roundDecimals(value float64) float64 {
+	return decimal.NewFromFloat(value).Round(MaxPriceDecimals).InexactFloat64()
+}
+
+// WithTrades switches the ingestor to trade mode: instead of ticker updates
+// it streams Binance aggregated trades and broadcasts the VWAP, trade count
+// and taker buy and sell volume of each symbol over the rolling window,
+// once per throttle interval for the symbols that traded.
+func WithTrades(window time.Duration) IngestorOption {
+	return func(i *Ingestor) {
+		if window > 0 {
+			i.stream = &tradeStream{
+				window: window,
+				trades: make(map[string]*tradeWindow),
+				traded: make(map[string]bool),
+			}
+		}
+	}
+}
+
+// tradeStream streams the aggregated trades of the tracked symbols.
+type tradeStream struct {
+	window time.Duration // Rolling window of the statistics
+
+	// mu protects the trades per symbol and the symbols that traded since
+	// the last broadcast
+	mu     sync.Mutex
+	trades map[string]*tradeWindow
+	traded map[string]bool
+}
+
+// TradeMode reports whether the ingestor streams trade statistics instead
+// of ticker updates.
+func (i *Ingestor) TradeMode() bool {
+	_, ok := i.stream.(*tradeStream)
+	return ok
+}
+
+// serve connects to the Binance aggregated trade streams of all symbols in
+// one connection and broadcasts trade statistics at the throttle interval,
+// re-establishing the connection whenever symbols are added or removed.
+func (s *tradeStream) serve(i *Ingestor) {
+	if len(i.GetSymbols()) == 0 {
+		log.Println("No symbols to track")
+		return
+	}
+
+	throttleTicker := i.clock.NewTicker(i.throttleInterval)
+	defer throttleTicker.Stop()
+
+	tradeHandler := s.aggTradeHandler(i)
+	errHandler := i.createErrorHandler()
+
+	i.runStream(func(symbols []string) (chan struct{}, chan struct{}, error) {
+		return binance.WsCombinedAggTradeServe(symbols, tradeHandler, errHandler)
+	}, func() {
+		i.startPeriodicBroadcast(throttleTicker, func() { s.broadcast(i) })
+	})
+}
+
+func (s *tradeStream) replays(kind string) bool {
+	return kind == "trade_stats"
+}
+
+// aggTradeHandler creates a handler adding each aggregated trade to the
+// rolling window of its symbol.
+func (s *tradeStream) aggTradeHandler(i *Ingestor) func(*binance.WsAggTradeEvent) {
+	return func(event *binance.WsAggTradeEvent) {
+		now := i.clock.Now()
+		i.markEventReceived(now)
+
+		t, err := convertAggTradeEvent(event)
+		i.recordEvent(event.Symbol, err)
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		window := s.trades[event.Symbol]
+		if window == nil {
+			window = &tradeWindow{}
+			s.trades[event.Symbol] = window
+		}
+		window.add(t, s.window)
+		window.receivedAt = now.UnixMilli()
+		s.traded[event.Symbol] = true
+	}
+}
+
+// broadcast broadcasts the statistics of the symbols that traded since the
+// last broadcast, one message per symbol. Trade statistics go out alongside
+// the price updates of whichever feed is active, so they are not gated on
+// the active source.
+func (s *tradeStream) broadcast(i *Ingestor) {
+	s.mu.Lock()
+	stats := make([]*TradeStats, 0, len(s.traded))
+	for symbol := range s.traded {
+		stats = append(stats, s.trades[symbol].stats(symbol, s.window))
+	}
+	clear(s.traded)
+	s.mu.Unlock()
+
+	sort.Slice(stats, func(a, b int) bool {
+		return stats[a].Symbol < stats[b].Symbol
+	})
+
+	for _, symbolStats := range stats {
+		data, err := json.Marshal(symbolStats)
+		if err != nil {
+			log.Printf("Error marshaling trade stats: %v", err)
+			continue
+		}
+		if !i.publish(TopicTrades, data) {
+			continue
+		}
+
+		i.mu.Lock()
+		if tracked := i.findSymbol(symbolStats.Symbol); tracked != nil {
+			tracked.UpdatesBroadcast++
+		}
+		i.mu.Unlock()
+	}
+}
+
+// convertAggTradeEvent converts a Binance aggregated trade. It returns an
+// error if the price or quantity fails to parse or is not positive.
+func convertAggTradeEvent(event *binance.WsAggTradeEvent) (trade, error) {
+	price, err := strconv.ParseFloat(event.Price, 64)
+	if err != nil || price <= 0 {
+		return trade{}, fmt.Errorf("invalid trade price %q for %s", event.Price, event.Symbol)
+	}
+	quantity, err := strconv.ParseFloat(event.Quantity, 64)
+	if err != nil || quantity <= 0 {
+		return trade{}, fmt.Errorf("invalid trade quantity %q for %s", event.Quantity, event.Symbol)
+	}
+
+	return trade{
+		price:    price,
+		quantity: quantity,
+		count:    max(event.LastBreakdownTradeID-event.FirstBreakdownTradeID+1, 1),
+		// The buyer is the maker when the taker sold
+		buy:  !event.IsBuyerMaker,
+		time: event.TradeTime,
+	}, nil
+}
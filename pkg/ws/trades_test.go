@@ -0,0 +1,108 @@
+package ws
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+)
+
+// aggTrade returns an aggregated trade of one Binance trade at the given
+// second.
+func aggTrade(symbol, price, quantity string, buyerMaker bool, second int64) *binance.WsAggTradeEvent {
+	return &binance.WsAggTradeEvent{
+		Symbol: symbol, Price: price, Quantity: quantity,
+		FirstBreakdownTradeID: second, LastBreakdownTradeID: second,
+		IsBuyerMaker: buyerMaker, TradeTime: second * 1000,
+	}
+}
+
+// TestConvertAggTradeEvent verifies aggregated trades count their Binance
+// trades and the taker side, and invalid trades are rejected.
+func TestConvertAggTradeEvent(t *testing.T) {
+	event := aggTrade("BTCUSDT", "51234.50", "0.25", true, 100)
+	event.LastBreakdownTradeID = 104
+
+	converted, err := convertAggTradeEvent(event)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	expected := trade{price: 51234.5, quantity: 0.25, count: 5, buy: false, time: 100000}
+	if converted != expected {
+		t.Errorf("Expected %+v, got %+v", expected, converted)
+	}
+
+	for _, invalid := range []*binance.WsAggTradeEvent{
+		aggTrade("BTCUSDT", "invalid", "1", false, 1),
+		aggTrade("BTCUSDT", "0", "1", false, 1),
+		aggTrade("BTCUSDT", "1", "0", false, 1),
+	} {
+		if _, err := convertAggTradeEvent(invalid); err == nil {
+			t.Errorf("Expected an error for %+v", invalid)
+		}
+	}
+}
+
+// TestTradeWindowStats verifies the VWAP, trade count and taker volumes
+// cover only the trades within the rolling window.
+func TestTradeWindowStats(t *testing.T) {
+	window := time.Minute
+	var trades tradeWindow
+
+	trades.add(trade{price: 90, quantity: 5, count: 1, buy: true, time: 0}, window)
+	trades.add(trade{price: 100, quantity: 0.1, count: 1, buy: true, time: 30000}, window)
+	trades.add(trade{price: 110, quantity: 0.2, count: 2, buy: false, time: 60000}, window)
+
+	stats := trades.stats("BTCUSDT", window)
+	expected := TradeStats{
+		Type: "trade_stats", Symbol: "BTCUSDT", WindowSeconds: 60,
+		VWAP: 106.66666667, Trades: 3, BuyVolume: 0.1, SellVolume: 0.2,
+		LastPrice: 110, EventTime: 60000,
+	}
+	if *stats != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *stats)
+	}
+}
+
+// TestBroadcastTradeStats verifies the stats of each symbol that traded are
+// broadcast once per interval, whichever feed is active.
+func TestBroadcastTradeStats(t *testing.T) {
+	// Arrange
+	hub := NewHub()
+	hub.SetActiveSource("primary")
+	ingestor := NewIngestor(HubSink(hub),
+		WithSourceName("trades"),
+		WithSymbols([]string{"BTCUSDT", "ETHUSDT"}),
+		WithTrades(5*time.Minute),
+	)
+	trades := ingestor.stream.(*tradeStream)
+	handler := trades.aggTradeHandler(ingestor)
+
+	// Act
+	handler(aggTrade("ETHUSDT", "3000", "2", false, 1))
+	handler(aggTrade("BTCUSDT", "50000", "1", false, 1))
+	handler(aggTrade("BTCUSDT", "50100", "1", true, 2))
+	trades.broadcast(ingestor)
+	trades.broadcast(ingestor)
+
+	// Assert
+	if len(hub.broadcast) != 2 {
+		t.Fatalf("Expected one message per symbol, got %d", len(hub.broadcast))
+	}
+	var stats TradeStats
+	if err := json.Unmarshal((<-hub.broadcast).data, &stats); err != nil {
+		t.Fatalf("Failed to decode trade stats: %v", err)
+	}
+	if stats.Symbol != "BTCUSDT" || stats.VWAP != 50050 || stats.Trades != 2 ||
+		stats.BuyVolume != 1 || stats.SellVolume != 1 || stats.ReceivedAt == 0 {
+		t.Errorf("Expected the BTCUSDT stats, got %+v", stats)
+	}
+
+	if !ingestor.TradeMode() {
+		t.Error("Expected trade mode")
+	}
+	if stats := ingestor.Stats(); stats[0].UpdatesBroadcast != 1 || stats[0].EventsReceived != 2 {
+		t.Errorf("Expected 2 events and 1 broadcast, got %+v", stats[0])
+	}
+}
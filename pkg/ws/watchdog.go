@@ -0,0 +1,164 @@
+package ws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultStaleFeedTimeout is how long the feed may stay silent while
+	// clients are connected before the stale feed alarm fires
+	DefaultStaleFeedTimeout = 60 * time.Second
+
+	// WebhookTimeout bounds a single alarm webhook delivery
+	WebhookTimeout = 5 * time.Second
+
+	// FeedStatusStale is reported when no events arrived within the timeout
+	FeedStatusStale = "stale"
+
+	// FeedStatusLive is reported when events resume after a stale alarm
+	FeedStatusLive = "live"
+)
+
+// FeedStatus is broadcast to clients when the feed goes stale or recovers.
+type FeedStatus struct {
+	Type        string `json:"type"`                  // Always "feed_status"
+	Status      string `json:"status"`                // "stale" or "live"
+	LastEventAt string `json:"lastEventAt,omitempty"` // RFC3339 time of the last Binance event
+	SilentForMs int64  `json:"silentForMs"`           // Time since the last event
+	Region      string `json:"region,omitempty"`      // Deployment region of the server
+}
+
+// WithStaleFeedTimeout sets how long the feed may be silent before the
+// stale feed alarm fires. A zero duration disables the watchdog.
+func WithStaleFeedTimeout(timeout time.Duration) IngestorOption {
+	return func(i *Ingestor) {
+		i.staleFeedTimeout = timeout
+	}
+}
+
+// WithStaleFeedWebhook sets a URL that receives a JSON POST with the
+// FeedStatus payload whenever the stale feed alarm fires or clears.
+func WithStaleFeedWebhook(url string) IngestorOption {
+	return func(i *Ingestor) {
+		i.staleFeedWebhook = url
+	}
+}
+
+// markEventReceived records the arrival time of a Binance event.
+func (i *Ingestor) markEventReceived(at time.Time) {
+	i.lastEventAt.Store(at.UnixNano())
+}
+
+// LastEventAt returns the arrival time of the most recent Binance event,
+// or the zero time if none has arrived yet.
+func (i *Ingestor) LastEventAt() time.Time {
+	nanos := i.lastEventAt.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// runFeedWatchdog periodically checks feed liveness until the ingestor stops.
+func (i *Ingestor) runFeedWatchdog() {
+	if i.staleFeedTimeout <= 0 {
+		return
+	}
+
+	// Check a few times per timeout window so the alarm fires promptly
+	ticker := i.clock.NewTicker(i.staleFeedTimeout / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-i.ctx.Done():
+			return
+		case now := <-ticker.C():
+			i.checkFeedHealth(now)
+		}
+	}
+}
+
+// checkFeedHealth fires the stale feed alarm when no events arrived within
+// the timeout while clients are connected, and clears it once events resume.
+func (i *Ingestor) checkFeedHealth(now time.Time) {
+	// Until the first event arrives, measure silence from start-up
+	lastEventAt := i.LastEventAt()
+	silentSince := i.startedAt
+	if !lastEventAt.IsZero() {
+		silentSince = lastEventAt
+	}
+
+	silentFor := now.Sub(silentSince)
+	stale := silentFor >= i.staleFeedTimeout
+
+	switch {
+	case stale && !i.feedStale && i.audience.GetClientCount() > 0 && i.audience.IsActiveSource(i.name):
+		i.feedStale = true
+		i.metrics.staleFeedAlarms.Inc()
+		i.metrics.feedStale.Set(1)
+		log.Printf("⚠ Stale feed alarm: no Binance events for %s with %d clients connected",
+			silentFor.Round(time.Second), i.audience.GetClientCount())
+		i.reportFeedStatus(FeedStatusStale, lastEventAt, silentFor)
+
+	case !stale && i.feedStale:
+		i.feedStale = false
+		i.metrics.feedStale.Set(0)
+		log.Println("✓ Binance feed recovered, stale feed alarm cleared")
+		i.reportFeedStatus(FeedStatusLive, lastEventAt, silentFor)
+	}
+}
+
+// reportFeedStatus broadcasts the feed status to clients and notifies the webhook.
+func (i *Ingestor) reportFeedStatus(status string, lastEventAt time.Time, silentFor time.Duration) {
+	feedStatus := &FeedStatus{
+		Type:        "feed_status",
+		Status:      status,
+		SilentForMs: silentFor.Milliseconds(),
+		Region:      i.audience.Region(),
+	}
+	if !lastEventAt.IsZero() {
+		feedStatus.LastEventAt = lastEventAt.UTC().Format(time.RFC3339)
+	}
+
+	jsonData, err := json.Marshal(feedStatus)
+	if err != nil {
+		log.Printf("Error marshaling feed status: %v", err)
+		return
+	}
+
+	i.publish("", jsonData)
+
+	if i.staleFeedWebhook != "" {
+		go i.sendWebhook(jsonData)
+	}
+}
+
+// sendWebhook posts the payload to the configured alarm webhook.
+func (i *Ingestor) sendWebhook(payload []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), WebhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.staleFeedWebhook, bytes.NewReader(payload))
+	if err != nil {
+		log.Printf("Failed to create feed status webhook request: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := i.httpClient.Do(req)
+	if err != nil {
+		log.Printf("Feed status webhook failed: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		log.Printf("Feed status webhook returned status %d", resp.StatusCode)
+	}
+}
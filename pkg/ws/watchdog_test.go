@@ -0,0 +1,153 @@
+package ws
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// readFeedStatus reads and decodes the next feed status from the hub broadcast channel.
+func readFeedStatus(t *testing.T, hub *Hub) *FeedStatus {
+	t.Helper()
+
+	select {
+	case queued := <-hub.broadcast:
+		msg := queued.data
+		var status FeedStatus
+		if err := json.Unmarshal(msg, &status); err != nil {
+			t.Fatalf("Failed to decode feed status: %v", err)
+		}
+		return &status
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Timeout waiting for feed status broadcast")
+		return nil
+	}
+}
+
+// TestDefaultStaleFeedTimeout verifies the watchdog is enabled by default.
+func TestDefaultStaleFeedTimeout(t *testing.T) {
+	ingestor := NewIngestor(HubSink(NewHub()))
+
+	if ingestor.staleFeedTimeout != DefaultStaleFeedTimeout {
+		t.Errorf("Expected default stale timeout %v, got %v", DefaultStaleFeedTimeout, ingestor.staleFeedTimeout)
+	}
+}
+
+// TestStaleFeedOptions verifies the watchdog functional options.
+func TestStaleFeedOptions(t *testing.T) {
+	ingestor := NewIngestor(HubSink(NewHub()),
+		WithStaleFeedTimeout(2*time.Minute),
+		WithStaleFeedWebhook("http://example.com/hook"),
+	)
+
+	if ingestor.staleFeedTimeout != 2*time.Minute {
+		t.Errorf("Expected stale timeout 2m, got %v", ingestor.staleFeedTimeout)
+	}
+
+	if ingestor.staleFeedWebhook != "http://example.com/hook" {
+		t.Errorf("Expected webhook URL to be set, got %q", ingestor.staleFeedWebhook)
+	}
+}
+
+// TestCheckFeedHealthFiresAlarm verifies the alarm fires and clears.
+func TestCheckFeedHealthFiresAlarm(t *testing.T) {
+	hub := NewHub()
+	hub.clients[&Client{Hub: hub, Send: make(chan []byte, 1)}] = true
+
+	ingestor := NewIngestor(HubSink(hub), WithStaleFeedTimeout(time.Minute))
+	now := time.Now()
+	ingestor.startedAt = now.Add(-2 * time.Minute)
+
+	// No events since start-up: alarm should fire
+	ingestor.checkFeedHealth(now)
+
+	status := readFeedStatus(t, hub)
+	if status.Type != "feed_status" || status.Status != FeedStatusStale {
+		t.Errorf("Expected stale feed_status, got %+v", status)
+	}
+	if status.LastEventAt != "" {
+		t.Errorf("Expected no lastEventAt before first event, got %s", status.LastEventAt)
+	}
+	if !ingestor.feedStale {
+		t.Error("Ingestor should be marked stale")
+	}
+
+	// A second check while still stale should not re-fire
+	ingestor.checkFeedHealth(now.Add(time.Second))
+	select {
+	case queued := <-hub.broadcast:
+		msg := queued.data
+		t.Errorf("Expected no repeated alarm, got %s", msg)
+	default:
+	}
+
+	// Events resume: alarm should clear
+	ingestor.markEventReceived(now.Add(2 * time.Second))
+	ingestor.checkFeedHealth(now.Add(3 * time.Second))
+
+	status = readFeedStatus(t, hub)
+	if status.Status != FeedStatusLive {
+		t.Errorf("Expected live feed_status, got %s", status.Status)
+	}
+	if status.LastEventAt == "" {
+		t.Error("Expected lastEventAt after events resume")
+	}
+	if ingestor.feedStale {
+		t.Error("Ingestor should no longer be marked stale")
+	}
+}
+
+// TestCheckFeedHealthWithoutClients verifies no alarm fires when nobody is connected.
+func TestCheckFeedHealthWithoutClients(t *testing.T) {
+	hub := NewHub()
+	ingestor := NewIngestor(HubSink(hub), WithStaleFeedTimeout(time.Minute))
+	now := time.Now()
+	ingestor.startedAt = now.Add(-time.Hour)
+
+	ingestor.checkFeedHealth(now)
+
+	select {
+	case queued := <-hub.broadcast:
+		msg := queued.data
+		t.Errorf("Expected no alarm without clients, got %s", msg)
+	default:
+	}
+}
+
+// TestStaleFeedWebhook verifies the webhook receives the feed status payload.
+func TestStaleFeedWebhook(t *testing.T) {
+	received := make(chan []byte, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- body
+	}))
+	defer webhook.Close()
+
+	hub := NewHub()
+	hub.clients[&Client{Hub: hub, Send: make(chan []byte, 1)}] = true
+
+	ingestor := NewIngestor(HubSink(hub),
+		WithStaleFeedTimeout(time.Minute),
+		WithStaleFeedWebhook(webhook.URL),
+	)
+	now := time.Now()
+	ingestor.startedAt = now.Add(-2 * time.Minute)
+
+	ingestor.checkFeedHealth(now)
+
+	select {
+	case body := <-received:
+		var status FeedStatus
+		if err := json.Unmarshal(body, &status); err != nil {
+			t.Fatalf("Failed to decode webhook payload: %v", err)
+		}
+		if status.Status != FeedStatusStale {
+			t.Errorf("Expected stale status in webhook, got %s", status.Status)
+		}
+	case <-time.After(time.Second):
+		t.Error("Timeout waiting for webhook delivery")
+	}
+}